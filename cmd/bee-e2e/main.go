@@ -0,0 +1,322 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	cmdfile "github.com/ethersphere/bee/cmd/internal/file"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/node"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	host             = "127.0.0.1"
+	portsPerNode     = 3 // api, debug api and p2p ports
+	pollInterval     = 500 * time.Millisecond
+	shutdownTimeout  = 10 * time.Second
+	e2eNodesPassword = "bee-e2e"
+)
+
+var (
+	nodesCount int           // flag variable, number of nodes in the cluster
+	basePort   int           // flag variable, first port of the nodes
+	dataDir    string        // flag variable, data directory of the nodes
+	networkID  uint64        // flag variable, network id of the cluster
+	dataSize   int           // flag variable, size of the uploaded data
+	timeout    time.Duration // flag variable, time to wait for connections and retrieval
+	keep       bool          // flag variable, keeps the cluster running after the check
+	verbosity  string        // flag variable, log level of the nodes
+	client     = &http.Client{Timeout: 30 * time.Second}
+)
+
+// clusterNode is a node of the cluster with the addresses of its APIs.
+type clusterNode struct {
+	bee         *node.Bee
+	apiURL      string
+	debugAPIURL string
+	p2pAddr     string
+	dataDir     string
+}
+
+// Run starts the cluster, uploads random data to the first node and
+// retrieves it from every other node.
+func Run(cmd *cobra.Command, args []string) (err error) {
+	if nodesCount < 2 {
+		return fmt.Errorf("at least 2 nodes are required, got %d", nodesCount)
+	}
+	logger, err := cmdfile.SetLogger(cmd, verbosity)
+	if err != nil {
+		return err
+	}
+
+	// the data of a temporary directory is removed with the cluster
+	if dataDir == "" {
+		dir, err := ioutil.TempDir("", "bee-e2e")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		dataDir = dir
+	}
+
+	var nodes []*clusterNode
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		for i, n := range nodes {
+			if err := n.bee.Shutdown(ctx); err != nil {
+				cmd.PrintErrf("shutdown node %d: %v\n", i, err)
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var bootnodes []string
+	for i := 0; i < nodesCount; i++ {
+		n, err := startNode(i, bootnodes, logger)
+		if err != nil {
+			return fmt.Errorf("start node %d: %w", i, err)
+		}
+		nodes = append(nodes, n)
+		cmd.Printf("node %d: api %s, debug api %s, p2p %s\n", i, n.apiURL, n.debugAPIURL, n.p2pAddr)
+
+		// all nodes connect to the first one, and find each other through it
+		if i == 0 {
+			underlay, err := n.underlay(ctx)
+			if err != nil {
+				return fmt.Errorf("underlay of node 0: %w", err)
+			}
+			bootnodes = []string{underlay}
+		}
+	}
+
+	for i, n := range nodes {
+		if err := n.waitPeers(ctx); err != nil {
+			return fmt.Errorf("peers of node %d: %w", i, err)
+		}
+	}
+	cmd.Println("all nodes are connected")
+
+	data := make([]byte, dataSize)
+	if _, err := rand.Read(data); err != nil {
+		return err
+	}
+	reference, err := nodes[0].upload(ctx, data)
+	if err != nil {
+		return fmt.Errorf("upload to node 0: %w", err)
+	}
+	cmd.Printf("uploaded %d bytes to node 0: %s\n", len(data), reference)
+
+	for i, n := range nodes[1:] {
+		start := time.Now()
+		if err := n.waitDownload(ctx, reference, data); err != nil {
+			return fmt.Errorf("retrieve from node %d: %w", i+1, err)
+		}
+		cmd.Printf("retrieved %s from node %d in %s\n", reference, i+1, time.Since(start))
+	}
+	cmd.Println("ok")
+
+	if keep {
+		cmd.Printf("cluster is running with data in %s, interrupt to stop\n", dataDir)
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		<-sigs
+	}
+	return nil
+}
+
+// startNode starts the node with the index, which determines its ports and
+// its data directory.
+func startNode(i int, bootnodes []string, logger logging.Logger) (*clusterNode, error) {
+	port := basePort + i*portsPerNode
+	n := &clusterNode{
+		apiURL:      fmt.Sprintf("http://%s:%d", host, port),
+		debugAPIURL: fmt.Sprintf("http://%s:%d", host, port+1),
+		p2pAddr:     fmt.Sprintf("%s:%d", host, port+2),
+		dataDir:     filepath.Join(dataDir, fmt.Sprintf("node-%d", i)),
+	}
+
+	bee, err := node.NewBee(node.Options{
+		DataDir:      n.dataDir,
+		DBCapacity:   5000000,
+		Password:     e2eNodesPassword,
+		APIAddr:      fmt.Sprintf("%s:%d", host, port),
+		DebugAPIAddr: fmt.Sprintf("%s:%d", host, port+1),
+		Addr:         n.p2pAddr,
+		NetworkID:    networkID,
+		Bootnodes:    bootnodes,
+		Logger:       logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	n.bee = bee
+	return n, nil
+}
+
+// underlay returns the loopback underlay address of the node.
+func (n *clusterNode) underlay(ctx context.Context) (string, error) {
+	var resp struct {
+		Underlay []string `json:"underlay"`
+	}
+	if err := n.getJSON(ctx, n.debugAPIURL+"/addresses", &resp); err != nil {
+		return "", err
+	}
+	for _, a := range resp.Underlay {
+		if strings.HasPrefix(a, "/ip4/"+host+"/") {
+			return a, nil
+		}
+	}
+	return "", errors.New("no loopback underlay address")
+}
+
+// waitPeers waits until the node is connected to at least one peer.
+func (n *clusterNode) waitPeers(ctx context.Context) error {
+	for {
+		var resp struct {
+			Peers []json.RawMessage `json:"peers"`
+		}
+		if err := n.getJSON(ctx, n.debugAPIURL+"/peers", &resp); err != nil {
+			return err
+		}
+		if len(resp.Peers) > 0 {
+			return nil
+		}
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// upload uploads the data to the node and returns its reference.
+func (n *clusterNode) upload(ctx context.Context, data []byte) (swarm.Address, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.apiURL+"/bytes", bytes.NewReader(data))
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := client.Do(req)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return swarm.ZeroAddress, fmt.Errorf("response status %s", resp.Status)
+	}
+
+	var r struct {
+		Reference swarm.Address `json:"reference"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return r.Reference, nil
+}
+
+// waitDownload downloads the data of the reference from the node until it is
+// retrieved or the context is done, as the chunks may not be synced to the
+// network yet, and validates that it is the expected data.
+func (n *clusterNode) waitDownload(ctx context.Context, reference swarm.Address, want []byte) error {
+	for {
+		got, err := n.download(ctx, reference)
+		if err == nil {
+			if !bytes.Equal(got, want) {
+				return errors.New("retrieved data differs from the uploaded data")
+			}
+			return nil
+		}
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", ctx.Err(), err)
+		}
+	}
+}
+
+func (n *clusterNode) download(ctx context.Context, reference swarm.Address) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.apiURL+"/bytes/"+reference.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("response status %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (n *clusterNode) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s: response status %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func main() {
+	c := &cobra.Command{
+		Use:   "bee-e2e",
+		Args:  cobra.NoArgs,
+		Short: "Run an end-to-end check on a local cluster of bee nodes",
+		Long: `Starts a cluster of bee nodes in this process, uploads random data to the first node
+and retrieves it from every other node.
+
+Every node listens on consecutive ports from the --base-port flag value, for the HTTP API, the debug
+HTTP API and the P2P connections, and keeps its data in its own directory under --data-dir.
+
+With the --keep flag the cluster keeps running after the check until it is interrupted, which makes it
+usable as a local development cluster.`,
+		RunE:         Run,
+		SilenceUsage: true,
+	}
+
+	c.Flags().IntVarP(&nodesCount, "nodes", "n", 3, "number of nodes in the cluster")
+	c.Flags().IntVar(&basePort, "base-port", 11630, "first port of the nodes")
+	c.Flags().StringVarP(&dataDir, "data-dir", "d", "", "data directory of the nodes, a temporary directory is used and removed if not set")
+	c.Flags().Uint64Var(&networkID, "network-id", 1633, "ID of the network of the cluster")
+	c.Flags().IntVarP(&dataSize, "size", "s", 1024*1024, "number of bytes of the uploaded data")
+	c.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "time to wait for the nodes to connect and the data to be retrieved")
+	c.Flags().BoolVar(&keep, "keep", false, "keep the cluster running after the check until interrupted")
+	c.Flags().StringVar(&verbosity, "info", "0", "log verbosity level of the nodes 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=trace")
+
+	c.SetOutput(c.OutOrStdout())
+	err := c.Execute()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
@@ -5,7 +5,9 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -55,10 +57,26 @@ func newCommand(opts ...option) (c *command, err error) {
 
 	c.initGlobalFlags()
 
+	if err := c.initInitCmd(); err != nil {
+		return nil, err
+	}
+
 	if err := c.initStartCmd(); err != nil {
 		return nil, err
 	}
 
+	if err := c.initRotateKeyCmd(); err != nil {
+		return nil, err
+	}
+
+	if err := c.initBackupKeyCmd(); err != nil {
+		return nil, err
+	}
+
+	if err := c.initRestoreKeyCmd(); err != nil {
+		return nil, err
+	}
+
 	c.initVersionCmd()
 	return c, nil
 }
@@ -102,14 +120,18 @@ func (c *command) initConfig() (err error) {
 		c.cfgFile = filepath.Join(c.homeDir, configName+".yaml")
 	}
 
-	// If a config file is found, read it in.
-	if err := config.ReadInConfig(); err != nil {
+	c.config = config
+	return c.reloadConfig()
+}
+
+// reloadConfig reads the config file again, if it is found.
+func (c *command) reloadConfig() error {
+	if err := c.config.ReadInConfig(); err != nil {
 		var e viper.ConfigFileNotFoundError
 		if !errors.As(err, &e) {
 			return err
 		}
 	}
-	c.config = config
 	return nil
 }
 
@@ -124,3 +146,19 @@ func (c *command) setHomeDir() (err error) {
 	c.homeDir = dir
 	return nil
 }
+
+// password returns the password for decrypting keys from the password
+// option, the file from the password file option, or the terminal prompt.
+func (c *command) password(cmd *cobra.Command, passwordOption, passwordFileOption string) (string, error) {
+	if p := c.config.GetString(passwordOption); p != "" {
+		return p, nil
+	}
+	if pf := c.config.GetString(passwordFileOption); pf != "" {
+		b, err := ioutil.ReadFile(pf)
+		if err != nil {
+			return "", err
+		}
+		return string(bytes.Trim(b, "\n")), nil
+	}
+	return terminalPromptPassword(cmd, c.passwordReader, "Password")
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/ethersphere/bee/pkg/identity"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func (c *command) initInitCmd() (err error) {
+
+	const (
+		optionNameDataDir      = "data-dir"
+		optionNamePassword     = "password"
+		optionNamePasswordFile = "password-file"
+		optionNameNetworkID    = "network-id"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create the keys of a new node",
+		Long: `Create the swarm and the libp2p keys of a new node in the keys directory of the data directory.
+
+The keys are encrypted with the password. Existing keys are not replaced, and
+they must be encrypted with the same password. The overlay address of the node
+is printed.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 0 {
+				return cmd.Help()
+			}
+
+			password, err := c.password(cmd, optionNamePassword, optionNamePasswordFile)
+			if err != nil {
+				return err
+			}
+
+			result, err := identity.Init(identity.Options{
+				DataDir:   c.config.GetString(optionNameDataDir),
+				Password:  password,
+				NetworkID: c.config.GetUint64(optionNameNetworkID),
+				Logger:    logging.New(cmd.ErrOrStderr(), logrus.WarnLevel),
+			})
+			if err != nil {
+				return err
+			}
+
+			if result.OldOverlay.IsZero() {
+				cmd.Printf("overlay address: %s\n", result.NewOverlay)
+			} else {
+				cmd.Printf("keys already exist, overlay address: %s\n", result.NewOverlay)
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
+	cmd.Flags().String(optionNamePassword, "", "password for encrypting keys")
+	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for encrypting keys")
+	cmd.Flags().Uint64(optionNameNetworkID, 1, "ID of the Swarm network")
+
+	c.root.AddCommand(cmd)
+	return nil
+}
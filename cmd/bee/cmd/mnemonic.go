@@ -0,0 +1,142 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bufio"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ethersphere/bee/pkg/identity"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func (c *command) initBackupKeyCmd() (err error) {
+
+	const (
+		optionNameDataDir      = "data-dir"
+		optionNamePassword     = "password"
+		optionNamePasswordFile = "password-file"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "backup-key",
+		Short: "Print the swarm key of the node as a mnemonic",
+		Long: `Print the swarm key of the node as a BIP-39 mnemonic of 24 words.
+
+The node identity can be restored from the mnemonic with the restore-key command,
+so it has to be kept as secret as the key itself.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 0 {
+				return cmd.Help()
+			}
+
+			password, err := c.password(cmd, optionNamePassword, optionNamePasswordFile)
+			if err != nil {
+				return err
+			}
+
+			mnemonic, err := identity.Mnemonic(identity.Options{
+				DataDir:  c.config.GetString(optionNameDataDir),
+				Password: password,
+			})
+			if err != nil {
+				return err
+			}
+
+			cmd.Println(mnemonic)
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
+	cmd.Flags().String(optionNamePassword, "", "password for decrypting keys")
+	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
+
+	c.root.AddCommand(cmd)
+	return nil
+}
+
+func (c *command) initRestoreKeyCmd() (err error) {
+
+	const (
+		optionNameDataDir      = "data-dir"
+		optionNamePassword     = "password"
+		optionNamePasswordFile = "password-file"
+		optionNameNetworkID    = "network-id"
+		optionNameMnemonicFile = "mnemonic-file"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "restore-key",
+		Short: "Restore the swarm key of a stopped node from a mnemonic",
+		Long: `Restore the swarm key of a stopped node from the mnemonic printed by the backup-key command.
+
+The mnemonic is read from the mnemonic file, or from the standard input. The
+restored key is encrypted with the password. An existing swarm key must be
+encrypted with the same password, and it is backed up in the keys directory. If
+the overlay address is changed, the node data is migrated as with the rotate-key
+command.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 0 {
+				return cmd.Help()
+			}
+
+			var mnemonic string
+			if f := c.config.GetString(optionNameMnemonicFile); f != "" {
+				b, err := ioutil.ReadFile(f)
+				if err != nil {
+					return err
+				}
+				mnemonic = string(b)
+			} else {
+				cmd.Print("Mnemonic: ")
+				mnemonic, err = bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				if err != nil {
+					return err
+				}
+			}
+
+			password, err := c.password(cmd, optionNamePassword, optionNamePasswordFile)
+			if err != nil {
+				return err
+			}
+
+			result, err := identity.Restore(identity.Options{
+				DataDir:   c.config.GetString(optionNameDataDir),
+				Password:  password,
+				NetworkID: c.config.GetUint64(optionNameNetworkID),
+				Logger:    logging.New(cmd.ErrOrStderr(), logrus.WarnLevel),
+			}, mnemonic)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("overlay address: %s\n", result.NewOverlay)
+			if result.KeyBackupFile != "" {
+				cmd.Printf("old overlay address: %s\n", result.OldOverlay)
+				cmd.Printf("old swarm key backed up to: %s\n", result.KeyBackupFile)
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
+	cmd.Flags().String(optionNamePassword, "", "password for encrypting keys")
+	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for encrypting keys")
+	cmd.Flags().Uint64(optionNameNetworkID, 1, "ID of the Swarm network")
+	cmd.Flags().String(optionNameMnemonicFile, "", "path to a file that contains the mnemonic, read from the standard input if not set")
+
+	c.root.AddCommand(cmd)
+	return nil
+}
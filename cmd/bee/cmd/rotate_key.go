@@ -0,0 +1,95 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/identity"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// rotateKeyConfirmation is the answer that confirms the key rotation.
+const rotateKeyConfirmation = "rotate"
+
+var errRotateKeyNotConfirmed = errors.New("swarm key rotation not confirmed")
+
+func (c *command) initRotateKeyCmd() (err error) {
+
+	const (
+		optionNameDataDir      = "data-dir"
+		optionNamePassword     = "password"
+		optionNamePasswordFile = "password-file"
+		optionNameNetworkID    = "network-id"
+		optionNameYes          = "yes"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Replace the swarm key of a stopped node",
+		Long: `Replace the swarm key of a stopped node with a new one, changing its overlay address.
+
+The replaced key is backed up in the keys directory. The old overlay address is
+removed from the address book, and the local store is reindexed for the new
+overlay address, so all stored chunks need to be synced to peers again. The
+libp2p key, and so the underlay addresses, are not changed.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if len(args) > 0 {
+				return cmd.Help()
+			}
+
+			dataDir := c.config.GetString(optionNameDataDir)
+			if !c.config.GetBool(optionNameYes) {
+				cmd.Printf("The swarm key and the overlay address of the node with data directory %s are going to be replaced.\n", dataDir)
+				cmd.Printf("Type %q to confirm: ", rotateKeyConfirmation)
+				answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				if err != nil {
+					return err
+				}
+				if strings.TrimSpace(answer) != rotateKeyConfirmation {
+					return errRotateKeyNotConfirmed
+				}
+			}
+
+			password, err := c.password(cmd, optionNamePassword, optionNamePasswordFile)
+			if err != nil {
+				return err
+			}
+
+			result, err := identity.Rotate(identity.Options{
+				DataDir:   dataDir,
+				Password:  password,
+				NetworkID: c.config.GetUint64(optionNameNetworkID),
+				Logger:    logging.New(cmd.ErrOrStderr(), logrus.WarnLevel),
+			})
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("old overlay address: %s\n", result.OldOverlay)
+			cmd.Printf("new overlay address: %s\n", result.NewOverlay)
+			cmd.Printf("old swarm key backed up to: %s\n", result.KeyBackupFile)
+			cmd.Println("all locally stored chunks need to be synced to peers again")
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
+	cmd.Flags().String(optionNamePassword, "", "password for decrypting keys")
+	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
+	cmd.Flags().Uint64(optionNameNetworkID, 1, "ID of the Swarm network")
+	cmd.Flags().Bool(optionNameYes, false, "replace the key without confirmation")
+
+	c.root.AddCommand(cmd)
+	return nil
+}
@@ -5,7 +5,6 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -26,25 +25,75 @@ import (
 func (c *command) initStartCmd() (err error) {
 
 	const (
-		optionNameDataDir            = "data-dir"
-		optionNameDBCapacity         = "db-capacity"
-		optionNamePassword           = "password"
-		optionNamePasswordFile       = "password-file"
-		optionNameAPIAddr            = "api-addr"
-		optionNameP2PAddr            = "p2p-addr"
-		optionNameNATAddr            = "nat-addr"
-		optionNameP2PWSEnable        = "p2p-ws-enable"
-		optionNameP2PQUICEnable      = "p2p-quic-enable"
-		optionNameDebugAPIEnable     = "debug-api-enable"
-		optionNameDebugAPIAddr       = "debug-api-addr"
-		optionNameBootnodes          = "bootnode"
-		optionNameNetworkID          = "network-id"
-		optionWelcomeMessage         = "welcome-message"
-		optionCORSAllowedOrigins     = "cors-allowed-origins"
-		optionNameTracingEnabled     = "tracing-enable"
-		optionNameTracingEndpoint    = "tracing-endpoint"
-		optionNameTracingServiceName = "tracing-service-name"
-		optionNameVerbosity          = "verbosity"
+		optionNameDataDir                = "data-dir"
+		optionNameDBCapacity             = "db-capacity"
+		optionNameDBCapacityBytes        = "db-capacity-bytes"
+		optionNameDBMigrationDryRun      = "db-migration-dry-run"
+		optionNameDBRebase               = "db-rebase"
+		optionNameDBAutoCapacity         = "db-auto-capacity"
+		optionNameDBDiskReserve          = "db-disk-reserve"
+		optionNameMirrorDir              = "mirror-dir"
+		optionNamePassword               = "password"
+		optionNamePasswordFile           = "password-file"
+		optionNameAPIAddr                = "api-addr"
+		optionNameP2PAddr                = "p2p-addr"
+		optionNameNATAddr                = "nat-addr"
+		optionNameNAT6Addr               = "nat6-addr"
+		optionNameProxyAddr              = "proxy-addr"
+		optionNameP2PWSEnable            = "p2p-ws-enable"
+		optionNameP2PQUICEnable          = "p2p-quic-enable"
+		optionNameDebugAPIEnable         = "debug-api-enable"
+		optionNameDebugAPIAddr           = "debug-api-addr"
+		optionNameDebugAPIAdminToken     = "debug-api-admin-token"
+		optionNameBootnodes              = "bootnode"
+		optionNameNetworkID              = "network-id"
+		optionWelcomeMessage             = "welcome-message"
+		optionCORSAllowedOrigins         = "cors-allowed-origins"
+		optionNameTracingEnabled         = "tracing-enable"
+		optionNameTracingEndpoint        = "tracing-endpoint"
+		optionNameTracingServiceName     = "tracing-service-name"
+		optionNameVerbosity              = "verbosity"
+		optionNameLogComponentLevels     = "log-component-levels"
+		optionNameLogFormat              = "log-format"
+		optionNameReceiptDepthCheck      = "receipt-depth-check"
+		optionNameBootnodeRefresh        = "bootnode-refresh"
+		optionNameReplicationFactor      = "replication-factor"
+		optionNameRetryPolicy            = "retry-policy"
+		optionNameRetryDelay             = "retry-delay"
+		optionNameRetryMaxDelay          = "retry-max-delay"
+		optionNamePullSyncDisable        = "pullsync-disable"
+		optionNameWarmupTime             = "warmup-time"
+		optionNameHiveDisable            = "hive-disable"
+		optionNameLightNode              = "light-node"
+		optionNameSplitterWorkers        = "splitter-workers"
+		optionNameSlowPut                = "slow-put-threshold"
+		optionNameSlowReceipt            = "slow-receipt-threshold"
+		optionNameSlowDial               = "slow-dial-threshold"
+		optionNameManifestPrefetch       = "manifest-prefetch"
+		optionNameResolverEndpoint       = "resolver-endpoint"
+		optionNameResolverCacheTTL       = "resolver-cache-ttl"
+		optionNameResolverNegativeTTL    = "resolver-negative-cache-ttl"
+		optionNameAllowedOverlays        = "allowed-overlays"
+		optionNameAllowedUnderlays       = "allowed-underlays"
+		optionNameDeniedOverlays         = "denied-overlays"
+		optionNameBandwidthLimits        = "bandwidth-limits"
+		optionNameVersionOverrides       = "protocol-version-overrides"
+		optionNameInboundIPLimit         = "inbound-ip-limit"
+		optionNameInboundSubnetLimit     = "inbound-subnet-limit"
+		optionNamePaymentThreshold       = "payment-threshold"
+		optionNamePaymentTolerance       = "payment-tolerance"
+		optionNamePaymentRefreshRate     = "payment-refresh-rate"
+		optionNameTestStorageLatency     = "test-storage-latency"
+		optionNameTestStorageJitter      = "test-storage-latency-jitter"
+		optionNameTestStorageErrorRate   = "test-storage-error-rate"
+		optionNameAPIUploadConcurrency   = "api-upload-concurrency"
+		optionNameAPIDownloadConcurrency = "api-download-concurrency"
+		optionNameDebugAPIConcurrency    = "debug-api-concurrency"
+		optionNameReadinessMinPeers      = "readiness-min-peers"
+		optionNameDebugAPIProfiling      = "debug-api-profiling"
+		optionNameDebugAPIReadToken      = "debug-api-read-token"
+		optionNameDebugAPIRestricted     = "debug-api-restricted"
+		optionDebugAPICORSAllowedOrigins = "debug-api-cors-allowed-origins"
 	)
 
 	cmd := &cobra.Command{
@@ -55,22 +104,26 @@ func (c *command) initStartCmd() (err error) {
 				return cmd.Help()
 			}
 
+			logLevel, err := verbosityLevel(c.config.GetString(optionNameVerbosity))
+			if err != nil {
+				return err
+			}
+			logFormat, err := logging.ParseFormat(c.config.GetString(optionNameLogFormat))
+			if err != nil {
+				return err
+			}
+			logComponentLevels, err := componentLevels(c.config.GetStringSlice(optionNameLogComponentLevels))
+			if err != nil {
+				return err
+			}
 			var logger logging.Logger
-			switch v := strings.ToLower(c.config.GetString(optionNameVerbosity)); v {
-			case "0", "silent":
+			if logLevel == logrus.PanicLevel {
 				logger = logging.New(ioutil.Discard, 0)
-			case "1", "error":
-				logger = logging.New(cmd.OutOrStdout(), logrus.ErrorLevel)
-			case "2", "warn":
-				logger = logging.New(cmd.OutOrStdout(), logrus.WarnLevel)
-			case "3", "info":
-				logger = logging.New(cmd.OutOrStdout(), logrus.InfoLevel)
-			case "4", "debug":
-				logger = logging.New(cmd.OutOrStdout(), logrus.DebugLevel)
-			case "5", "trace":
-				logger = logging.New(cmd.OutOrStdout(), logrus.TraceLevel)
-			default:
-				return fmt.Errorf("unknown verbosity level %q", v)
+			} else {
+				logger = logging.NewWithFormat(cmd.OutOrStdout(), logLevel, logFormat)
+			}
+			for component, level := range logComponentLevels {
+				logger.SetComponentLevel(component, level)
 			}
 			bee := `
 Welcome to the Swarm.... Bzzz Bzzzz Bzzzz
@@ -94,76 +147,150 @@ Welcome to the Swarm.... Bzzz Bzzzz Bzzzz
 				debugAPIAddr = ""
 			}
 
-			var password string
-			if p := c.config.GetString(optionNamePassword); p != "" {
-				password = p
-			} else if pf := c.config.GetString(optionNamePasswordFile); pf != "" {
-				b, err := ioutil.ReadFile(pf)
-				if err != nil {
-					return err
-				}
-				password = string(bytes.Trim(b, "\n"))
-			} else {
-				p, err := terminalPromptPassword(cmd, c.passwordReader, "Password")
-				if err != nil {
-					return err
-				}
-				password = p
+			password, err := c.password(cmd, optionNamePassword, optionNamePasswordFile)
+			if err != nil {
+				return err
 			}
 
 			b, err := node.NewBee(node.Options{
-				DataDir:            c.config.GetString(optionNameDataDir),
-				DBCapacity:         c.config.GetUint64(optionNameDBCapacity),
-				Password:           password,
-				APIAddr:            c.config.GetString(optionNameAPIAddr),
-				DebugAPIAddr:       debugAPIAddr,
-				Addr:               c.config.GetString(optionNameP2PAddr),
-				NATAddr:            c.config.GetString(optionNameNATAddr),
-				EnableWS:           c.config.GetBool(optionNameP2PWSEnable),
-				EnableQUIC:         c.config.GetBool(optionNameP2PQUICEnable),
-				NetworkID:          c.config.GetUint64(optionNameNetworkID),
-				WelcomeMessage:     c.config.GetString(optionWelcomeMessage),
-				Bootnodes:          c.config.GetStringSlice(optionNameBootnodes),
-				CORSAllowedOrigins: c.config.GetStringSlice(optionCORSAllowedOrigins),
-				TracingEnabled:     c.config.GetBool(optionNameTracingEnabled),
-				TracingEndpoint:    c.config.GetString(optionNameTracingEndpoint),
-				TracingServiceName: c.config.GetString(optionNameTracingServiceName),
-				Logger:             logger,
+				DataDir:                c.config.GetString(optionNameDataDir),
+				DBCapacity:             c.config.GetUint64(optionNameDBCapacity),
+				DBCapacityBytes:        c.config.GetUint64(optionNameDBCapacityBytes),
+				DBMigrationDryRun:      c.config.GetBool(optionNameDBMigrationDryRun),
+				DBRebase:               c.config.GetBool(optionNameDBRebase),
+				DBAutoCapacity:         c.config.GetBool(optionNameDBAutoCapacity),
+				DBDiskReserve:          c.config.GetUint64(optionNameDBDiskReserve),
+				MirrorDir:              c.config.GetString(optionNameMirrorDir),
+				Password:               password,
+				APIAddr:                c.config.GetString(optionNameAPIAddr),
+				DebugAPIAddr:           debugAPIAddr,
+				DebugAPIAdminToken:     c.config.GetString(optionNameDebugAPIAdminToken),
+				DebugAPIProfiling:      c.config.GetBool(optionNameDebugAPIProfiling),
+				DebugAPIReadToken:      c.config.GetString(optionNameDebugAPIReadToken),
+				DebugAPIRestricted:     c.config.GetBool(optionNameDebugAPIRestricted),
+				DebugAPICORSOrigins:    c.config.GetStringSlice(optionDebugAPICORSAllowedOrigins),
+				APIUploadConcurrency:   c.config.GetInt(optionNameAPIUploadConcurrency),
+				APIDownloadConcurrency: c.config.GetInt(optionNameAPIDownloadConcurrency),
+				DebugAPIConcurrency:    c.config.GetInt(optionNameDebugAPIConcurrency),
+				ReadinessMinPeers:      c.config.GetInt(optionNameReadinessMinPeers),
+				Addr:                   c.config.GetString(optionNameP2PAddr),
+				NATAddr:                c.config.GetString(optionNameNATAddr),
+				NAT6Addr:               c.config.GetString(optionNameNAT6Addr),
+				ProxyAddr:              c.config.GetString(optionNameProxyAddr),
+				EnableWS:               c.config.GetBool(optionNameP2PWSEnable),
+				EnableQUIC:             c.config.GetBool(optionNameP2PQUICEnable),
+				NetworkID:              c.config.GetUint64(optionNameNetworkID),
+				WelcomeMessage:         c.config.GetString(optionWelcomeMessage),
+				Bootnodes:              c.config.GetStringSlice(optionNameBootnodes),
+				CORSAllowedOrigins:     c.config.GetStringSlice(optionCORSAllowedOrigins),
+				TracingEnabled:         c.config.GetBool(optionNameTracingEnabled),
+				TracingEndpoint:        c.config.GetString(optionNameTracingEndpoint),
+				TracingServiceName:     c.config.GetString(optionNameTracingServiceName),
+				ReceiptDepthCheck:      c.config.GetBool(optionNameReceiptDepthCheck),
+				BootnodeRefresh:        c.config.GetDuration(optionNameBootnodeRefresh),
+				ReplicationFactor:      c.config.GetInt(optionNameReplicationFactor),
+				RetryPolicy:            c.config.GetString(optionNameRetryPolicy),
+				RetryDelay:             c.config.GetDuration(optionNameRetryDelay),
+				RetryMaxDelay:          c.config.GetDuration(optionNameRetryMaxDelay),
+				DisablePullSync:        c.config.GetBool(optionNamePullSyncDisable),
+				WarmupTime:             c.config.GetDuration(optionNameWarmupTime),
+				DisableHive:            c.config.GetBool(optionNameHiveDisable),
+				LightNode:              c.config.GetBool(optionNameLightNode),
+				SplitterWorkers:        c.config.GetInt(optionNameSplitterWorkers),
+				SlowPutThreshold:       c.config.GetDuration(optionNameSlowPut),
+				SlowReceiptThreshold:   c.config.GetDuration(optionNameSlowReceipt),
+				SlowDialThreshold:      c.config.GetDuration(optionNameSlowDial),
+				ManifestPrefetch:       c.config.GetInt(optionNameManifestPrefetch),
+				ResolverEndpoint:       c.config.GetString(optionNameResolverEndpoint),
+				ResolverCacheTTL:       c.config.GetDuration(optionNameResolverCacheTTL),
+				ResolverNegativeTTL:    c.config.GetDuration(optionNameResolverNegativeTTL),
+				AllowedOverlays:        c.config.GetStringSlice(optionNameAllowedOverlays),
+				AllowedUnderlays:       c.config.GetStringSlice(optionNameAllowedUnderlays),
+				DeniedOverlays:         c.config.GetStringSlice(optionNameDeniedOverlays),
+				BandwidthLimits:        c.config.GetStringSlice(optionNameBandwidthLimits),
+				VersionOverrides:       c.config.GetStringSlice(optionNameVersionOverrides),
+				InboundIPLimit:         c.config.GetInt(optionNameInboundIPLimit),
+				InboundSubnetLimit:     c.config.GetInt(optionNameInboundSubnetLimit),
+				PaymentThreshold:       c.config.GetUint64(optionNamePaymentThreshold),
+				PaymentTolerance:       c.config.GetUint64(optionNamePaymentTolerance),
+				PaymentRefreshRate:     c.config.GetUint64(optionNamePaymentRefreshRate),
+				TestStorageLatency:     c.config.GetDuration(optionNameTestStorageLatency),
+				TestStorageJitter:      c.config.GetDuration(optionNameTestStorageJitter),
+				TestStorageErrorRate:   c.config.GetFloat64(optionNameTestStorageErrorRate),
+				Logger:                 logger,
+				ConfigLoader: func() (o node.ReloadOptions, err error) {
+					// the values of the options that are set by the
+					// command line flags are not changed
+					if err := c.reloadConfig(); err != nil {
+						return o, err
+					}
+					o.LogLevel, err = verbosityLevel(c.config.GetString(optionNameVerbosity))
+					if err != nil {
+						return o, err
+					}
+					o.LogComponentLevels, err = componentLevels(c.config.GetStringSlice(optionNameLogComponentLevels))
+					if err != nil {
+						return o, err
+					}
+					o.CORSAllowedOrigins = c.config.GetStringSlice(optionCORSAllowedOrigins)
+					o.APIUploadConcurrency = c.config.GetInt(optionNameAPIUploadConcurrency)
+					o.APIDownloadConcurrency = c.config.GetInt(optionNameAPIDownloadConcurrency)
+					o.DebugAPIConcurrency = c.config.GetInt(optionNameDebugAPIConcurrency)
+					o.DebugAPICORSOrigins = c.config.GetStringSlice(optionDebugAPICORSAllowedOrigins)
+					o.WelcomeMessage = c.config.GetString(optionWelcomeMessage)
+					o.DeniedOverlays = c.config.GetStringSlice(optionNameDeniedOverlays)
+					return o, nil
+				},
 			})
 			if err != nil {
 				return err
 			}
 
+			// Reload the configuration on the hangup signal.
+			hangupChannel := make(chan os.Signal, 1)
+			signal.Notify(hangupChannel, syscall.SIGHUP)
+			defer signal.Stop(hangupChannel)
+			go func() {
+				for range hangupChannel {
+					if _, err := b.ReloadConfig(); err != nil {
+						logger.Errorf("reload configuration: %v", err)
+					}
+				}
+			}()
+
 			// Wait for termination or interrupt signals.
 			// We want to clean up things at the end.
 			interruptChannel := make(chan os.Signal, 1)
 			signal.Notify(interruptChannel, syscall.SIGINT, syscall.SIGTERM)
 
-			// Block main goroutine until it is interrupted
-			sig := <-interruptChannel
-
-			logger.Debugf("received signal: %v", sig)
-			logger.Info("shutting down")
+			// The node is shut down once the context is canceled, or if
+			// any of its servers fails.
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
 
-			// Shutdown
-			done := make(chan struct{})
+			done := make(chan error, 1)
 			go func() {
-				defer close(done)
-
-				ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-				defer cancel()
-
-				if err := b.Shutdown(ctx); err != nil {
-					logger.Errorf("shutdown: %v", err)
-				}
+				done <- b.Run(ctx)
 			}()
 
+			select {
+			case sig := <-interruptChannel:
+				logger.Debugf("received signal: %v", sig)
+				logger.Info("shutting down")
+				cancel()
+			case err := <-done:
+				return err
+			}
+
 			// If shutdown function is blocking too long,
 			// allow process termination by receiving another signal.
 			select {
 			case sig := <-interruptChannel:
 				logger.Debugf("received signal: %v", sig)
-			case <-done:
+			case err := <-done:
+				if err != nil {
+					logger.Errorf("shutdown: %v", err)
+				}
 			}
 
 			return nil
@@ -175,24 +302,122 @@ Welcome to the Swarm.... Bzzz Bzzzz Bzzzz
 
 	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
 	cmd.Flags().Uint64(optionNameDBCapacity, 5000000, fmt.Sprintf("db capacity in chunks, multiply by %d to get approximate capacity in bytes", swarm.ChunkSize))
+	cmd.Flags().Uint64(optionNameDBCapacityBytes, 0, "db capacity in bytes of chunk data, overrides db-capacity if set")
+	cmd.Flags().Bool(optionNameDBMigrationDryRun, false, "stop the node with the report of pending db schema migrations instead of running them")
+	cmd.Flags().Bool(optionNameDBRebase, false, "reindex the db if it was used with a different overlay address, or resume an interrupted reindexing")
+	cmd.Flags().Bool(optionNameDBAutoCapacity, false, "derive db capacity from the free disk space periodically, overrides db-capacity and db-capacity-bytes")
+	cmd.Flags().Uint64(optionNameDBDiskReserve, 10, "percentage of the disk size left free with db-auto-capacity")
+	cmd.Flags().String(optionNameMirrorDir, "", "directory to which locally stored chunks are mirrored for disaster recovery, such as a mounted object store bucket, mirroring is disabled if not set")
 	cmd.Flags().String(optionNamePassword, "", "password for decrypting keys")
 	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
 	cmd.Flags().String(optionNameAPIAddr, ":8080", "HTTP API listen address")
 	cmd.Flags().String(optionNameP2PAddr, ":7070", "P2P listen address")
 	cmd.Flags().String(optionNameNATAddr, "", "NAT exposed address")
+	cmd.Flags().String(optionNameNAT6Addr, "", "NAT exposed address advertised to peers connected over IPv6")
+	cmd.Flags().String(optionNameProxyAddr, "", "SOCKS5 proxy address to dial peers and resolve bootnode names through, the node does not listen and its address is not advertised")
 	cmd.Flags().Bool(optionNameP2PWSEnable, false, "enable P2P WebSocket transport")
 	cmd.Flags().Bool(optionNameP2PQUICEnable, false, "enable P2P QUIC transport")
 	cmd.Flags().StringSlice(optionNameBootnodes, []string{"/dnsaddr/bootnode.ethswarm.org"}, "initial nodes to connect to")
 	cmd.Flags().Bool(optionNameDebugAPIEnable, false, "enable debug HTTP API")
+	cmd.Flags().String(optionNameDebugAPIReadToken, "", "bearer token that authorizes read-only debug HTTP API requests if the API is restricted")
+	cmd.Flags().Bool(optionNameDebugAPIRestricted, false, "require the read or the admin token for debug HTTP API requests, except health, readiness and metrics")
+	cmd.Flags().Bool(optionNameDebugAPIProfiling, false, "serve pprof profiles and expvar variables under /debug on the debug HTTP API")
 	cmd.Flags().String(optionNameDebugAPIAddr, ":6060", "debug HTTP API listen address")
+	cmd.Flags().String(optionNameDebugAPIAdminToken, "", "bearer token that authorizes debug HTTP API requests that change the state of the node or use the node key, they are all forbidden if not set")
+	cmd.Flags().Int(optionNameAPIUploadConcurrency, 0, "number of HTTP API upload requests handled at the same time, others are rejected with 429 status, 0 for no limit")
+	cmd.Flags().Int(optionNameAPIDownloadConcurrency, 0, "number of HTTP API download requests handled at the same time, others are rejected with 429 status, 0 for no limit")
+	cmd.Flags().Int(optionNameDebugAPIConcurrency, 0, "number of debug HTTP API requests handled at the same time, others are rejected with 429 status, 0 for no limit")
+	cmd.Flags().Int(optionNameReadinessMinPeers, 0, "number of connected peers below which the node is reported as not ready by the debug HTTP API")
 	cmd.Flags().Uint64(optionNameNetworkID, 1, "ID of the Swarm network")
-	cmd.Flags().StringSlice(optionCORSAllowedOrigins, []string{}, "origins with CORS headers enabled")
+	cmd.Flags().StringSlice(optionCORSAllowedOrigins, []string{}, "origins with CORS headers enabled, \"*\" enables all origins without credentials")
+	cmd.Flags().StringSlice(optionDebugAPICORSAllowedOrigins, []string{}, "origins with CORS headers enabled on the debug HTTP API, \"*\" enables all origins without credentials")
 	cmd.Flags().Bool(optionNameTracingEnabled, false, "enable tracing")
 	cmd.Flags().String(optionNameTracingEndpoint, "127.0.0.1:6831", "endpoint to send tracing data")
 	cmd.Flags().String(optionNameTracingServiceName, "bee", "service name identifier for tracing")
-	cmd.Flags().String(optionNameVerbosity, "info", "log verbosity level 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=trace")
+	cmd.Flags().String(optionNameVerbosity, "info", "log verbosity level 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=trace, reloaded from the config file on the SIGHUP signal")
+	cmd.Flags().StringSlice(optionNameLogComponentLevels, []string{}, "log verbosity levels of the components that override the verbosity, as component=level, such as pushsync=debug, reloaded from the config file on the SIGHUP signal")
+	cmd.Flags().String(optionNameLogFormat, string(logging.FormatText), "log format, text or json")
 	cmd.Flags().String(optionWelcomeMessage, "", "send a welcome message string during handshakes")
+	cmd.Flags().Bool(optionNameReceiptDepthCheck, false, "accept push sync receipts only if signed by storers within the storage depth")
+	cmd.Flags().Duration(optionNameBootnodeRefresh, 5*time.Minute, "interval to resolve and connect to bootnodes again when there are no connected peers, 0 to disable")
+	cmd.Flags().Int(optionNameReplicationFactor, 0, "number of closest neighbours to replicate stored chunks to")
+	cmd.Flags().String(optionNameRetryPolicy, "", "retry policy for pushing chunks and connecting to peers: constant, exponential or jitter; the defaults of each service are used if not set")
+	cmd.Flags().Duration(optionNameRetryDelay, 10*time.Second, "base delay of the retry policy")
+	cmd.Flags().Duration(optionNameRetryMaxDelay, 10*time.Minute, "maximal delay of the exponential and jitter retry policies")
+	cmd.Flags().Bool(optionNamePullSyncDisable, false, "disable syncing chunks with the pull sync protocol")
+	cmd.Flags().Duration(optionNameWarmupTime, 0, "maximal duration to sync the historical chunks within depth before serving retrieval requests, 0 to disable")
+	cmd.Flags().Bool(optionNameHiveDisable, false, "disable the hive protocol that exchanges peer addresses with connected peers")
+	cmd.Flags().Bool(optionNameLightNode, false, "run a light node that does not store the chunks of its neighborhood and does not sync with peers")
+	cmd.Flags().Int(optionNameSplitterWorkers, 0, "number of chunks of uploaded data hashed and stored concurrently, the chunks are processed sequentially if 0")
+	cmd.Flags().Duration(optionNameSlowPut, 200*time.Millisecond, "duration above which storing chunks in the local store is logged as slow, 0 to disable")
+	cmd.Flags().Duration(optionNameSlowReceipt, 5*time.Second, "duration above which waiting for a push sync receipt is logged as slow, 0 to disable")
+	cmd.Flags().Duration(optionNameSlowDial, 10*time.Second, "duration above which dialing a peer is logged as slow, 0 to disable")
+	cmd.Flags().Int(optionNameManifestPrefetch, 0, "number of the first chunks of the style sheets and scripts of a manifest retrieved in the background when its web page is served, 0 to disable")
+	cmd.Flags().String(optionNameResolverEndpoint, "", "Ethereum JSON-RPC endpoint to resolve the ENS names requested under /bzz with, names are not resolved if not set")
+	cmd.Flags().Duration(optionNameResolverCacheTTL, 10*time.Minute, "duration for which the resolved names are cached")
+	cmd.Flags().Duration(optionNameResolverNegativeTTL, time.Minute, "duration for which the names that are not found are cached, negative to disable")
+
+	cmd.Flags().StringSlice(optionNameAllowedOverlays, []string{}, "overlay addresses of the only peers to connect with in a closed network, all peers are allowed if neither these nor allowed underlays are set")
+	cmd.Flags().StringSlice(optionNameDeniedOverlays, []string{}, "overlay addresses of the peers not to connect with, reloaded from the config file on the SIGHUP signal")
+	cmd.Flags().StringSlice(optionNameAllowedUnderlays, []string{}, "underlay multiaddresses with peer IDs of the only peers to connect with in a closed network")
+	cmd.Flags().StringSlice(optionNameBandwidthLimits, []string{}, "bandwidth limits of the protocols in bytes per second for each direction, as protocol=limit, such as pullsync=1048576")
+	cmd.Flags().StringSlice(optionNameVersionOverrides, []string{}, "newer protocol versions requested from some peers, as protocol=version:selector, where selector is a peer overlay address or a percentage of peers, such as pushsync=1.1.0:10%")
+	cmd.Flags().Int(optionNameInboundIPLimit, 0, "maximal number of peers with inbound connections from the same IP address, 0 for no limit")
+	cmd.Flags().Int(optionNameInboundSubnetLimit, 0, "maximal number of peers with inbound connections from the same /24 IPv4 or /48 IPv6 subnet, 0 for no limit")
+	cmd.Flags().Uint64(optionNamePaymentThreshold, 0, "debt to a peer over which no more chunks are pushed to it, 0 to disable the payment and disconnect thresholds")
+	cmd.Flags().Uint64(optionNamePaymentTolerance, 0, "debt of a peer over the payment threshold at which the peer is disconnected")
+	cmd.Flags().Uint64(optionNamePaymentRefreshRate, 0, "amount per second up to which the debts of peers are cleared with pseudo settlements, 0 to clear them in full")
+
+	// the test flags slow down or fail the local store operations to test
+	// the protocols against a slow disk, and are not shown in the help
+	cmd.Flags().Duration(optionNameTestStorageLatency, 0, "delay of every local store operation, for testing only")
+	cmd.Flags().Duration(optionNameTestStorageJitter, 0, "maximal random delay added to the test storage latency, for testing only")
+	cmd.Flags().Float64(optionNameTestStorageErrorRate, 0, "fraction of the failed local store operations from 0 to 1, for testing only")
+	for _, name := range []string{optionNameTestStorageLatency, optionNameTestStorageJitter, optionNameTestStorageErrorRate} {
+		if err := cmd.Flags().MarkHidden(name); err != nil {
+			return err
+		}
+	}
 
 	c.root.AddCommand(cmd)
 	return nil
 }
+
+// componentLevels returns the log levels by the components of the
+// component=verbosity option values.
+func componentLevels(values []string) (map[string]logrus.Level, error) {
+	levels := make(map[string]logrus.Level)
+	for _, v := range values {
+		i := strings.Index(v, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("log component level %q: missing component name", v)
+		}
+		level, err := verbosityLevel(v[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("log component level %q: %w", v, err)
+		}
+		levels[v[:i]] = level
+	}
+	return levels, nil
+}
+
+// verbosityLevel returns the log level of the verbosity option value, which
+// is the panic level for the silent verbosity.
+func verbosityLevel(verbosity string) (logrus.Level, error) {
+	switch v := strings.ToLower(verbosity); v {
+	case "0", "silent":
+		return logrus.PanicLevel, nil
+	case "1", "error":
+		return logrus.ErrorLevel, nil
+	case "2", "warn":
+		return logrus.WarnLevel, nil
+	case "3", "info":
+		return logrus.InfoLevel, nil
+	case "4", "debug":
+		return logrus.DebugLevel, nil
+	case "5", "trace":
+		return logrus.TraceLevel, nil
+	default:
+		return 0, fmt.Errorf("unknown verbosity level %q", v)
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package accounting provides the hooks for keeping track of the balances
+// with peers for the services that are exchanged with them.
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var (
+	// ErrOverdraft is returned by Reserve if the debt to the peer would
+	// exceed the payment threshold.
+	ErrOverdraft = errors.New("attempted overdraft")
+	// ErrDisconnectThresholdExceeded is wrapped into the p2p disconnect
+	// error returned by Debit if the debt of the peer exceeds the payment
+	// threshold and the payment tolerance.
+	ErrDisconnectThresholdExceeded = errors.New("disconnect threshold exceeded")
+	// ErrOverpayment is returned by NotifyPayment if the peer pays more
+	// than it owes.
+	ErrOverpayment = errors.New("attempted overpayment")
+)
+
+// Interface is the accounting of the services exchanged with peers. A
+// positive balance means that the peer owes to this node, and a negative
+// balance that this node owes to the peer.
+type Interface interface {
+	// Reserve is called before a service is requested from the peer, so
+	// that the concurrent requests can not bring the debt to the peer over
+	// the payment threshold. Every successful Reserve must be followed by a
+	// Release with the same price.
+	Reserve(ctx context.Context, peer swarm.Address, price uint64) error
+	// Release releases the price reserved with Reserve.
+	Release(peer swarm.Address, price uint64)
+	// Credit is called when a service has been received from the peer.
+	Credit(peer swarm.Address, price uint64) error
+	// Debit is called when a service has been provided to the peer.
+	Debit(peer swarm.Address, price uint64) error
+	// Balance returns the current balance with the peer.
+	Balance(peer swarm.Address) (int64, error)
+	// Balances returns the balances with all peers, keyed by the hex
+	// encoded peer address.
+	Balances() (map[string]int64, error)
+}
+
+const balancesPrefix = "balance_"
+
+var (
+	_ Interface                  = (*Accounting)(nil)
+	_ settlement.PaymentObserver = (*Accounting)(nil)
+)
+
+// Accounting keeps the balances with peers in the state store.
+type Accounting struct {
+	store            storage.StateStorer
+	paymentThreshold uint64
+	paymentTolerance uint64
+	settlement       settlement.Interface
+	logger           logging.Logger
+	metrics          metrics
+
+	peers   map[string]*peerAccounting
+	peersMu sync.Mutex
+}
+
+// peerAccounting serializes the balance changes with a single peer.
+type peerAccounting struct {
+	mu       sync.Mutex
+	reserved uint64
+}
+
+// Options are the options for the Accounting.
+type Options struct {
+	Store storage.StateStorer
+	// PaymentThreshold is the debt to a peer over which no more services are
+	// requested from it. Zero disables the limit.
+	PaymentThreshold uint64
+	// PaymentTolerance is the debt of a peer over the payment threshold at
+	// which the peer is disconnected. The peer is never disconnected if the
+	// payment threshold is zero.
+	PaymentTolerance uint64
+	// Settlement settles the debt to a peer when it would exceed the payment
+	// threshold. The debts are not settled if it is not set.
+	Settlement settlement.Interface
+	Logger     logging.Logger
+}
+
+// New returns a new Accounting.
+func New(o Options) *Accounting {
+	return &Accounting{
+		store:            o.Store,
+		paymentThreshold: o.PaymentThreshold,
+		paymentTolerance: o.PaymentTolerance,
+		settlement:       o.Settlement,
+		logger:           o.Logger,
+		metrics:          newMetrics(),
+		peers:            make(map[string]*peerAccounting),
+	}
+}
+
+// Reserve reserves the price of a service that is requested from the peer.
+// If the debt to the peer with all reserved prices would exceed the payment
+// threshold, the current debt is settled first. It returns ErrOverdraft if
+// the debt would still exceed the payment threshold.
+func (a *Accounting) Reserve(ctx context.Context, peer swarm.Address, price uint64) error {
+	pa := a.peer(peer)
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	balance, err := a.balance(peer)
+	if err != nil {
+		return err
+	}
+
+	if a.paymentThreshold > 0 {
+		// the expected debt is the current debt with all reserved prices
+		debt := int64(pa.reserved+price) - balance
+		if debt > int64(a.paymentThreshold) && balance < 0 && a.settlement != nil {
+			if balance, err = a.settle(ctx, peer, balance); err != nil {
+				return fmt.Errorf("settle with peer %s: %w", peer, err)
+			}
+			debt = int64(pa.reserved+price) - balance
+		}
+		if debt > int64(a.paymentThreshold) {
+			a.metrics.OverdraftCount.Inc()
+			return ErrOverdraft
+		}
+	}
+
+	pa.reserved += price
+	return nil
+}
+
+// settle pays the debt of the negative balance to the peer and returns the
+// balance reduced by the amount that the peer accepted. It must be called
+// with the peer lock held.
+func (a *Accounting) settle(ctx context.Context, peer swarm.Address, balance int64) (int64, error) {
+	accepted, err := a.settlement.Pay(ctx, peer, uint64(-balance))
+	if err != nil {
+		return 0, err
+	}
+	if accepted == 0 {
+		return balance, nil
+	}
+
+	balance += int64(accepted)
+	if err := a.store.Put(balanceKey(peer), balance); err != nil {
+		return 0, fmt.Errorf("store balance: %w", err)
+	}
+	a.metrics.SettlementCount.Inc()
+	a.logger.Tracef("accounting: settled %d with peer %s, new balance is %d", accepted, peer, balance)
+
+	return balance, nil
+}
+
+// NotifyPayment decreases the debt of the peer by the amount that the peer
+// paid. It returns ErrOverpayment if the amount is greater than the debt.
+func (a *Accounting) NotifyPayment(peer swarm.Address, amount uint64) error {
+	pa := a.peer(peer)
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	balance, err := a.balance(peer)
+	if err != nil {
+		return err
+	}
+	if balance < 0 || amount > uint64(balance) {
+		return ErrOverpayment
+	}
+
+	balance -= int64(amount)
+	if err := a.store.Put(balanceKey(peer), balance); err != nil {
+		return fmt.Errorf("store balance for peer %s: %w", peer, err)
+	}
+	a.logger.Tracef("accounting: peer %s paid %d, new balance is %d", peer, amount, balance)
+
+	return nil
+}
+
+// Release releases the price reserved with Reserve.
+func (a *Accounting) Release(peer swarm.Address, price uint64) {
+	pa := a.peer(peer)
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	if price > pa.reserved {
+		a.logger.Errorf("accounting: release of %d for peer %s exceeds reserved %d", price, peer, pa.reserved)
+		pa.reserved = 0
+		return
+	}
+	pa.reserved -= price
+}
+
+// Credit decreases the balance with the peer by the price of the service
+// received from it.
+func (a *Accounting) Credit(peer swarm.Address, price uint64) error {
+	pa := a.peer(peer)
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	balance, err := a.balance(peer)
+	if err != nil {
+		return err
+	}
+
+	balance -= int64(price)
+	if err := a.store.Put(balanceKey(peer), balance); err != nil {
+		return fmt.Errorf("store balance for peer %s: %w", peer, err)
+	}
+	a.metrics.CreditCount.Inc()
+	a.logger.Tracef("accounting: crediting peer %s with price %d, new balance is %d", peer, price, balance)
+
+	return nil
+}
+
+// Debit increases the balance with the peer by the price of the service
+// provided to it. It returns a p2p disconnect error if the debt of the peer
+// exceeds the payment threshold and the payment tolerance.
+func (a *Accounting) Debit(peer swarm.Address, price uint64) error {
+	pa := a.peer(peer)
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	balance, err := a.balance(peer)
+	if err != nil {
+		return err
+	}
+
+	balance += int64(price)
+	if err := a.store.Put(balanceKey(peer), balance); err != nil {
+		return fmt.Errorf("store balance for peer %s: %w", peer, err)
+	}
+	a.metrics.DebitCount.Inc()
+	a.logger.Tracef("accounting: debiting peer %s with price %d, new balance is %d", peer, price, balance)
+
+	if a.paymentThreshold > 0 && balance >= int64(a.paymentThreshold+a.paymentTolerance) {
+		a.metrics.DisconnectCount.Inc()
+		return p2p.NewDisconnectError(fmt.Errorf("peer %s balance %d: %w", peer, balance, ErrDisconnectThresholdExceeded))
+	}
+
+	return nil
+}
+
+// Balance returns the current balance with the peer.
+func (a *Accounting) Balance(peer swarm.Address) (int64, error) {
+	pa := a.peer(peer)
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	return a.balance(peer)
+}
+
+// Balances returns the balances with all peers, keyed by the hex encoded
+// peer address.
+func (a *Accounting) Balances() (map[string]int64, error) {
+	balances := make(map[string]int64)
+	err := a.store.Iterate(balancesPrefix, func(key, value []byte) (stop bool, err error) {
+		peer, err := swarm.ParseHexAddress(strings.TrimPrefix(string(key), balancesPrefix))
+		if err != nil {
+			return true, fmt.Errorf("parse balance key %q: %w", key, err)
+		}
+
+		var balance int64
+		if err := json.Unmarshal(value, &balance); err != nil {
+			return true, err
+		}
+
+		balances[peer.String()] = balance
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// balance returns the stored balance with the peer. It must be called with
+// the peer lock held.
+func (a *Accounting) balance(peer swarm.Address) (balance int64, err error) {
+	err = a.store.Get(balanceKey(peer), &balance)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("get balance for peer %s: %w", peer, err)
+	}
+	return balance, nil
+}
+
+func (a *Accounting) peer(peer swarm.Address) *peerAccounting {
+	a.peersMu.Lock()
+	defer a.peersMu.Unlock()
+
+	pa, ok := a.peers[peer.String()]
+	if !ok {
+		pa = new(peerAccounting)
+		a.peers[peer.String()] = pa
+	}
+	return pa
+}
+
+func balanceKey(peer swarm.Address) string {
+	return balancesPrefix + peer.String()
+}
+
+var _ Interface = (*noop)(nil)
+
+type noop struct{}
+
+// NewNoop returns an accounting that does not keep track of any balances.
+func NewNoop() Interface {
+	return noop{}
+}
+
+func (noop) Reserve(_ context.Context, _ swarm.Address, _ uint64) error { return nil }
+
+func (noop) Release(_ swarm.Address, _ uint64) {}
+
+func (noop) Credit(_ swarm.Address, _ uint64) error { return nil }
+
+func (noop) Debit(_ swarm.Address, _ uint64) error { return nil }
+
+func (noop) Balance(_ swarm.Address) (int64, error) { return 0, nil }
+
+func (noop) Balances() (map[string]int64, error) { return map[string]int64{}, nil }
@@ -0,0 +1,217 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accounting_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var (
+	peer1 = swarm.MustParseHexAddress("00112233")
+	peer2 = swarm.MustParseHexAddress("00112244")
+)
+
+func TestAccountingBalances(t *testing.T) {
+	store := statestore.NewStateStore()
+	acc := newAccounting(store, 0, 0)
+
+	if err := acc.Credit(peer1, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := acc.Debit(peer1, 30); err != nil {
+		t.Fatal(err)
+	}
+	if err := acc.Debit(peer2, 50); err != nil {
+		t.Fatal(err)
+	}
+	assertBalance(t, acc, peer1, -70)
+	assertBalance(t, acc, peer2, 50)
+
+	// the balances are persisted in the state store
+	acc = newAccounting(store, 0, 0)
+	assertBalance(t, acc, peer1, -70)
+
+	balances, err := acc.Balances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 2 || balances[peer1.String()] != -70 || balances[peer2.String()] != 50 {
+		t.Fatalf("got balances %v", balances)
+	}
+}
+
+func TestAccountingReserve(t *testing.T) {
+	acc := newAccounting(statestore.NewStateStore(), 100, 0)
+
+	if err := acc.Reserve(context.Background(), peer1, 60); err != nil {
+		t.Fatal(err)
+	}
+	// the reserved prices are part of the expected debt
+	if err := acc.Reserve(context.Background(), peer1, 50); !errors.Is(err, accounting.ErrOverdraft) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrOverdraft)
+	}
+	if err := acc.Reserve(context.Background(), peer2, 50); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acc.Credit(peer1, 60); err != nil {
+		t.Fatal(err)
+	}
+	acc.Release(peer1, 60)
+	if err := acc.Reserve(context.Background(), peer1, 50); !errors.Is(err, accounting.ErrOverdraft) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrOverdraft)
+	}
+	if err := acc.Reserve(context.Background(), peer1, 40); err != nil {
+		t.Fatal(err)
+	}
+	acc.Release(peer1, 40)
+
+	// the services provided to the peer decrease the debt to it
+	if err := acc.Debit(peer1, 60); err != nil {
+		t.Fatal(err)
+	}
+	if err := acc.Reserve(context.Background(), peer1, 100); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAccountingDisconnect(t *testing.T) {
+	acc := newAccounting(statestore.NewStateStore(), 100, 20)
+
+	if err := acc.Debit(peer1, 119); err != nil {
+		t.Fatal(err)
+	}
+
+	err := acc.Debit(peer1, 1)
+	var de *p2p.DisconnectError
+	if !errors.As(err, &de) {
+		t.Fatalf("got error %v, want disconnect error", err)
+	}
+	if !errors.Is(err, accounting.ErrDisconnectThresholdExceeded) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrDisconnectThresholdExceeded)
+	}
+	// the balance is recorded regardless
+	assertBalance(t, acc, peer1, 120)
+}
+
+func TestAccountingNoThreshold(t *testing.T) {
+	acc := newAccounting(statestore.NewStateStore(), 0, 0)
+
+	if err := acc.Reserve(context.Background(), peer1, 1<<40); err != nil {
+		t.Fatal(err)
+	}
+	acc.Release(peer1, 1<<40)
+	if err := acc.Debit(peer1, 1<<40); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAccountingSettlement(t *testing.T) {
+	ctx := context.Background()
+	store := statestore.NewStateStore()
+
+	var paid uint64
+	acc := accounting.New(accounting.Options{
+		Store:            store,
+		PaymentThreshold: 100,
+		Settlement: payFunc(func(_ context.Context, peer swarm.Address, amount uint64) (uint64, error) {
+			if !peer.Equal(peer1) {
+				t.Fatalf("got payment to peer %s, want %s", peer, peer1)
+			}
+			paid += amount
+			// only a part of the debt is accepted
+			return amount / 2, nil
+		}),
+		Logger: logging.New(ioutil.Discard, 0),
+	})
+
+	if err := acc.Credit(peer1, 80); err != nil {
+		t.Fatal(err)
+	}
+	if err := acc.Reserve(ctx, peer1, 10); err != nil {
+		t.Fatal(err)
+	}
+	acc.Release(peer1, 10)
+	if paid != 0 {
+		t.Fatalf("got paid %d under the payment threshold", paid)
+	}
+
+	// the debt of 80 is settled and the accepted 40 is enough for the price
+	if err := acc.Reserve(ctx, peer1, 50); err != nil {
+		t.Fatal(err)
+	}
+	acc.Release(peer1, 50)
+	if paid != 80 {
+		t.Fatalf("got paid %d, want %d", paid, 80)
+	}
+	assertBalance(t, acc, peer1, -40)
+
+	// the debt of 40 is settled, but the remaining 20 with the price is
+	// still over the payment threshold
+	if err := acc.Reserve(ctx, peer1, 90); !errors.Is(err, accounting.ErrOverdraft) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrOverdraft)
+	}
+	assertBalance(t, acc, peer1, -20)
+}
+
+func TestAccountingNotifyPayment(t *testing.T) {
+	acc := newAccounting(statestore.NewStateStore(), 100, 0)
+
+	if err := acc.Debit(peer1, 50); err != nil {
+		t.Fatal(err)
+	}
+	if err := acc.NotifyPayment(peer1, 30); err != nil {
+		t.Fatal(err)
+	}
+	assertBalance(t, acc, peer1, 20)
+
+	if err := acc.NotifyPayment(peer1, 30); !errors.Is(err, accounting.ErrOverpayment) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrOverpayment)
+	}
+	assertBalance(t, acc, peer1, 20)
+}
+
+// payFunc is the settlement that only pays, with the function.
+type payFunc func(ctx context.Context, peer swarm.Address, amount uint64) (uint64, error)
+
+func (f payFunc) Pay(ctx context.Context, peer swarm.Address, amount uint64) (uint64, error) {
+	return f(ctx, peer, amount)
+}
+
+func (payFunc) TotalSent(swarm.Address) (uint64, error)         { return 0, nil }
+func (payFunc) TotalReceived(swarm.Address) (uint64, error)     { return 0, nil }
+func (payFunc) SettlementsSent() (map[string]uint64, error)     { return nil, nil }
+func (payFunc) SettlementsReceived() (map[string]uint64, error) { return nil, nil }
+
+func newAccounting(store storage.StateStorer, paymentThreshold, paymentTolerance uint64) *accounting.Accounting {
+	return accounting.New(accounting.Options{
+		Store:            store,
+		PaymentThreshold: paymentThreshold,
+		PaymentTolerance: paymentTolerance,
+		Logger:           logging.New(ioutil.Discard, 0),
+	})
+}
+
+func assertBalance(t *testing.T, acc accounting.Interface, peer swarm.Address, want int64) {
+	t.Helper()
+
+	got, err := acc.Balance(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got balance %d for peer %s, want %d", got, peer, want)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accounting
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	// all metrics fields must be exported
+	// to be able to return them by Metrics()
+	// using reflection
+	CreditCount     prometheus.Counter
+	DebitCount      prometheus.Counter
+	OverdraftCount  prometheus.Counter
+	DisconnectCount prometheus.Counter
+	SettlementCount prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "accounting"
+
+	return metrics{
+		CreditCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "credit_count",
+			Help:      "Number of services received from peers.",
+		}),
+		DebitCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "debit_count",
+			Help:      "Number of services provided to peers.",
+		}),
+		OverdraftCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "overdraft_count",
+			Help:      "Number of services not requested because the payment threshold would be exceeded.",
+		}),
+		DisconnectCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "disconnect_count",
+			Help:      "Number of peers disconnected for exceeding the disconnect threshold.",
+		}),
+		SettlementCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "settlement_count",
+			Help:      "Number of debts to peers that are settled.",
+		}),
+	}
+}
+
+// Metrics returns the metrics of the Accounting.
+func (a *Accounting) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(a.metrics)
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ accounting.Interface = (*Accounting)(nil)
+
+// Accounting keeps the balances with peers in memory. A positive balance
+// means that the peer owes to this node.
+type Accounting struct {
+	balances   map[string]int64
+	balancesMu sync.Mutex
+}
+
+func NewAccounting() *Accounting {
+	return &Accounting{
+		balances: make(map[string]int64),
+	}
+}
+
+func (a *Accounting) Reserve(_ context.Context, _ swarm.Address, _ uint64) error {
+	return nil
+}
+
+func (a *Accounting) Release(_ swarm.Address, _ uint64) {}
+
+func (a *Accounting) Credit(peer swarm.Address, price uint64) error {
+	a.balancesMu.Lock()
+	defer a.balancesMu.Unlock()
+
+	a.balances[peer.String()] -= int64(price)
+	return nil
+}
+
+func (a *Accounting) Debit(peer swarm.Address, price uint64) error {
+	a.balancesMu.Lock()
+	defer a.balancesMu.Unlock()
+
+	a.balances[peer.String()] += int64(price)
+	return nil
+}
+
+// Balance returns the current balance with the peer.
+func (a *Accounting) Balance(peer swarm.Address) (int64, error) {
+	a.balancesMu.Lock()
+	defer a.balancesMu.Unlock()
+
+	return a.balances[peer.String()], nil
+}
+
+// Balances returns the balances with all peers.
+func (a *Accounting) Balances() (map[string]int64, error) {
+	a.balancesMu.Lock()
+	defer a.balancesMu.Unlock()
+
+	balances := make(map[string]int64, len(a.balances))
+	for k, v := range a.balances {
+		balances[k] = v
+	}
+	return balances, nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accounting
+
+import (
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// Pricer returns the prices of the chunk related services.
+type Pricer interface {
+	// Price returns the price this node charges for a chunk.
+	Price(chunk swarm.Address) uint64
+	// PeerPrice returns the price the peer charges for a chunk.
+	PeerPrice(peer, chunk swarm.Address) uint64
+}
+
+var _ Pricer = (*FixedPricer)(nil)
+
+// FixedPricer prices a chunk proportionally to the distance between the
+// chunk and the node that provides the service. A node with the chunk in
+// its deepest bin charges poPrice, and every bin farther away adds poPrice
+// to the price.
+type FixedPricer struct {
+	base    swarm.Address
+	poPrice uint64
+}
+
+// NewFixedPricer returns a new FixedPricer for the node with the base address.
+func NewFixedPricer(base swarm.Address, poPrice uint64) *FixedPricer {
+	return &FixedPricer{
+		base:    base,
+		poPrice: poPrice,
+	}
+}
+
+// Price returns the price this node charges for a chunk.
+func (p *FixedPricer) Price(chunk swarm.Address) uint64 {
+	return p.PeerPrice(p.base, chunk)
+}
+
+// PeerPrice returns the price the peer charges for a chunk.
+func (p *FixedPricer) PeerPrice(peer, chunk swarm.Address) uint64 {
+	po := swarm.Proximity(peer.Bytes(), chunk.Bytes())
+	return uint64(swarm.MaxPO-po+1) * p.poPrice
+}
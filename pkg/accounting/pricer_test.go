@@ -0,0 +1,54 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accounting_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestFixedPricer(t *testing.T) {
+	base := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	pricer := accounting.NewFixedPricer(base, 10)
+
+	for _, tc := range []struct {
+		name  string
+		peer  swarm.Address
+		chunk swarm.Address
+		want  uint64
+	}{
+		{
+			name:  "farthest",
+			peer:  base,
+			chunk: swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000"), // po 0
+			want:  uint64(swarm.MaxPO+1) * 10,
+		},
+		{
+			name:  "po 1",
+			peer:  base,
+			chunk: swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000"),
+			want:  uint64(swarm.MaxPO) * 10,
+		},
+		{
+			name:  "closest",
+			peer:  swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000"),
+			chunk: swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000"),
+			want:  10,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := pricer.PeerPrice(tc.peer, tc.chunk); got != tc.want {
+				t.Errorf("got peer price %d, want %d", got, tc.want)
+			}
+		})
+	}
+
+	chunk := swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000")
+	if got, want := pricer.Price(chunk), pricer.PeerPrice(base, chunk); got != want {
+		t.Errorf("got price %d, want %d", got, want)
+	}
+}
@@ -6,40 +6,118 @@ package api
 
 import (
 	"net/http"
+	"sync"
 
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/logging"
 	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/ethersphere/bee/pkg/pinning"
+	"github.com/ethersphere/bee/pkg/receipts"
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/retrieval"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/tags"
 	"github.com/ethersphere/bee/pkg/tracing"
+	"github.com/ethersphere/bee/pkg/uploadkeys"
+	"github.com/ethersphere/bee/pkg/uploadsession"
 )
 
 type Service interface {
 	http.Handler
 	m.Collector
+	// SetCORSAllowedOrigins and SetConcurrency change the options of the
+	// same names while the requests are served.
+	SetCORSAllowedOrigins(origins []string)
+	SetConcurrency(upload, download int)
 }
 
 type server struct {
 	Options
 	http.Handler
-	metrics metrics
+	metrics     metrics
+	prefetchSem chan struct{}
+	// pinLocks serialize the changes of the pin counters of every pinned
+	// reference, so that the counter of the pinned content matches the
+	// counters of its chunks.
+	pinLocks   map[string]*pinLock
+	pinLocksMu sync.Mutex
+	// activeUploadSessions are the ids of the upload sessions that are being
+	// written or deleted, so that a session is changed by one request at a
+	// time.
+	activeUploadSessions   map[string]struct{}
+	activeUploadSessionsMu sync.Mutex
+	uploadLimiter          *jsonhttp.ConcurrencyLimiter
+	downloadLimiter        *jsonhttp.ConcurrencyLimiter
+	cors                   *jsonhttp.CORS
+	downloads              *downloads
 }
 
 type Options struct {
 	Tags               *tags.Tags
 	Storer             storage.Storer
+	Receipts           receipts.Getter
+	Retrieval          retrieval.Interface
+	UploadSessions     uploadsession.Interface
+	UploadKeys         uploadkeys.Interface
+	Pins               pinning.Interface
 	CORSAllowedOrigins []string
-	Logger             logging.Logger
-	Tracer             *tracing.Tracer
+	// Resolver resolves the names, such as the ENS names, requested under
+	// /bzz instead of the addresses. The names are not resolved if it is
+	// not set.
+	Resolver resolver.Interface
+	// SplitterWorkers is the number of chunks of uploaded data that are
+	// hashed and stored concurrently. The chunks are processed sequentially
+	// if it is zero.
+	SplitterWorkers int
+	// ManifestPrefetch is the number of the first chunks of the style
+	// sheets and scripts in a manifest that are retrieved in the background
+	// when a web page of the manifest is served. Assets are not prefetched
+	// if it is zero.
+	ManifestPrefetch int
+	// UploadConcurrency and DownloadConcurrency are the numbers of the
+	// upload and the download requests that are handled at the same time.
+	// Requests are not limited if they are zero.
+	UploadConcurrency   int
+	DownloadConcurrency int
+	Logger              logging.Logger
+	Tracer              *tracing.Tracer
 }
 
 func New(o Options) Service {
 	s := &server{
-		Options: o,
-		metrics: newMetrics(),
+		Options:         o,
+		metrics:         newMetrics(),
+		prefetchSem:     make(chan struct{}, maxPrefetches),
+		uploadLimiter:   jsonhttp.NewConcurrencyLimiter(o.UploadConcurrency, limitRetryAfter),
+		downloadLimiter: jsonhttp.NewConcurrencyLimiter(o.DownloadConcurrency, limitRetryAfter),
+		cors:            jsonhttp.NewCORS(o.CORSAllowedOrigins),
+		downloads:       newDownloads(),
+
+		activeUploadSessions: make(map[string]struct{}),
+		pinLocks:             make(map[string]*pinLock),
 	}
 
 	s.setupRouting()
 
 	return s
 }
+
+func (s *server) SetCORSAllowedOrigins(origins []string) {
+	s.cors.SetAllowedOrigins(origins)
+}
+
+func (s *server) SetConcurrency(upload, download int) {
+	s.uploadLimiter.SetLimit(upload)
+	s.downloadLimiter.SetLimit(download)
+}
+
+// newSplitter returns the splitter of the uploaded data that stores the
+// chunks with the putter.
+func (s *server) newSplitter(putter storage.Putter) file.Splitter {
+	if s.SplitterWorkers > 0 {
+		return splitter.NewPipelineSplitter(putter, s.SplitterWorkers)
+	}
+	return splitter.NewSimpleSplitter(putter)
+}
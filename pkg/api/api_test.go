@@ -14,16 +14,30 @@ import (
 	"github.com/ethersphere/bee/pkg/api"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/pinning"
+	"github.com/ethersphere/bee/pkg/receipts"
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/retrieval"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/uploadkeys"
+	"github.com/ethersphere/bee/pkg/uploadsession"
 	"resenje.org/web"
 )
 
 type testServerOptions struct {
-	Pingpong pingpong.Interface
-	Storer   storage.Storer
-	Tags     *tags.Tags
-	Logger   logging.Logger
+	Pingpong         pingpong.Interface
+	Storer           storage.Storer
+	Receipts         receipts.Getter
+	Retrieval        retrieval.Interface
+	Tags             *tags.Tags
+	UploadSessions   uploadsession.Interface
+	UploadKeys       uploadkeys.Interface
+	Pins             pinning.Interface
+	Resolver         resolver.Interface
+	Logger           logging.Logger
+	SplitterWorkers  int
+	ManifestPrefetch int
 }
 
 func newTestServer(t *testing.T, o testServerOptions) *http.Client {
@@ -31,9 +45,17 @@ func newTestServer(t *testing.T, o testServerOptions) *http.Client {
 		o.Logger = logging.New(ioutil.Discard, 0)
 	}
 	s := api.New(api.Options{
-		Tags:   o.Tags,
-		Storer: o.Storer,
-		Logger: o.Logger,
+		Tags:             o.Tags,
+		Storer:           o.Storer,
+		Receipts:         o.Receipts,
+		Retrieval:        o.Retrieval,
+		UploadSessions:   o.UploadSessions,
+		UploadKeys:       o.UploadKeys,
+		Pins:             o.Pins,
+		Resolver:         o.Resolver,
+		SplitterWorkers:  o.SplitterWorkers,
+		ManifestPrefetch: o.ManifestPrefetch,
+		Logger:           o.Logger,
 	})
 	ts := httptest.NewServer(s)
 	t.Cleanup(ts.Close)
@@ -5,17 +5,14 @@
 package api
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 
 	"github.com/ethersphere/bee/pkg/encryption"
 	"github.com/ethersphere/bee/pkg/file"
 	"github.com/ethersphere/bee/pkg/file/joiner"
-	"github.com/ethersphere/bee/pkg/file/splitter"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -30,14 +27,42 @@ type bytesPostResponse struct {
 func (s *server) bytesUploadHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	tag, err := s.getOrCreateTag(r.Header.Get(TagHeaderUid))
+	if err != nil {
+		s.Logger.Debugf("bytes upload: get or create tag: %v", err)
+		s.Logger.Error("bytes upload: get or create tag")
+		if errors.Is(err, errInvalidTagUid) {
+			jsonhttp.BadRequest(w, "invalid taguid")
+			return
+		}
+		jsonhttp.InternalServerError(w, "cannot create tag")
+		return
+	}
+
 	toEncrypt := strings.ToLower(r.Header.Get(EncryptHeader)) == "true"
-	sp := splitter.NewSimpleSplitter(s.Storer)
-	address, err := file.SplitWriteAll(ctx, sp, r.Body, r.ContentLength, toEncrypt)
+	password, ok := s.uploadKeyPassword(w, r, toEncrypt)
+	if !ok {
+		return
+	}
+	putter := newUploadPutter(s.Storer, tag)
+	address, err := file.SplitWriteAll(ctx, s.newSplitter(putter), r.Body, r.ContentLength, toEncrypt)
 	if err != nil {
 		s.Logger.Debugf("bytes upload: %v", err)
+		s.abortUpload(putter)
 		jsonhttp.InternalServerError(w, nil)
 		return
 	}
+	if err := s.storeUploadKey(address, "", password); err != nil {
+		s.Logger.Debugf("bytes upload: store upload key: %v", err)
+		s.Logger.Error("bytes upload: store upload key")
+		s.abortUpload(putter)
+		jsonhttp.InternalServerError(w, "cannot store upload key")
+		return
+	}
+	putter.complete()
+	tag.DoneSplit(address)
+
+	setTagHeaders(w, tag)
 	jsonhttp.OK(w, bytesPostResponse{
 		Reference: address,
 	})
@@ -57,8 +82,7 @@ func (s *server) bytesGetHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	toDecrypt := len(address.Bytes()) == (swarm.HashSize + encryption.KeyLength)
-	j := joiner.NewSimpleJoiner(s.Storer)
-	dataSize, err := j.Size(ctx, address)
+	reader, _, err := joiner.NewReader(ctx, s.Storer, address, toDecrypt)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
 			s.Logger.Debugf("bytes: not found %s: %v", address, err)
@@ -71,20 +95,9 @@ func (s *server) bytesGetHandler(w http.ResponseWriter, r *http.Request) {
 		jsonhttp.BadRequest(w, "invalid root chunk")
 		return
 	}
+	defer reader.Close()
 
-	outBuffer := bytes.NewBuffer(nil)
-	c, err := file.JoinReadAll(j, address, outBuffer, toDecrypt)
-	if err != nil && c == 0 {
-		s.Logger.Debugf("bytes download: data join %s: %v", address, err)
-		s.Logger.Errorf("bytes download: data join %s", address)
-		jsonhttp.NotFound(w, nil)
-		return
-	}
 	w.Header().Set("ETag", fmt.Sprintf("%q", address))
 	w.Header().Set("Content-Type", "application/octet-stream")
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", dataSize))
-	if _, err = io.Copy(w, outBuffer); err != nil {
-		s.Logger.Debugf("bytes download: data read %s: %v", address, err)
-		s.Logger.Errorf("bytes download: data read %s", address)
-	}
+	s.serveContent(w, r, address, reader)
 }
@@ -6,18 +6,26 @@ package api_test
 
 import (
 	"bytes"
+	"context"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	filetest "github.com/ethersphere/bee/pkg/file/testing"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/localstore"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
-	mockbytes "gitlab.com/nolash/go-mockbytes"
 )
 
 // TestBytes tests that the data upload api responds as expected when uploading,
@@ -33,11 +41,7 @@ func TestBytes(t *testing.T) {
 			Logger: logging.New(ioutil.Discard, 5),
 		})
 	)
-	g := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255)
-	content, err := g.SequentialBytes(swarm.ChunkSize * 2)
-	if err != nil {
-		t.Fatal(err)
-	}
+	content := filetest.GenerateTestData(t, swarm.ChunkSize*2)
 
 	t.Run("upload", func(t *testing.T) {
 		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource, bytes.NewReader(content), http.StatusOK, api.BytesPostResponse{
@@ -57,6 +61,62 @@ func TestBytes(t *testing.T) {
 		}
 	})
 
+	t.Run("download range", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, resource+"/"+expHash, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Range", "bytes=4000-4199")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusPartialContent {
+			t.Fatalf("got response status %s, want %v %s", resp.Status, http.StatusPartialContent, http.StatusText(http.StatusPartialContent))
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(data, content[4000:4200]) {
+			t.Fatalf("data mismatch. got %x, want %x", data, content[4000:4200])
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		emptyHash := "ffd70157e48063fc33c97a050f7f640233bf646cc98d9524c6b92bcf3ab56f83"
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource, bytes.NewReader(nil), http.StatusOK, api.BytesPostResponse{
+			Reference: swarm.MustParseHexAddress(emptyHash),
+		})
+
+		req, err := http.NewRequest(http.MethodGet, resource+"/"+emptyHash, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// disable the compression of the response to receive its length
+		req.Header.Set("Accept-Encoding", "identity")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got response status %s, want %v %s", resp.Status, http.StatusOK, http.StatusText(http.StatusOK))
+		}
+		if resp.ContentLength != 0 {
+			t.Fatalf("got content length %d, want 0", resp.ContentLength)
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 0 {
+			t.Fatalf("got data %x, want none", data)
+		}
+	})
+
 	t.Run("not found", func(t *testing.T) {
 		jsonhttptest.ResponseDirect(t, client, http.MethodGet, resource+"/abcd", nil, http.StatusNotFound, jsonhttp.StatusResponse{
 			Message: "not found",
@@ -64,3 +124,151 @@ func TestBytes(t *testing.T) {
 		})
 	})
 }
+
+// TestBytesPipelineSplitter tests that the data uploaded with the pipeline
+// splitter has the same reference and is downloaded.
+func TestBytesPipelineSplitter(t *testing.T) {
+	var (
+		resource = "/bytes"
+		client   = newTestServer(t, testServerOptions{
+			Storer:          mock.NewStorer(),
+			Tags:            tags.NewTags(),
+			SplitterWorkers: 4,
+		})
+		content, expAddr = filetest.GetVector(t, 17)
+	)
+
+	jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource, bytes.NewReader(content), http.StatusOK, api.BytesPostResponse{
+		Reference: expAddr,
+	})
+
+	resp := request(t, client, http.MethodGet, resource+"/"+expAddr.String(), nil, http.StatusOK)
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Fatal("data mismatch")
+	}
+}
+
+// TestBytesUploadAborted tests that an upload whose client disconnects fails
+// without removing any of the chunks, as they may be shared with other
+// uploads of the same content. The chunks of the upload are discarded
+// instead.
+func TestBytesUploadAborted(t *testing.T) {
+	var (
+		mockStorer = mock.NewStorer()
+		s          = api.New(api.Options{
+			Storer: mockStorer,
+			Tags:   tags.NewTags(),
+			Logger: logging.New(ioutil.Discard, 0),
+		})
+		content = filetest.GenerateTestData(t, swarm.ChunkSize*4)
+	)
+
+	// the addresses of the first two data chunks
+	var addrs []swarm.Address
+	for i := 0; i < 2; i++ {
+		addr, err := file.SplitWriteAll(context.Background(), splitter.NewSimpleSplitter(mock.NewStorer()), bytes.NewReader(content[i*swarm.ChunkSize:(i+1)*swarm.ChunkSize]), swarm.ChunkSize, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs = append(addrs, addr)
+	}
+
+	// the first data chunk is stored before the upload
+	_, err := file.SplitWriteAll(context.Background(), splitter.NewSimpleSplitter(mockStorer), bytes.NewReader(content[:swarm.ChunkSize]), swarm.ChunkSize, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the client disconnects after sending two and a half data chunks
+	body := io.MultiReader(bytes.NewReader(content[:swarm.ChunkSize*5/2]), iotest.TimeoutReader(bytes.NewReader(content)))
+	req := httptest.NewRequest(http.MethodPost, "/bytes", body)
+	req.ContentLength = int64(len(content))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	for _, addr := range addrs {
+		has, err := mockStorer.Has(context.Background(), addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !has {
+			t.Fatalf("chunk %s removed", addr)
+		}
+	}
+}
+
+// TestBytesUploadAbortedDiscarded tests that the chunks stored by an aborted
+// upload are neither push synced nor pinned, while the chunks that were
+// stored before the upload are synced.
+func TestBytesUploadAbortedDiscarded(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	mtags := tags.NewTags()
+	storer, err := localstore.New("", make([]byte, 32), &localstore.Options{Tags: mtags}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storer.Close()
+
+	s := api.New(api.Options{
+		Storer: storer,
+		Tags:   mtags,
+		Logger: logger,
+	})
+	content := filetest.GenerateTestData(t, swarm.ChunkSize*4)
+
+	// the first data chunk is stored before the upload
+	stored, err := file.SplitWriteAll(context.Background(), splitter.NewSimpleSplitter(storer), bytes.NewReader(content[:swarm.ChunkSize]), swarm.ChunkSize, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the client disconnects after sending two and a half data chunks
+	body := io.MultiReader(bytes.NewReader(content[:swarm.ChunkSize*5/2]), iotest.TimeoutReader(bytes.NewReader(content)))
+	req := httptest.NewRequest(http.MethodPost, "/bytes", body)
+	req.ContentLength = int64(len(content))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+
+	pushed, stop := storer.SubscribePush(context.Background())
+	defer stop()
+	var got []swarm.Address
+	for done := false; !done; {
+		select {
+		case ch := <-pushed:
+			got = append(got, ch.Address())
+		case <-time.After(100 * time.Millisecond):
+			done = true
+		}
+	}
+	if len(got) != 1 || !got[0].Equal(stored) {
+		t.Fatalf("got pushed chunks %v, want %s", got, stored)
+	}
+
+	// the second data chunk is stored by the upload, but not pinned
+	discarded, err := file.SplitWriteAll(context.Background(), splitter.NewSimpleSplitter(mock.NewStorer()), bytes.NewReader(content[swarm.ChunkSize:swarm.ChunkSize*2]), swarm.ChunkSize, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	has, err := storer.Has(context.Background(), discarded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !has {
+		t.Fatalf("chunk %s removed", discarded)
+	}
+	if _, err := storer.PinInfo(discarded); err == nil {
+		t.Fatalf("chunk %s pinned", discarded)
+	}
+}
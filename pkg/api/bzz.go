@@ -0,0 +1,242 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/collection/entry"
+	"github.com/ethersphere/bee/pkg/encryption"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/manifest/triemanifest"
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// maxPrefetches is the number of manifests whose assets are prefetched
+	// at the same time. Pages served while all are busy are not prefetched.
+	maxPrefetches = 4
+	// prefetchTimeout limits the time spent on prefetching the assets of
+	// a single manifest.
+	prefetchTimeout = time.Minute
+)
+
+// errInvalidAddress is returned by resolveAddress if the address is neither a
+// swarm address nor a name that can be resolved.
+var errInvalidAddress = errors.New("invalid address")
+
+// resolveAddress parses the hex swarm address, or resolves the name if it is
+// not one and the names are resolved.
+func (s *server) resolveAddress(ctx context.Context, addr string) (swarm.Address, error) {
+	address, err := swarm.ParseHexAddress(addr)
+	if err == nil {
+		return address, nil
+	}
+	if s.Resolver == nil || !strings.Contains(addr, ".") {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %v", errInvalidAddress, err)
+	}
+	return s.Resolver.Resolve(ctx, addr)
+}
+
+// bzzDownloadHandler serves the file under the path of the manifest.
+func (s *server) bzzDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["address"]
+	address, err := s.resolveAddress(r.Context(), addr)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidAddress):
+			s.Logger.Debugf("bzz download: parse address %s: %v", addr, err)
+			s.Logger.Errorf("bzz download: parse address %s", addr)
+			jsonhttp.BadRequest(w, "invalid address")
+		case errors.Is(err, resolver.ErrNotFound):
+			s.Logger.Debugf("bzz download: resolve name %s: %v", addr, err)
+			jsonhttp.NotFound(w, "name not found")
+		default:
+			s.Logger.Debugf("bzz download: resolve name %s: %v", addr, err)
+			s.Logger.Errorf("bzz download: resolve name %s", addr)
+			jsonhttp.BadGateway(w, "resolve name")
+		}
+		return
+	}
+
+	toDecrypt := len(address.Bytes()) == (swarm.HashSize + encryption.KeyLength)
+
+	m := triemanifest.NewManifest()
+	err = manifest.Load(joiner.NewSimpleJoiner(s.Storer), address, m, toDecrypt)
+	if err != nil {
+		s.Logger.Debugf("bzz download: load manifest %s: %v", addr, err)
+		s.Logger.Errorf("bzz download: load manifest %s", addr)
+		if errors.Is(err, manifest.ErrInvalidManifest) {
+			jsonhttp.BadRequest(w, "invalid manifest")
+			return
+		}
+		jsonhttp.NotFound(w, nil)
+		return
+	}
+
+	// the path of the root of the collection is empty
+	p := mux.Vars(r)["path"]
+	filePath := strings.TrimPrefix(path.Clean("/"+p), "/")
+	me, err := m.Lookup(filePath)
+	if errors.Is(err, manifest.ErrNotFound) {
+		// the index document is served for the paths of directories
+		if index := m.Metadata(manifest.IndexDocumentKey); index != "" {
+			indexPath := path.Join(filePath, index)
+			if ie, ierr := m.Lookup(indexPath); ierr == nil {
+				// the relative links of the index document are resolved
+				// in its directory only with the trailing slash
+				if filePath != "" && !strings.HasSuffix(p, "/") {
+					http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+					return
+				}
+				filePath, me, err = indexPath, ie, nil
+			}
+		}
+	}
+	if err != nil {
+		s.Logger.Debugf("bzz download: manifest entry %s/%s: %v", addr, filePath, err)
+		s.Logger.Errorf("bzz download: manifest entry %s/%s", addr, filePath)
+		if errorDocument := m.Metadata(manifest.ErrorDocumentKey); errorDocument != "" {
+			if ee, eerr := m.Lookup(errorDocument); eerr == nil {
+				s.downloadFile(notFoundResponseWriter{w}, withoutConditions(r), ee.Reference(), entryHeaders(ee))
+				return
+			}
+		}
+		jsonhttp.NotFound(w, nil)
+		return
+	}
+
+	// the assets of the page are requested by the browser right after it,
+	// so their first chunks are retrieved while the page is served
+	if s.ManifestPrefetch > 0 && isPage(filePath) {
+		select {
+		case s.prefetchSem <- struct{}{}:
+			go func() {
+				defer func() { <-s.prefetchSem }()
+				s.prefetchAssets(m, toDecrypt)
+			}()
+		default:
+		}
+	}
+
+	s.downloadFile(w, r, me.Reference(), entryHeaders(me))
+}
+
+// entryHeaders returns the headers of the manifest entry that replace the
+// ones of the file.
+func entryHeaders(me manifest.Entry) http.Header {
+	headers := me.Headers().Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	if ct := me.ContentType(); ct != "" {
+		headers.Set("Content-Type", ct)
+	}
+	return headers
+}
+
+// notFoundResponseWriter sends the not found status instead of the ok
+// status, so that the error document of a website is served with it.
+type notFoundResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w notFoundResponseWriter) WriteHeader(code int) {
+	if code == http.StatusOK {
+		code = http.StatusNotFound
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// withoutConditions returns the copy of the request without the range and
+// the conditional headers, so that the whole error document is served
+// regardless of the requested file.
+func withoutConditions(r *http.Request) *http.Request {
+	r = r.Clone(r.Context())
+	for _, name := range []string{"Range", "If-Range", "If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since"} {
+		r.Header.Del(name)
+	}
+	return r
+}
+
+// prefetchAssets retrieves the first chunks of the data of the style sheets
+// and scripts in the manifest.
+func (s *server) prefetchAssets(m manifest.Interface, toDecrypt bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+	defer cancel()
+
+	_ = m.Walk(func(p string, me manifest.Entry) error {
+		if !isAsset(p) {
+			return nil
+		}
+		if err := s.prefetchFile(ctx, me.Reference(), toDecrypt); err != nil {
+			s.Logger.Debugf("bzz download: prefetch %s: %v", p, err)
+		}
+		return ctx.Err()
+	})
+}
+
+// prefetchFile retrieves up to the configured number of the first data
+// chunks of the file with the entry reference.
+func (s *server) prefetchFile(ctx context.Context, address swarm.Address, toDecrypt bool) error {
+	buf := bytes.NewBuffer(nil)
+	_, err := file.JoinReadAll(joiner.NewSimpleJoiner(s.Storer), address, buf, toDecrypt)
+	if err != nil {
+		return fmt.Errorf("read entry: %w", err)
+	}
+	e := &entry.Entry{}
+	if err := e.UnmarshalBinary(buf.Bytes()); err != nil {
+		return fmt.Errorf("unmarshal entry: %w", err)
+	}
+
+	reader, size, err := joiner.NewReader(ctx, s.Storer, e.Reference(), toDecrypt)
+	if err != nil {
+		return fmt.Errorf("read root chunk: %w", err)
+	}
+	defer reader.Close()
+
+	length := int64(s.ManifestPrefetch) * swarm.ChunkSize
+	if length > size {
+		length = size
+	}
+	if length == 0 {
+		return nil
+	}
+	if _, err := reader.ReadAt(make([]byte, length), 0); err != nil {
+		return fmt.Errorf("read data: %w", err)
+	}
+	return nil
+}
+
+// isPage reports whether the file under the path is a web page.
+func isPage(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".html", ".htm":
+		return true
+	}
+	return false
+}
+
+// isAsset reports whether the file under the path is a style sheet or
+// a script that is loaded by web pages.
+func isAsset(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".css", ".js":
+		return true
+	}
+	return false
+}
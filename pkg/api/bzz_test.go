@@ -0,0 +1,300 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/manifest/triemanifest"
+	resolvermock "github.com/ethersphere/bee/pkg/resolver/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+func TestBzz(t *testing.T) {
+	style := make([]byte, swarm.ChunkSize+100)
+	rand.New(rand.NewSource(1)).Read(style)
+	files := []tarFile{
+		{name: "index.html", data: []byte("<link rel=\"stylesheet\" href=\"style.css\"><h1>Swarm</h1>")},
+		{name: "style.css", data: style},
+		{name: "img/logo.png", data: []byte("not really a png")},
+	}
+
+	var (
+		storer = &getRecordingStorer{Storer: mock.NewStorer()}
+		client = newTestServer(t, testServerOptions{
+			Storer:           storer,
+			Tags:             tags.NewTags(),
+			Logger:           logging.New(ioutil.Discard, 5),
+			ManifestPrefetch: 1,
+		})
+	)
+
+	reference := uploadDir(t, client, files, nil)
+	bzzResource := "/bzz/" + reference.String() + "/"
+
+	t.Run("not-found", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, bzzResource+"missing.html", nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusNotFound),
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("invalid-manifest", func(t *testing.T) {
+		data := []byte("not a manifest")
+		bytesResp := request(t, client, http.MethodPost, "/bytes", bytes.NewReader(data), http.StatusOK)
+		defer bytesResp.Body.Close()
+		var r api.BytesPostResponse
+		if err := json.NewDecoder(bytesResp.Body).Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/bzz/"+r.Reference.String()+"/index.html", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid manifest",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("file", func(t *testing.T) {
+		header := jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, bzzResource+"img/logo.png", nil, http.StatusOK, files[2].data, nil)
+		if got := header.Get("Content-Type"); got != "image/png" {
+			t.Errorf("got content type %q, want %q", got, "image/png")
+		}
+	})
+
+	t.Run("prefetch", func(t *testing.T) {
+		// only the first chunk of the style sheet is prefetched
+		first := dataAddress(t, style[:swarm.ChunkSize])
+		second := dataAddress(t, style[swarm.ChunkSize:])
+
+		jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, bzzResource+"index.html", nil, http.StatusOK, files[0].data, nil)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for !storer.got(first) {
+			if time.Now().After(deadline) {
+				t.Fatal("first chunk of the style sheet not prefetched")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if storer.got(second) {
+			t.Fatal("second chunk of the style sheet prefetched")
+		}
+	})
+
+	t.Run("headers", func(t *testing.T) {
+		m := triemanifest.NewManifest()
+		if err := manifest.Load(joiner.NewSimpleJoiner(storer), reference, m, false); err != nil {
+			t.Fatal(err)
+		}
+		e, err := m.Lookup("img/logo.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the content type and the custom headers of the manifest entry
+		// replace the ones of the file
+		hm := triemanifest.NewManifest()
+		hm.Add("logo", triemanifest.NewEntry(e.Reference(), e.Name(), "image/svg+xml", http.Header{
+			"Cache-Control": {"max-age=3600"},
+		}))
+		reference, err := manifest.Store(context.Background(), splitter.NewSimpleSplitter(storer), hm, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		header := jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, "/bzz/"+reference.String()+"/logo", nil, http.StatusOK, files[2].data, nil)
+		if got := header.Get("Content-Type"); got != "image/svg+xml" {
+			t.Errorf("got content type %q, want %q", got, "image/svg+xml")
+		}
+		if got := header.Get("Cache-Control"); got != "max-age=3600" {
+			t.Errorf("got cache control %q, want %q", got, "max-age=3600")
+		}
+	})
+}
+
+func TestBzzWebsite(t *testing.T) {
+	files := []tarFile{
+		{name: "index.html", data: []byte("<h1>Swarm</h1>")},
+		{name: "docs/index.html", data: []byte("<h1>Docs</h1>")},
+		{name: "docs/api.html", data: []byte("<h1>API</h1>")},
+		{name: "404.html", data: []byte("<h1>Not Found</h1>")},
+	}
+	client := newTestServer(t, testServerOptions{
+		Storer: mock.NewStorer(),
+		Tags:   tags.NewTags(),
+		Logger: logging.New(ioutil.Discard, 5),
+	})
+
+	reference := uploadDir(t, client, files, http.Header{
+		api.IndexDocumentHeader: {"index.html"},
+		api.ErrorDocumentHeader: {"404.html"},
+	})
+	bzzResource := "/bzz/" + reference.String() + "/"
+
+	t.Run("index", func(t *testing.T) {
+		for _, tc := range []struct {
+			path string
+			want []byte
+		}{
+			{path: "", want: files[0].data},
+			{path: "docs/", want: files[1].data},
+			{path: "docs/api.html", want: files[2].data},
+		} {
+			header := jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, bzzResource+tc.path, nil, http.StatusOK, tc.want, nil)
+			if got := header.Get("Content-Type"); got != "text/html; charset=utf-8" {
+				t.Errorf("got content type %q of %q, want %q", got, tc.path, "text/html; charset=utf-8")
+			}
+		}
+	})
+
+	t.Run("directory-redirect", func(t *testing.T) {
+		noRedirectClient := *client
+		noRedirectClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+		resp := request(t, &noRedirectClient, http.MethodGet, bzzResource+"docs", nil, http.StatusMovedPermanently)
+		defer resp.Body.Close()
+		if got, want := resp.Header.Get("Location"), bzzResource+"docs/"; got != want {
+			t.Fatalf("got location %q, want %q", got, want)
+		}
+	})
+
+	t.Run("error-document", func(t *testing.T) {
+		jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, bzzResource+"missing.html", nil, http.StatusNotFound, files[3].data, http.Header{
+			"Range": {"bytes=0-1"},
+		})
+	})
+
+	t.Run("missing-error-document", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, "/dirs", bytes.NewReader(tarFiles(t, files)), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: `error document not in collection: "missing.html"`,
+			Code:    http.StatusBadRequest,
+		}, http.Header{
+			"Content-Type":          {"application/x-tar"},
+			api.ErrorDocumentHeader: {"missing.html"},
+		})
+	})
+}
+
+// uploadDir uploads the files as a collection with the headers and returns
+// the reference of its manifest.
+func uploadDir(t *testing.T, client *http.Client, files []tarFile, headers http.Header) swarm.Address {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, "/dirs", bytes.NewReader(tarFiles(t, files)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header = headers.Clone()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header.Set("Content-Type", "application/x-tar")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got response status %s, want %v", resp.Status, http.StatusOK)
+	}
+	var uploadResp api.FileUploadResponse
+	if err := json.NewDecoder(resp.Body).Decode(&uploadResp); err != nil {
+		t.Fatal(err)
+	}
+	return uploadResp.Reference
+}
+
+// dataAddress returns the address of the data when it is split to chunks.
+func dataAddress(t *testing.T, data []byte) swarm.Address {
+	t.Helper()
+
+	s := splitter.NewSimpleSplitter(mock.NewStorer())
+	addr, err := file.SplitWriteAll(context.Background(), s, bytes.NewReader(data), int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+// getRecordingStorer records the addresses of the chunks that are retrieved
+// from it.
+type getRecordingStorer struct {
+	storage.Storer
+	mu   sync.Mutex
+	gets []swarm.Address
+}
+
+func (s *getRecordingStorer) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	s.mu.Lock()
+	s.gets = append(s.gets, addr)
+	s.mu.Unlock()
+	return s.Storer.Get(ctx, mode, addr)
+}
+
+func (s *getRecordingStorer) got(addr swarm.Address) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range s.gets {
+		if a.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBzzResolveName(t *testing.T) {
+	files := []tarFile{
+		{name: "index.html", data: []byte("<h1>Swarm</h1>")},
+	}
+	var (
+		resolver = resolvermock.New(nil)
+		client   = newTestServer(t, testServerOptions{
+			Storer:   mock.NewStorer(),
+			Tags:     tags.NewTags(),
+			Resolver: resolver,
+			Logger:   logging.New(ioutil.Discard, 5),
+		})
+	)
+
+	resolver.Set("swarm.eth", uploadDir(t, client, files, nil))
+
+	t.Run("resolved", func(t *testing.T) {
+		jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, "/bzz/swarm.eth/index.html", nil, http.StatusOK, files[0].data, nil)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/bzz/unknown.eth/index.html", nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "name not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("invalid-address", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/bzz/swarm/index.html", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid address",
+			Code:    http.StatusBadRequest,
+		})
+	})
+}
@@ -6,16 +6,18 @@ package api
 
 import (
 	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/netstore"
+	"github.com/ethersphere/bee/pkg/recovery"
+	"github.com/ethersphere/bee/pkg/sctx"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
@@ -28,6 +30,11 @@ const TagHeaderUid = "swarm-tag-uid"
 // Presence of this header in the HTTP request indicates the chunk needs to be pinned.
 const PinHeaderName = "swarm-pin"
 
+// TargetsRecoveryHeader defines the comma separated hex encoded address
+// prefixes of the neighbourhoods that are asked to recover the chunk if it
+// can not be retrieved.
+const TargetsRecoveryHeader = "swarm-recovery-targets"
+
 func (s *server) chunkUploadHandler(w http.ResponseWriter, r *http.Request) {
 	addr := mux.Vars(r)["addr"]
 	ctx := r.Context()
@@ -41,34 +48,16 @@ func (s *server) chunkUploadHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// if tag header is not there create a new one
-	var tag *tags.Tag
-	tagUidStr := r.Header.Get(TagHeaderUid)
-	if tagUidStr == "" {
-		tagName := fmt.Sprintf("unnamed_tag_%d", time.Now().Unix())
-		tag, err = s.Tags.Create(tagName, 0, false)
-		if err != nil {
-			s.Logger.Debugf("chunk upload: tag creation error: %v, addr %s", err, address)
-			s.Logger.Error("chunk upload: tag creation error")
-			jsonhttp.InternalServerError(w, "cannot create tag")
-			return
-		}
-	} else {
-		// if the tag uid header is present, then use the tag sent
-		tagUid, err := strconv.ParseUint(tagUidStr, 10, 32)
-		if err != nil {
-			s.Logger.Debugf("chunk upload: parse taguid %s: %v", tagUidStr, err)
-			s.Logger.Error("chunk upload: parse taguid")
+	tag, err := s.getOrCreateTag(r.Header.Get(TagHeaderUid))
+	if err != nil {
+		s.Logger.Debugf("chunk upload: get or create tag: %v, addr %s", err, address)
+		s.Logger.Error("chunk upload: get or create tag")
+		if errors.Is(err, errInvalidTagUid) {
 			jsonhttp.BadRequest(w, "invalid taguid")
 			return
 		}
-
-		tag, err = s.Tags.Get(uint32(tagUid))
-		if err != nil {
-			s.Logger.Debugf("chunk upload: tag get error: %v, addr %s", err, address)
-			s.Logger.Error("chunk upload: tag get error")
-			jsonhttp.InternalServerError(w, "cannot create tag")
-			return
-		}
+		jsonhttp.InternalServerError(w, "cannot create tag")
+		return
 	}
 
 	// Increment the total tags here since we dont have a splitter
@@ -88,6 +77,10 @@ func (s *server) chunkUploadHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		s.Logger.Debugf("chunk upload: chunk write error: %v, addr %s", err, address)
 		s.Logger.Error("chunk upload: chunk write error")
+		if errors.Is(err, storage.ErrInvalidChunk) {
+			jsonhttp.BadRequest(w, "invalid chunk")
+			return
+		}
 		jsonhttp.BadRequest(w, "chunk write error")
 		return
 	} else if len(seen) > 0 && seen[0] {
@@ -109,8 +102,7 @@ func (s *server) chunkUploadHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	w.Header().Set(TagHeaderUid, fmt.Sprint(tag.Uid))
-	w.Header().Set("Access-Control-Expose-Headers", TagHeaderUid)
+	setTagHeaders(w, tag)
 	jsonhttp.OK(w, nil)
 }
 
@@ -126,14 +118,35 @@ func (s *server) chunkGetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if v := r.Header.Get(TargetsRecoveryHeader); v != "" {
+		targets, err := parseTargets(v)
+		if err != nil {
+			s.Logger.Debugf("chunk: parse recovery targets %s: %v", v, err)
+			s.Logger.Error("chunk: parse recovery targets error")
+			jsonhttp.BadRequest(w, "invalid recovery targets")
+			return
+		}
+		ctx = sctx.SetTargets(ctx, targets)
+	}
+
 	chunk, err := s.Storer.Get(ctx, storage.ModeGetRequest, address)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			s.Logger.Trace("chunk: chunk not found. addr %s", address)
+			s.Logger.Tracef("chunk: chunk not found. addr %s", address)
 			jsonhttp.NotFound(w, "chunk not found")
 			return
 
 		}
+		if errors.Is(err, netstore.ErrRecoveryAttempt) {
+			s.Logger.Tracef("chunk: chunk recovery initiated. addr %s", address)
+			jsonhttp.Accepted(w, "chunk recovery initiated. retry after sometime.")
+			return
+		}
+		if errors.Is(err, netstore.ErrRecoveryLimit) {
+			s.Logger.Tracef("chunk: chunk recovery limit reached. addr %s", address)
+			jsonhttp.TooManyRequests(w, "too many chunk recoveries. retry after sometime.")
+			return
+		}
 		s.Logger.Debugf("chunk: chunk read error: %v ,addr %s", err, address)
 		s.Logger.Error("chunk: chunk read error")
 		jsonhttp.InternalServerError(w, "chunk read error")
@@ -142,3 +155,22 @@ func (s *server) chunkGetHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "binary/octet-stream")
 	_, _ = io.Copy(w, bytes.NewReader(chunk.Data()))
 }
+
+// parseTargets parses the comma separated hex encoded recovery targets.
+func parseTargets(v string) ([][]byte, error) {
+	var targets [][]byte
+	for _, t := range strings.Split(v, ",") {
+		target, err := hex.DecodeString(strings.TrimSpace(t))
+		if err != nil {
+			return nil, err
+		}
+		if len(target) == 0 || len(target) > swarm.HashSize {
+			return nil, fmt.Errorf("invalid target length %d", len(target))
+		}
+		targets = append(targets, target)
+	}
+	if len(targets) > recovery.MaxTargets {
+		return nil, fmt.Errorf("%d targets, at most %d allowed", len(targets), recovery.MaxTargets)
+	}
+	return targets, nil
+}
@@ -43,7 +43,7 @@ func TestChunkUploadDownload(t *testing.T) {
 
 	t.Run("invalid hash", func(t *testing.T) {
 		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource(invalidHash), bytes.NewReader(validContent), http.StatusBadRequest, jsonhttp.StatusResponse{
-			Message: "chunk write error",
+			Message: "invalid chunk",
 			Code:    http.StatusBadRequest,
 		})
 
@@ -53,7 +53,7 @@ func TestChunkUploadDownload(t *testing.T) {
 
 	t.Run("invalid content", func(t *testing.T) {
 		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource(validHash), bytes.NewReader(invalidContent), http.StatusBadRequest, jsonhttp.StatusResponse{
-			Message: "chunk write error",
+			Message: "invalid chunk",
 			Code:    http.StatusBadRequest,
 		})
 
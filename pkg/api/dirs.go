@@ -0,0 +1,186 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/manifest/triemanifest"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	contentTypeTar = "application/x-tar"
+	// IndexDocumentHeader is the header of the name of the document that is
+	// served for the directories of the uploaded website.
+	IndexDocumentHeader = "swarm-index-document"
+	// ErrorDocumentHeader is the header of the path of the document that is
+	// served for the paths that are not in the uploaded website.
+	ErrorDocumentHeader = "swarm-error-document"
+)
+
+var (
+	errInvalidPath          = errors.New("invalid path")
+	errEmptyDir             = errors.New("no files in collection")
+	errMissingErrorDocument = errors.New("error document not in collection")
+)
+
+// dirUploadHandler uploads a collection of files supplied as a tar stream.
+// Every file is stored as a file entry, and the reference of the manifest
+// that maps the paths of the files to their entries is returned.
+func (s *server) dirUploadHandler(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != contentTypeTar {
+		s.Logger.Debugf("dir upload: invalid content type header %q: %v", contentType, err)
+		s.Logger.Errorf("dir upload: invalid content type header %q", contentType)
+		jsonhttp.BadRequest(w, "invalid content-type header")
+		return
+	}
+
+	tag, err := s.getOrCreateTag(r.Header.Get(TagHeaderUid))
+	if err != nil {
+		s.Logger.Debugf("dir upload: get or create tag: %v", err)
+		s.Logger.Error("dir upload: get or create tag")
+		if errors.Is(err, errInvalidTagUid) {
+			jsonhttp.BadRequest(w, "invalid taguid")
+			return
+		}
+		jsonhttp.InternalServerError(w, "cannot create tag")
+		return
+	}
+
+	// the workers of the splitter that are still running when the handler
+	// returns must not store any more chunks, and the chunks of a failed
+	// upload are discarded
+	putter := newUploadPutter(s.Storer, tag)
+	defer s.abortUpload(putter)
+
+	toEncrypt := strings.ToLower(r.Header.Get(EncryptHeader)) == "true"
+	password, ok := s.uploadKeyPassword(w, r, toEncrypt)
+	if !ok {
+		return
+	}
+	reference, err := s.storeDir(r.Context(), putter, r.Body, r.Header.Get(IndexDocumentHeader), r.Header.Get(ErrorDocumentHeader), toEncrypt)
+	if err != nil {
+		s.Logger.Debugf("dir upload: store dir: %v", err)
+		s.Logger.Error("dir upload: store dir")
+		if errors.Is(err, errInvalidPath) || errors.Is(err, errEmptyDir) || errors.Is(err, errMissingErrorDocument) || errors.Is(err, tar.ErrHeader) {
+			jsonhttp.BadRequest(w, err.Error())
+			return
+		}
+		jsonhttp.InternalServerError(w, "could not store dir")
+		return
+	}
+	if err := s.storeUploadKey(reference, "", password); err != nil {
+		s.Logger.Debugf("dir upload: store upload key: %v", err)
+		s.Logger.Error("dir upload: store upload key")
+		jsonhttp.InternalServerError(w, "cannot store upload key")
+		return
+	}
+	putter.complete()
+	tag.DoneSplit(reference)
+
+	setTagHeaders(w, tag)
+	jsonhttp.OK(w, fileUploadResponse{
+		Reference: reference,
+	})
+}
+
+// storeDir stores every regular file of the tar stream as a file entry and
+// returns the reference of the manifest of the files. The index and the error
+// documents of the website are set in the manifest metadata if they are not
+// empty.
+func (s *server) storeDir(ctx context.Context, putter storage.Putter, r io.Reader, indexDocument, errorDocument string, toEncrypt bool) (swarm.Address, error) {
+	m := triemanifest.NewManifest()
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return swarm.ZeroAddress, fmt.Errorf("read tar stream: %w", err)
+		}
+
+		// only regular files are stored, directories are implied by the
+		// paths of the files
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+
+		filePath, err := cleanPath(hdr.Name)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		fileName := path.Base(filePath)
+
+		var reader io.Reader = tr
+		contentType := mime.TypeByExtension(path.Ext(fileName))
+		if contentType == "" {
+			br := bufio.NewReader(tr)
+			buf, err := br.Peek(512)
+			if err != nil && err != io.EOF {
+				return swarm.ZeroAddress, fmt.Errorf("read content type of %q: %w", filePath, err)
+			}
+			contentType = http.DetectContentType(buf)
+			reader = br
+		}
+
+		reference, err := s.storeFile(ctx, putter, reader, hdr.Size, fileName, contentType, toEncrypt)
+		if err != nil {
+			return swarm.ZeroAddress, fmt.Errorf("store file %q: %w", filePath, err)
+		}
+		m.Add(filePath, triemanifest.NewEntry(reference, fileName, contentType, nil))
+	}
+
+	if m.Length() == 0 {
+		return swarm.ZeroAddress, errEmptyDir
+	}
+
+	if indexDocument != "" {
+		p, err := cleanPath(indexDocument)
+		if err != nil {
+			return swarm.ZeroAddress, fmt.Errorf("index document: %w", err)
+		}
+		m.SetMetadata(manifest.IndexDocumentKey, p)
+	}
+	if errorDocument != "" {
+		p, err := cleanPath(errorDocument)
+		if err != nil {
+			return swarm.ZeroAddress, fmt.Errorf("error document: %w", err)
+		}
+		if _, err := m.Lookup(p); err != nil {
+			return swarm.ZeroAddress, fmt.Errorf("%w: %q", errMissingErrorDocument, p)
+		}
+		m.SetMetadata(manifest.ErrorDocumentKey, p)
+	}
+
+	return manifest.Store(ctx, s.newSplitter(putter), m, toEncrypt)
+}
+
+// cleanPath returns the path of the file in the manifest, relative to the
+// root of the collection.
+func cleanPath(p string) (string, error) {
+	// cleaning the path as an absolute one drops the leading parent elements
+	cleaned := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if cleaned == "" {
+		return "", fmt.Errorf("%w %q", errInvalidPath, p)
+	}
+	return cleaned, nil
+}
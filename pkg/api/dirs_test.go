@@ -0,0 +1,151 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/manifest/triemanifest"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+func TestDirs(t *testing.T) {
+	var (
+		dirUploadResource = "/dirs"
+		client            = newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Tags:   tags.NewTags(),
+			Logger: logging.New(ioutil.Discard, 5),
+		})
+	)
+
+	t.Run("invalid-content-type", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, dirUploadResource, bytes.NewReader(tarFiles(t, nil)), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid content-type header",
+			Code:    http.StatusBadRequest,
+		}, nil)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, dirUploadResource, bytes.NewReader(tarFiles(t, nil)), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "no files in collection",
+			Code:    http.StatusBadRequest,
+		}, http.Header{"Content-Type": {"application/x-tar"}})
+	})
+
+	t.Run("upload", func(t *testing.T) {
+		files := []tarFile{
+			{name: "index.html", data: []byte("<h1>Swarm</h1>")},
+			{name: "img/logo.png", data: []byte("not really a png")},
+			{name: "../docs/readme", data: []byte("plain text")},
+		}
+		wantContentTypes := map[string]string{
+			"index.html":   "text/html; charset=utf-8",
+			"img/logo.png": "image/png",
+			"docs/readme":  "text/plain; charset=utf-8",
+		}
+
+		req, err := http.NewRequest(http.MethodPost, dirUploadResource, bytes.NewReader(tarFiles(t, files)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/x-tar")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got response status %s, want %v", resp.Status, http.StatusOK)
+		}
+		var uploadResp api.FileUploadResponse
+		if err := json.NewDecoder(resp.Body).Decode(&uploadResp); err != nil {
+			t.Fatal(err)
+		}
+
+		// the manifest maps the cleaned paths to the file entries
+		manifestResp := request(t, client, http.MethodGet, "/bytes/"+uploadResp.Reference.String(), nil, http.StatusOK)
+		defer manifestResp.Body.Close()
+		manifestData, err := ioutil.ReadAll(manifestResp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := triemanifest.NewManifest()
+		if err := m.UnmarshalBinary(manifestData); err != nil {
+			t.Fatal(err)
+		}
+		if m.Length() != len(files) {
+			t.Fatalf("got %d manifest entries, want %d", m.Length(), len(files))
+		}
+
+		for _, f := range files {
+			filePath := f.name
+			if filePath == "../docs/readme" {
+				filePath = "docs/readme"
+			}
+			e, err := m.Lookup(filePath)
+			if err != nil {
+				t.Fatalf("entry %q: %v", filePath, err)
+			}
+
+			header := jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, "/files/"+e.Reference().String(), nil, http.StatusOK, f.data, nil)
+			_, params, err := mime.ParseMediaType(header.Get("Content-Disposition"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if params["filename"] != e.Name() {
+				t.Errorf("got file name %q of %q, want %q", params["filename"], filePath, e.Name())
+			}
+			if got := header.Get("Content-Type"); got != wantContentTypes[filePath] {
+				t.Errorf("got content type %q of %q, want %q", got, filePath, wantContentTypes[filePath])
+			}
+			if got := e.ContentType(); got != wantContentTypes[filePath] {
+				t.Errorf("got manifest content type %q of %q, want %q", got, filePath, wantContentTypes[filePath])
+			}
+		}
+	})
+}
+
+// tarFile is a file of the tar stream created by tarFiles.
+type tarFile struct {
+	name string
+	data []byte
+}
+
+// tarFiles returns the tar stream of the files.
+func tarFiles(t *testing.T, files []tarFile) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name: f.name,
+			Mode: 0600,
+			Size: int64(len(f.data)),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,242 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// RequestIDHeader is the header of the download responses with the ID
+	// of the request, by which the status of the download can be requested
+	// after it.
+	RequestIDHeader = "swarm-request-id"
+	// StreamStatusTrailer and StreamErrorTrailer are the trailers of the
+	// download responses that are sent if the request has the "TE: trailers"
+	// header. The status is either completed or failed, and the error is
+	// only sent if the download failed.
+	StreamStatusTrailer = "swarm-stream-status"
+	StreamErrorTrailer  = "swarm-stream-error"
+)
+
+const (
+	downloadInProgress = "in progress"
+	downloadCompleted  = "completed"
+	downloadFailed     = "failed"
+	downloadCanceled   = "canceled"
+)
+
+// downloadError is the error of the failed downloads that is reported to the
+// client, while the error of the reading is only logged, as it may reveal
+// the addresses of the chunks and the details of the node.
+const downloadError = "content could not be read"
+
+// maxDownloads is the number of the most recent downloads of which the
+// statuses are kept.
+const maxDownloads = 1000
+
+const requestIDLength = 16
+
+type downloadResponse struct {
+	ID        string        `json:"id"`
+	Reference swarm.Address `json:"reference"`
+	Status    string        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Bytes     int64         `json:"bytes"`
+	Started   time.Time     `json:"started"`
+}
+
+// downloads keeps the statuses of the most recent downloads by their
+// request IDs.
+type downloads struct {
+	statuses map[string]*downloadResponse
+	// ids are the request IDs in the order of the downloads, the oldest
+	// of which is removed when the limit is reached
+	ids []string
+	mu  sync.Mutex
+}
+
+func newDownloads() *downloads {
+	return &downloads{
+		statuses: make(map[string]*downloadResponse),
+	}
+}
+
+func (d *downloads) add(status *downloadResponse) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.ids) >= maxDownloads {
+		delete(d.statuses, d.ids[0])
+		d.ids = d.ids[1:]
+	}
+	d.statuses[status.ID] = status
+	d.ids = append(d.ids, status.ID)
+}
+
+func (d *downloads) update(id string, f func(status *downloadResponse)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if status, ok := d.statuses[id]; ok {
+		f(status)
+	}
+}
+
+func (d *downloads) get(id string) (downloadResponse, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	status, ok := d.statuses[id]
+	if !ok {
+		return downloadResponse{}, false
+	}
+	return *status, true
+}
+
+// serveContent serves the content with the reference with the support for
+// range requests, so that only the chunks of the requested ranges are
+// retrieved. If reading the content fails after the response headers are
+// sent, as when a chunk deep in the tree is missing, the failure is
+// reported by the trailers if the client accepts them. Otherwise the
+// connection is closed without completing the response, so that the client
+// gets an unexpected end of the response instead of truncated content,
+// even if the response is compressed and has no content length. In both
+// cases the status of the download can be requested by the request ID from
+// the response headers.
+func (s *server) serveContent(w http.ResponseWriter, r *http.Request, reference swarm.Address, content io.ReadSeeker) {
+	id := make([]byte, requestIDLength)
+	if _, err := rand.Read(id); err != nil {
+		s.Logger.Debugf("download: request id: %v", err)
+		s.Logger.Error("download: request id")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+	requestID := hex.EncodeToString(id)
+
+	s.downloads.add(&downloadResponse{
+		ID:        requestID,
+		Reference: reference,
+		Status:    downloadInProgress,
+		Started:   time.Now(),
+	})
+
+	sw := &streamResponseWriter{
+		ResponseWriter: w,
+		trailers:       acceptsTrailers(r),
+	}
+	sw.Header().Set(RequestIDHeader, requestID)
+	if sw.trailers {
+		sw.Header().Set("Trailer", StreamStatusTrailer+", "+StreamErrorTrailer)
+	}
+
+	rc := &errorRecordingReadSeeker{ReadSeeker: content}
+	http.ServeContent(sw, r, "", time.Time{}, rc)
+
+	status := downloadCompleted
+	switch {
+	case r.Context().Err() != nil:
+		status = downloadCanceled
+	case rc.err != nil:
+		status = downloadFailed
+		s.Logger.Debugf("download: request %s: reference %s: %v", requestID, reference, rc.err)
+		s.Logger.Errorf("download: request %s: reference %s failed after %d bytes", requestID, reference, sw.written)
+	}
+	s.downloads.update(requestID, func(d *downloadResponse) {
+		d.Status = status
+		d.Bytes = sw.written
+		if rc.err != nil {
+			d.Error = downloadError
+		}
+	})
+
+	if !sw.trailers {
+		if rc.err != nil {
+			panic(http.ErrAbortHandler)
+		}
+		return
+	}
+	sw.Header().Set(StreamStatusTrailer, status)
+	if rc.err != nil {
+		sw.Header().Set(StreamErrorTrailer, downloadError)
+	}
+}
+
+// downloadStatusHandler responds with the status of the download with the
+// request ID.
+func (s *server) downloadStatusHandler(w http.ResponseWriter, r *http.Request) {
+	status, ok := s.downloads.get(mux.Vars(r)["id"])
+	if !ok {
+		jsonhttp.NotFound(w, "download not found")
+		return
+	}
+	jsonhttp.OK(w, status)
+}
+
+// acceptsTrailers returns true if the request has the TE header with the
+// trailers value.
+func acceptsTrailers(r *http.Request) bool {
+	for _, v := range r.Header.Values("TE") {
+		for _, te := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(te), "trailers") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// streamResponseWriter counts the written bytes of the response. If the
+// trailers are sent, it removes the content length header, so that the
+// response is chunked, as the trailers can not be sent otherwise. The
+// headers are flushed right away, so that the client gets the request ID
+// even if the response is aborted before any content is flushed.
+type streamResponseWriter struct {
+	http.ResponseWriter
+	trailers bool
+	written  int64
+}
+
+func (w *streamResponseWriter) WriteHeader(code int) {
+	if w.trailers {
+		w.Header().Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(code)
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *streamResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+// errorRecordingReadSeeker records the first error of its reads other than
+// io.EOF, which is not returned by http.ServeContent.
+type errorRecordingReadSeeker struct {
+	io.ReadSeeker
+	err error
+}
+
+func (r *errorRecordingReadSeeker) Read(p []byte) (int, error) {
+	n, err := r.ReadSeeker.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,135 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	filetest "github.com/ethersphere/bee/pkg/file/testing"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+func TestDownloadStatus(t *testing.T) {
+	mockStorer := mock.NewStorer()
+	client := newTestServer(t, testServerOptions{
+		Storer: mockStorer,
+		Tags:   tags.NewTags(),
+	})
+
+	content := filetest.GenerateTestData(t, swarm.ChunkSize*3)
+	var upload api.BytesPostResponse
+	jsonhttptest.ResponseUnmarshal(t, client, http.MethodPost, "/bytes", bytes.NewReader(content), http.StatusOK, &upload)
+	reference := upload.Reference.String()
+
+	t.Run("completed", func(t *testing.T) {
+		resp := downloadRequest(t, client, reference, true)
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, content) {
+			t.Fatal("data mismatch")
+		}
+		if got := resp.Trailer.Get(api.StreamStatusTrailer); got != "completed" {
+			t.Errorf("got stream status trailer %q, want %q", got, "completed")
+		}
+		if got := resp.Trailer.Get(api.StreamErrorTrailer); got != "" {
+			t.Errorf("got stream error trailer %q", got)
+		}
+
+		assertDownloadStatus(t, client, resp.Header.Get(api.RequestIDHeader), reference, "completed", int64(len(content)))
+	})
+
+	// the second data chunk is the reference of its data alone, and it is
+	// removed so that the download fails after the first one
+	var missing api.BytesPostResponse
+	jsonhttptest.ResponseUnmarshal(t, client, http.MethodPost, "/bytes", bytes.NewReader(content[swarm.ChunkSize:2*swarm.ChunkSize]), http.StatusOK, &missing)
+	if err := mockStorer.Set(context.Background(), storage.ModeSetRemove, missing.Reference); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("failed with trailers", func(t *testing.T) {
+		resp := downloadRequest(t, client, reference, true)
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) >= len(content) {
+			t.Fatalf("got %d bytes of failed download", len(data))
+		}
+		if got := resp.Trailer.Get(api.StreamStatusTrailer); got != "failed" {
+			t.Errorf("got stream status trailer %q, want %q", got, "failed")
+		}
+		if got := resp.Trailer.Get(api.StreamErrorTrailer); got != "content could not be read" {
+			t.Errorf("got stream error trailer %q, want %q", got, "content could not be read")
+		}
+
+		assertDownloadStatus(t, client, resp.Header.Get(api.RequestIDHeader), reference, "failed", int64(len(data)))
+	})
+
+	t.Run("failed without trailers", func(t *testing.T) {
+		resp := downloadRequest(t, client, reference, false)
+		data, err := ioutil.ReadAll(resp.Body)
+		if err == nil {
+			t.Fatal("got no error reading the response of failed download")
+		}
+
+		assertDownloadStatus(t, client, resp.Header.Get(api.RequestIDHeader), reference, "failed", int64(len(data)))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/downloads/0123", nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "download not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+}
+
+func downloadRequest(t *testing.T, client *http.Client, reference string, trailers bool) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, "/bytes/"+reference, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trailers {
+		req.Header.Set("TE", "trailers")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got response status %s, want %v %s", resp.Status, http.StatusOK, http.StatusText(http.StatusOK))
+	}
+	return resp
+}
+
+func assertDownloadStatus(t *testing.T, client *http.Client, id, reference, status string, size int64) {
+	t.Helper()
+
+	if id == "" {
+		t.Fatal("got no request id")
+	}
+	var got api.DownloadResponse
+	jsonhttptest.ResponseUnmarshal(t, client, http.MethodGet, "/downloads/"+id, nil, http.StatusOK, &got)
+	if got.ID != id || got.Reference.String() != reference || got.Status != status || got.Bytes != size {
+		t.Errorf("got download status %+v, want id %s, reference %s, status %q and %d bytes", got, id, reference, status, size)
+	}
+	if status == "failed" && got.Error != "content could not be read" {
+		t.Errorf("got download error %q, want %q", got.Error, "content could not be read")
+	}
+}
@@ -7,4 +7,17 @@ package api
 type (
 	BytesPostResponse  = bytesPostResponse
 	FileUploadResponse = fileUploadResponse
+	ReceiptsResponse   = receiptsResponse
+	ProbeResponse      = probeResponse
+	PinResponse        = pinResponse
+	ListPinsResponse   = listPinsResponse
+	DownloadResponse   = downloadResponse
+
+	UploadSessionResponse = uploadSessionResponse
+
+	UploadKeyResponse       = uploadKeyResponse
+	ListUploadKeysResponse  = listUploadKeysResponse
+	ExportUploadKeyResponse = exportUploadKeyResponse
 )
+
+var MaxProbeTraversed = &maxProbeTraversed
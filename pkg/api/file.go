@@ -7,6 +7,7 @@ package api
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,7 +24,6 @@ import (
 	"github.com/ethersphere/bee/pkg/encryption"
 	"github.com/ethersphere/bee/pkg/file"
 	"github.com/ethersphere/bee/pkg/file/joiner"
-	"github.com/ethersphere/bee/pkg/file/splitter"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -43,7 +43,20 @@ type fileUploadResponse struct {
 // - multipart http message
 // - other content types as complete file body
 func (s *server) fileUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if id := r.URL.Query().Get("session"); id != "" {
+		s.uploadSessionWriteHandler(w, r, id)
+		return
+	}
+	if r.URL.Query().Get("resume") == "true" {
+		s.uploadSessionCreateHandler(w, r)
+		return
+	}
+
 	toEncrypt := strings.ToLower(r.Header.Get(EncryptHeader)) == "true"
+	password, ok := s.uploadKeyPassword(w, r, toEncrypt)
+	if !ok {
+		return
+	}
 	contentType := r.Header.Get("Content-Type")
 	mediaType, params, err := mime.ParseMediaType(contentType)
 	if err != nil {
@@ -53,6 +66,24 @@ func (s *server) fileUploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tag, err := s.getOrCreateTag(r.Header.Get(TagHeaderUid))
+	if err != nil {
+		s.Logger.Debugf("file upload: get or create tag: %v", err)
+		s.Logger.Error("file upload: get or create tag")
+		if errors.Is(err, errInvalidTagUid) {
+			jsonhttp.BadRequest(w, "invalid taguid")
+			return
+		}
+		jsonhttp.InternalServerError(w, "cannot create tag")
+		return
+	}
+
+	// the workers of the splitter that are still running when the handler
+	// returns must not store any more chunks, and the chunks of a failed
+	// upload are discarded
+	putter := newUploadPutter(s.Storer, tag)
+	defer s.abortUpload(putter)
+
 	ctx := r.Context()
 	var reader io.Reader
 	var fileName, contentLength string
@@ -137,16 +168,44 @@ func (s *server) fileUploadHandler(w http.ResponseWriter, r *http.Request) {
 		reader = tmp
 	}
 
-	// first store the file and get its reference
-	sp := splitter.NewSimpleSplitter(s.Storer)
-	fr, err := file.SplitWriteAll(ctx, sp, reader, int64(fileSize), toEncrypt)
+	reference, err := s.storeFile(ctx, putter, reader, int64(fileSize), fileName, contentType, toEncrypt)
 	if err != nil {
-		s.Logger.Debugf("file upload: file store, file %q: %v", fileName, err)
-		s.Logger.Errorf("file upload: file store, file %q", fileName)
-		jsonhttp.InternalServerError(w, "could not store file data")
+		s.Logger.Debugf("file upload: store file %q: %v", fileName, err)
+		s.Logger.Errorf("file upload: store file %q", fileName)
+		jsonhttp.InternalServerError(w, "could not store file")
+		return
+	}
+	if err := s.storeUploadKey(reference, fileName, password); err != nil {
+		s.Logger.Debugf("file upload: store upload key, file %q: %v", fileName, err)
+		s.Logger.Errorf("file upload: store upload key, file %q", fileName)
+		jsonhttp.InternalServerError(w, "cannot store upload key")
 		return
 	}
+	putter.complete()
+	tag.DoneSplit(reference)
+
+	setTagHeaders(w, tag)
+	w.Header().Set("ETag", fmt.Sprintf("%q", reference.String()))
+	jsonhttp.OK(w, fileUploadResponse{
+		Reference: reference,
+	})
+}
+
+// storeFile stores the data of the file, its metadata and the entry that
+// joins them, and returns the reference of the entry. The reference of the
+// file data is used as the file name if the name is empty.
+func (s *server) storeFile(ctx context.Context, putter storage.Putter, r io.Reader, size int64, fileName, contentType string, toEncrypt bool) (swarm.Address, error) {
+	// first store the file and get its reference
+	fr, err := file.SplitWriteAll(ctx, s.newSplitter(putter), r, size, toEncrypt)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("file data store: %w", err)
+	}
+	return s.storeFileEntry(ctx, putter, fr, fileName, contentType, toEncrypt)
+}
 
+// storeFileEntry stores the metadata of the file with the data reference and
+// the entry that joins them, and returns the reference of the entry.
+func (s *server) storeFileEntry(ctx context.Context, putter storage.Putter, fr swarm.Address, fileName, contentType string, toEncrypt bool) (swarm.Address, error) {
 	// If filename is still empty, use the file hash as the filename
 	if fileName == "" {
 		fileName = fr.String()
@@ -157,41 +216,23 @@ func (s *server) fileUploadHandler(w http.ResponseWriter, r *http.Request) {
 	m.MimeType = contentType
 	metadataBytes, err := json.Marshal(m)
 	if err != nil {
-		s.Logger.Debugf("file upload: metadata marshal, file %q: %v", fileName, err)
-		s.Logger.Errorf("file upload: metadata marshal, file %q", fileName)
-		jsonhttp.InternalServerError(w, "metadata marshal error")
-		return
+		return swarm.ZeroAddress, fmt.Errorf("metadata marshal: %w", err)
 	}
-	sp = splitter.NewSimpleSplitter(s.Storer)
-	mr, err := file.SplitWriteAll(ctx, sp, bytes.NewReader(metadataBytes), int64(len(metadataBytes)), toEncrypt)
+	mr, err := file.SplitWriteAll(ctx, s.newSplitter(putter), bytes.NewReader(metadataBytes), int64(len(metadataBytes)), toEncrypt)
 	if err != nil {
-		s.Logger.Debugf("file upload: metadata store, file %q: %v", fileName, err)
-		s.Logger.Errorf("file upload: metadata store, file %q", fileName)
-		jsonhttp.InternalServerError(w, "could not store metadata")
-		return
+		return swarm.ZeroAddress, fmt.Errorf("metadata store: %w", err)
 	}
 
 	// now join both references (mr,fr) to create an entry and store it.
-	entrie := entry.New(fr, mr)
-	fileEntryBytes, err := entrie.MarshalBinary()
+	fileEntryBytes, err := entry.New(fr, mr).MarshalBinary()
 	if err != nil {
-		s.Logger.Debugf("file upload: entry marshal, file %q: %v", fileName, err)
-		s.Logger.Errorf("file upload: entry marshal, file %q", fileName)
-		jsonhttp.InternalServerError(w, "entry marshal error")
-		return
+		return swarm.ZeroAddress, fmt.Errorf("entry marshal: %w", err)
 	}
-	sp = splitter.NewSimpleSplitter(s.Storer)
-	reference, err := file.SplitWriteAll(ctx, sp, bytes.NewReader(fileEntryBytes), int64(len(fileEntryBytes)), toEncrypt)
+	reference, err := file.SplitWriteAll(ctx, s.newSplitter(putter), bytes.NewReader(fileEntryBytes), int64(len(fileEntryBytes)), toEncrypt)
 	if err != nil {
-		s.Logger.Debugf("file upload: entry store, file %q: %v", fileName, err)
-		s.Logger.Errorf("file upload: entry store, file %q", fileName)
-		jsonhttp.InternalServerError(w, "could not store entry")
-		return
+		return swarm.ZeroAddress, fmt.Errorf("entry store: %w", err)
 	}
-	w.Header().Set("ETag", fmt.Sprintf("%q", reference.String()))
-	jsonhttp.OK(w, fileUploadResponse{
-		Reference: reference,
-	})
+	return reference, nil
 }
 
 // fileDownloadHandler downloads the file given the entry's reference.
@@ -205,12 +246,20 @@ func (s *server) fileDownloadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.downloadFile(w, r, address, nil)
+}
+
+// downloadFile writes the data of the file with the entry reference to the
+// response, with the headers from the metadata of the file. The additional
+// headers are set after them, replacing the ones with the same names.
+func (s *server) downloadFile(w http.ResponseWriter, r *http.Request, address swarm.Address, additionalHeaders http.Header) {
+	addr := address.String()
 	toDecrypt := len(address.Bytes()) == (swarm.HashSize + encryption.KeyLength)
 
 	// read entry.
 	j := joiner.NewSimpleJoiner(s.Storer)
 	buf := bytes.NewBuffer(nil)
-	_, err = file.JoinReadAll(j, address, buf, toDecrypt)
+	_, err := file.JoinReadAll(j, address, buf, toDecrypt)
 	if err != nil {
 		s.Logger.Debugf("file download: read entry %s: %v", addr, err)
 		s.Logger.Errorf("file download: read entry %s", addr)
@@ -254,8 +303,7 @@ func (s *server) fileDownloadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// send the file data back in the response
-	dataSize, err := j.Size(r.Context(), e.Reference())
+	reader, dataSize, err := joiner.NewReader(r.Context(), s.Storer, e.Reference(), toDecrypt)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
 			s.Logger.Debugf("file download: not found %s: %v", e.Reference(), err)
@@ -268,44 +316,19 @@ func (s *server) fileDownloadHandler(w http.ResponseWriter, r *http.Request) {
 		jsonhttp.BadRequest(w, "invalid root chunk")
 		return
 	}
-
-	pr, pw := io.Pipe()
-	defer pr.Close()
-	go func() {
-		ctx := r.Context()
-		<-ctx.Done()
-		if err := ctx.Err(); err != nil {
-			if err := pr.CloseWithError(err); err != nil {
-				s.Logger.Debugf("file download: data join close %s: %v", addr, err)
-				s.Logger.Errorf("file download: data join close %s", addr)
-			}
-		}
-	}()
-
-	go func() {
-		_, err := file.JoinReadAll(j, e.Reference(), pw, toDecrypt)
-		if err := pw.CloseWithError(err); err != nil {
-			s.Logger.Debugf("file download: data join close %s: %v", addr, err)
-			s.Logger.Errorf("file download: data join close %s", addr)
-		}
-	}()
-
-	bpr := bufio.NewReader(pr)
-
-	if b, err := bpr.Peek(4096); err != nil && err != io.EOF && len(b) == 0 {
-		s.Logger.Debugf("file download: data join %s: %v", addr, err)
-		s.Logger.Errorf("file download: data join %s", addr)
-		jsonhttp.NotFound(w, nil)
-		return
-	}
+	defer reader.Close()
 
 	w.Header().Set("ETag", fmt.Sprintf("%q", e.Reference()))
 	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", metaData.Filename))
 	w.Header().Set("Content-Type", metaData.MimeType)
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", dataSize))
 	w.Header().Set("Decompressed-Content-Length", fmt.Sprintf("%d", dataSize))
-	if _, err = io.Copy(w, bpr); err != nil {
-		s.Logger.Debugf("file download: data read %s: %v", addr, err)
-		s.Logger.Errorf("file download: data read %s", addr)
+	for name, values := range additionalHeaders {
+		w.Header().Del(name)
+		for _, v := range values {
+			w.Header().Add(name, v)
+		}
 	}
+
+	// media can be streamed with the range requests
+	s.serveContent(w, r, e.Reference(), reader)
 }
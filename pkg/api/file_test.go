@@ -6,6 +6,7 @@ package api_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"mime"
@@ -49,6 +50,42 @@ func TestFiles(t *testing.T) {
 		})
 	})
 
+	t.Run("tag-progress-headers", func(t *testing.T) {
+		data := []byte("data of the upload progress")
+
+		// the data, the metadata and the entry chunks are seen on the
+		// second upload of the same file
+		for _, wantSeen := range []string{"0", "3"} {
+			req, err := http.NewRequest(http.MethodPost, fileUploadResource+"?name=progress.txt", bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "text/plain")
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("got response status %s, want %v", resp.Status, http.StatusOK)
+			}
+
+			header := resp.Header
+			if header.Get(api.TagHeaderUid) == "" {
+				t.Error("no tag uid header")
+			}
+			if got := header.Get(api.TagHeaderTotal); got != "3" {
+				t.Errorf("got total %q, want %q", got, "3")
+			}
+			if got := header.Get(api.TagHeaderSeen); got != wantSeen {
+				t.Errorf("got seen %q, want %q", got, wantSeen)
+			}
+			if got := header.Get(api.TagHeaderSynced); got != "0" {
+				t.Errorf("got synced %q, want %q", got, "0")
+			}
+		}
+	})
+
 	t.Run("encrypt-decrypt", func(t *testing.T) {
 		fileName := "my-pictures.jpeg"
 		rootHash := "f2e761160deda91c1fbfab065a5abf530b0766b3e102b51fbd626ba37c3bc581"
@@ -190,4 +227,52 @@ func TestFiles(t *testing.T) {
 		})
 	})
 
+	t.Run("download-range", func(t *testing.T) {
+		data := make([]byte, 3*swarm.ChunkSize+100)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		req, err := http.NewRequest(http.MethodPost, fileUploadResource+"?name=video.mp4", bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "video/mp4")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var upload api.FileUploadResponse
+		if err := json.NewDecoder(resp.Body).Decode(&upload); err != nil {
+			t.Fatal(err)
+		}
+
+		// the range spans several data chunks
+		req, err = http.NewRequest(http.MethodGet, fileDownloadResource(upload.Reference.String()), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Range", "bytes=4000-8199")
+		resp, err = client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusPartialContent {
+			t.Fatalf("got response status %s, want %v %s", resp.Status, http.StatusPartialContent, http.StatusText(http.StatusPartialContent))
+		}
+		if got, want := resp.Header.Get("Content-Range"), fmt.Sprintf("bytes 4000-8199/%d", len(data)); got != want {
+			t.Fatalf("got content range %q, want %q", got, want)
+		}
+		if got := resp.Header.Get("Content-Type"); got != "video/mp4" {
+			t.Fatalf("got content type %q, want %q", got, "video/mp4")
+		}
+		got, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data[4000:8200]) {
+			t.Fatalf("data mismatch. got %x, want %x", got, data[4000:8200])
+		}
+	})
 }
@@ -0,0 +1,379 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/pinning"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/traversal"
+	"github.com/gorilla/mux"
+)
+
+var (
+	errInvalidReferenceType = errors.New("invalid reference type")
+	errPinTypeChanged       = errors.New("reference pinned with a different type")
+)
+
+type pinResponse struct {
+	Address swarm.Address `json:"address"`
+	Type    string        `json:"type"`
+	Counter uint64        `json:"counter"`
+}
+
+type listPinsResponse struct {
+	Pins []pinResponse `json:"pins"`
+}
+
+func newPinResponse(r *pinning.Record) pinResponse {
+	return pinResponse{
+		Address: r.Address,
+		Type:    r.Type,
+		Counter: r.Counter,
+	}
+}
+
+// referenceTraverser returns the function that traverses the chunks of the
+// reference of the type, which is bytes if the type is empty.
+func referenceTraverser(traverser traversal.Service, referenceType string) (func(context.Context, swarm.Address, traversal.AddressIterFunc) error, bool) {
+	switch referenceType {
+	case "", "bytes":
+		return traverser.TraverseBytesAddresses, true
+	case "file":
+		return traverser.TraverseFileAddresses, true
+	case "manifest":
+		return traverser.TraverseManifestAddresses, true
+	}
+	return nil, false
+}
+
+// pinnedAddresses returns the addresses of all chunks of the content with the
+// root reference of the type. Every address is returned once, as the pin
+// counters of the chunks are changed in a single batch.
+func (s *server) pinnedAddresses(ctx context.Context, reference swarm.Address, referenceType string) ([]swarm.Address, error) {
+	traverse, ok := referenceTraverser(traversal.NewService(s.Storer), referenceType)
+	if !ok {
+		return nil, errInvalidReferenceType
+	}
+
+	var addresses []swarm.Address
+	seen := make(map[string]struct{})
+	err := traverse(ctx, reference, func(chunkAddr swarm.Address) error {
+		if _, ok := seen[chunkAddr.ByteString()]; ok {
+			return nil
+		}
+		seen[chunkAddr.ByteString()] = struct{}{}
+		addresses = append(addresses, chunkAddr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// pinLock is the lock of the pin of a single reference, which is removed
+// once no request holds or waits for it.
+type pinLock struct {
+	mu      sync.Mutex
+	waiting int // requests that hold or wait for the lock
+}
+
+// lockPin locks the pin of the reference and returns the function that
+// unlocks it, so that the pins of different references are changed
+// concurrently.
+func (s *server) lockPin(reference swarm.Address) (unlock func()) {
+	key := reference.ByteString()
+
+	s.pinLocksMu.Lock()
+	l, ok := s.pinLocks[key]
+	if !ok {
+		l = new(pinLock)
+		s.pinLocks[key] = l
+	}
+	l.waiting++
+	s.pinLocksMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+
+		s.pinLocksMu.Lock()
+		l.waiting--
+		if l.waiting == 0 {
+			delete(s.pinLocks, key)
+		}
+		s.pinLocksMu.Unlock()
+	}
+}
+
+// pinHandler pins all chunks of the content with the root reference, so that
+// they are not garbage collected. The chunks that are not stored locally are
+// retrieved from the network. The content is pinned once more if it is
+// already pinned, and it is kept until it is unpinned the same number of
+// times.
+func (s *server) pinHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Pins == nil {
+		jsonhttp.NotImplemented(w, "pinning not supported")
+		return
+	}
+
+	addr := mux.Vars(r)["address"]
+	reference, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("pin: parse address %s: %v", addr, err)
+		s.Logger.Error("pin: parse address")
+		jsonhttp.BadRequest(w, "invalid address")
+		return
+	}
+	referenceType := r.URL.Query().Get("type")
+
+	record, err := s.Pins.Get(reference)
+	switch {
+	case err == nil:
+		if referenceType != "" && referenceType != record.Type {
+			s.Logger.Debugf("pin: reference %s type %q, pinned as %q", reference, referenceType, record.Type)
+			s.Logger.Errorf("pin: reference type %s", reference)
+			jsonhttp.BadRequest(w, errPinTypeChanged.Error())
+			return
+		}
+		referenceType = record.Type
+	case errors.Is(err, pinning.ErrNotFound):
+		if referenceType == "" {
+			referenceType = "bytes"
+		}
+	default:
+		s.Logger.Debugf("pin: get %s: %v", reference, err)
+		s.Logger.Errorf("pin: get %s", reference)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	// the chunks are traversed without the lock, as they may need to be
+	// retrieved from the network
+	addresses, err := s.pinnedAddresses(r.Context(), reference, referenceType)
+	if err != nil {
+		s.Logger.Debugf("pin: traverse %s: %v", reference, err)
+		s.Logger.Errorf("pin: traverse %s", reference)
+		respondTraversalError(w, err)
+		return
+	}
+
+	unlock := s.lockPin(reference)
+	defer unlock()
+
+	// the record is read again, as it may have been changed while the
+	// chunks were traversed
+	record, err = s.Pins.Get(reference)
+	switch {
+	case err == nil:
+		if referenceType != record.Type {
+			s.Logger.Debugf("pin: reference %s type %q, pinned as %q", reference, referenceType, record.Type)
+			s.Logger.Errorf("pin: reference type %s", reference)
+			jsonhttp.BadRequest(w, errPinTypeChanged.Error())
+			return
+		}
+	case errors.Is(err, pinning.ErrNotFound):
+		record = &pinning.Record{
+			Address: reference,
+			Type:    referenceType,
+		}
+	default:
+		s.Logger.Debugf("pin: get %s: %v", reference, err)
+		s.Logger.Errorf("pin: get %s", reference)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	if err := s.Storer.Set(r.Context(), storage.ModeSetPin, addresses...); err != nil {
+		s.Logger.Debugf("pin: pin chunks %s: %v", reference, err)
+		s.Logger.Errorf("pin: pin chunks %s", reference)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	record.Counter++
+	if err := s.Pins.Put(record); err != nil {
+		s.Logger.Debugf("pin: put %s: %v", reference, err)
+		s.Logger.Errorf("pin: put %s", reference)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, newPinResponse(record))
+}
+
+// unpinHandler unpins all chunks of the pinned content with the root
+// reference once.
+func (s *server) unpinHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Pins == nil {
+		jsonhttp.NotImplemented(w, "pinning not supported")
+		return
+	}
+
+	addr := mux.Vars(r)["address"]
+	reference, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("unpin: parse address %s: %v", addr, err)
+		s.Logger.Error("unpin: parse address")
+		jsonhttp.BadRequest(w, "invalid address")
+		return
+	}
+
+	record, err := s.Pins.Get(reference)
+	if err != nil {
+		s.Logger.Debugf("unpin: get %s: %v", reference, err)
+		s.Logger.Errorf("unpin: get %s", reference)
+		if errors.Is(err, pinning.ErrNotFound) {
+			jsonhttp.NotFound(w, "content not pinned")
+			return
+		}
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+	referenceType := record.Type
+
+	// the chunks are traversed without the lock, as they may need to be
+	// retrieved from the network
+	addresses, err := s.pinnedAddresses(r.Context(), reference, referenceType)
+	if err != nil {
+		s.Logger.Debugf("unpin: traverse %s: %v", reference, err)
+		s.Logger.Errorf("unpin: traverse %s", reference)
+		respondTraversalError(w, err)
+		return
+	}
+
+	unlock := s.lockPin(reference)
+	defer unlock()
+
+	// the record is read again, as it may have been changed while the
+	// chunks were traversed
+	record, err = s.Pins.Get(reference)
+	if err != nil {
+		s.Logger.Debugf("unpin: get %s: %v", reference, err)
+		s.Logger.Errorf("unpin: get %s", reference)
+		if errors.Is(err, pinning.ErrNotFound) {
+			jsonhttp.NotFound(w, "content not pinned")
+			return
+		}
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+	if record.Type != referenceType {
+		s.Logger.Debugf("unpin: reference %s type %q, pinned as %q", reference, referenceType, record.Type)
+		s.Logger.Errorf("unpin: reference type %s", reference)
+		jsonhttp.Conflict(w, errPinTypeChanged.Error())
+		return
+	}
+
+	if err := s.Storer.Set(r.Context(), storage.ModeSetUnpin, addresses...); err != nil {
+		s.Logger.Debugf("unpin: unpin chunks %s: %v", reference, err)
+		s.Logger.Errorf("unpin: unpin chunks %s", reference)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	record.Counter--
+	if record.Counter == 0 {
+		err = s.Pins.Delete(reference)
+	} else {
+		err = s.Pins.Put(record)
+	}
+	if err != nil {
+		s.Logger.Debugf("unpin: update %s: %v", reference, err)
+		s.Logger.Errorf("unpin: update %s", reference)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, newPinResponse(record))
+}
+
+// getPinHandler returns the pinned content with the root reference.
+func (s *server) getPinHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Pins == nil {
+		jsonhttp.NotImplemented(w, "pinning not supported")
+		return
+	}
+
+	addr := mux.Vars(r)["address"]
+	reference, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("get pin: parse address %s: %v", addr, err)
+		s.Logger.Error("get pin: parse address")
+		jsonhttp.BadRequest(w, "invalid address")
+		return
+	}
+
+	record, err := s.Pins.Get(reference)
+	if err != nil {
+		s.Logger.Debugf("get pin: get %s: %v", reference, err)
+		s.Logger.Errorf("get pin: get %s", reference)
+		if errors.Is(err, pinning.ErrNotFound) {
+			jsonhttp.NotFound(w, "content not pinned")
+			return
+		}
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, newPinResponse(record))
+}
+
+// listPinsHandler returns the pinned content within the pagination of the
+// request.
+func (s *server) listPinsHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Pins == nil {
+		jsonhttp.NotImplemented(w, "pinning not supported")
+		return
+	}
+	if !jsonhttp.NegotiateJSON(w, r) {
+		return
+	}
+	page, err := jsonhttp.ParsePagination(r, 0)
+	if err != nil {
+		s.Logger.Debugf("list pins: %v", err)
+		jsonhttp.RespondError(w, err)
+		return
+	}
+
+	records, err := s.Pins.List()
+	if err != nil {
+		s.Logger.Debugf("list pins: %v", err)
+		s.Logger.Error("list pins")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	start, end := page.Bounds(len(records))
+	resp := listPinsResponse{
+		Pins: make([]pinResponse, 0, end-start),
+	}
+	for i := start; i < end; i++ {
+		resp.Pins = append(resp.Pins, newPinResponse(&records[i]))
+	}
+	jsonhttp.OK(w, resp)
+}
+
+// respondTraversalError responds with the status for the error of the
+// traversal of the chunks of a reference.
+func respondTraversalError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		jsonhttp.NotFound(w, nil)
+	case errors.Is(err, traversal.ErrInvalidReference):
+		jsonhttp.BadRequest(w, "invalid reference")
+	case errors.Is(err, errInvalidReferenceType):
+		jsonhttp.BadRequest(w, "invalid reference type")
+	default:
+		jsonhttp.InternalServerError(w, nil)
+	}
+}
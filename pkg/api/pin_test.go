@@ -0,0 +1,219 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	filetest "github.com/ethersphere/bee/pkg/file/testing"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/pinning"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+// TestPin tests that all chunks of the pinned content are pinned and
+// unpinned with the root reference.
+func TestPin(t *testing.T) {
+	var (
+		mockStorer = mock.NewStorer()
+		client     = newTestServer(t, testServerOptions{
+			Storer: mockStorer,
+			Tags:   tags.NewTags(),
+			Pins:   pinning.New(statestore.NewStateStore()),
+		})
+		pinResource = func(a swarm.Address) string { return "/pins/" + a.String() }
+	)
+
+	var upload api.BytesPostResponse
+	jsonhttptest.ResponseUnmarshal(t, client, http.MethodPost, "/bytes", bytes.NewReader(filetest.GenerateTestData(t, swarm.ChunkSize*2)), http.StatusOK, &upload)
+	reference := upload.Reference
+
+	// checkPinned checks the pin counter of all chunks of the content
+	checkPinned := func(t *testing.T, chunks int, counter uint64) {
+		t.Helper()
+
+		pinned, _ := mockStorer.PinnedChunks(context.Background(), swarm.ZeroAddress)
+		if len(pinned) != chunks {
+			t.Fatalf("got %d pinned chunks, want %d", len(pinned), chunks)
+		}
+		for _, p := range pinned {
+			if p.PinCounter != counter {
+				t.Fatalf("got pin counter %d of chunk %s, want %d", p.PinCounter, p.Address, counter)
+			}
+		}
+	}
+
+	t.Run("not pinned", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, pinResource(reference), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "content not pinned",
+			Code:    http.StatusNotFound,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodDelete, pinResource(reference), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "content not pinned",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("pin", func(t *testing.T) {
+		for counter := uint64(1); counter <= 2; counter++ {
+			jsonhttptest.ResponseDirect(t, client, http.MethodPost, pinResource(reference)+"?type=bytes", nil, http.StatusOK, api.PinResponse{
+				Address: reference,
+				Type:    "bytes",
+				Counter: counter,
+			})
+			checkPinned(t, 3, counter)
+		}
+
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, pinResource(reference), nil, http.StatusOK, api.PinResponse{
+			Address: reference,
+			Type:    "bytes",
+			Counter: 2,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/pins", nil, http.StatusOK, api.ListPinsResponse{
+			Pins: []api.PinResponse{
+				{Address: reference, Type: "bytes", Counter: 2},
+			},
+		})
+	})
+
+	t.Run("different type", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, pinResource(reference)+"?type=file", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "reference pinned with a different type",
+			Code:    http.StatusBadRequest,
+		})
+		checkPinned(t, 3, 2)
+	})
+
+	t.Run("unpin", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodDelete, pinResource(reference), nil, http.StatusOK, api.PinResponse{
+			Address: reference,
+			Type:    "bytes",
+			Counter: 1,
+		})
+		checkPinned(t, 3, 1)
+
+		jsonhttptest.ResponseDirect(t, client, http.MethodDelete, pinResource(reference), nil, http.StatusOK, api.PinResponse{
+			Address: reference,
+			Type:    "bytes",
+			Counter: 0,
+		})
+		checkPinned(t, 0, 0)
+
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/pins", nil, http.StatusOK, api.ListPinsResponse{
+			Pins: []api.PinResponse{},
+		})
+	})
+
+	t.Run("file", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/files?name=file.txt", bytes.NewReader(filetest.GenerateTestData(t, 100)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "text/plain")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var upload api.FileUploadResponse
+		if err := json.NewDecoder(resp.Body).Decode(&upload); err != nil {
+			t.Fatal(err)
+		}
+
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, pinResource(upload.Reference)+"?type=file", nil, http.StatusOK, api.PinResponse{
+			Address: upload.Reference,
+			Type:    "file",
+			Counter: 1,
+		})
+		// entry, metadata and data chunks
+		checkPinned(t, 3, 1)
+
+		jsonhttptest.ResponseDirect(t, client, http.MethodDelete, pinResource(upload.Reference), nil, http.StatusOK, api.PinResponse{
+			Address: upload.Reference,
+			Type:    "file",
+			Counter: 0,
+		})
+		checkPinned(t, 0, 0)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		const n = 10
+
+		// do sends the requests at the same time and checks their status
+		do := func(t *testing.T, method string) {
+			t.Helper()
+
+			var wg sync.WaitGroup
+			errs := make(chan error, n)
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					req, err := http.NewRequest(method, pinResource(reference), nil)
+					if err != nil {
+						errs <- err
+						return
+					}
+					resp, err := client.Do(req)
+					if err != nil {
+						errs <- err
+						return
+					}
+					resp.Body.Close()
+					if resp.StatusCode != http.StatusOK {
+						errs <- fmt.Errorf("got status %d", resp.StatusCode)
+					}
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Fatal(err)
+			}
+		}
+
+		do(t, http.MethodPost)
+		checkPinned(t, 3, n)
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, pinResource(reference), nil, http.StatusOK, api.PinResponse{
+			Address: reference,
+			Type:    "bytes",
+			Counter: n,
+		})
+
+		do(t, http.MethodDelete)
+		checkPinned(t, 0, 0)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		missing := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, pinResource(missing), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusNotFound),
+			Code:    http.StatusNotFound,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, pinResource(missing), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "content not pinned",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		other := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59d")
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, pinResource(other)+"?type=dir", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid reference type",
+			Code:    http.StatusBadRequest,
+		})
+	})
+}
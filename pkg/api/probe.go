@@ -0,0 +1,174 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/traversal"
+	"github.com/ethersphere/bee/pkg/validator"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultProbeSample = 10  // number of chunks probed if the sample size is not set
+	maxProbeSample     = 100 // maximal number of chunks probed in a single request
+	probeWorkers       = 8   // number of chunks retrieved at the same time
+)
+
+var (
+	maxProbeTraversed    = 1 << 16          // maximal number of chunks traversed to take the sample from
+	probeRetrieveTimeout = 10 * time.Second // time given to retrieve a single chunk
+)
+
+var errProbeTraversed = errors.New("probe: traversal limit reached")
+
+type probeResponse struct {
+	Reference swarm.Address      `json:"reference"`
+	Total     int                `json:"total"`
+	Truncated bool               `json:"truncated"`
+	Probed    int                `json:"probed"`
+	Retrieved int                `json:"retrieved"`
+	Chunks    []probeChunkResult `json:"chunks"`
+}
+
+type probeChunkResult struct {
+	Address   swarm.Address `json:"address"`
+	Retrieved bool          `json:"retrieved"`
+	Latency   string        `json:"latency"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// probeHandler retrieves a random sample of the reference chunks from the
+// network, bypassing the local store, and reports which chunks were
+// retrieved and how long it took. The sample is taken while the reference is
+// traversed, and at most maxProbeTraversed chunks are traversed, in which
+// case the response is marked as truncated.
+func (s *server) probeHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["reference"]
+	reference, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("probe: parse reference %s: %v", addr, err)
+		s.Logger.Error("probe: parse reference error")
+		jsonhttp.BadRequest(w, "invalid reference")
+		return
+	}
+
+	t := r.URL.Query().Get("type")
+	traverse, ok := referenceTraverser(traversal.NewService(s.Storer), t)
+	if !ok {
+		s.Logger.Debugf("probe: invalid reference type %q", t)
+		s.Logger.Error("probe: invalid reference type")
+		jsonhttp.BadRequest(w, "invalid reference type")
+		return
+	}
+
+	sample := defaultProbeSample
+	if v := r.URL.Query().Get("sample"); v != "" {
+		sample, err = strconv.Atoi(v)
+		if err != nil || sample < 1 || sample > maxProbeSample {
+			s.Logger.Debugf("probe: invalid sample size %q: %v", v, err)
+			s.Logger.Error("probe: invalid sample size")
+			jsonhttp.BadRequest(w, "invalid sample size")
+			return
+		}
+	}
+
+	resp := probeResponse{
+		Reference: reference,
+		Chunks:    []probeChunkResult{},
+	}
+	// reservoir sampling keeps every traversed chunk in the sample with the
+	// same probability without holding all the addresses
+	addresses := make([]swarm.Address, 0, sample)
+	err = traverse(r.Context(), reference, func(chunkAddr swarm.Address) error {
+		if resp.Total == maxProbeTraversed {
+			return errProbeTraversed
+		}
+		resp.Total++
+		if len(addresses) < sample {
+			addresses = append(addresses, chunkAddr)
+		} else if i := rand.Intn(resp.Total); i < sample {
+			addresses[i] = chunkAddr
+		}
+		return nil
+	})
+	if errors.Is(err, errProbeTraversed) {
+		resp.Truncated = true
+		err = nil
+	}
+	if err != nil {
+		s.Logger.Debugf("probe: traverse %s: %v", reference, err)
+		if errors.Is(err, storage.ErrNotFound) {
+			s.Logger.Errorf("probe: not found %s", reference)
+			jsonhttp.NotFound(w, nil)
+			return
+		}
+		if errors.Is(err, traversal.ErrInvalidReference) {
+			s.Logger.Errorf("probe: invalid reference %s", reference)
+			jsonhttp.BadRequest(w, "invalid reference")
+			return
+		}
+		s.Logger.Errorf("probe: traverse %s", reference)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	results := make([]probeChunkResult, len(addresses))
+	sem := make(chan struct{}, probeWorkers)
+	var wg sync.WaitGroup
+	for i, chunkAddr := range addresses {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, chunkAddr swarm.Address) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = s.probeChunk(r.Context(), chunkAddr)
+		}(i, chunkAddr)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		if result.Retrieved {
+			resp.Retrieved++
+		}
+		resp.Probed++
+		resp.Chunks = append(resp.Chunks, result)
+	}
+
+	jsonhttp.OK(w, resp)
+}
+
+// probeChunk retrieves the chunk from the network within the
+// probeRetrieveTimeout and validates it.
+func (s *server) probeChunk(ctx context.Context, chunkAddr swarm.Address) probeChunkResult {
+	ctx, cancel := context.WithTimeout(ctx, probeRetrieveTimeout)
+	defer cancel()
+
+	result := probeChunkResult{Address: chunkAddr}
+	start := time.Now()
+	data, err := s.Retrieval.RetrieveChunk(ctx, chunkAddr)
+	result.Latency = time.Since(start).String()
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+	case !validator.NewContentAddressValidator().Validate(swarm.NewChunk(chunkAddr, data)):
+		result.Error = storage.ErrInvalidChunk.Error()
+	default:
+		result.Retrieved = true
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	filetest "github.com/ethersphere/bee/pkg/file/testing"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	retrievalmock "github.com/ethersphere/bee/pkg/retrieval/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+// TestProbe tests that the probe reports the chunks retrieved from the
+// network.
+func TestProbe(t *testing.T) {
+	var (
+		mockStorer = mock.NewStorer()
+		missing    swarm.Address
+		// chunks are retrieved from the storer, except the missing chunk
+		retrieval = retrievalmock.New(func(ctx context.Context, addr swarm.Address) ([]byte, error) {
+			if addr.Equal(missing) {
+				return nil, errors.New("chunk not retrieved")
+			}
+			ch, err := mockStorer.Get(ctx, storage.ModeGetRequest, addr)
+			if err != nil {
+				return nil, err
+			}
+			return ch.Data(), nil
+		})
+		client = newTestServer(t, testServerOptions{
+			Storer:    mockStorer,
+			Retrieval: retrieval,
+			Tags:      tags.NewTags(),
+		})
+	)
+	content := filetest.GenerateTestData(t, swarm.ChunkSize*2)
+
+	var upload api.BytesPostResponse
+	jsonhttptest.ResponseUnmarshal(t, client, http.MethodPost, "/bytes", bytes.NewReader(content), http.StatusOK, &upload)
+	reference := upload.Reference
+	missing = reference
+
+	t.Run("all chunks", func(t *testing.T) {
+		var resp api.ProbeResponse
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodPost, "/probe/"+reference.String()+"?sample=100", nil, http.StatusOK, &resp)
+
+		if !resp.Reference.Equal(reference) {
+			t.Errorf("got reference %s, want %s", resp.Reference, reference)
+		}
+		if resp.Total != 3 || resp.Probed != 3 || resp.Retrieved != 2 {
+			t.Errorf("got total %v, probed %v, retrieved %v, want 3, 3, 2", resp.Total, resp.Probed, resp.Retrieved)
+		}
+		for _, c := range resp.Chunks {
+			missing := c.Address.Equal(reference)
+			if c.Retrieved == missing || (c.Error != "") != missing {
+				t.Errorf("unexpected result for chunk %s: %+v", c.Address, c)
+			}
+			if c.Latency == "" {
+				t.Errorf("missing latency for chunk %s", c.Address)
+			}
+		}
+	})
+
+	t.Run("sample", func(t *testing.T) {
+		var resp api.ProbeResponse
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodPost, "/probe/"+reference.String()+"?sample=1", nil, http.StatusOK, &resp)
+
+		if resp.Total != 3 || resp.Probed != 1 || len(resp.Chunks) != 1 {
+			t.Errorf("got total %v, probed %v, chunks %v, want 3, 1, 1", resp.Total, resp.Probed, len(resp.Chunks))
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		defer func(n int) {
+			*api.MaxProbeTraversed = n
+		}(*api.MaxProbeTraversed)
+		*api.MaxProbeTraversed = 2
+
+		var resp api.ProbeResponse
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodPost, "/probe/"+reference.String()+"?sample=100", nil, http.StatusOK, &resp)
+
+		if resp.Total != 2 || !resp.Truncated || resp.Probed != 2 {
+			t.Errorf("got total %v, truncated %v, probed %v, want 2, true, 2", resp.Total, resp.Truncated, resp.Probed)
+		}
+	})
+
+	t.Run("invalid sample", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, "/probe/"+reference.String()+"?sample=0", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid sample size",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, "/probe/"+reference.String()+"?type=dir", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid reference type",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, "/probe/"+swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000").String(), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusNotFound),
+			Code:    http.StatusNotFound,
+		})
+	})
+}
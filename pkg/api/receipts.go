@@ -0,0 +1,105 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/receipts"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/traversal"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// maxReceiptsMissing is the maximal number of the addresses of the
+	// chunks without receipts that are reported.
+	maxReceiptsMissing = 100
+	// noStorerPO is the farthest storer proximity order reported when no
+	// receipt has a storer.
+	noStorerPO = -1
+)
+
+type receiptsResponse struct {
+	Reference      swarm.Address   `json:"reference"`
+	Total          int             `json:"total"`
+	WithReceipt    int             `json:"withReceipt"`
+	WithoutReceipt int             `json:"withoutReceipt"`
+	Missing        []swarm.Address `json:"missing"`
+	// FarthestStorerPO is the lowest proximity order of a storer to its
+	// chunk, or noStorerPO if no receipt has a storer.
+	FarthestStorerPO int `json:"farthestStorerPO"`
+}
+
+// fileReceiptsHandler reports the push sync receipts of all chunks of a file.
+func (s *server) fileReceiptsHandler(w http.ResponseWriter, r *http.Request) {
+	s.receiptsHandler(w, r, mux.Vars(r)["addr"], traversal.NewService(s.Storer).TraverseFileAddresses)
+}
+
+// bytesReceiptsHandler reports the push sync receipts of all chunks of raw
+// binary data.
+func (s *server) bytesReceiptsHandler(w http.ResponseWriter, r *http.Request) {
+	s.receiptsHandler(w, r, mux.Vars(r)["address"], traversal.NewService(s.Storer).TraverseBytesAddresses)
+}
+
+func (s *server) receiptsHandler(w http.ResponseWriter, r *http.Request, addr string, traverse func(context.Context, swarm.Address, traversal.AddressIterFunc) error) {
+	address, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("receipts: parse address %s: %v", addr, err)
+		s.Logger.Error("receipts: parse address error")
+		jsonhttp.BadRequest(w, "invalid address")
+		return
+	}
+
+	resp := receiptsResponse{
+		Reference:        address,
+		Missing:          []swarm.Address{},
+		FarthestStorerPO: noStorerPO,
+	}
+	err = traverse(r.Context(), address, func(chunkAddr swarm.Address) error {
+		resp.Total++
+		receipt, err := s.Receipts.Get(chunkAddr)
+		if err != nil {
+			if errors.Is(err, receipts.ErrNotFound) {
+				resp.WithoutReceipt++
+				if len(resp.Missing) < maxReceiptsMissing {
+					resp.Missing = append(resp.Missing, chunkAddr)
+				}
+				return nil
+			}
+			return err
+		}
+		resp.WithReceipt++
+		if !receipt.Storer.IsZero() {
+			po := int(swarm.Proximity(receipt.Storer.Bytes(), chunkAddr.Bytes()))
+			if resp.FarthestStorerPO == noStorerPO || po < resp.FarthestStorerPO {
+				resp.FarthestStorerPO = po
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.Logger.Debugf("receipts: traverse %s: %v", address, err)
+		if errors.Is(err, storage.ErrNotFound) {
+			s.Logger.Errorf("receipts: not found %s", address)
+			jsonhttp.NotFound(w, nil)
+			return
+		}
+		if errors.Is(err, traversal.ErrInvalidReference) {
+			s.Logger.Errorf("receipts: invalid reference %s", address)
+			jsonhttp.BadRequest(w, "invalid address")
+			return
+		}
+		s.Logger.Errorf("receipts: traverse %s", address)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, resp)
+}
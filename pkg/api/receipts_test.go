@@ -0,0 +1,96 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	filetest "github.com/ethersphere/bee/pkg/file/testing"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/receipts"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/traversal"
+)
+
+// TestBytesReceipts tests that the receipts report of uploaded data
+// accounts for the chunks with and without stored receipts.
+func TestBytesReceipts(t *testing.T) {
+	var (
+		resource     = "/bytes"
+		mockStorer   = mock.NewStorer()
+		receiptStore = receipts.New(statestore.NewStateStore())
+		client       = newTestServer(t, testServerOptions{
+			Storer:   mockStorer,
+			Receipts: receiptStore,
+			Tags:     tags.NewTags(),
+		})
+	)
+	content := filetest.GenerateTestData(t, swarm.ChunkSize*2)
+
+	var upload api.BytesPostResponse
+	jsonhttptest.ResponseUnmarshal(t, client, http.MethodPost, resource, bytes.NewReader(content), http.StatusOK, &upload)
+	reference := upload.Reference
+
+	// store receipts for all chunks except the root chunk
+	// from storers with proximity order 3 to the chunk
+	err := traversal.NewService(mockStorer).TraverseBytesAddresses(context.Background(), reference, func(addr swarm.Address) error {
+		if addr.Equal(reference) {
+			return nil
+		}
+		storer := append([]byte(nil), addr.Bytes()...)
+		storer[0] ^= 0x10
+		return receiptStore.Put(pushsync.Receipt{Address: addr, Storer: swarm.NewAddress(storer)})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("report", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, resource+"/"+reference.String()+"/receipts", nil, http.StatusOK, api.ReceiptsResponse{
+			Reference:        reference,
+			Total:            3,
+			WithReceipt:      2,
+			WithoutReceipt:   1,
+			Missing:          []swarm.Address{reference},
+			FarthestStorerPO: 3,
+		})
+	})
+
+	t.Run("no receipts", func(t *testing.T) {
+		var upload api.BytesPostResponse
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodPost, resource, bytes.NewReader([]byte("no receipts")), http.StatusOK, &upload)
+
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, resource+"/"+upload.Reference.String()+"/receipts", nil, http.StatusOK, api.ReceiptsResponse{
+			Reference:        upload.Reference,
+			Total:            1,
+			WithoutReceipt:   1,
+			Missing:          []swarm.Address{upload.Reference},
+			FarthestStorerPO: -1,
+		})
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, resource+"/"+swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000").String()+"/receipts", nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusNotFound),
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, resource+"/abcd/receipts", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid address",
+			Code:    http.StatusBadRequest,
+		})
+	})
+}
@@ -7,6 +7,7 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/logging"
@@ -16,9 +17,18 @@ import (
 	"resenje.org/web"
 )
 
+// limitRetryAfter is the time after which the requests that are rejected
+// over the concurrency limits should be retried.
+const limitRetryAfter = 5 * time.Second
+
 func (s *server) setupRouting() {
 	apiVersion := "v1" // only one api version exists, this should be configurable with more
 
+	// the requests that store and retrieve content are limited separately,
+	// as they take the most resources
+	uploadLimit := s.uploadLimiter.Handler
+	downloadLimit := s.downloadLimiter.Handler
+
 	handle := func(router *mux.Router, path string, handler http.Handler) {
 		router.Handle(path, handler)
 		router.Handle("/"+apiVersion+path, handler)
@@ -36,22 +46,70 @@ func (s *server) setupRouting() {
 	})
 
 	handle(router, "/files", jsonhttp.MethodHandler{
-		"POST": http.HandlerFunc(s.fileUploadHandler),
+		"POST": uploadLimit(http.HandlerFunc(s.fileUploadHandler)),
 	})
 	handle(router, "/files/{addr}", jsonhttp.MethodHandler{
-		"GET": http.HandlerFunc(s.fileDownloadHandler),
+		"GET": downloadLimit(http.HandlerFunc(s.fileDownloadHandler)),
+	})
+	handle(router, "/files/{addr}/receipts", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.fileReceiptsHandler),
+	})
+
+	handle(router, "/upload-sessions/{id}", jsonhttp.MethodHandler{
+		"GET":    http.HandlerFunc(s.uploadSessionGetHandler),
+		"DELETE": http.HandlerFunc(s.uploadSessionDeleteHandler),
+	})
+
+	handle(router, "/upload-keys", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.listUploadKeysHandler),
+	})
+	handle(router, "/upload-keys/{address}", jsonhttp.MethodHandler{
+		"GET":    http.HandlerFunc(s.getUploadKeyHandler),
+		"DELETE": http.HandlerFunc(s.deleteUploadKeyHandler),
+	})
+	handle(router, "/upload-keys/{address}/export", jsonhttp.MethodHandler{
+		"POST": http.HandlerFunc(s.exportUploadKeyHandler),
+	})
+
+	handle(router, "/dirs", jsonhttp.MethodHandler{
+		"POST": uploadLimit(http.HandlerFunc(s.dirUploadHandler)),
+	})
+
+	handle(router, "/bzz/{address}/{path:.*}", jsonhttp.MethodHandler{
+		"GET": downloadLimit(http.HandlerFunc(s.bzzDownloadHandler)),
 	})
 
 	handle(router, "/bytes", jsonhttp.MethodHandler{
-		"POST": http.HandlerFunc(s.bytesUploadHandler),
+		"POST": uploadLimit(http.HandlerFunc(s.bytesUploadHandler)),
 	})
 	handle(router, "/bytes/{address}", jsonhttp.MethodHandler{
-		"GET": http.HandlerFunc(s.bytesGetHandler),
+		"GET": downloadLimit(http.HandlerFunc(s.bytesGetHandler)),
+	})
+	handle(router, "/bytes/{address}/receipts", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.bytesReceiptsHandler),
+	})
+
+	handle(router, "/downloads/{id}", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.downloadStatusHandler),
 	})
 
 	handle(router, "/chunks/{addr}", jsonhttp.MethodHandler{
-		"GET":  http.HandlerFunc(s.chunkGetHandler),
-		"POST": http.HandlerFunc(s.chunkUploadHandler),
+		"GET":  downloadLimit(http.HandlerFunc(s.chunkGetHandler)),
+		"POST": uploadLimit(http.HandlerFunc(s.chunkUploadHandler)),
+	})
+
+	handle(router, "/pins", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.listPinsHandler),
+	})
+
+	handle(router, "/pins/{address}", jsonhttp.MethodHandler{
+		"GET":    http.HandlerFunc(s.getPinHandler),
+		"POST":   downloadLimit(http.HandlerFunc(s.pinHandler)),
+		"DELETE": http.HandlerFunc(s.unpinHandler),
+	})
+
+	handle(router, "/probe/{reference}", jsonhttp.MethodHandler{
+		"POST": downloadLimit(http.HandlerFunc(s.probeHandler)),
 	})
 
 	s.Handler = web.ChainHandlers(
@@ -59,27 +117,8 @@ func (s *server) setupRouting() {
 		handlers.CompressHandler,
 		// todo: add recovery handler
 		s.pageviewMetricsHandler,
-		func(h http.Handler) http.Handler {
-			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if o := r.Header.Get("Origin"); o != "" && (s.CORSAllowedOrigins == nil || containsOrigin(o, s.CORSAllowedOrigins)) {
-					w.Header().Set("Access-Control-Allow-Credentials", "true")
-					w.Header().Set("Access-Control-Allow-Origin", o)
-					w.Header().Set("Access-Control-Allow-Headers", "Origin, Accept, Authorization, Content-Type, X-Requested-With, Access-Control-Request-Headers, Access-Control-Request-Method")
-					w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS, POST, PUT, DELETE")
-					w.Header().Set("Access-Control-Max-Age", "3600")
-				}
-				h.ServeHTTP(w, r)
-			})
-		},
+		s.tracingHandler,
+		s.cors.Handler,
 		web.FinalHandler(router),
 	)
 }
-
-func containsOrigin(s string, l []string) (ok bool) {
-	for _, e := range l {
-		if e == s || e == "*" {
-			return true
-		}
-	}
-	return false
-}
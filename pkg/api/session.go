@@ -0,0 +1,356 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/uploadsession"
+	"github.com/gorilla/mux"
+)
+
+const (
+	UploadSessionHeader = "swarm-upload-session"
+	UploadOffsetHeader  = "swarm-upload-offset"
+	UploadLengthHeader  = "swarm-upload-length"
+
+	// uploadSessionStateInterval is the number of data chunks that are
+	// written between the saves of the session state during an upload.
+	uploadSessionStateInterval = 256
+)
+
+type uploadSessionResponse struct {
+	Session string `json:"session"`
+	Offset  int64  `json:"offset"`
+	Length  int64  `json:"length"`
+}
+
+func newUploadSessionResponse(session *uploadsession.Session) uploadSessionResponse {
+	return uploadSessionResponse{
+		Session: session.ID,
+		Offset:  session.Offset,
+		Length:  session.Length,
+	}
+}
+
+// uploadSessionCreateHandler creates the session of a resumable upload of the
+// file with the length from the upload length header. The file data is
+// uploaded to the session by the uploadSessionWriteHandler.
+func (s *server) uploadSessionCreateHandler(w http.ResponseWriter, r *http.Request) {
+	if s.UploadSessions == nil {
+		jsonhttp.NotImplemented(w, "resumable uploads not supported")
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		s.Logger.Debugf("upload session: parse content type header %q: %v", contentType, err)
+		s.Logger.Errorf("upload session: parse content type header %q", contentType)
+		jsonhttp.BadRequest(w, "invalid content-type header")
+		return
+	}
+	if mediaType == multiPartFormData {
+		jsonhttp.BadRequest(w, "multipart upload cannot be resumed")
+		return
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get(UploadLengthHeader), 10, 64)
+	if err != nil || length < 0 {
+		s.Logger.Debugf("upload session: parse upload length header %q: %v", r.Header.Get(UploadLengthHeader), err)
+		s.Logger.Errorf("upload session: parse upload length header %q", r.Header.Get(UploadLengthHeader))
+		jsonhttp.BadRequest(w, "invalid upload length header")
+		return
+	}
+
+	tag, err := s.getOrCreateTag(r.Header.Get(TagHeaderUid))
+	if err != nil {
+		s.Logger.Debugf("upload session: get or create tag: %v", err)
+		s.Logger.Error("upload session: get or create tag")
+		if errors.Is(err, errInvalidTagUid) {
+			jsonhttp.BadRequest(w, "invalid taguid")
+			return
+		}
+		jsonhttp.InternalServerError(w, "cannot create tag")
+		return
+	}
+
+	session := &uploadsession.Session{
+		FileName:    r.URL.Query().Get("name"),
+		ContentType: contentType,
+		Length:      length,
+		ToEncrypt:   strings.ToLower(r.Header.Get(EncryptHeader)) == "true",
+		TagUid:      tag.Uid,
+	}
+	if err := s.UploadSessions.Create(session); err != nil {
+		s.Logger.Debugf("upload session: create: %v", err)
+		s.Logger.Error("upload session: create")
+		jsonhttp.InternalServerError(w, "cannot create upload session")
+		return
+	}
+
+	setTagHeaders(w, tag)
+	w.Header().Set(UploadSessionHeader, session.ID)
+	jsonhttp.Created(w, newUploadSessionResponse(session))
+}
+
+// uploadSessionWriteHandler continues the upload of the session with the data
+// in the request body, which starts at the offset from the upload offset
+// header. The data before the offset of the session is skipped, as it is
+// already stored, while a gap after it is rejected. The file is stored once
+// all of its data is uploaded, and the session is kept otherwise, so that the
+// upload can be resumed by the next request.
+func (s *server) uploadSessionWriteHandler(w http.ResponseWriter, r *http.Request, id string) {
+	if s.UploadSessions == nil {
+		jsonhttp.NotImplemented(w, "resumable uploads not supported")
+		return
+	}
+
+	release, ok := s.acquireUploadSession(id)
+	if !ok {
+		jsonhttp.Conflict(w, "upload session in use")
+		return
+	}
+	defer release()
+
+	session, err := s.UploadSessions.Get(id)
+	if err != nil {
+		s.Logger.Debugf("upload session: get %s: %v", id, err)
+		s.Logger.Errorf("upload session: get %s", id)
+		if errors.Is(err, uploadsession.ErrNotFound) {
+			jsonhttp.NotFound(w, "upload session not found")
+			return
+		}
+		jsonhttp.InternalServerError(w, "cannot get upload session")
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get(UploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		s.Logger.Debugf("upload session: parse upload offset header %q: %v", r.Header.Get(UploadOffsetHeader), err)
+		s.Logger.Errorf("upload session: parse upload offset header %q", r.Header.Get(UploadOffsetHeader))
+		jsonhttp.BadRequest(w, "invalid upload offset header")
+		return
+	}
+	if offset > session.Offset {
+		w.Header().Set(UploadOffsetHeader, fmt.Sprint(session.Offset))
+		jsonhttp.Conflict(w, newUploadSessionResponse(session))
+		return
+	}
+	if _, err := io.CopyN(ioutil.Discard, r.Body, session.Offset-offset); err != nil {
+		s.Logger.Debugf("upload session: skip stored data %s: %v", id, err)
+		s.Logger.Errorf("upload session: skip stored data %s", id)
+		jsonhttp.BadRequest(w, "upload data ends before the session offset")
+		return
+	}
+
+	// the chunks of the session are counted on the tag of the session, or
+	// on a new one if it no longer exists
+	tag, err := s.Tags.Get(session.TagUid)
+	if err != nil {
+		if !errors.Is(err, tags.ErrNotFound) {
+			s.Logger.Debugf("upload session: get tag %d: %v", session.TagUid, err)
+			s.Logger.Errorf("upload session: get tag %d", session.TagUid)
+			jsonhttp.InternalServerError(w, "cannot get tag")
+			return
+		}
+		if tag, err = s.getOrCreateTag(""); err != nil {
+			s.Logger.Debugf("upload session: create tag: %v", err)
+			s.Logger.Error("upload session: create tag")
+			jsonhttp.InternalServerError(w, "cannot create tag")
+			return
+		}
+		session.TagUid = tag.Uid
+	}
+
+	// the chunks of an incomplete upload are not removed, as they are needed
+	// to resume it, and the chunks that are stored again by a retried upload
+	// are deduplicated by the storer
+	ctx := r.Context()
+	putter := newUploadPutter(s.Storer, tag)
+	var job *splitter.ResumableJob
+	if session.SplitterState == nil {
+		job = splitter.NewResumableJob(ctx, putter, session.Length, session.ToEncrypt)
+	} else {
+		job, err = splitter.ResumeJob(ctx, putter, session.SplitterState)
+		if err != nil {
+			s.Logger.Debugf("upload session: resume splitter %s: %v", id, err)
+			s.Logger.Errorf("upload session: resume splitter %s", id)
+			jsonhttp.InternalServerError(w, "cannot resume upload")
+			return
+		}
+	}
+
+	if session.Length == 0 {
+		if _, err := job.Write(nil); err != nil {
+			s.Logger.Debugf("upload session: write data %s: %v", id, err)
+			s.Logger.Errorf("upload session: write data %s", id)
+			jsonhttp.InternalServerError(w, "could not store file")
+			return
+		}
+	}
+
+	buf := make([]byte, swarm.ChunkSize)
+	for chunks := 1; job.Written() < session.Length; chunks++ {
+		n := int64(len(buf))
+		if remaining := session.Length - job.Written(); remaining < n {
+			n = remaining
+		}
+		_, err := io.ReadFull(r.Body, buf[:n])
+		if err == nil {
+			// the chunks can not be stored once the request is canceled,
+			// so the state is saved before the next write fails
+			err = ctx.Err()
+		}
+		if err != nil {
+			// the upload is interrupted and its state is saved up to
+			// the last complete chunk
+			if err := s.saveUploadSession(session, job); err != nil {
+				s.Logger.Debugf("upload session: save %s: %v", id, err)
+				s.Logger.Errorf("upload session: save %s", id)
+				jsonhttp.InternalServerError(w, "cannot save upload session")
+				return
+			}
+			setTagHeaders(w, tag)
+			w.Header().Set(UploadOffsetHeader, fmt.Sprint(session.Offset))
+			jsonhttp.Accepted(w, newUploadSessionResponse(session))
+			return
+		}
+		if _, err := job.Write(buf[:n]); err != nil {
+			s.Logger.Debugf("upload session: write data %s: %v", id, err)
+			s.Logger.Errorf("upload session: write data %s", id)
+			jsonhttp.InternalServerError(w, "could not store file")
+			return
+		}
+		if chunks%uploadSessionStateInterval == 0 && job.Written() < session.Length {
+			if err := s.saveUploadSession(session, job); err != nil {
+				s.Logger.Debugf("upload session: save %s: %v", id, err)
+				s.Logger.Errorf("upload session: save %s", id)
+				jsonhttp.InternalServerError(w, "cannot save upload session")
+				return
+			}
+		}
+	}
+
+	reference, err := s.storeFileEntry(ctx, putter, job.Sum(), session.FileName, session.ContentType, session.ToEncrypt)
+	if err != nil {
+		s.Logger.Debugf("upload session: store file %s: %v", id, err)
+		s.Logger.Errorf("upload session: store file %s", id)
+		jsonhttp.InternalServerError(w, "could not store file")
+		return
+	}
+	if err := s.UploadSessions.Delete(id); err != nil {
+		s.Logger.Debugf("upload session: delete %s: %v", id, err)
+		s.Logger.Errorf("upload session: delete %s", id)
+	}
+	tag.DoneSplit(reference)
+
+	setTagHeaders(w, tag)
+	w.Header().Set("ETag", fmt.Sprintf("%q", reference.String()))
+	jsonhttp.OK(w, fileUploadResponse{
+		Reference: reference,
+	})
+}
+
+// saveUploadSession stores the session with the state of the splitter job.
+func (s *server) saveUploadSession(session *uploadsession.Session, job *splitter.ResumableJob) error {
+	state, err := job.State()
+	if err != nil {
+		return err
+	}
+	session.SplitterState = state
+	session.Offset = job.Written()
+	return s.UploadSessions.Put(session)
+}
+
+// uploadSessionGetHandler returns the offset from which the upload of the
+// session is continued.
+func (s *server) uploadSessionGetHandler(w http.ResponseWriter, r *http.Request) {
+	if s.UploadSessions == nil {
+		jsonhttp.NotImplemented(w, "resumable uploads not supported")
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	session, err := s.UploadSessions.Get(id)
+	if err != nil {
+		s.Logger.Debugf("upload session: get %s: %v", id, err)
+		s.Logger.Errorf("upload session: get %s", id)
+		if errors.Is(err, uploadsession.ErrNotFound) {
+			jsonhttp.NotFound(w, "upload session not found")
+			return
+		}
+		jsonhttp.InternalServerError(w, "cannot get upload session")
+		return
+	}
+
+	w.Header().Set(UploadOffsetHeader, fmt.Sprint(session.Offset))
+	jsonhttp.OK(w, newUploadSessionResponse(session))
+}
+
+// uploadSessionDeleteHandler cancels the upload of the session. The chunks
+// that are already stored are kept.
+func (s *server) uploadSessionDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if s.UploadSessions == nil {
+		jsonhttp.NotImplemented(w, "resumable uploads not supported")
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	release, ok := s.acquireUploadSession(id)
+	if !ok {
+		jsonhttp.Conflict(w, "upload session in use")
+		return
+	}
+	defer release()
+
+	if _, err := s.UploadSessions.Get(id); err != nil {
+		s.Logger.Debugf("upload session: get %s: %v", id, err)
+		s.Logger.Errorf("upload session: get %s", id)
+		if errors.Is(err, uploadsession.ErrNotFound) {
+			jsonhttp.NotFound(w, "upload session not found")
+			return
+		}
+		jsonhttp.InternalServerError(w, "cannot get upload session")
+		return
+	}
+	if err := s.UploadSessions.Delete(id); err != nil {
+		s.Logger.Debugf("upload session: delete %s: %v", id, err)
+		s.Logger.Errorf("upload session: delete %s", id)
+		jsonhttp.InternalServerError(w, "cannot delete upload session")
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
+
+// acquireUploadSession marks the upload session as in use by the request
+// and returns the function that releases it. It returns false if the
+// session is already in use by another request.
+func (s *server) acquireUploadSession(id string) (release func(), ok bool) {
+	s.activeUploadSessionsMu.Lock()
+	defer s.activeUploadSessionsMu.Unlock()
+
+	if _, ok := s.activeUploadSessions[id]; ok {
+		return nil, false
+	}
+	s.activeUploadSessions[id] = struct{}{}
+	return func() {
+		s.activeUploadSessionsMu.Lock()
+		delete(s.activeUploadSessions, id)
+		s.activeUploadSessionsMu.Unlock()
+	}, true
+}
@@ -0,0 +1,234 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	filetest "github.com/ethersphere/bee/pkg/file/testing"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/uploadsession"
+)
+
+func TestUploadSession(t *testing.T) {
+	var (
+		data   = filetest.GenerateTestData(t, swarm.ChunkSize*5+100)
+		client = newTestServer(t, testServerOptions{
+			Storer:         mock.NewStorer(),
+			Tags:           tags.NewTags(),
+			UploadSessions: uploadsession.New(statestore.NewStateStore()),
+		})
+	)
+
+	// the reference of the file uploaded at once
+	want := func() api.FileUploadResponse {
+		c := newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Tags:   tags.NewTags(),
+		})
+		resp := sessionRequest(t, c, "/files?name=data.bin", data, http.StatusOK, map[string]string{
+			"Content-Type": "application/octet-stream",
+		})
+		var r api.FileUploadResponse
+		decodeResponse(t, resp, &r)
+		return r
+	}()
+
+	resp := sessionRequest(t, client, "/files?resume=true&name=data.bin", nil, http.StatusCreated, map[string]string{
+		"Content-Type":         "application/octet-stream",
+		api.UploadLengthHeader: fmt.Sprint(len(data)),
+	})
+	var created api.UploadSessionResponse
+	decodeResponse(t, resp, &created)
+	if created.Session == "" || resp.Header.Get(api.UploadSessionHeader) != created.Session {
+		t.Fatalf("got session %q and header %q", created.Session, resp.Header.Get(api.UploadSessionHeader))
+	}
+	sessionResource := "/files?session=" + created.Session
+
+	// the interrupted upload is stored up to the last complete chunk
+	resp = sessionRequest(t, client, sessionResource, data[:swarm.ChunkSize*2+10], http.StatusAccepted, map[string]string{
+		api.UploadOffsetHeader: "0",
+	})
+	var partial api.UploadSessionResponse
+	decodeResponse(t, resp, &partial)
+	if partial.Offset != swarm.ChunkSize*2 || partial.Length != int64(len(data)) {
+		t.Fatalf("got offset %d and length %d, want %d and %d", partial.Offset, partial.Length, swarm.ChunkSize*2, len(data))
+	}
+
+	resp = request(t, client, http.MethodGet, "/upload-sessions/"+created.Session, nil, http.StatusOK)
+	if got := resp.Header.Get(api.UploadOffsetHeader); got != fmt.Sprint(swarm.ChunkSize*2) {
+		t.Fatalf("got offset header %q, want %d", got, swarm.ChunkSize*2)
+	}
+	resp.Body.Close()
+
+	t.Run("offset-gap", func(t *testing.T) {
+		resp := sessionRequest(t, client, sessionResource, data[swarm.ChunkSize*3:], http.StatusConflict, map[string]string{
+			api.UploadOffsetHeader: fmt.Sprint(swarm.ChunkSize * 3),
+		})
+		resp.Body.Close()
+	})
+
+	// the data before the session offset is skipped on the retry
+	resp = sessionRequest(t, client, sessionResource, data[swarm.ChunkSize:], http.StatusOK, map[string]string{
+		api.UploadOffsetHeader: fmt.Sprint(swarm.ChunkSize),
+	})
+	var got api.FileUploadResponse
+	decodeResponse(t, resp, &got)
+	if !got.Reference.Equal(want.Reference) {
+		t.Fatalf("got reference %s, want %s", got.Reference, want.Reference)
+	}
+
+	request(t, client, http.MethodGet, "/upload-sessions/"+created.Session, nil, http.StatusNotFound).Body.Close()
+
+	resp = request(t, client, http.MethodGet, "/files/"+got.Reference.String(), nil, http.StatusOK)
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, data) {
+		t.Fatal("downloaded data differs from the uploaded data")
+	}
+}
+
+func TestUploadSessionDelete(t *testing.T) {
+	client := newTestServer(t, testServerOptions{
+		Storer:         mock.NewStorer(),
+		Tags:           tags.NewTags(),
+		UploadSessions: uploadsession.New(statestore.NewStateStore()),
+	})
+
+	resp := sessionRequest(t, client, "/files?resume=true", nil, http.StatusCreated, map[string]string{
+		"Content-Type":         "text/plain",
+		api.UploadLengthHeader: "10",
+	})
+	var created api.UploadSessionResponse
+	decodeResponse(t, resp, &created)
+
+	request(t, client, http.MethodDelete, "/upload-sessions/"+created.Session, nil, http.StatusOK).Body.Close()
+	request(t, client, http.MethodDelete, "/upload-sessions/"+created.Session, nil, http.StatusNotFound).Body.Close()
+	sessionRequest(t, client, "/files?session="+created.Session, []byte("0123456789"), http.StatusNotFound, map[string]string{
+		api.UploadOffsetHeader: "0",
+	}).Body.Close()
+}
+
+// TestUploadSessionInUse checks that the session is written by one request at
+// a time.
+func TestUploadSessionInUse(t *testing.T) {
+	data := filetest.GenerateTestData(t, swarm.ChunkSize*3)
+	tagStore := tags.NewTags()
+	client := newTestServer(t, testServerOptions{
+		Storer:         mock.NewStorer(),
+		Tags:           tagStore,
+		UploadSessions: uploadsession.New(statestore.NewStateStore()),
+	})
+
+	resp := sessionRequest(t, client, "/files?resume=true", nil, http.StatusCreated, map[string]string{
+		"Content-Type":         "application/octet-stream",
+		api.UploadLengthHeader: fmt.Sprint(len(data)),
+	})
+	tagUid, err := strconv.ParseUint(resp.Header.Get(api.TagHeaderUid), 10, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created api.UploadSessionResponse
+	decodeResponse(t, resp, &created)
+	sessionResource := "/files?session=" + created.Session
+
+	pr, pw := io.Pipe()
+	req, err := http.NewRequest(http.MethodPost, sessionResource, pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(api.UploadOffsetHeader, "0")
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		resp, err := client.Do(req)
+		results <- result{resp: resp, err: err}
+	}()
+
+	// the first chunk is stored by the handler once the session is in use
+	if _, err := pw.Write(data[:swarm.ChunkSize]); err != nil {
+		t.Fatal(err)
+	}
+	tag, err := tagStore.Get(uint32(tagUid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; tag.Get(tags.StateStored) == 0; i++ {
+		if i == 100 {
+			t.Fatal("first chunk not stored")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	sessionRequest(t, client, sessionResource, data, http.StatusConflict, map[string]string{
+		api.UploadOffsetHeader: "0",
+	}).Body.Close()
+	request(t, client, http.MethodDelete, "/upload-sessions/"+created.Session, nil, http.StatusConflict).Body.Close()
+
+	if err := pw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r := <-results
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if r.resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("got response status %s, want %v", r.resp.Status, http.StatusAccepted)
+	}
+	var partial api.UploadSessionResponse
+	decodeResponse(t, r.resp, &partial)
+	if partial.Offset != swarm.ChunkSize {
+		t.Fatalf("got offset %d, want %d", partial.Offset, swarm.ChunkSize)
+	}
+
+	// the session is released after the request
+	request(t, client, http.MethodDelete, "/upload-sessions/"+created.Session, nil, http.StatusOK).Body.Close()
+}
+
+func sessionRequest(t *testing.T, client *http.Client, resource string, body []byte, responseCode int, headers map[string]string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, resource, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != responseCode {
+		t.Fatalf("got response status %s, want %v %s", resp.Status, responseCode, http.StatusText(responseCode))
+	}
+	return resp
+}
+
+func decodeResponse(t *testing.T, resp *http.Response, v interface{}) {
+	t.Helper()
+
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// tracingHandler starts a tracing span for every API request, so that the
+// chunks that are retrieved or pushed in the request context can be followed
+// across the nodes. The span is a child of the span context in the request
+// HTTP headers, if the client provided one.
+func (s *server) tracingHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, _ := s.Tracer.WithContextFromHTTPHeaders(r.Context(), r.Header)
+
+		span, _, ctx := s.Tracer.StartSpanFromContext(ctx, "api-request", nil)
+		defer span.Finish()
+
+		ext.SpanKindRPCServer.Set(span)
+		ext.HTTPMethod.Set(span, r.Method)
+		ext.HTTPUrl.Set(span, r.URL.Path)
+
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+// Headers of the upload responses that report the progress of the upload
+// with the counters of its tag.
+const (
+	TagHeaderTotal  = "swarm-tag-total"
+	TagHeaderSeen   = "swarm-tag-seen"
+	TagHeaderSynced = "swarm-tag-synced"
+)
+
+var (
+	errInvalidTagUid = errors.New("invalid tag uid")
+	errUploadAborted = errors.New("upload aborted")
+)
+
+// getOrCreateTag returns the tag with the uid from the tag header value, or
+// a new tag if the value is empty.
+func (s *server) getOrCreateTag(tagUid string) (*tags.Tag, error) {
+	if tagUid == "" {
+		tagName := fmt.Sprintf("unnamed_tag_%d", time.Now().Unix())
+		return s.Tags.Create(tagName, 0, false)
+	}
+	uid, err := strconv.ParseUint(tagUid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidTagUid, err)
+	}
+	return s.Tags.Get(uint32(uid))
+}
+
+// setTagHeaders sets the uid of the tag of the upload and the progress of the
+// upload on the response. The total is the number of chunks of the tag, and
+// the seen and synced counts are the numbers of the chunks that were already
+// stored locally and that are synced to the network.
+func setTagHeaders(w http.ResponseWriter, tag *tags.Tag) {
+	w.Header().Set(TagHeaderUid, fmt.Sprint(tag.Uid))
+	w.Header().Set(TagHeaderTotal, fmt.Sprint(tag.TotalCounter()))
+	w.Header().Set(TagHeaderSeen, fmt.Sprint(tag.Get(tags.StateSeen)))
+	w.Header().Set(TagHeaderSynced, fmt.Sprint(tag.Get(tags.StateSynced)))
+	w.Header().Set("Access-Control-Expose-Headers", strings.Join([]string{TagHeaderUid, TagHeaderTotal, TagHeaderSeen, TagHeaderSynced}, ", "))
+}
+
+// uploadPutter stores the chunks of a single upload and counts them on the
+// tag of the upload, until the upload is completed or aborted.
+type uploadPutter struct {
+	storer    storage.Storer
+	tag       *tags.Tag
+	stored    []swarm.Address // chunks that were not stored before the upload
+	mu        sync.Mutex
+	completed bool
+	aborted   bool
+}
+
+func newUploadPutter(storer storage.Storer, tag *tags.Tag) *uploadPutter {
+	return &uploadPutter{
+		storer: storer,
+		tag:    tag,
+	}
+}
+
+// Put implements the storage.Putter interface.
+func (p *uploadPutter) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// chunks of the splitter workers that are still running after the
+	// upload has been aborted must not be stored
+	if p.aborted || p.completed {
+		return nil, errUploadAborted
+	}
+
+	exist, err = p.storer.Put(ctx, mode, chs...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range chs {
+		p.tag.Inc(tags.StateSplit)
+		p.tag.Inc(tags.StateStored)
+		if exist[i] {
+			p.tag.Inc(tags.StateSeen)
+		} else {
+			p.stored = append(p.stored, chs[i].Address())
+		}
+	}
+	return exist, nil
+}
+
+// complete stops the storing of the chunks of the upload once all of its
+// chunks are stored, so that they are kept when the upload is aborted.
+func (p *uploadPutter) complete() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.completed = true
+}
+
+// abort stops the storing of the chunks of an incomplete upload, and
+// discards the chunks that were stored by it: they are removed from the push
+// sync index and left to the garbage collection. The chunks are not removed
+// immediately, as any of them may be stored by another upload of the same
+// content in the meantime, and the chunks that were stored before the upload
+// are left as they are.
+func (p *uploadPutter) abort() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.aborted || p.completed {
+		return nil
+	}
+	p.aborted = true
+
+	if len(p.stored) == 0 {
+		return nil
+	}
+	// the request context may be canceled already
+	return p.storer.Set(context.Background(), storage.ModeSetDiscard, p.stored...)
+}
+
+// abortUpload aborts the upload of the putter, if it is not completed.
+func (s *server) abortUpload(p *uploadPutter) {
+	if err := p.abort(); err != nil {
+		s.Logger.Debugf("upload: discard chunks of aborted upload: %v", err)
+		s.Logger.Error("upload: discard chunks of aborted upload")
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/uploadkeys"
+	"github.com/gorilla/mux"
+)
+
+// UploadKeyPasswordHeader is the header of the password with which the
+// reference of an encrypted upload is stored, and with which it is exported
+// and removed.
+const UploadKeyPasswordHeader = "swarm-upload-key-password"
+
+type uploadKeyResponse struct {
+	Address swarm.Address `json:"address"`
+	Name    string        `json:"name"`
+	Created time.Time     `json:"created"`
+}
+
+type listUploadKeysResponse struct {
+	Keys []uploadKeyResponse `json:"keys"`
+}
+
+type exportUploadKeyResponse struct {
+	Reference swarm.Address `json:"reference"`
+}
+
+func newUploadKeyResponse(k *uploadkeys.Key) uploadKeyResponse {
+	return uploadKeyResponse{
+		Address: k.Address,
+		Name:    k.Name,
+		Created: k.Created,
+	}
+}
+
+// uploadKeyPassword returns the password from the upload key password header
+// of the upload request, with which the reference of the upload is stored.
+// The password is empty if the reference is not stored. It responds with an
+// error and returns false if the reference cannot be stored.
+func (s *server) uploadKeyPassword(w http.ResponseWriter, r *http.Request, toEncrypt bool) (password string, ok bool) {
+	password = r.Header.Get(UploadKeyPasswordHeader)
+	if password == "" {
+		return "", true
+	}
+	if s.UploadKeys == nil {
+		jsonhttp.NotImplemented(w, "upload keys not supported")
+		return "", false
+	}
+	if !toEncrypt {
+		jsonhttp.BadRequest(w, "upload key password requires encryption")
+		return "", false
+	}
+	return password, true
+}
+
+// storeUploadKey stores the reference of the encrypted upload if the password
+// is not empty.
+func (s *server) storeUploadKey(reference swarm.Address, name, password string) error {
+	if password == "" {
+		return nil
+	}
+	_, err := s.UploadKeys.Put(reference, name, password)
+	return err
+}
+
+// listUploadKeysHandler returns the addresses of all encrypted uploads with
+// the stored references.
+func (s *server) listUploadKeysHandler(w http.ResponseWriter, r *http.Request) {
+	if s.UploadKeys == nil {
+		jsonhttp.NotImplemented(w, "upload keys not supported")
+		return
+	}
+
+	keys, err := s.UploadKeys.List()
+	if err != nil {
+		s.Logger.Debugf("list upload keys: %v", err)
+		s.Logger.Error("list upload keys")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	resp := listUploadKeysResponse{
+		Keys: make([]uploadKeyResponse, 0, len(keys)),
+	}
+	for i := range keys {
+		resp.Keys = append(resp.Keys, newUploadKeyResponse(&keys[i]))
+	}
+	jsonhttp.OK(w, resp)
+}
+
+// getUploadKeyHandler returns the stored reference of the encrypted upload
+// without the decryption key.
+func (s *server) getUploadKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if s.UploadKeys == nil {
+		jsonhttp.NotImplemented(w, "upload keys not supported")
+		return
+	}
+
+	address, ok := s.parseUploadKeyAddress(w, r)
+	if !ok {
+		return
+	}
+
+	k, err := s.UploadKeys.Get(address)
+	if err != nil {
+		s.respondUploadKeyError(w, "get upload key", address, err)
+		return
+	}
+	jsonhttp.OK(w, newUploadKeyResponse(k))
+}
+
+// exportUploadKeyHandler returns the reference of the encrypted upload with
+// the decryption key, which is decrypted with the password from the upload
+// key password header.
+func (s *server) exportUploadKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if s.UploadKeys == nil {
+		jsonhttp.NotImplemented(w, "upload keys not supported")
+		return
+	}
+
+	address, ok := s.parseUploadKeyAddress(w, r)
+	if !ok {
+		return
+	}
+	password := r.Header.Get(UploadKeyPasswordHeader)
+	if password == "" {
+		jsonhttp.BadRequest(w, "missing upload key password header")
+		return
+	}
+
+	reference, err := s.UploadKeys.Export(address, password)
+	if err != nil {
+		s.respondUploadKeyError(w, "export upload key", address, err)
+		return
+	}
+	jsonhttp.OK(w, exportUploadKeyResponse{
+		Reference: reference,
+	})
+}
+
+// deleteUploadKeyHandler removes the stored reference of the encrypted
+// upload if the password from the upload key password header is the one
+// that it is stored with. The content is not removed.
+func (s *server) deleteUploadKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if s.UploadKeys == nil {
+		jsonhttp.NotImplemented(w, "upload keys not supported")
+		return
+	}
+
+	address, ok := s.parseUploadKeyAddress(w, r)
+	if !ok {
+		return
+	}
+
+	password := r.Header.Get(UploadKeyPasswordHeader)
+	if password == "" {
+		jsonhttp.BadRequest(w, "missing upload key password header")
+		return
+	}
+
+	if err := s.UploadKeys.Delete(address, password); err != nil {
+		s.respondUploadKeyError(w, "delete upload key", address, err)
+		return
+	}
+	jsonhttp.OK(w, nil)
+}
+
+func (s *server) parseUploadKeyAddress(w http.ResponseWriter, r *http.Request) (swarm.Address, bool) {
+	addressHex := mux.Vars(r)["address"]
+	address, err := swarm.ParseHexAddress(addressHex)
+	if err != nil || len(address.Bytes()) != swarm.HashSize {
+		s.Logger.Debugf("upload keys: parse address %s: %v", addressHex, err)
+		s.Logger.Error("upload keys: parse address")
+		jsonhttp.BadRequest(w, "invalid address")
+		return swarm.ZeroAddress, false
+	}
+	return address, true
+}
+
+func (s *server) respondUploadKeyError(w http.ResponseWriter, msg string, address swarm.Address, err error) {
+	s.Logger.Debugf("%s %s: %v", msg, address, err)
+	s.Logger.Errorf("%s %s", msg, address)
+	switch {
+	case errors.Is(err, uploadkeys.ErrNotFound):
+		jsonhttp.NotFound(w, nil)
+	case errors.Is(err, uploadkeys.ErrInvalidPassword):
+		jsonhttp.Forbidden(w, "invalid upload key password")
+	default:
+		jsonhttp.InternalServerError(w, nil)
+	}
+}
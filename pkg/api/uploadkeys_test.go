@@ -0,0 +1,112 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/uploadkeys"
+)
+
+func TestUploadKeys(t *testing.T) {
+	client := newTestServer(t, testServerOptions{
+		Storer:     mock.NewStorer(),
+		Tags:       tags.NewTags(),
+		UploadKeys: uploadkeys.New(statestore.NewStateStore()),
+	})
+	data := []byte("encrypted content")
+
+	t.Run("not-encrypted", func(t *testing.T) {
+		resp := sessionRequest(t, client, "/bytes", data, http.StatusBadRequest, map[string]string{
+			api.UploadKeyPasswordHeader: "secret",
+		})
+		resp.Body.Close()
+	})
+
+	resp := sessionRequest(t, client, "/files?name=secret.txt", data, http.StatusOK, map[string]string{
+		"Content-Type":              "text/plain",
+		api.EncryptHeader:           "true",
+		api.UploadKeyPasswordHeader: "secret",
+	})
+	var uploaded api.FileUploadResponse
+	decodeResponse(t, resp, &uploaded)
+	if len(uploaded.Reference.Bytes()) != 2*swarm.HashSize {
+		t.Fatalf("got reference %s, want encrypted reference", uploaded.Reference)
+	}
+	address := swarm.NewAddress(uploaded.Reference.Bytes()[:swarm.HashSize])
+
+	resp = request(t, client, http.MethodGet, "/upload-keys", nil, http.StatusOK)
+	var list api.ListUploadKeysResponse
+	decodeResponse(t, resp, &list)
+	if len(list.Keys) != 1 || !list.Keys[0].Address.Equal(address) || list.Keys[0].Name != "secret.txt" {
+		t.Fatalf("got keys %+v", list.Keys)
+	}
+
+	t.Run("invalid-password", func(t *testing.T) {
+		resp := sessionRequest(t, client, "/upload-keys/"+address.String()+"/export", nil, http.StatusForbidden, map[string]string{
+			api.UploadKeyPasswordHeader: "wrong",
+		})
+		resp.Body.Close()
+	})
+
+	resp = sessionRequest(t, client, "/upload-keys/"+address.String()+"/export", nil, http.StatusOK, map[string]string{
+		api.UploadKeyPasswordHeader: "secret",
+	})
+	var exported api.ExportUploadKeyResponse
+	decodeResponse(t, resp, &exported)
+	if !exported.Reference.Equal(uploaded.Reference) {
+		t.Fatalf("got reference %s, want %s", exported.Reference, uploaded.Reference)
+	}
+
+	t.Run("delete-without-password", func(t *testing.T) {
+		deleteUploadKeyRequest(t, client, address, "", http.StatusBadRequest)
+	})
+	t.Run("delete-invalid-password", func(t *testing.T) {
+		deleteUploadKeyRequest(t, client, address, "wrong", http.StatusForbidden)
+	})
+
+	deleteUploadKeyRequest(t, client, address, "secret", http.StatusOK)
+	request(t, client, http.MethodGet, "/upload-keys/"+address.String(), nil, http.StatusNotFound).Body.Close()
+}
+
+func TestUploadKeysNotSupported(t *testing.T) {
+	client := newTestServer(t, testServerOptions{
+		Storer: mock.NewStorer(),
+		Tags:   tags.NewTags(),
+	})
+
+	request(t, client, http.MethodGet, "/upload-keys", nil, http.StatusNotImplemented).Body.Close()
+	resp := sessionRequest(t, client, "/bytes", []byte("data"), http.StatusNotImplemented, map[string]string{
+		api.EncryptHeader:           "true",
+		api.UploadKeyPasswordHeader: "secret",
+	})
+	resp.Body.Close()
+}
+
+func deleteUploadKeyRequest(t *testing.T, client *http.Client, address swarm.Address, password string, responseCode int) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodDelete, "/upload-keys/"+address.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if password != "" {
+		req.Header.Set(api.UploadKeyPasswordHeader, password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != responseCode {
+		t.Fatalf("got response status %s, want %v %s", resp.Status, responseCode, http.StatusText(responseCode))
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/clock"
+)
+
+var _ clock.Clock = (*Clock)(nil)
+
+// Clock is a clock.Clock whose time changes only when it is advanced. The
+// timers fire when the clock is advanced to their deadlines.
+type Clock struct {
+	now    time.Time
+	timers map[*timer]struct{} // active timers
+	mu     sync.Mutex
+}
+
+// New returns a new Clock with the time now.
+func New(now time.Time) *Clock {
+	return &Clock{
+		now:    now,
+		timers: make(map[*timer]struct{}),
+	}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+func (c *Clock) NewTimer(d time.Duration) clock.Timer {
+	t := &timer{
+		clock: c,
+		c:     make(chan time.Time, 1),
+	}
+	t.Reset(d)
+	return t
+}
+
+// Add advances the time of the clock by the duration and fires the timers
+// whose deadlines are reached.
+func (c *Clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for t := range c.timers {
+		if !t.deadline.After(c.now) {
+			c.fire(t)
+		}
+	}
+}
+
+// Timers returns the number of the timers that have not fired or have not
+// been stopped.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+// fire sends the time on the timer channel without blocking, as time.Timer
+// does, and deactivates the timer. It must be called with the lock held.
+func (c *Clock) fire(t *timer) {
+	delete(c.timers, t)
+	select {
+	case t.c <- c.now:
+	default:
+	}
+}
+
+type timer struct {
+	clock    *Clock
+	c        chan time.Time
+	deadline time.Time
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	_, active := t.clock.timers[t]
+	delete(t.clock.timers, t)
+	return active
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, active := c.timers[t]
+	t.deadline = c.now.Add(d)
+	if d <= 0 {
+		c.fire(t)
+		return active
+	}
+	c.timers[t] = struct{}{}
+	return active
+}
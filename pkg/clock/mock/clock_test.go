@@ -0,0 +1,100 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/clock/mock"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	c := mock.New(start)
+
+	if got := c.Now(); !got.Equal(start) {
+		t.Fatalf("got time %v, want %v", got, start)
+	}
+	c.Add(time.Minute)
+	if got := c.Since(start); got != time.Minute {
+		t.Fatalf("got duration %v, want %v", got, time.Minute)
+	}
+}
+
+func TestTimer(t *testing.T) {
+	c := mock.New(time.Unix(1600000000, 0))
+
+	timer := c.NewTimer(10 * time.Second)
+	after := c.After(20 * time.Second)
+	if got := c.Timers(); got != 2 {
+		t.Fatalf("got %d timers, want %d", got, 2)
+	}
+
+	c.Add(9 * time.Second)
+	assertNotFired(t, timer.C())
+
+	c.Add(time.Second)
+	assertFired(t, timer.C(), c.Now())
+	assertNotFired(t, after)
+	if timer.Stop() {
+		t.Fatal("fired timer stopped")
+	}
+
+	c.Add(10 * time.Second)
+	assertFired(t, after, c.Now())
+	if got := c.Timers(); got != 0 {
+		t.Fatalf("got %d timers, want %d", got, 0)
+	}
+
+	// reset timers fire after the duration from the current time
+	if timer.Reset(5 * time.Second) {
+		t.Fatal("reset of a fired timer reported active")
+	}
+	c.Add(4 * time.Second)
+	assertNotFired(t, timer.C())
+	if !timer.Reset(5 * time.Second) {
+		t.Fatal("reset of an active timer reported inactive")
+	}
+	c.Add(4 * time.Second)
+	assertNotFired(t, timer.C())
+	c.Add(time.Second)
+	assertFired(t, timer.C(), c.Now())
+
+	// stopped timers do not fire
+	timer.Reset(time.Second)
+	if !timer.Stop() {
+		t.Fatal("active timer not stopped")
+	}
+	c.Add(time.Second)
+	assertNotFired(t, timer.C())
+
+	// timers with no duration fire immediately
+	timer.Reset(0)
+	assertFired(t, timer.C(), c.Now())
+}
+
+func assertFired(t *testing.T, c <-chan time.Time, want time.Time) {
+	t.Helper()
+
+	select {
+	case got := <-c:
+		if !got.Equal(want) {
+			t.Fatalf("got fire time %v, want %v", got, want)
+		}
+	default:
+		t.Fatal("timer not fired")
+	}
+}
+
+func assertNotFired(t *testing.T, c <-chan time.Time) {
+	t.Helper()
+
+	select {
+	case <-c:
+		t.Fatal("timer fired")
+	default:
+	}
+}
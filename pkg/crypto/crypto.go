@@ -17,7 +17,10 @@ import (
 	"golang.org/x/crypto/sha3"
 )
 
-// NewOverlayAddress constructs a Swarm Address from ECDSA private key.
+// NewOverlayAddress constructs a Swarm Address from ECDSA public key. It is
+// the SHA3-256 hash of the ethereum address of the key followed by the
+// little endian network ID, so the same key has different overlay addresses
+// in different networks.
 func NewOverlayAddress(p ecdsa.PublicKey, networkID uint64) (swarm.Address, error) {
 	ethAddr, err := NewEthereumAddress(p)
 	if err != nil {
@@ -40,6 +43,11 @@ func EncodeSecp256k1PrivateKey(k *ecdsa.PrivateKey) []byte {
 	return (*btcec.PrivateKey)(k).Serialize()
 }
 
+// EncodeSecp256k1PublicKey encodes ECDSA public key in the compressed form.
+func EncodeSecp256k1PublicKey(k *ecdsa.PublicKey) []byte {
+	return (*btcec.PublicKey)(k).SerializeCompressed()
+}
+
 // DecodeSecp256k1PrivateKey decodes raw ECDSA private key.
 func DecodeSecp256k1PrivateKey(data []byte) (*ecdsa.PrivateKey, error) {
 	if l := len(data); l != btcec.PrivKeyBytesLen {
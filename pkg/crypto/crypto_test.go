@@ -7,8 +7,11 @@ package crypto_test
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/ethersphere/bee/pkg/crypto"
 )
 
@@ -62,6 +65,24 @@ func TestEncodeSecp256k1PrivateKey(t *testing.T) {
 	}
 }
 
+func TestEncodeSecp256k1PublicKey(t *testing.T) {
+	k, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := crypto.EncodeSecp256k1PublicKey(&k.PublicKey)
+	if l := len(d); l != btcec.PubKeyBytesLenCompressed {
+		t.Fatalf("got encoded public key length %v, want %v", l, btcec.PubKeyBytesLenCompressed)
+	}
+	p, err := btcec.ParsePubKey(d, btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.X.Cmp(k.PublicKey.X) != 0 || p.Y.Cmp(k.PublicKey.Y) != 0 {
+		t.Fatal("encoded and decoded public keys are not equal")
+	}
+}
+
 func TestNewEthereumAddress(t *testing.T) {
 	privKeyHex := "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
 	privKeyBytes, err := hex.DecodeString(privKeyHex)
@@ -85,3 +106,45 @@ func TestNewEthereumAddress(t *testing.T) {
 		t.Fatalf("address mismatch %x %x", address, expectAddress)
 	}
 }
+
+func TestMnemonic(t *testing.T) {
+	k1, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mnemonic, err := crypto.EncodeMnemonic(k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(strings.Fields(mnemonic)); n != 24 {
+		t.Fatalf("got %d words, want %d", n, 24)
+	}
+
+	// the words can be separated by any white space
+	k2, err := crypto.DecodeMnemonic(" " + strings.ReplaceAll(mnemonic, " ", "\n") + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(k1.D.Bytes(), k2.D.Bytes()) {
+		t.Fatal("decoded key is not the encoded key")
+	}
+
+	// BIP-39 test vector
+	k3, err := crypto.DecodeMnemonic("legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(crypto.EncodeSecp256k1PrivateKey(k3)), strings.Repeat("7f", 32); got != want {
+		t.Fatalf("got key %s, want %s", got, want)
+	}
+
+	// the checksum of the mnemonic is verified
+	if _, err := crypto.DecodeMnemonic("legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful"); !errors.Is(err, crypto.ErrInvalidMnemonic) {
+		t.Fatalf("got error %v, want %v", err, crypto.ErrInvalidMnemonic)
+	}
+
+	// the mnemonics of other lengths are not keys
+	if _, err := crypto.DecodeMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"); !errors.Is(err, crypto.ErrInvalidMnemonic) {
+		t.Fatalf("got error %v, want %v", err, crypto.ErrInvalidMnemonic)
+	}
+}
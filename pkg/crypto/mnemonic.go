@@ -0,0 +1,37 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// ErrInvalidMnemonic is returned by DecodeMnemonic if the mnemonic is not a
+// valid BIP-39 mnemonic of a private key.
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// EncodeMnemonic encodes the secp256k1 private key as the BIP-39 mnemonic
+// of 24 words, with the key as its entropy, so that the key can be written
+// down and restored with DecodeMnemonic.
+func EncodeMnemonic(k *ecdsa.PrivateKey) (string, error) {
+	return bip39.NewMnemonic(EncodeSecp256k1PrivateKey(k))
+}
+
+// DecodeMnemonic decodes the secp256k1 private key from the BIP-39 mnemonic
+// returned by EncodeMnemonic. The words can be separated by any white space.
+func DecodeMnemonic(mnemonic string) (*ecdsa.PrivateKey, error) {
+	entropy, err := bip39.EntropyFromMnemonic(strings.Join(strings.Fields(mnemonic), " "))
+	if err != nil {
+		return nil, ErrInvalidMnemonic
+	}
+	if len(entropy) != 32 {
+		return nil, ErrInvalidMnemonic
+	}
+	return DecodeSecp256k1PrivateKey(entropy)
+}
@@ -6,22 +6,38 @@ package crypto
 
 import (
 	"crypto/ecdsa"
+	"strconv"
 
 	"github.com/btcsuite/btcd/btcec"
 )
 
+// SignedMessagePrefix is prepended, together with the decimal length of the
+// message, to arbitrary messages before they are hashed and signed, in the
+// manner of EIP-191. It separates signatures of externally supplied messages
+// from the digests the node signs for protocol purposes.
+const SignedMessagePrefix = "\x19Swarm Signed Message:\n"
+
+// Signer signs data with the secp256k1 key of the node. The signatures are
+// in the compact form, from which the public key is recovered by Recover.
 type Signer interface {
 	Sign(data []byte) ([]byte, error)
 	PublicKey() (*ecdsa.PublicKey, error)
 }
 
-// Recover verifies signature with the data base provided.
-// It is using `btcec.RecoverCompact` function
+// Recover returns the public key of the signature of the data, created by
+// the Signer. It is using `btcec.RecoverCompact` function
 func Recover(signature, data []byte) (*ecdsa.PublicKey, error) {
 	p, _, err := btcec.RecoverCompact(btcec.S256(), signature, data)
 	return (*ecdsa.PublicKey)(p), err
 }
 
+// HashSignedMessage returns the keccak256 hash of the data prefixed with the
+// SignedMessagePrefix and the length of the data.
+func HashSignedMessage(data []byte) ([]byte, error) {
+	prefix := []byte(SignedMessagePrefix + strconv.Itoa(len(data)))
+	return legacyKeccak256(append(prefix, data...))
+}
+
 type defaultSigner struct {
 	key *ecdsa.PrivateKey
 }
@@ -5,6 +5,7 @@
 package crypto_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/crypto"
@@ -45,3 +46,21 @@ func TestDefaultSigner(t *testing.T) {
 		}
 	})
 }
+
+func TestHashSignedMessage(t *testing.T) {
+	digest, err := crypto.HashSignedMessage([]byte("test string"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(digest) != 32 {
+		t.Fatalf("got digest length %v, want 32", len(digest))
+	}
+
+	other, err := crypto.HashSignedMessage([]byte("test strin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(digest, other) {
+		t.Fatal("expected different digests for different messages")
+	}
+}
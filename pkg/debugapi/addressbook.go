@@ -0,0 +1,41 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/bzz"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+type addressbookResponse struct {
+	Addresses []bzz.Address `json:"addresses"`
+}
+
+// addressbookHandler responds with the signed overlay and underlay
+// addresses of all peers that are known to the node, from which the
+// topology dials them.
+func (s *server) addressbookHandler(w http.ResponseWriter, r *http.Request) {
+	if s.AddressBook == nil {
+		jsonhttp.NotImplemented(w, "addressbook not supported")
+		return
+	}
+
+	addresses, err := s.AddressBook.Addresses()
+	if err != nil {
+		s.Logger.Debugf("debug api: addressbook: %v", err)
+		s.Logger.Error("debug api: addressbook")
+		jsonhttp.InternalServerError(w, "cannot get addressbook")
+		return
+	}
+	if addresses == nil {
+		addresses = []bzz.Address{}
+	}
+
+	jsonhttp.OK(w, addressbookResponse{
+		Addresses: addresses,
+	})
+}
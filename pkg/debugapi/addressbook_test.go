@@ -0,0 +1,68 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/addressbook"
+	"github.com/ethersphere/bee/pkg/bzz"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestAddressbook(t *testing.T) {
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := crypto.NewOverlayAddress(pk.PublicKey, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	underlay, err := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/7070/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bzzAddress, err := bzz.NewAddress(crypto.NewDefaultSigner(pk), underlay, overlay, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book := addressbook.New(statestore.NewStateStore())
+	testServer := newTestServer(t, testServerOptions{
+		AddressBook: book,
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/addressbook", nil, http.StatusOK, debugapi.AddressbookResponse{
+			Addresses: []bzz.Address{},
+		})
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		if err := book.Put(overlay, *bzzAddress); err != nil {
+			t.Fatal(err)
+		}
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/addressbook", nil, http.StatusOK, debugapi.AddressbookResponse{
+			Addresses: []bzz.Address{*bzzAddress},
+		})
+	})
+}
+
+func TestAddressbookNotImplemented(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/addressbook", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+		Message: "addressbook not supported",
+		Code:    http.StatusNotImplemented,
+	})
+}
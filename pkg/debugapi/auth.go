@@ -0,0 +1,116 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+// role is the level of the access to the debug API that a request is
+// authorized with.
+type role int
+
+const (
+	roleNone role = iota
+	roleRead
+	roleAdmin
+)
+
+// authorize returns the role of the token that the request carries as the
+// bearer token, or as the password of the basic authentication with any
+// user name, in the Authorization header. The tokens are compared in
+// constant time.
+func (s *server) authorize(r *http.Request) role {
+	token, ok := requestToken(r)
+	if !ok {
+		return roleNone
+	}
+	if s.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.AdminToken)) == 1 {
+		return roleAdmin
+	}
+	if s.ReadToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.ReadToken)) == 1 {
+		return roleRead
+	}
+	return roleNone
+}
+
+// requestToken returns the bearer token or the basic authentication password
+// from the Authorization header of the request.
+func requestToken(r *http.Request) (token string, ok bool) {
+	if _, password, ok := r.BasicAuth(); ok {
+		return password, true
+	}
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if len(auth) < len(prefix) || auth[:len(prefix)] != prefix {
+		return "", false
+	}
+	return auth[len(prefix):], true
+}
+
+// unauthorized responds to the request that is not authorized with the
+// required role.
+func (s *server) unauthorized(w http.ResponseWriter, got role) {
+	if got != roleNone {
+		jsonhttp.Forbidden(w, "admin role required")
+		return
+	}
+	s.Logger.Debug("debug api: invalid token")
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	jsonhttp.Unauthorized(w, nil)
+}
+
+// adminAuthHandler serves only the requests that carry the admin token. If
+// the admin token is not set, all requests are forbidden.
+func (s *server) adminAuthHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.AdminToken == "" {
+			jsonhttp.Forbidden(w, "admin token not set")
+			return
+		}
+		if got := s.authorize(r); got != roleAdmin {
+			s.unauthorized(w, got)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// restrictedHandler serves the requests with methods other than GET, HEAD and
+// OPTIONS, which change the state of the node, only if they carry the admin
+// token, and forbids them if the admin token is not set, so that every new
+// endpoint that changes the state is protected without opting in. If the
+// Restricted option is set, the other requests are served only if they carry
+// the read or the admin token. The health and readiness checks are always
+// served.
+func (s *server) restrictedHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" || r.URL.Path == "/readiness" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		want := roleAdmin
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if !s.Restricted {
+				h.ServeHTTP(w, r)
+				return
+			}
+			want = roleRead
+		}
+		if want == roleAdmin && s.AdminToken == "" {
+			jsonhttp.Forbidden(w, "admin token not set")
+			return
+		}
+		if got := s.authorize(r); got < want {
+			s.unauthorized(w, got)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
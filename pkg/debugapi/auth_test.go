@@ -0,0 +1,102 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+func TestRestricted(t *testing.T) {
+	const (
+		adminToken = "admin-secret"
+		readToken  = "read-secret"
+	)
+
+	bearer := func(token string) http.Header {
+		return http.Header{"Authorization": {"Bearer " + token}}
+	}
+	basic := func(token string) http.Header {
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+		r.SetBasicAuth("operator", token)
+		return r.Header
+	}
+	// the preflight requests do not carry the credentials
+	preflight := http.Header{
+		"Origin":                        {"http://localhost:3000"},
+		"Access-Control-Request-Method": {http.MethodPost},
+	}
+
+	// the schema is not supported by the test server, so the served requests
+	// for it respond with the not implemented status
+	for _, tc := range []struct {
+		name       string
+		restricted bool
+		method     string
+		path       string
+		headers    http.Header
+		wantStatus int
+		// noAdminToken starts the server without the admin token
+		noAdminToken bool
+	}{
+		{name: "read with read token", restricted: true, method: http.MethodGet, path: "/schema", headers: bearer(readToken), wantStatus: http.StatusNotImplemented},
+		{name: "read with admin token", restricted: true, method: http.MethodGet, path: "/schema", headers: bearer(adminToken), wantStatus: http.StatusNotImplemented},
+		{name: "read with basic auth", restricted: true, method: http.MethodGet, path: "/schema", headers: basic(readToken), wantStatus: http.StatusNotImplemented},
+		{name: "read without token", restricted: true, method: http.MethodGet, path: "/schema", wantStatus: http.StatusUnauthorized},
+		{name: "read with wrong token", restricted: true, method: http.MethodGet, path: "/schema", headers: bearer("wrong"), wantStatus: http.StatusUnauthorized},
+		{name: "write with admin token", restricted: true, method: http.MethodPost, path: "/tags", headers: bearer(adminToken), wantStatus: http.StatusOK},
+		{name: "write with basic auth", restricted: true, method: http.MethodPost, path: "/tags", headers: basic(adminToken), wantStatus: http.StatusOK},
+		{name: "write with read token", restricted: true, method: http.MethodPost, path: "/tags", headers: bearer(readToken), wantStatus: http.StatusForbidden},
+		{name: "write without token", restricted: true, method: http.MethodPost, path: "/tags", wantStatus: http.StatusUnauthorized},
+		{name: "health", restricted: true, method: http.MethodGet, path: "/health", wantStatus: http.StatusOK},
+		{name: "readiness", restricted: true, method: http.MethodGet, path: "/readiness", wantStatus: http.StatusOK},
+		{name: "preflight", restricted: true, method: http.MethodOptions, path: "/schema", headers: preflight, wantStatus: http.StatusNoContent},
+		{name: "metrics", restricted: true, method: http.MethodGet, path: "/metrics", wantStatus: http.StatusOK},
+		{name: "admin endpoint with read token", restricted: false, method: http.MethodGet, path: "/public-key", headers: bearer(readToken), wantStatus: http.StatusForbidden},
+		{name: "not restricted read", restricted: false, method: http.MethodGet, path: "/schema", wantStatus: http.StatusNotImplemented},
+		{name: "not restricted write with admin token", restricted: false, method: http.MethodPost, path: "/tags", headers: bearer(adminToken), wantStatus: http.StatusOK},
+		{name: "not restricted write with read token", restricted: false, method: http.MethodPost, path: "/tags", headers: bearer(readToken), wantStatus: http.StatusForbidden},
+		{name: "not restricted write without token", restricted: false, method: http.MethodPost, path: "/tags", wantStatus: http.StatusUnauthorized},
+		{name: "not restricted delete without token", restricted: false, method: http.MethodDelete, path: "/chunks/" + swarm.ZeroAddress.String(), wantStatus: http.StatusUnauthorized},
+		{name: "write without admin token set", noAdminToken: true, method: http.MethodPost, path: "/tags", headers: bearer(adminToken), wantStatus: http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := testServerOptions{
+				Tags:       tags.NewTags(),
+				AdminToken: adminToken,
+				ReadToken:  readToken,
+				Restricted: tc.restricted,
+			}
+			if tc.noAdminToken {
+				o.AdminToken = ""
+				o.NoAdminToken = true
+			}
+			testServer := newTestServer(t, o)
+
+			req, err := http.NewRequest(tc.method, tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tc.headers {
+				req.Header[k] = v
+			}
+			resp, err := testServer.Client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("got status %v, want %v", resp.StatusCode, tc.wantStatus)
+			}
+			if tc.wantStatus == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("got WWW-Authenticate header %q, want %q", resp.Header.Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type balanceResponse struct {
+	Peer    string `json:"peer"`
+	Balance int64  `json:"balance"`
+}
+
+type balancesResponse struct {
+	Balances []balanceResponse `json:"balances"`
+}
+
+// balancesHandler responds with the balances with the peers within the
+// pagination of the request, sorted by the peer address.
+func (s *server) balancesHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Accounting == nil {
+		jsonhttp.NotImplemented(w, "accounting not supported")
+		return
+	}
+	if !jsonhttp.NegotiateJSON(w, r) {
+		return
+	}
+	page, err := jsonhttp.ParsePagination(r, 0)
+	if err != nil {
+		s.Logger.Debugf("debug api: balances: %v", err)
+		jsonhttp.RespondError(w, err)
+		return
+	}
+
+	balances, err := s.Accounting.Balances()
+	if err != nil {
+		s.Logger.Debugf("debug api: balances: %v", err)
+		s.Logger.Error("debug api: balances")
+		jsonhttp.InternalServerError(w, "cannot get balances")
+		return
+	}
+
+	resp := balancesResponse{
+		Balances: make([]balanceResponse, 0, len(balances)),
+	}
+	for peer, balance := range balances {
+		resp.Balances = append(resp.Balances, balanceResponse{
+			Peer:    peer,
+			Balance: balance,
+		})
+	}
+	sort.Slice(resp.Balances, func(i, j int) bool {
+		return resp.Balances[i].Peer < resp.Balances[j].Peer
+	})
+	start, end := page.Bounds(len(resp.Balances))
+	resp.Balances = resp.Balances[start:end]
+
+	jsonhttp.OK(w, resp)
+}
+
+// peerBalanceHandler responds with the balance with a single peer.
+func (s *server) peerBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Accounting == nil {
+		jsonhttp.NotImplemented(w, "accounting not supported")
+		return
+	}
+
+	peer, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.Logger.Debugf("debug api: balances peer: invalid peer address: %v", err)
+		s.Logger.Error("debug api: balances peer: invalid peer address")
+		jsonhttp.BadRequest(w, "invalid peer address")
+		return
+	}
+
+	balance, err := s.Accounting.Balance(peer)
+	if err != nil {
+		s.Logger.Debugf("debug api: balances peer: get peer %s balance: %v", peer, err)
+		s.Logger.Errorf("debug api: balances peer: get peer %s balance", peer)
+		jsonhttp.InternalServerError(w, "cannot get peer balance")
+		return
+	}
+
+	jsonhttp.OK(w, balanceResponse{
+		Peer:    peer.String(),
+		Balance: balance,
+	})
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	accountingmock "github.com/ethersphere/bee/pkg/accounting/mock"
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestBalances(t *testing.T) {
+	peer1 := swarm.MustParseHexAddress("b0baf377")
+	peer2 := swarm.MustParseHexAddress("a0baf377")
+
+	acc := accountingmock.NewAccounting()
+	if err := acc.Debit(peer1, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := acc.Credit(peer2, 30); err != nil {
+		t.Fatal(err)
+	}
+
+	testServer := newTestServer(t, testServerOptions{
+		Accounting: acc,
+	})
+
+	t.Run("all", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/balances", nil, http.StatusOK, debugapi.BalancesResponse{
+			Balances: []debugapi.BalanceResponse{
+				{Peer: peer2.String(), Balance: -30},
+				{Peer: peer1.String(), Balance: 100},
+			},
+		})
+	})
+
+	t.Run("page", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/balances?offset=1&limit=1", nil, http.StatusOK, debugapi.BalancesResponse{
+			Balances: []debugapi.BalanceResponse{
+				{Peer: peer1.String(), Balance: 100},
+			},
+		})
+	})
+
+	t.Run("invalid page", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/balances?limit=-1", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid limit",
+			Code:    http.StatusBadRequest,
+			Reason:  jsonhttp.ReasonInvalidPagination,
+		})
+	})
+
+	t.Run("peer", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/balances/"+peer1.String(), nil, http.StatusOK, debugapi.BalanceResponse{
+			Peer:    peer1.String(),
+			Balance: 100,
+		})
+	})
+
+	t.Run("invalid peer", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/balances/invalid-address", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid peer address",
+			Code:    http.StatusBadRequest,
+		})
+	})
+}
+
+func TestBalancesNotSupported(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/balances", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+		Message: "accounting not supported",
+		Code:    http.StatusNotImplemented,
+	})
+}
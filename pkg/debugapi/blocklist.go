@@ -0,0 +1,103 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type blocklistResponse struct {
+	Peers []p2p.BlocklistedPeer `json:"peers"`
+}
+
+// blocklistHandler responds with the peers that are kept disconnected and
+// the times until which they are.
+func (s *server) blocklistHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Blocklister == nil {
+		jsonhttp.NotImplemented(w, "blocklist not supported")
+		return
+	}
+
+	peers, err := s.Blocklister.BlocklistedPeers()
+	if err != nil {
+		s.Logger.Debugf("debug api: blocklist: %v", err)
+		s.Logger.Error("debug api: blocklist")
+		jsonhttp.InternalServerError(w, "cannot get blocklist")
+		return
+	}
+	if peers == nil {
+		peers = []p2p.BlocklistedPeer{}
+	}
+
+	jsonhttp.OK(w, blocklistResponse{
+		Peers: peers,
+	})
+}
+
+// blocklistPeerHandler disconnects the peer and keeps it disconnected for
+// the duration from the query, so that the failover from the peer can be
+// exercised on demand.
+func (s *server) blocklistPeerHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Blocklister == nil {
+		jsonhttp.NotImplemented(w, "blocklist not supported")
+		return
+	}
+
+	addr := mux.Vars(r)["address"]
+	overlay, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("debug api: blocklist peer: parse peer address %s: %v", addr, err)
+		jsonhttp.BadRequest(w, "invalid peer address")
+		return
+	}
+	duration, err := time.ParseDuration(r.URL.Query().Get("duration"))
+	if err != nil || duration <= 0 {
+		s.Logger.Debugf("debug api: blocklist peer %s: parse duration: %v", addr, err)
+		jsonhttp.BadRequest(w, "invalid duration")
+		return
+	}
+
+	if err := s.Blocklister.Blocklist(overlay, duration); err != nil {
+		s.Logger.Debugf("debug api: blocklist peer %s: %v", addr, err)
+		s.Logger.Errorf("debug api: blocklist peer %s", addr)
+		jsonhttp.InternalServerError(w, "cannot blocklist peer")
+		return
+	}
+	s.Logger.Infof("debug api: peer %s blocklisted for %s", overlay, duration)
+
+	jsonhttp.OK(w, nil)
+}
+
+// unblocklistPeerHandler allows the connections with the peer before its
+// blocklist duration passes.
+func (s *server) unblocklistPeerHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Blocklister == nil {
+		jsonhttp.NotImplemented(w, "blocklist not supported")
+		return
+	}
+
+	addr := mux.Vars(r)["address"]
+	overlay, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("debug api: unblocklist peer: parse peer address %s: %v", addr, err)
+		jsonhttp.BadRequest(w, "invalid peer address")
+		return
+	}
+
+	if err := s.Blocklister.Unblocklist(overlay); err != nil {
+		s.Logger.Debugf("debug api: unblocklist peer %s: %v", addr, err)
+		s.Logger.Errorf("debug api: unblocklist peer %s", addr)
+		jsonhttp.InternalServerError(w, "cannot unblocklist peer")
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestBlocklist(t *testing.T) {
+	var (
+		adminToken = "secret"
+		authorized = http.Header{"Authorization": {"Bearer " + adminToken}}
+		overlay    = swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+		blocklist  = newTestBlocklist()
+		testServer = newTestServer(t, testServerOptions{
+			Blocklister: blocklist,
+			AdminToken:  adminToken,
+		})
+	)
+
+	t.Run("empty", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodGet, "/blocklist", nil, http.StatusOK, debugapi.BlocklistResponse{
+			Peers: []p2p.BlocklistedPeer{},
+		}, authorized)
+	})
+
+	t.Run("blocklist", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodPost, "/blocklist/"+overlay.String()+"?duration=1m", nil, http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		}, authorized)
+
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodGet, "/blocklist", nil, http.StatusOK, debugapi.BlocklistResponse{
+			Peers: []p2p.BlocklistedPeer{{
+				Address: overlay,
+				Until:   blocklist.now.Add(time.Minute),
+			}},
+		}, authorized)
+	})
+
+	t.Run("unblocklist", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodDelete, "/blocklist/"+overlay.String(), nil, http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		}, authorized)
+
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodGet, "/blocklist", nil, http.StatusOK, debugapi.BlocklistResponse{
+			Peers: []p2p.BlocklistedPeer{},
+		}, authorized)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodPost, "/blocklist/invalid?duration=1m", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid peer address",
+			Code:    http.StatusBadRequest,
+		}, authorized)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		for _, duration := range []string{"", "1", "-1m", "0s"} {
+			jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodPost, "/blocklist/"+overlay.String()+"?duration="+duration, nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+				Message: "invalid duration",
+				Code:    http.StatusBadRequest,
+			}, authorized)
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodPost, "/blocklist/"+overlay.String()+"?duration=1m", nil, http.StatusUnauthorized, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusUnauthorized),
+			Code:    http.StatusUnauthorized,
+		}, nil)
+	})
+}
+
+func TestBlocklistNotImplemented(t *testing.T) {
+	adminToken := "secret"
+	testServer := newTestServer(t, testServerOptions{
+		AdminToken: adminToken,
+	})
+
+	jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodGet, "/blocklist", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+		Message: "blocklist not supported",
+		Code:    http.StatusNotImplemented,
+	}, http.Header{"Authorization": {"Bearer " + adminToken}})
+}
+
+// testBlocklist is the blocklist that keeps the peers in memory, with the
+// expiration times from a fixed time.
+type testBlocklist struct {
+	now   time.Time
+	peers []p2p.BlocklistedPeer
+}
+
+func newTestBlocklist() *testBlocklist {
+	return &testBlocklist{
+		now: time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (b *testBlocklist) Blocklist(overlay swarm.Address, duration time.Duration) error {
+	b.peers = append(b.peers, p2p.BlocklistedPeer{
+		Address: overlay,
+		Until:   b.now.Add(duration),
+	})
+	return nil
+}
+
+func (b *testBlocklist) Unblocklist(overlay swarm.Address) error {
+	for i, p := range b.peers {
+		if p.Address.Equal(overlay) {
+			b.peers = append(b.peers[:i], b.peers[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (b *testBlocklist) BlocklistedPeers() ([]p2p.BlocklistedPeer, error) {
+	return b.peers, nil
+}
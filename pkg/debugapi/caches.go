@@ -0,0 +1,70 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+// BlockCacheReporter reports the statistics of the block cache of a LevelDB
+// database.
+type BlockCacheReporter interface {
+	// BlockCacheStats returns the size of the cached blocks and the
+	// capacity of the cache in bytes.
+	BlockCacheStats() (size, capacity int, err error)
+}
+
+type cacheStats struct {
+	Name     string `json:"name"`
+	Size     int    `json:"size"`
+	Capacity int    `json:"capacity"`
+	// Usage is the fraction of the capacity used by the cached data.
+	Usage float64 `json:"usage"`
+}
+
+type cachesResponse struct {
+	Caches []cacheStats `json:"caches"`
+}
+
+// cachesHandler responds with the statistics of all caches, sorted by their
+// names.
+func (s *server) cachesHandler(w http.ResponseWriter, r *http.Request) {
+	if len(s.BlockCaches) == 0 {
+		jsonhttp.NotImplemented(w, "cache statistics not supported")
+		return
+	}
+
+	resp := cachesResponse{
+		Caches: make([]cacheStats, 0, len(s.BlockCaches)),
+	}
+	for name, c := range s.BlockCaches {
+		size, capacity, err := c.BlockCacheStats()
+		if err != nil {
+			s.Logger.Debugf("debug api: caches: %s: %v", name, err)
+			s.Logger.Errorf("debug api: caches: %s", name)
+			jsonhttp.InternalServerError(w, "cannot get cache statistics")
+			return
+		}
+
+		var usage float64
+		if capacity > 0 {
+			usage = float64(size) / float64(capacity)
+		}
+		resp.Caches = append(resp.Caches, cacheStats{
+			Name:     name,
+			Size:     size,
+			Capacity: capacity,
+			Usage:    usage,
+		})
+	}
+	sort.Slice(resp.Caches, func(i, j int) bool {
+		return resp.Caches[i].Name < resp.Caches[j].Name
+	})
+
+	jsonhttp.OK(w, resp)
+}
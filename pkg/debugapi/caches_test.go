@@ -0,0 +1,65 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+)
+
+type blockCacheReporterFunc func() (int, int, error)
+
+func (f blockCacheReporterFunc) BlockCacheStats() (int, int, error) { return f() }
+
+func TestCaches(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			BlockCaches: map[string]debugapi.BlockCacheReporter{
+				"statestore": blockCacheReporterFunc(func() (int, int, error) {
+					return 0, 100, nil
+				}),
+				"localstore": blockCacheReporterFunc(func() (int, int, error) {
+					return 25, 100, nil
+				}),
+			},
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/caches", nil, http.StatusOK, debugapi.CachesResponse{
+			Caches: []debugapi.CacheStats{
+				{Name: "localstore", Size: 25, Capacity: 100, Usage: 0.25},
+				{Name: "statestore", Size: 0, Capacity: 100, Usage: 0},
+			},
+		})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			BlockCaches: map[string]debugapi.BlockCacheReporter{
+				"localstore": blockCacheReporterFunc(func() (int, int, error) {
+					return 0, 0, errors.New("closed")
+				}),
+			},
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/caches", nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+			Message: "cannot get cache statistics",
+			Code:    http.StatusInternalServerError,
+		})
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/caches", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+			Message: "cache statistics not supported",
+			Code:    http.StatusNotImplemented,
+		})
+	})
+}
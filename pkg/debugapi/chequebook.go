@@ -0,0 +1,91 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type chequebookResponse struct {
+	Address          string `json:"address"`
+	TotalBalance     uint64 `json:"totalBalance"`
+	AvailableBalance uint64 `json:"availableBalance"`
+}
+
+type cashoutResponse struct {
+	Peer   string `json:"peer"`
+	Amount uint64 `json:"amount"`
+}
+
+// chequebookBalanceHandler responds with the balance of the chequebook of
+// the node, and the part of it that is not promised by the issued cheques.
+func (s *server) chequebookBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Chequebook == nil {
+		jsonhttp.NotImplemented(w, "chequebook not supported")
+		return
+	}
+
+	total, err := s.Chequebook.Balance(r.Context())
+	if err != nil {
+		s.Logger.Debugf("debug api: chequebook balance: %v", err)
+		s.Logger.Error("debug api: chequebook balance")
+		jsonhttp.InternalServerError(w, "cannot get chequebook balance")
+		return
+	}
+	available, err := s.Chequebook.AvailableBalance(r.Context())
+	if err != nil {
+		s.Logger.Debugf("debug api: chequebook balance: available: %v", err)
+		s.Logger.Error("debug api: chequebook balance: available")
+		jsonhttp.InternalServerError(w, "cannot get chequebook balance")
+		return
+	}
+
+	jsonhttp.OK(w, chequebookResponse{
+		Address:          hex.EncodeToString(s.Chequebook.Address()),
+		TotalBalance:     total,
+		AvailableBalance: available,
+	})
+}
+
+// chequebookCashoutHandler cashes the last cheque received from the peer
+// and responds with the amount that is paid out by it.
+func (s *server) chequebookCashoutHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Swap == nil {
+		jsonhttp.NotImplemented(w, "swap not supported")
+		return
+	}
+
+	peer, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.Logger.Debugf("debug api: chequebook cashout: invalid peer address: %v", err)
+		s.Logger.Error("debug api: chequebook cashout: invalid peer address")
+		jsonhttp.BadRequest(w, "invalid peer address")
+		return
+	}
+
+	amount, err := s.Swap.CashCheque(r.Context(), peer)
+	if err != nil {
+		if errors.Is(err, settlement.ErrPeerNoSettlements) {
+			jsonhttp.NotFound(w, "no cheque from peer")
+			return
+		}
+		s.Logger.Debugf("debug api: chequebook cashout: peer %s: %v", peer, err)
+		s.Logger.Errorf("debug api: chequebook cashout: peer %s", peer)
+		jsonhttp.InternalServerError(w, "cannot cash cheque")
+		return
+	}
+
+	jsonhttp.OK(w, cashoutResponse{
+		Peer:   peer.String(),
+		Amount: amount,
+	})
+}
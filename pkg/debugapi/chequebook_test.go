@@ -0,0 +1,127 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement"
+	settlementmock "github.com/ethersphere/bee/pkg/settlement/mock"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestChequebookBalance(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{
+		Chequebook: &chequebookMock{
+			address:   bytes.Repeat([]byte{0xab}, chequebook.AddressLength),
+			balance:   1000,
+			available: 400,
+		},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chequebook/balance", nil, http.StatusOK, debugapi.ChequebookResponse{
+		Address:          "abababababababababababababababababababab",
+		TotalBalance:     1000,
+		AvailableBalance: 400,
+	})
+}
+
+func TestChequebookCashout(t *testing.T) {
+	peer1 := swarm.MustParseHexAddress("b0baf377")
+	peer2 := swarm.MustParseHexAddress("a0baf377")
+	peer3 := swarm.MustParseHexAddress("c0baf377")
+
+	testServer := newTestServer(t, testServerOptions{
+		Swap: &swapMock{
+			Settlement: settlementmock.NewSettlement(),
+			cash: func(peer swarm.Address) (uint64, error) {
+				switch {
+				case peer.Equal(peer1):
+					return 150, nil
+				case peer.Equal(peer2):
+					return 0, errors.New("bounced")
+				}
+				return 0, settlement.ErrPeerNoSettlements
+			},
+		},
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/chequebook/cashout/"+peer1.String(), nil, http.StatusOK, debugapi.CashoutResponse{
+			Peer:   peer1.String(),
+			Amount: 150,
+		})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/chequebook/cashout/"+peer2.String(), nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+			Message: "cannot cash cheque",
+			Code:    http.StatusInternalServerError,
+		})
+	})
+
+	t.Run("no cheque", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/chequebook/cashout/"+peer3.String(), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "no cheque from peer",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/chequebook/cashout/invalid-address", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid peer address",
+			Code:    http.StatusBadRequest,
+		})
+	})
+}
+
+func TestChequebookNotSupported(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chequebook/balance", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+		Message: "chequebook not supported",
+		Code:    http.StatusNotImplemented,
+	})
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/chequebook/cashout/b0baf377", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+		Message: "swap not supported",
+		Code:    http.StatusNotImplemented,
+	})
+}
+
+type chequebookMock struct {
+	chequebook.Service
+	address   []byte
+	balance   uint64
+	available uint64
+}
+
+func (m *chequebookMock) Address() []byte {
+	return m.address
+}
+
+func (m *chequebookMock) Balance(context.Context) (uint64, error) {
+	return m.balance, nil
+}
+
+func (m *chequebookMock) AvailableBalance(context.Context) (uint64, error) {
+	return m.available, nil
+}
+
+type swapMock struct {
+	*settlementmock.Settlement
+	cash func(peer swarm.Address) (uint64, error)
+}
+
+func (m *swapMock) CashCheque(_ context.Context, peer swarm.Address) (uint64, error) {
+	return m.cash(peer)
+}
@@ -5,13 +5,32 @@
 package debugapi
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/gorilla/mux"
 )
 
+// ChunkStater reports the presence of a chunk in the indexes of the local
+// store.
+type ChunkStater interface {
+	ChunkState(addr swarm.Address) (localstore.ChunkState, error)
+}
+
+type chunkResponse struct {
+	Address     swarm.Address `json:"address"`
+	PinCounter  uint64        `json:"pinCounter"`
+	Synced      bool          `json:"synced"`
+	InPullIndex bool          `json:"inPullIndex"`
+	InGCIndex   bool          `json:"inGCIndex"`
+}
+
+// hasChunkHandler responds if the chunk is stored locally, with its pin
+// counter and sync state if the ChunkStater is set.
 func (s *server) hasChunkHandler(w http.ResponseWriter, r *http.Request) {
 	addr, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
 	if err != nil {
@@ -20,6 +39,28 @@ func (s *server) hasChunkHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.ChunkStater != nil {
+		state, err := s.ChunkStater.ChunkState(addr)
+		if err != nil {
+			s.Logger.Debugf("debug api: chunk state %s: %v", addr, err)
+			s.Logger.Errorf("debug api: chunk state %s", addr)
+			jsonhttp.InternalServerError(w, err)
+			return
+		}
+		if !state.Stored {
+			jsonhttp.NotFound(w, nil)
+			return
+		}
+		jsonhttp.OK(w, chunkResponse{
+			Address:     addr,
+			PinCounter:  state.PinCounter,
+			Synced:      !state.InPushIndex,
+			InPullIndex: state.InPullIndex,
+			InGCIndex:   state.InGCIndex,
+		})
+		return
+	}
+
 	has, err := s.Storer.Has(r.Context(), addr)
 	if err != nil {
 		s.Logger.Debugf("debug api: localstore has: %v", err)
@@ -34,3 +75,45 @@ func (s *server) hasChunkHandler(w http.ResponseWriter, r *http.Request) {
 	jsonhttp.OK(w, nil)
 
 }
+
+// removeChunkHandler removes the chunk from the local store. Pinned chunks
+// have to be unpinned first.
+func (s *server) removeChunkHandler(w http.ResponseWriter, r *http.Request) {
+	addr, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.Logger.Debugf("debug api: parse chunk address: %v", err)
+		jsonhttp.BadRequest(w, "bad address")
+		return
+	}
+
+	has, err := s.Storer.Has(r.Context(), addr)
+	if err != nil {
+		s.Logger.Debugf("debug api: localstore has: %v", err)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+	if !has {
+		jsonhttp.NotFound(w, nil)
+		return
+	}
+
+	// the pin counter is checked by the store in the same transaction in
+	// which the chunk is removed, so that it is not pinned in between
+	if err := s.Storer.Set(r.Context(), storage.ModeSetRemoveUnpinned, addr); err != nil {
+		if errors.Is(err, storage.ErrPinned) {
+			jsonhttp.BadRequest(w, "chunk is pinned")
+			return
+		}
+		if errors.Is(err, storage.ErrNotFound) {
+			jsonhttp.NotFound(w, nil)
+			return
+		}
+		s.Logger.Debugf("debug api: remove chunk %s: %v", addr, err)
+		s.Logger.Errorf("debug api: remove chunk %s", addr)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+	s.Logger.Infof("debug api: removed chunk %s", addr)
+
+	jsonhttp.OK(w, nil)
+}
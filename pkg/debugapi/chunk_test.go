@@ -9,9 +9,11 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/debugapi"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
 	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/inmem"
 	"github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
@@ -51,3 +53,104 @@ func TestHasChunkHandler(t *testing.T) {
 		})
 	})
 }
+
+func TestChunkStateHandler(t *testing.T) {
+	store := inmem.New(make([]byte, 32), inmem.Options{})
+	testServer := newTestServer(t, testServerOptions{
+		Storer:      store,
+		ChunkStater: store,
+	})
+
+	key := swarm.MustParseHexAddress("aabbcc")
+	if _, err := store.Put(context.Background(), storage.ModePutUpload, swarm.NewChunk(key, []byte("data"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(context.Background(), storage.ModeSetPin, key); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("not synced", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chunks/"+key.String(), nil, http.StatusOK, debugapi.ChunkResponse{
+			Address:     key,
+			PinCounter:  1,
+			Synced:      false,
+			InPullIndex: true,
+		})
+	})
+
+	if err := store.Set(context.Background(), storage.ModeSetSyncPush, key); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("synced", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chunks/"+key.String(), nil, http.StatusOK, debugapi.ChunkResponse{
+			Address:     key,
+			PinCounter:  1,
+			Synced:      true,
+			InPullIndex: true,
+		})
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chunks/abbbbb", nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusNotFound),
+			Code:    http.StatusNotFound,
+		})
+	})
+}
+
+func TestRemoveChunkHandler(t *testing.T) {
+	store := inmem.New(make([]byte, 32), inmem.Options{})
+	testServer := newTestServer(t, testServerOptions{
+		Storer:      store,
+		ChunkStater: store,
+	})
+
+	key := swarm.MustParseHexAddress("aabbcc")
+	if _, err := store.Put(context.Background(), storage.ModePutUpload, swarm.NewChunk(key, []byte("data"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(context.Background(), storage.ModeSetPin, key); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("pinned", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodDelete, "/chunks/"+key.String(), nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "chunk is pinned",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	if err := store.Set(context.Background(), storage.ModeSetUnpin, key); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodDelete, "/chunks/"+key.String(), nil, http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		})
+
+		has, err := store.Has(context.Background(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if has {
+			t.Fatal("chunk is not removed")
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodDelete, "/chunks/"+key.String(), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusNotFound),
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("bad address", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodDelete, "/chunks/abcd1100zz", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "bad address",
+			Code:    http.StatusBadRequest,
+		})
+	})
+}
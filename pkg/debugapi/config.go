@@ -0,0 +1,47 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+// ConfigReloader reloads the configuration of the node and applies the
+// options that can be changed while the node is running.
+type ConfigReloader interface {
+	// ReloadConfig returns the names of the options that are changed.
+	ReloadConfig() (applied []string, err error)
+}
+
+type configReloadResponse struct {
+	Applied []string `json:"applied"`
+}
+
+// configReloadHandler reloads the configuration of the node and responds
+// with the names of the applied options.
+func (s *server) configReloadHandler(w http.ResponseWriter, r *http.Request) {
+	if s.ConfigReloader == nil {
+		jsonhttp.NotImplemented(w, "configuration reload not supported")
+		return
+	}
+
+	applied, err := s.ConfigReloader.ReloadConfig()
+	if err != nil {
+		// the error is logged at the error level, as it is most likely
+		// caused by the invalid configuration
+		s.Logger.Errorf("debug api: config reload: %v", err)
+		jsonhttp.InternalServerError(w, "cannot reload configuration")
+		return
+	}
+
+	if applied == nil {
+		applied = make([]string, 0)
+	}
+	jsonhttp.OK(w, configReloadResponse{
+		Applied: applied,
+	})
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+)
+
+type configReloaderFunc func() ([]string, error)
+
+func (f configReloaderFunc) ReloadConfig() ([]string, error) { return f() }
+
+func TestConfigReload(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			ConfigReloader: configReloaderFunc(func() ([]string, error) {
+				return []string{"verbosity", "welcome-message"}, nil
+			}),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/config/reload", nil, http.StatusOK, debugapi.ConfigReloadResponse{
+			Applied: []string{"verbosity", "welcome-message"},
+		})
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			ConfigReloader: configReloaderFunc(func() ([]string, error) {
+				return nil, nil
+			}),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/config/reload", nil, http.StatusOK, debugapi.ConfigReloadResponse{
+			Applied: []string{},
+		})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			ConfigReloader: configReloaderFunc(func() ([]string, error) {
+				return nil, errors.New("invalid config")
+			}),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/config/reload", nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+			Message: "cannot reload configuration",
+			Code:    http.StatusInternalServerError,
+		})
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/config/reload", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+			Message: "configuration reload not supported",
+			Code:    http.StatusNotImplemented,
+		})
+	})
+}
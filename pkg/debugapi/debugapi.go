@@ -7,9 +7,17 @@ package debugapi
 import (
 	"net/http"
 
+	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/addressbook"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
@@ -21,6 +29,12 @@ import (
 type Service interface {
 	http.Handler
 	MustRegisterMetrics(cs ...prometheus.Collector)
+	// SetConcurrency changes the Concurrency option while the requests are
+	// served.
+	SetConcurrency(limit int)
+	// SetCORSAllowedOrigins changes the CORSAllowedOrigins option while the
+	// requests are served.
+	SetCORSAllowedOrigins(origins []string)
 }
 
 type server struct {
@@ -28,6 +42,8 @@ type server struct {
 	http.Handler
 
 	metricsRegistry *prometheus.Registry
+	limiter         *jsonhttp.ConcurrencyLimiter
+	cors            *jsonhttp.CORS
 }
 
 type Options struct {
@@ -36,18 +52,111 @@ type Options struct {
 	Pingpong       pingpong.Interface
 	TopologyDriver topology.PeerAdder
 	Storer         storage.Storer
+	// GarbageCollector runs the garbage collection of the Storer on
+	// demand. It is disabled if it is not set.
+	GarbageCollector GarbageCollector
+	// ChunkStater reports the pin counters and the sync states of the
+	// chunks of the Storer. Only the presence of the chunks is reported if
+	// it is not set.
+	ChunkStater ChunkStater
+	// SchemaNamer reports the schema of the local store of the Storer.
+	SchemaNamer SchemaNamer
+	// RadiusReporter reports the storage radius of the local store of the
+	// Storer.
+	RadiusReporter RadiusReporter
+	// StorageReporter reports the stored chunks of the local store of the
+	// Storer by their proximity orders.
+	StorageReporter StorageReporter
+	// MirrorRestorer restores the chunks of the Storer from their mirror.
+	// It is disabled if it is not set.
+	MirrorRestorer MirrorRestorer
 	Logger         logging.Logger
 	Tracer         *tracing.Tracer
 	Tags           *tags.Tags
+	PushSyncEvents pushsync.EventSubscriber
+	LogStream      *logging.Stream
+	Signer         crypto.Signer
+	// PushQueue reports the chunks that are waiting to be push synced. It
+	// is disabled if it is not set.
+	PushQueue PushQueueReporter
+	// PushInflight counts the chunks that are being pushed. They are
+	// reported as none if it is not set.
+	PushInflight PushInflightCounter
+	// AdminToken is the bearer token that authorizes the requests with
+	// methods other than GET, HEAD and OPTIONS, which change the state of
+	// the node, and the requests to the endpoints that use the node key.
+	// They are all forbidden if it is not set.
+	AdminToken string
+	// ReadToken is the bearer token that authorizes requests to the
+	// endpoints that do not change the state of the node if Restricted is
+	// set. The admin token authorizes them too.
+	ReadToken string
+	// Restricted requires the read or the admin token also for the requests
+	// with the GET, HEAD and OPTIONS methods, except the health and
+	// readiness checks and the metrics. The tokens are also accepted as the
+	// basic authentication password.
+	Restricted bool
+	// CORSAllowedOrigins are the origins of the requests that the CORS
+	// headers are set for. No origin is allowed if it is empty, and all
+	// origins, without the credentials, if it contains "*".
+	CORSAllowedOrigins []string
+	// BlockCaches are the block caches of the databases of the node by
+	// their names. Cache statistics are disabled if there are none.
+	BlockCaches map[string]BlockCacheReporter
+	// ConfigReloader reloads the configuration of the node. It is disabled
+	// if it is not set.
+	ConfigReloader ConfigReloader
+	// Accounting reports the balances with peers. It is disabled if it is
+	// not set.
+	Accounting accounting.Interface
+	// Settlement reports the amounts settled with peers. It is disabled if
+	// it is not set.
+	Settlement settlement.Interface
+	// Chequebook reports the balance of the chequebook of the node. It is
+	// disabled if it is not set.
+	Chequebook chequebook.Service
+	// Swap cashes the cheques received from peers. It is disabled if it is
+	// not set.
+	Swap swap.Interface
+	// AddressBook reports the addresses of the known peers. It is disabled
+	// if it is not set.
+	AddressBook addressbook.Interface
+	// Blocklister keeps peers disconnected on demand, with the endpoints
+	// that are authorized by the admin token. It is disabled if it is not
+	// set.
+	Blocklister p2p.Blocklister
+	// ResolverCache flushes the cache of the names resolved for the API. It
+	// is disabled if it is not set.
+	ResolverCache ResolverCacheFlusher
+	// ReadinessChecks are the checks of the node components by their names,
+	// which all have to pass for the node to be reported as ready.
+	ReadinessChecks map[string]ReadinessCheck
+	// Profiling serves the pprof profiles and the expvar variables of the
+	// node under /debug. They are not served if it is not set.
+	Profiling bool
+	// Concurrency is the number of the requests that are handled at the
+	// same time, except the health and readiness checks and the metrics.
+	// Requests are not limited if it is zero.
+	Concurrency int
 }
 
 func New(o Options) Service {
 	s := &server{
 		Options:         o,
 		metricsRegistry: newMetricsRegistry(),
+		limiter:         jsonhttp.NewConcurrencyLimiter(o.Concurrency, limitRetryAfter),
+		cors:            jsonhttp.NewCORS(o.CORSAllowedOrigins),
 	}
 
 	s.setupRouting()
 
 	return s
 }
+
+func (s *server) SetConcurrency(limit int) {
+	s.limiter.SetLimit(limit)
+}
+
+func (s *server) SetCORSAllowedOrigins(origins []string) {
+	s.cors.SetAllowedOrigins(origins)
+}
@@ -11,45 +11,121 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/debugapi"
 	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
 	mockp2p "github.com/ethersphere/bee/pkg/p2p/mock"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
 	"github.com/ethersphere/bee/pkg/topology/mock"
 	"github.com/multiformats/go-multiaddr"
+	"github.com/prometheus/client_golang/prometheus"
 	"resenje.org/web"
 )
 
 type testServerOptions struct {
-	Overlay      swarm.Address
-	P2P          *mockp2p.Service
-	Pingpong     pingpong.Interface
-	Storer       storage.Storer
-	TopologyOpts []mock.Option
-	Tags         *tags.Tags
+	Overlay        swarm.Address
+	P2P            *mockp2p.Service
+	Pingpong       pingpong.Interface
+	Storer         storage.Storer
+	GC             debugapi.GarbageCollector
+	ChunkStater    debugapi.ChunkStater
+	SchemaNamer    debugapi.SchemaNamer
+	RadiusReporter debugapi.RadiusReporter
+	StorageStats   debugapi.StorageReporter
+	MirrorRestorer debugapi.MirrorRestorer
+	BlockCaches    map[string]debugapi.BlockCacheReporter
+	TopologyOpts   []mock.Option
+	Tags           *tags.Tags
+	PushSyncEvents pushsync.EventSubscriber
+	PushQueue      debugapi.PushQueueReporter
+	PushInflight   debugapi.PushInflightCounter
+	LogStream      *logging.Stream
+	Signer         crypto.Signer
+	AdminToken     string
+	NoAdminToken   bool
+	ReadToken      string
+	Restricted     bool
+	ConfigReloader debugapi.ConfigReloader
+	Accounting     accounting.Interface
+	Settlement     settlement.Interface
+	Chequebook     chequebook.Service
+	Swap           swap.Interface
+	AddressBook    addressbook.Interface
+	Blocklister    p2p.Blocklister
+	ResolverCache  debugapi.ResolverCacheFlusher
+	Readiness      map[string]debugapi.ReadinessCheck
+	Metrics        []prometheus.Collector
+	Profiling      bool
+	CORSOrigins    []string
 }
 
 type testServer struct {
 	Client  *http.Client
 	P2PMock *mockp2p.Service
+	Addr    string
 }
 
+// testAdminToken is the admin token of the test servers whose options do not
+// set one, which their clients send with the requests that carry no
+// Authorization header, unless the NoAdminToken option is set.
+const testAdminToken = "test-admin-token"
+
 func newTestServer(t *testing.T, o testServerOptions) *testServer {
 	topologyDriver := mock.NewTopologyDriver(o.TopologyOpts...)
 
+	var defaultAuthorization string
+	if o.AdminToken == "" && !o.NoAdminToken {
+		o.AdminToken = testAdminToken
+		defaultAuthorization = "Bearer " + testAdminToken
+	}
+
 	s := debugapi.New(debugapi.Options{
-		Overlay:        o.Overlay,
-		P2P:            o.P2P,
-		Pingpong:       o.Pingpong,
-		Tags:           o.Tags,
-		Logger:         logging.New(ioutil.Discard, 0),
-		Storer:         o.Storer,
-		TopologyDriver: topologyDriver,
+		Overlay:            o.Overlay,
+		P2P:                o.P2P,
+		Pingpong:           o.Pingpong,
+		Tags:               o.Tags,
+		Logger:             logging.New(ioutil.Discard, 0),
+		Storer:             o.Storer,
+		GarbageCollector:   o.GC,
+		ChunkStater:        o.ChunkStater,
+		SchemaNamer:        o.SchemaNamer,
+		RadiusReporter:     o.RadiusReporter,
+		StorageReporter:    o.StorageStats,
+		MirrorRestorer:     o.MirrorRestorer,
+		BlockCaches:        o.BlockCaches,
+		TopologyDriver:     topologyDriver,
+		PushSyncEvents:     o.PushSyncEvents,
+		PushQueue:          o.PushQueue,
+		PushInflight:       o.PushInflight,
+		LogStream:          o.LogStream,
+		Signer:             o.Signer,
+		AdminToken:         o.AdminToken,
+		ReadToken:          o.ReadToken,
+		Restricted:         o.Restricted,
+		ConfigReloader:     o.ConfigReloader,
+		Accounting:         o.Accounting,
+		Settlement:         o.Settlement,
+		Chequebook:         o.Chequebook,
+		Swap:               o.Swap,
+		AddressBook:        o.AddressBook,
+		Blocklister:        o.Blocklister,
+		ResolverCache:      o.ResolverCache,
+		ReadinessChecks:    o.Readiness,
+		Profiling:          o.Profiling,
+		CORSAllowedOrigins: o.CORSOrigins,
 	})
+	s.MustRegisterMetrics(o.Metrics...)
 	ts := httptest.NewServer(s)
 	t.Cleanup(ts.Close)
 
@@ -60,12 +136,16 @@ func newTestServer(t *testing.T, o testServerOptions) *testServer {
 				return nil, err
 			}
 			r.URL = u
+			if defaultAuthorization != "" && r.Header.Get("Authorization") == "" {
+				r.Header.Set("Authorization", defaultAuthorization)
+			}
 			return ts.Client().Transport.RoundTrip(r)
 		}),
 	}
 	return &testServer{
 		Client:  client,
 		P2PMock: o.P2P,
+		Addr:    ts.Listener.Addr().String(),
 	}
 }
 
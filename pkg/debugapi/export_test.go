@@ -13,4 +13,29 @@ type (
 	PinnedChunk              = pinnedChunk
 	ListPinnedChunksResponse = listPinnedChunksResponse
 	TagResponse              = tagResponse
+	SignResponse             = signResponse
+	PublicKeyResponse        = publicKeyResponse
+	GCResponse               = gcResponse
+	SchemaResponse           = schemaResponse
+	RadiusResponse           = radiusResponse
+	StorageBin               = storageBin
+	StorageResponse          = storageResponse
+	MirrorRestoreResponse    = mirrorRestoreResponse
+	ConfigReloadResponse     = configReloadResponse
+	BalanceResponse          = balanceResponse
+	BalancesResponse         = balancesResponse
+	CacheStats               = cacheStats
+	CachesResponse           = cachesResponse
+	SettlementResponse       = settlementResponse
+	SettlementsResponse      = settlementsResponse
+	ChequebookResponse       = chequebookResponse
+	CashoutResponse          = cashoutResponse
+	AddressbookResponse      = addressbookResponse
+	BlocklistResponse        = blocklistResponse
+	ReadinessResponse        = readinessResponse
+	ChunkResponse            = chunkResponse
+	PushQueueTag             = pushQueueTag
+	PushQueueResponse        = pushQueueResponse
+	LoggersResponse          = loggersResponse
+	ResolverCacheResponse    = resolverCacheResponse
 )
@@ -0,0 +1,48 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+// GarbageCollector removes the chunks that are not needed by the node from
+// its local store.
+type GarbageCollector interface {
+	// CollectGarbage runs the garbage collection and returns the number of
+	// collected chunks.
+	CollectGarbage() (collected uint64, err error)
+}
+
+type gcResponse struct {
+	Collected uint64 `json:"collected"`
+	Duration  string `json:"duration"`
+}
+
+// gcHandler runs the garbage collection of the local store on demand and
+// responds when it is done.
+func (s *server) gcHandler(w http.ResponseWriter, r *http.Request) {
+	if s.GarbageCollector == nil {
+		jsonhttp.NotImplemented(w, "garbage collection not supported")
+		return
+	}
+
+	start := time.Now()
+	collected, err := s.GarbageCollector.CollectGarbage()
+	if err != nil {
+		s.Logger.Debugf("debug api: gc: %v", err)
+		s.Logger.Error("debug api: gc")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, gcResponse{
+		Collected: collected,
+		Duration:  time.Since(start).String(),
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+)
+
+type garbageCollectorFunc func() (uint64, error)
+
+func (f garbageCollectorFunc) CollectGarbage() (uint64, error) { return f() }
+
+func TestGC(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		var calls int
+		testServer := newTestServer(t, testServerOptions{
+			GC: garbageCollectorFunc(func() (uint64, error) {
+				calls++
+				return 42, nil
+			}),
+		})
+
+		var resp debugapi.GCResponse
+		jsonhttptest.ResponseUnmarshal(t, testServer.Client, http.MethodPost, "/gc", nil, http.StatusOK, &resp)
+		if resp.Collected != 42 {
+			t.Errorf("got %d collected chunks, want %d", resp.Collected, 42)
+		}
+		if resp.Duration == "" {
+			t.Error("missing duration")
+		}
+		if calls != 1 {
+			t.Errorf("got %d garbage collections, want %d", calls, 1)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			GC: garbageCollectorFunc(func() (uint64, error) {
+				return 0, errors.New("gc error")
+			}),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/gc", nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+			Message: "gc error",
+			Code:    http.StatusInternalServerError,
+		})
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/gc", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+			Message: "garbage collection not supported",
+			Code:    http.StatusNotImplemented,
+		})
+	})
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// logsHandler streams the log lines of the node as json messages over a
+// websocket connection, until the client closes it. The lines are filtered
+// by the optional level and subsystem query parameters. As the log lines may
+// reveal the activity of the node, cross-origin requests are upgraded only
+// from the allowed CORS origins.
+func (s *server) logsHandler(w http.ResponseWriter, r *http.Request) {
+	if s.LogStream == nil {
+		jsonhttp.NotImplemented(w, "log streaming not supported")
+		return
+	}
+
+	level := logrus.TraceLevel
+	if l := r.URL.Query().Get("level"); l != "" {
+		var err error
+		level, err = logrus.ParseLevel(l)
+		if err != nil {
+			s.Logger.Debugf("debug api: logs: parse level %q: %v", l, err)
+			s.Logger.Errorf("debug api: logs: parse level %q", l)
+			jsonhttp.BadRequest(w, "invalid level")
+			return
+		}
+	}
+	subsystem := r.URL.Query().Get("subsystem")
+
+	conn, err := s.upgrade(w, r)
+	if err != nil {
+		// the upgrader already responded with an error
+		s.Logger.Debugf("debug api: logs: upgrade: %v", err)
+		s.Logger.Error("debug api: logs: upgrade")
+		return
+	}
+	defer conn.Close()
+
+	lines, unsubscribe := s.LogStream.Subscribe(level, subsystem)
+	defer unsubscribe()
+
+	// messages from the client are discarded, reading is required only to
+	// detect when the connection is closed
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// write errors are not logged, as they would be streamed to the client
+	for {
+		select {
+		case l, ok := <-lines:
+			if !ok {
+				return
+			}
+			if err := conn.SetWriteDeadline(time.Now().Add(eventWriteTimeout)); err != nil {
+				return
+			}
+			if err := conn.WriteJSON(l); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+type loggersResponse struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
+
+// loggersHandler responds with the level of the logger and with the levels
+// that the components override it with.
+func (s *server) loggersHandler(w http.ResponseWriter, r *http.Request) {
+	resp := loggersResponse{
+		Level:      s.Logger.GetLevel().String(),
+		Components: make(map[string]string),
+	}
+	for component, level := range s.Logger.ComponentLevels() {
+		resp.Components[component] = level.String()
+	}
+	jsonhttp.OK(w, resp)
+}
+
+// setLoggerLevelHandler overrides the level of the logger for the lines of
+// the component.
+func (s *server) setLoggerLevelHandler(w http.ResponseWriter, r *http.Request) {
+	component := mux.Vars(r)["component"]
+	level, err := logrus.ParseLevel(mux.Vars(r)["level"])
+	if err != nil {
+		s.Logger.Debugf("debug api: loggers: parse level: %v", err)
+		jsonhttp.BadRequest(w, "invalid level")
+		return
+	}
+
+	s.Logger.SetComponentLevel(component, level)
+	s.Logger.Infof("debug api: loggers: level of component %q set to %s", component, level)
+	jsonhttp.OK(w, nil)
+}
+
+// removeLoggerLevelHandler removes the override of the level of the logger
+// for the lines of the component.
+func (s *server) removeLoggerLevelHandler(w http.ResponseWriter, r *http.Request) {
+	component := mux.Vars(r)["component"]
+
+	s.Logger.RemoveComponentLevel(component)
+	s.Logger.Infof("debug api: loggers: level of component %q removed", component)
+	jsonhttp.OK(w, nil)
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+func TestLogs(t *testing.T) {
+	stream := logging.NewStream()
+	logger := logging.New(ioutil.Discard, logrus.TraceLevel)
+	logger.AddHook(stream)
+
+	testServer := newTestServer(t, testServerOptions{
+		LogStream: stream,
+	})
+
+	t.Run("invalid-level", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/logs?level=loud", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid level",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("cross-origin", func(t *testing.T) {
+		conn, resp, err := websocket.DefaultDialer.Dial("ws://"+testServer.Addr+"/logs", http.Header{
+			"Origin": {"http://example.com"},
+		})
+		if err == nil {
+			conn.Close()
+			t.Fatal("cross-origin connection upgraded")
+		}
+		if resp == nil || resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("got response %v, want status %d", resp, http.StatusForbidden)
+		}
+	})
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+testServer.Addr+"/logs?level=info&subsystem=pinning", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the subscription is made by the handler after the connection is
+	// upgraded, so log until the line is received
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			logger.Debug("pinning: debug line")
+			logger.Info("pusher: line of another subsystem")
+			logger.WithField("chunk", 7).Info("pinning: info line")
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}()
+
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var got logging.Line
+	if err := conn.ReadJSON(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Message != "pinning: info line" || got.Level != "info" || got.Subsystem != "pinning" || got.Fields["chunk"] != "7" {
+		t.Fatalf("got line %+v", got)
+	}
+}
+
+func TestLoggers(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/loggers", nil, http.StatusOK, debugapi.LoggersResponse{
+		Level:      "panic",
+		Components: map[string]string{},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPut, "/loggers/pushsync/debug", nil, http.StatusOK, jsonhttp.StatusResponse{
+		Message: http.StatusText(http.StatusOK),
+		Code:    http.StatusOK,
+	})
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/loggers", nil, http.StatusOK, debugapi.LoggersResponse{
+		Level:      "panic",
+		Components: map[string]string{"pushsync": "debug"},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPut, "/loggers/pushsync/loud", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+		Message: "invalid level",
+		Code:    http.StatusBadRequest,
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodDelete, "/loggers/pushsync", nil, http.StatusOK, jsonhttp.StatusResponse{
+		Message: http.StatusText(http.StatusOK),
+		Code:    http.StatusOK,
+	})
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/loggers", nil, http.StatusOK, debugapi.LoggersResponse{
+		Level:      "panic",
+		Components: map[string]string{},
+	})
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetrics(t *testing.T) {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "bee",
+		Subsystem: "test",
+		Name:      "requests",
+		Help:      "Test requests.",
+	})
+	counter.Add(3)
+
+	testServer := newTestServer(t, testServerOptions{
+		Metrics: []prometheus.Collector{counter},
+	})
+
+	resp, err := testServer.Client.Get("/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"bee_test_requests 3",
+		"bee_info{",
+		"go_goroutines ",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics do not contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+// MirrorRestorer stores the chunks from the mirror backend that are missing
+// from the local store.
+type MirrorRestorer interface {
+	Restore(ctx context.Context) (restored uint64, err error)
+}
+
+type mirrorRestoreResponse struct {
+	Restored uint64 `json:"restored"`
+	Duration string `json:"duration"`
+}
+
+// mirrorRestoreHandler restores the chunks from the mirror and responds when
+// all of them are restored.
+func (s *server) mirrorRestoreHandler(w http.ResponseWriter, r *http.Request) {
+	if s.MirrorRestorer == nil {
+		jsonhttp.NotImplemented(w, "mirror not enabled")
+		return
+	}
+
+	start := time.Now()
+	restored, err := s.MirrorRestorer.Restore(r.Context())
+	if err != nil {
+		s.Logger.Debugf("debug api: mirror restore: %v", err)
+		s.Logger.Error("debug api: mirror restore")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, mirrorRestoreResponse{
+		Restored: restored,
+		Duration: time.Since(start).String(),
+	})
+}
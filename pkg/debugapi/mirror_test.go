@@ -0,0 +1,61 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+)
+
+type mirrorRestorerFunc func(ctx context.Context) (uint64, error)
+
+func (f mirrorRestorerFunc) Restore(ctx context.Context) (uint64, error) { return f(ctx) }
+
+func TestMirrorRestore(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			MirrorRestorer: mirrorRestorerFunc(func(context.Context) (uint64, error) {
+				return 42, nil
+			}),
+		})
+
+		var resp debugapi.MirrorRestoreResponse
+		jsonhttptest.ResponseUnmarshal(t, testServer.Client, http.MethodPost, "/mirror/restore", nil, http.StatusOK, &resp)
+		if resp.Restored != 42 {
+			t.Errorf("got %d restored chunks, want %d", resp.Restored, 42)
+		}
+		if resp.Duration == "" {
+			t.Error("missing duration")
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			MirrorRestorer: mirrorRestorerFunc(func(context.Context) (uint64, error) {
+				return 0, errors.New("restore error")
+			}),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/mirror/restore", nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+			Message: "restore error",
+			Code:    http.StatusInternalServerError,
+		})
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodPost, "/mirror/restore", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+			Message: "mirror not enabled",
+			Code:    http.StatusNotImplemented,
+		})
+	})
+}
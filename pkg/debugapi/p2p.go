@@ -5,8 +5,10 @@
 package debugapi
 
 import (
+	"encoding/hex"
 	"net/http"
 
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/multiformats/go-multiaddr"
@@ -15,6 +17,11 @@ import (
 type addressesResponse struct {
 	Overlay  swarm.Address         `json:"overlay"`
 	Underlay []multiaddr.Multiaddr `json:"underlay"`
+	IPv4     []multiaddr.Multiaddr `json:"ipv4"` // underlay addresses of the IPv4 family
+	IPv6     []multiaddr.Multiaddr `json:"ipv6"` // underlay addresses of the IPv6 family
+	// Ethereum is the hex encoded ethereum address of the swarm key of the
+	// node, from which the overlay address is derived.
+	Ethereum string `json:"ethereum,omitempty"`
 }
 
 func (s *server) addressesHandler(w http.ResponseWriter, r *http.Request) {
@@ -24,8 +31,37 @@ func (s *server) addressesHandler(w http.ResponseWriter, r *http.Request) {
 		jsonhttp.InternalServerError(w, err)
 		return
 	}
+	ipv4 := make([]multiaddr.Multiaddr, 0)
+	ipv6 := make([]multiaddr.Multiaddr, 0)
+	for _, a := range underlay {
+		if _, err := a.ValueForProtocol(multiaddr.P_IP4); err == nil {
+			ipv4 = append(ipv4, a)
+		}
+		if _, err := a.ValueForProtocol(multiaddr.P_IP6); err == nil {
+			ipv6 = append(ipv6, a)
+		}
+	}
+	var ethereum string
+	if s.Signer != nil {
+		publicKey, err := s.Signer.PublicKey()
+		if err != nil {
+			s.Logger.Debugf("debug api: p2p addresses: public key: %v", err)
+			jsonhttp.InternalServerError(w, err)
+			return
+		}
+		address, err := crypto.NewEthereumAddress(*publicKey)
+		if err != nil {
+			s.Logger.Debugf("debug api: p2p addresses: ethereum address: %v", err)
+			jsonhttp.InternalServerError(w, err)
+			return
+		}
+		ethereum = hex.EncodeToString(address)
+	}
 	jsonhttp.OK(w, addressesResponse{
 		Overlay:  s.Overlay,
 		Underlay: underlay,
+		IPv4:     ipv4,
+		IPv6:     ipv6,
+		Ethereum: ethereum,
 	})
 }
@@ -5,10 +5,12 @@
 package debugapi_test
 
 import (
+	"encoding/hex"
 	"errors"
 	"net/http"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/debugapi"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
@@ -23,6 +25,7 @@ func TestAddresses(t *testing.T) {
 		mustMultiaddr(t, "/ip4/127.0.0.1/tcp/7071/p2p/16Uiu2HAmTBuJT9LvNmBiQiNoTsxE5mtNy6YG3paw79m94CRa9sRb"),
 		mustMultiaddr(t, "/ip4/192.168.0.101/tcp/7071/p2p/16Uiu2HAmTBuJT9LvNmBiQiNoTsxE5mtNy6YG3paw79m94CRa9sRb"),
 		mustMultiaddr(t, "/ip4/127.0.0.1/udp/7071/quic/p2p/16Uiu2HAmTBuJT9LvNmBiQiNoTsxE5mtNy6YG3paw79m94CRa9sRb"),
+		mustMultiaddr(t, "/ip6/::1/tcp/7071/p2p/16Uiu2HAmTBuJT9LvNmBiQiNoTsxE5mtNy6YG3paw79m94CRa9sRb"),
 	}
 
 	testServer := newTestServer(t, testServerOptions{
@@ -36,6 +39,8 @@ func TestAddresses(t *testing.T) {
 		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/addresses", nil, http.StatusOK, debugapi.AddressesResponse{
 			Overlay:  overlay,
 			Underlay: addresses,
+			IPv4:     addresses[:3],
+			IPv6:     addresses[3:],
 		})
 	})
 
@@ -47,6 +52,36 @@ func TestAddresses(t *testing.T) {
 	})
 }
 
+func TestAddresses_ethereum(t *testing.T) {
+	privateKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ethereum, err := crypto.NewEthereumAddress(privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := crypto.NewOverlayAddress(privateKey.PublicKey, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testServer := newTestServer(t, testServerOptions{
+		Overlay: overlay,
+		Signer:  crypto.NewDefaultSigner(privateKey),
+		P2P: mock.New(mock.WithAddressesFunc(func() ([]multiaddr.Multiaddr, error) {
+			return nil, nil
+		})),
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/addresses", nil, http.StatusOK, debugapi.AddressesResponse{
+		Overlay:  overlay,
+		IPv4:     []multiaddr.Multiaddr{},
+		IPv6:     []multiaddr.Multiaddr{},
+		Ethereum: hex.EncodeToString(ethereum),
+	})
+}
+
 func TestAddresses_error(t *testing.T) {
 	testErr := errors.New("test error")
 
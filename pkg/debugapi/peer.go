@@ -67,8 +67,22 @@ type peersResponse struct {
 	Peers []p2p.Peer `json:"peers"`
 }
 
+// peersHandler responds with the connected peers within the pagination of
+// the request.
 func (s *server) peersHandler(w http.ResponseWriter, r *http.Request) {
+	if !jsonhttp.NegotiateJSON(w, r) {
+		return
+	}
+	page, err := jsonhttp.ParsePagination(r, 0)
+	if err != nil {
+		s.Logger.Debugf("debug api: peers: %v", err)
+		jsonhttp.RespondError(w, err)
+		return
+	}
+
+	peers := s.P2P.Peers()
+	start, end := page.Bounds(len(peers))
 	jsonhttp.OK(w, peersResponse{
-		Peers: s.P2P.Peers(),
+		Peers: peers[start:end],
 	})
 }
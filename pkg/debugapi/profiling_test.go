@@ -0,0 +1,44 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestProfiling(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		profiling  bool
+		wantStatus int
+	}{
+		{name: "enabled", profiling: true, wantStatus: http.StatusOK},
+		{name: "disabled", profiling: false, wantStatus: http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			testServer := newTestServer(t, testServerOptions{
+				Profiling: tc.profiling,
+			})
+
+			for _, path := range []string{
+				"/debug/pprof/",
+				"/debug/pprof/heap",
+				"/debug/pprof/goroutine",
+				"/debug/vars",
+			} {
+				resp, err := testServer.Client.Get(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+
+				if resp.StatusCode != tc.wantStatus {
+					t.Errorf("got status %v for %s, want %v", resp.StatusCode, path, tc.wantStatus)
+				}
+			}
+		})
+	}
+}
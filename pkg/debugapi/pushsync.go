@@ -0,0 +1,155 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/gorilla/websocket"
+)
+
+var eventWriteTimeout = 10 * time.Second
+
+// upgrade upgrades the request to a websocket connection. Browsers open
+// websockets from any web page without a preflight request, so only the
+// requests from the same origin or from the allowed CORS origins are
+// upgraded, otherwise any visited page could read the streams of the node.
+func (s *server) upgrade(w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: s.checkOrigin,
+	}
+	return upgrader.Upgrade(w, r, nil)
+}
+
+// checkOrigin returns true if the websocket request is not made by a browser,
+// or if its origin is the debug api itself or one of the allowed origins.
+func (s *server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return s.cors.Allowed(origin)
+}
+
+// pushsyncEventsHandler streams push progress events as json messages over
+// a websocket connection, until the client closes it.
+func (s *server) pushsyncEventsHandler(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrade(w, r)
+	if err != nil {
+		// the upgrader already responded with an error
+		s.Logger.Debugf("debug api: pushsync events: upgrade: %v", err)
+		s.Logger.Error("debug api: pushsync events: upgrade")
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := s.PushSyncEvents.Subscribe()
+	defer unsubscribe()
+
+	// messages from the client are discarded, reading is required only to
+	// detect when the connection is closed
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := conn.SetWriteDeadline(time.Now().Add(eventWriteTimeout)); err != nil {
+				s.Logger.Debugf("debug api: pushsync events: set write deadline: %v", err)
+				return
+			}
+			if err := conn.WriteJSON(e); err != nil {
+				s.Logger.Debugf("debug api: pushsync events: write: %v", err)
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// PushQueueReporter reports the chunks in the push index of the local store,
+// which are waiting to be push synced.
+type PushQueueReporter interface {
+	PushQueue() (localstore.PushQueue, error)
+}
+
+// PushInflightCounter counts the chunks that are being pushed.
+type PushInflightCounter interface {
+	InflightCount() int
+}
+
+type pushQueueTag struct {
+	UID     uint32 `json:"uid"`
+	Pending int    `json:"pending"`
+}
+
+type pushQueueResponse struct {
+	Pending       int            `json:"pending"`
+	OldestPending *time.Time     `json:"oldestPending,omitempty"`
+	Tags          []pushQueueTag `json:"tags"`
+	Inflight      int            `json:"inflight"`
+}
+
+// pushQueueHandler responds with the number of the chunks that are waiting to
+// be push synced, in total and by their tags, the store time of the oldest
+// one and the number of the chunks that are being pushed, so that it can be
+// told if the uploads are draining.
+func (s *server) pushQueueHandler(w http.ResponseWriter, r *http.Request) {
+	if s.PushQueue == nil {
+		jsonhttp.NotImplemented(w, "push queue not supported")
+		return
+	}
+
+	q, err := s.PushQueue.PushQueue()
+	if err != nil {
+		s.Logger.Debugf("debug api: push queue: %v", err)
+		s.Logger.Error("debug api: push queue")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	resp := pushQueueResponse{
+		Pending: q.Pending,
+		Tags:    make([]pushQueueTag, 0, len(q.Tags)),
+	}
+	if !q.Oldest.IsZero() {
+		oldest := q.Oldest.UTC()
+		resp.OldestPending = &oldest
+	}
+	for uid, pending := range q.Tags {
+		resp.Tags = append(resp.Tags, pushQueueTag{
+			UID:     uid,
+			Pending: pending,
+		})
+	}
+	sort.Slice(resp.Tags, func(i, j int) bool {
+		return resp.Tags[i].UID < resp.Tags[j].UID
+	})
+	if s.PushInflight != nil {
+		resp.Inflight = s.PushInflight.InflightCount()
+	}
+
+	jsonhttp.OK(w, resp)
+}
@@ -0,0 +1,161 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/websocket"
+)
+
+func TestPushSyncEvents(t *testing.T) {
+	events := pushsync.NewEvents()
+	testServer := newTestServer(t, testServerOptions{
+		PushSyncEvents: events,
+	})
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+testServer.Addr+"/pushsync/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	chunk := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	peer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+	want := pushsync.Event{
+		Type:    pushsync.EventReceiptReceived,
+		Address: chunk,
+		Peer:    peer,
+		Time:    time.Unix(1600000000, 0).UTC(),
+	}
+
+	// the subscription is made by the handler after the connection is
+	// upgraded, so publish until the event is received
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			events.Publish(want)
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}()
+
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var got pushsync.Event
+	if err := conn.ReadJSON(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Type != want.Type || !got.Address.Equal(want.Address) || !got.Peer.Equal(want.Peer) || !got.Time.Equal(want.Time) {
+		t.Fatalf("got event %+v, want %+v", got, want)
+	}
+}
+
+func TestPushSyncEventsOrigin(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{
+		PushSyncEvents: pushsync.NewEvents(),
+		CORSOrigins:    []string{"http://localhost:3000"},
+	})
+
+	for _, tc := range []struct {
+		origin   string
+		upgraded bool
+	}{
+		{origin: "http://localhost:3000", upgraded: true},
+		{origin: "http://" + testServer.Addr, upgraded: true},
+		{origin: "http://example.com"},
+	} {
+		t.Run(tc.origin, func(t *testing.T) {
+			conn, resp, err := websocket.DefaultDialer.Dial("ws://"+testServer.Addr+"/pushsync/events", http.Header{
+				"Origin": {tc.origin},
+			})
+			if tc.upgraded {
+				if err != nil {
+					t.Fatal(err)
+				}
+				conn.Close()
+				return
+			}
+			if err == nil {
+				conn.Close()
+				t.Fatal("connection from the origin upgraded")
+			}
+			if resp == nil || resp.StatusCode != http.StatusForbidden {
+				t.Fatalf("got response %v, want status %d", resp, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestPushQueue(t *testing.T) {
+	oldest := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("ok", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			PushQueue: pushQueueFunc(func() (localstore.PushQueue, error) {
+				return localstore.PushQueue{
+					Pending: 5,
+					Oldest:  oldest,
+					Tags:    map[uint32]int{7: 3, 0: 1, 2: 1},
+				}, nil
+			}),
+			PushInflight: inflightCount(2),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/pushsync/queue", nil, http.StatusOK, debugapi.PushQueueResponse{
+			Pending:       5,
+			OldestPending: &oldest,
+			Tags: []debugapi.PushQueueTag{
+				{UID: 0, Pending: 1},
+				{UID: 2, Pending: 1},
+				{UID: 7, Pending: 3},
+			},
+			Inflight: 2,
+		})
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			PushQueue: pushQueueFunc(func() (localstore.PushQueue, error) {
+				return localstore.PushQueue{}, nil
+			}),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/pushsync/queue", nil, http.StatusOK, debugapi.PushQueueResponse{
+			Tags: []debugapi.PushQueueTag{},
+		})
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/pushsync/queue", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+			Message: "push queue not supported",
+			Code:    http.StatusNotImplemented,
+		})
+	})
+}
+
+type pushQueueFunc func() (localstore.PushQueue, error)
+
+func (f pushQueueFunc) PushQueue() (localstore.PushQueue, error) { return f() }
+
+type inflightCount int
+
+func (c inflightCount) InflightCount() int { return int(c) }
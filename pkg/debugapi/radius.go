@@ -0,0 +1,34 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+// RadiusReporter reports the storage radius of the local store.
+type RadiusReporter interface {
+	Radius() uint8
+}
+
+type radiusResponse struct {
+	Radius uint8 `json:"radius"`
+}
+
+// radiusHandler responds with the storage radius, the proximity order to the
+// overlay address from which the chunks are in the responsibility of the
+// node.
+func (s *server) radiusHandler(w http.ResponseWriter, r *http.Request) {
+	if s.RadiusReporter == nil {
+		jsonhttp.NotImplemented(w, "storage radius not supported")
+		return
+	}
+
+	jsonhttp.OK(w, radiusResponse{
+		Radius: s.RadiusReporter.Radius(),
+	})
+}
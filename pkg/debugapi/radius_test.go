@@ -0,0 +1,41 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+)
+
+type radiusReporterFunc func() uint8
+
+func (f radiusReporterFunc) Radius() uint8 { return f() }
+
+func TestRadius(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			RadiusReporter: radiusReporterFunc(func() uint8 {
+				return 5
+			}),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/radius", nil, http.StatusOK, debugapi.RadiusResponse{
+			Radius: 5,
+		})
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/radius", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+			Message: "storage radius not supported",
+			Code:    http.StatusNotImplemented,
+		})
+	})
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+// ResolverCacheFlusher removes the names cached by the resolver of the node.
+type ResolverCacheFlusher interface {
+	// Flush removes all cached names and returns their number.
+	Flush() int
+}
+
+type resolverCacheResponse struct {
+	Flushed int `json:"flushed"`
+}
+
+// resolverCacheFlushHandler removes the cached names, so that they are
+// resolved again on the next requests.
+func (s *server) resolverCacheFlushHandler(w http.ResponseWriter, r *http.Request) {
+	if s.ResolverCache == nil {
+		jsonhttp.NotImplemented(w, "resolver cache not supported")
+		return
+	}
+
+	flushed := s.ResolverCache.Flush()
+	s.Logger.Debugf("debug api: resolver cache: flushed %d names", flushed)
+
+	jsonhttp.OK(w, resolverCacheResponse{
+		Flushed: flushed,
+	})
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/resolver/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestResolverCacheFlush(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		r := mock.New(map[string]swarm.Address{
+			"swarm.eth": swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c"),
+		})
+		cache := resolver.NewCache(r, resolver.CacheOptions{})
+		for _, name := range []string{"swarm.eth", "unknown.eth"} {
+			_, _ = cache.Resolve(context.Background(), name)
+		}
+
+		testServer := newTestServer(t, testServerOptions{
+			ResolverCache: cache,
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodDelete, "/resolver/cache", nil, http.StatusOK, debugapi.ResolverCacheResponse{
+			Flushed: 2,
+		})
+
+		if _, err := cache.Resolve(context.Background(), "swarm.eth"); err != nil {
+			t.Fatal(err)
+		}
+		if n := r.Lookups("swarm.eth"); n != 2 {
+			t.Errorf("got %d lookups after the flush, want %d", n, 2)
+		}
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodDelete, "/resolver/cache", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+			Message: "resolver cache not supported",
+			Code:    http.StatusNotImplemented,
+		})
+	})
+}
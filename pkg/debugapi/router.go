@@ -8,6 +8,7 @@ import (
 	"expvar"
 	"net/http"
 	"net/http/pprof"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/logging"
@@ -18,6 +19,10 @@ import (
 	"resenje.org/web"
 )
 
+// limitRetryAfter is the time after which the requests that are rejected
+// over the concurrency limit should be retried.
+const limitRetryAfter = 5 * time.Second
+
 func (s *server) setupRouting() {
 	baseRouter := http.NewServeMux()
 
@@ -32,14 +37,16 @@ func (s *server) setupRouting() {
 	router := mux.NewRouter()
 	router.NotFoundHandler = http.HandlerFunc(jsonhttp.NotFoundHandler)
 
-	router.Handle("/debug/pprof", http.HandlerFunc(pprof.Index))
-	router.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
-	router.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
-	router.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
-	router.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
-	router.PathPrefix("/debug/pprof/").Handler(http.HandlerFunc(pprof.Index))
+	if s.Profiling {
+		router.Handle("/debug/pprof", http.HandlerFunc(pprof.Index))
+		router.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+		router.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
+		router.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+		router.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+		router.PathPrefix("/debug/pprof/").Handler(http.HandlerFunc(pprof.Index))
 
-	router.Handle("/debug/vars", expvar.Handler())
+		router.Handle("/debug/vars", expvar.Handler())
+	}
 
 	router.Handle("/health", web.ChainHandlers(
 		logging.SetAccessLogLevelHandler(0), // suppress access log messages
@@ -47,7 +54,7 @@ func (s *server) setupRouting() {
 	))
 	router.Handle("/readiness", web.ChainHandlers(
 		logging.SetAccessLogLevelHandler(0), // suppress access log messages
-		web.FinalHandlerFunc(s.statusHandler),
+		web.FinalHandlerFunc(s.readinessHandler),
 	))
 
 	router.Handle("/pingpong/{peer-id}", jsonhttp.MethodHandler{
@@ -57,6 +64,9 @@ func (s *server) setupRouting() {
 	router.Handle("/addresses", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.addressesHandler),
 	})
+	router.Handle("/addressbook", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.addressbookHandler),
+	})
 	router.Handle("/connect/{multi-address:.+}", jsonhttp.MethodHandler{
 		"POST": http.HandlerFunc(s.peerConnectHandler),
 	})
@@ -67,7 +77,8 @@ func (s *server) setupRouting() {
 		"DELETE": http.HandlerFunc(s.peerDisconnectHandler),
 	})
 	router.Handle("/chunks/{address}", jsonhttp.MethodHandler{
-		"GET": http.HandlerFunc(s.hasChunkHandler),
+		"GET":    http.HandlerFunc(s.hasChunkHandler),
+		"DELETE": http.HandlerFunc(s.removeChunkHandler),
 	})
 	router.Handle("/chunks-pin/{address}", jsonhttp.MethodHandler{
 		"GET":    http.HandlerFunc(s.getPinnedChunk),
@@ -77,23 +88,139 @@ func (s *server) setupRouting() {
 	router.Handle("/chunks-pin", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.listPinnedChunks),
 	})
+	router.Handle("/gc", jsonhttp.MethodHandler{
+		"POST": http.HandlerFunc(s.gcHandler),
+	})
+
+	router.Handle("/mirror/restore", jsonhttp.MethodHandler{
+		"POST": http.HandlerFunc(s.mirrorRestoreHandler),
+	})
+
+	router.Handle("/schema", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.schemaHandler),
+	})
+
+	router.Handle("/radius", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.radiusHandler),
+	})
+
+	router.Handle("/storage", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.storageHandler),
+	})
+
+	router.Handle("/caches", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.cachesHandler),
+	})
+
+	router.Handle("/resolver/cache", jsonhttp.MethodHandler{
+		"DELETE": http.HandlerFunc(s.resolverCacheFlushHandler),
+	})
+
+	router.Handle("/balances", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.balancesHandler),
+	})
+	router.Handle("/balances/{address}", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.peerBalanceHandler),
+	})
+
+	router.Handle("/settlements", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.settlementsHandler),
+	})
+	router.Handle("/settlements/{address}", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.peerSettlementsHandler),
+	})
+
+	router.Handle("/chequebook/balance", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.chequebookBalanceHandler),
+	})
+	router.Handle("/chequebook/cashout/{address}", jsonhttp.MethodHandler{
+		"POST": http.HandlerFunc(s.chequebookCashoutHandler),
+	})
+
+	router.Handle("/config/reload", jsonhttp.MethodHandler{
+		"POST": http.HandlerFunc(s.configReloadHandler),
+	})
+
 	router.Handle("/tags", jsonhttp.MethodHandler{
 		"POST": http.HandlerFunc(s.createTag),
 	})
 	router.Handle("/tags/{uid}", jsonhttp.MethodHandler{
-		"GET": http.HandlerFunc(s.getTag),
+		"GET":    http.HandlerFunc(s.getTag),
+		"DELETE": http.HandlerFunc(s.deleteTag),
+	})
+	router.Handle("/pushsync/events", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.pushsyncEventsHandler),
+	})
+	router.Handle("/pushsync/queue", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.pushQueueHandler),
+	})
+	router.Handle("/logs", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.logsHandler),
+	})
+	router.Handle("/loggers", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.loggersHandler),
+	})
+	router.Handle("/loggers/{component}", jsonhttp.MethodHandler{
+		"DELETE": http.HandlerFunc(s.removeLoggerLevelHandler),
+	})
+	router.Handle("/loggers/{component}/{level}", jsonhttp.MethodHandler{
+		"PUT": http.HandlerFunc(s.setLoggerLevelHandler),
 	})
 	router.Handle("/topology", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.topologyHandler),
 	})
+	router.Handle("/sign", web.ChainHandlers(
+		s.adminAuthHandler,
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"POST": http.HandlerFunc(s.signHandler),
+		}),
+	))
+	router.Handle("/public-key", web.ChainHandlers(
+		s.adminAuthHandler,
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.publicKeyHandler),
+		}),
+	))
+	router.Handle("/blocklist", web.ChainHandlers(
+		s.adminAuthHandler,
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.blocklistHandler),
+		}),
+	))
+	router.Handle("/blocklist/{address}", web.ChainHandlers(
+		s.adminAuthHandler,
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"POST":   http.HandlerFunc(s.blocklistPeerHandler),
+			"DELETE": http.HandlerFunc(s.unblocklistPeerHandler),
+		}),
+	))
 
 	baseRouter.Handle("/", web.ChainHandlers(
 		logging.NewHTTPAccessLogHandler(s.Logger, logrus.InfoLevel, "debug api access"),
 		handlers.CompressHandler,
 		// todo: add recovery handler
 		web.NoCacheHeadersHandler,
+		s.cors.Handler,
+		s.restrictedHandler,
+		s.concurrencyLimitHandler(),
 		web.FinalHandler(router),
 	))
 
 	s.Handler = baseRouter
 }
+
+// concurrencyLimitHandler limits the number of the requests that are handled
+// at the same time, except the health and readiness checks, which are
+// expected to respond while the node is busy.
+func (s *server) concurrencyLimitHandler() func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		limited := s.limiter.Handler(h)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/health" || r.URL.Path == "/readiness" {
+				h.ServeHTTP(w, r)
+				return
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
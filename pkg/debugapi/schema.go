@@ -0,0 +1,41 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+// SchemaNamer reports the name of the schema of the local store data.
+type SchemaNamer interface {
+	SchemaName() (string, error)
+}
+
+type schemaResponse struct {
+	Name string `json:"name"`
+}
+
+// schemaHandler responds with the name of the schema of the local store, to
+// which its data is migrated when the node starts.
+func (s *server) schemaHandler(w http.ResponseWriter, r *http.Request) {
+	if s.SchemaNamer == nil {
+		jsonhttp.NotImplemented(w, "schema not supported")
+		return
+	}
+
+	name, err := s.SchemaNamer.SchemaName()
+	if err != nil {
+		s.Logger.Debugf("debug api: schema: %v", err)
+		s.Logger.Error("debug api: schema")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, schemaResponse{
+		Name: name,
+	})
+}
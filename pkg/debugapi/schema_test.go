@@ -0,0 +1,55 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+)
+
+type schemaNamerFunc func() (string, error)
+
+func (f schemaNamerFunc) SchemaName() (string, error) { return f() }
+
+func TestSchema(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			SchemaNamer: schemaNamerFunc(func() (string, error) {
+				return "code", nil
+			}),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/schema", nil, http.StatusOK, debugapi.SchemaResponse{
+			Name: "code",
+		})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			SchemaNamer: schemaNamerFunc(func() (string, error) {
+				return "", errors.New("schema error")
+			}),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/schema", nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+			Message: "schema error",
+			Code:    http.StatusInternalServerError,
+		})
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/schema", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+			Message: "schema not supported",
+			Code:    http.StatusNotImplemented,
+		})
+	})
+}
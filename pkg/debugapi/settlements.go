@@ -0,0 +1,129 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type settlementResponse struct {
+	Peer     string `json:"peer"`
+	Sent     uint64 `json:"sent"`
+	Received uint64 `json:"received"`
+}
+
+type settlementsResponse struct {
+	TotalSent     uint64               `json:"totalSent"`
+	TotalReceived uint64               `json:"totalReceived"`
+	Settlements   []settlementResponse `json:"settlements"`
+}
+
+// settlementsHandler responds with the total amounts settled with every
+// peer, sorted by the peer address.
+func (s *server) settlementsHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Settlement == nil {
+		jsonhttp.NotImplemented(w, "settlement not supported")
+		return
+	}
+
+	sent, err := s.Settlement.SettlementsSent()
+	if err != nil {
+		s.Logger.Debugf("debug api: settlements: sent: %v", err)
+		s.Logger.Error("debug api: settlements: sent")
+		jsonhttp.InternalServerError(w, "cannot get settlements")
+		return
+	}
+	received, err := s.Settlement.SettlementsReceived()
+	if err != nil {
+		s.Logger.Debugf("debug api: settlements: received: %v", err)
+		s.Logger.Error("debug api: settlements: received")
+		jsonhttp.InternalServerError(w, "cannot get settlements")
+		return
+	}
+
+	peers := make(map[string]*settlementResponse)
+	for peer, amount := range sent {
+		peers[peer] = &settlementResponse{Peer: peer, Sent: amount}
+	}
+	for peer, amount := range received {
+		if _, ok := peers[peer]; !ok {
+			peers[peer] = &settlementResponse{Peer: peer}
+		}
+		peers[peer].Received = amount
+	}
+
+	resp := settlementsResponse{
+		Settlements: make([]settlementResponse, 0, len(peers)),
+	}
+	for _, p := range peers {
+		resp.TotalSent += p.Sent
+		resp.TotalReceived += p.Received
+		resp.Settlements = append(resp.Settlements, *p)
+	}
+	sort.Slice(resp.Settlements, func(i, j int) bool {
+		return resp.Settlements[i].Peer < resp.Settlements[j].Peer
+	})
+
+	jsonhttp.OK(w, resp)
+}
+
+// peerSettlementsHandler responds with the total amounts settled with a
+// single peer.
+func (s *server) peerSettlementsHandler(w http.ResponseWriter, r *http.Request) {
+	if s.Settlement == nil {
+		jsonhttp.NotImplemented(w, "settlement not supported")
+		return
+	}
+
+	peer, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.Logger.Debugf("debug api: settlements peer: invalid peer address: %v", err)
+		s.Logger.Error("debug api: settlements peer: invalid peer address")
+		jsonhttp.BadRequest(w, "invalid peer address")
+		return
+	}
+
+	var found bool
+	sent, err := s.Settlement.TotalSent(peer)
+	switch {
+	case err == nil:
+		found = true
+	case errors.Is(err, settlement.ErrPeerNoSettlements):
+	default:
+		s.Logger.Debugf("debug api: settlements peer: get peer %s sent: %v", peer, err)
+		s.Logger.Errorf("debug api: settlements peer: get peer %s sent", peer)
+		jsonhttp.InternalServerError(w, "cannot get peer settlements")
+		return
+	}
+	received, err := s.Settlement.TotalReceived(peer)
+	switch {
+	case err == nil:
+		found = true
+	case errors.Is(err, settlement.ErrPeerNoSettlements):
+	default:
+		s.Logger.Debugf("debug api: settlements peer: get peer %s received: %v", peer, err)
+		s.Logger.Errorf("debug api: settlements peer: get peer %s received", peer)
+		jsonhttp.InternalServerError(w, "cannot get peer settlements")
+		return
+	}
+
+	if !found {
+		jsonhttp.NotFound(w, "no settlements for peer")
+		return
+	}
+
+	jsonhttp.OK(w, settlementResponse{
+		Peer:     peer.String(),
+		Sent:     sent,
+		Received: received,
+	})
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	settlementmock "github.com/ethersphere/bee/pkg/settlement/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestSettlements(t *testing.T) {
+	peer1 := swarm.MustParseHexAddress("b0baf377")
+	peer2 := swarm.MustParseHexAddress("a0baf377")
+	peer3 := swarm.MustParseHexAddress("c0baf377")
+
+	s := settlementmock.NewSettlement()
+	if _, err := s.Pay(context.Background(), peer1, 100); err != nil {
+		t.Fatal(err)
+	}
+	s.Receive(peer1, 20)
+	s.Receive(peer2, 30)
+
+	testServer := newTestServer(t, testServerOptions{
+		Settlement: s,
+	})
+
+	t.Run("all", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/settlements", nil, http.StatusOK, debugapi.SettlementsResponse{
+			TotalSent:     100,
+			TotalReceived: 50,
+			Settlements: []debugapi.SettlementResponse{
+				{Peer: peer2.String(), Sent: 0, Received: 30},
+				{Peer: peer1.String(), Sent: 100, Received: 20},
+			},
+		})
+	})
+
+	t.Run("peer", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/settlements/"+peer2.String(), nil, http.StatusOK, debugapi.SettlementResponse{
+			Peer:     peer2.String(),
+			Sent:     0,
+			Received: 30,
+		})
+	})
+
+	t.Run("peer without settlements", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/settlements/"+peer3.String(), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "no settlements for peer",
+			Code:    http.StatusNotFound,
+		})
+	})
+}
+
+func TestSettlementsNotSupported(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/settlements", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+		Message: "settlement not supported",
+		Code:    http.StatusNotImplemented,
+	})
+}
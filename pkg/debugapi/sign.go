@@ -0,0 +1,82 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+const maxSignPayloadSize = 64 * 1024 // maximal size of the payload to sign
+
+type signResponse struct {
+	Signature string `json:"signature"` // hex encoded signature
+	Prefix    string `json:"prefix"`    // prefix of the signed message
+	Digest    string `json:"digest"`    // hex encoded signed digest
+}
+
+type publicKeyResponse struct {
+	PublicKey string `json:"publicKey"` // hex encoded compressed public key
+}
+
+// signHandler signs the request body with the key of the node, so that the
+// signature can be verified against the node public key. The payload is never
+// signed directly, but only the keccak256 hash of it prefixed with
+// crypto.SignedMessagePrefix and its length, so that the endpoint can not be
+// used to sign handshake addresses, cheques or other digests of the node.
+func (s *server) signHandler(w http.ResponseWriter, r *http.Request) {
+	payload, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxSignPayloadSize))
+	if err != nil {
+		s.Logger.Debugf("debug api: sign: read payload: %v", err)
+		s.Logger.Error("debug api: sign: read payload")
+		jsonhttp.BadRequest(w, "invalid payload")
+		return
+	}
+	if len(payload) == 0 {
+		jsonhttp.BadRequest(w, "empty payload")
+		return
+	}
+
+	digest, err := crypto.HashSignedMessage(payload)
+	if err != nil {
+		s.Logger.Debugf("debug api: sign: hash payload: %v", err)
+		s.Logger.Error("debug api: sign: hash payload")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	signature, err := s.Signer.Sign(digest)
+	if err != nil {
+		s.Logger.Debugf("debug api: sign: %v", err)
+		s.Logger.Error("debug api: sign")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, signResponse{
+		Signature: hex.EncodeToString(signature),
+		Prefix:    crypto.SignedMessagePrefix,
+		Digest:    hex.EncodeToString(digest),
+	})
+}
+
+// publicKeyHandler returns the public key of the node that signs payloads.
+func (s *server) publicKeyHandler(w http.ResponseWriter, r *http.Request) {
+	publicKey, err := s.Signer.PublicKey()
+	if err != nil {
+		s.Logger.Debugf("debug api: public key: %v", err)
+		s.Logger.Error("debug api: public key")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, publicKeyResponse{
+		PublicKey: hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(publicKey)),
+	})
+}
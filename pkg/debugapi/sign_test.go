@@ -0,0 +1,97 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+)
+
+func TestSign(t *testing.T) {
+	privateKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		signer     = crypto.NewDefaultSigner(privateKey)
+		adminToken = "secret"
+		payload    = []byte("payload to sign")
+		authorized = http.Header{"Authorization": {"Bearer " + adminToken}}
+		testServer = newTestServer(t, testServerOptions{
+			Signer:     signer,
+			AdminToken: adminToken,
+		})
+	)
+
+	t.Run("sign", func(t *testing.T) {
+		digest, err := crypto.HashSignedMessage(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature, err := signer.Sign(digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodPost, "/sign", bytes.NewReader(payload), http.StatusOK, debugapi.SignResponse{
+			Signature: hex.EncodeToString(signature),
+			Prefix:    crypto.SignedMessagePrefix,
+			Digest:    hex.EncodeToString(digest),
+		}, authorized)
+
+		publicKey, err := crypto.Recover(signature, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if publicKey.X.Cmp(privateKey.X) != 0 || publicKey.Y.Cmp(privateKey.Y) != 0 {
+			t.Fatal("signature not made with the node key")
+		}
+	})
+
+	t.Run("empty payload", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodPost, "/sign", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "empty payload",
+			Code:    http.StatusBadRequest,
+		}, authorized)
+	})
+
+	t.Run("public key", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodGet, "/public-key", nil, http.StatusOK, debugapi.PublicKeyResponse{
+			PublicKey: hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&privateKey.PublicKey)),
+		}, authorized)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		for _, headers := range []http.Header{
+			nil,
+			{"Authorization": {"Bearer wrong"}},
+			{"Authorization": {adminToken}},
+		} {
+			jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodPost, "/sign", bytes.NewReader(payload), http.StatusUnauthorized, jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusUnauthorized),
+				Code:    http.StatusUnauthorized,
+			}, headers)
+		}
+	})
+
+	t.Run("admin token not set", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			Signer:       signer,
+			NoAdminToken: true,
+		})
+
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, testServer.Client, http.MethodGet, "/public-key", nil, http.StatusForbidden, jsonhttp.StatusResponse{
+			Message: "admin token not set",
+			Code:    http.StatusForbidden,
+		}, http.Header{"Authorization": {"Bearer "}})
+	})
+}
@@ -10,12 +10,49 @@ import (
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 )
 
+// ReadinessCheck checks a component of the node, such as the p2p service or
+// the storage, and returns an error if it is not ready.
+type ReadinessCheck func() error
+
 type statusResponse struct {
 	Status string `json:"status"`
 }
 
+// statusHandler reports that the node is alive, as long as the debug API
+// serves requests.
 func (s *server) statusHandler(w http.ResponseWriter, r *http.Request) {
 	jsonhttp.OK(w, statusResponse{
 		Status: "ok",
 	})
 }
+
+type readinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// readinessHandler runs all readiness checks and reports the node as ready
+// only if all of them passed, otherwise it responds with the 503 status.
+func (s *server) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	resp := readinessResponse{
+		Status: "ok",
+	}
+	if len(s.ReadinessChecks) > 0 {
+		resp.Checks = make(map[string]string, len(s.ReadinessChecks))
+	}
+	for name, check := range s.ReadinessChecks {
+		if err := check(); err != nil {
+			s.Logger.Debugf("debug api: readiness check %s: %v", name, err)
+			resp.Status = "not ready"
+			resp.Checks[name] = err.Error()
+			continue
+		}
+		resp.Checks[name] = "ok"
+	}
+
+	if resp.Status != "ok" {
+		jsonhttp.ServiceUnavailable(w, resp)
+		return
+	}
+	jsonhttp.OK(w, resp)
+}
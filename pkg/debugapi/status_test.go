@@ -5,6 +5,7 @@
 package debugapi_test
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 
@@ -27,3 +28,51 @@ func TestReadiness(t *testing.T) {
 		Status: "ok",
 	})
 }
+
+func TestReadinessChecks(t *testing.T) {
+	ready := map[string]debugapi.ReadinessCheck{
+		"p2p":     func() error { return nil },
+		"storage": func() error { return nil },
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			Readiness: ready,
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/readiness", nil, http.StatusOK, debugapi.ReadinessResponse{
+			Status: "ok",
+			Checks: map[string]string{
+				"p2p":     "ok",
+				"storage": "ok",
+			},
+		})
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		ready["peers"] = func() error { return errors.New("0 connected peers, want at least 1") }
+		testServer := newTestServer(t, testServerOptions{
+			Readiness: ready,
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/readiness", nil, http.StatusServiceUnavailable, debugapi.ReadinessResponse{
+			Status: "not ready",
+			Checks: map[string]string{
+				"p2p":     "ok",
+				"storage": "ok",
+				"peers":   "0 connected peers, want at least 1",
+			},
+		})
+	})
+
+	t.Run("health", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			Readiness: ready,
+		})
+
+		// the node is alive regardless of the readiness checks
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/health", nil, http.StatusOK, debugapi.StatusResponse{
+			Status: "ok",
+		})
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+// StorageReporter reports the stored chunks of the local store by their
+// proximity order to the overlay address.
+type StorageReporter interface {
+	RadiusReporter
+	// ProximityHistogram returns the numbers of the stored chunks by their
+	// proximity orders.
+	ProximityHistogram() ([]uint64, error)
+}
+
+type storageBin struct {
+	PO           uint8  `json:"po"`
+	Chunks       uint64 `json:"chunks"`
+	WithinRadius bool   `json:"withinRadius"`
+}
+
+type storageResponse struct {
+	Radius uint8        `json:"radius"`
+	Chunks uint64       `json:"chunks"`
+	Bins   []storageBin `json:"bins"`
+}
+
+// storageHandler responds with the numbers of the stored chunks in every
+// proximity order bin, and whether the bins are within the storage radius,
+// so that it can be verified that the node stores its neighbourhood.
+func (s *server) storageHandler(w http.ResponseWriter, r *http.Request) {
+	if s.StorageReporter == nil {
+		jsonhttp.NotImplemented(w, "storage statistics not supported")
+		return
+	}
+
+	counts, err := s.StorageReporter.ProximityHistogram()
+	if err != nil {
+		s.Logger.Debugf("debug api: storage: %v", err)
+		s.Logger.Error("debug api: storage")
+		jsonhttp.InternalServerError(w, "cannot get storage statistics")
+		return
+	}
+
+	resp := storageResponse{
+		Radius: s.StorageReporter.Radius(),
+		Bins:   make([]storageBin, 0, len(counts)),
+	}
+	for po, count := range counts {
+		resp.Chunks += count
+		resp.Bins = append(resp.Bins, storageBin{
+			PO:           uint8(po),
+			Chunks:       count,
+			WithinRadius: uint8(po) >= resp.Radius,
+		})
+	}
+
+	jsonhttp.OK(w, resp)
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+)
+
+type storageReporterMock struct {
+	radius uint8
+	counts []uint64
+	err    error
+}
+
+func (m storageReporterMock) Radius() uint8 { return m.radius }
+
+func (m storageReporterMock) ProximityHistogram() ([]uint64, error) { return m.counts, m.err }
+
+func TestStorage(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			StorageStats: storageReporterMock{
+				radius: 2,
+				counts: []uint64{10, 0, 5, 7},
+			},
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/storage", nil, http.StatusOK, debugapi.StorageResponse{
+			Radius: 2,
+			Chunks: 22,
+			Bins: []debugapi.StorageBin{
+				{PO: 0, Chunks: 10, WithinRadius: false},
+				{PO: 1, Chunks: 0, WithinRadius: false},
+				{PO: 2, Chunks: 5, WithinRadius: true},
+				{PO: 3, Chunks: 7, WithinRadius: true},
+			},
+		})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			StorageStats: storageReporterMock{
+				err: errors.New("failed"),
+			},
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/storage", nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+			Message: "cannot get storage statistics",
+			Code:    http.StatusInternalServerError,
+		})
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/storage", nil, http.StatusNotImplemented, jsonhttp.StatusResponse{
+			Message: "storage statistics not supported",
+			Code:    http.StatusNotImplemented,
+		})
+	})
+}
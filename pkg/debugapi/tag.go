@@ -26,8 +26,10 @@ type tagResponse struct {
 	Stored    int64         `json:"stored"`
 	Sent      int64         `json:"sent"`
 	Synced    int64         `json:"synced"`
+	Receipts  int64         `json:"receipts"`
 	Uid       uint32        `json:"uid"`
 	Anonymous bool          `json:"anonymous"`
+	Priority  tags.Priority `json:"priority"`
 	Name      string        `json:"name"`
 	Address   swarm.Address `json:"address"`
 	StartedAt time.Time     `json:"startedAt"`
@@ -41,8 +43,10 @@ func newTagResponse(tag *tags.Tag) tagResponse {
 		Stored:    tag.Stored,
 		Sent:      tag.Sent,
 		Synced:    tag.Synced,
+		Receipts:  tag.Receipts,
 		Uid:       tag.Uid,
 		Anonymous: tag.Anonymous,
+		Priority:  tag.Priority,
 		Name:      tag.Name,
 		Address:   tag.Address,
 		StartedAt: tag.StartedAt,
@@ -63,7 +67,19 @@ func (s *server) createTag(w http.ResponseWriter, r *http.Request) {
 		name = fmt.Sprintf("tag-%v-%x", time.Now().UnixNano(), b)
 	}
 
-	tag, err := s.Tags.Create(name, 0, false)
+	priority := tags.PriorityNormal
+	if p := r.URL.Query().Get("priority"); p != "" {
+		v, err := strconv.ParseUint(p, 10, 8)
+		if err != nil {
+			s.Logger.Debugf("create tag: parse priority %s: %v", p, err)
+			s.Logger.Error("create tag: parse priority")
+			jsonhttp.BadRequest(w, "invalid priority")
+			return
+		}
+		priority = tags.Priority(v)
+	}
+
+	tag, err := s.Tags.CreateWithPriority(name, 0, false, priority)
 	if err != nil {
 		s.Logger.Debugf("create tag: %s %v", name, err)
 		s.Logger.Errorf("create tag: %s error", name)
@@ -103,3 +119,34 @@ func (s *server) getTag(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Cache-Control", "no-cache, private, max-age=0")
 	jsonhttp.OK(w, newTagResponse(tag))
 }
+
+// deleteTag removes the tag, so that its counters are no longer kept. The
+// chunks that are uploaded with the tag are not affected.
+func (s *server) deleteTag(w http.ResponseWriter, r *http.Request) {
+	uidStr := mux.Vars(r)["uid"]
+
+	uid, err := strconv.ParseUint(uidStr, 10, 32)
+	if err != nil {
+		s.Logger.Debugf("delete tag: parse uid  %s: %v", uidStr, err)
+		s.Logger.Error("delete tag: parse uid")
+		jsonhttp.BadRequest(w, "invalid uid")
+		return
+	}
+
+	tag, err := s.Tags.Get(uint32(uid))
+	if err != nil {
+		if errors.Is(err, tags.ErrNotFound) {
+			s.Logger.Debugf("delete tag: tag %v not present: %v", uid, err)
+			s.Logger.Warningf("delete tag: tag %v not present", uid)
+			jsonhttp.NotFound(w, "tag not present")
+			return
+		}
+		s.Logger.Debugf("delete tag: tag %v: %v", uid, err)
+		s.Logger.Errorf("delete tag: %v", uid)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	s.Tags.Delete(tag.Uid)
+	jsonhttp.OK(w, nil)
+}
@@ -52,6 +52,22 @@ func TestTags(t *testing.T) {
 		}, sentHheaders)
 	})
 
+	t.Run("create-tag-with-priority", func(t *testing.T) {
+		ta := debugapi.TagResponse{}
+		jsonhttptest.ResponseUnmarshal(t, ts.Client, http.MethodPost, "/tags?name=urgent&priority=1", nil, http.StatusOK, &ta)
+
+		if ta.Priority != tags.PriorityHigh {
+			t.Fatalf("got priority %d, want %d", ta.Priority, tags.PriorityHigh)
+		}
+	})
+
+	t.Run("create-tag-with-invalid-priority", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, ts.Client, http.MethodPost, "/tags?name=urgent&priority=high", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid priority",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
 	t.Run("uid-header-in-return-for-empty-tag", func(t *testing.T) {
 		rcvdHeaders := jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, apiClient, http.MethodPost, resource(validHash), bytes.NewReader(validContent), http.StatusOK, jsonhttp.StatusResponse{
 			Message: http.StatusText(http.StatusOK),
@@ -201,6 +217,27 @@ func TestTags(t *testing.T) {
 		if tagToVerify.Synced != finalTag.Synced {
 			t.Errorf("tag synced count mismatch. got %d want %d", tagToVerify.Synced, finalTag.Synced)
 		}
+		if finalTag.Receipts != 1 {
+			t.Errorf("tag receipts count mismatch. got %d want %d", finalTag.Receipts, 1)
+		}
+	})
+
+	t.Run("delete-tag", func(t *testing.T) {
+		ta := debugapi.TagResponse{}
+		jsonhttptest.ResponseUnmarshal(t, ts.Client, http.MethodPost, tagResourceUidCreate("deleted"), nil, http.StatusOK, &ta)
+
+		jsonhttptest.ResponseDirect(t, ts.Client, http.MethodDelete, tagResourceUUid(uint64(ta.Uid)), nil, http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		})
+		jsonhttptest.ResponseDirect(t, ts.Client, http.MethodGet, tagResourceUUid(uint64(ta.Uid)), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "tag not present",
+			Code:    http.StatusNotFound,
+		})
+		jsonhttptest.ResponseDirect(t, ts.Client, http.MethodDelete, tagResourceUUid(uint64(ta.Uid)), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "tag not present",
+			Code:    http.StatusNotFound,
+		})
 	})
 }
 
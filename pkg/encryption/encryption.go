@@ -97,11 +97,11 @@ func (e *Encryption) Reset() {
 func (e *Encryption) transform(in, out []byte) error {
 	inLength := len(in)
 	wg := sync.WaitGroup{}
-	wg.Add((inLength-1)/e.keyLen + 1)
 
 	for i := 0; i < inLength; i += e.keyLen {
 		errs := make(chan error, 1)
 		l := min(e.keyLen, inLength-i)
+		wg.Add(1)
 		go func(i int, x, y []byte) {
 			defer wg.Done()
 			err := e.Transcrypt(i, x, y)
@@ -63,6 +63,18 @@ func TestEncryptDataZeroPadding(t *testing.T) {
 	}
 }
 
+func TestEncryptEmptyData(t *testing.T) {
+	enc := New(testKey, 8, uint32(0), hashFunc)
+
+	encrypted, err := enc.Encrypt([]byte{})
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if len(encrypted) != 8 {
+		t.Fatalf("Encrypted data length expected \"%v\" got %v", 8, len(encrypted))
+	}
+}
+
 func TestEncryptDataLengthEqualsPadding(t *testing.T) {
 	enc := New(testKey, 4096, uint32(0), hashFunc)
 
@@ -10,26 +10,22 @@ import (
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
-const (
-	maxBufferSize = swarm.ChunkSize * 2
-)
-
 // ChunkPipe ensures that only the last read is smaller than the chunk size,
 // regardless of size of individual writes.
 type ChunkPipe struct {
 	io.ReadCloser
-	writer io.WriteCloser
+	writer *io.PipeWriter
 	data   []byte
 	cursor int
 }
 
 // Creates a new ChunkPipe
-func NewChunkPipe() io.ReadWriteCloser {
+func NewChunkPipe() *ChunkPipe {
 	r, w := io.Pipe()
 	return &ChunkPipe{
 		ReadCloser: r,
 		writer:     w,
-		data:       make([]byte, maxBufferSize),
+		data:       make([]byte, swarm.ChunkSize),
 	}
 }
 
@@ -39,18 +35,23 @@ func (c *ChunkPipe) Read(b []byte) (int, error) {
 }
 
 // Writer implements io.Writer
+//
+// Writes may be of any size, as io.Copy passes all of the data of sources
+// that implement io.WriterTo in a single write.
 func (c *ChunkPipe) Write(b []byte) (int, error) {
-	copy(c.data[c.cursor:], b)
-	c.cursor += len(b)
-	if c.cursor >= swarm.ChunkSize {
-		_, err := c.writer.Write(c.data[:swarm.ChunkSize])
-		if err != nil {
-			return len(b), err
+	var written int
+	for written < len(b) {
+		n := copy(c.data[c.cursor:], b[written:])
+		c.cursor += n
+		written += n
+		if c.cursor == swarm.ChunkSize {
+			if _, err := c.writer.Write(c.data); err != nil {
+				return written, err
+			}
+			c.cursor = 0
 		}
-		c.cursor -= swarm.ChunkSize
-		copy(c.data, c.data[swarm.ChunkSize:])
 	}
-	return len(b), nil
+	return written, nil
 }
 
 // Closer implements io.Closer
@@ -63,3 +64,9 @@ func (c *ChunkPipe) Close() error {
 	}
 	return c.writer.Close()
 }
+
+// CloseWithError closes the pipe without writing the buffered data, so that
+// reads return the error.
+func (c *ChunkPipe) CloseWithError(err error) error {
+	return c.writer.CloseWithError(err)
+}
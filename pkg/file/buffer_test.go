@@ -27,6 +27,7 @@ var (
 		{swarm.ChunkSize, 2, swarm.ChunkSize},         // on, short, over
 		{swarm.ChunkSize, 2, swarm.ChunkSize - 2, 4},  // on, short, on, short
 		{swarm.ChunkSize, swarm.ChunkSize},            // on, on
+		{swarm.ChunkSize*2 + 2, swarm.ChunkSize - 2},  // over two, on
 	}
 )
 
@@ -67,27 +67,31 @@ func JoinReadAll(j Joiner, addr swarm.Address, outFile io.Writer, toDecrypt bool
 }
 
 // SplitWriteAll writes all input from provided reader to the provided splitter
+//
+// The splitting is aborted if the reader returns an error, as it does when the
+// client of an upload disconnects, or if the context is done.
 func SplitWriteAll(ctx context.Context, s Splitter, r io.Reader, l int64, toEncrypt bool) (swarm.Address, error) {
 	chunkPipe := NewChunkPipe()
-	errC := make(chan error)
+	errC := make(chan error, 1)
 	go func() {
 		buf := make([]byte, swarm.ChunkSize)
 		c, err := io.CopyBuffer(chunkPipe, r, buf)
-		if err != nil {
-			errC <- err
-		}
-		if c != l {
-			errC <- errors.New("read count mismatch")
+		if err == nil && c != l {
+			err = errors.New("read count mismatch")
 		}
-		err = chunkPipe.Close()
 		if err != nil {
+			// unblock the splitter that is reading from the pipe
+			_ = chunkPipe.CloseWithError(err)
 			errC <- err
+			return
 		}
-		close(errC)
+		errC <- chunkPipe.Close()
 	}()
 
 	addr, err := s.Split(ctx, chunkPipe, l, toEncrypt)
 	if err != nil {
+		// unblock the copying if the splitter stopped reading
+		_ = chunkPipe.ReadCloser.Close()
 		return swarm.ZeroAddress, err
 	}
 
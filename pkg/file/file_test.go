@@ -7,11 +7,13 @@ package file_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"io/ioutil"
 	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/ethersphere/bee/pkg/file"
 	"github.com/ethersphere/bee/pkg/file/joiner"
@@ -106,6 +108,19 @@ func TestJoinReadAll(t *testing.T) {
 
 }
 
+// TestSplitWriteAllReadError verifies that the splitting is aborted with the
+// error of the reader, as it is when the client of an upload disconnects.
+func TestSplitWriteAllReadError(t *testing.T) {
+	data := test.GenerateTestData(t, swarm.ChunkSize*3)
+	r := io.MultiReader(bytes.NewReader(data[:swarm.ChunkSize+10]), iotest.TimeoutReader(bytes.NewReader(data[swarm.ChunkSize+10:swarm.ChunkSize*2])))
+
+	s := splitter.NewSimpleSplitter(mock.NewStorer())
+	_, err := file.SplitWriteAll(context.Background(), s, r, int64(len(data)), false)
+	if !errors.Is(err, iotest.ErrTimeout) {
+		t.Fatalf("got error %v, want %v", err, iotest.ErrTimeout)
+	}
+}
+
 // mockJoiner is an implementation of file,Joiner that short-circuits that returns
 // a mock byte vector of the length given at initialization.
 type mockJoiner struct {
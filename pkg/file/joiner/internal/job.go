@@ -54,7 +54,11 @@ type SimpleJoinerJob struct {
 // NewSimpleJoinerJob creates a new simpleJoinerJob.
 func NewSimpleJoinerJob(ctx context.Context, getter storage.Getter, rootChunk swarm.Chunk, toDecrypt bool) *SimpleJoinerJob {
 	spanLength := binary.LittleEndian.Uint64(rootChunk.Data()[:8])
-	levelCount := file.Levels(int64(spanLength), swarm.SectionSize, swarm.Branches)
+	branches := swarm.Branches
+	if toDecrypt {
+		branches = swarm.ChunkSize / (swarm.HashSize + encryption.KeyLength)
+	}
+	levelCount := file.Levels(int64(spanLength), swarm.ChunkSize/branches, branches)
 
 	j := &SimpleJoinerJob{
 		ctx:        ctx,
@@ -108,6 +112,20 @@ func (j *SimpleJoinerJob) nextReference(level int) error {
 	data := j.data[level]
 	cursor := j.cursors[level]
 
+	refLength := swarm.SectionSize
+	if j.toDecrypt {
+		refLength += encryption.KeyLength
+	}
+	// a "dangling chunk" that is shorter than a reference can only be the
+	// data chunk that has been moved to an intermediate level
+	if cursor+refLength > len(data) {
+		if j.readCount+int64(len(data)) == j.spanLength {
+			j.cursors[level] = len(j.data[level])
+			return j.sendChunkToReader(data)
+		}
+		return fmt.Errorf("error in join: reference out of bounds of chunk with %d bytes", len(data))
+	}
+
 	var encryptionKey encryption.Key
 	chunkAddress := swarm.NewAddress(data[cursor : cursor+swarm.SectionSize])
 	if j.toDecrypt {
@@ -133,10 +151,7 @@ func (j *SimpleJoinerJob) nextReference(level int) error {
 	}
 
 	// move the cursor to the next reference
-	j.cursors[level] += swarm.SectionSize
-	if j.toDecrypt {
-		j.cursors[level] += encryption.KeyLength
-	}
+	j.cursors[level] += refLength
 	return nil
 }
 
@@ -30,65 +30,77 @@ func NewSimpleJoiner(getter storage.Getter) file.Joiner {
 	}
 }
 
+// Size returns the length of the data referenced by the address. The root
+// chunk is decrypted if the address is an encrypted reference, that is it
+// contains the decryption key after the chunk address.
 func (s *simpleJoiner) Size(ctx context.Context, address swarm.Address) (dataSize int64, err error) {
-	// retrieve the root chunk to read the total data length the be retrieved
-	rootChunk, err := s.getter.Get(ctx, storage.ModeGetRequest, address)
+	toDecrypt := len(address.Bytes()) == swarm.HashSize+encryption.KeyLength
+	chunkData, err := s.rootChunkData(ctx, address, toDecrypt)
 	if err != nil {
 		return 0, err
 	}
 
-	chunkLength := rootChunk.Data()
-	if len(chunkLength) < 8 {
-		return 0, fmt.Errorf("invalid chunk content of %d bytes", chunkLength)
-	}
-
-	dataLength := binary.LittleEndian.Uint64(rootChunk.Data())
+	dataLength := binary.LittleEndian.Uint64(chunkData)
 	return int64(dataLength), nil
 }
 
 // Join implements the file.Joiner interface.
 //
 // It uses a non-optimized internal component that only retrieves a data chunk
-// after the previous has been read.
+// after the previous has been read. The data is decrypted if toDecrypt is set
+// or the address is an encrypted reference.
 func (s *simpleJoiner) Join(ctx context.Context, address swarm.Address, toDecrypt bool) (dataOut io.ReadCloser, dataSize int64, err error) {
-	var addr []byte
-	var key encryption.Key
-	if toDecrypt {
-		addr = address.Bytes()[:swarm.HashSize]
-		key = address.Bytes()[swarm.HashSize : swarm.HashSize+encryption.KeyLength]
-	} else {
-		addr = address.Bytes()
-	}
+	toDecrypt = toDecrypt || len(address.Bytes()) == swarm.HashSize+encryption.KeyLength
 
 	// retrieve the root chunk to read the total data length the be retrieved
-	rootChunk, err := s.getter.Get(ctx, storage.ModeGetRequest, swarm.NewAddress(addr))
+	chunkData, err := s.rootChunkData(ctx, address, toDecrypt)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	var chunkData []byte
-	if toDecrypt {
-		originalData, err := internal.DecryptChunkData(rootChunk.Data(), key)
-		if err != nil {
-			return nil, 0, err
-		}
-		chunkData = originalData
-	} else {
-		chunkData = rootChunk.Data()
-	}
-
 	// if this is a single chunk, short circuit to returning just that chunk
 	spanLength := binary.LittleEndian.Uint64(chunkData[:8])
-	chunkToSend := rootChunk
 	if spanLength <= swarm.ChunkSize {
 		data := chunkData[8:]
-		return file.NewSimpleReadCloser(data), int64(spanLength), nil
+		if uint64(len(data)) < spanLength {
+			return nil, 0, fmt.Errorf("invalid chunk content of %d bytes for span %d", len(data), spanLength)
+		}
+		return file.NewSimpleReadCloser(data[:spanLength]), int64(spanLength), nil
 	}
 
+	rootChunk := swarm.NewChunk(swarm.NewAddress(address.Bytes()[:swarm.HashSize]), chunkData)
+	r := internal.NewSimpleJoinerJob(ctx, s.getter, rootChunk, toDecrypt)
+	return r, int64(spanLength), nil
+}
+
+// rootChunkData retrieves the root chunk of the address and returns its
+// data, decrypted with the key from the address if toDecrypt is set.
+func (s *simpleJoiner) rootChunkData(ctx context.Context, address swarm.Address, toDecrypt bool) ([]byte, error) {
+	addr := address.Bytes()
+	var key encryption.Key
 	if toDecrypt {
-		chunkToSend = swarm.NewChunk(swarm.NewAddress(addr), chunkData)
+		if len(addr) != swarm.HashSize+encryption.KeyLength {
+			return nil, fmt.Errorf("invalid encrypted reference length %d", len(addr))
+		}
+		key = addr[swarm.HashSize:]
+		addr = addr[:swarm.HashSize]
 	}
 
-	r := internal.NewSimpleJoinerJob(ctx, s.getter, chunkToSend, toDecrypt)
-	return r, int64(spanLength), nil
+	rootChunk, err := s.getter.Get(ctx, storage.ModeGetRequest, swarm.NewAddress(addr))
+	if err != nil {
+		return nil, err
+	}
+
+	chunkData := rootChunk.Data()
+	if toDecrypt {
+		chunkData, err = internal.DecryptChunkData(chunkData, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(chunkData) < 8 {
+		return nil, fmt.Errorf("invalid chunk content of %d bytes", len(chunkData))
+	}
+	return chunkData, nil
 }
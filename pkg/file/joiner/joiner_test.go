@@ -19,7 +19,7 @@ import (
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
-	"gitlab.com/nolash/go-mockbytes"
+	"github.com/ethersphere/bee/pkg/validator"
 )
 
 // TestJoiner verifies that a newly created joiner returns the data stored
@@ -67,6 +67,67 @@ func TestJoinerSingleChunk(t *testing.T) {
 	}
 }
 
+// TestJoinerZeroLength verifies that the joiner returns an empty reader for
+// the reference of empty data.
+func TestJoinerZeroLength(t *testing.T) {
+	for _, toEncrypt := range []bool{false, true} {
+		store := mock.NewStorer()
+		addr, err := splitter.NewSimpleSplitter(store).Split(context.Background(), file.NewSimpleReadCloser(nil), 0, toEncrypt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		j := joiner.NewSimpleJoiner(store)
+		r, l, err := j.Join(context.Background(), addr, toEncrypt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l != 0 {
+			t.Fatalf("expected join data length 0, got %d", l)
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 0 {
+			t.Fatalf("expected no data, got %x", data)
+		}
+
+		size, err := j.Size(context.Background(), addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != 0 {
+			t.Fatalf("expected size 0, got %d", size)
+		}
+	}
+}
+
+// TestJoinerInvalidChunk verifies that a root chunk that is too short to
+// contain the span or the announced data is rejected.
+func TestJoinerInvalidChunk(t *testing.T) {
+	store := mock.NewStorer()
+	joiner := joiner.NewSimpleJoiner(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, data := range [][]byte{
+		{0x01, 0x02}, // no span
+		{0x05, 0, 0, 0, 0, 0, 0, 0, 'f', 'o', 'o'}, // span larger than data
+	} {
+		addr := swarm.MustParseHexAddress(fmt.Sprintf("%064x", len(data)))
+		_, err := store.Put(ctx, storage.ModePutUpload, swarm.NewChunk(addr, data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err = joiner.Join(ctx, addr, false); err == nil {
+			t.Fatalf("expected error for chunk data %x", data)
+		}
+	}
+}
+
 // TestJoinerWithReference verifies that a chunk reference is correctly resolved
 // and the underlying data is returned.
 func TestJoinerWithReference(t *testing.T) {
@@ -130,18 +191,18 @@ func TestEncryptionAndDecryption(t *testing.T) {
 		{4096},
 		{4097},
 		{15000},
+		{swarm.ChunkSize * 64},
+		{swarm.ChunkSize*64 + 1},
+		{swarm.ChunkSize*130 + 100},
 	}
 
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("Encrypt %d bytes", tt.chunkLength), func(t *testing.T) {
-			store := mock.NewStorer()
+			// encrypted chunks must be addressed by their content
+			store := mock.NewValidatingStorer(validator.NewContentAddressValidator(), nil)
 			joinner := joiner.NewSimpleJoiner(store)
 
-			g := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255)
-			testData, err := g.SequentialBytes(tt.chunkLength)
-			if err != nil {
-				t.Fatal(err)
-			}
+			testData := filetest.GenerateTestData(t, tt.chunkLength)
 
 			s := splitter.NewSimpleSplitter(store)
 			testDataReader := file.NewSimpleReadCloser(testData)
@@ -159,6 +220,14 @@ func TestEncryptionAndDecryption(t *testing.T) {
 				t.Fatalf("expected join data length %d, got %d", len(testData), l)
 			}
 
+			size, err := joinner.Size(context.Background(), resultAddress)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size != int64(len(testData)) {
+				t.Fatalf("expected size %d, got %d", len(testData), size)
+			}
+
 			totalGot := make([]byte, tt.chunkLength)
 			index := 0
 			resultBuffer := make([]byte, swarm.ChunkSize)
@@ -175,6 +244,16 @@ func TestEncryptionAndDecryption(t *testing.T) {
 			if !bytes.Equal(testData, totalGot) {
 				t.Fatal("input data and output data does not match")
 			}
+
+			// the encrypted reference is detected without the decrypt flag
+			var data bytes.Buffer
+			_, err = file.JoinReadAll(joinner, resultAddress, &data, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(testData, data.Bytes()) {
+				t.Fatal("input data and output data of detected encrypted reference does not match")
+			}
 		})
 	}
 }
@@ -0,0 +1,310 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package joiner
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/encryption"
+	"github.com/ethersphere/bee/pkg/file/joiner/internal"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultPrefetch is the number of data chunks following the read position
+// that are retrieved in advance by the Reader.
+const DefaultPrefetch = 8
+
+var (
+	errWhence         = errors.New("seek: invalid whence")
+	errNegativeOffset = errors.New("seek: negative offset")
+)
+
+// Reader provides random access to the data referenced by a root chunk. Only
+// the chunks that hold the requested byte ranges and the intermediate chunks
+// on their paths are retrieved.
+//
+// Reader implements io.ReadSeeker and io.ReaderAt. Sequential reads retrieve
+// the data chunks following the read position concurrently in advance.
+// ReadAt may be called concurrently, but Read and Seek may not.
+type Reader struct {
+	ctx       context.Context
+	getter    storage.Getter
+	rootData  []byte // data of the root chunk without the span
+	span      int64  // length of the data referenced by the root chunk
+	refLength int    // length of the references in intermediate chunks
+	toDecrypt bool
+	prefetch  int
+	off       int64 // offset of the next Read
+
+	cacheMu sync.Mutex
+	cache   map[int64]*prefetchedChunk // prefetched data chunks by their index
+}
+
+// prefetchedChunk is a data chunk that is retrieved in advance.
+type prefetchedChunk struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// NewReader creates a new Reader of the data referenced by the address and
+// returns it with the length of the data. The data is decrypted if toDecrypt
+// is set or the address is an encrypted reference.
+func NewReader(ctx context.Context, getter storage.Getter, address swarm.Address, toDecrypt bool) (*Reader, int64, error) {
+	toDecrypt = toDecrypt || len(address.Bytes()) == swarm.HashSize+encryption.KeyLength
+
+	j := &simpleJoiner{getter: getter}
+	chunkData, err := j.rootChunkData(ctx, address, toDecrypt)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	span := int64(binary.LittleEndian.Uint64(chunkData[:8]))
+	refLength := swarm.HashSize
+	if toDecrypt {
+		refLength += encryption.KeyLength
+	}
+
+	r := &Reader{
+		ctx:       ctx,
+		getter:    getter,
+		rootData:  chunkData[8:],
+		span:      span,
+		refLength: refLength,
+		toDecrypt: toDecrypt,
+		prefetch:  DefaultPrefetch,
+		cache:     make(map[int64]*prefetchedChunk),
+	}
+	if span <= swarm.ChunkSize && int64(len(r.rootData)) < span {
+		return nil, 0, fmt.Errorf("invalid chunk content of %d bytes for span %d", len(r.rootData), span)
+	}
+	return r, span, nil
+}
+
+// SetPrefetch sets the number of data chunks following the read position
+// that are retrieved in advance. Prefetching is disabled if it is 0.
+func (r *Reader) SetPrefetch(n int) {
+	r.prefetch = n
+}
+
+// Size returns the length of the data.
+func (r *Reader) Size() int64 {
+	return r.span
+}
+
+// Read implements io.Reader.
+func (r *Reader) Read(b []byte) (int, error) {
+	if r.off >= r.span {
+		return 0, io.EOF
+	}
+	n, err := r.ReadAt(b, r.off)
+	r.off += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	if err == nil {
+		r.prefetchFrom(r.off)
+	}
+	return n, err
+}
+
+// Seek implements io.Seeker. The offset may not be negative, but may be
+// beyond the end of the data, where Read returns io.EOF.
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.off
+	case io.SeekEnd:
+		offset += r.span
+	default:
+		return 0, errWhence
+	}
+	if offset < 0 {
+		return 0, errNegativeOffset
+	}
+	r.off = offset
+	return offset, nil
+}
+
+// ReadAt implements io.ReaderAt. The data chunks that hold the byte range
+// are retrieved concurrently.
+func (r *Reader) ReadAt(b []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errNegativeOffset
+	}
+	if off >= r.span {
+		return 0, io.EOF
+	}
+
+	end := off + int64(len(b))
+	if end > r.span {
+		end = r.span
+	}
+	if end == off {
+		return 0, nil
+	}
+
+	g, ctx := errgroup.WithContext(r.ctx)
+	for i := off / swarm.ChunkSize; i*swarm.ChunkSize < end; i++ {
+		i := i
+		g.Go(func() error {
+			data, err := r.dataChunk(ctx, i)
+			if err != nil {
+				return err
+			}
+
+			start := i * swarm.ChunkSize
+			length := r.span - start
+			if length > swarm.ChunkSize {
+				length = swarm.ChunkSize
+			}
+			if int64(len(data)) != length {
+				return fmt.Errorf("data chunk %d has %d bytes, want %d", i, len(data), length)
+			}
+
+			// copy the part of the chunk data within the byte range
+			from, to := int64(0), length
+			if start < off {
+				from = off - start
+			}
+			if start+to > end {
+				to = end - start
+			}
+			copy(b[start+from-off:], data[from:to])
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return 0, err
+	}
+
+	n := int(end - off)
+	if n < len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Close stops the use of the prefetched chunks.
+func (r *Reader) Close() error {
+	r.cacheMu.Lock()
+	r.cache = make(map[int64]*prefetchedChunk)
+	r.cacheMu.Unlock()
+	return nil
+}
+
+// prefetchFrom retrieves the data chunks following the offset in advance and
+// drops the prefetched chunks before it.
+func (r *Reader) prefetchFrom(off int64) {
+	first := off / swarm.ChunkSize
+
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+
+	for i := range r.cache {
+		if i < first || i >= first+int64(r.prefetch) {
+			delete(r.cache, i)
+		}
+	}
+	for i := first; i < first+int64(r.prefetch) && i*swarm.ChunkSize < r.span; i++ {
+		if _, ok := r.cache[i]; ok {
+			continue
+		}
+		c := &prefetchedChunk{done: make(chan struct{})}
+		r.cache[i] = c
+		go func(i int64) {
+			c.data, c.err = r.chunkAt(r.ctx, i*swarm.ChunkSize)
+			close(c.done)
+		}(i)
+	}
+}
+
+// dataChunk returns the data of the data chunk with the index, from the
+// prefetched chunks if it is there.
+func (r *Reader) dataChunk(ctx context.Context, i int64) ([]byte, error) {
+	r.cacheMu.Lock()
+	c, ok := r.cache[i]
+	r.cacheMu.Unlock()
+	if ok {
+		select {
+		case <-c.done:
+			if c.err == nil {
+				return c.data, nil
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return r.chunkAt(ctx, i*swarm.ChunkSize)
+}
+
+// chunkAt walks the chunk tree from the root chunk down to the data chunk
+// that holds the byte at the offset and returns the data of that chunk.
+func (r *Reader) chunkAt(ctx context.Context, off int64) ([]byte, error) {
+	data, span := r.rootData, r.span
+	branches := int64(swarm.ChunkSize / r.refLength)
+
+	for span > swarm.ChunkSize {
+		// every reference but the last covers a subtree of the same size
+		subtreeSize := int64(swarm.ChunkSize)
+		for subtreeSize*branches < span {
+			subtreeSize *= branches
+		}
+
+		i := off / subtreeSize
+		cursor := int(i) * r.refLength
+		if cursor+r.refLength > len(data) {
+			return nil, fmt.Errorf("reference %d out of bounds of intermediate chunk", i)
+		}
+		ref := data[cursor : cursor+r.refLength]
+		off -= i * subtreeSize
+
+		var err error
+		data, span, err = r.chunk(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if int64(len(data)) < span {
+		return nil, fmt.Errorf("invalid chunk content of %d bytes for span %d", len(data), span)
+	}
+	return data[:span], nil
+}
+
+// chunk retrieves the chunk of the reference and returns its data without
+// the span and the span.
+func (r *Reader) chunk(ctx context.Context, ref []byte) ([]byte, int64, error) {
+	addr := swarm.NewAddress(ref[:swarm.HashSize])
+	ch, err := r.getter.Get(ctx, storage.ModeGetRequest, addr)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	chunkData := ch.Data()
+	if r.toDecrypt {
+		chunkData, err = internal.DecryptChunkData(chunkData, ref[swarm.HashSize:])
+		if err != nil {
+			return nil, 0, fmt.Errorf("decrypt chunk %s: %w", addr, err)
+		}
+	}
+	if len(chunkData) < 8 {
+		return nil, 0, fmt.Errorf("invalid chunk content of %d bytes", len(chunkData))
+	}
+	span := binary.LittleEndian.Uint64(chunkData[:8])
+	if span > math.MaxInt64 {
+		return nil, 0, fmt.Errorf("invalid span %d of chunk %s", span, addr)
+	}
+	return chunkData[8:], int64(span), nil
+}
@@ -0,0 +1,214 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package joiner_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	filetest "github.com/ethersphere/bee/pkg/file/testing"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestReader verifies that the reader returns the data of the test vectors
+// when read sequentially, at random offsets and after seeking.
+func TestReader(t *testing.T) {
+	for i := 0; i < filetest.GetVectorCount()-2; i++ {
+		data, _ := filetest.GetVector(t, i)
+		t.Run(fmt.Sprintf("%d bytes", len(data)), func(t *testing.T) {
+			testReader(t, data, false)
+		})
+	}
+}
+
+// TestReaderEncrypted verifies that the reader decrypts the chunks and
+// resolves the encrypted references of intermediate chunks.
+func TestReaderEncrypted(t *testing.T) {
+	for _, size := range []int{
+		10,
+		swarm.ChunkSize,
+		swarm.ChunkSize + 1,
+		swarm.ChunkSize*63 + 5,
+	} {
+		t.Run(fmt.Sprintf("%d bytes", size), func(t *testing.T) {
+			testReader(t, filetest.GenerateTestData(t, size), true)
+		})
+	}
+}
+
+func testReader(t *testing.T, data []byte, toEncrypt bool) {
+	t.Helper()
+
+	ctx := context.Background()
+	store := mock.NewStorer()
+	addr, err := splitter.NewSimpleSplitter(store).Split(ctx, file.NewSimpleReadCloser(data), int64(len(data)), toEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, l, err := joiner.NewReader(ctx, store, addr, toEncrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if l != int64(len(data)) {
+		t.Fatalf("got length %d, want %d", l, len(data))
+	}
+
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("read data does not match")
+	}
+
+	rnd := rand.New(rand.NewSource(int64(len(data))))
+	for i := 0; i < 10; i++ {
+		off := rnd.Int63n(l)
+		buf := make([]byte, rnd.Intn(3*swarm.ChunkSize)+1)
+		n, err := r.ReadAt(buf, off)
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		want := data[off:]
+		if len(want) > len(buf) {
+			want = want[:len(buf)]
+		}
+		if n != len(want) || !bytes.Equal(buf[:n], want) {
+			t.Fatalf("read at %d: data does not match", off)
+		}
+		if n < len(buf) && err != io.EOF {
+			t.Fatalf("read at %d: got error %v, want %v", off, err, io.EOF)
+		}
+	}
+
+	off := l / 2
+	if _, err := r.Seek(-(l - off), io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data[off:]) {
+		t.Fatalf("read from %d: data does not match", off)
+	}
+}
+
+// TestReaderRetrievesRange verifies that only the chunks on the paths to the
+// requested byte range are retrieved.
+func TestReaderRetrievesRange(t *testing.T) {
+	ctx := context.Background()
+	store := mock.NewStorer()
+	data, addr := filetest.GetVector(t, 18) // 130 data chunks in two subtrees
+	_, err := splitter.NewSimpleSplitter(store).Split(ctx, file.NewSimpleReadCloser(data), int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getter := &countingGetter{Getter: store}
+	r, _, err := joiner.NewReader(ctx, getter, addr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetPrefetch(0)
+
+	off, err := r.Seek(swarm.ChunkSize*129+10, io.SeekStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 10)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, data[off:off+10]) {
+		t.Fatal("read data does not match")
+	}
+
+	// root chunk, intermediate chunk of the second subtree and the data chunk
+	if got := getter.count(); got != 3 {
+		t.Fatalf("got %d retrieved chunks, want %d", got, 3)
+	}
+}
+
+func TestReaderSeek(t *testing.T) {
+	ctx := context.Background()
+	store := mock.NewStorer()
+	data := filetest.GenerateTestData(t, 100)
+	addr, err := splitter.NewSimpleSplitter(store).Split(ctx, file.NewSimpleReadCloser(data), int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, _, err := joiner.NewReader(ctx, store, addr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		offset  int64
+		whence  int
+		want    int64
+		wantErr bool
+	}{
+		{offset: 10, whence: io.SeekStart, want: 10},
+		{offset: 5, whence: io.SeekCurrent, want: 15},
+		{offset: -20, whence: io.SeekEnd, want: 80},
+		{offset: 20, whence: io.SeekEnd, want: 120},
+		{offset: -1, whence: io.SeekStart, wantErr: true},
+		{offset: 0, whence: 3, wantErr: true},
+	} {
+		got, err := r.Seek(tc.offset, tc.whence)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("seek %d whence %d: expected error", tc.offset, tc.whence)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Fatalf("seek %d whence %d: got offset %d, want %d", tc.offset, tc.whence, got, tc.want)
+		}
+	}
+
+	// reading beyond the end of data
+	if _, err := r.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("got error %v, want %v", err, io.EOF)
+	}
+}
+
+// countingGetter counts the retrieved chunks.
+type countingGetter struct {
+	storage.Getter
+	mu sync.Mutex
+	n  int
+}
+
+func (g *countingGetter) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	g.mu.Lock()
+	g.n++
+	g.mu.Unlock()
+	return g.Getter.Get(ctx, mode, addr)
+}
+
+func (g *countingGetter) count() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.n
+}
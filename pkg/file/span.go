@@ -5,8 +5,6 @@
 package file
 
 import (
-	"math"
-
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
@@ -21,7 +19,7 @@ func GenerateSpanSizes(levels, branches int) []int64 {
 	spans := make([]int64, levels)
 	branchesSixtyfour := int64(branches)
 	var span int64 = 1
-	for i := 0; i < 9; i++ {
+	for i := 0; i < levels; i++ {
 		spans[i] = span
 		span *= branchesSixtyfour
 	}
@@ -40,5 +38,11 @@ func Levels(length int64, sectionSize, branches int) int {
 	}
 	c := (length - 1) / s
 
-	return int(math.Log(float64(c))/math.Log(float64(b)) + 1)
+	// integer logarithm of the section count, as the floating point one is
+	// not exact on the powers of the branches
+	levels := 1
+	for ; c >= b; c /= b {
+		levels++
+	}
+	return levels
 }
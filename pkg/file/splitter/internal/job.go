@@ -7,6 +7,7 @@ package internal
 import (
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash"
@@ -49,6 +50,8 @@ type SimpleSplitterJob struct {
 	buffer     []byte   // keeps data and hashes, indexed by cursors
 	toEncrypt  bool     // to encryrpt the chunks or not
 	refSize    int64
+	branches   int     // number of references in an intermediate chunk
+	spans      []int64 // maximum span per level in data chunks
 }
 
 // NewSimpleSplitterJob creates a new SimpleSplitterJob.
@@ -60,6 +63,7 @@ func NewSimpleSplitterJob(ctx context.Context, putter storage.Putter, spanLength
 	if toEncrypt {
 		refSize += encryption.KeyLength
 	}
+	branches := int(swarm.ChunkSize / refSize)
 	p := bmtlegacy.NewTreePool(hashFunc, swarm.Branches, bmtlegacy.PoolSize)
 	return &SimpleSplitterJob{
 		ctx:        ctx,
@@ -71,9 +75,86 @@ func NewSimpleSplitterJob(ctx context.Context, putter storage.Putter, spanLength
 		buffer:     make([]byte, file.ChunkWithLengthSize*levelBufferLimit*2), // double size as temp workaround for weak calculation of needed buffer space
 		toEncrypt:  toEncrypt,
 		refSize:    refSize,
+		branches:   branches,
+		spans:      file.GenerateSpanSizes(levelBufferLimit, branches),
 	}
 }
 
+// jobState is the serialized state of the SimpleSplitterJob between the
+// writes of whole chunks, from which the job can be resumed.
+type jobState struct {
+	SpanLength int64  `json:"spanLength"`
+	Length     int64  `json:"length"`
+	ToEncrypt  bool   `json:"toEncrypt"`
+	SumCounts  []int  `json:"sumCounts"`
+	Cursors    []int  `json:"cursors"`
+	Buffer     []byte `json:"buffer"` // references of the unfinished intermediate chunks
+}
+
+// ErrNotResumable is returned by MarshalBinary if the job is not at the
+// boundary of a data chunk.
+var ErrNotResumable = errors.New("splitter job not at chunk boundary")
+
+// NewSimpleSplitterJobFromState creates a SimpleSplitterJob that continues
+// the job whose state was serialized with MarshalBinary.
+func NewSimpleSplitterJobFromState(ctx context.Context, putter storage.Putter, state []byte) (*SimpleSplitterJob, error) {
+	var st jobState
+	if err := json.Unmarshal(state, &st); err != nil {
+		return nil, fmt.Errorf("splitter job state: %w", err)
+	}
+	if st.Length < 0 || st.Length > st.SpanLength || st.Length%swarm.ChunkSize != 0 {
+		return nil, fmt.Errorf("splitter job state: invalid length %d of %d", st.Length, st.SpanLength)
+	}
+	if len(st.SumCounts) != levelBufferLimit || len(st.Cursors) != levelBufferLimit {
+		return nil, errors.New("splitter job state: invalid number of levels")
+	}
+	j := NewSimpleSplitterJob(ctx, putter, st.SpanLength, st.ToEncrypt)
+	if len(st.Buffer) != st.Cursors[0] || len(st.Buffer) > len(j.buffer) {
+		return nil, errors.New("splitter job state: invalid buffer length")
+	}
+	for i := 1; i < levelBufferLimit; i++ {
+		if st.Cursors[i] > st.Cursors[i-1] || st.Cursors[i] < 0 {
+			return nil, errors.New("splitter job state: invalid cursors")
+		}
+	}
+	if st.Cursors[0] != st.Cursors[1] {
+		return nil, errors.New("splitter job state: unfinished data chunk")
+	}
+
+	j.length = st.Length
+	copy(j.sumCounts, st.SumCounts)
+	copy(j.cursors, st.Cursors)
+	copy(j.buffer, st.Buffer)
+	return j, nil
+}
+
+// MarshalBinary serializes the state of the job, so that it can be resumed
+// with NewSimpleSplitterJobFromState. The state can be serialized only
+// between the writes of whole chunks, before the last write.
+func (j *SimpleSplitterJob) MarshalBinary() ([]byte, error) {
+	if j.length%swarm.ChunkSize != 0 || j.length == j.spanLength {
+		return nil, ErrNotResumable
+	}
+	return json.Marshal(jobState{
+		SpanLength: j.spanLength,
+		Length:     j.length,
+		ToEncrypt:  j.toEncrypt,
+		SumCounts:  j.sumCounts,
+		Cursors:    j.cursors,
+		Buffer:     j.buffer[:j.cursors[0]],
+	})
+}
+
+// Length returns the number of bytes written to the job.
+func (j *SimpleSplitterJob) Length() int64 {
+	return j.length
+}
+
+// SpanLength returns the length of the data of the job.
+func (j *SimpleSplitterJob) SpanLength() int64 {
+	return j.spanLength
+}
+
 // Write adds data to the file splitter.
 func (j *SimpleSplitterJob) Write(b []byte) (int, error) {
 	if len(b) > swarm.ChunkSize {
@@ -134,36 +215,17 @@ func (s *SimpleSplitterJob) writeToLevel(lvl int, data []byte) error {
 // TODO: error handling on store write fail
 func (s *SimpleSplitterJob) sumLevel(lvl int) ([]byte, error) {
 	s.sumCounts[lvl]++
-	spanSize := file.Spans[lvl] * swarm.ChunkSize
+	spanSize := s.spans[lvl] * swarm.ChunkSize
 	span := (s.length-1)%spanSize + 1
-	sizeToSum := s.cursors[lvl] - s.cursors[lvl+1]
-
-	//perform hashing
-	s.hasher.Reset()
-	err := s.hasher.SetSpan(span)
-	if err != nil {
-		return nil, err
-	}
-
-	var ref encryption.Key
-	var chunkData []byte
-	data := s.buffer[s.cursors[lvl+1] : s.cursors[lvl+1]+sizeToSum]
 
-	_, err = s.hasher.Write(data)
-	if err != nil {
-		return nil, err
-	}
-	ref = s.hasher.Sum(nil)
 	head := make([]byte, 8)
 	binary.LittleEndian.PutUint64(head, uint64(span))
 	tail := s.buffer[s.cursors[lvl+1]:s.cursors[lvl]]
-	chunkData = append(head, tail...)
-
-	// assemble chunk and put in store
-	addr := swarm.NewAddress(ref)
+	chunkData := append(head, tail...)
 
 	c := chunkData
 	var encryptionKey encryption.Key
+	var err error
 	if s.toEncrypt {
 		c, encryptionKey, err = s.encryptChunkData(chunkData)
 		if err != nil {
@@ -171,7 +233,15 @@ func (s *SimpleSplitterJob) sumLevel(lvl int) ([]byte, error) {
 		}
 	}
 
-	ch := swarm.NewChunk(addr, c)
+	// the chunk is addressed by the hash of its content as it is stored,
+	// so that encrypted chunks are content addressed as well
+	ref, err := chunkAddress(s.hasher, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// assemble chunk and put in store
+	ch := swarm.NewChunk(swarm.NewAddress(ref), c)
 	_, err = s.putter.Put(s.ctx, storage.ModePutUpload, ch)
 	if err != nil {
 		return nil, err
@@ -197,8 +267,10 @@ func (s *SimpleSplitterJob) digest() []byte {
 
 // hashUnfinished hasher the remaining unhashed chunks at the end of each level if
 // write doesn't end on a chunk boundary.
+//
+// Empty data is hashed to a single chunk with zero span.
 func (s *SimpleSplitterJob) hashUnfinished() error {
-	if s.length%swarm.ChunkSize != 0 {
+	if s.length%swarm.ChunkSize != 0 || s.length == 0 {
 		ref, err := s.sumLevel(0)
 		if err != nil {
 			return err
@@ -231,7 +303,7 @@ func (s *SimpleSplitterJob) hashUnfinished() error {
 // After which the SS will be hashed to obtain the final root hash
 func (s *SimpleSplitterJob) moveDanglingChunk() error {
 	// calculate the total number of levels needed to represent the data (including the data level)
-	targetLevel := file.Levels(s.length, swarm.SectionSize, swarm.Branches)
+	targetLevel := file.Levels(s.length, swarm.ChunkSize/s.branches, s.branches)
 
 	// sum every intermediate level and write to the level above it
 	for i := 1; i < targetLevel; i++ {
@@ -240,7 +312,7 @@ func (s *SimpleSplitterJob) moveDanglingChunk() error {
 		// don't hash it again but pass it on to the next level
 		if s.sumCounts[i] > 0 {
 			// TODO: simplify if possible
-			if int64(s.sumCounts[i-1])-file.Spans[targetLevel-1-i] <= 1 {
+			if int64(s.sumCounts[i-1])-s.spans[targetLevel-1-i] <= 1 {
 				s.cursors[i+1] = s.cursors[i]
 				s.cursors[i] = s.cursors[i-1]
 				continue
@@ -258,12 +330,33 @@ func (s *SimpleSplitterJob) moveDanglingChunk() error {
 	return nil
 }
 
+// chunkAddress returns the bmt hash of the chunk data, which is the span
+// followed by the payload.
+func chunkAddress(hasher bmt.Hash, chunkData []byte) ([]byte, error) {
+	hasher.Reset()
+	err := hasher.SetSpan(int64(binary.LittleEndian.Uint64(chunkData[:8])))
+	if err != nil {
+		return nil, err
+	}
+	_, err = hasher.Write(chunkData[8:])
+	if err != nil {
+		return nil, err
+	}
+	return hasher.Sum(nil), nil
+}
+
 func (s *SimpleSplitterJob) encryptChunkData(chunkData []byte) ([]byte, encryption.Key, error) {
+	return encryptChunkData(chunkData, s.refSize)
+}
+
+// encryptChunkData encrypts the span and the data of the chunk with a new
+// random key, with the span encryption of trees with references of refSize.
+func encryptChunkData(chunkData []byte, refSize int64) ([]byte, encryption.Key, error) {
 	if len(chunkData) < 8 {
 		return nil, nil, fmt.Errorf("invalid data, min length 8 got %v", len(chunkData))
 	}
 
-	key, encryptedSpan, encryptedData, err := s.encrypt(chunkData)
+	key, encryptedSpan, encryptedData, err := encrypt(chunkData, refSize)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -273,23 +366,23 @@ func (s *SimpleSplitterJob) encryptChunkData(chunkData []byte) ([]byte, encrypti
 	return c, key, nil
 }
 
-func (s *SimpleSplitterJob) encrypt(chunkData []byte) (encryption.Key, []byte, []byte, error) {
+func encrypt(chunkData []byte, refSize int64) (encryption.Key, []byte, []byte, error) {
 	key := encryption.GenerateRandomKey(encryption.KeyLength)
-	encryptedSpan, err := s.newSpanEncryption(key).Encrypt(chunkData[:8])
+	encryptedSpan, err := newSpanEncryption(key, refSize).Encrypt(chunkData[:8])
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	encryptedData, err := s.newDataEncryption(key).Encrypt(chunkData[8:])
+	encryptedData, err := newDataEncryption(key).Encrypt(chunkData[8:])
 	if err != nil {
 		return nil, nil, nil, err
 	}
 	return key, encryptedSpan, encryptedData, nil
 }
 
-func (s *SimpleSplitterJob) newSpanEncryption(key encryption.Key) *encryption.Encryption {
-	return encryption.New(key, 0, uint32(swarm.ChunkSize/s.refSize), sha3.NewLegacyKeccak256)
+func newSpanEncryption(key encryption.Key, refSize int64) *encryption.Encryption {
+	return encryption.New(key, 0, uint32(swarm.ChunkSize/refSize), sha3.NewLegacyKeccak256)
 }
 
-func (s *SimpleSplitterJob) newDataEncryption(key encryption.Key) *encryption.Encryption {
+func newDataEncryption(key encryption.Key) *encryption.Encryption {
 	return encryption.New(key, int(swarm.ChunkSize), 0, sha3.NewLegacyKeccak256)
 }
@@ -0,0 +1,283 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/encryption"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	bmtlegacy "github.com/ethersphere/bmt/legacy"
+)
+
+// PipelineSplitterJob encapsulates a single splitter operation that hashes and
+// stores the data chunks concurrently, accepting blockwise writes of data
+// whose length is defined in advance.
+//
+// Up to the number of workers data chunks are hashed and stored at the same
+// time, while the writes of further data block. The references of the data
+// chunks are added to the hash tree in the order of the data, so the result
+// does not depend on the order in which the workers finish.
+//
+// The intermediate chunks are hashed and stored by the writer, as there is
+// only one for every branches data chunks.
+type PipelineSplitterJob struct {
+	ctx        context.Context
+	putter     storage.Putter
+	spanLength int64 // target length of data
+	length     int64 // number of bytes written
+	toEncrypt  bool
+	refSize    int64
+	branches   int
+	pool       *bmtlegacy.TreePool
+	hasher     *bmtlegacy.Hasher // hasher of the intermediate chunks
+	buffer     []byte            // data of the data chunk that is written
+	sem        chan struct{}     // limits the number of concurrently processed data chunks
+	pending    []*pendingChunk   // data chunks in process in the order of data
+	levels     [][]pipelineRef   // references not yet in an intermediate chunk, indexed per level
+	err        error             // first error of the processing of a data chunk
+}
+
+// pipelineRef is a reference to a chunk with the length of the data that the
+// chunk represents.
+type pipelineRef struct {
+	ref  []byte
+	span int64
+}
+
+// pendingChunk is a data chunk that is hashed and stored by a worker.
+type pendingChunk struct {
+	done chan struct{}
+	ref  pipelineRef
+	err  error
+}
+
+// NewPipelineSplitterJob creates a new PipelineSplitterJob that processes
+// up to workers data chunks concurrently.
+//
+// The spanLength is the length of the data that will be written.
+func NewPipelineSplitterJob(ctx context.Context, putter storage.Putter, spanLength int64, toEncrypt bool, workers int) *PipelineSplitterJob {
+	if workers < 1 {
+		workers = 1
+	}
+	refSize := int64(swarm.HashSize)
+	if toEncrypt {
+		refSize += encryption.KeyLength
+	}
+	p := bmtlegacy.NewTreePool(hashFunc, swarm.Branches, workers+1)
+	return &PipelineSplitterJob{
+		ctx:        ctx,
+		putter:     putter,
+		spanLength: spanLength,
+		toEncrypt:  toEncrypt,
+		refSize:    refSize,
+		branches:   int(swarm.ChunkSize / refSize),
+		pool:       p,
+		hasher:     bmtlegacy.New(p),
+		buffer:     make([]byte, 0, swarm.ChunkSize),
+		sem:        make(chan struct{}, workers),
+	}
+}
+
+// Write adds data to the file splitter.
+func (j *PipelineSplitterJob) Write(b []byte) (int, error) {
+	if len(b) > swarm.ChunkSize {
+		return 0, fmt.Errorf("Write must be called with a maximum of %d bytes", swarm.ChunkSize)
+	}
+	if j.length+int64(len(b)) > j.spanLength {
+		return 0, errors.New("write past span length")
+	}
+	if err := j.collect(false); err != nil {
+		return 0, err
+	}
+
+	for written := 0; written < len(b); {
+		n := copy(j.buffer[len(j.buffer):cap(j.buffer)], b[written:])
+		j.buffer = j.buffer[:len(j.buffer)+n]
+		written += n
+		j.length += int64(n)
+		if len(j.buffer) == swarm.ChunkSize {
+			if err := j.process(); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// the last data chunk is not full, unless the data is empty
+	if j.length == j.spanLength && (len(j.buffer) > 0 || j.length == 0) {
+		if err := j.process(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Sum waits for all data chunks to be processed and returns the Swarm hash
+// of the data. It must be called after all the data has been written.
+func (j *PipelineSplitterJob) Sum() ([]byte, error) {
+	if j.length != j.spanLength {
+		return nil, fmt.Errorf("sum after %d of %d bytes", j.length, j.spanLength)
+	}
+	if err := j.collect(true); err != nil {
+		return nil, err
+	}
+	root, err := j.finish()
+	if err != nil {
+		return nil, err
+	}
+	return root.ref, nil
+}
+
+// process starts the hashing and storing of the data chunk in the buffer by
+// a worker. It blocks while all workers are busy.
+func (j *PipelineSplitterJob) process() error {
+	select {
+	case j.sem <- struct{}{}:
+	case <-j.ctx.Done():
+		return j.ctx.Err()
+	}
+
+	data := make([]byte, len(j.buffer))
+	copy(data, j.buffer)
+	j.buffer = j.buffer[:0]
+
+	c := &pendingChunk{done: make(chan struct{})}
+	j.pending = append(j.pending, c)
+	go func() {
+		defer func() { <-j.sem }()
+		c.ref, c.err = j.sumChunk(bmtlegacy.New(j.pool), int64(len(data)), data)
+		close(c.done)
+	}()
+	return nil
+}
+
+// collect adds the references of the processed data chunks to the hash tree
+// in the order of the data. It waits for all data chunks to be processed if
+// wait is set.
+func (j *PipelineSplitterJob) collect(wait bool) error {
+	for len(j.pending) > 0 {
+		c := j.pending[0]
+		if wait {
+			<-c.done
+		} else {
+			select {
+			case <-c.done:
+			default:
+				return j.err
+			}
+		}
+		j.pending = j.pending[1:]
+		if c.err != nil && j.err == nil {
+			j.err = c.err
+		}
+		if j.err != nil {
+			continue
+		}
+		if err := j.addRef(0, c.ref); err != nil {
+			j.err = err
+		}
+	}
+	return j.err
+}
+
+// addRef adds the reference to the level and sums the level to an
+// intermediate chunk when it has a full chunk of references.
+func (j *PipelineSplitterJob) addRef(level int, ref pipelineRef) error {
+	for len(j.levels) <= level {
+		j.levels = append(j.levels, nil)
+	}
+	j.levels[level] = append(j.levels[level], ref)
+	if len(j.levels[level]) < j.branches {
+		return nil
+	}
+
+	parent, err := j.sumRefs(j.levels[level])
+	if err != nil {
+		return err
+	}
+	j.levels[level] = j.levels[level][:0]
+	return j.addRef(level+1, parent)
+}
+
+// finish sums the remaining references on every level and returns the root
+// reference. A single remaining reference on a level is not wrapped in an
+// intermediate chunk of its own, but passed on to the level above it.
+func (j *PipelineSplitterJob) finish() (pipelineRef, error) {
+	for level := 0; level < len(j.levels); level++ {
+		refs := j.levels[level]
+		if len(refs) == 0 {
+			continue
+		}
+
+		var above bool
+		for _, l := range j.levels[level+1:] {
+			if len(l) > 0 {
+				above = true
+				break
+			}
+		}
+		if len(refs) == 1 && !above {
+			return refs[0], nil
+		}
+
+		ref := refs[0]
+		if len(refs) > 1 {
+			var err error
+			ref, err = j.sumRefs(refs)
+			if err != nil {
+				return pipelineRef{}, err
+			}
+		}
+		j.levels[level] = nil
+		if level+1 == len(j.levels) {
+			j.levels = append(j.levels, nil)
+		}
+		j.levels[level+1] = append(j.levels[level+1], ref)
+	}
+	return pipelineRef{}, errors.New("no data chunks")
+}
+
+// sumRefs hashes and stores the intermediate chunk of the references.
+func (j *PipelineSplitterJob) sumRefs(refs []pipelineRef) (pipelineRef, error) {
+	var span int64
+	data := make([]byte, 0, len(refs)*int(j.refSize))
+	for _, r := range refs {
+		span += r.span
+		data = append(data, r.ref...)
+	}
+	return j.sumChunk(j.hasher, span, data)
+}
+
+// sumChunk assembles the chunk of the data with the span, encrypts it if the
+// content is to be encrypted, stores it and returns its reference.
+func (j *PipelineSplitterJob) sumChunk(hasher *bmtlegacy.Hasher, span int64, data []byte) (pipelineRef, error) {
+	chunkData := make([]byte, 8+len(data))
+	binary.LittleEndian.PutUint64(chunkData, uint64(span))
+	copy(chunkData[8:], data)
+
+	var key encryption.Key
+	if j.toEncrypt {
+		var err error
+		chunkData, key, err = encryptChunkData(chunkData, j.refSize)
+		if err != nil {
+			return pipelineRef{}, err
+		}
+	}
+
+	ref, err := chunkAddress(hasher, chunkData)
+	if err != nil {
+		return pipelineRef{}, err
+	}
+	addr := swarm.NewAddress(ref)
+
+	if _, err := j.putter.Put(j.ctx, storage.ModePutUpload, swarm.NewChunk(addr, chunkData)); err != nil {
+		return pipelineRef{}, err
+	}
+	return pipelineRef{ref: append(addr.Bytes(), key...), span: span}, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package splitter
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/splitter/internal"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// pipelineSplitter wraps an implementation of file.Splitter that hashes and
+// stores the data chunks concurrently.
+type pipelineSplitter struct {
+	putter  storage.Putter
+	workers int
+}
+
+// NewPipelineSplitter creates a new splitter that hashes and stores up to
+// workers data chunks concurrently. It returns the Swarm hashes of the test
+// vectors, as the SimpleSplitter does.
+func NewPipelineSplitter(putter storage.Putter, workers int) file.Splitter {
+	return &pipelineSplitter{
+		putter:  putter,
+		workers: workers,
+	}
+}
+
+// Split implements the file.Splitter interface
+//
+// It returns the Swarmhash of the data.
+func (s *pipelineSplitter) Split(ctx context.Context, r io.ReadCloser, dataLength int64, toEncrypt bool) (addr swarm.Address, err error) {
+	j := internal.NewPipelineSplitterJob(ctx, s.putter, dataLength, toEncrypt, s.workers)
+	var total int64
+	data := make([]byte, swarm.ChunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return swarm.ZeroAddress, err
+		}
+		c, err := r.Read(data)
+		total += int64(c)
+		if c > 0 {
+			cc, err := j.Write(data[:c])
+			if err != nil {
+				return swarm.ZeroAddress, err
+			}
+			if cc < c {
+				return swarm.ZeroAddress, fmt.Errorf("write count to file hasher component %d does not match read count %d", cc, c)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+	}
+	if total < dataLength {
+		return swarm.ZeroAddress, fmt.Errorf("splitter only received %d bytes of data, expected %d bytes", total, dataLength)
+	}
+
+	// write the empty data explicitly, as the loop above does not write it
+	if dataLength == 0 {
+		if _, err := j.Write(nil); err != nil {
+			return swarm.ZeroAddress, err
+		}
+	}
+
+	sum, err := j.Sum()
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return swarm.NewAddress(sum), nil
+}
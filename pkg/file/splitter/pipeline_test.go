@@ -0,0 +1,122 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package splitter_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	filetest "github.com/ethersphere/bee/pkg/file/testing"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestPipelineSplitterVectors verifies that the pipeline splitter returns
+// the expected addresses of the test vectors.
+func TestPipelineSplitterVectors(t *testing.T) {
+	for i := 0; i < filetest.GetVectorCount(); i++ {
+		data, expectAddr := filetest.GetVector(t, i)
+		t.Run(fmt.Sprintf("%d bytes", len(data)), func(t *testing.T) {
+			store := mock.NewStorer()
+			s := splitter.NewPipelineSplitter(store, 4)
+
+			addr, err := s.Split(context.Background(), file.NewSimpleReadCloser(data), int64(len(data)), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !addr.Equal(expectAddr) {
+				t.Fatalf("expected %s, got %s", expectAddr, addr)
+			}
+			if _, err := store.Get(context.Background(), storage.ModeGetRequest, addr); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// TestPipelineSplitterEncrypted verifies that the encrypted content split
+// by the pipeline splitter is joined back.
+func TestPipelineSplitterEncrypted(t *testing.T) {
+	for _, size := range []int{
+		0,
+		10,
+		swarm.ChunkSize,
+		swarm.ChunkSize*64 + 1,
+		swarm.ChunkSize*130 + 100,
+	} {
+		t.Run(fmt.Sprintf("%d bytes", size), func(t *testing.T) {
+			// random data, as the equal data chunks of the sequential test data
+			// would be stored under the same address with different keys
+			data := make([]byte, size)
+			rand.New(rand.NewSource(int64(size))).Read(data)
+			store := mock.NewStorer()
+			s := splitter.NewPipelineSplitter(store, 4)
+
+			addr, err := s.Split(context.Background(), file.NewSimpleReadCloser(data), int64(len(data)), true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(addr.Bytes()) != swarm.HashSize+swarm.HashSize {
+				t.Fatalf("expected encrypted reference, got %s", addr)
+			}
+
+			r, l, err := joiner.NewReader(context.Background(), store, addr, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l != int64(size) {
+				t.Fatalf("expected length %d, got %d", size, l)
+			}
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatal("joined data does not match")
+			}
+		})
+	}
+}
+
+// TestPipelineSplitterPutError verifies that the error of storing a chunk
+// is returned.
+func TestPipelineSplitterPutError(t *testing.T) {
+	errPut := errors.New("put error")
+	data := filetest.GenerateTestData(t, swarm.ChunkSize*10)
+	s := splitter.NewPipelineSplitter(failingPutter{err: errPut}, 4)
+
+	_, err := s.Split(context.Background(), file.NewSimpleReadCloser(data), int64(len(data)), false)
+	if !errors.Is(err, errPut) {
+		t.Fatalf("got error %v, want %v", err, errPut)
+	}
+}
+
+// TestPipelineSplitterIncomplete verifies that an error is returned if less
+// data than the data length is read.
+func TestPipelineSplitterIncomplete(t *testing.T) {
+	s := splitter.NewPipelineSplitter(mock.NewStorer(), 4)
+
+	_, err := s.Split(context.Background(), file.NewSimpleReadCloser(make([]byte, 42)), 43, false)
+	if err == nil {
+		t.Fatal("expected error on EOF before full length write")
+	}
+}
+
+type failingPutter struct {
+	err error
+}
+
+func (p failingPutter) Put(_ context.Context, _ storage.ModePut, _ ...swarm.Chunk) ([]bool, error) {
+	return nil, p.err
+}
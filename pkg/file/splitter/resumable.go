@@ -0,0 +1,67 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package splitter
+
+import (
+	"context"
+
+	"github.com/ethersphere/bee/pkg/file/splitter/internal"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ErrNotResumable is returned by the State method of the ResumableJob if
+// the written data does not end at a chunk boundary or is complete.
+var ErrNotResumable = internal.ErrNotResumable
+
+// ResumableJob splits the data of known length that is written to it, and
+// records its state between the writes of whole chunks, so that the
+// splitting of data that is received in parts can be resumed from it, even
+// by another process.
+type ResumableJob struct {
+	j *internal.SimpleSplitterJob
+}
+
+// NewResumableJob creates a new ResumableJob of the data with the length.
+func NewResumableJob(ctx context.Context, putter storage.Putter, dataLength int64, toEncrypt bool) *ResumableJob {
+	return &ResumableJob{
+		j: internal.NewSimpleSplitterJob(ctx, putter, dataLength, toEncrypt),
+	}
+}
+
+// ResumeJob creates the ResumableJob that continues the job with the state.
+func ResumeJob(ctx context.Context, putter storage.Putter, state []byte) (*ResumableJob, error) {
+	j, err := internal.NewSimpleSplitterJobFromState(ctx, putter, state)
+	if err != nil {
+		return nil, err
+	}
+	return &ResumableJob{j: j}, nil
+}
+
+// Write writes up to swarm.ChunkSize bytes of the data. Only the last write
+// may be shorter, for the state to be recorded after the writes.
+func (r *ResumableJob) Write(b []byte) (int, error) {
+	return r.j.Write(b)
+}
+
+// Written returns the number of bytes of the data that are written.
+func (r *ResumableJob) Written() int64 {
+	return r.j.Length()
+}
+
+// DataLength returns the length of the data.
+func (r *ResumableJob) DataLength() int64 {
+	return r.j.SpanLength()
+}
+
+// State returns the serialized state of the job.
+func (r *ResumableJob) State() ([]byte, error) {
+	return r.j.MarshalBinary()
+}
+
+// Sum returns the Swarm hash of the data after all of it is written.
+func (r *ResumableJob) Sum() swarm.Address {
+	return swarm.NewAddress(r.j.Sum(nil))
+}
@@ -0,0 +1,135 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package splitter_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	filetest "github.com/ethersphere/bee/pkg/file/testing"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestResumableJob validates that the data that is split by jobs resumed
+// from the recorded states has the same hash as the data split at once.
+func TestResumableJob(t *testing.T) {
+	for _, tc := range []struct {
+		length int
+		stops  []int // numbers of chunks after which the job is resumed
+	}{
+		{length: swarm.ChunkSize*3 + 100, stops: []int{0, 1, 2}},
+		{length: swarm.ChunkSize * 4, stops: []int{2}},
+		{length: swarm.ChunkSize*130 + 1, stops: []int{1, 127, 128, 129, 130}},
+	} {
+		t.Run(fmt.Sprintf("%d", tc.length), func(t *testing.T) {
+			data := filetest.GenerateTestData(t, tc.length)
+			want, err := file.SplitWriteAll(context.Background(), splitter.NewSimpleSplitter(mock.NewStorer()), bytes.NewReader(data), int64(len(data)), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			store := mock.NewStorer()
+			ctx := context.Background()
+			j := splitter.NewResumableJob(ctx, store, int64(len(data)), false)
+			var written int
+			for _, stop := range tc.stops {
+				for ; written < stop*swarm.ChunkSize; written += swarm.ChunkSize {
+					if _, err := j.Write(data[written : written+swarm.ChunkSize]); err != nil {
+						t.Fatal(err)
+					}
+				}
+				state, err := j.State()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if j, err = splitter.ResumeJob(ctx, store, state); err != nil {
+					t.Fatal(err)
+				}
+				if j.Written() != int64(written) {
+					t.Fatalf("got %d written bytes, want %d", j.Written(), written)
+				}
+			}
+			for written < len(data) {
+				end := written + swarm.ChunkSize
+				if end > len(data) {
+					end = len(data)
+				}
+				if _, err := j.Write(data[written:end]); err != nil {
+					t.Fatal(err)
+				}
+				written = end
+			}
+
+			if got := j.Sum(); !got.Equal(want) {
+				t.Fatalf("got hash %s, want %s", got, want)
+			}
+			if _, err := j.State(); !errors.Is(err, splitter.ErrNotResumable) {
+				t.Fatalf("got error %v, want %v", err, splitter.ErrNotResumable)
+			}
+		})
+	}
+}
+
+func TestResumableJobEncrypted(t *testing.T) {
+	data := filetest.GenerateTestData(t, swarm.ChunkSize*70+10)
+	store := mock.NewStorer()
+	ctx := context.Background()
+
+	j := splitter.NewResumableJob(ctx, store, int64(len(data)), true)
+	for i := 0; i < 65; i++ {
+		if _, err := j.Write(data[i*swarm.ChunkSize : (i+1)*swarm.ChunkSize]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state, err := j.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j, err = splitter.ResumeJob(ctx, store, state); err != nil {
+		t.Fatal(err)
+	}
+	for i := 65; i*swarm.ChunkSize < len(data); i++ {
+		end := (i + 1) * swarm.ChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if _, err := j.Write(data[i*swarm.ChunkSize : end]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the hashes of encrypted data differ, so the data is joined instead
+	buf := bytes.NewBuffer(nil)
+	if _, err := file.JoinReadAll(joiner.NewSimpleJoiner(store), j.Sum(), buf, true); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatal("joined data differs from the split data")
+	}
+}
+
+func TestResumeJobInvalidState(t *testing.T) {
+	store := mock.NewStorer()
+	j := splitter.NewResumableJob(context.Background(), store, swarm.ChunkSize*2, false)
+	if _, err := j.Write(make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.State(); !errors.Is(err, splitter.ErrNotResumable) {
+		t.Fatalf("got error %v, want %v", err, splitter.ErrNotResumable)
+	}
+
+	for _, state := range []string{"", "{}", `{"spanLength":10,"length":4096}`} {
+		if _, err := splitter.ResumeJob(context.Background(), store, []byte(state)); err == nil {
+			t.Fatalf("got no error of state %q", state)
+		}
+	}
+}
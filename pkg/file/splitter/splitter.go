@@ -40,6 +40,9 @@ func (s *simpleSplitter) Split(ctx context.Context, r io.ReadCloser, dataLength
 	data := make([]byte, swarm.ChunkSize)
 	var eof bool
 	for !eof {
+		if err := ctx.Err(); err != nil {
+			return swarm.ZeroAddress, err
+		}
 		c, err := r.Read(data)
 		total += int64(c)
 		if err != nil {
@@ -62,6 +65,13 @@ func (s *simpleSplitter) Split(ctx context.Context, r io.ReadCloser, dataLength
 		}
 	}
 
+	// write the empty data explicitly, as the loop above does not write it
+	if dataLength == 0 {
+		if _, err := j.Write(nil); err != nil {
+			return swarm.ZeroAddress, err
+		}
+	}
+
 	sum := j.Sum(nil)
 	return swarm.NewAddress(sum), nil
 }
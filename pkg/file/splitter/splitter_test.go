@@ -10,8 +10,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/encryption"
 	"github.com/ethersphere/bee/pkg/file"
 	"github.com/ethersphere/bee/pkg/file/splitter"
+	filetest "github.com/ethersphere/bee/pkg/file/testing"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -62,6 +64,40 @@ func TestSplitSingleChunk(t *testing.T) {
 	}
 }
 
+// TestSplitZeroLength verifies that empty data is hashed to the canonical
+// empty file reference of a single chunk with zero span.
+func TestSplitZeroLength(t *testing.T) {
+	store := mock.NewStorer()
+	s := splitter.NewSimpleSplitter(store)
+
+	resultAddress, err := s.Split(context.Background(), file.NewSimpleReadCloser(nil), 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testHashHex := "ffd70157e48063fc33c97a050f7f640233bf646cc98d9524c6b92bcf3ab56f83"
+	testHashAddress := swarm.MustParseHexAddress(testHashHex)
+	if !testHashAddress.Equal(resultAddress) {
+		t.Fatalf("expected %v, got %v", testHashAddress, resultAddress)
+	}
+
+	ch, err := store.Get(context.Background(), storage.ModeGetRequest, resultAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ch.Data(), make([]byte, 8)) {
+		t.Fatalf("expected zero span chunk data, got %x", ch.Data())
+	}
+
+	encryptedAddress, err := s.Split(context.Background(), file.NewSimpleReadCloser(nil), 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encryptedAddress.Bytes()) != swarm.HashSize+encryption.KeyLength {
+		t.Fatalf("expected encrypted reference, got %v", encryptedAddress)
+	}
+}
+
 // TestSplitThreeLevels hashes enough data chunks in order to
 // create a full chunk of intermediate hashes.
 // It verifies that all created chunks exist in the store afterwards.
@@ -130,6 +166,15 @@ func TestUnalignedSplit(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// the test data generator returns the same vector
+	vector, vectorAddr := filetest.GetVector(t, 12)
+	if !bytes.Equal(vector, content) {
+		t.Fatal("generated test vector data mismatch")
+	}
+	if vectorAddr.String() != expectAddrHex {
+		t.Fatalf("test vector addr mismatch, expected %s, got %s", expectAddrHex, vectorAddr)
+	}
+
 	// perform the split in a separate thread
 	sp := splitter.NewSimpleSplitter(storer)
 	ctx := context.Background()
@@ -65,15 +65,23 @@ var (
 // and the expected result address.
 func GetVector(t *testing.T, idx int) ([]byte, swarm.Address) {
 	t.Helper()
-	if idx > fileLengths[idx] {
+	if idx < 0 || idx >= GetVectorCount() {
 		t.Fatalf("idx %d out of bound for count %d", idx, GetVectorCount())
 	}
+	return GenerateTestData(t, fileLengths[idx]), swarm.MustParseHexAddress(fileExpectHashHex[idx])
+}
+
+// GenerateTestData returns deterministic data of the given length. The data
+// of the same length is the same as the data of the test vectors, so the
+// expected addresses of the vectors apply to it.
+func GenerateTestData(t *testing.T, length int) []byte {
+	t.Helper()
 	g := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(fileByteMod)
-	data, err := g.SequentialBytes(fileLengths[idx])
+	data, err := g.SequentialBytes(length)
 	if err != nil {
 		t.Fatal(err)
 	}
-	return data, swarm.MustParseHexAddress(fileExpectHashHex[idx])
+	return data
 }
 
 // GetVectorCount returns the number of available test vectors.
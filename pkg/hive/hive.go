@@ -6,6 +6,7 @@ package hive
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -19,7 +20,8 @@ import (
 )
 
 const (
-	protocolName    = "hive"
+	// ProtocolName is the name of the hive protocol.
+	ProtocolName    = "hive"
 	protocolVersion = "1.0.0"
 	peersStreamName = "peers"
 	messageTimeout  = 1 * time.Minute // maximum allowed time for a message to be read or written.
@@ -52,7 +54,7 @@ func New(o Options) *Service {
 
 func (s *Service) Protocol() p2p.ProtocolSpec {
 	return p2p.ProtocolSpec{
-		Name:    protocolName,
+		Name:    ProtocolName,
 		Version: protocolVersion,
 		StreamSpecs: []p2p.StreamSpec{
 			{
@@ -70,6 +72,10 @@ func (s *Service) BroadcastPeers(ctx context.Context, addressee swarm.Address, p
 			max = len(peers)
 		}
 		if err := s.sendPeers(ctx, addressee, peers[:max]); err != nil {
+			if errors.Is(err, p2p.ErrProtocolDisabled) {
+				// the addressee does not run hive and is not sent any peers
+				return nil
+			}
 			return err
 		}
 
@@ -84,7 +90,7 @@ func (s *Service) SetPeerAddedHandler(h func(ctx context.Context, addr swarm.Add
 }
 
 func (s *Service) sendPeers(ctx context.Context, peer swarm.Address, peers []swarm.Address) error {
-	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, peersStreamName)
+	stream, err := s.streamer.NewStream(ctx, peer, nil, ProtocolName, protocolVersion, peersStreamName)
 	if err != nil {
 		return fmt.Errorf("new stream: %w", err)
 	}
@@ -132,16 +138,32 @@ func (s *Service) peersHandler(ctx context.Context, peer p2p.Peer, stream p2p.St
 	// but we still want to handle not closed stream from the other side to avoid zombie stream
 	go stream.FullClose()
 
+	seen := make(map[string]struct{}, len(peersReq.Peers))
 	for _, newPeer := range peersReq.Peers {
 		bzzAddress, err := bzz.ParseAddress(newPeer.Underlay, newPeer.Overlay, newPeer.Signature, s.networkID)
 		if err != nil {
-			s.logger.Warningf("skipping peer in response %s: %w", newPeer, err)
+			s.logger.Warningf("skipping peer in response %s: %v", newPeer, err)
+			continue
+		}
+
+		// the peers that are gossiped more than once, in the same message or
+		// with the same address as already known, are not added again
+		if _, ok := seen[bzzAddress.Overlay.ByteString()]; ok {
+			continue
+		}
+		seen[bzzAddress.Overlay.ByteString()] = struct{}{}
+		known, err := s.addressBook.Get(bzzAddress.Overlay)
+		if err == nil && known.Equal(bzzAddress) {
+			continue
+		}
+		if err != nil && err != addressbook.ErrNotFound {
+			s.logger.Warningf("skipping peer in response %s: %v", newPeer, err)
 			continue
 		}
 
 		err = s.addressBook.Put(bzzAddress.Overlay, *bzzAddress)
 		if err != nil {
-			s.logger.Warningf("skipping peer in response %s: %w", newPeer, err)
+			s.logger.Warningf("skipping peer in response %s: %v", newPeer, err)
 			continue
 		}
 
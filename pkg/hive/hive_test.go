@@ -13,6 +13,7 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/ethersphere/bee/pkg/hive"
 	"github.com/ethersphere/bee/pkg/hive/pb"
 	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/pkg/p2p/streamtest"
 	"github.com/ethersphere/bee/pkg/statestore/mock"
@@ -174,6 +176,94 @@ func TestBroadcastPeers(t *testing.T) {
 	}
 }
 
+func TestPeersHandlerDedup(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	addressbook := ab.New(mock.NewStateStore())
+	networkID := uint64(1)
+
+	var overlays []swarm.Address
+	for i := 0; i < 3; i++ {
+		underlay, err := ma.NewMultiaddr("/ip4/127.0.0.1/udp/" + strconv.Itoa(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pk, err := crypto.GenerateSecp256k1Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		overlay, err := crypto.NewOverlayAddress(pk.PublicKey, networkID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bzzAddr, err := bzz.NewAddress(crypto.NewDefaultSigner(pk), underlay, overlay, networkID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the last peer is gossiped with an invalid signature
+		if i == 2 {
+			bzzAddr.Signature[0]++
+		}
+		if err := addressbook.Put(bzzAddr.Overlay, *bzzAddr); err != nil {
+			t.Fatal(err)
+		}
+		overlays = append(overlays, bzzAddr.Overlay)
+	}
+
+	var (
+		added   []swarm.Address
+		addedMu sync.Mutex
+	)
+	server := hive.New(hive.Options{
+		Logger:      logger,
+		AddressBook: ab.New(mock.NewStateStore()),
+		NetworkID:   networkID,
+	})
+	server.SetPeerAddedHandler(func(_ context.Context, addr swarm.Address) error {
+		addedMu.Lock()
+		defer addedMu.Unlock()
+		added = append(added, addr)
+		return nil
+	})
+	recorder := streamtest.New(
+		streamtest.WithProtocols(server.Protocol()),
+	)
+	client := hive.New(hive.Options{
+		Streamer:    recorder,
+		Logger:      logger,
+		AddressBook: addressbook,
+		NetworkID:   networkID,
+	})
+
+	addressee := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	// the first peer is repeated in the same message and in the next one
+	if err := client.BroadcastPeers(context.Background(), addressee, overlays[0], overlays[0], overlays[1], overlays[2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.BroadcastPeers(context.Background(), addressee, overlays[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	want := overlays[:2]
+	var got []swarm.Address
+	for i := 0; i < 100; i++ {
+		addedMu.Lock()
+		got = append(got[:0], added...)
+		addedMu.Unlock()
+		if len(got) >= len(want) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// wait for the second message to be handled
+	time.Sleep(50 * time.Millisecond)
+	addedMu.Lock()
+	got = append(got[:0], added...)
+	addedMu.Unlock()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got added peers %v, want %v", got, want)
+	}
+}
+
 func expectOverlaysEventually(t *testing.T, exporter ab.Interface, wantOverlays []swarm.Address) {
 	for i := 0; i < 100; i++ {
 		var stringOverlays []string
@@ -259,3 +349,27 @@ func readAndAssertPeersMsgs(in []byte, expectedLen int) ([]pb.Peers, error) {
 
 	return peers, nil
 }
+
+// TestBroadcastPeersProtocolDisabled tests that no error is returned when
+// the addressee announced that it does not run hive.
+func TestBroadcastPeersProtocolDisabled(t *testing.T) {
+	client := hive.New(hive.Options{
+		Streamer:    disabledStreamer{},
+		AddressBook: ab.New(mock.NewStateStore()),
+		NetworkID:   1,
+		Logger:      logging.New(ioutil.Discard, 0),
+	})
+
+	addressee := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	peer := swarm.MustParseHexAddress("8b2c1b1f7f2fa7ed1ea1d8e5f7fcfa0f13ecbd6ba7fc5c9ac1a4f3f3cc0de8d4")
+	if err := client.BroadcastPeers(context.Background(), addressee, peer); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// disabledStreamer is a streamer of the peers that do not run any protocol.
+type disabledStreamer struct{}
+
+func (disabledStreamer) NewStream(context.Context, swarm.Address, p2p.Headers, string, string, string) (p2p.Stream, error) {
+	return nil, p2p.ErrProtocolDisabled
+}
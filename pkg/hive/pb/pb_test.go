@@ -0,0 +1,35 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/hive/pb"
+	"github.com/ethersphere/bee/pkg/p2p/protobuf/protobuftest"
+)
+
+// TestMessages checks that the wire format of the messages is compatible
+// with the released version of the protocol.
+func TestMessages(t *testing.T) {
+	protobuftest.Check(t,
+		protobuftest.Message{
+			Message: &pb.Peers{Peers: []*pb.BzzAddress{{Underlay: []byte{1}, Signature: []byte{2}, Overlay: []byte{3}}}},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "peers", WireType: "bytes", Repeated: true},
+			},
+			Wire: "0a090a01011201021a0103",
+		},
+		protobuftest.Message{
+			Message: &pb.BzzAddress{Underlay: []byte{1}, Signature: []byte{2}, Overlay: []byte{3}},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Underlay", WireType: "bytes"},
+				{Number: 2, Name: "Signature", WireType: "bytes"},
+				{Number: 3, Name: "Overlay", WireType: "bytes"},
+			},
+			Wire: "0a01011201021a0103",
+		},
+	)
+}
@@ -0,0 +1,224 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package identity creates, rotates, backs up and restores the swarm key of a
+// node, and migrates the node data that depends on the overlay address
+// derived from it.
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ethersphere/bee/pkg/addressbook"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/keystore"
+	filekeystore "github.com/ethersphere/bee/pkg/keystore/file"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	swarmKeyName  = "swarm"
+	libp2pKeyName = "libp2p"
+)
+
+// Options are the options of the rotation of the swarm key of the node with
+// the data in the DataDir. The node must not be running.
+type Options struct {
+	DataDir   string
+	Password  string
+	NetworkID uint64
+	Logger    logging.Logger
+}
+
+// Result describes the identity change of the node.
+type Result struct {
+	// OldOverlay is the zero address if the node had no swarm key.
+	OldOverlay swarm.Address
+	NewOverlay swarm.Address
+	// KeyBackupFile is the file in which the replaced swarm key is kept. It
+	// is empty if no key is replaced.
+	KeyBackupFile string
+}
+
+// Init creates the swarm and the libp2p keys of the node, encrypted with the
+// password, if they do not exist, and returns the overlay address derived
+// from the swarm key. Existing keys must be encrypted with the same password.
+// The Result has the zero OldOverlay if the swarm key is created, and the
+// existing overlay address as both addresses otherwise.
+func Init(o Options) (*Result, error) {
+	if o.DataDir == "" {
+		return nil, errors.New("data directory not provided")
+	}
+
+	keyStore := filekeystore.New(filepath.Join(o.DataDir, "keys"))
+	key, created, err := keyStore.Key(swarmKeyName, o.Password)
+	if err != nil {
+		return nil, fmt.Errorf("swarm key: %w", err)
+	}
+	overlay, err := crypto.NewOverlayAddress(key.PublicKey, o.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := keyStore.Key(libp2pKeyName, o.Password); err != nil {
+		return nil, fmt.Errorf("libp2p key: %w", err)
+	}
+
+	result := &Result{
+		OldOverlay: overlay,
+		NewOverlay: overlay,
+	}
+	if created {
+		o.Logger.Infof("identity: swarm key created with overlay %s", overlay)
+		result.OldOverlay = swarm.ZeroAddress
+	}
+	return result, nil
+}
+
+// Rotate replaces the swarm key of the node with a new one and migrates the
+// node data to the new overlay address:
+//   - the old overlay is removed from the address book, as it may have been
+//     learned from the peers and would be dialed as a peer otherwise
+//   - the localstore indexes that depend on the overlay are rebuilt, and all
+//     chunks need to be synced again by the peers that pull from the node
+//
+// The libp2p key, and so the underlay addresses, are not changed.
+func Rotate(o Options) (*Result, error) {
+	if o.DataDir == "" {
+		return nil, errors.New("data directory not provided")
+	}
+
+	// the state store is opened before the key is replaced, to fail while
+	// the node is running, as it locks the store
+	stateStore, err := leveldb.NewStateStore(filepath.Join(o.DataDir, "statestore"))
+	if err != nil {
+		return nil, fmt.Errorf("statestore: %w", err)
+	}
+	defer stateStore.Close()
+
+	oldKey, newKey, backupFile, err := filekeystore.New(filepath.Join(o.DataDir, "keys")).Rotate(swarmKeyName, o.Password)
+	if err != nil {
+		return nil, fmt.Errorf("swarm key: %w", err)
+	}
+	oldOverlay, err := crypto.NewOverlayAddress(oldKey.PublicKey, o.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	newOverlay, err := crypto.NewOverlayAddress(newKey.PublicKey, o.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	o.Logger.Infof("identity: swarm key replaced, overlay %s changed to %s, old key backed up to %s", oldOverlay, newOverlay, backupFile)
+
+	if err := migrate(o, stateStore, oldOverlay, newOverlay); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		OldOverlay:    oldOverlay,
+		NewOverlay:    newOverlay,
+		KeyBackupFile: backupFile,
+	}, nil
+}
+
+// Mnemonic returns the BIP-39 mnemonic of the swarm key of the node, from
+// which the key can be restored with Restore. It returns
+// keystore.ErrNotFound if the node has no swarm key.
+func Mnemonic(o Options) (string, error) {
+	if o.DataDir == "" {
+		return "", errors.New("data directory not provided")
+	}
+
+	keyStore := filekeystore.New(filepath.Join(o.DataDir, "keys"))
+	exists, err := keyStore.Exists(swarmKeyName)
+	if err != nil {
+		return "", fmt.Errorf("swarm key: %w", err)
+	}
+	if !exists {
+		return "", fmt.Errorf("swarm key: %w", keystore.ErrNotFound)
+	}
+	key, _, err := keyStore.Key(swarmKeyName, o.Password)
+	if err != nil {
+		return "", fmt.Errorf("swarm key: %w", err)
+	}
+	return crypto.EncodeMnemonic(key)
+}
+
+// Restore sets the swarm key of the node to the key of the mnemonic returned
+// by Mnemonic, encrypted with the password. An existing swarm key must be
+// encrypted with the same password, and it is backed up in the keys
+// directory. If the overlay address is changed by the restored key, the node
+// data is migrated to it as by Rotate.
+func Restore(o Options, mnemonic string) (*Result, error) {
+	if o.DataDir == "" {
+		return nil, errors.New("data directory not provided")
+	}
+	key, err := crypto.DecodeMnemonic(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	// the state store is opened before the key is replaced, to fail while
+	// the node is running, as it locks the store
+	stateStore, err := leveldb.NewStateStore(filepath.Join(o.DataDir, "statestore"))
+	if err != nil {
+		return nil, fmt.Errorf("statestore: %w", err)
+	}
+	defer stateStore.Close()
+
+	oldKey, backupFile, err := filekeystore.New(filepath.Join(o.DataDir, "keys")).Import(swarmKeyName, o.Password, key)
+	if err != nil {
+		return nil, fmt.Errorf("swarm key: %w", err)
+	}
+
+	result := &Result{
+		KeyBackupFile: backupFile,
+	}
+	result.NewOverlay, err = crypto.NewOverlayAddress(key.PublicKey, o.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	if oldKey == nil {
+		o.Logger.Infof("identity: swarm key restored, overlay %s", result.NewOverlay)
+		return result, nil
+	}
+
+	result.OldOverlay, err = crypto.NewOverlayAddress(oldKey.PublicKey, o.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	o.Logger.Infof("identity: swarm key restored, overlay %s changed to %s, old key backed up to %s", result.OldOverlay, result.NewOverlay, backupFile)
+	if result.OldOverlay.Equal(result.NewOverlay) {
+		return result, nil
+	}
+
+	if err := migrate(o, stateStore, result.OldOverlay, result.NewOverlay); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// migrate migrates the node data from the old overlay address to the new
+// one, as described by Rotate.
+func migrate(o Options, stateStore storage.StateStorer, oldOverlay, newOverlay swarm.Address) error {
+	if err := addressbook.New(stateStore).Remove(oldOverlay); err != nil {
+		return fmt.Errorf("addressbook: %w", err)
+	}
+
+	// the localstore rebuilds its indexes when it is opened with a
+	// different overlay and the rebase option
+	storer, err := localstore.New(filepath.Join(o.DataDir, "localstore"), newOverlay.Bytes(), &localstore.Options{Rebase: true}, o.Logger)
+	if err != nil {
+		return fmt.Errorf("localstore: %w", err)
+	}
+	if err := storer.Close(); err != nil {
+		return fmt.Errorf("localstore: %w", err)
+	}
+	return nil
+}
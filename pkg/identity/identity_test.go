@@ -0,0 +1,270 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package identity_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/addressbook"
+	"github.com/ethersphere/bee/pkg/bzz"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/identity"
+	"github.com/ethersphere/bee/pkg/keystore"
+	filekeystore "github.com/ethersphere/bee/pkg/keystore/file"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/storage"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	password  = "pass123456"
+	networkID = 1
+)
+
+func TestRotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bee-identity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logger := logging.New(ioutil.Discard, 0)
+
+	oldKey, _, err := filekeystore.New(filepath.Join(dir, "keys")).Key("swarm", password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldOverlay, err := crypto.NewOverlayAddress(oldKey.PublicKey, networkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the old overlay learned from a peer
+	stateStore, err := leveldb.NewStateStore(filepath.Join(dir, "statestore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	underlay, err := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/1634")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bzzAddr, err := bzz.NewAddress(crypto.NewDefaultSigner(oldKey), underlay, oldOverlay, networkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := addressbook.New(stateStore).Put(oldOverlay, *bzzAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	storer, err := localstore.New(filepath.Join(dir, "localstore"), oldOverlay.Bytes(), nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := chunktesting.GenerateTestRandomChunk()
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunk); err != nil {
+		t.Fatal(err)
+	}
+	if err := storer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the stores are locked while the node is running
+	o := identity.Options{
+		DataDir:   dir,
+		Password:  password,
+		NetworkID: networkID,
+		Logger:    logger,
+	}
+	if _, err := identity.Rotate(o); err == nil {
+		t.Fatal("got no error while the state store is open")
+	}
+	if err := stateStore.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Password = "invalid password"
+	if _, err := identity.Rotate(o); err == nil {
+		t.Fatal("got no error with an invalid password")
+	}
+	o.Password = password
+
+	result, err := identity.Rotate(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.OldOverlay.Equal(oldOverlay) {
+		t.Fatalf("got old overlay %s, want %s", result.OldOverlay, oldOverlay)
+	}
+
+	newKey, created, err := filekeystore.New(filepath.Join(dir, "keys")).Key("swarm", password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Fatal("key is created, but should be rotated")
+	}
+	newOverlay, err := crypto.NewOverlayAddress(newKey.PublicKey, networkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.NewOverlay.Equal(newOverlay) || newOverlay.Equal(oldOverlay) {
+		t.Fatalf("got new overlay %s, want %s different from %s", result.NewOverlay, newOverlay, oldOverlay)
+	}
+	if _, err := os.Stat(result.KeyBackupFile); err != nil {
+		t.Fatal(err)
+	}
+
+	stateStore, err = leveldb.NewStateStore(filepath.Join(dir, "statestore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stateStore.Close()
+	if _, err := addressbook.New(stateStore).Get(oldOverlay); !errors.Is(err, addressbook.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, addressbook.ErrNotFound)
+	}
+
+	storer, err = localstore.New(filepath.Join(dir, "localstore"), newOverlay.Bytes(), nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storer.Close()
+	got, err := storer.Get(context.Background(), storage.ModeGetRequest, chunk.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data(), chunk.Data()) {
+		t.Fatal("chunk data changed")
+	}
+}
+
+func TestMnemonicRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bee-identity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	o := identity.Options{
+		DataDir:   dir,
+		Password:  password,
+		NetworkID: networkID,
+		Logger:    logging.New(ioutil.Discard, 0),
+	}
+
+	if _, err := identity.Mnemonic(o); !errors.Is(err, keystore.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, keystore.ErrNotFound)
+	}
+
+	key, _, err := filekeystore.New(filepath.Join(dir, "keys")).Key("swarm", password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := crypto.NewOverlayAddress(key.PublicKey, networkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mnemonic, err := identity.Mnemonic(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the identity is restored on a new node
+	newDir, err := ioutil.TempDir("", "bee-identity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(newDir)
+	o.DataDir = newDir
+
+	if _, err := identity.Restore(o, "invalid mnemonic"); !errors.Is(err, crypto.ErrInvalidMnemonic) {
+		t.Fatalf("got error %v, want %v", err, crypto.ErrInvalidMnemonic)
+	}
+	result, err := identity.Restore(o, mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.OldOverlay.Equal(swarm.ZeroAddress) || !result.NewOverlay.Equal(overlay) || result.KeyBackupFile != "" {
+		t.Fatalf("got result %+v, want new overlay %s", result, overlay)
+	}
+	restoredKey, created, err := filekeystore.New(filepath.Join(newDir, "keys")).Key("swarm", password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || !bytes.Equal(restoredKey.D.Bytes(), key.D.Bytes()) {
+		t.Fatal("restored key is not the backed up key")
+	}
+
+	// the identity replaces the one of a node that is already started
+	if _, err := identity.Rotate(o); err != nil {
+		t.Fatal(err)
+	}
+	result, err = identity.Restore(o, mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.OldOverlay.Equal(overlay) || !result.NewOverlay.Equal(overlay) {
+		t.Fatalf("got result %+v, want new overlay %s", result, overlay)
+	}
+	if _, err := os.Stat(result.KeyBackupFile); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bee-identity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	o := identity.Options{
+		DataDir:   dir,
+		Password:  password,
+		NetworkID: networkID,
+		Logger:    logging.New(ioutil.Discard, 0),
+	}
+
+	result, err := identity.Init(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.OldOverlay.IsZero() {
+		t.Errorf("got old overlay %s for the created key", result.OldOverlay)
+	}
+
+	keyStore := filekeystore.New(filepath.Join(dir, "keys"))
+	for _, name := range []string{"swarm", "libp2p"} {
+		exists, err := keyStore.Exists(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exists {
+			t.Errorf("%s key not created", name)
+		}
+	}
+
+	// the existing keys are kept
+	again, err := identity.Init(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.OldOverlay.Equal(result.NewOverlay) || !again.NewOverlay.Equal(result.NewOverlay) {
+		t.Errorf("got overlays %s and %s, want %s", again.OldOverlay, again.NewOverlay, result.NewOverlay)
+	}
+
+	o.Password = "wrong"
+	if _, err := identity.Init(o); err == nil {
+		t.Fatal("got no error with the wrong password")
+	}
+}
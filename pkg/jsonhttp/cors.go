@@ -0,0 +1,92 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonhttp
+
+import (
+	"net/http"
+	"sync"
+)
+
+// CORS sets the CORS headers on the responses to the requests from the
+// allowed origins, and responds to the preflight requests before they reach
+// the handlers that it wraps, with the allowed origins that can be changed
+// while the requests are handled.
+type CORS struct {
+	allowedOrigins []string
+	mu             sync.RWMutex
+}
+
+// NewCORS returns the CORS middleware for the allowed origins. No origin is
+// allowed if the allowed origins are nil or empty, and all origins are
+// allowed only if they contain "*", in which case the credentials are never
+// allowed.
+func NewCORS(allowedOrigins []string) *CORS {
+	return &CORS{
+		allowedOrigins: allowedOrigins,
+	}
+}
+
+// SetAllowedOrigins changes the allowed origins.
+func (c *CORS) SetAllowedOrigins(origins []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.allowedOrigins = origins
+}
+
+// Allowed returns true if the CORS headers are set for the origin.
+func (c *CORS) Allowed(origin string) bool {
+	allowed, _ := c.allowed(origin)
+	return allowed
+}
+
+// allowed reports whether the origin is allowed, and whether it is allowed
+// only by the wildcard.
+func (c *CORS) allowed(origin string) (allowed, wildcard bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, o := range c.allowedOrigins {
+		if o == origin {
+			return true, false
+		}
+		if o == "*" {
+			wildcard = true
+		}
+	}
+	return wildcard, wildcard
+}
+
+// Handler returns the handler that sets the CORS headers for the allowed
+// origins. The credentials are allowed only for the explicitly listed
+// origins, and not for the origins that are allowed by the wildcard. The
+// preflight requests, with the OPTIONS method and the
+// Access-Control-Request-Method header, are responded with status code 204,
+// without the CORS headers if the origin is not allowed, as they do not carry
+// the credentials that the wrapped handlers may require.
+func (c *CORS) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" {
+			if allowed, wildcard := c.allowed(origin); allowed {
+				if wildcard {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+					w.Header().Add("Vary", "Origin")
+				}
+				w.Header().Set("Access-Control-Allow-Headers", "Origin, Accept, Authorization, Content-Type, X-Requested-With, Access-Control-Request-Headers, Access-Control-Request-Method")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS, POST, PUT, DELETE")
+				w.Header().Set("Access-Control-Max-Age", "3600")
+			}
+		}
+		if origin != "" && r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,152 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+func TestCORS(t *testing.T) {
+	cors := jsonhttp.NewCORS([]string{"http://localhost:3000"})
+	h := cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the wrapped handler requires the credentials
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+
+	for _, tc := range []struct {
+		name        string
+		method      string
+		origin      string
+		preflight   bool
+		wantCode    int
+		wantHeaders bool
+	}{
+		{
+			name:     "no origin",
+			method:   http.MethodGet,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:        "allowed origin",
+			method:      http.MethodGet,
+			origin:      "http://localhost:3000",
+			wantCode:    http.StatusUnauthorized,
+			wantHeaders: true,
+		},
+		{
+			name:     "not allowed origin",
+			method:   http.MethodGet,
+			origin:   "http://example.com",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:        "allowed preflight",
+			method:      http.MethodOptions,
+			origin:      "http://localhost:3000",
+			preflight:   true,
+			wantCode:    http.StatusNoContent,
+			wantHeaders: true,
+		},
+		{
+			name:      "not allowed preflight",
+			method:    http.MethodOptions,
+			origin:    "http://example.com",
+			preflight: true,
+			wantCode:  http.StatusNoContent,
+		},
+		{
+			name:        "options without preflight",
+			method:      http.MethodOptions,
+			origin:      "http://localhost:3000",
+			wantCode:    http.StatusUnauthorized,
+			wantHeaders: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/", nil)
+			if tc.origin != "" {
+				r.Header.Set("Origin", tc.origin)
+			}
+			if tc.preflight {
+				r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.wantCode {
+				t.Errorf("got status code %d, want %d", w.Code, tc.wantCode)
+			}
+			var wantOrigin string
+			if tc.wantHeaders {
+				wantOrigin = tc.origin
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != wantOrigin {
+				t.Errorf("got allowed origin %q, want %q", got, wantOrigin)
+			}
+		})
+	}
+}
+
+func TestCORS_allowedOrigins(t *testing.T) {
+	const origin = "http://localhost:3000"
+
+	cors := jsonhttp.NewCORS(nil)
+	if cors.Allowed(origin) {
+		t.Error("origin allowed with nil allowed origins")
+	}
+
+	cors.SetAllowedOrigins([]string{})
+	if cors.Allowed(origin) {
+		t.Error("origin allowed with empty allowed origins")
+	}
+
+	cors.SetAllowedOrigins([]string{"*"})
+	if !cors.Allowed(origin) {
+		t.Error("origin not allowed with the wildcard")
+	}
+}
+
+func TestCORS_wildcard(t *testing.T) {
+	cors := jsonhttp.NewCORS([]string{"*", "http://localhost:3000"})
+	h := cors.Handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	for _, tc := range []struct {
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{
+			origin:          "http://localhost:3000",
+			wantOrigin:      "http://localhost:3000",
+			wantCredentials: "true",
+		},
+		{
+			origin:     "http://example.com",
+			wantOrigin: "*",
+		},
+	} {
+		t.Run(tc.origin, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Origin", tc.origin)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tc.wantOrigin {
+				t.Errorf("got allowed origin %q, want %q", got, tc.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tc.wantCredentials {
+				t.Errorf("got allowed credentials %q, want %q", got, tc.wantCredentials)
+			}
+		})
+	}
+}
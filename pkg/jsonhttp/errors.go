@@ -0,0 +1,51 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonhttp
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Reasons are the machine-readable codes of the errors that the responses
+// of the handlers share.
+const (
+	ReasonInvalidPagination = "invalid_pagination"
+	ReasonNotAcceptable     = "not_acceptable"
+)
+
+// Error is the error that is responded with the HTTP status code, and with
+// the reason as the machine-readable code in the status response.
+type Error struct {
+	Code    int
+	Reason  string
+	Message string
+}
+
+// NewError returns the error with the HTTP status code, the reason and the
+// message.
+func NewError(code int, reason, message string) *Error {
+	return &Error{
+		Code:    code,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// RespondError writes the status response of the error with its status code
+// and reason. Other errors are responded with status code 500, without the
+// error message, which may contain the internal details.
+func RespondError(w http.ResponseWriter, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		InternalServerError(w, nil)
+		return
+	}
+	Respond(w, e.Code, e)
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonhttp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+func TestRespondError(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want jsonhttp.StatusResponse
+	}{
+		{
+			name: "error",
+			err:  jsonhttp.NewError(http.StatusConflict, "exists", "already exists"),
+			want: jsonhttp.StatusResponse{Message: "already exists", Code: http.StatusConflict, Reason: "exists"},
+		},
+		{
+			name: "wrapped error",
+			err:  fmt.Errorf("create: %w", jsonhttp.NewError(http.StatusConflict, "exists", "already exists")),
+			want: jsonhttp.StatusResponse{Message: "already exists", Code: http.StatusConflict, Reason: "exists"},
+		},
+		{
+			name: "internal error",
+			err:  errors.New("database closed"),
+			want: jsonhttp.StatusResponse{Message: http.StatusText(http.StatusInternalServerError), Code: http.StatusInternalServerError},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			jsonhttp.RespondError(w, tc.err)
+
+			if w.Code != tc.want.Code {
+				t.Errorf("got status code %d, want %d", w.Code, tc.want.Code)
+			}
+			var got jsonhttp.StatusResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got response %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -5,7 +5,11 @@
 package jsonhttp
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
 	"resenje.org/web"
 )
@@ -19,3 +23,73 @@ func (h MethodHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func NotFoundHandler(w http.ResponseWriter, _ *http.Request) {
 	NotFound(w, nil)
 }
+
+// NewConcurrencyLimitHandler returns a middleware that limits the number of
+// requests that are handled at the same time by all handlers that it wraps.
+// Requests over the limit are rejected with status code 429 and the
+// Retry-After header. Requests are not limited if the limit is zero.
+func NewConcurrencyLimitHandler(limit int, retryAfter time.Duration) func(http.Handler) http.Handler {
+	return NewConcurrencyLimiter(limit, retryAfter).Handler
+}
+
+// ConcurrencyLimiter limits the number of requests that are handled at the
+// same time by all handlers that it wraps, as the middleware returned by
+// NewConcurrencyLimitHandler, with the limit that can be changed while the
+// requests are handled.
+type ConcurrencyLimiter struct {
+	limit             int
+	active            int
+	retryAfterSeconds string
+	mu                sync.Mutex
+}
+
+// NewConcurrencyLimiter returns the limiter of the number of concurrent
+// requests. Requests are not limited if the limit is zero.
+func NewConcurrencyLimiter(limit int, retryAfter time.Duration) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		limit:             limit,
+		retryAfterSeconds: strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))),
+	}
+}
+
+// SetLimit changes the limit of the concurrent requests. Requests that are
+// already handled are not interrupted if the new limit is lower.
+func (l *ConcurrencyLimiter) SetLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit = limit
+}
+
+// Handler returns the handler that rejects the requests over the limit with
+// status code 429 and the Retry-After header.
+func (l *ConcurrencyLimiter) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.acquire() {
+			w.Header().Set("Retry-After", l.retryAfterSeconds)
+			TooManyRequests(w, "too many concurrent requests")
+			return
+		}
+		defer l.release()
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (l *ConcurrencyLimiter) acquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit > 0 && l.active >= l.limit {
+		return false
+	}
+	l.active++
+	return true
+}
+
+func (l *ConcurrencyLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active--
+}
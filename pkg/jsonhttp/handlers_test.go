@@ -12,6 +12,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 )
@@ -114,3 +115,106 @@ func TestNotFoundHandler(t *testing.T) {
 
 	testContentType(t, w)
 }
+
+func TestConcurrencyLimitHandler(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+		})
+		limit = jsonhttp.NewConcurrencyLimitHandler(2, 1500*time.Millisecond)
+		// handlers wrapped by the same middleware share the limit
+		h1, h2 = limit(handler), limit(handler)
+	)
+
+	done := make(chan struct{})
+	for _, h := range []http.Handler{h1, h2} {
+		go func(h http.Handler) {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			done <- struct{}{}
+		}(h)
+		<-started
+	}
+
+	w := httptest.NewRecorder()
+	h1.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status code %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("got retry after %q, want %q", got, "2")
+	}
+	var resp jsonhttp.StatusResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message != "too many concurrent requests" {
+		t.Errorf("got message %q", resp.Message)
+	}
+
+	// a request is handled once another one is done
+	release <- struct{}{}
+	<-done
+	go func() {
+		h2.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		done <- struct{}{}
+	}()
+	<-started
+	close(release)
+	<-done
+	<-done
+}
+
+func TestConcurrencyLimitHandler_noLimit(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := jsonhttp.NewConcurrencyLimitHandler(0, time.Second)(handler)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status code %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestConcurrencyLimiter_setLimit(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+		limiter = jsonhttp.NewConcurrencyLimiter(1, time.Second)
+		h       = limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+		}))
+	)
+
+	done := make(chan struct{})
+	serve := func() {
+		go func() {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			done <- struct{}{}
+		}()
+		<-started
+	}
+	serve()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status code %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	// requests over the old limit are handled once it is raised
+	limiter.SetLimit(2)
+	serve()
+
+	// and requests are not limited once the limit is removed
+	limiter.SetLimit(0)
+	serve()
+
+	close(release)
+	for i := 0; i < 3; i++ {
+		<-done
+	}
+}
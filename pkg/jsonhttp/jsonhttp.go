@@ -28,10 +28,12 @@ var (
 // response.
 //
 // If response is string, error or Stringer type the string will be set as
-// value to the Message field.
+// value to the Message field. The Reason field is the machine-readable code of
+// the Error responses.
 type StatusResponse struct {
 	Message string `json:"message,omitempty"`
 	Code    int    `json:"code,omitempty"`
+	Reason  string `json:"reason,omitempty"`
 }
 
 // Respond writes a JSON-encoded body to http.ResponseWriter.
@@ -51,6 +53,12 @@ func Respond(w http.ResponseWriter, statusCode int, response interface{}) {
 				Message: message,
 				Code:    statusCode,
 			}
+		case *Error:
+			response = &StatusResponse{
+				Message: message.Message,
+				Code:    statusCode,
+				Reason:  message.Reason,
+			}
 		case error:
 			response = &StatusResponse{
 				Message: message.Error(),
@@ -0,0 +1,100 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Pagination is the range of the items of a list that a response includes,
+// requested with the offset and the limit query parameters. All items after
+// the offset are included if the limit is zero.
+type Pagination struct {
+	Offset int
+	Limit  int
+}
+
+// ParsePagination returns the pagination of the request. The limit defaults
+// to the max limit, and it can not be over it, unless the max limit is zero.
+// The returned error is the Error with status code 400.
+func ParsePagination(r *http.Request, maxLimit int) (p Pagination, err error) {
+	q := r.URL.Query()
+	if v := q.Get("offset"); v != "" {
+		p.Offset, err = strconv.Atoi(v)
+		if err != nil || p.Offset < 0 {
+			return Pagination{}, NewError(http.StatusBadRequest, ReasonInvalidPagination, "invalid offset")
+		}
+	}
+	p.Limit = maxLimit
+	if v := q.Get("limit"); v != "" {
+		p.Limit, err = strconv.Atoi(v)
+		if err != nil || p.Limit < 1 || (maxLimit > 0 && p.Limit > maxLimit) {
+			return Pagination{}, NewError(http.StatusBadRequest, ReasonInvalidPagination, "invalid limit")
+		}
+	}
+	return p, nil
+}
+
+// Bounds returns the start and the end indexes of the items of a list of
+// length n within the pagination, to be used as the slice expression.
+func (p Pagination) Bounds(n int) (start, end int) {
+	if p.Offset > n {
+		return n, n
+	}
+	start, end = p.Offset, n
+	if p.Limit > 0 && n-start > p.Limit {
+		end = start + p.Limit
+	}
+	return start, end
+}
+
+// ParseFields returns the names of the fields of the items of a list that a
+// response includes, requested with the comma separated fields query
+// parameter. All fields are included if there are none.
+func ParseFields(r *http.Request) (fields []string) {
+	for _, f := range strings.Split(r.URL.Query().Get("fields"), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// SelectFields returns the JSON objects of the items with only the fields of
+// the names, in the form that is encoded by Respond. The items are returned
+// unchanged if there are no fields.
+func SelectFields(items interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(b, &objects); err != nil {
+		return nil, err
+	}
+	for _, o := range objects {
+		for name := range o {
+			if !containsString(fields, name) {
+				delete(o, name)
+			}
+		}
+	}
+	return objects, nil
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
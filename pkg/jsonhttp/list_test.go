@@ -0,0 +1,102 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonhttp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+func TestParsePagination(t *testing.T) {
+	for _, tc := range []struct {
+		query    string
+		maxLimit int
+		want     jsonhttp.Pagination
+		wantErr  bool
+	}{
+		{query: "", want: jsonhttp.Pagination{}},
+		{query: "", maxLimit: 10, want: jsonhttp.Pagination{Limit: 10}},
+		{query: "offset=5&limit=3", maxLimit: 10, want: jsonhttp.Pagination{Offset: 5, Limit: 3}},
+		{query: "limit=20", want: jsonhttp.Pagination{Limit: 20}},
+		{query: "limit=20", maxLimit: 10, wantErr: true},
+		{query: "limit=0", wantErr: true},
+		{query: "offset=-1", wantErr: true},
+		{query: "offset=first", wantErr: true},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			got, err := jsonhttp.ParsePagination(httptest.NewRequest(http.MethodGet, "/?"+tc.query, nil), tc.maxLimit)
+			if tc.wantErr {
+				var e *jsonhttp.Error
+				if !errors.As(err, &e) || e.Code != http.StatusBadRequest || e.Reason != jsonhttp.ReasonInvalidPagination {
+					t.Fatalf("got error %v, want invalid pagination", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got pagination %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPaginationBounds(t *testing.T) {
+	for _, tc := range []struct {
+		pagination jsonhttp.Pagination
+		n          int
+		start, end int
+	}{
+		{pagination: jsonhttp.Pagination{}, n: 5, start: 0, end: 5},
+		{pagination: jsonhttp.Pagination{Offset: 2}, n: 5, start: 2, end: 5},
+		{pagination: jsonhttp.Pagination{Offset: 1, Limit: 2}, n: 5, start: 1, end: 3},
+		{pagination: jsonhttp.Pagination{Offset: 4, Limit: 2}, n: 5, start: 4, end: 5},
+		{pagination: jsonhttp.Pagination{Offset: 10, Limit: 2}, n: 5, start: 5, end: 5},
+	} {
+		start, end := tc.pagination.Bounds(tc.n)
+		if start != tc.start || end != tc.end {
+			t.Errorf("got bounds %d:%d of %+v for %d items, want %d:%d", start, end, tc.pagination, tc.n, tc.start, tc.end)
+		}
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	type item struct {
+		Name  string `json:"name"`
+		Size  int    `json:"size"`
+		Owner string `json:"owner"`
+	}
+	items := []item{{Name: "a", Size: 1, Owner: "x"}, {Name: "b", Size: 2, Owner: "y"}}
+
+	fields := jsonhttp.ParseFields(httptest.NewRequest(http.MethodGet, "/?fields=name,%20size,,", nil))
+	if want := []string{"name", "size"}; !reflect.DeepEqual(fields, want) {
+		t.Fatalf("got fields %v, want %v", fields, want)
+	}
+
+	w := httptest.NewRecorder()
+	selected, err := jsonhttp.SelectFields(items, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonhttp.OK(w, selected)
+	if got, want := w.Body.String(), `[{"name":"a","size":1},{"name":"b","size":2}]`+"\n\n"; got != want {
+		t.Errorf("got body %q, want %q", got, want)
+	}
+
+	// all fields are included without the fields query parameter
+	selected, err = jsonhttp.SelectFields(items, jsonhttp.ParseFields(httptest.NewRequest(http.MethodGet, "/", nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(selected, items) {
+		t.Errorf("got items %v, want %v", selected, items)
+	}
+}
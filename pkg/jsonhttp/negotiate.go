@@ -0,0 +1,71 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonhttp
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Negotiate returns the content type of the offers that is preferred by the
+// Accept header of the request, or the first offer if the header is not set.
+// An empty string is returned if none of the offers is acceptable, for the
+// request to be responded with the NotAcceptable status.
+func Negotiate(r *http.Request, offers ...string) string {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		if len(offers) == 0 {
+			return ""
+		}
+		return offers[0]
+	}
+
+	var (
+		best        string
+		bestQuality float64
+	)
+	for _, offer := range offers {
+		for _, a := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(a))
+			if err != nil || !matchMediaType(mediaType, offer) {
+				continue
+			}
+			quality := 1.0
+			if q, ok := params["q"]; ok {
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					continue
+				}
+			}
+			if quality > bestQuality {
+				best, bestQuality = offer, quality
+			}
+		}
+	}
+	return best
+}
+
+// NegotiateJSON returns true if the JSON responses are acceptable for the
+// request. Otherwise, it responds with the NotAcceptable status.
+func NegotiateJSON(w http.ResponseWriter, r *http.Request) bool {
+	if Negotiate(r, "application/json") == "" {
+		NotAcceptable(w, NewError(http.StatusNotAcceptable, ReasonNotAcceptable, "only json responses are supported"))
+		return false
+	}
+	return true
+}
+
+// matchMediaType returns true if the media type of the Accept header, with
+// the optional wildcards, matches the offered content type.
+func matchMediaType(mediaType, offer string) bool {
+	if mediaType == "*/*" || mediaType == offer {
+		return true
+	}
+	if strings.HasSuffix(mediaType, "/*") {
+		return strings.HasPrefix(offer, strings.TrimSuffix(mediaType, "*"))
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonhttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/json", "text/plain"}
+	for _, tc := range []struct {
+		accept string
+		want   string
+	}{
+		{accept: "", want: "application/json"},
+		{accept: "*/*", want: "application/json"},
+		{accept: "text/plain", want: "text/plain"},
+		{accept: "text/*", want: "text/plain"},
+		{accept: "application/json;q=0.5, text/plain", want: "text/plain"},
+		{accept: "text/html,application/xhtml+xml,*/*;q=0.8", want: "application/json"},
+		{accept: "image/png", want: ""},
+		{accept: "text/plain;q=0", want: ""},
+	} {
+		t.Run(tc.accept, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+			if got := jsonhttp.Negotiate(r, offers...); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNegotiateJSON(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+
+	if jsonhttp.NegotiateJSON(w, r) {
+		t.Fatal("json responses acceptable")
+	}
+	if w.Code != http.StatusNotAcceptable {
+		t.Fatalf("got status code %d, want %d", w.Code, http.StatusNotAcceptable)
+	}
+	var resp jsonhttp.StatusResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Reason != jsonhttp.ReasonNotAcceptable {
+		t.Errorf("got reason %q, want %q", resp.Reason, jsonhttp.ReasonNotAcceptable)
+	}
+}
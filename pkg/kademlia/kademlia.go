@@ -12,10 +12,13 @@ import (
 	"time"
 
 	"github.com/ethersphere/bee/pkg/addressbook"
+	"github.com/ethersphere/bee/pkg/clock"
 	"github.com/ethersphere/bee/pkg/discovery"
 	"github.com/ethersphere/bee/pkg/kademlia/pslice"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/reputation"
+	"github.com/ethersphere/bee/pkg/retry"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/topology"
 	ma "github.com/multiformats/go-multiaddr"
@@ -29,7 +32,7 @@ const (
 var (
 	errMissingAddressBookEntry = errors.New("addressbook underlay entry not found")
 	errOverlayMismatch         = errors.New("overlay mismatch")
-	timeToRetry                = 60 * time.Second
+	timeToRetry                = 60 * time.Second // time to wait before connecting to a peer again if no retry policy is set
 	shortRetry                 = 30 * time.Second
 	saturationPeers            = 4
 )
@@ -39,9 +42,15 @@ type binSaturationFunc func(bin uint8, peers, connected *pslice.PSlice) bool
 // Options for injecting services to Kademlia.
 type Options struct {
 	Base           swarm.Address
-	Discovery      discovery.Driver
+	Discovery      discovery.Driver // peers are not broadcast if it is nil
 	AddressBook    addressbook.Interface
+	Reputation     reputation.Interface    // known peers are dialed by it, the most reliable first; in the order they were added if it is nil
+	Disconnecters  []topology.Disconnecter // notified after a connected peer is removed
+	RetryPolicy    retry.Policy
+	Clock          clock.Clock // times the connection retries, the system clock if not set
 	P2P            p2p.Service
+	LightNode      bool                  // this node is a light node, which is never the closest peer itself
+	LightPeers     p2p.LightNodeReporter // light node peers are not counted in the depth, chosen as the closest or gossiped; all peers are full nodes if it is nil
 	SaturationFunc binSaturationFunc
 	Logger         logging.Logger
 }
@@ -51,16 +60,24 @@ type Kad struct {
 	base           swarm.Address         // this node's overlay address
 	discovery      discovery.Driver      // the discovery driver
 	addressBook    addressbook.Interface // address book to get underlays
+	reputation     reputation.Interface  // records connection attempt outcomes and orders the peers to dial, optional
+	disconnecters  []topology.Disconnecter
+	retryPolicy    retry.Policy          // delays connection attempts to peers that failed to connect
+	clock          clock.Clock           // tells when the connection attempts are retried
 	p2p            p2p.Service           // p2p service to connect to nodes with
+	lightNode      bool                  // this node does not take the storage responsibility
+	lightReporter  p2p.LightNodeReporter // tells which peers are light nodes, optional
 	saturationFunc binSaturationFunc     // pluggable saturation function
 	connectedPeers *pslice.PSlice        // a slice of peers sorted and indexed by po, indexes kept in `bins`
 	knownPeers     *pslice.PSlice        // both are po aware slice of addresses
+	lightPeers     *pslice.PSlice        // connected light node peers, which are not in connectedPeers
 	depth          uint8                 // current neighborhood depth
 	depthMu        sync.RWMutex          // protect depth changes
 	manageC        chan struct{}         // trigger the manage forever loop to connect to new peers
 	waitNext       map[string]retryInfo  // sanction connections to a peer, key is overlay string and value is a retry information
 	waitNextMu     sync.Mutex            // synchronize map
 	peerSig        []chan struct{}
+	depthSig       []chan struct{} // signaled when the depth changes, guarded by peerSigMtx
 	peerSigMtx     sync.Mutex
 	logger         logging.Logger // logger
 	quit           chan struct{}  // quit channel
@@ -71,6 +88,7 @@ type Kad struct {
 type retryInfo struct {
 	tryAfter       time.Time
 	failedAttempts int
+	delay          time.Duration // delay before the last attempt, as returned by the retry policy
 }
 
 // New returns a new Kademlia.
@@ -78,15 +96,28 @@ func New(o Options) *Kad {
 	if o.SaturationFunc == nil {
 		o.SaturationFunc = binSaturated
 	}
+	if o.RetryPolicy == nil {
+		o.RetryPolicy = retry.Constant(timeToRetry)
+	}
+	if o.Clock == nil {
+		o.Clock = clock.System
+	}
 
 	k := &Kad{
 		base:           o.Base,
 		discovery:      o.Discovery,
 		addressBook:    o.AddressBook,
+		reputation:     o.Reputation,
+		disconnecters:  o.Disconnecters,
+		retryPolicy:    o.RetryPolicy,
+		clock:          o.Clock,
 		p2p:            o.P2P,
+		lightNode:      o.LightNode,
+		lightReporter:  o.LightPeers,
 		saturationFunc: o.SaturationFunc,
 		connectedPeers: pslice.New(int(swarm.MaxBins)),
 		knownPeers:     pslice.New(int(swarm.MaxBins)),
+		lightPeers:     pslice.New(int(swarm.MaxBins)),
 		manageC:        make(chan struct{}, 1),
 		waitNext:       make(map[string]retryInfo),
 		logger:         o.Logger,
@@ -132,13 +163,13 @@ func (k *Kad) manage() {
 				return
 			default:
 			}
-			err := k.knownPeers.EachBinRev(func(peer swarm.Address, po uint8) (bool, bool, error) {
+			err := k.eachPeerToDial(func(peer swarm.Address, po uint8) (bool, bool, error) {
 				if k.connectedPeers.Exists(peer) {
 					return false, false, nil
 				}
 
 				k.waitNextMu.Lock()
-				if next, ok := k.waitNext[peer.String()]; ok && time.Now().Before(next.tryAfter) {
+				if next, ok := k.waitNext[peer.String()]; ok && k.clock.Now().Before(next.tryAfter) {
 					k.waitNextMu.Unlock()
 					return false, false, nil
 				}
@@ -170,6 +201,7 @@ func (k *Kad) manage() {
 						if err := k.addressBook.Remove(peer); err != nil {
 							k.logger.Debugf("could not remove peer from addressbook: %s", peer.String())
 						}
+						k.deleteReputation(peer)
 					}
 					k.logger.Debugf("error connecting to peer from kademlia %s: %v", bzzAddr.String(), err)
 					k.logger.Warningf("connecting to peer %s: %v", bzzAddr.ShortString(), err)
@@ -177,15 +209,22 @@ func (k *Kad) manage() {
 					return false, false, nil
 				}
 
+				if k.isLightPeer(peer) {
+					// light nodes are kept out of the depth and the
+					// closest peers as they are on the inbound connections
+					k.knownPeers.Remove(peer, po)
+					k.lightPeers.Add(peer, po)
+					k.logger.Debugf("kademlia: connected to light node peer %s", peer)
+					return false, false, nil
+				}
+
 				k.waitNextMu.Lock()
-				k.waitNext[peer.String()] = retryInfo{tryAfter: time.Now().Add(shortRetry)}
+				k.waitNext[peer.String()] = retryInfo{tryAfter: k.clock.Now().Add(shortRetry)}
 				k.waitNextMu.Unlock()
 
 				k.connectedPeers.Add(peer, po)
 
-				k.depthMu.Lock()
-				k.depth = recalcDepth(k.connectedPeers)
-				k.depthMu.Unlock()
+				k.updateDepth()
 
 				k.logger.Debugf("connected to peer: %s old depth: %d new depth: %d", peer, currentDepth, k.NeighborhoodDepth())
 
@@ -207,6 +246,7 @@ func (k *Kad) manage() {
 				if errors.Is(err, errMissingAddressBookEntry) {
 					po := swarm.Proximity(k.base.Bytes(), peerToRemove.Bytes())
 					k.knownPeers.Remove(peerToRemove, po)
+					k.deleteReputation(peerToRemove)
 				} else {
 					k.logger.Errorf("kademlia manage loop iterator: %v", err)
 				}
@@ -215,6 +255,43 @@ func (k *Kad) manage() {
 	}
 }
 
+// eachPeerToDial iterates over the known peers bin by bin, from the
+// shallowest bin, in the same way as knownPeers.EachBinRev. The peers of
+// every bin are ordered by their reputation, the most reliable first. The
+// order is taken from a snapshot of the known peers, so that it does not
+// depend on the order in which the peers were added, and the known peers
+// are not locked while the peers are dialed.
+func (k *Kad) eachPeerToDial(f topology.EachPeerFunc) error {
+	if k.reputation == nil {
+		return k.knownPeers.EachBinRev(f)
+	}
+
+	bins := make([][]swarm.Address, swarm.MaxBins)
+	_ = k.knownPeers.EachBinRev(func(peer swarm.Address, po uint8) (bool, bool, error) {
+		bins[po] = append(bins[po], peer)
+		return false, false, nil
+	})
+
+	for po, peers := range bins {
+		if err := k.reputation.Sort(peers); err != nil {
+			k.logger.Debugf("kademlia sort peers in bin %d by reputation: %v", po, err)
+		}
+		for _, peer := range peers {
+			stop, next, err := f(peer, uint8(po))
+			if err != nil {
+				return err
+			}
+			if stop {
+				return nil
+			}
+			if next {
+				break
+			}
+		}
+	}
+	return nil
+}
+
 // binSaturated indicates whether a certain bin is saturated or not.
 // when a bin is not saturated it means we would like to proactively
 // initiate connections to other peers in the bin.
@@ -284,19 +361,24 @@ func (k *Kad) connect(ctx context.Context, peer swarm.Address, ma ma.Multiaddr,
 		}
 
 		k.logger.Debugf("error connecting to peer %s: %v", peer, err)
-		retryTime := time.Now().Add(timeToRetry)
 		var e *p2p.ConnectionBackoffError
 		k.waitNextMu.Lock()
+		var retryTime time.Time
+		var delay time.Duration
 		failedAttempts := 0
 		if errors.As(err, &e) {
 			retryTime = e.TryAfter()
 		} else {
+			k.recordReputation(peer, false)
 			info, ok := k.waitNext[peer.String()]
 			if ok {
 				failedAttempts = info.failedAttempts
+				delay = info.delay
 			}
 
 			failedAttempts++
+			delay = k.retryPolicy.Delay(failedAttempts, delay)
+			retryTime = k.clock.Now().Add(delay)
 		}
 
 		if failedAttempts > maxConnAttempts {
@@ -304,9 +386,10 @@ func (k *Kad) connect(ctx context.Context, peer swarm.Address, ma ma.Multiaddr,
 			if err := k.addressBook.Remove(peer); err != nil {
 				k.logger.Debugf("could not remove peer from addressbook: %s", peer.String())
 			}
+			k.deleteReputation(peer)
 			k.logger.Debugf("kademlia pruned peer from address book %s", peer.String())
 		} else {
-			k.waitNext[peer.String()] = retryInfo{tryAfter: retryTime, failedAttempts: failedAttempts}
+			k.waitNext[peer.String()] = retryInfo{tryAfter: retryTime, failedAttempts: failedAttempts, delay: delay}
 		}
 
 		k.waitNextMu.Unlock()
@@ -319,12 +402,53 @@ func (k *Kad) connect(ctx context.Context, peer swarm.Address, ma ma.Multiaddr,
 		return errOverlayMismatch
 	}
 
-	return k.announce(ctx, peer)
+	k.recordReputation(peer, true)
+
+	return k.announce(ctx, peer, k.isLightPeer(peer))
+}
+
+// isLightPeer returns true if the connected peer is a light node.
+func (k *Kad) isLightPeer(peer swarm.Address) bool {
+	return k.lightReporter != nil && k.lightReporter.IsLightNode(peer)
+}
+
+// recordReputation persists the outcome of a connection attempt to a peer.
+func (k *Kad) recordReputation(peer swarm.Address, success bool) {
+	if k.reputation == nil {
+		return
+	}
+
+	var err error
+	if success {
+		err = k.reputation.Success(peer)
+	} else {
+		err = k.reputation.Failure(peer)
+	}
+	if err != nil {
+		k.logger.Debugf("kademlia record reputation of peer %s: %v", peer, err)
+	}
+}
+
+// deleteReputation removes the recorded reputation of a peer that is
+// removed from the address book.
+func (k *Kad) deleteReputation(peer swarm.Address) {
+	if k.reputation == nil {
+		return
+	}
+
+	if err := k.reputation.Delete(peer); err != nil {
+		k.logger.Debugf("kademlia delete reputation of peer %s: %v", peer, err)
+	}
 }
 
 // announce a newly connected peer to our connected peers, but also
-// notify the peer about our already connected peers
-func (k *Kad) announce(ctx context.Context, peer swarm.Address) error {
+// notify the peer about our already connected peers. Light node peers
+// are only notified, as they are not to be connected to by others.
+func (k *Kad) announce(ctx context.Context, peer swarm.Address, light bool) error {
+	if k.discovery == nil {
+		return nil
+	}
+
 	addrs := []swarm.Address{}
 
 	_ = k.connectedPeers.EachBinRev(func(connectedPeer swarm.Address, _ uint8) (bool, bool, error) {
@@ -334,6 +458,10 @@ func (k *Kad) announce(ctx context.Context, peer swarm.Address) error {
 
 		addrs = append(addrs, connectedPeer)
 
+		if light {
+			return false, false, nil
+		}
+
 		// this needs to be in a separate goroutine since a peer we are gossipping to might
 		// be slow and since this function is called with the same context from kademlia connect
 		// function, this might result in the unfortunate situation where we end up on
@@ -383,11 +511,17 @@ func (k *Kad) AddPeer(ctx context.Context, addr swarm.Address) error {
 
 // Connected is called when a peer has dialed in.
 func (k *Kad) Connected(ctx context.Context, addr swarm.Address) error {
-	if err := k.announce(ctx, addr); err != nil {
+	light := k.isLightPeer(addr)
+	if err := k.announce(ctx, addr, light); err != nil {
 		return err
 	}
 
 	po := swarm.Proximity(k.base.Bytes(), addr.Bytes())
+	if light {
+		k.lightPeers.Add(addr, po)
+		k.logger.Debugf("kademlia: connected to light node peer %s", addr)
+		return nil
+	}
 	k.knownPeers.Add(addr, po)
 	k.connectedPeers.Add(addr, po)
 
@@ -395,9 +529,7 @@ func (k *Kad) Connected(ctx context.Context, addr swarm.Address) error {
 	delete(k.waitNext, addr.String())
 	k.waitNextMu.Unlock()
 
-	k.depthMu.Lock()
-	k.depth = recalcDepth(k.connectedPeers)
-	k.depthMu.Unlock()
+	k.updateDepth()
 
 	k.notifyPeerSig()
 
@@ -412,15 +544,20 @@ func (k *Kad) Connected(ctx context.Context, addr swarm.Address) error {
 // Disconnected is called when peer disconnects.
 func (k *Kad) Disconnected(addr swarm.Address) {
 	po := swarm.Proximity(k.base.Bytes(), addr.Bytes())
+	for _, d := range k.disconnecters {
+		d.Disconnected(addr)
+	}
+	if k.lightPeers.Exists(addr) {
+		k.lightPeers.Remove(addr, po)
+		return
+	}
 	k.connectedPeers.Remove(addr, po)
 
 	k.waitNextMu.Lock()
-	k.waitNext[addr.String()] = retryInfo{tryAfter: time.Now().Add(timeToRetry), failedAttempts: 0}
+	k.waitNext[addr.String()] = retryInfo{tryAfter: k.clock.Now().Add(k.retryPolicy.Delay(1, 0)), failedAttempts: 0}
 	k.waitNextMu.Unlock()
 
-	k.depthMu.Lock()
-	k.depth = recalcDepth(k.connectedPeers)
-	k.depthMu.Unlock()
+	k.updateDepth()
 	select {
 	case k.manageC <- struct{}{}:
 	default:
@@ -428,12 +565,32 @@ func (k *Kad) Disconnected(addr swarm.Address) {
 	k.notifyPeerSig()
 }
 
+// updateDepth recalculates the neighborhood depth from the connected peers
+// and signals the depth subscribers if it changed.
+func (k *Kad) updateDepth() {
+	k.depthMu.Lock()
+	depth := recalcDepth(k.connectedPeers)
+	changed := depth != k.depth
+	k.depth = depth
+	k.depthMu.Unlock()
+
+	if changed {
+		k.peerSigMtx.Lock()
+		signal(k.depthSig)
+		k.peerSigMtx.Unlock()
+	}
+}
+
 func (k *Kad) notifyPeerSig() {
 	k.peerSigMtx.Lock()
 	defer k.peerSigMtx.Unlock()
 
-	for _, c := range k.peerSig {
-		// Every peerSig channel has a buffer capacity of 1,
+	signal(k.peerSig)
+}
+
+func signal(sigs []chan struct{}) {
+	for _, c := range sigs {
+		// Every signal channel has a buffer capacity of 1,
 		// so every receiver will get the signal even if the
 		// select statement has the default case to avoid blocking.
 		select {
@@ -443,27 +600,50 @@ func (k *Kad) notifyPeerSig() {
 	}
 }
 
-// ClosestPeer returns the closest peer to a given address.
+// ClosestPeer returns the closest peer to a given address. A light node
+// returns the closest peer even if it is closer to the address itself.
 func (k *Kad) ClosestPeer(addr swarm.Address) (swarm.Address, error) {
+	return k.ClosestReliablePeer(addr, nil)
+}
+
+// ClosestReliablePeer returns the closest peer to the address for which the
+// unreliable filter returns false. The unreliable peers are returned only if
+// no other peer is closer to the address than this node. All peers are
+// reliable if the filter is nil. The skipped peers are never returned.
+func (k *Kad) ClosestReliablePeer(addr swarm.Address, unreliable topology.PeerFilter, skip ...swarm.Address) (swarm.Address, error) {
 	if k.connectedPeers.Length() == 0 {
 		return swarm.Address{}, topology.ErrNotFound
 	}
 
 	closest := k.base
+	if k.lightNode {
+		closest = swarm.ZeroAddress
+	}
+	// closest peer that is not failing, unreliable peers are
+	// chosen only if no reliable peer is closer than this node
+	closestReliable := closest
+	skipped := topology.SkipPeers(skip...)
 	err := k.connectedPeers.EachBinRev(func(peer swarm.Address, po uint8) (bool, bool, error) {
-		dcmp, err := swarm.DistanceCmp(addr.Bytes(), closest.Bytes(), peer.Bytes())
+		if skipped(peer) {
+			return false, false, nil
+		}
+		closer, err := closerPeer(addr, closest, peer)
 		if err != nil {
 			return false, false, err
 		}
-		switch dcmp {
-		case 0:
-			// do nothing
-		case -1:
-			// current peer is closer
+		if closer {
 			closest = peer
-		case 1:
-			// closest is already closer to chunk
-			// do nothing
+		}
+
+		if unreliable != nil && unreliable(peer) {
+			return false, false, nil
+		}
+		closer, err = closerPeer(addr, closestReliable, peer)
+		if err != nil {
+			return false, false, err
+		}
+		if closer {
+			closestReliable = peer
 		}
 		return false, false, nil
 	})
@@ -471,14 +651,37 @@ func (k *Kad) ClosestPeer(addr swarm.Address) (swarm.Address, error) {
 		return swarm.Address{}, err
 	}
 
+	if !closestReliable.Equal(k.base) && !closestReliable.IsZero() {
+		return closestReliable, nil
+	}
+
 	// check if self
 	if closest.Equal(k.base) {
 		return swarm.Address{}, topology.ErrWantSelf
 	}
+	// a light node without any peers that are not skipped
+	if closest.IsZero() {
+		return swarm.Address{}, topology.ErrNotFound
+	}
 
 	return closest, nil
 }
 
+// closerPeer reports whether the peer is closer to addr than the closest,
+// or whether there is no closest yet, if it is the zero address.
+func closerPeer(addr, closest, peer swarm.Address) (bool, error) {
+	if closest.IsZero() {
+		return true, nil
+	}
+	dcmp, err := swarm.DistanceCmp(addr.Bytes(), closest.Bytes(), peer.Bytes())
+	if err != nil {
+		return false, err
+	}
+	// -1 means that the peer is closer, 0 and 1 that the closest
+	// is at the same distance or already closer to addr
+	return dcmp == -1, nil
+}
+
 // EachPeer iterates from closest bin to farthest
 func (k *Kad) EachPeer(f topology.EachPeerFunc) error {
 	return k.connectedPeers.EachBin(f)
@@ -492,21 +695,31 @@ func (k *Kad) EachPeerRev(f topology.EachPeerFunc) error {
 // SubscribePeersChange returns the channel that signals when the connected peers
 // set changes. Returned function is safe to be called multiple times.
 func (k *Kad) SubscribePeersChange() (c <-chan struct{}, unsubscribe func()) {
+	return k.subscribe(&k.peerSig)
+}
+
+// SubscribeDepth returns the channel that signals when the neighborhood depth
+// changes. Returned function is safe to be called multiple times.
+func (k *Kad) SubscribeDepth() (c <-chan struct{}, unsubscribe func()) {
+	return k.subscribe(&k.depthSig)
+}
+
+func (k *Kad) subscribe(sigs *[]chan struct{}) (c <-chan struct{}, unsubscribe func()) {
 	channel := make(chan struct{}, 1)
 	var closeOnce sync.Once
 
 	k.peerSigMtx.Lock()
 	defer k.peerSigMtx.Unlock()
 
-	k.peerSig = append(k.peerSig, channel)
+	*sigs = append(*sigs, channel)
 
 	unsubscribe = func() {
 		k.peerSigMtx.Lock()
 		defer k.peerSigMtx.Unlock()
 
-		for i, c := range k.peerSig {
+		for i, c := range *sigs {
 			if c == channel {
-				k.peerSig = append(k.peerSig[:i], k.peerSig[i+1:]...)
+				*sigs = append((*sigs)[:i], (*sigs)[i+1:]...)
 				break
 			}
 		}
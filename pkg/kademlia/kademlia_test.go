@@ -9,6 +9,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"math/rand"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -17,6 +18,8 @@ import (
 
 	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/bzz"
+	"github.com/ethersphere/bee/pkg/clock"
+	clockmock "github.com/ethersphere/bee/pkg/clock/mock"
 	"github.com/ethersphere/bee/pkg/crypto"
 	beeCrypto "github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/discovery/mock"
@@ -25,6 +28,7 @@ import (
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	p2pmock "github.com/ethersphere/bee/pkg/p2p/mock"
+	"github.com/ethersphere/bee/pkg/reputation"
 	mockstate "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/swarm/test"
@@ -312,17 +316,31 @@ func TestDiscoveryHooks(t *testing.T) {
 	waitBcast(t, disc, p3, p1, p2)
 }
 
-func TestBackoff(t *testing.T) {
-	// cheat and decrease the timer
-	defer func(t time.Duration) {
-		*kademlia.TimeToRetry = t
-	}(*kademlia.TimeToRetry)
+// TestNoDiscovery tests that peers are connected without being gossiped
+// if there is no discovery.
+func TestNoDiscovery(t *testing.T) {
+	var (
+		conns int32
+		ab    = addressbook.New(mockstate.NewStateStore())
+		kad   = kademlia.New(kademlia.Options{Base: test.RandomAddress(), AddressBook: ab, P2P: p2pMock(ab, &conns, nil), Logger: logging.New(ioutil.Discard, 0)})
+	)
+	defer kad.Close()
 
-	*kademlia.TimeToRetry = 500 * time.Millisecond
+	pk, _ := crypto.GenerateSecp256k1Key()
+	signer := beeCrypto.NewDefaultSigner(pk)
 
+	addOne(t, signer, kad, ab, test.RandomAddress())
+	waitConn(t, &conns)
+	addOne(t, signer, kad, ab, test.RandomAddress())
+	waitConn(t, &conns)
+	connectOne(t, signer, kad, ab, test.RandomAddress())
+}
+
+func TestBackoff(t *testing.T) {
 	var (
 		conns                    int32 // how many connect calls were made to the p2p mock
-		base, kad, ab, _, signer = newTestKademlia(&conns, nil, nil)
+		clock                    = clockmock.New(time.Now())
+		base, kad, ab, _, signer = newTestKademliaWithClock(&conns, nil, nil, clock)
 	)
 	defer kad.Close()
 
@@ -335,15 +353,15 @@ func TestBackoff(t *testing.T) {
 	// remove that peer
 	removeOne(kad, addr)
 
-	// wait for 100ms, add another peer, expect just one more connection
-	time.Sleep(100 * time.Millisecond)
+	// before the retry time, add another peer, expect just one more connection
+	clock.Add(*kademlia.TimeToRetry / 5)
 	addr = test.RandomAddressAt(base, 1)
 	addOne(t, signer, kad, ab, addr)
 
 	waitCounter(t, &conns, 1)
 
-	// wait for another 400ms, add another, expect 2 connections
-	time.Sleep(400 * time.Millisecond)
+	// at the retry time, add another, expect 2 connections
+	clock.Add(*kademlia.TimeToRetry - *kademlia.TimeToRetry/5)
 	addr = test.RandomAddressAt(base, 1)
 	addOne(t, signer, kad, ab, addr)
 
@@ -433,6 +451,162 @@ func TestAddressBookPrune(t *testing.T) {
 	}
 }
 
+// TestReputation tests that the outcomes of kademlia dials are recorded
+// in the peer reputation store, and that the records of the peers pruned
+// from the address book are deleted.
+func TestReputation(t *testing.T) {
+	defer func(t time.Duration) {
+		*kademlia.TimeToRetry = t
+	}(*kademlia.TimeToRetry)
+
+	*kademlia.TimeToRetry = 50 * time.Millisecond
+
+	var (
+		conns, failedConns int32
+		base               = test.RandomAddress()
+		ab                 = addressbook.New(mockstate.NewStateStore())
+		rep                = newTestReputation(t)
+		logger             = logging.New(ioutil.Discard, 0)
+		kad                = kademlia.New(kademlia.Options{Base: base, Discovery: mock.NewDiscovery(), AddressBook: ab, Reputation: rep, P2P: p2pMock(ab, &conns, &failedConns), Logger: logger})
+	)
+	defer kad.Close()
+
+	pk, _ := crypto.GenerateSecp256k1Key()
+	signer := beeCrypto.NewDefaultSigner(pk)
+
+	peer := test.RandomAddressAt(base, 1)
+	addOne(t, signer, kad, ab, peer)
+	waitCounter(t, &conns, 1)
+
+	nonConnPeer, err := bzz.NewAddress(signer, nonConnectableAddress, test.RandomAddressAt(base, 1), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ab.Put(nonConnPeer.Overlay, *nonConnPeer); err != nil {
+		t.Fatal(err)
+	}
+	_ = kad.AddPeer(context.Background(), nonConnPeer.Overlay)
+	waitCounter(t, &failedConns, 1)
+
+	for _, tc := range []struct {
+		peer                swarm.Address
+		successes, failures float64
+	}{
+		{peer: nonConnPeer.Overlay, failures: 1},
+		{peer: peer, successes: 1},
+	} {
+		// the record is written after the connect call returns
+		var r *reputation.Record
+		for i := 0; i < 50; i++ {
+			r, err = rep.Get(tc.peer)
+			if err == nil && r.Successes == tc.successes && r.Failures == tc.failures {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Successes != tc.successes || r.Failures != tc.failures {
+			t.Fatalf("peer %s: got %+v, want %v successes and %v failures", tc.peer, r, tc.successes, tc.failures)
+		}
+	}
+
+	// retry the non connectable peer until it is pruned
+	for i := 0; i < 3; i++ {
+		time.Sleep(50 * time.Millisecond)
+		addOne(t, signer, kad, ab, test.RandomAddressAt(base, 1))
+		waitCounter(t, &conns, 1)
+		waitCounter(t, &failedConns, 1)
+	}
+
+	if _, err := ab.Get(nonConnPeer.Overlay); err != addressbook.ErrNotFound {
+		t.Fatalf("got error %v, want %v", err, addressbook.ErrNotFound)
+	}
+	if _, err := rep.Get(nonConnPeer.Overlay); err != reputation.ErrNotFound {
+		t.Fatalf("got error %v, want %v", err, reputation.ErrNotFound)
+	}
+}
+
+// TestReputationOrder tests that the known peers in a bin are dialed by
+// their reputation, the most reliable first, regardless of the order in
+// which they were added.
+func TestReputationOrder(t *testing.T) {
+	var (
+		base   = test.RandomAddress()
+		ab     = addressbook.New(mockstate.NewStateStore())
+		rep    = newTestReputation(t)
+		logger = logging.New(ioutil.Discard, 0)
+
+		ready  int32 // no bin is saturated once set, so the peers are dialed
+		mtx    sync.Mutex
+		dialed []swarm.Address
+	)
+
+	p2ps := p2pmock.New(p2pmock.WithConnectFunc(func(ctx context.Context, addr ma.Multiaddr) (*bzz.Address, error) {
+		addresses, err := ab.Addresses()
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range addresses {
+			if a.Underlay.Equal(addr) {
+				mtx.Lock()
+				dialed = append(dialed, a.Overlay)
+				mtx.Unlock()
+				return &a, nil
+			}
+		}
+		return nil, errors.New("unknown underlay")
+	}))
+	saturation := func(bin uint8, peers, connected *pslice.PSlice) bool {
+		return atomic.LoadInt32(&ready) == 0
+	}
+
+	kad := kademlia.New(kademlia.Options{Base: base, Discovery: mock.NewDiscovery(), AddressBook: ab, Reputation: rep, P2P: p2ps, SaturationFunc: saturation, Logger: logger})
+	defer kad.Close()
+
+	pk, _ := crypto.GenerateSecp256k1Key()
+	signer := beeCrypto.NewDefaultSigner(pk)
+
+	unreliable := test.RandomAddressAt(base, 1)
+	reliable := test.RandomAddressAt(base, 1)
+	for i := 0; i < 3; i++ {
+		if err := rep.Failure(unreliable); err != nil {
+			t.Fatal(err)
+		}
+		if err := rep.Success(reliable); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the bins are saturated while the peers are added, so that they are
+	// not dialed before all of them are known
+	addOne(t, signer, kad, ab, reliable)
+	addOne(t, signer, kad, ab, unreliable)
+
+	atomic.StoreInt32(&ready, 1)
+	addOne(t, signer, kad, ab, test.RandomAddressAt(base, 2))
+
+	for i := 0; i < 50; i++ {
+		mtx.Lock()
+		n := len(dialed)
+		mtx.Unlock()
+		if n == 3 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if len(dialed) != 3 {
+		t.Fatalf("got %d dialed peers, want 3", len(dialed))
+	}
+	if !dialed[0].Equal(reliable) || !dialed[1].Equal(unreliable) {
+		t.Fatalf("got dial order %v, want %s before %s", dialed[:2], reliable, unreliable)
+	}
+}
+
 // TestClosestPeer tests that ClosestPeer method returns closest connected peer to a given address.
 func TestClosestPeer(t *testing.T) {
 	logger := logging.New(ioutil.Discard, 0)
@@ -511,6 +685,246 @@ func TestClosestPeer(t *testing.T) {
 	}
 }
 
+// TestClosestReliablePeer checks that the unreliable peers are chosen as the
+// closest peer only if no other peer is closer than the base.
+func TestClosestReliablePeer(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	base := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000") // base is 0000
+	connectedPeers := []swarm.Address{
+		swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000"), // binary 1000 -> po 0 to base
+		swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000"), // binary 0100 -> po 1 to base
+		swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000"), // binary 0110 -> po 1 to base
+	}
+
+	disc := mock.NewDiscovery()
+	ab := addressbook.New(mockstate.NewStateStore())
+	var conns int32
+
+	unreliable := map[string]bool{
+		connectedPeers[1].ByteString(): true,
+		connectedPeers[2].ByteString(): true,
+	}
+	filter := func(peer swarm.Address) bool {
+		return unreliable[peer.ByteString()]
+	}
+	kad := kademlia.New(kademlia.Options{Base: base, Discovery: disc, AddressBook: ab, P2P: p2pMock(ab, &conns, nil), Logger: logger})
+	defer kad.Close()
+
+	pk, _ := crypto.GenerateSecp256k1Key()
+	for _, v := range connectedPeers {
+		addOne(t, beeCrypto.NewDefaultSigner(pk), kad, ab, v)
+	}
+	waitCounter(t, &conns, 3)
+
+	for _, tc := range []struct {
+		chunkAddress swarm.Address // chunk address to test
+		expectedPeer int           // points to the index of the connectedPeers slice. -1 means self (baseOverlay)
+	}{
+		{
+			chunkAddress: swarm.MustParseHexAddress("c000000000000000000000000000000000000000000000000000000000000000"), // 1100, want peer 0
+			expectedPeer: 0,
+		},
+		{
+			chunkAddress: swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), // 0111, wants unreliable peer 2, no other peer is closer than self
+			expectedPeer: 2,
+		},
+		{
+			chunkAddress: swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000"), // 0100, wants unreliable peer 1, no other peer is closer than self
+			expectedPeer: 1,
+		},
+		{
+			chunkAddress: swarm.MustParseHexAddress("0000001000000000000000000000000000000000000000000000000000000000"), // want self
+			expectedPeer: -1,
+		},
+	} {
+		peer, err := kad.ClosestReliablePeer(tc.chunkAddress, filter)
+		if tc.expectedPeer == -1 {
+			if !errors.Is(err, topology.ErrWantSelf) {
+				t.Fatalf("wanted %v but got %v", topology.ErrWantSelf, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := connectedPeers[tc.expectedPeer]
+		if !peer.Equal(expected) {
+			t.Fatalf("peers not equal. got %s expected %s", peer, expected)
+		}
+	}
+
+	// the reliable peer that is closer than self is preferred
+	delete(unreliable, connectedPeers[1].ByteString())
+	peer, err := kad.ClosestReliablePeer(swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !peer.Equal(connectedPeers[1]) {
+		t.Fatalf("peers not equal. got %s expected %s", peer, connectedPeers[1])
+	}
+
+	// the skipped peers are not returned, even if they are the only ones
+	// closer than self
+	peer, err = kad.ClosestReliablePeer(swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), filter, connectedPeers[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !peer.Equal(connectedPeers[2]) {
+		t.Fatalf("peers not equal. got %s expected %s", peer, connectedPeers[2])
+	}
+	_, err = kad.ClosestReliablePeer(swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), filter, connectedPeers[1], connectedPeers[2])
+	if !errors.Is(err, topology.ErrWantSelf) {
+		t.Fatalf("wanted %v but got %v", topology.ErrWantSelf, err)
+	}
+}
+
+// TestLightPeers checks that the connected light node peers are told about
+// the other peers, but they are not gossiped and they are neither counted
+// in the depth nor chosen as the closest peers.
+func TestLightPeers(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	base := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")  // base is 0000
+	full := swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000")  // binary 1000 -> po 0 to base
+	light := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000") // binary 0111 -> po 1 to base
+
+	disc := mock.NewDiscovery()
+	ab := addressbook.New(mockstate.NewStateStore())
+	var conns int32
+
+	lightPeers := lightPeers{light.ByteString(): true}
+	kad := kademlia.New(kademlia.Options{Base: base, Discovery: disc, AddressBook: ab, P2P: p2pMock(ab, &conns, nil), LightPeers: lightPeers, Logger: logger})
+	defer kad.Close()
+
+	pk, _ := crypto.GenerateSecp256k1Key()
+	signer := beeCrypto.NewDefaultSigner(pk)
+
+	connectOne(t, signer, kad, ab, full)
+	connectOne(t, signer, kad, ab, light)
+	waitBcast(t, disc, light, full)
+
+	if recs, ok := disc.AddresseeRecords(full); ok && isIn(light, recs) {
+		t.Fatal("light peer gossiped")
+	}
+
+	// the light peer is the closest to its own address, but it is skipped
+	if _, err := kad.ClosestPeer(light); !errors.Is(err, topology.ErrWantSelf) {
+		t.Fatalf("got error %v, want %v", err, topology.ErrWantSelf)
+	}
+
+	var peers []swarm.Address
+	if err := kad.EachPeer(func(p swarm.Address, _ uint8) (bool, bool, error) {
+		peers = append(peers, p)
+		return false, false, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || !peers[0].Equal(full) {
+		t.Fatalf("got peers %v, want %v", peers, []swarm.Address{full})
+	}
+
+	// the disconnected light peer does not change the full peers
+	kad.Disconnected(light)
+	peer, err := kad.ClosestPeer(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !peer.Equal(full) {
+		t.Fatalf("got closest peer %s, want %s", peer, full)
+	}
+}
+
+// TestLightPeers_outbound checks that the light node peers that are dialed
+// are kept out of the depth and the closest peers as on the inbound
+// connections.
+func TestLightPeers_outbound(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	base := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")  // base is 0000
+	light := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000") // binary 0111 -> po 1 to base
+
+	ab := addressbook.New(mockstate.NewStateStore())
+	var conns int32
+
+	lightPeers := lightPeers{light.ByteString(): true}
+	kad := kademlia.New(kademlia.Options{Base: base, AddressBook: ab, P2P: p2pMock(ab, &conns, nil), LightPeers: lightPeers, Logger: logger})
+	defer kad.Close()
+
+	pk, _ := crypto.GenerateSecp256k1Key()
+	addOne(t, beeCrypto.NewDefaultSigner(pk), kad, ab, light)
+	waitConn(t, &conns)
+	// the light peer is not dialed again
+	waitCounter(t, &conns, 0)
+
+	if _, err := kad.ClosestPeer(light); !errors.Is(err, topology.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, topology.ErrNotFound)
+	}
+	if depth := kad.NeighborhoodDepth(); depth != 0 {
+		t.Fatalf("got depth %d, want 0", depth)
+	}
+}
+
+// TestClosestPeer_lightNode checks that a light node chooses the closest
+// peer even if it is closer to the address itself.
+func TestClosestPeer_lightNode(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	base := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000") // base is 0000
+	peer := swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000") // binary 1000 -> po 0 to base
+
+	ab := addressbook.New(mockstate.NewStateStore())
+	var conns int32
+
+	kad := kademlia.New(kademlia.Options{Base: base, AddressBook: ab, P2P: p2pMock(ab, &conns, nil), LightNode: true, Logger: logger})
+	defer kad.Close()
+
+	pk, _ := crypto.GenerateSecp256k1Key()
+	connectOne(t, beeCrypto.NewDefaultSigner(pk), kad, ab, peer)
+
+	got, err := kad.ClosestPeer(swarm.MustParseHexAddress("0000001000000000000000000000000000000000000000000000000000000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(peer) {
+		t.Fatalf("got closest peer %s, want %s", got, peer)
+	}
+}
+
+// lightPeers reports the peers by their overlay addresses as light nodes.
+type lightPeers map[string]bool
+
+func (l lightPeers) IsLightNode(overlay swarm.Address) bool {
+	return l[overlay.ByteString()]
+}
+
+// TestKademlia_SubscribeDepth checks that the depth subscribers are
+// signaled only when the connected peers change the depth.
+func TestKademlia_SubscribeDepth(t *testing.T) {
+	base, kad, ab, _, signer := newTestKademlia(nil, nil, nil)
+	defer kad.Close()
+
+	c, unsubscribe := kad.SubscribeDepth()
+	defer unsubscribe()
+
+	// two bin 8 peers do not change the depth 0
+	connectOne(t, signer, kad, ab, test.RandomAddressAt(base, 8))
+	connectOne(t, signer, kad, ab, test.RandomAddressAt(base, 8))
+	select {
+	case <-c:
+		t.Fatalf("got depth signal with depth %d", kad.NeighborhoodDepth())
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// a bin 0 peer changes the depth to 1, the shallowest empty bin
+	connectOne(t, signer, kad, ab, test.RandomAddressAt(base, 0))
+	select {
+	case <-c:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the depth signal")
+	}
+	if d := kad.NeighborhoodDepth(); d != 1 {
+		t.Fatalf("got depth %d, want 1", d)
+	}
+}
+
 func TestKademlia_SubscribePeersChange(t *testing.T) {
 
 	testSignal := func(t *testing.T, k *kademlia.Kad, c <-chan struct{}) {
@@ -627,13 +1041,30 @@ func TestMarshal(t *testing.T) {
 }
 
 func newTestKademlia(connCounter, failedConnCounter *int32, f func(bin uint8, peers, connected *pslice.PSlice) bool) (swarm.Address, *kademlia.Kad, addressbook.Interface, *mock.Discovery, beeCrypto.Signer) {
+	return newTestKademliaWithClock(connCounter, failedConnCounter, f, nil)
+}
+
+// newTestReputation returns the reputation of the peers that is closed when
+// the test ends.
+func newTestReputation(t *testing.T) reputation.Interface {
+	t.Helper()
+
+	rep, err := reputation.New(mockstate.NewStateStore(), reputation.Options{Logger: logging.New(ioutil.Discard, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = rep.Close() })
+	return rep
+}
+
+func newTestKademliaWithClock(connCounter, failedConnCounter *int32, f func(bin uint8, peers, connected *pslice.PSlice) bool, c clock.Clock) (swarm.Address, *kademlia.Kad, addressbook.Interface, *mock.Discovery, beeCrypto.Signer) {
 	var (
 		base   = test.RandomAddress()                       // base address
 		ab     = addressbook.New(mockstate.NewStateStore()) // address book
 		p2p    = p2pMock(ab, connCounter, failedConnCounter)
-		logger = logging.New(ioutil.Discard, 0)                                                                                            // logger
-		disc   = mock.NewDiscovery()                                                                                                       // mock discovery
-		kad    = kademlia.New(kademlia.Options{Base: base, Discovery: disc, AddressBook: ab, P2P: p2p, Logger: logger, SaturationFunc: f}) // kademlia instance
+		logger = logging.New(ioutil.Discard, 0)                                                                                                      // logger
+		disc   = mock.NewDiscovery()                                                                                                                 // mock discovery
+		kad    = kademlia.New(kademlia.Options{Base: base, Discovery: disc, AddressBook: ab, P2P: p2p, Clock: c, Logger: logger, SaturationFunc: f}) // kademlia instance
 	)
 
 	pk, _ := crypto.GenerateSecp256k1Key()
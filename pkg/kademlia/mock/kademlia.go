@@ -151,6 +151,12 @@ func (m *Mock) SubscribePeersChange() (c <-chan struct{}, unsubscribe func()) {
 	return channel, unsubscribe
 }
 
+// SubscribeDepth returns the channel that is signaled by the Trigger, as
+// the SubscribePeersChange.
+func (m *Mock) SubscribeDepth() (c <-chan struct{}, unsubscribe func()) {
+	return m.SubscribePeersChange()
+}
+
 func (m *Mock) Trigger() {
 	m.trigMtx.Lock()
 	defer m.trigMtx.Unlock()
@@ -10,8 +10,10 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/keystore"
 )
 
 type Service struct {
@@ -57,6 +59,109 @@ func (s *Service) Key(name, password string) (pk *ecdsa.PrivateKey, created bool
 	return pk, false, nil
 }
 
+// Rotate replaces the existing key with the name by a newly generated one,
+// encrypted with the same password. The replaced key is kept in the backup
+// file, whose name is returned together with both keys.
+func (s *Service) Rotate(name, password string) (oldKey, newKey *ecdsa.PrivateKey, backupFilename string, err error) {
+	filename := s.keyFilename(name)
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, "", keystore.ErrNotFound
+		}
+		return nil, nil, "", fmt.Errorf("read private key: %w", err)
+	}
+	oldKey, err = decryptKey(data, password)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	newKey, err = crypto.GenerateSecp256k1Key()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("generate secp256k1 key: %w", err)
+	}
+	backupFilename, err = s.replace(name, password, newKey)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return oldKey, newKey, backupFilename, nil
+}
+
+// Import stores the key with the name, encrypted with the password. An
+// existing key is replaced only if it can be decrypted with the same
+// password, and it is kept in the backup file, whose name is returned
+// together with the replaced key. Both are empty if there is no existing
+// key.
+func (s *Service) Import(name, password string, key *ecdsa.PrivateKey) (oldKey *ecdsa.PrivateKey, backupFilename string, err error) {
+	filename := s.keyFilename(name)
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, "", fmt.Errorf("read private key: %w", err)
+	}
+	if len(data) == 0 {
+		d, err := encryptKey(key, password)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+			return nil, "", err
+		}
+		if err := ioutil.WriteFile(filename, d, 0600); err != nil {
+			return nil, "", err
+		}
+		return nil, "", nil
+	}
+
+	oldKey, err = decryptKey(data, password)
+	if err != nil {
+		return nil, "", err
+	}
+	backupFilename, err = s.replace(name, password, key)
+	if err != nil {
+		return nil, "", err
+	}
+	return oldKey, backupFilename, nil
+}
+
+// Exists reports whether the key with the name is stored.
+func (s *Service) Exists(name string) (bool, error) {
+	if _, err := os.Stat(s.keyFilename(name)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// replace replaces the existing key with the name by the new key, encrypted
+// with the password, and returns the name of the backup file in which the
+// replaced key is kept.
+func (s *Service) replace(name, password string, newKey *ecdsa.PrivateKey) (backupFilename string, err error) {
+	filename := s.keyFilename(name)
+
+	d, err := encryptKey(newKey, password)
+	if err != nil {
+		return "", err
+	}
+
+	// the new key is written completely before the old one is replaced
+	tmpFilename := filename + ".tmp"
+	if err := ioutil.WriteFile(tmpFilename, d, 0600); err != nil {
+		return "", err
+	}
+	backupFilename = filepath.Join(s.dir, fmt.Sprintf("%s.%d.key.bak", name, time.Now().Unix()))
+	if err := os.Rename(filename, backupFilename); err != nil {
+		return "", fmt.Errorf("back up private key: %w", err)
+	}
+	if err := os.Rename(tmpFilename, filename); err != nil {
+		return "", err
+	}
+	return backupFilename, nil
+}
+
 func (s *Service) keyFilename(name string) string {
 	return filepath.Join(s.dir, fmt.Sprintf("%s.key", name))
 }
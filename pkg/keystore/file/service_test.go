@@ -5,10 +5,15 @@
 package file_test
 
 import (
+	"bytes"
+	"errors"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/keystore"
 	"github.com/ethersphere/bee/pkg/keystore/file"
 	"github.com/ethersphere/bee/pkg/keystore/test"
 )
@@ -22,3 +27,130 @@ func TestService(t *testing.T) {
 
 	test.Service(t, file.New(dir))
 }
+
+func TestServiceRotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bzz-keystore-file-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := file.New(dir)
+
+	if _, _, _, err := s.Rotate("swarm", "pass123456"); !errors.Is(err, keystore.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, keystore.ErrNotFound)
+	}
+
+	k1, _, err := s.Key("swarm", "pass123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := s.Rotate("swarm", "invalid password"); !errors.Is(err, keystore.ErrInvalidPassword) {
+		t.Fatalf("got error %v, want %v", err, keystore.ErrInvalidPassword)
+	}
+
+	oldKey, newKey, backupFilename, err := s.Rotate("swarm", "pass123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(oldKey.D.Bytes(), k1.D.Bytes()) {
+		t.Fatal("replaced key is not the existing key")
+	}
+	if bytes.Equal(newKey.D.Bytes(), k1.D.Bytes()) {
+		t.Fatal("new key is equal to the replaced key")
+	}
+
+	k2, created, err := s.Key("swarm", "pass123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Fatal("key is created, but should not be")
+	}
+	if !bytes.Equal(k2.D.Bytes(), newKey.D.Bytes()) {
+		t.Fatal("stored key is not the new key")
+	}
+
+	// the replaced key is restored from the backup file
+	backup, err := ioutil.ReadFile(backupFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restoreDir, err := ioutil.TempDir("", "bzz-keystore-file-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(restoreDir)
+	if err := ioutil.WriteFile(filepath.Join(restoreDir, "swarm.key"), backup, 0600); err != nil {
+		t.Fatal(err)
+	}
+	k3, _, err := file.New(restoreDir).Key("swarm", "pass123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(k3.D.Bytes(), k1.D.Bytes()) {
+		t.Fatal("backed up key is not the replaced key")
+	}
+}
+
+func TestServiceImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bzz-keystore-file-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := file.New(dir)
+
+	if exists, err := s.Exists("swarm"); err != nil || exists {
+		t.Fatalf("got exists %v, error %v, want false, nil", exists, err)
+	}
+
+	k1, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldKey, backupFilename, err := s.Import("swarm", "pass123456", k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oldKey != nil || backupFilename != "" {
+		t.Fatalf("got replaced key %v with backup file %q, want none", oldKey, backupFilename)
+	}
+	if exists, err := s.Exists("swarm"); err != nil || !exists {
+		t.Fatalf("got exists %v, error %v, want true, nil", exists, err)
+	}
+	k, created, err := s.Key("swarm", "pass123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || !bytes.Equal(k.D.Bytes(), k1.D.Bytes()) {
+		t.Fatal("stored key is not the imported key")
+	}
+
+	k2, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Import("swarm", "invalid password", k2); !errors.Is(err, keystore.ErrInvalidPassword) {
+		t.Fatalf("got error %v, want %v", err, keystore.ErrInvalidPassword)
+	}
+	oldKey, backupFilename, err = s.Import("swarm", "pass123456", k2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(oldKey.D.Bytes(), k1.D.Bytes()) {
+		t.Fatal("replaced key is not the existing key")
+	}
+	if _, err := os.Stat(backupFilename); err != nil {
+		t.Fatal(err)
+	}
+	k, _, err = s.Key("swarm", "pass123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(k.D.Bytes(), k2.D.Bytes()) {
+		t.Fatal("stored key is not the imported key")
+	}
+}
@@ -9,7 +9,10 @@ import (
 	"errors"
 )
 
-var ErrInvalidPassword = errors.New("invalid password")
+var (
+	ErrInvalidPassword = errors.New("invalid password")
+	ErrNotFound        = errors.New("key not found")
+)
 
 type Service interface {
 	Key(name, password string) (k *ecdsa.PrivateKey, created bool, err error)
@@ -0,0 +1,158 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/clock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+var (
+	// autoCapacityInterval is the interval at which the capacity is
+	// derived again from the free disk space, if Options.AutoCapacity is
+	// set.
+	autoCapacityInterval = time.Minute
+	// minAutoCapacity is the lower limit of the capacity derived from the
+	// free disk space, so that the garbage collection does not remove
+	// all chunks when the disk is filled with other data.
+	minAutoCapacity uint64 = 1000
+	// diskUsage returns the total size and the free space available to
+	// the user of the disk of the path. It is replaced in tests.
+	diskUsage = diskUsageOf
+)
+
+// errAutoCapacityNotSupported is returned by diskUsageOf on the platforms
+// where the free disk space can not be determined.
+var errAutoCapacityNotSupported = errors.New("free disk space not supported on this platform")
+
+// Capacity returns the number of chunks in the garbage collection index at
+// which the garbage collection is triggered.
+func (db *DB) Capacity() uint64 {
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	return db.capacity
+}
+
+// setCapacity changes the capacity and triggers the garbage collection if
+// the garbage collection index is already over it.
+func (db *DB) setCapacity(capacity uint64) error {
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	db.capacity = capacity
+	db.metrics.Capacity.Set(float64(capacity))
+
+	gcSize, err := db.gcSize.Get()
+	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+		return err
+	}
+	if gcSize >= capacity {
+		db.triggerGarbageCollection()
+	}
+	return nil
+}
+
+// autoCapacity derives the capacity from the free space of the disk of the
+// database at path, leaving the reserve percentage of the disk size free.
+// The chunks in the garbage collection index keep their space, and the
+// free space above the reserve is converted to the number of chunks with
+// the disk space that the stored chunks take on average, or with the chunk
+// size if the database is empty. The capacity is lower than the number of
+// the chunks in the garbage collection index if the free space is below
+// the reserve.
+func (db *DB) autoCapacity(path string, reserve uint64) (capacity uint64, err error) {
+	total, free, err := diskUsage(path)
+	if err != nil {
+		return 0, err
+	}
+	used, err := dirSize(path)
+	if err != nil {
+		return 0, err
+	}
+	counts, err := db.ProximityHistogram()
+	if err != nil {
+		return 0, err
+	}
+	var stored uint64
+	for _, c := range counts {
+		stored += c
+	}
+	gcSize, err := db.gcSize.Get()
+	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+		return 0, err
+	}
+
+	chunkSize := uint64(swarm.ChunkSize)
+	if stored > 0 && used/stored > chunkSize {
+		chunkSize = used / stored
+	}
+
+	reserved := total / 100 * reserve
+	if free >= reserved {
+		capacity = gcSize + (free-reserved)/chunkSize
+	} else if deficit := (reserved - free) / chunkSize; deficit < gcSize {
+		capacity = gcSize - deficit
+	}
+	if capacity < minAutoCapacity {
+		capacity = minAutoCapacity
+	}
+	return capacity, nil
+}
+
+// updateAutoCapacity sets the capacity derived from the free disk space.
+func (db *DB) updateAutoCapacity(path string, reserve uint64) error {
+	capacity, err := db.autoCapacity(path, reserve)
+	if err != nil {
+		return err
+	}
+	if old := db.Capacity(); capacity != old {
+		db.logger.Debugf("localstore: capacity changed from %d to %d chunks by the free disk space", old, capacity)
+	}
+	return db.setCapacity(capacity)
+}
+
+// autoCapacityWorker is a long running function that derives the capacity
+// from the free disk space periodically, when the timer fires.
+func (db *DB) autoCapacityWorker(timer clock.Timer, path string, reserve uint64) {
+	defer close(db.autoCapacityWorkerDone)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C():
+			if err := db.updateAutoCapacity(path, reserve); err != nil {
+				db.logger.Errorf("localstore: auto capacity: %v", err)
+			}
+			timer.Reset(autoCapacityInterval)
+		case <-db.close:
+			return
+		}
+	}
+}
+
+// dirSize returns the total size of the files in the directory tree.
+func dirSize(path string) (size uint64, err error) {
+	err = filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			// the files of the database can be removed while walking
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,203 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	clockmock "github.com/ethersphere/bee/pkg/clock/mock"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestAutoCapacity validates that the capacity is derived from the free
+// disk space over the reserve, and that the garbage collection is triggered
+// when the free space runs out.
+func TestAutoCapacity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-auto-capacity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const (
+		total   = 1000 * swarm.ChunkSize
+		reserve = 10
+		// the reserved space of the disk size
+		reserved = 100 * swarm.ChunkSize
+	)
+	free := uint64(600 * swarm.ChunkSize)
+	defer func(f func(string) (uint64, uint64, error), c uint64) {
+		diskUsage = f
+		minAutoCapacity = c
+	}(diskUsage, minAutoCapacity)
+	diskUsage = func(path string) (uint64, uint64, error) {
+		if path != dir {
+			t.Errorf("got disk usage path %q, want %q", path, dir)
+		}
+		return total, free, nil
+	}
+	minAutoCapacity = 10
+
+	var closed chan struct{}
+	testHookCollectGarbageChan := make(chan uint64)
+	defer setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-closed:
+		}
+	})()
+
+	db, err := New(dir, make([]byte, 32), &Options{
+		Capacity:     5,
+		AutoCapacity: true,
+		DiskReserve:  reserve,
+	}, logging.New(ioutil.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	closed = db.close
+
+	// the empty database gets the free space over the reserve
+	if got := db.Capacity(); got != 500 {
+		t.Fatalf("got capacity %d, want %d", got, 500)
+	}
+
+	const chunkCount = 50
+	for i := 0; i < chunkCount; i++ {
+		ch := generateTestRandomChunk()
+		if _, err := db.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Set(context.Background(), storage.ModeSetSyncPull, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// no free space over the reserve keeps the stored chunks
+	free = reserved
+	if err := db.updateAutoCapacity(dir, reserve); err != nil {
+		t.Fatal(err)
+	}
+	if got := db.Capacity(); got != chunkCount {
+		t.Fatalf("got capacity %d, want %d", got, chunkCount)
+	}
+
+	wantGCSize := uint64(float64(chunkCount) * gcTargetRatio)
+	for {
+		select {
+		case <-testHookCollectGarbageChan:
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gcSize == wantGCSize {
+			break
+		}
+	}
+
+	// the capacity does not go below the limit when the disk is full
+	free = 0
+	if err := db.updateAutoCapacity(dir, reserve); err != nil {
+		t.Fatal(err)
+	}
+	if got := db.Capacity(); got != minAutoCapacity {
+		t.Fatalf("got capacity %d, want %d", got, minAutoCapacity)
+	}
+}
+
+// TestAutoCapacityInterval validates that the capacity is derived from the
+// free disk space again at the interval.
+func TestAutoCapacityInterval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-auto-capacity-interval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		free   uint64 = 600 * swarm.ChunkSize
+		freeMu sync.Mutex
+	)
+	defer func(f func(string) (uint64, uint64, error), c uint64) {
+		diskUsage = f
+		minAutoCapacity = c
+	}(diskUsage, minAutoCapacity)
+	diskUsage = func(string) (uint64, uint64, error) {
+		freeMu.Lock()
+		defer freeMu.Unlock()
+		return 1000 * swarm.ChunkSize, free, nil
+	}
+	minAutoCapacity = 10
+
+	clock := clockmock.New(time.Unix(1600000000, 0))
+	db, err := New(dir, make([]byte, 32), &Options{
+		AutoCapacity: true,
+		DiskReserve:  10,
+		Clock:        clock,
+	}, logging.New(ioutil.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if got := db.Capacity(); got != 500 {
+		t.Fatalf("got capacity %d, want %d", got, 500)
+	}
+
+	freeMu.Lock()
+	free = 300 * swarm.ChunkSize
+	freeMu.Unlock()
+
+	// the capacity is not changed before the interval
+	clock.Add(autoCapacityInterval - time.Second)
+	time.Sleep(10 * time.Millisecond)
+	if got := db.Capacity(); got != 500 {
+		t.Fatalf("got capacity %d before the interval, want %d", got, 500)
+	}
+
+	clock.Add(time.Second)
+	for i := 0; db.Capacity() != 200; i++ {
+		if i == 100 {
+			t.Fatalf("got capacity %d after the interval, want %d", db.Capacity(), 200)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestAutoCapacityInvalidReserve validates that the disk reserve can not be
+// over 100%.
+func TestAutoCapacityInvalidReserve(t *testing.T) {
+	_, err := New("", make([]byte, 32), &Options{
+		AutoCapacity: true,
+		DiskReserve:  101,
+	}, logging.New(ioutil.Discard, 0))
+	if err == nil {
+		t.Fatal("got no error")
+	}
+}
+
+// TestAutoCapacityInMemory validates that the capacity options are used for
+// the in-memory database.
+func TestAutoCapacityInMemory(t *testing.T) {
+	db := newTestDB(t, &Options{
+		Capacity:     100,
+		AutoCapacity: true,
+		DiskReserve:  10,
+	})
+	if got := db.Capacity(); got != 100 {
+		t.Fatalf("got capacity %d, want %d", got, 100)
+	}
+}
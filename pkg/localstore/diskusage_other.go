@@ -0,0 +1,14 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package localstore
+
+// diskUsageOf returns errAutoCapacityNotSupported, as the free disk space
+// is not determined on this platform.
+func diskUsageOf(_ string) (total, free uint64, err error) {
+	return 0, 0, errAutoCapacityNotSupported
+}
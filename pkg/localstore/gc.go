@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/syndtr/goleveldb/leveldb"
 )
 
@@ -80,6 +81,9 @@ func (db *DB) collectGarbageWorker() {
 func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 	db.metrics.GCCounter.Inc()
 	defer totalTimeMetric(db.metrics.TotalTimeCollectGarbage, time.Now())
+	defer func(start time.Time) {
+		db.metrics.GCRoundTimer.Observe(time.Since(start).Seconds())
+	}(time.Now())
 	defer func() {
 		if err != nil {
 			db.metrics.GCErrorCounter.Inc()
@@ -87,12 +91,15 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 	}()
 
 	batch := new(leveldb.Batch)
-	target := db.gcTarget()
+	poCountsChange := make(map[uint8]int64)
 
 	// protect database from changing idexes and gcSize
 	db.batchMu.Lock()
 	defer db.batchMu.Unlock()
 
+	// the capacity is protected by the batch lock
+	target := db.gcTarget()
+
 	// run through the recently pinned chunks and
 	// remove them from the gcIndex before iterating through gcIndex
 	err = db.removeChunksInExcludeIndexFromGC()
@@ -104,7 +111,6 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 	if err != nil {
 		return 0, true, err
 	}
-	db.metrics.GCSize.Inc()
 
 	done = true
 	err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
@@ -132,6 +138,7 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 		if err != nil {
 			return true, nil
 		}
+		poCountsChange[db.po(swarm.NewAddress(item.Address))]--
 		collectedCount++
 		if collectedCount >= gcBatchSize {
 			// bach size limit reached,
@@ -147,14 +154,39 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 	db.metrics.GCCollectedCounter.Inc()
 
 	db.gcSize.PutInBatch(batch, gcSize-collectedCount)
+	poCounts, err := db.incPOCountsInBatch(batch, poCountsChange)
+	if err != nil {
+		return 0, false, err
+	}
 	err = db.shed.WriteBatch(batch)
 	if err != nil {
 		db.metrics.GCExcludeWriteBatchError.Inc()
 		return 0, false, err
 	}
+	db.setPOCountsMetrics(poCounts)
+	db.metrics.GCEvictedCounter.Add(float64(collectedCount))
+	db.metrics.GCSize.Set(float64(gcSize - collectedCount))
 	return collectedCount, done, nil
 }
 
+// CollectGarbage runs garbage collection rounds until the number of chunks
+// in gcIndex is reduced to the garbage collection target, independently of
+// the rounds that are triggered when the capacity is reached. Pinned chunks
+// and chunks that are not synced are never in gcIndex, so they are not
+// collected. It returns the number of collected chunks.
+func (db *DB) CollectGarbage() (collectedCount uint64, err error) {
+	for {
+		c, done, err := db.collectGarbage()
+		collectedCount += c
+		if err != nil {
+			return collectedCount, err
+		}
+		if done {
+			return collectedCount, nil
+		}
+	}
+}
+
 // removeChunksInExcludeIndexFromGC removed any recently chunks in the exclude Index, from the gcIndex.
 func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 	db.metrics.GCExcludeCounter.Inc()
@@ -227,6 +259,7 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 
 // gcTrigger retruns the absolute value for garbage collection
 // target value, calculated from db.capacity and gcTargetRatio.
+// It must be called with the batchMu lock held.
 func (db *DB) gcTarget() (target uint64) {
 	return uint64(float64(db.capacity) * gcTargetRatio)
 }
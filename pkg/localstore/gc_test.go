@@ -472,6 +472,62 @@ func TestDB_gcSize(t *testing.T) {
 	t.Run("gc index size", newIndexGCSizeTest(db))
 }
 
+// TestDB_CollectGarbage tests that garbage collection on demand reduces the
+// number of chunks in gcIndex to the target, keeping the pinned chunks and
+// the chunks that are not synced.
+func TestDB_CollectGarbage(t *testing.T) {
+	db := newTestDB(t, &Options{
+		Capacity: 100,
+	})
+
+	for i := 0; i < 150; i++ {
+		ch := generateTestRandomChunk()
+		if _, err := db.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Set(context.Background(), storage.ModeSetSyncPull, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pinned := addRandomChunks(t, 20, db, true)
+	var unsynced []swarm.Chunk
+	for i := 0; i < 20; i++ {
+		ch := generateTestRandomChunk()
+		if _, err := db.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		unsynced = append(unsynced, ch)
+	}
+
+	if _, err := db.CollectGarbage(); err != nil {
+		t.Fatal(err)
+	}
+
+	gcSize, err := db.gcSize.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gcSize > db.gcTarget() {
+		t.Errorf("got gc size %d, want at most %d", gcSize, db.gcTarget())
+	}
+	t.Run("gc index size", newIndexGCSizeTest(db))
+
+	for _, ch := range append(pinned, unsynced...) {
+		if _, err := db.Get(context.Background(), storage.ModeGetRequest, ch.Address()); err != nil {
+			t.Fatalf("chunk %s: %v", ch.Address(), err)
+		}
+	}
+
+	// the collection is idempotent once the target is reached
+	collected, err := db.CollectGarbage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collected != 0 {
+		t.Errorf("got %d collected chunks, want 0", collected)
+	}
+}
+
 // setTestHookCollectGarbage sets testHookCollectGarbage and
 // returns a function that will reset it to the
 // value before the change.
@@ -0,0 +1,109 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// ProximityHistogram returns the numbers of the stored chunks by their
+// proximity order to the base key, for every bin up to swarm.MaxPO.
+func (db *DB) ProximityHistogram() (counts []uint64, err error) {
+	counts = make([]uint64, swarm.MaxBins)
+	for po := range counts {
+		counts[po], err = db.poCounts.Get(uint64(po))
+		if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+// initPOCounts counts the stored chunks by their proximity order, if the
+// counts are not maintained for the current base key, as in the databases
+// that are created before the counts are introduced, or that are rebased.
+// It must be called before the database is used.
+func (db *DB) initPOCounts() error {
+	baseKeyField, err := db.shed.NewStringField("po-counts-base-key")
+	if err != nil {
+		return err
+	}
+	storedBaseKey, err := baseKeyField.Get()
+	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+		return err
+	}
+
+	if storedBaseKey != string(db.baseKey) {
+		counts := make([]uint64, swarm.MaxBins)
+		err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			counts[db.po(swarm.NewAddress(item.Address))]++
+			return false, nil
+		}, nil)
+		if err != nil {
+			return err
+		}
+
+		batch := new(leveldb.Batch)
+		for po, count := range counts {
+			db.poCounts.PutInBatch(batch, uint64(po), count)
+		}
+		if err := db.shed.WriteBatch(batch); err != nil {
+			return err
+		}
+		if err := baseKeyField.Put(string(db.baseKey)); err != nil {
+			return err
+		}
+	}
+
+	counts, err := db.ProximityHistogram()
+	if err != nil {
+		return err
+	}
+	for po, count := range counts {
+		db.metrics.StoredChunks.WithLabelValues(strconv.Itoa(po)).Set(float64(count))
+	}
+	return nil
+}
+
+// incPOCountsInBatch changes the numbers of the stored chunks in the bins
+// by the changes, which can be negative. It returns the new numbers, which
+// are reported to the metrics with setPOCountsMetrics after the batch is
+// written. This function must be called under batchMu lock.
+func (db *DB) incPOCountsInBatch(batch *leveldb.Batch, changes map[uint8]int64) (counts map[uint8]uint64, err error) {
+	counts = make(map[uint8]uint64, len(changes))
+	for po, change := range changes {
+		if change == 0 {
+			continue
+		}
+		count, err := db.poCounts.Get(uint64(po))
+		if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+			return nil, err
+		}
+		if change > 0 {
+			count += uint64(change)
+		} else if c := uint64(-change); c < count {
+			count -= c
+		} else {
+			// protect uint64 underflow
+			count = 0
+		}
+		db.poCounts.PutInBatch(batch, uint64(po), count)
+		counts[po] = count
+	}
+	return counts, nil
+}
+
+// setPOCountsMetrics reports the numbers of the stored chunks returned by
+// incPOCountsInBatch.
+func (db *DB) setPOCountsMetrics(counts map[uint8]uint64) {
+	for po, count := range counts {
+		db.metrics.StoredChunks.WithLabelValues(strconv.Itoa(int(po))).Set(float64(count))
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestDB_ProximityHistogram validates that the numbers of the stored chunks
+// by proximity order are maintained when the chunks are stored and removed,
+// and rebuilt when the database is opened with a different base key.
+func TestDB_ProximityHistogram(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-histogram")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.New(ioutil.Discard, 0)
+	ctx := context.Background()
+
+	db, err := New(dir, baseKey, &Options{Capacity: 100}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("empty", newProximityHistogramTest(db, nil))
+
+	uploaded := generateTestRandomChunks(50)
+	if _, err := db.Put(ctx, storage.ModePutUpload, uploaded...); err != nil {
+		t.Fatal(err)
+	}
+	synced := generateTestRandomChunks(30)
+	if _, err := db.Put(ctx, storage.ModePutSync, synced...); err != nil {
+		t.Fatal(err)
+	}
+	requested := generateTestRandomChunks(20)
+	if _, err := db.Put(ctx, storage.ModePutRequest, requested...); err != nil {
+		t.Fatal(err)
+	}
+	// the chunks that are already stored are not counted again
+	if _, err := db.Put(ctx, storage.ModePutRequest, uploaded[:10]...); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(ctx, storage.ModeSetRemove, uploaded[0].Address(), synced[0].Address()); err != nil {
+		t.Fatal(err)
+	}
+	stored := append(append(append([]swarm.Chunk(nil), uploaded[1:]...), synced[1:]...), requested...)
+	t.Run("stored", newProximityHistogramTest(db, stored))
+
+	// only the requested chunks are collected, as the uploaded ones are
+	// waiting to be synced and the synced ones are within the radius
+	db.capacity = 10
+	collected, err := db.CollectGarbage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collected == 0 {
+		t.Fatal("no chunks are collected")
+	}
+	var remaining []swarm.Chunk
+	for _, ch := range stored {
+		has, err := db.Has(ctx, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if has {
+			remaining = append(remaining, ch)
+		}
+	}
+	stored = remaining
+	t.Run("collected", newProximityHistogramTest(db, stored))
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = New(dir, baseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("reopened", newProximityHistogramTest(db, stored))
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	newBaseKey := make([]byte, 32)
+	if _, err := rand.Read(newBaseKey); err != nil {
+		t.Fatal(err)
+	}
+	db, err = New(dir, newBaseKey, &Options{Rebase: true}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	t.Run("rebased", newProximityHistogramTest(db, stored))
+}
+
+// newProximityHistogramTest returns a test function that validates that the
+// proximity histogram of the database counts the chunks.
+func newProximityHistogramTest(db *DB, chunks []swarm.Chunk) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+
+		want := make([]uint64, swarm.MaxBins)
+		for _, ch := range chunks {
+			want[db.po(ch.Address())]++
+		}
+
+		got, err := db.ProximityHistogram()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("got %d bins, want %d", len(got), len(want))
+		}
+		for po := range want {
+			if got[po] != want[po] {
+				t.Errorf("got %d chunks in bin %d, want %d", got[po], po, want[po])
+			}
+		}
+	}
+}
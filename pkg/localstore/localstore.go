@@ -19,13 +19,16 @@ package localstore
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"os"
 	"runtime/pprof"
 	"sync"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/clock"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/slowlog"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
@@ -64,7 +67,7 @@ type DB struct {
 	// push syncing index
 	pushIndex shed.Index
 	// push syncing subscriptions triggers
-	pushTriggers   []chan struct{}
+	pushTriggers   []*pushTrigger
 	pushTriggersMu sync.RWMutex
 
 	// pull syncing index
@@ -77,6 +80,10 @@ type DB struct {
 	// proximity order bin
 	binIDs shed.Uint64Vector
 
+	// poCounts stores the number of the stored chunks in every
+	// proximity order bin
+	poCounts shed.Uint64Vector
+
 	// garbage collection index
 	gcIndex shed.Index
 
@@ -86,11 +93,16 @@ type DB struct {
 	// pin files Index
 	pinIndex shed.Index
 
+	// progress of the rebase of the indexes to a new base key, and the
+	// tags of the chunks that are removed from the pull index by it
+	rebaseState     shed.StructField
+	rebaseTagsIndex shed.Index
+
 	// field that stores number of intems in gc index
 	gcSize shed.Uint64Field
 
 	// garbage collection is triggered when gcSize exceeds
-	// the capacity value
+	// the capacity value, protected by batchMu
 	capacity uint64
 
 	// triggers garbage collection event loop
@@ -107,6 +119,11 @@ type DB struct {
 	// baseKey is the overlay address
 	baseKey []byte
 
+	// radius is the storage radius, accessed atomically
+	radius uint32
+	// radiusMu serializes the changes of the storage radius
+	radiusMu sync.Mutex
+
 	batchMu sync.Mutex
 
 	// this channel is closed when close function is called
@@ -118,6 +135,10 @@ type DB struct {
 	// are done
 	collectGarbageWorkerDone chan struct{}
 
+	// closed when the auto capacity worker is done, nil if
+	// the worker is not started
+	autoCapacityWorkerDone chan struct{}
+
 	// wait for all subscriptions to finish before closing
 	// underlaying BadgerDB to prevent possible panics from
 	// iterators
@@ -125,7 +146,9 @@ type DB struct {
 
 	metrics metrics
 
-	logger logging.Logger
+	logger     logging.Logger
+	slowPutLog *slowlog.Logger
+	clock      clock.Clock
 }
 
 // Options struct holds optional parameters for configuring DB.
@@ -133,9 +156,34 @@ type Options struct {
 	// Capacity is a limit that triggers garbage collection when
 	// number of items in gcIndex equals or exceeds it.
 	Capacity uint64
+	// CapacityBytes is the limit of the size of the chunk data in gcIndex
+	// that triggers garbage collection. It is converted to the number of
+	// chunks and it takes precedence over Capacity if it is not zero.
+	CapacityBytes uint64
+	// AutoCapacity derives the capacity from the free space of the disk of
+	// the database and updates it periodically, instead of using Capacity
+	// and CapacityBytes. It is ignored for the in-memory database.
+	AutoCapacity bool
+	// DiskReserve is the percentage of the disk size that is left free by
+	// the stored chunks if AutoCapacity is set.
+	DiskReserve uint64
 	// MetricsPrefix defines a prefix for metrics names.
 	MetricsPrefix string
 	Tags          *tags.Tags
+	// SlowPutThreshold is the duration of Put calls above which they are
+	// logged as slow. They are not logged if it is zero.
+	SlowPutThreshold time.Duration
+	// MigrationDryRun makes New report the schema migrations that need to
+	// be run on the existing data with the ErrMigrationDryRun error,
+	// instead of running them.
+	MigrationDryRun bool
+	// Rebase makes New rebuild the indexes that depend on the base key if
+	// the database was used with a different one. Without it, New returns
+	// ErrBaseKeyChanged in that case.
+	Rebase bool
+	// Clock schedules the derivation of the capacity from the free disk
+	// space, the system clock if it is not set.
+	Clock clock.Clock
 }
 
 // New returns a new DB.  All fields and indexes are initialized
@@ -149,7 +197,12 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		}
 	}
 
+	if o.Clock == nil {
+		o.Clock = clock.System
+	}
+
 	db = &DB{
+		clock:    o.Clock,
 		capacity: o.Capacity,
 		baseKey:  baseKey,
 		tags:     o.Tags,
@@ -162,17 +215,26 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		collectGarbageWorkerDone: make(chan struct{}),
 		metrics:                  newMetrics(),
 		logger:                   logger,
+		slowPutLog:               slowlog.New(logger, "localstore put", o.SlowPutThreshold),
+	}
+	if o.CapacityBytes > 0 {
+		db.capacity = o.CapacityBytes / swarm.ChunkSize
 	}
 	if db.capacity == 0 {
 		db.capacity = defaultCapacity
 	}
+	if o.AutoCapacity && o.DiskReserve > 100 {
+		return nil, fmt.Errorf("disk reserve %d%% over 100%%", o.DiskReserve)
+	}
 
 	capacityMB := float64(db.capacity*swarm.ChunkSize) * 9.5367431640625e-7
 
-	if capacityMB <= 1000 {
-		db.logger.Infof("database capacity: %d chunks (approximately %fMB)", db.capacity, capacityMB)
-	} else {
-		db.logger.Infof("database capacity: %d chunks (approximately %0.1fGB)", db.capacity, capacityMB/1000)
+	if !o.AutoCapacity || path == "" {
+		if capacityMB <= 1000 {
+			db.logger.Infof("database capacity: %d chunks (approximately %fMB)", db.capacity, capacityMB)
+		} else {
+			db.logger.Infof("database capacity: %d chunks (approximately %0.1fGB)", db.capacity, capacityMB/1000)
+		}
 	}
 
 	if maxParallelUpdateGC > 0 {
@@ -201,8 +263,14 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		}
 	} else {
 		// execute possible migrations
-		err = db.migrate(schemaName)
+		err = db.migrate(schemaName, o.MigrationDryRun)
 		if err != nil {
+			if errors.Is(err, ErrMigrationDryRun) {
+				// the database is not used in the dry run
+				if err := db.shed.Close(); err != nil {
+					return nil, err
+				}
+			}
 			return nil, err
 		}
 	}
@@ -300,6 +368,11 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 	if err != nil {
 		return nil, err
 	}
+	// create a vector for the proximity histogram of the stored chunks
+	db.poCounts, err = db.shed.NewUint64Vector("po-counts")
+	if err != nil {
+		return nil, err
+	}
 	// create a pull syncing triggers used by SubscribePull function
 	db.pullTriggers = make(map[uint8][]chan struct{})
 	// push index contains as yet unsynced chunks
@@ -331,7 +404,7 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		return nil, err
 	}
 	// create a push syncing triggers used by SubscribePush function
-	db.pushTriggers = make([]chan struct{}, 0)
+	db.pushTriggers = make([]*pushTrigger, 0)
 	// gc index for removable chunk ordered by ascending last access time
 	db.gcIndex, err = db.shed.NewIndex("AccessTimestamp|BinID|Hash->nil", shed.IndexFuncs{
 		EncodeKey: func(fields shed.Item) (key []byte, err error) {
@@ -401,6 +474,38 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		return nil, err
 	}
 
+	// the indexes are rebuilt if the database was used with a different
+	// base key, as the proximity orders of the chunks are changed
+	if err := db.initRebase(o.Rebase); err != nil {
+		if errors.Is(err, ErrBaseKeyChanged) {
+			// the database is not used without the rebase
+			if err := db.shed.Close(); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	if err := db.initPOCounts(); err != nil {
+		return nil, fmt.Errorf("proximity histogram: %w", err)
+	}
+
+	if o.AutoCapacity {
+		if path == "" {
+			db.logger.Warning("localstore: auto capacity is not supported for the in-memory database")
+		} else {
+			db.capacity, err = db.autoCapacity(path, o.DiskReserve)
+			if err != nil {
+				return nil, fmt.Errorf("auto capacity: %w", err)
+			}
+			db.logger.Infof("database capacity: %d chunks derived from the free disk space with %d%% reserve", db.capacity, o.DiskReserve)
+			db.autoCapacityWorkerDone = make(chan struct{})
+			// the timer is created before the worker is started, so that
+			// the interval is timed from the creation of the database
+			go db.autoCapacityWorker(db.clock.NewTimer(autoCapacityInterval), path, o.DiskReserve)
+		}
+	}
+	db.metrics.Capacity.Set(float64(db.capacity))
+
 	// start garbage collection worker
 	go db.collectGarbageWorker()
 	return db, nil
@@ -418,6 +523,9 @@ func (db *DB) Close() (err error) {
 		// wait for gc worker to
 		// return before closing the shed
 		<-db.collectGarbageWorkerDone
+		if db.autoCapacityWorkerDone != nil {
+			<-db.autoCapacityWorkerDone
+		}
 		close(done)
 	}()
 	select {
@@ -435,6 +543,12 @@ func (db *DB) Close() (err error) {
 	return db.shed.Close()
 }
 
+// BlockCacheStats returns the size and the capacity in bytes of the block
+// cache of the underlying database.
+func (db *DB) BlockCacheStats() (size, capacity int, err error) {
+	return db.shed.BlockCacheStats()
+}
+
 // po computes the proximity order between the address
 // and database base key.
 func (db *DB) po(addr swarm.Address) (bin uint8) {
@@ -67,6 +67,17 @@ func TestDBCapacity(t *testing.T) {
 	}
 }
 
+func TestDBCapacityBytes(t *testing.T) {
+	lo := Options{
+		Capacity:      500,
+		CapacityBytes: 100 * swarm.ChunkSize,
+	}
+	db := newTestDB(t, &lo)
+	if db.capacity != 100 {
+		t.Fatalf("got db capacity %d, want %d", db.capacity, 100)
+	}
+}
+
 // TestDB validates if the chunk can be uploaded and
 // correctly retrieved.
 func TestDB(t *testing.T) {
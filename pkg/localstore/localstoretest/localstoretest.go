@@ -0,0 +1,81 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package localstoretest provides the assertions of the state of the chunks
+// in the local store, so that the tests of the protocols that store chunks
+// can validate the storage effects of the exchanged messages.
+package localstoretest
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// StateGetter is the store of which the chunk state is validated, as the
+// localstore.DB.
+type StateGetter interface {
+	ChunkState(addr swarm.Address) (localstore.ChunkState, error)
+}
+
+// AssertStored fails the test if the chunk is not stored, or if it is stored
+// when want is false.
+func AssertStored(t *testing.T, db StateGetter, addr swarm.Address, want bool) {
+	t.Helper()
+
+	if got := chunkState(t, db, addr).Stored; got != want {
+		t.Errorf("chunk %s: got stored %v, want %v", addr, got, want)
+	}
+}
+
+// AssertPushIndex fails the test if the presence of the chunk in the push
+// index, from which the chunk is push synced, does not match want.
+func AssertPushIndex(t *testing.T, db StateGetter, addr swarm.Address, want bool) {
+	t.Helper()
+
+	if got := chunkState(t, db, addr).InPushIndex; got != want {
+		t.Errorf("chunk %s: got in push index %v, want %v", addr, got, want)
+	}
+}
+
+// AssertPullIndex fails the test if the presence of the chunk in the pull
+// index, from which the chunk is pull synced, does not match want.
+func AssertPullIndex(t *testing.T, db StateGetter, addr swarm.Address, want bool) {
+	t.Helper()
+
+	if got := chunkState(t, db, addr).InPullIndex; got != want {
+		t.Errorf("chunk %s: got in pull index %v, want %v", addr, got, want)
+	}
+}
+
+// AssertGCIndex fails the test if the presence of the chunk in the gc index,
+// from which the chunk can be garbage collected, does not match want.
+func AssertGCIndex(t *testing.T, db StateGetter, addr swarm.Address, want bool) {
+	t.Helper()
+
+	if got := chunkState(t, db, addr).InGCIndex; got != want {
+		t.Errorf("chunk %s: got in gc index %v, want %v", addr, got, want)
+	}
+}
+
+// AssertPinCounter fails the test if the chunk is not pinned the wanted
+// number of times.
+func AssertPinCounter(t *testing.T, db StateGetter, addr swarm.Address, want uint64) {
+	t.Helper()
+
+	if got := chunkState(t, db, addr).PinCounter; got != want {
+		t.Errorf("chunk %s: got pin counter %v, want %v", addr, got, want)
+	}
+}
+
+func chunkState(t *testing.T, db StateGetter, addr swarm.Address) localstore.ChunkState {
+	t.Helper()
+
+	s, err := db.ChunkState(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstoretest_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/localstore/localstoretest"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	testingc "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestAssertions(t *testing.T) {
+	db, err := localstore.New("", make([]byte, swarm.HashSize), nil, logging.New(ioutil.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	ch := testingc.GenerateTestRandomChunk()
+	addr := ch.Address()
+
+	localstoretest.AssertStored(t, db, addr, false)
+	localstoretest.AssertPushIndex(t, db, addr, false)
+
+	if _, err := db.Put(ctx, storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	localstoretest.AssertStored(t, db, addr, true)
+	localstoretest.AssertPushIndex(t, db, addr, true)
+	localstoretest.AssertPullIndex(t, db, addr, true)
+	localstoretest.AssertGCIndex(t, db, addr, false)
+	localstoretest.AssertPinCounter(t, db, addr, 0)
+
+	if err := db.Set(ctx, storage.ModeSetSyncPush, addr); err != nil {
+		t.Fatal(err)
+	}
+	localstoretest.AssertPushIndex(t, db, addr, false)
+	localstoretest.AssertGCIndex(t, db, addr, true)
+
+	if err := db.Set(ctx, storage.ModeSetPin, addr); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(ctx, storage.ModeSetPin, addr); err != nil {
+		t.Fatal(err)
+	}
+	localstoretest.AssertPinCounter(t, db, addr, 2)
+}
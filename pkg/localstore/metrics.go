@@ -59,9 +59,16 @@ type metrics struct {
 	SubscribePushIterationDone    prometheus.Counter
 	SubscribePushIterationFailure prometheus.Counter
 
+	GCEvictedCounter prometheus.Counter
+	GCRoundTimer     prometheus.Histogram
+
 	GCSize                  prometheus.Gauge
 	GCStoreTimeStamps       prometheus.Gauge
 	GCStoreAccessTimeStamps prometheus.Gauge
+
+	StorageRadius prometheus.Gauge
+	StoredChunks  *prometheus.GaugeVec
+	Capacity      prometheus.Gauge
 }
 
 func newMetrics() metrics {
@@ -340,6 +347,37 @@ func newMetrics() metrics {
 			Name:      "gc_access_time_stamp",
 			Help:      "Access timestamp in Garbage collection iteration.",
 		}),
+		StorageRadius: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "storage_radius",
+			Help:      "Proximity order from which the chunks are in the responsibility of the node.",
+		}),
+		StoredChunks: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "stored_chunks",
+			Help:      "Number of stored chunks by their proximity order to the overlay address.",
+		}, []string{"po"}),
+		Capacity: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "capacity",
+			Help:      "Number of chunks in the garbage collection index at which the garbage collection is triggered.",
+		}),
+		GCEvictedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "gc_evicted_count",
+			Help:      "Number of chunks evicted by the garbage collection.",
+		}),
+		GCRoundTimer: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "gc_round_time_histogram",
+			Help:      "Histogram of time spent in a single garbage collection round.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}),
 	}
 }
 
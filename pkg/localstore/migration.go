@@ -19,11 +19,16 @@ package localstore
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var errMissingCurrentSchema = errors.New("could not find current db schema")
 var errMissingTargetSchema = errors.New("could not find target db schema")
 
+// ErrMigrationDryRun is returned by New in the migration dry run mode if
+// the database schema needs to be migrated.
+var ErrMigrationDryRun = errors.New("migration dry run")
+
 type migration struct {
 	name string             // name of the schema
 	fn   func(db *DB) error // the migration function that needs to be performed in order to get to the current schema name
@@ -35,7 +40,10 @@ var schemaMigrations = []migration{
 	{name: DbSchemaCode, fn: func(db *DB) error { return nil }},
 }
 
-func (db *DB) migrate(schemaName string) error {
+// migrate runs the migrations from the schema with the name to the current
+// schema. In the dry run mode, the migrations are only reported with the
+// ErrMigrationDryRun error.
+func (db *DB) migrate(schemaName string, dryRun bool) error {
 	migrations, err := getMigrations(schemaName, DbSchemaCurrent, schemaMigrations, db)
 	if err != nil {
 		return fmt.Errorf("error getting migrations for current schema (%s): %v", schemaName, err)
@@ -46,6 +54,15 @@ func (db *DB) migrate(schemaName string) error {
 		return nil
 	}
 
+	if dryRun {
+		names := make([]string, 0, len(migrations))
+		for _, m := range migrations {
+			names = append(names, m.name)
+		}
+		db.logger.Infof("localstore migration: dry run: %v data migrations on localstore from schema %s: %s", len(migrations), schemaName, strings.Join(names, ", "))
+		return fmt.Errorf("%w: schema %s needs migrations to %s", ErrMigrationDryRun, schemaName, strings.Join(names, ", "))
+	}
+
 	db.logger.Infof("localstore migration: need to run %v data migrations on localstore to schema %s", len(migrations), schemaName)
 	for i := 0; i < len(migrations); i++ {
 		err := migrations[i].fn(db)
@@ -17,6 +17,7 @@
 package localstore
 
 import (
+	"errors"
 	"io/ioutil"
 	"math/rand"
 	"os"
@@ -311,3 +312,76 @@ func TestMigrationErrorTo(t *testing.T) {
 		t.Errorf("migration ran but shouldnt have")
 	}
 }
+
+// TestMigrationDryRun checks that the migrations are not run in the dry run
+// mode and that the database can be migrated after it.
+func TestMigrationDryRun(t *testing.T) {
+	defer func(v []migration, s string) {
+		schemaMigrations = v
+		DbSchemaCurrent = s
+	}(schemaMigrations, DbSchemaCurrent)
+
+	DbSchemaCurrent = DbSchemaCode
+	dbSchemaNext := "dbSchemaNext"
+
+	ran := false
+	schemaMigrations = []migration{
+		{name: DbSchemaCode, fn: func(db *DB) error { return nil }},
+		{name: dbSchemaNext, fn: func(db *DB) error {
+			ran = true
+			return nil
+		}},
+	}
+
+	dir, err := ioutil.TempDir("", "localstore-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logging.New(ioutil.Discard, 0)
+
+	// the dry run of the fresh localstore does not need migrations
+	db, err := New(dir, baseKey, &Options{MigrationDryRun: true}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	DbSchemaCurrent = dbSchemaNext
+
+	_, err = New(dir, baseKey, &Options{MigrationDryRun: true}, logger)
+	if !errors.Is(err, ErrMigrationDryRun) {
+		t.Fatalf("got error %v, want %v", err, ErrMigrationDryRun)
+	}
+	if !strings.Contains(err.Error(), dbSchemaNext) {
+		t.Errorf("error %q does not report migration %s", err, dbSchemaNext)
+	}
+	if ran {
+		t.Fatal("migration ran in dry run")
+	}
+
+	// the database is closed after the dry run and it can be migrated
+	db, err = New(dir, baseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if !ran {
+		t.Error("expected migration did not run")
+	}
+	schemaName, err := db.SchemaName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schemaName != dbSchemaNext {
+		t.Errorf("got schema name %q, want %q", schemaName, dbSchemaNext)
+	}
+}
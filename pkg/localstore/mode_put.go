@@ -35,8 +35,11 @@ func (db *DB) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk)
 
 	db.metrics.ModePut.Inc()
 	defer totalTimeMetric(db.metrics.TotalTimePut, time.Now())
+	if len(chs) > 0 {
+		defer db.slowPutLog.Observe(time.Now(), "%s of %d chunks, first chunk %s", mode, len(chs), chs[0].Address())
+	}
 
-	exist, err = db.put(mode, chs...)
+	exist, err = db.put(ctx, mode, chs...)
 	if err != nil {
 		db.metrics.ModePutFailure.Inc()
 	}
@@ -50,8 +53,9 @@ func (db *DB) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk)
 // same address are passed in arguments, only the first chunk will be stored,
 // and following ones will have exist set to true for their index in exist
 // slice. This is the same behaviour as if the same chunks are passed one by one
-// in multiple put method calls.
-func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err error) {
+// in multiple put method calls. The batch is not written if the context is
+// done, for example when the upload of the chunks is aborted.
+func (db *DB) put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err error) {
 	// protect parallel updates
 	db.batchMu.Lock()
 	defer db.batchMu.Unlock()
@@ -61,7 +65,9 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 	// variables that provide information for operations
 	// to be done after write batch function successfully executes
 	var gcSizeChange int64                      // number to add or subtract from gcSize
+	poCountsChange := make(map[uint8]int64)     // numbers to add to the stored chunks in bins
 	var triggerPushFeed bool                    // signal push feed subscriptions to iterate
+	var lowestPushItem shed.Item                // the lowest new item in the push index
 	triggerPullFeed := make(map[uint8]struct{}) // signal pull feed subscriptions to iterate
 
 	exist = make([]bool, len(chs))
@@ -84,6 +90,9 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				return nil, err
 			}
 			exist[i] = exists
+			if !exists {
+				poCountsChange[db.po(ch.Address())]++
+			}
 			gcSizeChange += c
 		}
 
@@ -93,15 +102,20 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putUpload(batch, binIDs, chunkToItem(ch))
+			item := chunkToItem(ch)
+			exists, c, err := db.putUpload(batch, binIDs, &item)
 			if err != nil {
 				return nil, err
 			}
 			exist[i] = exists
 			if !exists {
+				poCountsChange[db.po(ch.Address())]++
 				// chunk is new so, trigger subscription feeds
 				// after the batch is successfully written
 				triggerPullFeed[db.po(ch.Address())] = struct{}{}
+				if !triggerPushFeed || pushIndexLess(item, lowestPushItem) {
+					lowestPushItem = item
+				}
 				triggerPushFeed = true
 			}
 			gcSizeChange += c
@@ -119,6 +133,7 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 			}
 			exist[i] = exists
 			if !exists {
+				poCountsChange[db.po(ch.Address())]++
 				// chunk is new so, trigger pull subscription feed
 				// after the batch is successfully written
 				triggerPullFeed[db.po(ch.Address())] = struct{}{}
@@ -138,17 +153,26 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 	if err != nil {
 		return nil, err
 	}
+	poCounts, err := db.incPOCountsInBatch(batch, poCountsChange)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	err = db.shed.WriteBatch(batch)
 	if err != nil {
 		return nil, err
 	}
+	db.setPOCountsMetrics(poCounts)
 
 	for po := range triggerPullFeed {
 		db.triggerPullSubscriptions(po)
 	}
 	if triggerPushFeed {
-		db.triggerPushSubscriptions()
+		db.triggerPushSubscriptions(lowestPushItem)
 	}
 	return exist, nil
 }
@@ -197,9 +221,9 @@ func (db *DB) putRequest(batch *leveldb.Batch, binIDs map[uint8]uint64, item she
 // putUpload adds an Item to the batch by updating required indexes:
 //  - put to indexes: retrieve, push, pull
 // The batch can be written to the database.
-// Provided batch and binID map are updated.
-func (db *DB) putUpload(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
-	exists, err = db.retrievalDataIndex.Has(item)
+// Provided batch, binID map and item are updated.
+func (db *DB) putUpload(batch *leveldb.Batch, binIDs map[uint8]uint64, item *shed.Item) (exists bool, gcSizeChange int64, err error) {
+	exists, err = db.retrievalDataIndex.Has(*item)
 	if err != nil {
 		return false, 0, err
 	}
@@ -220,16 +244,16 @@ func (db *DB) putUpload(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed
 	if err != nil {
 		return false, 0, err
 	}
-	err = db.retrievalDataIndex.PutInBatch(batch, item)
+	err = db.retrievalDataIndex.PutInBatch(batch, *item)
 	if err != nil {
 		return false, 0, err
 	}
-	err = db.pullIndex.PutInBatch(batch, item)
+	err = db.pullIndex.PutInBatch(batch, *item)
 	if err != nil {
 		return false, 0, err
 	}
 	if !anonymous {
-		err = db.pushIndex.PutInBatch(batch, item)
+		err = db.pushIndex.PutInBatch(batch, *item)
 		if err != nil {
 			return false, 0, err
 		}
@@ -240,6 +264,7 @@ func (db *DB) putUpload(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed
 
 // putSync adds an Item to the batch by updating required indexes:
 //  - put to indexes: retrieve, pull
+//  - put to indexes: gc, if the chunk is outside of the storage radius
 // The batch can be written to the database.
 // Provided batch and binID map are updated.
 func (db *DB) putSync(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
@@ -265,6 +290,13 @@ func (db *DB) putSync(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.I
 		return false, 0, err
 	}
 
+	if !db.withinRadius(swarm.NewAddress(item.Address)) {
+		gcSizeChange, err = db.addToGC(batch, item)
+		if err != nil {
+			return false, 0, err
+		}
+	}
+
 	return false, gcSizeChange, nil
 }
 
@@ -19,6 +19,7 @@ package localstore
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -145,6 +146,27 @@ func TestModePutUpload(t *testing.T) {
 	}
 }
 
+// TestModePutUpload_canceled validates that the chunks are not stored if the
+// context of the upload is done.
+func TestModePutUpload_canceled(t *testing.T) {
+	db := newTestDB(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ch := generateTestRandomChunk()
+	_, err := db.Put(ctx, storage.ModePutUpload, ch)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+
+	_, err = db.Get(context.Background(), storage.ModeGetRequest, ch.Address())
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+	newItemsCountTest(db.pushIndex, 0)(t)
+}
+
 // TestModePutUpload_parallel uploads chunks in parallel
 // and validates if all chunks can be retrieved with correct data.
 func TestModePutUpload_parallel(t *testing.T) {
@@ -56,6 +56,7 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 	// variables that provide information for operations
 	// to be done after write batch function successfully executes
 	var gcSizeChange int64                      // number to add or subtract from gcSize
+	poCountsChange := make(map[uint8]int64)     // numbers to add to the stored chunks in bins
 	triggerPullFeed := make(map[uint8]struct{}) // signal pull feed subscriptions to iterate
 
 	switch mode {
@@ -77,7 +78,7 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 			db.binIDs.PutInBatch(batch, uint64(po), id)
 		}
 
-	case storage.ModeSetSyncPush, storage.ModeSetSyncPull:
+	case storage.ModeSetSyncPush, storage.ModeSetSyncPull, storage.ModeSetDiscard:
 		for _, addr := range addrs {
 			c, err := db.setSync(batch, addr, mode)
 			if err != nil {
@@ -93,6 +94,29 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 				return err
 			}
 			gcSizeChange += c
+			poCountsChange[db.po(addr)]--
+		}
+
+	case storage.ModeSetRemoveUnpinned:
+		// the pin counters are changed under the same lock, so the
+		// chunks are not pinned before they are removed
+		for _, addr := range addrs {
+			pinned, err := db.pinIndex.Has(addressToItem(addr))
+			if err != nil {
+				return err
+			}
+			if pinned {
+				return storage.ErrPinned
+			}
+			c, err := db.setRemove(batch, addr)
+			if err != nil {
+				if errors.Is(err, leveldb.ErrNotFound) {
+					return storage.ErrNotFound
+				}
+				return err
+			}
+			gcSizeChange += c
+			poCountsChange[db.po(addr)]--
 		}
 
 	case storage.ModeSetPin:
@@ -118,11 +142,16 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 	if err != nil {
 		return err
 	}
+	poCounts, err := db.incPOCountsInBatch(batch, poCountsChange)
+	if err != nil {
+		return err
+	}
 
 	err = db.shed.WriteBatch(batch)
 	if err != nil {
 		return err
 	}
+	db.setPOCountsMetrics(poCounts)
 	for po := range triggerPullFeed {
 		db.triggerPullSubscriptions(po)
 	}
@@ -162,11 +191,19 @@ func (db *DB) setAccess(batch *leveldb.Batch, binIDs map[uint8]uint64, addr swar
 	switch {
 	case err == nil:
 		item.AccessTimestamp = i.AccessTimestamp
-		err = db.gcIndex.DeleteInBatch(batch, item)
+		// pinned chunks are not in the gc index and are not counted
+		// in the gc size
+		inGC, err := db.gcIndex.Has(item)
 		if err != nil {
 			return 0, err
 		}
-		gcSizeChange--
+		if inGC {
+			err = db.gcIndex.DeleteInBatch(batch, item)
+			if err != nil {
+				return 0, err
+			}
+			gcSizeChange--
+		}
 	case errors.Is(err, leveldb.ErrNotFound):
 		// the chunk is not accessed before
 	default:
@@ -202,6 +239,8 @@ func (db *DB) setAccess(batch *leveldb.Batch, binIDs map[uint8]uint64, addr swar
 //	 is then set to 0 to prevent duplicate increments for the same chunk synced multiple times
 // - ModeSetSyncPush - the corresponding tag is incremented, then item is removed
 //   from push sync index
+// - ModeSetDiscard - item is removed from push sync index without incrementing
+//   the tag, as the chunk of an aborted upload is not synced
 // - update to gc index happens given item does not exist in pin index
 // Provided batch is updated.
 func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.ModeSet) (gcSizeChange int64, err error) {
@@ -300,17 +339,31 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 		if err != nil {
 			return 0, err
 		}
+
+	case storage.ModeSetDiscard:
+		err = db.pushIndex.DeleteInBatch(batch, item)
+		if err != nil {
+			return 0, err
+		}
 	}
 
 	i, err = db.retrievalAccessIndex.Get(item)
 	switch {
 	case err == nil:
 		item.AccessTimestamp = i.AccessTimestamp
-		err = db.gcIndex.DeleteInBatch(batch, item)
+		// pinned chunks are not in the gc index and are not counted
+		// in the gc size
+		inGC, err := db.gcIndex.Has(item)
 		if err != nil {
 			return 0, err
 		}
-		gcSizeChange--
+		if inGC {
+			err = db.gcIndex.DeleteInBatch(batch, item)
+			if err != nil {
+				return 0, err
+			}
+			gcSizeChange--
+		}
 	case errors.Is(err, leveldb.ErrNotFound):
 		// the chunk is not accessed before
 	default:
@@ -374,6 +427,11 @@ func (db *DB) setRemove(batch *leveldb.Batch, addr swarm.Address) (gcSizeChange
 	if err != nil {
 		return 0, err
 	}
+	// a removed chunk of an aborted upload must not be push synced
+	err = db.pushIndex.DeleteInBatch(batch, item)
+	if err != nil {
+		return 0, err
+	}
 	err = db.gcIndex.DeleteInBatch(batch, item)
 	if err != nil {
 		return 0, err
@@ -24,6 +24,7 @@ import (
 
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
 	tagtesting "github.com/ethersphere/bee/pkg/tags/testing"
 	"github.com/syndtr/goleveldb/leveldb"
@@ -309,6 +310,47 @@ func TestModeSetSyncPushNormalTag(t *testing.T) {
 	}
 }
 
+// TestModeSetDiscard validates that the discarded chunks are removed from
+// the push index and added to the gc index, without incrementing their tag.
+func TestModeSetDiscard(t *testing.T) {
+	db := newTestDB(t, &Options{Tags: tags.NewTags()})
+
+	tag, err := db.tags.Create("test", 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunks := []swarm.Chunk{
+		generateTestRandomChunk().WithTagID(tag.Uid),
+		generateTestRandomChunk().WithTagID(tag.Uid),
+	}
+	_, err = db.Put(context.Background(), storage.ModePutUpload, chunks...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag.Inc(tags.StateStored)
+	tag.Inc(tags.StateStored)
+
+	err = db.Set(context.Background(), storage.ModeSetDiscard, chunkAddresses(chunks)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ch := range chunks {
+		newPushIndexTest(db, ch, 0, leveldb.ErrNotFound)(t)
+	}
+
+	t.Run("push index count", newItemsCountTest(db.pushIndex, 0))
+
+	t.Run("pull index count", newItemsCountTest(db.pullIndex, len(chunks)))
+
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, len(chunks)))
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	tagtesting.CheckTag(t, tag, 0, 2, 0, 0, 0, 2)
+}
+
 // TestModeSetRemove validates ModeSetRemove index values on the provided DB.
 func TestModeSetRemove(t *testing.T) {
 	for _, tc := range multiChunkTestCases {
@@ -353,9 +395,52 @@ func TestModeSetRemove(t *testing.T) {
 
 			t.Run("pull index count", newItemsCountTest(db.pullIndex, 0))
 
+			t.Run("push index count", newItemsCountTest(db.pushIndex, 0))
+
 			t.Run("gc index count", newItemsCountTest(db.gcIndex, 0))
 
 			t.Run("gc size", newIndexGCSizeTest(db))
 		})
 	}
 }
+
+// TestModeSetRemoveUnpinned tests that the chunks are not removed if any of
+// them is pinned, and that they are removed once they are unpinned.
+func TestModeSetRemoveUnpinned(t *testing.T) {
+	db := newTestDB(t, nil)
+
+	chunks := generateTestRandomChunks(3)
+
+	_, err := db.Put(context.Background(), storage.ModePutUpload, chunks...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pinned := chunks[1].Address()
+	if err := db.Set(context.Background(), storage.ModeSetPin, pinned); err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.Set(context.Background(), storage.ModeSetRemoveUnpinned, chunkAddresses(chunks)...)
+	if !errors.Is(err, storage.ErrPinned) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrPinned)
+	}
+	t.Run("retrieve data index count", newItemsCountTest(db.retrievalDataIndex, 3))
+
+	if err := db.Set(context.Background(), storage.ModeSetUnpin, pinned); err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.Set(context.Background(), storage.ModeSetRemoveUnpinned, chunkAddresses(chunks)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("retrieve data index count", newItemsCountTest(db.retrievalDataIndex, 0))
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	err = db.Set(context.Background(), storage.ModeSetRemoveUnpinned, pinned)
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"time"
+
+	"github.com/ethersphere/bee/pkg/shed"
+)
+
+// PushQueue is the state of the chunks in the push index, which are waiting
+// to be push synced.
+type PushQueue struct {
+	// Pending is the number of the chunks in the push index.
+	Pending int
+	// Oldest is the store time of the chunk that is waiting the longest. It
+	// is zero if there are no pending chunks.
+	Oldest time.Time
+	// Tags are the numbers of the pending chunks by their tag uids. Chunks
+	// that do not belong to a tag are counted under zero.
+	Tags map[uint32]int
+}
+
+// PushQueue returns the state of the chunks that are waiting to be push
+// synced. The push index is iterated in full, so it is meant for inspection
+// and not for frequent use.
+func (db *DB) PushQueue() (q PushQueue, err error) {
+	q.Tags = make(map[uint32]int)
+	err = db.pushIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		// the push index is ordered by the store timestamp
+		if q.Pending == 0 {
+			q.Oldest = time.Unix(0, item.StoreTimestamp)
+		}
+		q.Pending++
+		q.Tags[item.Tag]++
+		return false, nil
+	}, nil)
+	if err != nil {
+		return PushQueue{}, err
+	}
+	return q, nil
+}
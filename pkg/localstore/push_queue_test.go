@@ -0,0 +1,71 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// TestDB_PushQueue validates that the pending chunks in the push index are
+// counted by their tags, with the store time of the oldest one.
+func TestDB_PushQueue(t *testing.T) {
+	db := newTestDB(t, nil)
+
+	q, err := db.PushQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Pending != 0 || !q.Oldest.IsZero() || len(q.Tags) != 0 {
+		t.Fatalf("got push queue %+v of the empty database", q)
+	}
+
+	var timestamp int64 = 1000
+	defer setNow(func() int64 {
+		timestamp++
+		return timestamp
+	})()
+
+	chunks := []struct {
+		tag    uint32
+		synced bool
+	}{
+		{tag: 1},
+		{tag: 1},
+		{tag: 2},
+		{tag: 2, synced: true},
+		{tag: 0},
+	}
+	for _, c := range chunks {
+		ch := generateTestRandomChunk().WithTagID(c.tag)
+		if _, err := db.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		if c.synced {
+			if err := db.Set(context.Background(), storage.ModeSetSyncPush, ch.Address()); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	q, err = db.PushQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Pending != 4 {
+		t.Errorf("got %d pending chunks, want %d", q.Pending, 4)
+	}
+	if want := time.Unix(0, 1001); !q.Oldest.Equal(want) {
+		t.Errorf("got oldest %v, want %v", q.Oldest, want)
+	}
+	for tag, want := range map[uint32]int{0: 1, 1: 2, 2: 1} {
+		if got := q.Tags[tag]; got != want {
+			t.Errorf("got %d pending chunks of tag %d, want %d", got, tag, want)
+		}
+	}
+}
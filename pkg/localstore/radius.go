@@ -0,0 +1,181 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// radiusBatchSize is the number of chunks that are added to the garbage
+// collection index in a single batch when the storage radius increases.
+var radiusBatchSize = 1000
+
+// Radius returns the storage radius, the proximity order to the base key
+// from which the chunks are in the responsibility of the node.
+func (db *DB) Radius() uint8 {
+	return uint8(atomic.LoadUint32(&db.radius))
+}
+
+// SetRadius sets the storage radius, usually to the neighbourhood depth of
+// the node. Chunks that are put with ModePutSync outside of the radius are
+// added to the garbage collection index, instead of being kept until they
+// are synced to other peers. When the radius increases, the stored chunks
+// that are left outside of it are added to the garbage collection index,
+// except the pinned chunks and the chunks that are not push synced yet.
+// They are added in batches, releasing the batch lock between them, so that
+// the other operations on the database are not blocked meanwhile, and the
+// garbage collection is triggered once they are added.
+func (db *DB) SetRadius(radius uint8) error {
+	db.radiusMu.Lock()
+	defer db.radiusMu.Unlock()
+
+	db.batchMu.Lock()
+	old := db.Radius()
+	atomic.StoreUint32(&db.radius, uint32(radius))
+	db.metrics.StorageRadius.Set(float64(radius))
+	db.batchMu.Unlock()
+	if radius <= old {
+		return nil
+	}
+
+	var (
+		start *shed.Item
+		added bool
+	)
+	for {
+		last, gcSizeChange, err := db.setOutsideRadiusBatch(radius, start)
+		if err != nil {
+			return err
+		}
+		if gcSizeChange > 0 {
+			added = true
+		}
+		if last == nil {
+			break
+		}
+		start = last
+	}
+	if added {
+		db.triggerGarbageCollection()
+	}
+	return nil
+}
+
+// setOutsideRadiusBatch adds at most radiusBatchSize chunks outside of the
+// radius to the garbage collection index, iterating the pull index after
+// the start item, or from its beginning if it is nil. It returns the last
+// iterated item if there may be more chunks outside of the radius after it.
+func (db *DB) setOutsideRadiusBatch(radius uint8, start *shed.Item) (last *shed.Item, gcSizeChange int64, err error) {
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	batch := new(leveldb.Batch)
+	var count int
+	// the pull index is ordered by the proximity order of the chunks, so
+	// only the bins outside of the radius are iterated
+	err = db.pullIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		if db.po(swarm.NewAddress(item.Address)) >= radius {
+			return true, nil
+		}
+		c, err := db.setOutsideRadius(batch, item)
+		if err != nil {
+			return true, err
+		}
+		gcSizeChange += c
+		count++
+		if count == radiusBatchSize {
+			i := item
+			last = &i
+			return true, nil
+		}
+		return false, nil
+	}, &shed.IterateOptions{
+		StartFrom:         start,
+		SkipStartFromItem: start != nil,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := db.incGCSizeInBatch(batch, gcSizeChange); err != nil {
+		return nil, 0, err
+	}
+	if err := db.shed.WriteBatch(batch); err != nil {
+		return nil, 0, err
+	}
+	return last, gcSizeChange, nil
+}
+
+// withinRadius returns true if the chunk with the address is in the
+// responsibility of the node.
+func (db *DB) withinRadius(addr swarm.Address) bool {
+	return db.po(addr) >= db.Radius()
+}
+
+// setOutsideRadius adds the stored chunk, which is outside of the storage
+// radius, to the garbage collection index, if it is not pinned, not waiting
+// to be push synced and not in the index already.
+// Provided batch is updated.
+func (db *DB) setOutsideRadius(batch *leveldb.Batch, item shed.Item) (gcSizeChange int64, err error) {
+	i, err := db.retrievalDataIndex.Get(item)
+	if err != nil {
+		return 0, err
+	}
+	item.StoreTimestamp = i.StoreTimestamp
+	item.BinID = i.BinID
+
+	pushing, err := db.pushIndex.Has(item)
+	if err != nil {
+		return 0, err
+	}
+	if pushing {
+		return 0, nil
+	}
+	return db.addToGC(batch, item)
+}
+
+// addToGC adds the chunk to the garbage collection index, if it is neither
+// pinned nor in the index already. Item field BinID must be set.
+// Provided batch is updated.
+func (db *DB) addToGC(batch *leveldb.Batch, item shed.Item) (gcSizeChange int64, err error) {
+	i, err := db.retrievalAccessIndex.Get(item)
+	switch {
+	case err == nil:
+		item.AccessTimestamp = i.AccessTimestamp
+		inGC, err := db.gcIndex.Has(item)
+		if err != nil {
+			return 0, err
+		}
+		if inGC {
+			return 0, nil
+		}
+	case errors.Is(err, leveldb.ErrNotFound):
+		item.AccessTimestamp = now()
+		err = db.retrievalAccessIndex.PutInBatch(batch, item)
+		if err != nil {
+			return 0, err
+		}
+	default:
+		return 0, err
+	}
+
+	pinned, err := db.pinIndex.Has(item)
+	if err != nil {
+		return 0, err
+	}
+	if pinned {
+		return 0, nil
+	}
+	err = db.gcIndex.PutInBatch(batch, item)
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
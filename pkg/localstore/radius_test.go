@@ -0,0 +1,149 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestRadius_putSync validates that the chunks that are put with ModePutSync
+// outside of the storage radius are added to the gc index.
+func TestRadius_putSync(t *testing.T) {
+	db := newTestDB(t, nil)
+
+	if err := db.SetRadius(3); err != nil {
+		t.Fatal(err)
+	}
+	if got := db.Radius(); got != 3 {
+		t.Fatalf("got radius %v, want %v", got, 3)
+	}
+
+	outside := newTestChunkInBin(db, 1)
+	within := newTestChunkInBin(db, 4)
+	if _, err := db.Put(context.Background(), storage.ModePutSync, outside, within); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, 1))
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	t.Run("outside chunk in gc index", newGCIndexHasTest(db, outside, true))
+
+	t.Run("within chunk not in gc index", newGCIndexHasTest(db, within, false))
+}
+
+// TestRadius_increase validates that the stored chunks that are left outside
+// of the storage radius when it increases are added to the gc index, except
+// the pinned chunks and the chunks that are not push synced yet.
+func TestRadius_increase(t *testing.T) {
+	db := newTestDB(t, nil)
+	ctx := context.Background()
+
+	synced := newTestChunkInBin(db, 1)
+	pinned := newTestChunkInBin(db, 2)
+	uploaded := newTestChunkInBin(db, 3)
+	within := newTestChunkInBin(db, 6)
+
+	if _, err := db.Put(ctx, storage.ModePutSync, synced, pinned, within); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Put(ctx, storage.ModePutUpload, uploaded); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(ctx, storage.ModeSetPin, pinned.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("gc index count before", newItemsCountTest(db.gcIndex, 0))
+
+	if err := db.SetRadius(5); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, 1))
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	t.Run("synced chunk in gc index", newGCIndexHasTest(db, synced, true))
+
+	t.Run("pinned chunk not in gc index", newGCIndexHasTest(db, pinned, false))
+
+	t.Run("uploaded chunk not in gc index", newGCIndexHasTest(db, uploaded, false))
+
+	t.Run("within chunk not in gc index", newGCIndexHasTest(db, within, false))
+
+	// decreasing the radius does not change the gc index
+	if err := db.SetRadius(0); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("gc index count after decrease", newItemsCountTest(db.gcIndex, 1))
+}
+
+// TestRadius_increaseBatches validates that all chunks outside of the storage
+// radius are added to the gc index when they span multiple batches.
+func TestRadius_increaseBatches(t *testing.T) {
+	defer func(s int) { radiusBatchSize = s }(radiusBatchSize)
+	radiusBatchSize = 3
+
+	db := newTestDB(t, nil)
+
+	var chunks []swarm.Chunk
+	for i := 0; i < 10; i++ {
+		// the last byte makes the addresses in the same bin distinct
+		addr := db.addressInBin(uint8(i % 4)).Bytes()
+		addr[len(addr)-1] ^= byte(i + 1)
+		chunks = append(chunks, swarm.NewChunk(swarm.NewAddress(addr), generateTestRandomChunk().Data()))
+	}
+	within := newTestChunkInBin(db, 6)
+	if _, err := db.Put(context.Background(), storage.ModePutSync, append(chunks, within)...); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.SetRadius(5); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, len(chunks)))
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	t.Run("within chunk not in gc index", newGCIndexHasTest(db, within, false))
+}
+
+// newTestChunkInBin returns a chunk with the address in the proximity order
+// bin of the database base key.
+func newTestChunkInBin(db *DB, bin uint8) swarm.Chunk {
+	return swarm.NewChunk(db.addressInBin(bin), generateTestRandomChunk().Data())
+}
+
+// newGCIndexHasTest returns a test function that validates if the chunk is
+// in the gc index.
+func newGCIndexHasTest(db *DB, ch swarm.Chunk, want bool) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+
+		var got bool
+		err := db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if ch.Address().Equal(swarm.NewAddress(item.Address)) {
+				got = true
+				return true, nil
+			}
+			return false, nil
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got chunk in gc index %v, want %v", got, want)
+		}
+	}
+}
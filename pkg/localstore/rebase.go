@@ -0,0 +1,283 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// ErrBaseKeyChanged is returned by New if the database was used with a
+// different base key and the Rebase option is not set.
+var ErrBaseKeyChanged = errors.New("base key changed")
+
+// rebaseBatchSize is the number of chunks that are reindexed in a single
+// batch when the base key changes.
+const rebaseBatchSize = 1000
+
+// rebaseState is the progress of the rebase that is stored with every batch,
+// so that an interrupted rebase is resumed when the database is opened
+// again, instead of leaving the indexes partly rebuilt.
+type rebaseState struct {
+	// BaseKey is the base key that the indexes are rebuilt for. It is
+	// empty if no rebase is in progress.
+	BaseKey []byte `json:"baseKey"`
+	// Reindexing is set when all pull index entries of the old base key
+	// are removed and the chunks are being assigned the new bin ids.
+	Reindexing bool `json:"reindexing"`
+	// Cursor is the address of the last reindexed chunk.
+	Cursor []byte `json:"cursor"`
+}
+
+// initRebase checks the base key that the database was used with, and
+// rebuilds the indexes that depend on it if the Rebase option is set. An
+// interrupted rebase is always resumed if the Rebase option is set.
+func (db *DB) initRebase(rebase bool) error {
+	baseKeyField, err := db.shed.NewStringField("base-key")
+	if err != nil {
+		return err
+	}
+	storedBaseKey, err := baseKeyField.Get()
+	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+		return err
+	}
+	db.rebaseState, err = db.shed.NewStructField("rebase-state")
+	if err != nil {
+		return err
+	}
+	db.rebaseTagsIndex, err = db.shed.NewIndex("Hash->Tag", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			return fields.Address, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.Address = key
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			b := make([]byte, 4)
+			binary.BigEndian.PutUint32(b, fields.Tag)
+			return b, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.Tag = binary.BigEndian.Uint32(value)
+			return e, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if storedBaseKey == "" {
+		// new database
+		return baseKeyField.Put(string(db.baseKey))
+	}
+	if storedBaseKey == string(db.baseKey) {
+		return nil
+	}
+	if !rebase {
+		return fmt.Errorf("%w: start with the rebase option to reindex the stored chunks", ErrBaseKeyChanged)
+	}
+
+	var state rebaseState
+	if err := db.rebaseState.Get(&state); err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+		return err
+	}
+	if state.BaseKey != nil && !swarm.NewAddress(state.BaseKey).Equal(swarm.NewAddress(db.baseKey)) {
+		return fmt.Errorf("%w: interrupted rebase to base key %x needs to be completed first", ErrBaseKeyChanged, state.BaseKey)
+	}
+	if state.BaseKey != nil {
+		db.logger.Infof("localstore: resuming interrupted rebase")
+	}
+	state.BaseKey = db.baseKey
+
+	count, err := db.rebase([]byte(storedBaseKey), state)
+	if err != nil {
+		return fmt.Errorf("rebase: %w", err)
+	}
+
+	// the base key is updated and the rebase is completed atomically
+	batch := new(leveldb.Batch)
+	baseKeyField.PutInBatch(batch, string(db.baseKey))
+	if err := db.rebaseState.PutInBatch(batch, rebaseState{}); err != nil {
+		return err
+	}
+	if err := db.shed.WriteBatch(batch); err != nil {
+		return err
+	}
+	db.logger.Warningf("localstore: base key changed, %d chunks are reindexed and need to be synced to peers again", count)
+	return nil
+}
+
+// rebase rebuilds the indexes that depend on the proximity of the chunks to
+// the base key, after the database was used with the old base key, as when
+// the overlay address of the node changes. The chunks are assigned new bin
+// ids in the bins of the current base key, so all of them need to be synced
+// again by the peers that pull from the node. It returns the number of the
+// reindexed chunks. It must be called before the database is used.
+//
+// Every batch is written together with the progress of the rebase, so that
+// it continues from the last written batch if it is interrupted.
+func (db *DB) rebase(oldBaseKey []byte, state rebaseState) (count int, err error) {
+	if !state.Reindexing {
+		if err := db.rebaseRemovePullIndex(oldBaseKey, state); err != nil {
+			return 0, err
+		}
+		state.Reindexing = true
+	}
+
+	// bin ids are assigned again from the start of every bin, or continued
+	// from the last written batch
+	binIDs := make(map[uint8]uint64)
+	for po := uint8(0); po <= swarm.MaxPO; po++ {
+		if state.Cursor == nil {
+			binIDs[po] = 0
+			continue
+		}
+		binIDs[po], err = db.binIDs.Get(uint64(po))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	batch := new(leveldb.Batch)
+	writeBatch := func() error {
+		for po, id := range binIDs {
+			db.binIDs.PutInBatch(batch, uint64(po), id)
+		}
+		if err := db.rebaseState.PutInBatch(batch, state); err != nil {
+			return err
+		}
+		if err := db.shed.WriteBatch(batch); err != nil {
+			return err
+		}
+		batch.Reset()
+		return nil
+	}
+
+	var iterateOptions *shed.IterateOptions
+	if state.Cursor != nil {
+		iterateOptions = &shed.IterateOptions{
+			StartFrom:         &shed.Item{Address: state.Cursor},
+			SkipStartFromItem: true,
+		}
+	}
+	err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		oldBinID := item.BinID
+		item.BinID, err = db.incBinID(binIDs, db.po(swarm.NewAddress(item.Address)))
+		if err != nil {
+			return true, err
+		}
+		if err := db.retrievalDataIndex.PutInBatch(batch, item); err != nil {
+			return true, err
+		}
+
+		// the gc index is keyed by the bin id
+		i, err := db.retrievalAccessIndex.Get(item)
+		switch {
+		case err == nil:
+			gcItem := shed.Item{
+				Address:         item.Address,
+				AccessTimestamp: i.AccessTimestamp,
+				BinID:           oldBinID,
+			}
+			inGC, err := db.gcIndex.Has(gcItem)
+			if err != nil {
+				return true, err
+			}
+			if inGC {
+				if err := db.gcIndex.DeleteInBatch(batch, gcItem); err != nil {
+					return true, err
+				}
+				gcItem.BinID = item.BinID
+				if err := db.gcIndex.PutInBatch(batch, gcItem); err != nil {
+					return true, err
+				}
+			}
+		case errors.Is(err, leveldb.ErrNotFound):
+			// the chunk is not accessed
+		default:
+			return true, err
+		}
+
+		// only the chunks that were in the pull index are added to it
+		t, err := db.rebaseTagsIndex.Get(item)
+		switch {
+		case err == nil:
+			item.Tag = t.Tag
+			if err := db.pullIndex.PutInBatch(batch, item); err != nil {
+				return true, err
+			}
+			if err := db.rebaseTagsIndex.DeleteInBatch(batch, item); err != nil {
+				return true, err
+			}
+		case errors.Is(err, leveldb.ErrNotFound):
+		default:
+			return true, err
+		}
+
+		state.Cursor = item.Address
+		count++
+		if count%rebaseBatchSize == 0 {
+			if err := writeBatch(); err != nil {
+				return true, err
+			}
+		}
+		return false, nil
+	}, iterateOptions)
+	if err != nil {
+		return 0, err
+	}
+	if err := writeBatch(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// rebaseRemovePullIndex removes the pull index entries, which are keyed by
+// the proximity order to the old base key, keeping the tags of the chunks in
+// the rebase tags index for the reindexing.
+func (db *DB) rebaseRemovePullIndex(oldBaseKey []byte, state rebaseState) (err error) {
+	baseKey := db.baseKey
+	db.baseKey = oldBaseKey
+	defer func() { db.baseKey = baseKey }()
+
+	batch := new(leveldb.Batch)
+	writeBatch := func() error {
+		if err := db.rebaseState.PutInBatch(batch, state); err != nil {
+			return err
+		}
+		if err := db.shed.WriteBatch(batch); err != nil {
+			return err
+		}
+		batch.Reset()
+		return nil
+	}
+
+	var n int
+	err = db.pullIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		if err := db.rebaseTagsIndex.PutInBatch(batch, item); err != nil {
+			return true, err
+		}
+		if err := db.pullIndex.DeleteInBatch(batch, item); err != nil {
+			return true, err
+		}
+		n++
+		if n%rebaseBatchSize == 0 {
+			if err := writeBatch(); err != nil {
+				return true, err
+			}
+		}
+		return false, nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+	return writeBatch()
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestDB_rebase validates that the indexes that depend on the base key are
+// rebuilt when the database is opened with a different base key and the
+// rebase option.
+func TestDB_rebase(t *testing.T) {
+	testDBRebase(t, nil)
+}
+
+// TestDB_rebaseResume validates that an interrupted rebase is completed when
+// the database is opened again.
+func TestDB_rebaseResume(t *testing.T) {
+	testDBRebase(t, func(t *testing.T, db *DB, oldBaseKey, newBaseKey []byte) {
+		// the pull index entries are removed before the interruption
+		db.baseKey = newBaseKey
+		if err := db.rebaseRemovePullIndex(oldBaseKey, rebaseState{BaseKey: newBaseKey}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// testDBRebase stores chunks with the old base key, calls interrupt if it is
+// set with the database before it is closed, and validates the indexes after
+// the database is opened with the new base key.
+func testDBRebase(t *testing.T, interrupt func(t *testing.T, db *DB, oldBaseKey, newBaseKey []byte)) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "localstore-rebase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	oldBaseKey := make([]byte, 32)
+	if _, err := rand.Read(oldBaseKey); err != nil {
+		t.Fatal(err)
+	}
+	newBaseKey := make([]byte, 32)
+	if _, err := rand.Read(newBaseKey); err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.New(ioutil.Discard, 0)
+
+	db, err := New(dir, oldBaseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploaded := generateTestRandomChunks(100)
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, uploaded...); err != nil {
+		t.Fatal(err)
+	}
+	requested := generateTestRandomChunks(20)
+	if _, err := db.Put(context.Background(), storage.ModePutRequest, requested...); err != nil {
+		t.Fatal(err)
+	}
+	if interrupt != nil {
+		interrupt(t, db, oldBaseKey, newBaseKey)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(dir, newBaseKey, nil, logger); !errors.Is(err, ErrBaseKeyChanged) {
+		t.Fatalf("got error %v, want %v", err, ErrBaseKeyChanged)
+	}
+
+	db, err = New(dir, newBaseKey, &Options{Rebase: true}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var state rebaseState
+	if err := db.rebaseState.Get(&state); err != nil {
+		t.Fatal(err)
+	}
+	if state.BaseKey != nil {
+		t.Fatalf("rebase to %x not completed", state.BaseKey)
+	}
+	t.Run("rebase tags index count", newItemsCountTest(db.rebaseTagsIndex, 0))
+
+	t.Run("pull index count", newItemsCountTest(db.pullIndex, len(uploaded)))
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, len(requested)))
+
+	for _, ch := range uploaded {
+		item, err := db.retrievalDataIndex.Get(addressToItem(ch.Address()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run("pull index", newPullIndexTest(db, ch, item.BinID, nil))
+	}
+
+	for _, ch := range requested {
+		item, err := db.retrievalDataIndex.Get(addressToItem(ch.Address()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		access, err := db.retrievalAccessIndex.Get(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		has, err := db.gcIndex.Has(shed.Item{
+			Address:         item.Address,
+			AccessTimestamp: access.AccessTimestamp,
+			BinID:           item.BinID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !has {
+			t.Fatalf("chunk %s not in gc index with bin id %d", ch.Address(), item.BinID)
+		}
+	}
+
+	// bin ids are assigned in the bins of the new base key
+	wantBinIDs := make(map[uint8]uint64)
+	for _, ch := range append(uploaded, requested...) {
+		wantBinIDs[swarm.Proximity(newBaseKey, ch.Address().Bytes())]++
+	}
+	for po := uint8(0); po <= swarm.MaxPO; po++ {
+		got, err := db.binIDs.Get(uint64(po))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != wantBinIDs[po] {
+			t.Errorf("bin %d: got bin id %d, want %d", po, got, wantBinIDs[po])
+		}
+	}
+}
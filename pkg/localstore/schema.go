@@ -25,3 +25,8 @@ const DbSchemaNone = ""
 
 // DbSchemaCode is the first bee schema identifier
 const DbSchemaCode = "code"
+
+// SchemaName returns the name of the schema of the database.
+func (db *DB) SchemaName() (string, error) {
+	return db.schemaName.Get()
+}
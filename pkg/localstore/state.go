@@ -0,0 +1,72 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// ChunkState is the presence of a chunk in the indexes of the database.
+type ChunkState struct {
+	// Stored is true if the chunk data is in the retrieval index, and all
+	// other fields are zero if it is false.
+	Stored      bool
+	InPushIndex bool
+	InPullIndex bool
+	InGCIndex   bool
+	// PinCounter is the number of times the chunk is pinned.
+	PinCounter uint64
+}
+
+// ChunkState returns the presence of the chunk with the address in the
+// indexes of the database, so that the effects of the storer operations can
+// be inspected by the tests of the packages that use it.
+func (db *DB) ChunkState(addr swarm.Address) (s ChunkState, err error) {
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	item, err := db.retrievalDataIndex.Get(addressToItem(addr))
+	if err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return s, nil
+		}
+		return s, err
+	}
+	s.Stored = true
+
+	if s.InPushIndex, err = db.pushIndex.Has(item); err != nil {
+		return s, err
+	}
+	if s.InPullIndex, err = db.pullIndex.Has(item); err != nil {
+		return s, err
+	}
+
+	i, err := db.retrievalAccessIndex.Get(item)
+	switch {
+	case err == nil:
+		item.AccessTimestamp = i.AccessTimestamp
+		if s.InGCIndex, err = db.gcIndex.Has(item); err != nil {
+			return s, err
+		}
+	case errors.Is(err, leveldb.ErrNotFound):
+		// the chunk is not accessed
+	default:
+		return s, err
+	}
+
+	p, err := db.pinIndex.Get(shed.Item{Address: item.Address})
+	switch {
+	case err == nil:
+		s.PinCounter = p.PinCounter
+	case errors.Is(err, leveldb.ErrNotFound):
+	default:
+		return s, err
+	}
+	return s, nil
+}
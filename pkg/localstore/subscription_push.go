@@ -17,6 +17,7 @@
 package localstore
 
 import (
+	"bytes"
 	"context"
 	"sync"
 	"time"
@@ -29,18 +30,20 @@ import (
 // Returned stop function will terminate current and further iterations, and also it will close
 // the returned channel without any errors. Make sure that you check the second returned parameter
 // from the channel to stop iteration when its value is false.
+// The subscription continues from the last sent chunk as new chunks are added to
+// the push index, and starts from the beginning only if a chunk is added before it.
 func (db *DB) SubscribePush(ctx context.Context) (c <-chan swarm.Chunk, stop func()) {
 	db.metrics.SubscribePush.Inc()
 
 	chunks := make(chan swarm.Chunk)
-	trigger := make(chan struct{}, 1)
+	trigger := newPushTrigger()
 
 	db.pushTriggersMu.Lock()
 	db.pushTriggers = append(db.pushTriggers, trigger)
 	db.pushTriggersMu.Unlock()
 
 	// send signal for the initial iteration
-	trigger <- struct{}{}
+	trigger.c <- struct{}{}
 
 	stopChan := make(chan struct{})
 	var stopChanOnce sync.Once
@@ -57,7 +60,12 @@ func (db *DB) SubscribePush(ctx context.Context) (c <-chan swarm.Chunk, stop fun
 		var sinceItem *shed.Item
 		for {
 			select {
-			case <-trigger:
+			case <-trigger.c:
+				// start from the first Item if new items were
+				// added before the last sent one
+				if trigger.rewound() {
+					sinceItem = nil
+				}
 				// iterate until:
 				// - last index Item is reached
 				// - subscription stop is called
@@ -79,6 +87,7 @@ func (db *DB) SubscribePush(ctx context.Context) (c <-chan swarm.Chunk, stop fun
 						// set next iteration start item
 						// when its chunk is successfully sent to channel
 						sinceItem = &item
+						trigger.setCursor(item)
 						return false, nil
 					case <-stopChan:
 						// gracefully stop the iteration
@@ -144,15 +153,71 @@ func (db *DB) SubscribePush(ctx context.Context) (c <-chan swarm.Chunk, stop fun
 
 // triggerPushSubscriptions is used internally for starting iterations
 // on Push subscriptions. Whenever new item is added to the push index,
-// this function should be called.
-func (db *DB) triggerPushSubscriptions() {
+// this function should be called with the lowest added item.
+func (db *DB) triggerPushSubscriptions(lowest shed.Item) {
 	db.pushTriggersMu.RLock()
 	defer db.pushTriggersMu.RUnlock()
 
 	for _, t := range db.pushTriggers {
-		select {
-		case t <- struct{}{}:
-		default:
-		}
+		t.trigger(lowest)
+	}
+}
+
+// pushTrigger signals a push subscription to continue iterating from the
+// last item that it sent, or from the beginning of the push index if a new
+// item was added before that item.
+type pushTrigger struct {
+	c      chan struct{}
+	cursor *shed.Item // the last item sent by the subscription
+	rewind bool       // an item was added before the cursor
+	mu     sync.Mutex
+}
+
+func newPushTrigger() *pushTrigger {
+	return &pushTrigger{
+		c: make(chan struct{}, 1),
+	}
+}
+
+func (t *pushTrigger) trigger(lowest shed.Item) {
+	t.mu.Lock()
+	if t.cursor != nil && pushIndexLess(lowest, *t.cursor) {
+		t.rewind = true
+	}
+	t.mu.Unlock()
+
+	select {
+	case t.c <- struct{}{}:
+	default:
+	}
+}
+
+func (t *pushTrigger) setCursor(item shed.Item) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cursor = &item
+}
+
+// rewound reports whether the iteration should start from the beginning
+// and resets the cursor if it should.
+func (t *pushTrigger) rewound() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.rewind {
+		return false
+	}
+	t.rewind = false
+	t.cursor = nil
+	return true
+}
+
+// pushIndexLess reports whether the item a is before the item b
+// in the push index, which is ordered by store timestamp and address.
+func pushIndexLess(a, b shed.Item) bool {
+	if a.StoreTimestamp != b.StoreTimestamp {
+		return a.StoreTimestamp < b.StoreTimestamp
 	}
+	return bytes.Compare(a.Address, b.Address) < 0
 }
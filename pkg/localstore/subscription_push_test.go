@@ -203,3 +203,46 @@ func TestDB_SubscribePush_multiple(t *testing.T) {
 
 	checkErrChan(ctx, t, errChan, wantedChunksCount)
 }
+
+// TestDB_SubscribePush_rewind validates that the push syncing subscription
+// starts from the beginning of the push index when a chunk is added before
+// the last chunk that it sent.
+func TestDB_SubscribePush_rewind(t *testing.T) {
+	db := newTestDB(t, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ch, stop := db.SubscribePush(ctx)
+	defer stop()
+
+	upload := func(timestamp int64) swarm.Chunk {
+		t.Helper()
+
+		defer setNow(func() int64 { return timestamp })()
+
+		chunk := generateTestRandomChunk()
+		if _, err := db.Put(context.Background(), storage.ModePutUpload, chunk); err != nil {
+			t.Fatal(err)
+		}
+		return chunk
+	}
+
+	receive := func(want swarm.Chunk) {
+		t.Helper()
+
+		select {
+		case got := <-ch:
+			if !got.Address().Equal(want.Address()) {
+				t.Fatalf("got chunk %s, want %s", got.Address(), want.Address())
+			}
+		case <-ctx.Done():
+			t.Fatalf("chunk %s not received: %v", want.Address(), ctx.Err())
+		}
+	}
+
+	receive(upload(20))
+
+	// the chunk is stored with an older timestamp than the received one
+	receive(upload(10))
+}
@@ -5,6 +5,7 @@
 package logging
 
 import (
+	"bufio"
 	"net"
 	"net/http"
 	"time"
@@ -95,6 +96,10 @@ func (l *responseLogger) CloseNotify() <-chan bool {
 	return l.w.(http.CloseNotifier).CloseNotify() // skipcq: SCC-SA1019
 }
 
+func (l *responseLogger) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return l.w.(http.Hijacker).Hijack()
+}
+
 func (l *responseLogger) Push(target string, opts *http.PushOptions) error {
 	return l.w.(http.Pusher).Push(target, opts)
 }
@@ -0,0 +1,146 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logging
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// levels are the levels of the lines of the components. The level of the
+// logrus logger is the most verbose of them, so that the lines of all levels
+// are logged, and the hooks and the formatter drop the lines that are not
+// enabled for their components.
+type levels struct {
+	logger    *logrus.Logger
+	base      logrus.Level
+	overrides map[string]logrus.Level
+	mu        sync.RWMutex
+}
+
+func newLevels(logger *logrus.Logger) *levels {
+	return &levels{
+		logger:    logger,
+		overrides: make(map[string]logrus.Level),
+	}
+}
+
+func (l *levels) setBase(level logrus.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.base = level
+	l.updateLogger()
+}
+
+func (l *levels) getBase() logrus.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.base
+}
+
+func (l *levels) set(component string, level logrus.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.overrides[component] = level
+	l.updateLogger()
+}
+
+func (l *levels) remove(component string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.overrides, component)
+	l.updateLogger()
+}
+
+func (l *levels) components() map[string]logrus.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	c := make(map[string]logrus.Level, len(l.overrides))
+	for component, level := range l.overrides {
+		c[component] = level
+	}
+	return c
+}
+
+// enabled returns true if the lines of the level are logged for the
+// component.
+func (l *levels) enabled(component string, level logrus.Level) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if override, ok := l.overrides[component]; ok {
+		return level <= override
+	}
+	return level <= l.base
+}
+
+// updateLogger sets the level of the logrus logger to the most verbose
+// level. It must be called with the lock held.
+func (l *levels) updateLogger() {
+	max := l.base
+	for _, level := range l.overrides {
+		if level > max {
+			max = level
+		}
+	}
+	l.logger.SetLevel(max)
+}
+
+// formatter formats only the lines that are enabled for their components.
+type formatter struct {
+	logrus.Formatter
+	levels *levels
+	// addComponent adds the component field to the lines that do not have
+	// it, with the subsystem of the message.
+	addComponent bool
+}
+
+func (f *formatter) Format(e *logrus.Entry) ([]byte, error) {
+	component, hasComponent := entryComponent(e)
+	if !f.levels.enabled(component, e.Level) {
+		return nil, nil
+	}
+	if f.addComponent && !hasComponent && component != "" {
+		c := *e
+		c.Data = make(logrus.Fields, len(e.Data)+1)
+		for k, v := range e.Data {
+			c.Data[k] = v
+		}
+		c.Data[FieldComponent] = component
+		e = &c
+	}
+	return f.Formatter.Format(e)
+}
+
+// levelHook fires the hook only for the lines that are enabled for their
+// components.
+type levelHook struct {
+	logrus.Hook
+	levels *levels
+}
+
+func (h *levelHook) Fire(e *logrus.Entry) error {
+	component, _ := entryComponent(e)
+	if !h.levels.enabled(component, e.Level) {
+		return nil
+	}
+	return h.Hook.Fire(e)
+}
+
+// entryComponent returns the component of the line, which is its component
+// field, or the subsystem of its message if the field is not set.
+func entryComponent(e *logrus.Entry) (component string, hasComponent bool) {
+	if v, ok := e.Data[FieldComponent]; ok {
+		return fmt.Sprint(v), true
+	}
+	return subsystem(e.Message), false
+}
@@ -5,6 +5,7 @@
 package logging
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/sirupsen/logrus"
@@ -23,30 +24,186 @@ type Logger interface {
 	Error(args ...interface{})
 	WithField(key string, value interface{}) *logrus.Entry
 	WithFields(fields logrus.Fields) *logrus.Entry
+	// WithValues returns the logger that adds the fields of the key and
+	// value pairs to all lines that it logs.
+	WithValues(keysAndValues ...interface{}) Logger
 	WriterLevel(logrus.Level) *io.PipeWriter
 	NewEntry() *logrus.Entry
+	AddHook(logrus.Hook)
+	SetLevel(logrus.Level)
+	GetLevel() logrus.Level
+	// SetComponentLevel overrides the level of the logger for the lines
+	// of the component, which is the component field of the line, or the
+	// subsystem of its message if the field is not set.
+	SetComponentLevel(component string, level logrus.Level)
+	// RemoveComponentLevel removes the override of the level of the
+	// component.
+	RemoveComponentLevel(component string)
+	// ComponentLevels returns the overridden levels by the components.
+	ComponentLevels() map[string]logrus.Level
+}
+
+// The names of the fields of the log lines that are shared by the
+// components.
+const (
+	FieldComponent = "component"
+	FieldPeer      = "peer"
+	FieldChunk     = "chunk"
+)
+
+// Format is the format of the log lines.
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat returns the format of its name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatText, FormatJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q", s)
+	}
 }
 
 type logger struct {
 	*logrus.Logger
 	metrics metrics
+	levels  *levels
 }
 
+// New returns the logger that writes the lines of the level, or of the more
+// severe ones, as text.
 func New(w io.Writer, level logrus.Level) Logger {
+	return NewWithFormat(w, level, FormatText)
+}
+
+// NewWithFormat returns the logger that writes the lines of the level, or of
+// the more severe ones, in the format. The JSON lines include the component
+// field, with the subsystem of the message if it is not set.
+func NewWithFormat(w io.Writer, level logrus.Level, format Format) Logger {
 	l := logrus.New()
 	l.SetOutput(w)
-	l.SetLevel(level)
-	l.Formatter = &logrus.TextFormatter{
-		FullTimestamp: true,
+	levels := newLevels(l)
+	f := &formatter{
+		Formatter: &logrus.TextFormatter{
+			FullTimestamp: true,
+		},
+		levels: levels,
+	}
+	if format == FormatJSON {
+		f.Formatter = new(logrus.JSONFormatter)
+		f.addComponent = true
 	}
+	l.Formatter = f
 	metrics := newMetrics()
-	l.AddHook(metrics)
-	return &logger{
+	levels.setBase(level)
+	lg := &logger{
 		Logger:  l,
 		metrics: metrics,
+		levels:  levels,
 	}
+	lg.AddHook(metrics)
+	return lg
 }
 
 func (l *logger) NewEntry() *logrus.Entry {
 	return logrus.NewEntry(l.Logger)
 }
+
+func (l *logger) WithValues(keysAndValues ...interface{}) Logger {
+	return &entryLogger{
+		Entry:  logrus.NewEntry(l.Logger).WithFields(valuesFields(keysAndValues)),
+		logger: l,
+	}
+}
+
+// AddHook adds the hook that is fired only for the lines that are enabled
+// for their components.
+func (l *logger) AddHook(hook logrus.Hook) {
+	l.Logger.AddHook(&levelHook{Hook: hook, levels: l.levels})
+}
+
+// SetLevel sets the level of the lines of the components that do not
+// override it.
+func (l *logger) SetLevel(level logrus.Level) {
+	l.levels.setBase(level)
+}
+
+// GetLevel returns the level of the lines of the components that do not
+// override it.
+func (l *logger) GetLevel() logrus.Level {
+	return l.levels.getBase()
+}
+
+func (l *logger) SetComponentLevel(component string, level logrus.Level) {
+	l.levels.set(component, level)
+}
+
+func (l *logger) RemoveComponentLevel(component string) {
+	l.levels.remove(component)
+}
+
+func (l *logger) ComponentLevels() map[string]logrus.Level {
+	return l.levels.components()
+}
+
+// entryLogger is the logger returned by WithValues, which adds the fields to
+// all lines that it logs with the logger that it is derived from.
+type entryLogger struct {
+	*logrus.Entry
+	logger *logger
+}
+
+func (l *entryLogger) WithValues(keysAndValues ...interface{}) Logger {
+	return &entryLogger{
+		Entry:  l.Entry.WithFields(valuesFields(keysAndValues)),
+		logger: l.logger,
+	}
+}
+
+// NewEntry returns the entry with the fields of the logger.
+func (l *entryLogger) NewEntry() *logrus.Entry {
+	return l.Entry.WithFields(nil)
+}
+
+func (l *entryLogger) AddHook(hook logrus.Hook) {
+	l.logger.AddHook(hook)
+}
+
+func (l *entryLogger) SetLevel(level logrus.Level) {
+	l.logger.SetLevel(level)
+}
+
+func (l *entryLogger) GetLevel() logrus.Level {
+	return l.logger.GetLevel()
+}
+
+func (l *entryLogger) SetComponentLevel(component string, level logrus.Level) {
+	l.logger.SetComponentLevel(component, level)
+}
+
+func (l *entryLogger) RemoveComponentLevel(component string) {
+	l.logger.RemoveComponentLevel(component)
+}
+
+func (l *entryLogger) ComponentLevels() map[string]logrus.Level {
+	return l.logger.ComponentLevels()
+}
+
+// valuesFields returns the fields of the key and value pairs. The value of
+// the key without a pair is nil.
+func valuesFields(keysAndValues []interface{}) logrus.Fields {
+	fields := make(logrus.Fields, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value interface{}
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		fields[fmt.Sprint(keysAndValues[i])] = value
+	}
+	return fields
+}
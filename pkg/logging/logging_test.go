@@ -0,0 +1,114 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/sirupsen/logrus"
+)
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.NewWithFormat(&buf, logrus.InfoLevel, logging.FormatJSON)
+
+	logger.WithValues(logging.FieldPeer, "b0baf377").Info("pushsync: chunk stored")
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]string{
+		"level":                "info",
+		"msg":                  "pushsync: chunk stored",
+		logging.FieldComponent: "pushsync",
+		logging.FieldPeer:      "b0baf377",
+	} {
+		if got := line[k]; got != want {
+			t.Errorf("got %s %v, want %q", k, got, want)
+		}
+	}
+}
+
+func TestComponentLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, logrus.InfoLevel)
+
+	logger.SetComponentLevel("pushsync", logrus.DebugLevel)
+	logger.SetComponentLevel("retrieval", logrus.ErrorLevel)
+
+	logger.Debug("pushsync: debug line")
+	logger.WithValues(logging.FieldComponent, "pushsync").Debug("debug line with the component field")
+	logger.Debug("pullsync: debug line")
+	logger.Info("retrieval: info line")
+	logger.Info("pullsync: info line")
+
+	got := buf.String()
+	for _, want := range []string{"pushsync: debug line", "debug line with the component field", "pullsync: info line"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("line %q not logged", want)
+		}
+	}
+	for _, notWant := range []string{"pullsync: debug line", "retrieval: info line"} {
+		if strings.Contains(got, notWant) {
+			t.Errorf("line %q logged", notWant)
+		}
+	}
+	if l := logger.GetLevel(); l != logrus.InfoLevel {
+		t.Errorf("got level %v, want %v", l, logrus.InfoLevel)
+	}
+
+	logger.RemoveComponentLevel("pushsync")
+	buf.Reset()
+	logger.Debug("pushsync: debug line")
+	if buf.Len() != 0 {
+		t.Errorf("got line %q after the component level is removed", buf.String())
+	}
+	if levels := logger.ComponentLevels(); len(levels) != 1 || levels["retrieval"] != logrus.ErrorLevel {
+		t.Errorf("got component levels %v", levels)
+	}
+}
+
+// TestComponentLevelsHooks checks that the hooks are fired only for the lines
+// that are enabled for their components.
+func TestComponentLevelsHooks(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, logrus.InfoLevel)
+	logger.SetComponentLevel("pushsync", logrus.TraceLevel)
+
+	hook := new(recordHook)
+	logger.AddHook(hook)
+
+	logger.Trace("pushsync: trace line")
+	logger.Trace("pullsync: trace line")
+	logger.Info("pullsync: info line")
+
+	want := []string{"pushsync: trace line", "pullsync: info line"}
+	if len(hook.messages) != len(want) {
+		t.Fatalf("got hook messages %q, want %q", hook.messages, want)
+	}
+	for i, m := range want {
+		if hook.messages[i] != m {
+			t.Errorf("got hook message %q, want %q", hook.messages[i], m)
+		}
+	}
+}
+
+type recordHook struct {
+	messages []string
+}
+
+func (h *recordHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *recordHook) Fire(e *logrus.Entry) error {
+	h.messages = append(h.messages, e.Message)
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Line is a log line that is sent to the subscribers of the Stream.
+type Line struct {
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	// Subsystem is the part of the message before the first colon, which
+	// by convention names the component that logged it.
+	Subsystem string            `json:"subsystem,omitempty"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// streamBufferSize is the number of log lines buffered for every subscriber.
+// Lines are dropped for subscribers that do not keep up.
+const streamBufferSize = 256
+
+var _ logrus.Hook = (*Stream)(nil)
+
+// Stream is the logrus hook that sends the logged lines to its subscribers.
+// Only the lines that are enabled by the level of the logger are sent.
+type Stream struct {
+	subscribers map[*streamSubscriber]struct{}
+	mu          sync.Mutex
+}
+
+type streamSubscriber struct {
+	c         chan Line
+	level     logrus.Level
+	subsystem string
+}
+
+// NewStream returns a new Stream which receives the log lines once it is
+// added as a hook to the logger.
+func NewStream() *Stream {
+	return &Stream{
+		subscribers: make(map[*streamSubscriber]struct{}),
+	}
+}
+
+// Levels implements the logrus.Hook interface.
+func (s *Stream) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements the logrus.Hook interface. It sends the line to the
+// subscribers without blocking.
+func (s *Stream) Fire(e *logrus.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.subscribers) == 0 {
+		return nil
+	}
+
+	line := Line{
+		Time:    e.Time,
+		Level:   e.Level.String(),
+		Message: e.Message,
+	}
+	line.Subsystem = subsystem(e.Message)
+	if len(e.Data) > 0 {
+		line.Fields = make(map[string]string, len(e.Data))
+		for k, v := range e.Data {
+			line.Fields[k] = fmt.Sprint(v)
+		}
+	}
+
+	for sub := range s.subscribers {
+		if e.Level > sub.level {
+			continue
+		}
+		if sub.subsystem != "" && sub.subsystem != line.Subsystem {
+			continue
+		}
+		select {
+		case sub.c <- line:
+		default:
+		}
+	}
+	return nil
+}
+
+// subsystem returns the part of the message before the first colon.
+func subsystem(message string) string {
+	if i := strings.Index(message, ":"); i > 0 {
+		return message[:i]
+	}
+	return ""
+}
+
+// Subscribe returns the channel on which the log lines of the level or of
+// the more severe ones are received. If the subsystem is not empty, only the
+// lines of that subsystem are received. Returned function is safe to be
+// called multiple times.
+func (s *Stream) Subscribe(level logrus.Level, subsystem string) (c <-chan Line, unsubscribe func()) {
+	sub := &streamSubscriber{
+		c:         make(chan Line, streamBufferSize),
+		level:     level,
+		subsystem: subsystem,
+	}
+	var closeOnce sync.Once
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscribers[sub] = struct{}{}
+
+	unsubscribe = func() {
+		closeOnce.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			delete(s.subscribers, sub)
+			close(sub.c)
+		})
+	}
+
+	return sub.c, unsubscribe
+}
@@ -0,0 +1,192 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jsonmanifest provides the manifest that is serialized as a JSON
+// object of its entries.
+package jsonmanifest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ manifest.Interface = (*JSONManifest)(nil)
+
+// JSONManifest is a manifest whose entries are serialized as a JSON object
+// keyed by their paths.
+type JSONManifest struct {
+	entriesMu sync.RWMutex
+	entries   map[string]*JSONEntry
+	metadata  map[string]string
+}
+
+// NewManifest creates a new empty JSONManifest.
+func NewManifest() *JSONManifest {
+	return &JSONManifest{
+		entries:  make(map[string]*JSONEntry),
+		metadata: make(map[string]string),
+	}
+}
+
+// Add implements manifest.Interface.
+func (m *JSONManifest) Add(path string, entry manifest.Entry) {
+	m.entriesMu.Lock()
+	defer m.entriesMu.Unlock()
+
+	m.entries[path] = &JSONEntry{
+		Ref:         entry.Reference(),
+		FileName:    entry.Name(),
+		Type:        entry.ContentType(),
+		HTTPHeaders: entry.Headers(),
+	}
+}
+
+// Remove implements manifest.Interface.
+func (m *JSONManifest) Remove(path string) {
+	m.entriesMu.Lock()
+	defer m.entriesMu.Unlock()
+
+	delete(m.entries, path)
+}
+
+// Lookup implements manifest.Interface.
+func (m *JSONManifest) Lookup(path string) (manifest.Entry, error) {
+	m.entriesMu.RLock()
+	defer m.entriesMu.RUnlock()
+
+	entry, ok := m.entries[path]
+	if !ok {
+		return nil, manifest.ErrNotFound
+	}
+	return entry, nil
+}
+
+// Length implements manifest.Interface.
+func (m *JSONManifest) Length() int {
+	m.entriesMu.RLock()
+	defer m.entriesMu.RUnlock()
+
+	return len(m.entries)
+}
+
+// SetMetadata implements manifest.Interface.
+func (m *JSONManifest) SetMetadata(key, value string) {
+	m.entriesMu.Lock()
+	defer m.entriesMu.Unlock()
+
+	if value == "" {
+		delete(m.metadata, key)
+		return
+	}
+	m.metadata[key] = value
+}
+
+// Metadata implements manifest.Interface.
+func (m *JSONManifest) Metadata(key string) string {
+	m.entriesMu.RLock()
+	defer m.entriesMu.RUnlock()
+
+	return m.metadata[key]
+}
+
+// Walk implements manifest.Interface.
+func (m *JSONManifest) Walk(fn manifest.WalkFunc) error {
+	m.entriesMu.RLock()
+	paths := make([]string, 0, len(m.entries))
+	entries := make(map[string]*JSONEntry, len(m.entries))
+	for p, e := range m.entries {
+		paths = append(paths, p)
+		entries[p] = e
+	}
+	m.entriesMu.RUnlock()
+
+	// the function is called without the lock, so that it may change the
+	// manifest
+	sort.Strings(paths)
+	for _, p := range paths {
+		if err := fn(p, entries[p]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m *JSONManifest) MarshalBinary() ([]byte, error) {
+	m.entriesMu.RLock()
+	defer m.entriesMu.RUnlock()
+
+	return json.Marshal(jsonManifest{Entries: m.entries, Metadata: m.metadata})
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *JSONManifest) UnmarshalBinary(b []byte) error {
+	var v jsonManifest
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v.Entries == nil {
+		v.Entries = make(map[string]*JSONEntry)
+	}
+	if v.Metadata == nil {
+		v.Metadata = make(map[string]string)
+	}
+
+	m.entriesMu.Lock()
+	defer m.entriesMu.Unlock()
+
+	m.entries = v.Entries
+	m.metadata = v.Metadata
+	return nil
+}
+
+// jsonManifest is the serialized form of the JSONManifest.
+type jsonManifest struct {
+	Entries  map[string]*JSONEntry `json:"entries"`
+	Metadata map[string]string     `json:"metadata,omitempty"`
+}
+
+// JSONEntry is a manifest entry that is serialized as a JSON object.
+type JSONEntry struct {
+	Ref         swarm.Address `json:"reference"`
+	FileName    string        `json:"name"`
+	Type        string        `json:"contentType,omitempty"`
+	HTTPHeaders http.Header   `json:"headers,omitempty"`
+}
+
+// NewEntry creates a new JSONEntry of the file entry reference, the file
+// name, the content type and the custom headers of the file.
+func NewEntry(reference swarm.Address, name, contentType string, headers http.Header) *JSONEntry {
+	return &JSONEntry{
+		Ref:         reference,
+		FileName:    name,
+		Type:        contentType,
+		HTTPHeaders: headers,
+	}
+}
+
+// Reference implements manifest.Entry.
+func (e *JSONEntry) Reference() swarm.Address {
+	return e.Ref
+}
+
+// Name implements manifest.Entry.
+func (e *JSONEntry) Name() string {
+	return e.FileName
+}
+
+// ContentType implements manifest.Entry.
+func (e *JSONEntry) ContentType() string {
+	return e.Type
+}
+
+// Headers implements manifest.Entry.
+func (e *JSONEntry) Headers() http.Header {
+	return e.HTTPHeaders
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonmanifest_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/manifest/jsonmanifest"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestManifest(t *testing.T) {
+	m := jsonmanifest.NewManifest()
+
+	entries := map[string]*jsonmanifest.JSONEntry{
+		"index.html":     jsonmanifest.NewEntry(swarm.MustParseHexAddress("aa"), "index.html", "text/html; charset=utf-8", http.Header{"Cache-Control": {"no-cache"}}),
+		"img/logo.png":   jsonmanifest.NewEntry(swarm.MustParseHexAddress("bb"), "logo.png", "image/png", nil),
+		"img/banner.png": jsonmanifest.NewEntry(swarm.MustParseHexAddress("cc"), "banner.png", "image/png", nil),
+	}
+	for path, e := range entries {
+		m.Add(path, e)
+	}
+	m.Remove("img/banner.png")
+	delete(entries, "img/banner.png")
+	m.SetMetadata(manifest.IndexDocumentKey, "index.html")
+
+	if m.Length() != len(entries) {
+		t.Fatalf("got length %d, want %d", m.Length(), len(entries))
+	}
+
+	b, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	um := jsonmanifest.NewManifest()
+	if err := um.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if um.Length() != len(entries) {
+		t.Fatalf("got unmarshaled length %d, want %d", um.Length(), len(entries))
+	}
+	if got := um.Metadata(manifest.IndexDocumentKey); got != "index.html" {
+		t.Errorf("got index document %q, want %q", got, "index.html")
+	}
+	for path, want := range entries {
+		got, err := um.Lookup(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Reference().Equal(want.Reference()) {
+			t.Errorf("got reference %s of %q, want %s", got.Reference(), path, want.Reference())
+		}
+		if got.Name() != want.Name() {
+			t.Errorf("got name %q of %q, want %q", got.Name(), path, want.Name())
+		}
+		if got.ContentType() != want.ContentType() {
+			t.Errorf("got content type %q of %q, want %q", got.ContentType(), path, want.ContentType())
+		}
+		if !reflect.DeepEqual(got.Headers(), want.Headers()) {
+			t.Errorf("got headers %v of %q, want %v", got.Headers(), path, want.Headers())
+		}
+	}
+
+	var paths []string
+	if err := um.Walk(func(path string, _ manifest.Entry) error {
+		paths = append(paths, path)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	wantPaths := []string{"img/logo.png", "index.html"}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("got paths %v, want %v", paths, wantPaths)
+	}
+
+	if _, err := um.Lookup("img/banner.png"); !errors.Is(err, manifest.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, manifest.ErrNotFound)
+	}
+}
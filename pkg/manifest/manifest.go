@@ -0,0 +1,105 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package manifest provides the mapping of the paths of the files in a
+// collection to the references of their entries, so that the collection can
+// be addressed by a single reference.
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	// IndexDocumentKey is the metadata key of the name of the document that
+	// is served for the paths of the directories of a website.
+	IndexDocumentKey = "website-index-document"
+	// ErrorDocumentKey is the metadata key of the path of the document that
+	// is served for the paths of a website that are not in the manifest.
+	ErrorDocumentKey = "website-error-document"
+)
+
+var (
+	// ErrNotFound is returned when the manifest has no entry with the path.
+	ErrNotFound = errors.New("manifest: not found")
+	// ErrInvalidManifest is returned by Load when the data under the
+	// reference is not a manifest.
+	ErrInvalidManifest = errors.New("manifest: invalid")
+)
+
+// Interface for operations with manifest.
+type Interface interface {
+	// Add adds the entry to the manifest under the path, replacing the entry
+	// that is already there.
+	Add(path string, entry Entry)
+	// Remove removes the entry under the path from the manifest.
+	Remove(path string)
+	// Lookup returns the entry under the path.
+	Lookup(path string) (Entry, error)
+	// Length returns the number of entries in the manifest.
+	Length() int
+	// SetMetadata sets the value of the metadata key of the manifest. The
+	// key is removed if the value is empty.
+	SetMetadata(key, value string)
+	// Metadata returns the value of the metadata key of the manifest, which
+	// is empty if it is not set.
+	Metadata(key string) string
+	// Walk calls the function for every entry in the manifest in
+	// lexicographical order of their paths. The walk stops at the first
+	// error returned by the function, which is returned by Walk.
+	Walk(fn WalkFunc) error
+
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// WalkFunc is the function called by Walk for every entry of the manifest.
+type WalkFunc func(path string, entry Entry) error
+
+// Entry represents a single file in the manifest.
+type Entry interface {
+	// Reference returns the reference of the file entry.
+	Reference() swarm.Address
+	// Name returns the name of the file.
+	Name() string
+	// ContentType returns the content type of the file, which is empty if
+	// it is not set in the manifest.
+	ContentType() string
+	// Headers returns the custom headers that are sent with the file.
+	Headers() http.Header
+}
+
+// Store stores the serialized manifest with the splitter and returns the
+// reference of the manifest.
+func Store(ctx context.Context, s file.Splitter, m Interface, toEncrypt bool) (swarm.Address, error) {
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("manifest marshal: %w", err)
+	}
+	reference, err := file.SplitWriteAll(ctx, s, bytes.NewReader(b), int64(len(b)), toEncrypt)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("manifest store: %w", err)
+	}
+	return reference, nil
+}
+
+// Load reads the manifest with the reference with the joiner into m.
+func Load(j file.Joiner, reference swarm.Address, m Interface, toDecrypt bool) error {
+	buf := bytes.NewBuffer(nil)
+	if _, err := file.JoinReadAll(j, reference, buf, toDecrypt); err != nil {
+		return fmt.Errorf("manifest read: %w", err)
+	}
+	if err := m.UnmarshalBinary(buf.Bytes()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package manifest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/manifest/triemanifest"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestStoreLoad(t *testing.T) {
+	for _, toEncrypt := range []bool{false, true} {
+		storer := mock.NewStorer()
+
+		// enough entries for the serialized manifest to span several chunks
+		m := triemanifest.NewManifest()
+		for i := 0; i < 200; i++ {
+			path := strings.Repeat("dir/", i%5) + strings.Repeat("x", i)
+			m.Add(path, triemanifest.NewEntry(swarm.MustParseHexAddress(strings.Repeat("aa", swarm.HashSize)), "x", "text/plain", nil))
+		}
+
+		reference, err := manifest.Store(context.Background(), splitter.NewSimpleSplitter(storer), m, toEncrypt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lm := triemanifest.NewManifest()
+		if err := manifest.Load(joiner.NewSimpleJoiner(storer), reference, lm, toEncrypt); err != nil {
+			t.Fatal(err)
+		}
+		if lm.Length() != m.Length() {
+			t.Fatalf("got length %d, want %d", lm.Length(), m.Length())
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	storer := mock.NewStorer()
+	data := "not a manifest"
+	reference, err := file.SplitWriteAll(context.Background(), splitter.NewSimpleSplitter(storer), strings.NewReader(data), int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = manifest.Load(joiner.NewSimpleJoiner(storer), reference, triemanifest.NewManifest(), false)
+	if !errors.Is(err, manifest.ErrInvalidManifest) {
+		t.Fatalf("got error %v, want %v", err, manifest.ErrInvalidManifest)
+	}
+}
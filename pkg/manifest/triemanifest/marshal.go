@@ -0,0 +1,308 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package triemanifest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/ethersphere/bee/pkg/encryption"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// The serialized manifest starts with the version byte, followed by the
+// metadata of the manifest and the nodes of the trie in depth-first order.
+// The metadata is serialized as the uvarint count of the keys, each followed
+// by its value, all length-prefixed bytes. A node is serialized as:
+//
+//	flags           1 byte, 1 if the node has an entry
+//	entry           only if the node has one
+//	  reference     length-prefixed bytes
+//	  name          length-prefixed bytes
+//	  content type  length-prefixed bytes
+//	  headers       uvarint count of the header names, each followed by
+//	                the uvarint count of its values and the values, all
+//	                names and values length-prefixed bytes
+//	forks           uvarint count of the forks, each followed by its
+//	                length-prefixed prefix and its node
+//
+// Lengths are encoded as uvarints, metadata keys, headers and forks are
+// ordered, so that equal manifests are serialized to equal data.
+//
+// Manifests of the version 0 have no metadata.
+const version = 1
+
+// maxDepth is the maximal number of nodes on a path from the root of an
+// unmarshaled trie, which limits the recursion on malformed data. A trie
+// this deep needs as many branching or nested paths.
+const maxDepth = 1 << 16
+
+var (
+	errInvalidVersion = errors.New("invalid version")
+	errTruncated      = errors.New("truncated data")
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m *TrieManifest) MarshalBinary() ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var buf bytes.Buffer
+	buf.WriteByte(version)
+	marshalMetadata(&buf, m.metadata)
+	m.root.marshal(&buf)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *TrieManifest) UnmarshalBinary(b []byte) error {
+	if len(b) == 0 {
+		return errTruncated
+	}
+	if b[0] > version {
+		return fmt.Errorf("%w %d", errInvalidVersion, b[0])
+	}
+
+	// the nodes keep parts of the data
+	d := &decoder{data: append([]byte(nil), b[1:]...)}
+	metadata := make(map[string]string)
+	if b[0] > 0 {
+		var err error
+		if metadata, err = d.metadata(); err != nil {
+			return err
+		}
+	}
+	root, err := d.node(0)
+	if err != nil {
+		return err
+	}
+	if len(d.data) > 0 {
+		return fmt.Errorf("%d bytes after the trie", len(d.data))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.root = root
+	m.length = d.entries
+	m.metadata = metadata
+	return nil
+}
+
+func marshalMetadata(buf *bytes.Buffer, metadata map[string]string) {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	writeUvarint(buf, uint64(len(keys)))
+	for _, key := range keys {
+		writeBytes(buf, []byte(key))
+		writeBytes(buf, []byte(metadata[key]))
+	}
+}
+
+func (n *node) marshal(buf *bytes.Buffer) {
+	if n.entry == nil {
+		buf.WriteByte(0)
+	} else {
+		buf.WriteByte(1)
+		n.entry.marshal(buf)
+	}
+
+	writeUvarint(buf, uint64(len(n.forks)))
+	for _, f := range n.sortedForks() {
+		writeBytes(buf, f.prefix)
+		f.node.marshal(buf)
+	}
+}
+
+func (e *TrieEntry) marshal(buf *bytes.Buffer) {
+	writeBytes(buf, e.Ref.Bytes())
+	writeBytes(buf, []byte(e.FileName))
+	writeBytes(buf, []byte(e.Type))
+
+	names := make([]string, 0, len(e.HTTPHeaders))
+	for name := range e.HTTPHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writeUvarint(buf, uint64(len(names)))
+	for _, name := range names {
+		writeBytes(buf, []byte(name))
+		values := e.HTTPHeaders[name]
+		writeUvarint(buf, uint64(len(values)))
+		for _, v := range values {
+			writeBytes(buf, []byte(v))
+		}
+	}
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func writeBytes(buf *bytes.Buffer, b []byte) {
+	writeUvarint(buf, uint64(len(b)))
+	buf.Write(b)
+}
+
+// decoder reads the nodes of the serialized trie and counts their entries.
+type decoder struct {
+	data    []byte
+	entries int
+}
+
+func (d *decoder) metadata() (map[string]string, error) {
+	count, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	metadata := make(map[string]string)
+	for i := uint64(0); i < count; i++ {
+		key, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := metadata[string(key)]; ok {
+			return nil, fmt.Errorf("duplicate metadata key %q", key)
+		}
+		metadata[string(key)] = string(value)
+	}
+	return metadata, nil
+}
+
+func (d *decoder) node(depth int) (*node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("trie deeper than %d", maxDepth)
+	}
+	if len(d.data) == 0 {
+		return nil, errTruncated
+	}
+	flags := d.data[0]
+	d.data = d.data[1:]
+
+	n := newNode()
+	switch flags {
+	case 0:
+	case 1:
+		e, err := d.entry()
+		if err != nil {
+			return nil, err
+		}
+		n.entry = e
+		d.entries++
+	default:
+		return nil, fmt.Errorf("invalid node flags %d", flags)
+	}
+
+	count, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < count; i++ {
+		prefix, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		if len(prefix) == 0 {
+			return nil, errors.New("empty fork prefix")
+		}
+		if _, ok := n.forks[prefix[0]]; ok {
+			return nil, fmt.Errorf("duplicate fork %q", prefix[0])
+		}
+		child, err := d.node(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		n.forks[prefix[0]] = &fork{prefix: prefix, node: child}
+	}
+	if depth > 0 && n.entry == nil && len(n.forks) < 2 {
+		return nil, errors.New("node without entry and with less than two forks")
+	}
+	return n, nil
+}
+
+func (d *decoder) entry() (*TrieEntry, error) {
+	ref, err := d.bytes()
+	if err != nil {
+		return nil, err
+	}
+	if len(ref) != swarm.HashSize && len(ref) != swarm.HashSize+encryption.KeyLength {
+		return nil, fmt.Errorf("invalid reference length %d", len(ref))
+	}
+	fileName, err := d.bytes()
+	if err != nil {
+		return nil, err
+	}
+	contentType, err := d.bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	var headers http.Header
+	if count > 0 {
+		headers = make(http.Header)
+	}
+	for i := uint64(0); i < count; i++ {
+		name, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		valuesCount, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		for j := uint64(0); j < valuesCount; j++ {
+			v, err := d.bytes()
+			if err != nil {
+				return nil, err
+			}
+			headers[string(name)] = append(headers[string(name)], string(v))
+		}
+	}
+
+	return &TrieEntry{
+		Ref:         swarm.NewAddress(ref),
+		FileName:    string(fileName),
+		Type:        string(contentType),
+		HTTPHeaders: headers,
+	}, nil
+}
+
+func (d *decoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	d.data = d.data[n:]
+	return v, nil
+}
+
+func (d *decoder) bytes() ([]byte, error) {
+	l, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if l > uint64(len(d.data)) {
+		return nil, errTruncated
+	}
+	b := d.data[:l:l]
+	d.data = d.data[l:]
+	return b, nil
+}
@@ -0,0 +1,300 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package triemanifest provides the manifest that keeps its entries in a
+// radix trie of their paths and is serialized in a compact binary form.
+package triemanifest
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ manifest.Interface = (*TrieManifest)(nil)
+
+// TrieManifest is a manifest whose entries are kept in a radix trie, where
+// the paths with a common prefix share the nodes of the prefix.
+type TrieManifest struct {
+	mu       sync.RWMutex
+	root     *node
+	length   int
+	metadata map[string]string
+}
+
+// node is a node of the trie. It holds the entry of the path that ends at
+// the node, if there is one, and the forks to the longer paths, keyed by the
+// first byte of their prefixes.
+//
+// Every node but the root either has an entry or at least two forks.
+type node struct {
+	entry *TrieEntry
+	forks map[byte]*fork
+}
+
+// fork is an edge of the trie with the part of the path between the nodes.
+type fork struct {
+	prefix []byte
+	node   *node
+}
+
+// NewManifest creates a new empty TrieManifest.
+func NewManifest() *TrieManifest {
+	return &TrieManifest{
+		root:     newNode(),
+		metadata: make(map[string]string),
+	}
+}
+
+func newNode() *node {
+	return &node{
+		forks: make(map[byte]*fork),
+	}
+}
+
+// Add implements manifest.Interface.
+func (m *TrieManifest) Add(path string, entry manifest.Entry) {
+	e := &TrieEntry{
+		Ref:         entry.Reference(),
+		FileName:    entry.Name(),
+		Type:        entry.ContentType(),
+		HTTPHeaders: entry.Headers().Clone(),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.root.add([]byte(path), e) {
+		m.length++
+	}
+}
+
+// Remove implements manifest.Interface.
+func (m *TrieManifest) Remove(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.root.remove([]byte(path)) {
+		m.length--
+	}
+}
+
+// Lookup implements manifest.Interface.
+func (m *TrieManifest) Lookup(path string) (manifest.Entry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e := m.root.lookup([]byte(path))
+	if e == nil {
+		return nil, manifest.ErrNotFound
+	}
+	return e, nil
+}
+
+// Length implements manifest.Interface.
+func (m *TrieManifest) Length() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.length
+}
+
+// SetMetadata implements manifest.Interface.
+func (m *TrieManifest) SetMetadata(key, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if value == "" {
+		delete(m.metadata, key)
+		return
+	}
+	m.metadata[key] = value
+}
+
+// Metadata implements manifest.Interface.
+func (m *TrieManifest) Metadata(key string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.metadata[key]
+}
+
+// Walk implements manifest.Interface.
+func (m *TrieManifest) Walk(fn manifest.WalkFunc) error {
+	type pathEntry struct {
+		path  string
+		entry *TrieEntry
+	}
+
+	m.mu.RLock()
+	entries := make([]pathEntry, 0, m.length)
+	m.root.walk(nil, func(path []byte, e *TrieEntry) {
+		entries = append(entries, pathEntry{path: string(path), entry: e})
+	})
+	m.mu.RUnlock()
+
+	// the function is called without the lock, so that it may change the
+	// manifest
+	for _, pe := range entries {
+		if err := fn(pe.path, pe.entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add adds the entry under the path relative to the node and reports
+// whether there was no entry under the path before.
+func (n *node) add(path []byte, e *TrieEntry) bool {
+	if len(path) == 0 {
+		isNew := n.entry == nil
+		n.entry = e
+		return isNew
+	}
+
+	f, ok := n.forks[path[0]]
+	if !ok {
+		child := newNode()
+		child.entry = e
+		n.forks[path[0]] = &fork{prefix: path, node: child}
+		return true
+	}
+
+	c := commonPrefixLength(f.prefix, path)
+	if c < len(f.prefix) {
+		// split the fork at the end of the common prefix
+		mid := newNode()
+		mid.forks[f.prefix[c]] = &fork{prefix: f.prefix[c:], node: f.node}
+		f.prefix, f.node = f.prefix[:c], mid
+	}
+	return f.node.add(path[c:], e)
+}
+
+// remove removes the entry under the path relative to the node and reports
+// whether there was one.
+func (n *node) remove(path []byte) bool {
+	if len(path) == 0 {
+		if n.entry == nil {
+			return false
+		}
+		n.entry = nil
+		return true
+	}
+
+	f, ok := n.forks[path[0]]
+	if !ok || !bytes.HasPrefix(path, f.prefix) {
+		return false
+	}
+	if !f.node.remove(path[len(f.prefix):]) {
+		return false
+	}
+
+	// a node that is left without an entry is dropped if it has no forks
+	// and merged with its fork if it has only one
+	if f.node.entry == nil {
+		switch len(f.node.forks) {
+		case 0:
+			delete(n.forks, path[0])
+		case 1:
+			for _, cf := range f.node.forks {
+				// the prefix is copied, as it shares the backing array with
+				// the prefixes of other forks
+				f.prefix = append(append([]byte(nil), f.prefix...), cf.prefix...)
+				f.node = cf.node
+			}
+		}
+	}
+	return true
+}
+
+// lookup returns the entry under the path relative to the node, or nil if
+// there is none.
+func (n *node) lookup(path []byte) *TrieEntry {
+	for len(path) > 0 {
+		f, ok := n.forks[path[0]]
+		if !ok || !bytes.HasPrefix(path, f.prefix) {
+			return nil
+		}
+		path = path[len(f.prefix):]
+		n = f.node
+	}
+	return n.entry
+}
+
+// walk calls the function for every entry under the node in lexicographical
+// order of their paths, which are prefixed with the path of the node.
+func (n *node) walk(path []byte, fn func(path []byte, e *TrieEntry)) {
+	if n.entry != nil {
+		fn(path, n.entry)
+	}
+	for _, f := range n.sortedForks() {
+		// the path is copied on append, so that the forks do not overwrite
+		// the paths of each other
+		f.node.walk(append(path[:len(path):len(path)], f.prefix...), fn)
+	}
+}
+
+// sortedForks returns the forks of the node ordered by their prefixes.
+func (n *node) sortedForks() []*fork {
+	forks := make([]*fork, 0, len(n.forks))
+	for _, f := range n.forks {
+		forks = append(forks, f)
+	}
+	// the first bytes of the prefixes are unique
+	sort.Slice(forks, func(i, j int) bool {
+		return forks[i].prefix[0] < forks[j].prefix[0]
+	})
+	return forks
+}
+
+func commonPrefixLength(a, b []byte) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// TrieEntry is a manifest entry of the TrieManifest.
+type TrieEntry struct {
+	Ref         swarm.Address
+	FileName    string
+	Type        string
+	HTTPHeaders http.Header
+}
+
+// NewEntry creates a new TrieEntry of the file entry reference, the file
+// name, the content type and the custom headers of the file.
+func NewEntry(reference swarm.Address, name, contentType string, headers http.Header) *TrieEntry {
+	return &TrieEntry{
+		Ref:         reference,
+		FileName:    name,
+		Type:        contentType,
+		HTTPHeaders: headers,
+	}
+}
+
+// Reference implements manifest.Entry.
+func (e *TrieEntry) Reference() swarm.Address {
+	return e.Ref
+}
+
+// Name implements manifest.Entry.
+func (e *TrieEntry) Name() string {
+	return e.FileName
+}
+
+// ContentType implements manifest.Entry.
+func (e *TrieEntry) ContentType() string {
+	return e.Type
+}
+
+// Headers implements manifest.Entry.
+func (e *TrieEntry) Headers() http.Header {
+	return e.HTTPHeaders
+}
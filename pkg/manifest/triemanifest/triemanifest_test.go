@@ -0,0 +1,213 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package triemanifest_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/manifest/triemanifest"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestManifest(t *testing.T) {
+	m := triemanifest.NewManifest()
+
+	// paths that share prefixes at different positions, and paths that are
+	// prefixes of other paths
+	entries := map[string]*triemanifest.TrieEntry{
+		"index.html":     triemanifest.NewEntry(addr(1), "index.html", "text/html; charset=utf-8", http.Header{"Cache-Control": {"no-cache"}}),
+		"index.htm":      triemanifest.NewEntry(addr(2), "index.htm", "text/html; charset=utf-8", nil),
+		"img":            triemanifest.NewEntry(addr(3), "img", "", nil),
+		"img/logo.png":   triemanifest.NewEntry(addr(4), "logo.png", "image/png", nil),
+		"img/banner.png": triemanifest.NewEntry(addr(5), "banner.png", "image/png", http.Header{"X-A": {"1", "2"}, "X-B": {"3"}}),
+		"":               triemanifest.NewEntry(addr(6), "", "", nil),
+	}
+	for path, e := range entries {
+		m.Add(path, e)
+	}
+	// replacing an entry does not change the length
+	m.Add("img", entries["img"])
+
+	checkEntries(t, m, entries)
+
+	b, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	um := triemanifest.NewManifest()
+	if err := um.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	checkEntries(t, um, entries)
+
+	for _, path := range []string{"in", "index.h", "img/", "img/logo.pn", "img/logo.png/"} {
+		if _, err := um.Lookup(path); !errors.Is(err, manifest.ErrNotFound) {
+			t.Fatalf("got error %v of %q, want %v", err, path, manifest.ErrNotFound)
+		}
+	}
+
+	for _, path := range []string{"index.htm", "img", "img/banner.png", "missing"} {
+		um.Remove(path)
+		delete(entries, path)
+	}
+	checkEntries(t, um, entries)
+
+	// the trie is compacted on remove, so that it is serialized the same as
+	// the trie of only the remaining entries
+	fresh := triemanifest.NewManifest()
+	for path, e := range entries {
+		fresh.Add(path, e)
+	}
+	got, err := um.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := fresh.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got serialized manifest %x, want %x", got, want)
+	}
+}
+
+func TestManifestMetadata(t *testing.T) {
+	m := triemanifest.NewManifest()
+	m.Add("index.html", triemanifest.NewEntry(addr(1), "index.html", "text/html", nil))
+	m.SetMetadata(manifest.IndexDocumentKey, "index.html")
+	m.SetMetadata(manifest.ErrorDocumentKey, "404.html")
+	m.SetMetadata(manifest.ErrorDocumentKey, "")
+
+	b, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	um := triemanifest.NewManifest()
+	if err := um.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if got := um.Metadata(manifest.IndexDocumentKey); got != "index.html" {
+		t.Errorf("got index document %q, want %q", got, "index.html")
+	}
+	if got := um.Metadata(manifest.ErrorDocumentKey); got != "" {
+		t.Errorf("got error document %q, want none", got)
+	}
+
+	// manifests of the version without metadata are still read
+	v0 := triemanifest.NewManifest()
+	if err := v0.UnmarshalBinary([]byte{0, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	if v0.Length() != 0 || v0.Metadata(manifest.IndexDocumentKey) != "" {
+		t.Fatal("got entries or metadata of an empty manifest")
+	}
+}
+
+func TestManifestWalkError(t *testing.T) {
+	m := triemanifest.NewManifest()
+	m.Add("a", triemanifest.NewEntry(addr(1), "a", "", nil))
+	m.Add("b", triemanifest.NewEntry(addr(2), "b", "", nil))
+
+	testErr := errors.New("test error")
+	var calls int
+	err := m.Walk(func(path string, _ manifest.Entry) error {
+		calls++
+		return testErr
+	})
+	if !errors.Is(err, testErr) {
+		t.Fatalf("got error %v, want %v", err, testErr)
+	}
+	if calls != 1 {
+		t.Fatalf("got %d calls, want 1", calls)
+	}
+}
+
+func TestManifestUnmarshalInvalid(t *testing.T) {
+	m := triemanifest.NewManifest()
+	m.Add("index.html", triemanifest.NewEntry(addr(1), "index.html", "text/html", nil))
+	m.Add("img/logo.png", triemanifest.NewEntry(addr(2), "logo.png", "image/png", nil))
+	b, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "version", data: append([]byte{2}, b[1:]...)},
+		{name: "truncated", data: b[:len(b)-1]},
+		{name: "trailing", data: append(append([]byte(nil), b...), 0)},
+		{name: "flags", data: []byte{0, 2, 0}},
+		{name: "duplicate metadata", data: []byte{1, 2, 1, 'k', 0, 1, 'k', 0, 0, 0}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			um := triemanifest.NewManifest()
+			if err := um.UnmarshalBinary(tc.data); err == nil {
+				t.Fatal("got no error")
+			}
+		})
+	}
+}
+
+// checkEntries validates that the manifest has exactly the entries, and that
+// they are walked in order of their paths.
+func checkEntries(t *testing.T, m manifest.Interface, entries map[string]*triemanifest.TrieEntry) {
+	t.Helper()
+
+	if m.Length() != len(entries) {
+		t.Fatalf("got length %d, want %d", m.Length(), len(entries))
+	}
+
+	for path, want := range entries {
+		got, err := m.Lookup(path)
+		if err != nil {
+			t.Fatalf("lookup %q: %v", path, err)
+		}
+		if !got.Reference().Equal(want.Reference()) {
+			t.Errorf("got reference %s of %q, want %s", got.Reference(), path, want.Reference())
+		}
+		if got.Name() != want.Name() {
+			t.Errorf("got name %q of %q, want %q", got.Name(), path, want.Name())
+		}
+		if got.ContentType() != want.ContentType() {
+			t.Errorf("got content type %q of %q, want %q", got.ContentType(), path, want.ContentType())
+		}
+		if !reflect.DeepEqual(got.Headers(), want.Headers()) {
+			t.Errorf("got headers %v of %q, want %v", got.Headers(), path, want.Headers())
+		}
+	}
+
+	var prev string
+	var count int
+	if err := m.Walk(func(path string, e manifest.Entry) error {
+		if count > 0 && path <= prev {
+			t.Errorf("walked %q after %q", path, prev)
+		}
+		if _, ok := entries[path]; !ok {
+			t.Errorf("walked unexpected path %q", path)
+		}
+		prev = path
+		count++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if count != len(entries) {
+		t.Fatalf("walked %d entries, want %d", count, len(entries))
+	}
+}
+
+func addr(i byte) swarm.Address {
+	b := make([]byte, swarm.HashSize)
+	b[0] = i
+	return swarm.NewAddress(b)
+}
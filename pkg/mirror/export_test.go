@@ -0,0 +1,13 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mirror
+
+import "time"
+
+func SetRetryInterval(d time.Duration) (reset func()) {
+	old := retryInterval
+	retryInterval = d
+	return func() { retryInterval = old }
+}
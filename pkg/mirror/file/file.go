@@ -0,0 +1,111 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package file provides the mirror backend that keeps the chunks as files in
+// a directory, such as a mounted object store bucket.
+package file
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ethersphere/bee/pkg/mirror"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// readDirBatch is the number of the file names that are read from the
+// directory at once while iterating.
+const readDirBatch = 1000
+
+var _ mirror.Backend = (*Backend)(nil)
+
+// Backend keeps every chunk in a file named by the hex encoded chunk address.
+type Backend struct {
+	dir string
+}
+
+// New returns the backend with the chunks in the directory, which is created
+// if it does not exist.
+func New(dir string) (*Backend, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Backend{dir: dir}, nil
+}
+
+// Put writes the chunk data to a temporary file which replaces the chunk file,
+// so that the incomplete data is never read as the chunk.
+func (b *Backend) Put(_ context.Context, addr swarm.Address, data []byte) error {
+	f, err := ioutil.TempFile(b.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), b.path(addr))
+}
+
+func (b *Backend) Get(_ context.Context, addr swarm.Address) ([]byte, error) {
+	data, err := ioutil.ReadFile(b.path(addr))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, mirror.ErrNotFound
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// Iterate calls the function with the addresses of the chunk files in the
+// order in which they are listed in the directory. Other files are skipped.
+func (b *Backend) Iterate(ctx context.Context, fn func(addr swarm.Address) (stop bool, err error)) error {
+	d, err := os.Open(b.dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	for {
+		names, err := d.Readdirnames(readDirBatch)
+		for _, name := range names {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			a, err := hex.DecodeString(name)
+			if err != nil || len(a) != swarm.HashSize {
+				continue
+			}
+			stop, err := fn(swarm.NewAddress(a))
+			if err != nil {
+				return err
+			}
+			if stop {
+				return nil
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read directory: %w", err)
+		}
+	}
+}
+
+func (b *Backend) path(addr swarm.Address) string {
+	return filepath.Join(b.dir, addr.String())
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package file_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/mirror"
+	"github.com/ethersphere/bee/pkg/mirror/file"
+	testingc "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bee-mirror-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b, err := file.New(filepath.Join(dir, "chunks"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	chunks := testingc.GenerateTestRandomChunks(5)
+	for _, ch := range chunks {
+		if err := b.Put(ctx, ch.Address(), ch.Data()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// chunks can be stored again
+	if err := b.Put(ctx, chunks[0].Address(), chunks[0].Data()); err != nil {
+		t.Fatal(err)
+	}
+	// files that are not chunks are skipped
+	if err := ioutil.WriteFile(filepath.Join(dir, "chunks", "README"), []byte("chunks"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ch := range chunks {
+		data, err := b.Get(ctx, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, ch.Data()) {
+			t.Errorf("got data of chunk %s that does not match", ch.Address())
+		}
+	}
+
+	if _, err := b.Get(ctx, swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000001")); !errors.Is(err, mirror.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, mirror.ErrNotFound)
+	}
+
+	found := make(map[string]struct{})
+	if err := b.Iterate(ctx, func(addr swarm.Address) (bool, error) {
+		found[addr.String()] = struct{}{}
+		return false, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != len(chunks) {
+		t.Errorf("got %d iterated chunks, want %d", len(found), len(chunks))
+	}
+	for _, ch := range chunks {
+		if _, ok := found[ch.Address().String()]; !ok {
+			t.Errorf("chunk %s not iterated", ch.Address())
+		}
+	}
+
+	var count int
+	if err := b.Iterate(ctx, func(addr swarm.Address) (bool, error) {
+		count++
+		return true, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("got %d iterated chunks after stop, want %d", count, 1)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mirror
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	// all metrics fields must be exported
+	// to be able to return them by Metrics()
+	// using reflection
+
+	MirroredChunks prometheus.Counter
+	MirrorErrors   prometheus.Counter
+	RestoredChunks prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "mirror"
+
+	return metrics{
+		MirroredChunks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "mirrored_chunks",
+			Help:      "Total chunks written to the mirror backend.",
+		}),
+		MirrorErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "mirror_errors",
+			Help:      "Total failed attempts to mirror a chunk.",
+		}),
+		RestoredChunks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "restored_chunks",
+			Help:      "Total chunks restored from the mirror backend.",
+		}),
+	}
+}
+
+func (s *Service) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
@@ -0,0 +1,197 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mirror writes the chunks that are stored in the local store to an
+// external storage backend, such as an object store, and restores them from
+// it. It provides a disaster recovery of the node data that does not depend
+// on the redundancy of the chunks in the network.
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/validator"
+)
+
+const cursorKeyPrefix = "mirror_cursor_"
+
+var (
+	// ErrNotFound is returned by the Backend if the chunk is not mirrored.
+	ErrNotFound = errors.New("mirror: not found")
+
+	retryInterval = 10 * time.Second // time interval between retries of a failed backend write
+)
+
+// Backend is the external storage of the mirrored chunks. Object store
+// implementations keep the data of every chunk as an object named by its
+// address.
+type Backend interface {
+	// Put stores the chunk data. Storing the same chunk again is allowed.
+	Put(ctx context.Context, addr swarm.Address, data []byte) error
+	// Get returns the chunk data or ErrNotFound.
+	Get(ctx context.Context, addr swarm.Address) (data []byte, err error)
+	// Iterate calls the function with the addresses of all mirrored chunks,
+	// until it returns true or an error.
+	Iterate(ctx context.Context, fn func(addr swarm.Address) (stop bool, err error)) error
+}
+
+type Options struct {
+	Storer     storage.Storer
+	StateStore storage.StateStorer
+	Backend    Backend
+	Logger     logging.Logger
+}
+
+// Service mirrors the chunks of every bin of the pull sync index of the local
+// store in the order in which they are stored. The bin ids of the last
+// mirrored chunks are kept in the state store, so that mirroring resumes
+// where it stopped when the node restarts.
+type Service struct {
+	storer     storage.Storer
+	stateStore storage.StateStorer
+	backend    Backend
+	logger     logging.Logger
+	metrics    metrics
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+func New(o Options) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Service{
+		storer:     o.Storer,
+		stateStore: o.StateStore,
+		backend:    o.Backend,
+		logger:     o.Logger,
+		metrics:    newMetrics(),
+		cancel:     cancel,
+	}
+
+	for bin := uint8(0); bin <= swarm.MaxPO; bin++ {
+		s.wg.Add(1)
+		go s.mirrorBin(ctx, bin)
+	}
+	return s
+}
+
+// mirrorBin writes the chunks of the bin to the backend, from the chunk after
+// the last mirrored one. A chunk that fails to be written is retried until it
+// is mirrored, as the chunks after it would be skipped otherwise.
+func (s *Service) mirrorBin(ctx context.Context, bin uint8) {
+	defer s.wg.Done()
+
+	cursor, err := s.cursor(bin)
+	if err != nil {
+		s.logger.Errorf("mirror: get cursor of bin %d: %v", bin, err)
+		return
+	}
+
+	descriptors, _, stop := s.storer.SubscribePull(ctx, bin, cursor+1, 0)
+	defer stop()
+
+	for d := range descriptors {
+		for {
+			err := s.mirrorChunk(ctx, d.Address)
+			if err == nil {
+				break
+			}
+			s.metrics.MirrorErrors.Inc()
+			s.logger.Debugf("mirror: chunk %s: %v", d.Address, err)
+			s.logger.Errorf("mirror: chunk %s", d.Address)
+			select {
+			case <-time.After(retryInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := s.stateStore.Put(cursorKey(bin), d.BinID); err != nil {
+			s.logger.Errorf("mirror: put cursor of bin %d: %v", bin, err)
+			return
+		}
+	}
+}
+
+// mirrorChunk writes the chunk to the backend. Chunks that are already
+// removed from the local store are skipped.
+func (s *Service) mirrorChunk(ctx context.Context, addr swarm.Address) error {
+	ch, err := s.storer.Get(ctx, storage.ModeGetSync, addr)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("get: %w", err)
+	}
+	if err := s.backend.Put(ctx, addr, ch.Data()); err != nil {
+		return fmt.Errorf("backend put: %w", err)
+	}
+	s.metrics.MirroredChunks.Inc()
+	return nil
+}
+
+func (s *Service) cursor(bin uint8) (cursor uint64, err error) {
+	err = s.stateStore.Get(cursorKey(bin), &cursor)
+	if errors.Is(err, storage.ErrNotFound) {
+		return 0, nil
+	}
+	return cursor, err
+}
+
+func cursorKey(bin uint8) string {
+	return fmt.Sprintf("%s%d", cursorKeyPrefix, bin)
+}
+
+// Restore stores the mirrored chunks that are missing from the local store,
+// as if they were synced, so that they are pushed to the neighbourhood by
+// the pull sync of the peers. Chunks with data that does not match their
+// addresses are not restored. It returns the number of restored chunks.
+func (s *Service) Restore(ctx context.Context) (restored uint64, err error) {
+	v := validator.NewContentAddressValidator()
+	err = s.backend.Iterate(ctx, func(addr swarm.Address) (bool, error) {
+		has, err := s.storer.Has(ctx, addr)
+		if err != nil {
+			return true, err
+		}
+		if has {
+			return false, nil
+		}
+		data, err := s.backend.Get(ctx, addr)
+		if err != nil {
+			return true, fmt.Errorf("backend get %s: %w", addr, err)
+		}
+		ch := swarm.NewChunk(addr, data)
+		if !v.Validate(ch) {
+			s.logger.Errorf("mirror: restore: invalid chunk %s", addr)
+			return false, nil
+		}
+		if _, err := s.storer.Put(ctx, storage.ModePutSync, ch); err != nil {
+			return true, fmt.Errorf("put %s: %w", addr, err)
+		}
+		restored++
+		s.metrics.RestoredChunks.Inc()
+		return false, nil
+	})
+	return restored, err
+}
+
+func (s *Service) Close() error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+	}
+	return nil
+}
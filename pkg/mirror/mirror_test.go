@@ -0,0 +1,191 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mirror_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/mirror"
+	"github.com/ethersphere/bee/pkg/mirror/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	testingc "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+	bmtlegacy "github.com/ethersphere/bmt/legacy"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestMirror(t *testing.T) {
+	defer mirror.SetRetryInterval(10 * time.Millisecond)()
+
+	storer := newTestStorer(t)
+	stateStore := statestore.NewStateStore()
+	backend := mock.New()
+
+	s := mirror.New(mirror.Options{
+		Storer:     storer,
+		StateStore: stateStore,
+		Backend:    backend,
+		Logger:     logging.New(ioutil.Discard, 0),
+	})
+
+	chunks := testingc.GenerateTestRandomChunks(10)
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	waitMirrored(t, backend, chunks...)
+
+	// the failed writes are retried
+	backend.SetPutError(errors.New("backend error"))
+	ch := testingc.GenerateTestRandomChunk()
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	backend.SetPutError(nil)
+	waitMirrored(t, backend, ch)
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// mirroring is resumed after the last mirrored chunks
+	backend = mock.New()
+	s = mirror.New(mirror.Options{
+		Storer:     storer,
+		StateStore: stateStore,
+		Backend:    backend,
+		Logger:     logging.New(ioutil.Discard, 0),
+	})
+	defer s.Close()
+
+	ch = testingc.GenerateTestRandomChunk()
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	waitMirrored(t, backend, ch)
+	time.Sleep(50 * time.Millisecond)
+	if got := backend.Size(); got != 1 {
+		t.Errorf("got %d mirrored chunks after resume, want %d", got, 1)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	storer := newTestStorer(t)
+	backend := mock.New()
+
+	chunks := make([]swarm.Chunk, 10)
+	for i := range chunks {
+		chunks[i] = newValidChunk(t)
+	}
+	for _, ch := range chunks {
+		if err := backend.Put(context.Background(), ch.Address(), ch.Data()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the chunk that is already stored is not restored
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunks[0]); err != nil {
+		t.Fatal(err)
+	}
+	// the chunk with data that does not match its address is not restored
+	invalid := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000001")
+	if err := backend.Put(context.Background(), invalid, chunks[1].Data()); err != nil {
+		t.Fatal(err)
+	}
+
+	s := mirror.New(mirror.Options{
+		Storer:     storer,
+		StateStore: statestore.NewStateStore(),
+		Backend:    backend,
+		Logger:     logging.New(ioutil.Discard, 0),
+	})
+	defer s.Close()
+
+	restored, err := s.Restore(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored != uint64(len(chunks)-1) {
+		t.Errorf("got %d restored chunks, want %d", restored, len(chunks)-1)
+	}
+	for _, ch := range chunks {
+		got, err := storer.Get(context.Background(), storage.ModeGetRequest, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(ch) {
+			t.Errorf("got chunk %s, want %s", got.Address(), ch.Address())
+		}
+	}
+	has, err := storer.Has(context.Background(), invalid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has {
+		t.Error("invalid chunk restored")
+	}
+}
+
+func newTestStorer(t *testing.T) *localstore.DB {
+	t.Helper()
+
+	storer, err := localstore.New("", make([]byte, swarm.HashSize), nil, logging.New(ioutil.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := storer.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return storer
+}
+
+func waitMirrored(t *testing.T, backend *mock.Backend, chunks ...swarm.Chunk) {
+	t.Helper()
+
+	for _, ch := range chunks {
+		var data []byte
+		for i := 0; i < 100; i++ {
+			var err error
+			data, err = backend.Get(context.Background(), ch.Address())
+			if err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if string(data) != string(ch.Data()) {
+			t.Fatalf("chunk %s not mirrored", ch.Address())
+		}
+	}
+}
+
+// newValidChunk returns the chunk with random data and its content address.
+func newValidChunk(t *testing.T) swarm.Chunk {
+	t.Helper()
+
+	data := make([]byte, 8+swarm.ChunkSize)
+	binary.LittleEndian.PutUint64(data, swarm.ChunkSize)
+	if _, err := rand.Read(data[8:]); err != nil {
+		t.Fatal(err)
+	}
+
+	hasher := bmtlegacy.New(bmtlegacy.NewTreePool(sha3.NewLegacyKeccak256, swarm.Branches, bmtlegacy.PoolSize))
+	if err := hasher.SetSpan(swarm.ChunkSize); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hasher.Write(data[8:]); err != nil {
+		t.Fatal(err)
+	}
+	return swarm.NewChunk(swarm.NewAddress(hasher.Sum(nil)), data)
+}
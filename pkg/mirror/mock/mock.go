@@ -0,0 +1,87 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/mirror"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ mirror.Backend = (*Backend)(nil)
+
+// Backend is the in-memory mirror backend. Put fails with the error that is
+// set by SetPutError.
+type Backend struct {
+	chunks map[string][]byte
+	putErr error
+	mu     sync.Mutex
+}
+
+func New() *Backend {
+	return &Backend{
+		chunks: make(map[string][]byte),
+	}
+}
+
+func (b *Backend) Put(_ context.Context, addr swarm.Address, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.putErr != nil {
+		return b.putErr
+	}
+	b.chunks[addr.ByteString()] = append([]byte(nil), data...)
+	return nil
+}
+
+func (b *Backend) Get(_ context.Context, addr swarm.Address) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data, ok := b.chunks[addr.ByteString()]
+	if !ok {
+		return nil, mirror.ErrNotFound
+	}
+	return data, nil
+}
+
+func (b *Backend) Iterate(_ context.Context, fn func(addr swarm.Address) (stop bool, err error)) error {
+	b.mu.Lock()
+	addrs := make([]swarm.Address, 0, len(b.chunks))
+	for a := range b.chunks {
+		addrs = append(addrs, swarm.NewAddress([]byte(a)))
+	}
+	b.mu.Unlock()
+
+	for _, a := range addrs {
+		stop, err := fn(a)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// SetPutError sets the error that is returned by Put, or clears it if nil.
+func (b *Backend) SetPutError(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.putErr = err
+}
+
+// Size returns the number of the mirrored chunks.
+func (b *Backend) Size() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.chunks)
+}
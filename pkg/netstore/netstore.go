@@ -8,8 +8,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/recovery"
 	"github.com/ethersphere/bee/pkg/retrieval"
+	"github.com/ethersphere/bee/pkg/sctx"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
@@ -17,13 +22,35 @@ import (
 type store struct {
 	storage.Storer
 
+	recovery   recovery.Interface
 	retrieval  retrieval.Interface
+	logger     logging.Logger
 	validators []swarm.ChunkValidator
+
+	recovering   map[string]struct{} // chunks whose recovery is being requested
+	recoveringMu sync.Mutex
 }
 
-// New returns a new NetStore that wraps a given Storer.
-func New(s storage.Storer, r retrieval.Interface, validators ...swarm.ChunkValidator) storage.Storer {
-	return &store{Storer: s, retrieval: r, validators: validators}
+var (
+	// ErrRecoveryAttempt is returned when the chunk could not be retrieved
+	// and its recovery was requested from the recovery targets.
+	ErrRecoveryAttempt = errors.New("failed to retrieve chunk, recovery initiated")
+	// ErrRecoveryLimit is returned when the chunk could not be retrieved
+	// and its recovery was not requested, as the recoveries of too many
+	// other chunks are being requested.
+	ErrRecoveryLimit = errors.New("failed to retrieve chunk, too many recoveries")
+)
+
+var (
+	recoveryTimeout = 30 * time.Second // time given to send the recovery requests
+	maxRecoveries   = 16               // chunks whose recovery is requested at the same time
+)
+
+// New returns a new NetStore that wraps a given Storer. The recovery service
+// is optional and is used only if the recovery targets are set in the
+// context of the retrieval that failed.
+func New(s storage.Storer, rcb recovery.Interface, r retrieval.Interface, logger logging.Logger, validators ...swarm.ChunkValidator) storage.Storer {
+	return &store{Storer: s, recovery: rcb, retrieval: r, logger: logger, validators: validators, recovering: make(map[string]struct{})}
 }
 
 // Get retrieves a given chunk address.
@@ -35,6 +62,9 @@ func (s *store) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Addres
 			// request from network
 			data, err := s.retrieval.RetrieveChunk(ctx, addr)
 			if err != nil {
+				if targets := sctx.GetTargets(ctx); s.recovery != nil && len(targets) > 0 {
+					return nil, s.startRecovery(addr, targets)
+				}
 				return nil, fmt.Errorf("netstore retrieve chunk: %w", err)
 			}
 
@@ -54,6 +84,49 @@ func (s *store) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Addres
 	return ch, nil
 }
 
+// startRecovery starts to request the recovery of the chunk from the
+// targets, unless it is already being requested. At most the recovery
+// targets limit is used, and at most maxRecoveries chunks are recovered at
+// the same time.
+func (s *store) startRecovery(addr swarm.Address, targets [][]byte) error {
+	if len(targets) > recovery.MaxTargets {
+		targets = targets[:recovery.MaxTargets]
+	}
+
+	s.recoveringMu.Lock()
+	defer s.recoveringMu.Unlock()
+
+	key := addr.ByteString()
+	if _, ok := s.recovering[key]; ok {
+		return ErrRecoveryAttempt
+	}
+	if len(s.recovering) >= maxRecoveries {
+		return ErrRecoveryLimit
+	}
+	s.recovering[key] = struct{}{}
+
+	go s.recover(addr, targets)
+	return ErrRecoveryAttempt
+}
+
+// recover requests the recovery of the chunk from the targets. It does not
+// use the context of the retrieval as the retrieval may be done before the
+// requests are sent.
+func (s *store) recover(addr swarm.Address, targets [][]byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), recoveryTimeout)
+	defer cancel()
+	defer func() {
+		s.recoveringMu.Lock()
+		delete(s.recovering, addr.ByteString())
+		s.recoveringMu.Unlock()
+	}()
+
+	if err := s.recovery.RecoverChunk(ctx, addr, targets); err != nil {
+		s.logger.Debugf("netstore: recover chunk %s: %v", addr, err)
+		s.logger.Errorf("netstore: recover chunk %s", addr)
+	}
+}
+
 // Put stores a given chunk in the local storage.
 // returns a storage.ErrInvalidChunk error when
 // encountering an invalid chunk.
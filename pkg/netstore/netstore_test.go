@@ -7,10 +7,16 @@ package netstore_test
 import (
 	"bytes"
 	"context"
+	"errors"
+	"io/ioutil"
 	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/netstore"
+	recoverymock "github.com/ethersphere/bee/pkg/recovery/mock"
+	"github.com/ethersphere/bee/pkg/sctx"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -93,11 +99,53 @@ func TestNetstoreNoRetrieval(t *testing.T) {
 	}
 }
 
+// TestNetstoreRecovery verifies that the recovery of a chunk is requested from
+// the targets in the context whenever the chunk can not be retrieved.
+func TestNetstoreRecovery(t *testing.T) {
+	addr := swarm.MustParseHexAddress("000001")
+	targets := [][]byte{{0x01}, {0x02, 0x03}}
+
+	recovered := make(chan [][]byte, 1)
+	rcv := recoverymock.New(func(_ context.Context, a swarm.Address, t [][]byte) error {
+		if a.Equal(addr) {
+			recovered <- t
+		}
+		return nil
+	})
+	retrieve := &retrievalMock{failure: true}
+	nstore := netstore.New(mock.NewStorer(), rcv, retrieve, logging.New(ioutil.Discard, 0), mockValidator{})
+
+	_, err := nstore.Get(context.Background(), storage.ModeGetRequest, addr)
+	if err == nil || errors.Is(err, netstore.ErrRecoveryAttempt) {
+		t.Fatalf("got error %v, want retrieval error", err)
+	}
+
+	ctx := sctx.SetTargets(context.Background(), targets)
+	_, err = nstore.Get(ctx, storage.ModeGetRequest, addr)
+	if !errors.Is(err, netstore.ErrRecoveryAttempt) {
+		t.Fatalf("got error %v, want %v", err, netstore.ErrRecoveryAttempt)
+	}
+
+	select {
+	case got := <-recovered:
+		if len(got) != len(targets) {
+			t.Fatalf("got %d targets, want %d", len(got), len(targets))
+		}
+		for i := range got {
+			if !bytes.Equal(got[i], targets[i]) {
+				t.Fatalf("got target %x, want %x", got[i], targets[i])
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("recovery not requested")
+	}
+}
+
 // returns a mock retrieval protocol, a mock local storage and a netstore
 func newRetrievingNetstore() (ret *retrievalMock, mockStore storage.Storer, ns storage.Storer) {
 	retrieve := &retrievalMock{}
 	store := mock.NewStorer()
-	nstore := netstore.New(store, retrieve, mockValidator{})
+	nstore := netstore.New(store, nil, retrieve, logging.New(ioutil.Discard, 0), mockValidator{})
 
 	return retrieve, store, nstore
 }
@@ -105,6 +153,7 @@ func newRetrievingNetstore() (ret *retrievalMock, mockStore storage.Storer, ns s
 type retrievalMock struct {
 	called    bool
 	callCount int32
+	failure   bool
 	addr      swarm.Address
 }
 
@@ -112,5 +161,53 @@ func (r *retrievalMock) RetrieveChunk(ctx context.Context, addr swarm.Address) (
 	r.called = true
 	atomic.AddInt32(&r.callCount, 1)
 	r.addr = addr
+	if r.failure {
+		return nil, errors.New("chunk not retrieved")
+	}
 	return chunkData, nil
 }
+
+// TestNetstoreRecoveryLimit verifies that the recovery of a chunk is requested
+// only once while it is in progress, and that the recoveries of too many
+// chunks at the same time are refused.
+func TestNetstoreRecoveryLimit(t *testing.T) {
+	release := make(chan struct{})
+	var calls int32
+	rcv := recoverymock.New(func(_ context.Context, _ swarm.Address, _ [][]byte) error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return nil
+	})
+	retrieve := &retrievalMock{failure: true}
+	nstore := netstore.New(mock.NewStorer(), rcv, retrieve, logging.New(ioutil.Discard, 0), mockValidator{})
+	defer close(release)
+
+	ctx := sctx.SetTargets(context.Background(), [][]byte{{0x01}})
+
+	var limited bool
+	for i := 0; i < 100; i++ {
+		addr := swarm.NewAddress([]byte{byte(i)})
+		_, err := nstore.Get(ctx, storage.ModeGetRequest, addr)
+		if errors.Is(err, netstore.ErrRecoveryLimit) {
+			limited = true
+			break
+		}
+		if !errors.Is(err, netstore.ErrRecoveryAttempt) {
+			t.Fatalf("got error %v, want %v", err, netstore.ErrRecoveryAttempt)
+		}
+
+		// the recovery of the same chunk is not requested again
+		_, err = nstore.Get(ctx, storage.ModeGetRequest, addr)
+		if !errors.Is(err, netstore.ErrRecoveryAttempt) {
+			t.Fatalf("got error %v, want %v", err, netstore.ErrRecoveryAttempt)
+		}
+	}
+	if !limited {
+		t.Fatal("recoveries not limited")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if c := atomic.LoadInt32(&calls); c == 0 || c > 16 {
+		t.Fatalf("got %d recovery requests", c)
+	}
+}
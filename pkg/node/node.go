@@ -13,13 +13,17 @@ import (
 	"net"
 	"net/http"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/accounting"
 	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/api"
 	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/discovery"
 	"github.com/ethersphere/bee/pkg/hive"
 	"github.com/ethersphere/bee/pkg/kademlia"
 	"github.com/ethersphere/bee/pkg/keystore"
@@ -28,63 +32,236 @@ import (
 	"github.com/ethersphere/bee/pkg/localstore"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/metrics"
+	"github.com/ethersphere/bee/pkg/mirror"
+	mirrorfile "github.com/ethersphere/bee/pkg/mirror/file"
 	"github.com/ethersphere/bee/pkg/netstore"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/pinning"
 	"github.com/ethersphere/bee/pkg/puller"
 	"github.com/ethersphere/bee/pkg/pullsync"
 	"github.com/ethersphere/bee/pkg/pullsync/pullstorage"
 	"github.com/ethersphere/bee/pkg/pusher"
 	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/receipts"
+	"github.com/ethersphere/bee/pkg/recovery"
+	"github.com/ethersphere/bee/pkg/reputation"
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/resolver/ens"
 	"github.com/ethersphere/bee/pkg/retrieval"
+	"github.com/ethersphere/bee/pkg/retry"
+	"github.com/ethersphere/bee/pkg/settlement/pseudosettle"
 	"github.com/ethersphere/bee/pkg/statestore/leveldb"
 	mockinmem "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/latency"
+	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/topology"
 	"github.com/ethersphere/bee/pkg/tracing"
+	"github.com/ethersphere/bee/pkg/uploadkeys"
+	"github.com/ethersphere/bee/pkg/uploadsession"
 	"github.com/ethersphere/bee/pkg/validator"
 	ma "github.com/multiformats/go-multiaddr"
+	madns "github.com/multiformats/go-multiaddr-dns"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 )
 
+// poPrice is the price of a chunk delivered by the peer with the chunk in
+// its deepest bin, which grows with every bin farther from the chunk.
+const poPrice = 10
+
+// shutdownTimeout is the time given to the API servers to finish serving
+// the requests when the node is shut down by Run.
+const shutdownTimeout = 15 * time.Second
+
 type Bee struct {
 	p2pService       io.Closer
 	p2pCancel        context.CancelFunc
 	apiServer        *http.Server
 	debugAPIServer   *http.Server
+	serveGroup       *errgroup.Group // serves the API servers, failing if any of them fails
+	serveCtx         context.Context // done when the serving of any of the API servers fails
 	errorLogWriter   *io.PipeWriter
 	tracerCloser     io.Closer
 	stateStoreCloser io.Closer
 	localstoreCloser io.Closer
 	topologyCloser   io.Closer
 	pusherCloser     io.Closer
+	pushSyncCloser   io.Closer
 	pullerCloser     io.Closer
 	pullSyncCloser   io.Closer
+	mirrorCloser     io.Closer
+	reputationCloser io.Closer
+	reloader         *reloader
 }
 
 type Options struct {
-	DataDir            string
-	DBCapacity         uint64
+	DataDir         string
+	DBCapacity      uint64
+	DBCapacityBytes uint64
+	// DBMigrationDryRun makes the node report the pending migrations of the
+	// local store data and stop, instead of running them.
+	DBMigrationDryRun bool
+	// DBRebase makes the node reindex the local store data if it was
+	// stored with a different overlay address, instead of failing to start.
+	DBRebase bool
+	// DBAutoCapacity derives the local store capacity from the free disk
+	// space, leaving DBDiskReserve percent of the disk size free.
+	DBAutoCapacity bool
+	DBDiskReserve  uint64
+	// MirrorDir is the directory to which the locally stored chunks are
+	// mirrored, such as a mounted object store bucket. Chunks are not
+	// mirrored if it is not set.
+	MirrorDir          string
 	Password           string
 	APIAddr            string
 	DebugAPIAddr       string
-	Addr               string
-	NATAddr            string
-	EnableWS           bool
-	EnableQUIC         bool
-	NetworkID          uint64
-	WelcomeMessage     string
-	Bootnodes          []string
-	CORSAllowedOrigins []string
-	Logger             logging.Logger
-	TracingEnabled     bool
-	TracingEndpoint    string
-	TracingServiceName string
+	DebugAPIAdminToken string
+	// DebugAPIReadToken and DebugAPIRestricted require the read or the
+	// admin token for all debug API requests, except the health and
+	// readiness checks and the metrics, as described by debugapi.Options.
+	DebugAPIReadToken  string
+	DebugAPIRestricted bool
+	// DebugAPICORSOrigins are the origins of the debug API requests that the
+	// CORS headers are set for.
+	DebugAPICORSOrigins []string
+	// DebugAPIProfiling serves the pprof profiles and the expvar variables
+	// on the debug API.
+	DebugAPIProfiling bool
+	// APIUploadConcurrency, APIDownloadConcurrency and DebugAPIConcurrency
+	// are the numbers of the API requests of each class that are handled at
+	// the same time. Requests are not limited if they are zero.
+	APIUploadConcurrency   int
+	APIDownloadConcurrency int
+	DebugAPIConcurrency    int
+	Addr                   string
+	NATAddr                string
+	NAT6Addr               string
+	ProxyAddr              string
+	EnableWS               bool
+	EnableQUIC             bool
+	NetworkID              uint64
+	WelcomeMessage         string
+	Bootnodes              []string
+	CORSAllowedOrigins     []string
+	Logger                 logging.Logger
+	TracingEnabled         bool
+	TracingEndpoint        string
+	TracingServiceName     string
+	ReceiptDepthCheck      bool
+	// BootnodeRefresh is the interval at which the bootnodes are resolved
+	// and connected to again while the node has no connected peers.
+	// Refreshing is disabled if it is zero.
+	BootnodeRefresh time.Duration
+	// ReplicationFactor is the number of closest neighbours that chunks
+	// stored by this node as the closest peer are replicated to.
+	ReplicationFactor int
+	// RetryPolicy is the name of the retry policy that delays pushing
+	// chunks and connecting to peers again after failures, with the base
+	// RetryDelay and at most RetryMaxDelay. Each service keeps its own
+	// default retry interval if it is not set.
+	RetryPolicy   string
+	RetryDelay    time.Duration
+	RetryMaxDelay time.Duration
+	// DisablePullSync disables syncing chunks with the pull sync protocol.
+	DisablePullSync bool
+	// LightNode runs the node without the storage responsibility for the
+	// chunks of its neighborhood. It advertises itself as a light node in
+	// the handshake, so that peers do not count it in their neighborhood
+	// depth or forward chunks to it, it does not sync with the pull sync
+	// protocol and it pushes the uploaded chunks to peers even if it is
+	// closer to them itself. Chunks are retrieved from the network.
+	LightNode bool
+	// WarmupTime is the maximal duration for which the retrieval protocol
+	// is not served after the start, until the historical intervals of the
+	// bins within depth are synced with the pull sync protocol, so that the
+	// chunks which the node is responsible for, but did not sync yet, are
+	// retrieved from other peers. The retrieval protocol is served from the
+	// start if it is zero or if the pull sync is disabled.
+	WarmupTime time.Duration
+	// DisableHive disables the hive protocol, so that peers are neither
+	// broadcast to nor received from the connected peers.
+	DisableHive bool
+	// SplitterWorkers is the number of chunks of uploaded data that are
+	// hashed and stored concurrently. The chunks are processed sequentially
+	// if it is zero.
+	SplitterWorkers int
+	// SlowPutThreshold, SlowReceiptThreshold and SlowDialThreshold are the
+	// durations of storing chunks locally, waiting for push sync receipts
+	// and dialing peers above which the operations are logged as slow.
+	// Slow operations are not logged if the threshold is zero.
+	SlowPutThreshold     time.Duration
+	SlowReceiptThreshold time.Duration
+	SlowDialThreshold    time.Duration
+	// ManifestPrefetch is the number of the first chunks of the style
+	// sheets and scripts in a manifest that are retrieved in the background
+	// when a web page of the manifest is served through the API. Assets are
+	// not prefetched if it is zero.
+	ManifestPrefetch int
+	// ResolverEndpoint is the URL of the Ethereum JSON-RPC endpoint that the
+	// ENS names requested through the API are resolved with. The names are
+	// not resolved if it is empty.
+	ResolverEndpoint string
+	// ResolverCacheTTL and ResolverNegativeTTL are the durations for which
+	// the resolved names and the names that are not found are cached. The
+	// defaults of the cache are used if they are zero, and the names that
+	// are not found are not cached if the negative TTL is negative.
+	ResolverCacheTTL    time.Duration
+	ResolverNegativeTTL time.Duration
+	// AllowedOverlays and AllowedUnderlays are the hex encoded overlay
+	// addresses and the underlay multiaddresses with peer IDs of the only
+	// peers that the node connects with. All peers are allowed if both are
+	// empty.
+	AllowedOverlays  []string
+	AllowedUnderlays []string
+	// DeniedOverlays are the hex encoded overlay addresses of the peers that
+	// the node does not connect with.
+	DeniedOverlays []string
+	// BandwidthLimits are the limits of the bandwidth of the streams of the
+	// protocols, each as the protocol name and the limit in bytes per second
+	// separated by the equal sign, such as pullsync=1048576.
+	BandwidthLimits []string
+	// VersionOverrides are the newer protocol versions that are requested
+	// from some peers, each as protocol=version:selector, where the
+	// selector is the hex encoded overlay address of a peer or the
+	// percentage of the peers, such as pushsync=1.1.0:10%.
+	VersionOverrides []string
+	// InboundIPLimit and InboundSubnetLimit are the maximal numbers of the
+	// peers with inbound connections from the same IP address and from the
+	// same subnet. They are not limited if the limit is zero.
+	InboundIPLimit     int
+	InboundSubnetLimit int
+	// PaymentThreshold is the debt to a peer over which no more chunks are
+	// pushed to it, and PaymentTolerance the debt of a peer over the payment
+	// threshold at which the peer is disconnected. Balances are kept, but
+	// not limited, if the payment threshold is zero.
+	PaymentThreshold uint64
+	PaymentTolerance uint64
+	// PaymentRefreshRate is the amount per second up to which the debts of
+	// peers are cleared by the pseudosettle protocol. The debts are cleared
+	// in full if it is zero.
+	PaymentRefreshRate uint64
+	// TestStorageLatency, TestStorageJitter and TestStorageErrorRate
+	// are the delay, its maximal random addition, and the fraction of the
+	// failed operations of the local store as used by the protocols and the
+	// API, to test them against a slow or failing disk. The local store is
+	// not wrapped if all of them are zero.
+	TestStorageLatency   time.Duration
+	TestStorageJitter    time.Duration
+	TestStorageErrorRate float64
+	// ReadinessMinPeers is the number of connected peers below which the
+	// node is reported as not ready by the debug API.
+	ReadinessMinPeers int
+	// ConfigLoader loads the options that are applied again when the
+	// configuration of the node is reloaded with ReloadConfig. The
+	// configuration cannot be reloaded if it is not set.
+	ConfigLoader func() (ReloadOptions, error)
 }
 
-func NewBee(o Options) (*Bee, error) {
+func NewBee(o Options) (bee *Bee, err error) {
 	logger := o.Logger
 
 	tracer, tracerCloser, err := tracing.NewTracer(&tracing.Options{
@@ -97,12 +274,39 @@ func NewBee(o Options) (*Bee, error) {
 	}
 
 	p2pCtx, p2pCancel := context.WithCancel(context.Background())
+	serveGroup, serveCtx := errgroup.WithContext(context.Background())
 
 	b := &Bee{
 		p2pCancel:      p2pCancel,
+		serveGroup:     serveGroup,
+		serveCtx:       serveCtx,
 		errorLogWriter: logger.WriterLevel(logrus.ErrorLevel),
 		tracerCloser:   tracerCloser,
+		reloader: &reloader{
+			load: o.ConfigLoader,
+			current: ReloadOptions{
+				LogLevel:               logger.GetLevel(),
+				LogComponentLevels:     logger.ComponentLevels(),
+				CORSAllowedOrigins:     o.CORSAllowedOrigins,
+				APIUploadConcurrency:   o.APIUploadConcurrency,
+				APIDownloadConcurrency: o.APIDownloadConcurrency,
+				DebugAPIConcurrency:    o.DebugAPIConcurrency,
+				DebugAPICORSOrigins:    o.DebugAPICORSOrigins,
+				WelcomeMessage:         o.WelcomeMessage,
+				DeniedOverlays:         o.DeniedOverlays,
+			},
+			logger: logger,
+		},
 	}
+	// the components that are already constructed are closed if the
+	// construction of the others fails
+	defer func() {
+		if err != nil {
+			if e := b.Shutdown(context.Background()); e != nil {
+				logger.Debugf("shutdown after construction error: %v", e)
+			}
+		}
+	}()
 
 	var keyStore keystore.Service
 	if o.DataDir == "" {
@@ -149,22 +353,83 @@ func NewBee(o Options) (*Bee, error) {
 	}
 	b.stateStoreCloser = stateStore
 	addressbook := addressbook.New(stateStore)
+	receiptStore := receipts.New(stateStore)
 	signer := crypto.NewDefaultSigner(swarmPrivateKey)
 
+	if o.LightNode {
+		o.DisablePullSync = true
+	}
+
+	var disabledProtocols []string
+	if o.DisablePullSync {
+		disabledProtocols = append(disabledProtocols, pullsync.ProtocolName)
+	}
+	if o.DisableHive {
+		disabledProtocols = append(disabledProtocols, hive.ProtocolName)
+	}
+
+	allowedOverlays, err := parseOverlays(o.AllowedOverlays)
+	if err != nil {
+		return nil, fmt.Errorf("allowed overlays: %w", err)
+	}
+	deniedOverlays, err := parseOverlays(o.DeniedOverlays)
+	if err != nil {
+		return nil, fmt.Errorf("denied overlays: %w", err)
+	}
+	var allowedUnderlays []ma.Multiaddr
+	for _, a := range o.AllowedUnderlays {
+		underlay, err := ma.NewMultiaddr(a)
+		if err != nil {
+			return nil, fmt.Errorf("allowed underlay %q: %w", a, err)
+		}
+		allowedUnderlays = append(allowedUnderlays, underlay)
+	}
+
+	bandwidthLimits := make(map[string]int64)
+	for _, l := range o.BandwidthLimits {
+		i := strings.Index(l, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("bandwidth limit %q: missing protocol name", l)
+		}
+		limit, err := strconv.ParseInt(l[i+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bandwidth limit %q: %w", l, err)
+		}
+		bandwidthLimits[l[:i]] = limit
+	}
+	versionOverrides, err := p2p.ParseVersionOverrides(o.VersionOverrides)
+	if err != nil {
+		return nil, err
+	}
+
 	p2ps, err := libp2p.New(p2pCtx, signer, o.NetworkID, address, o.Addr, libp2p.Options{
-		PrivateKey:     libp2pPrivateKey,
-		NATAddr:        o.NATAddr,
-		EnableWS:       o.EnableWS,
-		EnableQUIC:     o.EnableQUIC,
-		Addressbook:    addressbook,
-		WelcomeMessage: o.WelcomeMessage,
-		Logger:         logger,
-		Tracer:         tracer,
+		PrivateKey:         libp2pPrivateKey,
+		NATAddr:            o.NATAddr,
+		NAT6Addr:           o.NAT6Addr,
+		ProxyAddr:          o.ProxyAddr,
+		EnableWS:           o.EnableWS,
+		EnableQUIC:         o.EnableQUIC,
+		LightNode:          o.LightNode,
+		Addressbook:        addressbook,
+		WelcomeMessage:     o.WelcomeMessage,
+		Logger:             logger,
+		Tracer:             tracer,
+		DisabledProtocols:  disabledProtocols,
+		SlowDialThreshold:  o.SlowDialThreshold,
+		AllowedOverlays:    allowedOverlays,
+		AllowedUnderlays:   allowedUnderlays,
+		DeniedOverlays:     deniedOverlays,
+		BandwidthLimits:    bandwidthLimits,
+		InboundIPLimit:     o.InboundIPLimit,
+		InboundSubnetLimit: o.InboundSubnetLimit,
+		VersionOverrides:   versionOverrides,
+		StateStore:         stateStore,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("p2p service: %w", err)
 	}
 	b.p2pService = p2ps
+	b.reloader.p2p = p2ps
 
 	if natManager := p2ps.NATManager(); natManager != nil {
 		// wait for nat manager to init
@@ -198,11 +463,29 @@ func NewBee(o Options) (*Bee, error) {
 		Logger:      logger,
 	})
 
-	if err = p2ps.AddProtocol(hive.Protocol()); err != nil {
-		return nil, fmt.Errorf("hive service: %w", err)
+	var peerDiscovery discovery.Driver
+	if !o.DisableHive {
+		if err = p2ps.AddProtocol(hive.Protocol()); err != nil {
+			return nil, fmt.Errorf("hive service: %w", err)
+		}
+		peerDiscovery = hive
+	}
+
+	peerReputation, err := reputation.New(stateStore, reputation.Options{Logger: logger})
+	if err != nil {
+		return nil, fmt.Errorf("reputation: %w", err)
+	}
+	b.reputationCloser = peerReputation
+	pushPeerScores := pushsync.NewPeerScores()
+	var retryPolicy retry.Policy
+	if o.RetryPolicy != "" {
+		retryPolicy, err = retry.New(o.RetryPolicy, o.RetryDelay, o.RetryMaxDelay)
+		if err != nil {
+			return nil, fmt.Errorf("retry policy: %w", err)
+		}
 	}
 
-	topologyDriver := kademlia.New(kademlia.Options{Base: address, Discovery: hive, AddressBook: addressbook, P2P: p2ps, Logger: logger})
+	topologyDriver := kademlia.New(kademlia.Options{Base: address, Discovery: peerDiscovery, AddressBook: addressbook, Reputation: peerReputation, Disconnecters: []topology.Disconnecter{pushPeerScores}, RetryPolicy: retryPolicy, P2P: p2ps, LightNode: o.LightNode, LightPeers: p2ps, Logger: logger})
 	b.topologyCloser = topologyDriver
 	hive.SetPeerAddedHandler(topologyDriver.AddPeer)
 	p2ps.SetNotifier(topologyDriver)
@@ -216,7 +499,7 @@ func NewBee(o Options) (*Bee, error) {
 	}
 
 	var (
-		storer storage.Storer
+		storer *localstore.DB
 		path   = ""
 	)
 
@@ -224,7 +507,13 @@ func NewBee(o Options) (*Bee, error) {
 		path = filepath.Join(o.DataDir, "localstore")
 	}
 	lo := &localstore.Options{
-		Capacity: o.DBCapacity,
+		Capacity:         o.DBCapacity,
+		CapacityBytes:    o.DBCapacityBytes,
+		AutoCapacity:     o.DBAutoCapacity,
+		DiskReserve:      o.DBDiskReserve,
+		SlowPutThreshold: o.SlowPutThreshold,
+		MigrationDryRun:  o.DBMigrationDryRun,
+		Rebase:           o.DBRebase,
 	}
 	storer, err = localstore.New(path, address.Bytes(), lo, logger)
 	if err != nil {
@@ -232,113 +521,368 @@ func NewBee(o Options) (*Bee, error) {
 	}
 	b.localstoreCloser = storer
 
+	go setStorageRadius(p2pCtx, storer, topologyDriver, logger)
+
+	// chunkStorer is the local store as used by the protocols and the API
+	var chunkStorer storage.Storer = storer
+	if o.TestStorageErrorRate < 0 || o.TestStorageErrorRate > 1 {
+		return nil, fmt.Errorf("test storage error rate %v not between 0 and 1", o.TestStorageErrorRate)
+	}
+	if o.TestStorageLatency > 0 || o.TestStorageJitter > 0 || o.TestStorageErrorRate > 0 {
+		logger.Warningf("local store operations are delayed by %v with jitter %v and fail at rate %v", o.TestStorageLatency, o.TestStorageJitter, o.TestStorageErrorRate)
+		chunkStorer = latency.New(storer, latency.Options{
+			Latency:   o.TestStorageLatency,
+			Jitter:    o.TestStorageJitter,
+			ErrorRate: o.TestStorageErrorRate,
+		})
+	}
+
+	chunkValidator := validator.NewContentAddressValidator()
+
 	retrieve := retrieval.New(retrieval.Options{
-		Streamer:    p2ps,
-		ChunkPeerer: topologyDriver,
-		Logger:      logger,
+		Streamer:       p2ps,
+		ChunkPeerer:    topologyDriver,
+		ChunkValidator: chunkValidator,
+		Logger:         logger,
+		Tracer:         tracer,
 	})
 	tagg := tags.NewTags()
 
-	if err = p2ps.AddProtocol(retrieve.Protocol()); err != nil {
-		return nil, fmt.Errorf("retrieval service: %w", err)
+	warmup := o.WarmupTime > 0 && !o.DisablePullSync
+	if !warmup {
+		if err = p2ps.AddProtocol(retrieve.Protocol()); err != nil {
+			return nil, fmt.Errorf("retrieval service: %w", err)
+		}
 	}
 
-	ns := netstore.New(storer, retrieve, validator.NewContentAddressValidator())
+	var receiptDepther topology.NeighborhoodDepther
+	if o.ReceiptDepthCheck {
+		receiptDepther = topologyDriver
+	}
 
-	retrieve.SetStorer(ns)
+	// light nodes do not store the chunks of their neighborhood
+	var storageDepther topology.NeighborhoodDepther
+	if !o.LightNode {
+		storageDepther = topologyDriver
+	}
+
+	settlement := pseudosettle.New(pseudosettle.Options{
+		Streamer:    p2ps,
+		Store:       stateStore,
+		RefreshRate: o.PaymentRefreshRate,
+		Logger:      logger,
+	})
+
+	acc := accounting.New(accounting.Options{
+		Store:            stateStore,
+		PaymentThreshold: o.PaymentThreshold,
+		PaymentTolerance: o.PaymentTolerance,
+		Settlement:       settlement,
+		Logger:           logger,
+	})
+	settlement.SetPaymentObserver(acc)
+
+	if err = p2ps.AddProtocol(settlement.Protocol()); err != nil {
+		return nil, fmt.Errorf("pseudosettle service: %w", err)
+	}
 
+	pushSyncEvents := pushsync.NewEvents()
 	pushSyncProtocol := pushsync.New(pushsync.Options{
-		Streamer:      p2ps,
-		Storer:        storer,
-		ClosestPeerer: topologyDriver,
-		Tagger:        tagg,
-		Logger:        logger,
+		Base:                 address,
+		Signer:               signer,
+		NetworkID:            o.NetworkID,
+		Streamer:             p2ps,
+		Storer:               chunkStorer,
+		ClosestPeerer:        topologyDriver,
+		ChunkValidator:       chunkValidator,
+		ReceiptDepther:       receiptDepther,
+		StorageDepther:       storageDepther,
+		Tagger:               tagg,
+		Events:               pushSyncEvents,
+		PeerScores:           pushPeerScores,
+		ReplicationPeers:     topologyDriver,
+		ReplicationFactor:    o.ReplicationFactor,
+		LightNode:            o.LightNode,
+		SlowReceiptThreshold: o.SlowReceiptThreshold,
+		Accounting:           acc,
+		Pricer:               accounting.NewFixedPricer(address, poPrice),
+		Logger:               logger,
+		Tracer:               tracer,
 	})
 
 	if err = p2ps.AddProtocol(pushSyncProtocol.Protocol()); err != nil {
 		return nil, fmt.Errorf("pushsync service: %w", err)
 	}
+	b.pushSyncCloser = pushSyncProtocol
+
+	chunkRecovery := recovery.New(recovery.Options{
+		Streamer:      p2ps,
+		PeerSuggester: topologyDriver,
+		Storer:        chunkStorer,
+		PushSyncer:    pushSyncProtocol,
+		Logger:        logger,
+	})
+
+	if err = p2ps.AddProtocol(chunkRecovery.Protocol()); err != nil {
+		return nil, fmt.Errorf("recovery service: %w", err)
+	}
+
+	ns := netstore.New(chunkStorer, chunkRecovery, retrieve, logger, chunkValidator)
+
+	retrieve.SetStorer(ns)
 
 	pushSyncPusher := pusher.New(pusher.Options{
-		Storer:        storer,
+		Storer:        chunkStorer,
 		PeerSuggester: topologyDriver,
 		PushSyncer:    pushSyncProtocol,
 		Tagger:        tagg,
+		Receipts:      receiptStore,
+		Events:        pushSyncEvents,
+		RetryPolicy:   retryPolicy,
 		Logger:        logger,
 	})
 	b.pusherCloser = pushSyncPusher
 
-	pullStorage := pullstorage.New(storer)
+	var (
+		pullSync      *pullsync.Syncer
+		pullerService *puller.Puller
+	)
+	if !o.DisablePullSync {
+		pullStorage := pullstorage.New(chunkStorer)
+
+		pullSync = pullsync.New(pullsync.Options{
+			Streamer: p2ps,
+			Storage:  pullStorage,
+			Logger:   logger,
+		})
+		b.pullSyncCloser = pullSync
 
-	pullSync := pullsync.New(pullsync.Options{
-		Streamer: p2ps,
-		Storage:  pullStorage,
-		Logger:   logger,
-	})
-	b.pullSyncCloser = pullSync
+		if err = p2ps.AddProtocol(pullSync.Protocol()); err != nil {
+			return nil, fmt.Errorf("pullsync protocol: %w", err)
+		}
 
-	if err = p2ps.AddProtocol(pullSync.Protocol()); err != nil {
-		return nil, fmt.Errorf("pullsync protocol: %w", err)
-	}
+		pullerService = puller.New(puller.Options{
+			StateStore: stateStore,
+			Topology:   topologyDriver,
+			PullSync:   pullSync,
+			Logger:     logger,
+		})
 
-	puller := puller.New(puller.Options{
-		StateStore: stateStore,
-		Topology:   topologyDriver,
-		PullSync:   pullSync,
-		Logger:     logger,
-	})
+		b.pullerCloser = pullerService
+
+		if warmup {
+			go func() {
+				select {
+				case <-pullerService.HistorySynced():
+					logger.Info("warmup: historical syncing done, serving retrieval")
+				case <-time.After(o.WarmupTime):
+					logger.Infof("warmup: historical syncing not done in %s, serving retrieval", o.WarmupTime)
+				case <-p2pCtx.Done():
+					return
+				}
+				if err := p2ps.AddProtocol(retrieve.Protocol()); err != nil {
+					logger.Errorf("retrieval service: %v", err)
+				}
+			}()
+		}
+	}
 
-	b.pullerCloser = puller
+	var mirrorService *mirror.Service
+	var mirrorRestorer debugapi.MirrorRestorer
+	if o.MirrorDir != "" {
+		mirrorBackend, err := mirrorfile.New(o.MirrorDir)
+		if err != nil {
+			return nil, fmt.Errorf("mirror: %w", err)
+		}
+		mirrorService = mirror.New(mirror.Options{
+			Storer:     storer,
+			StateStore: stateStore,
+			Backend:    mirrorBackend,
+			Logger:     logger,
+		})
+		mirrorRestorer = mirrorService
+		b.mirrorCloser = mirrorService
+	}
 
-	var apiService api.Service
+	var (
+		apiService    api.Service
+		apiAddr       net.Addr
+		nameResolver  resolver.Interface
+		resolverCache *resolver.Cache
+	)
+	if o.APIAddr != "" && o.ResolverEndpoint != "" {
+		ensResolver, err := ens.New(o.ResolverEndpoint, ens.Options{})
+		if err != nil {
+			return nil, fmt.Errorf("ens resolver: %w", err)
+		}
+		resolverCache = resolver.NewCache(ensResolver, resolver.CacheOptions{
+			TTL:         o.ResolverCacheTTL,
+			NegativeTTL: o.ResolverNegativeTTL,
+		})
+		nameResolver = resolverCache
+	}
 	if o.APIAddr != "" {
 		// API server
 		apiService = api.New(api.Options{
-			Tags:               tagg,
-			Storer:             ns,
-			CORSAllowedOrigins: o.CORSAllowedOrigins,
-			Logger:             logger,
-			Tracer:             tracer,
+			Tags:                tagg,
+			Storer:              ns,
+			Receipts:            receiptStore,
+			Retrieval:           retrieve,
+			UploadSessions:      uploadsession.New(stateStore),
+			UploadKeys:          uploadkeys.New(stateStore),
+			Pins:                pinning.New(stateStore),
+			Resolver:            nameResolver,
+			CORSAllowedOrigins:  o.CORSAllowedOrigins,
+			SplitterWorkers:     o.SplitterWorkers,
+			ManifestPrefetch:    o.ManifestPrefetch,
+			UploadConcurrency:   o.APIUploadConcurrency,
+			DownloadConcurrency: o.APIDownloadConcurrency,
+			Logger:              logger,
+			Tracer:              tracer,
 		})
 		apiListener, err := net.Listen("tcp", o.APIAddr)
 		if err != nil {
 			return nil, fmt.Errorf("api listener: %w", err)
 		}
+		apiAddr = apiListener.Addr()
 
 		apiServer := &http.Server{
 			Handler:  apiService,
 			ErrorLog: log.New(b.errorLogWriter, "", 0),
 		}
 
-		go func() {
+		b.serveGroup.Go(func() error {
 			logger.Infof("api address: %s", apiListener.Addr())
 
 			if err := apiServer.Serve(apiListener); err != nil && err != http.ErrServerClosed {
 				logger.Debugf("api server: %v", err)
 				logger.Error("unable to serve api")
+				return fmt.Errorf("api server: %w", err)
 			}
-		}()
+			return nil
+		})
 
 		b.apiServer = apiServer
+		b.reloader.api = apiService
 	}
 
 	if o.DebugAPIAddr != "" {
 		// Debug API server
+		logStream := logging.NewStream()
+		logger.AddHook(logStream)
+
+		var configReloader debugapi.ConfigReloader
+		if o.ConfigLoader != nil {
+			configReloader = b
+		}
+
+		var resolverCacheFlusher debugapi.ResolverCacheFlusher
+		if resolverCache != nil {
+			resolverCacheFlusher = resolverCache
+		}
+
+		blockCaches := map[string]debugapi.BlockCacheReporter{
+			"localstore": storer,
+		}
+		// the in-memory state store of a node without a data directory has
+		// no block cache
+		if r, ok := stateStore.(debugapi.BlockCacheReporter); ok {
+			blockCaches["statestore"] = r
+		}
+
+		readinessChecks := map[string]debugapi.ReadinessCheck{
+			"p2p": func() error {
+				addrs, err := p2ps.Addresses()
+				if err != nil {
+					return err
+				}
+				if len(addrs) == 0 {
+					return errors.New("not listening")
+				}
+				return nil
+			},
+			"storage": func() error {
+				_, err := storer.Has(context.Background(), swarm.ZeroAddress)
+				return err
+			},
+			"peers": func() error {
+				if n := len(p2ps.Peers()); n < o.ReadinessMinPeers {
+					return fmt.Errorf("%d connected peers, want at least %d", n, o.ReadinessMinPeers)
+				}
+				return nil
+			},
+		}
+		if apiAddr != nil {
+			readinessChecks["api"] = func() error {
+				conn, err := net.DialTimeout("tcp", apiAddr.String(), time.Second)
+				if err != nil {
+					return err
+				}
+				return conn.Close()
+			}
+		}
+
 		debugAPIService := debugapi.New(debugapi.Options{
-			Overlay:        address,
-			P2P:            p2ps,
-			Pingpong:       pingPong,
-			Logger:         logger,
-			Tracer:         tracer,
-			TopologyDriver: topologyDriver,
-			Storer:         storer,
+			Overlay:            address,
+			P2P:                p2ps,
+			Pingpong:           pingPong,
+			Logger:             logger,
+			Tracer:             tracer,
+			TopologyDriver:     topologyDriver,
+			Storer:             storer,
+			GarbageCollector:   storer,
+			ChunkStater:        storer,
+			SchemaNamer:        storer,
+			RadiusReporter:     storer,
+			StorageReporter:    storer,
+			MirrorRestorer:     mirrorRestorer,
+			BlockCaches:        blockCaches,
+			PushSyncEvents:     pushSyncEvents,
+			PushQueue:          storer,
+			PushInflight:       pushSyncPusher,
+			LogStream:          logStream,
+			Signer:             signer,
+			AdminToken:         o.DebugAPIAdminToken,
+			ReadToken:          o.DebugAPIReadToken,
+			Restricted:         o.DebugAPIRestricted,
+			CORSAllowedOrigins: o.DebugAPICORSOrigins,
+			ConfigReloader:     configReloader,
+			Accounting:         acc,
+			Settlement:         settlement,
+			AddressBook:        addressbook,
+			Blocklister:        p2ps,
+			ResolverCache:      resolverCacheFlusher,
+			ReadinessChecks:    readinessChecks,
+			Profiling:          o.DebugAPIProfiling,
+			Concurrency:        o.DebugAPIConcurrency,
 		})
+		b.reloader.debugAPI = debugAPIService
+
 		// register metrics from components
 		debugAPIService.MustRegisterMetrics(p2ps.Metrics()...)
 		debugAPIService.MustRegisterMetrics(pingPong.Metrics()...)
+		debugAPIService.MustRegisterMetrics(retrieve.Metrics()...)
+		debugAPIService.MustRegisterMetrics(pushSyncProtocol.Metrics()...)
+		debugAPIService.MustRegisterMetrics(pushSyncPusher.Metrics()...)
+		if pullSync != nil {
+			debugAPIService.MustRegisterMetrics(pullSync.Metrics()...)
+		}
+		if pullerService != nil {
+			debugAPIService.MustRegisterMetrics(pullerService.Metrics()...)
+		}
+		debugAPIService.MustRegisterMetrics(chunkRecovery.Metrics()...)
+		debugAPIService.MustRegisterMetrics(storer.Metrics()...)
+		debugAPIService.MustRegisterMetrics(acc.Metrics()...)
+		debugAPIService.MustRegisterMetrics(settlement.Metrics()...)
 		if apiService != nil {
 			debugAPIService.MustRegisterMetrics(apiService.Metrics()...)
 		}
+		if mirrorService != nil {
+			debugAPIService.MustRegisterMetrics(mirrorService.Metrics()...)
+		}
+		if resolverCache != nil {
+			debugAPIService.MustRegisterMetrics(resolverCache.Metrics()...)
+		}
 		if l, ok := logger.(metrics.Collector); ok {
 			debugAPIService.MustRegisterMetrics(l.Metrics()...)
 		}
@@ -353,14 +897,16 @@ func NewBee(o Options) (*Bee, error) {
 			ErrorLog: log.New(b.errorLogWriter, "", 0),
 		}
 
-		go func() {
+		b.serveGroup.Go(func() error {
 			logger.Infof("debug api address: %s", debugAPIListener.Addr())
 
 			if err := debugAPIServer.Serve(debugAPIListener); err != nil && err != http.ErrServerClosed {
 				logger.Debugf("debug api server: %v", err)
 				logger.Error("unable to serve debug api")
+				return fmt.Errorf("debug api server: %w", err)
 			}
-		}()
+			return nil
+		})
 
 		b.debugAPIServer = debugAPIServer
 	}
@@ -382,47 +928,144 @@ func NewBee(o Options) (*Bee, error) {
 		}
 	}
 
+	// dnsaddr bootnodes are resolved through the proxy in the proxy mode
+	dnsResolver := madns.DefaultResolver
+	if o.ProxyAddr != "" {
+		dnsResolver, err = libp2p.NewProxyResolver(o.ProxyAddr)
+		if err != nil {
+			return nil, fmt.Errorf("proxy resolver: %w", err)
+		}
+	}
+
 	// Connect bootnodes if the address book is clean
 	if count == 0 {
-		var wg sync.WaitGroup
-		for _, a := range o.Bootnodes {
-			wg.Add(1)
-			go func(a string) {
-				defer wg.Done()
-				addr, err := ma.NewMultiaddr(a)
+		connectBootnodes(p2pCtx, p2ps, dnsResolver, o.Bootnodes, logger)
+	}
+
+	if o.BootnodeRefresh > 0 && len(o.Bootnodes) > 0 {
+		go refreshBootnodes(p2pCtx, p2ps, topologyDriver, dnsResolver, o.Bootnodes, o.BootnodeRefresh, logger)
+	}
+
+	return b, nil
+}
+
+// connectBootnodes connects to at most a few nodes from every bootnode address.
+// Addresses of the dnsaddr protocol are resolved on every call, so the
+// current DNS records are used.
+func connectBootnodes(ctx context.Context, p2ps p2p.Service, dnsResolver *madns.Resolver, bootnodes []string, logger logging.Logger) {
+	var wg sync.WaitGroup
+	for _, a := range bootnodes {
+		wg.Add(1)
+		go func(a string) {
+			defer wg.Done()
+			addr, err := ma.NewMultiaddr(a)
+			if err != nil {
+				logger.Debugf("multiaddress fail %s: %v", a, err)
+				logger.Warningf("connect to bootnode %s", a)
+				return
+			}
+			var count int
+			if _, err := p2p.DiscoverWithResolver(ctx, dnsResolver, addr, func(addr ma.Multiaddr) (stop bool, err error) {
+				logger.Tracef("connecting to bootnode %s", addr)
+				_, err = p2ps.ConnectNotify(ctx, addr)
 				if err != nil {
-					logger.Debugf("multiaddress fail %s: %v", a, err)
-					logger.Warningf("connect to bootnode %s", a)
-					return
-				}
-				var count int
-				if _, err := p2p.Discover(p2pCtx, addr, func(addr ma.Multiaddr) (stop bool, err error) {
-					logger.Tracef("connecting to bootnode %s", addr)
-					_, err = p2ps.ConnectNotify(p2pCtx, addr)
-					if err != nil {
-						if !errors.Is(err, p2p.ErrAlreadyConnected) {
-							logger.Debugf("connect fail %s: %v", addr, err)
-							logger.Warningf("connect to bootnode %s", addr)
-						}
-						return false, nil
+					if !errors.Is(err, p2p.ErrAlreadyConnected) {
+						logger.Debugf("connect fail %s: %v", addr, err)
+						logger.Warningf("connect to bootnode %s", addr)
 					}
-					logger.Tracef("connected to bootnode %s", addr)
-					count++
-					// connect to max 3 bootnodes
-					return count > 3, nil
-				}); err != nil {
-					logger.Debugf("discover fail %s: %v", a, err)
-					logger.Warningf("discover to bootnode %s", a)
-					return
+					return false, nil
 				}
-			}(a)
+				logger.Tracef("connected to bootnode %s", addr)
+				count++
+				// connect to max 3 bootnodes
+				return count > 3, nil
+			}); err != nil {
+				logger.Debugf("discover fail %s: %v", a, err)
+				logger.Warningf("discover to bootnode %s", a)
+				return
+			}
+		}(a)
+	}
+	wg.Wait()
+}
+
+// refreshBootnodes periodically connects to the bootnodes again if the node
+// has lost all of its peers, until the context is done.
+func refreshBootnodes(ctx context.Context, p2ps p2p.Service, peers topology.EachPeerer, dnsResolver *madns.Resolver, bootnodes []string, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var connected bool
+		_ = peers.EachPeer(func(swarm.Address, uint8) (bool, bool, error) {
+			connected = true
+			return true, false, nil
+		})
+		if connected {
+			continue
 		}
-		wg.Wait()
+
+		logger.Debug("no connected peers, refreshing bootnodes")
+		connectBootnodes(ctx, p2ps, dnsResolver, bootnodes, logger)
 	}
+}
 
-	return b, nil
+// setStorageRadius sets the storage radius of the local store to the
+// neighbourhood depth of the topology whenever it changes.
+func setStorageRadius(ctx context.Context, storer *localstore.DB, topologyDriver topology.Driver, logger logging.Logger) {
+	c, unsubscribe := topologyDriver.SubscribeDepth()
+	defer unsubscribe()
+
+	radius := storer.Radius()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c:
+		}
+
+		depth := topologyDriver.NeighborhoodDepth()
+		if depth == radius {
+			continue
+		}
+		if err := storer.SetRadius(depth); err != nil {
+			logger.Errorf("localstore: set storage radius %d: %v", depth, err)
+			continue
+		}
+		logger.Debugf("localstore: storage radius changed from %d to %d", radius, depth)
+		radius = depth
+	}
 }
 
+// Run blocks until the context is done or any of the API servers fails to
+// serve, and then shuts the node down gracefully, giving the servers
+// shutdownTimeout to finish serving the requests. It returns the error of the
+// server that failed, or the error of the shutdown.
+func (b *Bee) Run(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+	case <-b.serveCtx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := b.Shutdown(shutdownCtx)
+	if serveErr := b.serveGroup.Wait(); serveErr != nil {
+		return serveErr
+	}
+	return err
+}
+
+// Shutdown closes all components of the node, the API servers first, and
+// the stores that the others use last. The context bounds the time that the
+// API servers are given to finish serving the requests.
 func (b *Bee) Shutdown(ctx context.Context) error {
 	errs := new(multiError)
 
@@ -447,37 +1090,73 @@ func (b *Bee) Shutdown(ctx context.Context) error {
 		errs.add(err)
 	}
 
-	if err := b.pusherCloser.Close(); err != nil {
-		errs.add(fmt.Errorf("pusher: %w", err))
+	if b.pusherCloser != nil {
+		if err := b.pusherCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("pusher: %w", err))
+		}
+	}
+
+	if b.pushSyncCloser != nil {
+		if err := b.pushSyncCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("pushsync: %w", err))
+		}
+	}
+
+	if b.pullerCloser != nil {
+		if err := b.pullerCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("puller: %w", err))
+		}
 	}
 
-	if err := b.pullerCloser.Close(); err != nil {
-		errs.add(fmt.Errorf("puller: %w", err))
+	if b.pullSyncCloser != nil {
+		if err := b.pullSyncCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("pull sync: %w", err))
+		}
 	}
 
-	if err := b.pullSyncCloser.Close(); err != nil {
-		errs.add(fmt.Errorf("pull sync: %w", err))
+	if b.mirrorCloser != nil {
+		if err := b.mirrorCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("mirror: %w", err))
+		}
 	}
 
 	b.p2pCancel()
-	if err := b.p2pService.Close(); err != nil {
-		errs.add(fmt.Errorf("p2p server: %w", err))
+	if b.p2pService != nil {
+		if err := b.p2pService.Close(); err != nil {
+			errs.add(fmt.Errorf("p2p server: %w", err))
+		}
 	}
 
-	if err := b.tracerCloser.Close(); err != nil {
-		errs.add(fmt.Errorf("tracer: %w", err))
+	// the topology driver is closed before the state store that keeps its
+	// address book
+	if b.topologyCloser != nil {
+		if err := b.topologyCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("topology driver: %w", err))
+		}
 	}
 
-	if err := b.stateStoreCloser.Close(); err != nil {
-		errs.add(fmt.Errorf("statestore: %w", err))
+	// the reputation of the peers is written to the state store when it is
+	// closed, after the topology driver has stopped recording
+	if b.reputationCloser != nil {
+		if err := b.reputationCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("reputation: %w", err))
+		}
 	}
 
-	if err := b.localstoreCloser.Close(); err != nil {
-		errs.add(fmt.Errorf("localstore: %w", err))
+	if err := b.tracerCloser.Close(); err != nil {
+		errs.add(fmt.Errorf("tracer: %w", err))
 	}
 
-	if err := b.topologyCloser.Close(); err != nil {
-		errs.add(fmt.Errorf("topology driver: %w", err))
+	if b.stateStoreCloser != nil {
+		if err := b.stateStoreCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("statestore: %w", err))
+		}
+	}
+
+	if b.localstoreCloser != nil {
+		if err := b.localstoreCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("localstore: %w", err))
+		}
 	}
 
 	if err := b.errorLogWriter.Close(); err != nil {
@@ -0,0 +1,174 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p/libp2p"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrReloadNotSupported is returned by ReloadConfig if the node is started
+// without the ConfigLoader option.
+var ErrReloadNotSupported = errors.New("configuration reload not supported")
+
+// ReloadOptions are the options that are applied again when the
+// configuration of the node is reloaded, without restarting it. The options
+// of the same names in Options are overridden by them.
+type ReloadOptions struct {
+	// LogLevel is the level of the logger from Options. The lines are
+	// still discarded if the logger is created with a discarding writer.
+	LogLevel               logrus.Level
+	CORSAllowedOrigins     []string
+	APIUploadConcurrency   int
+	APIDownloadConcurrency int
+	DebugAPIConcurrency    int
+	DebugAPICORSOrigins    []string
+	WelcomeMessage         string
+	DeniedOverlays         []string
+	// LogComponentLevels override the level of the logger for the lines of
+	// the components. The overrides of the components that are removed
+	// from them are removed from the logger too.
+	LogComponentLevels map[string]logrus.Level
+}
+
+// reloader applies the changed reload options to the running services.
+type reloader struct {
+	load     func() (ReloadOptions, error)
+	current  ReloadOptions
+	logger   logging.Logger
+	p2p      *libp2p.Service
+	api      api.Service
+	debugAPI debugapi.Service
+	mu       sync.Mutex
+}
+
+// ReloadConfig loads the reload options with the ConfigLoader from Options
+// and applies those that are changed. It returns the names of the applied
+// options, which are the names of the command line flags. No option is
+// applied if any of them is invalid.
+func (b *Bee) ReloadConfig() (applied []string, err error) {
+	r := b.reloader
+	if r == nil || r.load == nil {
+		return nil, ErrReloadNotSupported
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, err := r.load()
+	if err != nil {
+		return nil, fmt.Errorf("load configuration: %w", err)
+	}
+
+	// the denied overlays and the welcome message are the only options
+	// that are validated, so they are validated first to apply either all
+	// options or none of them
+	deniedOverlays, err := parseOverlays(o.DeniedOverlays)
+	if err != nil {
+		return nil, fmt.Errorf("denied overlays: %w", err)
+	}
+	if o.WelcomeMessage != r.current.WelcomeMessage {
+		if err := r.p2p.SetWelcomeMessage(o.WelcomeMessage); err != nil {
+			return nil, fmt.Errorf("welcome message: %w", err)
+		}
+		applied = append(applied, "welcome-message")
+	}
+	if !equalStrings(o.DeniedOverlays, r.current.DeniedOverlays) {
+		r.p2p.SetDeniedOverlays(deniedOverlays)
+		applied = append(applied, "denied-overlays")
+	}
+	if o.LogLevel != r.current.LogLevel {
+		r.logger.SetLevel(o.LogLevel)
+		applied = append(applied, "verbosity")
+	}
+	if !equalLevels(o.LogComponentLevels, r.current.LogComponentLevels) {
+		for component := range r.current.LogComponentLevels {
+			if _, ok := o.LogComponentLevels[component]; !ok {
+				r.logger.RemoveComponentLevel(component)
+			}
+		}
+		for component, level := range o.LogComponentLevels {
+			r.logger.SetComponentLevel(component, level)
+		}
+		applied = append(applied, "log-component-levels")
+	}
+	if r.api != nil {
+		if !equalStrings(o.CORSAllowedOrigins, r.current.CORSAllowedOrigins) {
+			r.api.SetCORSAllowedOrigins(o.CORSAllowedOrigins)
+			applied = append(applied, "cors-allowed-origins")
+		}
+		if o.APIUploadConcurrency != r.current.APIUploadConcurrency || o.APIDownloadConcurrency != r.current.APIDownloadConcurrency {
+			r.api.SetConcurrency(o.APIUploadConcurrency, o.APIDownloadConcurrency)
+			if o.APIUploadConcurrency != r.current.APIUploadConcurrency {
+				applied = append(applied, "api-upload-concurrency")
+			}
+			if o.APIDownloadConcurrency != r.current.APIDownloadConcurrency {
+				applied = append(applied, "api-download-concurrency")
+			}
+		}
+	}
+	if r.debugAPI != nil {
+		if o.DebugAPIConcurrency != r.current.DebugAPIConcurrency {
+			r.debugAPI.SetConcurrency(o.DebugAPIConcurrency)
+			applied = append(applied, "debug-api-concurrency")
+		}
+		if !equalStrings(o.DebugAPICORSOrigins, r.current.DebugAPICORSOrigins) {
+			r.debugAPI.SetCORSAllowedOrigins(o.DebugAPICORSOrigins)
+			applied = append(applied, "debug-api-cors-allowed-origins")
+		}
+	}
+	r.current = o
+
+	r.logger.Infof("configuration reloaded, applied options: %v", applied)
+	return applied, nil
+}
+
+// equalStrings returns true if the slices have the same strings in the same
+// order. A nil slice is equal to an empty one, as the configuration does not
+// tell them apart.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalLevels(a, b map[string]logrus.Level) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for component, level := range a {
+		if l, ok := b[component]; !ok || l != level {
+			return false
+		}
+	}
+	return true
+}
+
+// parseOverlays parses the hex encoded overlay addresses.
+func parseOverlays(overlays []string) ([]swarm.Address, error) {
+	var addrs []swarm.Address
+	for _, o := range overlays {
+		addr, err := swarm.ParseHexAddress(o)
+		if err != nil {
+			return nil, fmt.Errorf("overlay %q: %w", o, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
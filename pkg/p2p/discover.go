@@ -14,12 +14,20 @@ import (
 	madns "github.com/multiformats/go-multiaddr-dns"
 )
 
+// Discover calls f for every address that addr resolves to. Addresses of the
+// dnsaddr protocol are resolved recursively by looking up their DNS TXT
+// records, all other addresses are passed to f unchanged.
 func Discover(ctx context.Context, addr ma.Multiaddr, f func(ma.Multiaddr) (stop bool, err error)) (stopped bool, err error) {
+	return DiscoverWithResolver(ctx, madns.DefaultResolver, addr, f)
+}
+
+// DiscoverWithResolver is the same as Discover, but it resolves dnsaddr
+// addresses with the provided resolver.
+func DiscoverWithResolver(ctx context.Context, dnsResolver *madns.Resolver, addr ma.Multiaddr, f func(ma.Multiaddr) (stop bool, err error)) (stopped bool, err error) {
 	if comp, _ := ma.SplitFirst(addr); comp.Protocol().Name != "dnsaddr" {
 		return f(addr)
 	}
 
-	dnsResolver := madns.DefaultResolver
 	addrs, err := dnsResolver.Resolve(ctx, addr)
 	if err != nil {
 		return false, fmt.Errorf("dns resolve address %s: %w", addr, err)
@@ -32,7 +40,7 @@ func Discover(ctx context.Context, addr ma.Multiaddr, f func(ma.Multiaddr) (stop
 		addrs[i], addrs[j] = addrs[j], addrs[i]
 	})
 	for _, addr := range addrs {
-		stopped, err = Discover(ctx, addr, f)
+		stopped, err = DiscoverWithResolver(ctx, dnsResolver, addr, f)
 		if err != nil {
 			return false, fmt.Errorf("discover %s: %w", addr, err)
 		}
@@ -0,0 +1,86 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package p2p_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p"
+	ma "github.com/multiformats/go-multiaddr"
+	madns "github.com/multiformats/go-multiaddr-dns"
+)
+
+func TestDiscoverWithResolver(t *testing.T) {
+	resolver := &madns.Resolver{Backend: &madns.MockBackend{
+		TXT: map[string][]string{
+			"_dnsaddr.bootnode.example.org": {
+				"dnsaddr=/dnsaddr/eu.bootnode.example.org",
+				"dnsaddr=/ip4/10.0.0.1/tcp/1634",
+			},
+			"_dnsaddr.eu.bootnode.example.org": {
+				"dnsaddr=/ip4/10.0.0.2/tcp/1634",
+				"dnsaddr=/ip4/10.0.0.3/tcp/1634",
+			},
+		},
+	}}
+
+	for _, tc := range []struct {
+		name string
+		addr string
+		stop int
+		want []string
+	}{
+		{
+			name: "plain address",
+			addr: "/ip4/10.0.0.9/tcp/1634",
+			want: []string{"/ip4/10.0.0.9/tcp/1634"},
+		},
+		{
+			name: "nested dnsaddr",
+			addr: "/dnsaddr/bootnode.example.org",
+			want: []string{"/ip4/10.0.0.1/tcp/1634", "/ip4/10.0.0.2/tcp/1634", "/ip4/10.0.0.3/tcp/1634"},
+		},
+		{
+			name: "stop",
+			addr: "/dnsaddr/eu.bootnode.example.org",
+			stop: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			addr, err := ma.NewMultiaddr(tc.addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			_, err = p2p.DiscoverWithResolver(context.Background(), resolver, addr, func(addr ma.Multiaddr) (bool, error) {
+				got = append(got, addr.String())
+				return len(got) == tc.stop, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.stop > 0 {
+				if len(got) != tc.stop {
+					t.Fatalf("got %v addresses, want %v", len(got), tc.stop)
+				}
+				return
+			}
+
+			sort.Strings(got)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got addresses %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("got addresses %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
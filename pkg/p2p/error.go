@@ -16,6 +16,18 @@ var (
 	ErrPeerNotFound = errors.New("peer not found")
 	// ErrAlreadyConnected is returned if connect was called for already connected node.
 	ErrAlreadyConnected = errors.New("already connected")
+	// ErrPeerNotAllowed is returned if connect was called for a node that is
+	// not allowed in the closed network mode.
+	ErrPeerNotAllowed = errors.New("peer not allowed")
+	// ErrPeerBlocklisted is returned if connect was called for a node that
+	// is on the blocklist.
+	ErrPeerBlocklisted = errors.New("peer blocklisted")
+	// ErrPeerDenied is returned if connect was called for a node that is on
+	// the denylist of the node configuration.
+	ErrPeerDenied = errors.New("peer denied")
+	// ErrProtocolDisabled is returned if a stream was requested with a
+	// protocol that the peer announced as disabled in the handshake.
+	ErrProtocolDisabled = errors.New("protocol disabled by peer")
 )
 
 // ConnectionBackoffError indicates that connection calls will not be executed until `tryAfter` timetamp.
@@ -65,6 +77,67 @@ func (e *DisconnectError) Error() string {
 	return e.err.Error()
 }
 
+// BlockPeerError is an error that is specifically handled inside p2p. If
+// returned by a protocol handler, it causes the peer to be disconnected and
+// added to the blocklist for the duration.
+type BlockPeerError struct {
+	duration time.Duration
+	err      error
+}
+
+// NewBlockPeerError wraps the error and creates a special error that causes
+// the peer to be blocklisted for the duration.
+func NewBlockPeerError(duration time.Duration, err error) error {
+	return &BlockPeerError{
+		duration: duration,
+		err:      err,
+	}
+}
+
+// Duration returns the duration for which the peer is blocklisted.
+func (e *BlockPeerError) Duration() time.Duration {
+	return e.duration
+}
+
+// Unwrap returns an underlying error.
+func (e *BlockPeerError) Unwrap() error { return e.err }
+
+// Error implements function of the standard go error interface.
+func (e *BlockPeerError) Error() string {
+	return e.err.Error()
+}
+
+// TemporaryError is an error that is specifically handled inside p2p. If
+// returned by a protocol handler, the peer is kept connected, and if
+// returned to the caller of a protocol, the request may be retried after
+// the duration.
+type TemporaryError struct {
+	retryAfter time.Duration
+	err        error
+}
+
+// NewTemporaryError wraps the error and creates a special error that marks
+// the failure as temporary, to be retried after the duration.
+func NewTemporaryError(retryAfter time.Duration, err error) error {
+	return &TemporaryError{
+		retryAfter: retryAfter,
+		err:        err,
+	}
+}
+
+// RetryAfter returns the duration after which the request may be retried.
+func (e *TemporaryError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// Unwrap returns an underlying error.
+func (e *TemporaryError) Unwrap() error { return e.err }
+
+// Error implements function of the standard go error interface.
+func (e *TemporaryError) Error() string {
+	return e.err.Error()
+}
+
 // IncompatibleStreamError is the error that should be returned by p2p service
 // NewStream method when the stream or its version is not supported.
 type IncompatibleStreamError struct {
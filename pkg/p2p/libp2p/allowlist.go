@@ -0,0 +1,68 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p
+
+import (
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+	libp2ppeer "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// allowlist is the set of peers that the node connects with in the closed
+// network mode. A peer is allowed if either its overlay address or the peer
+// ID of its underlay address is on the list. The network is open, and all
+// peers are allowed, if the list is empty.
+type allowlist struct {
+	overlays map[string]struct{}
+	peerIDs  map[libp2ppeer.ID]struct{}
+}
+
+func newAllowlist(overlays []swarm.Address, underlays []ma.Multiaddr) (*allowlist, error) {
+	a := &allowlist{
+		overlays: make(map[string]struct{}),
+		peerIDs:  make(map[libp2ppeer.ID]struct{}),
+	}
+	for _, o := range overlays {
+		a.overlays[o.ByteString()] = struct{}{}
+	}
+	for _, u := range underlays {
+		info, err := libp2ppeer.AddrInfoFromP2pAddr(u)
+		if err != nil {
+			return nil, fmt.Errorf("underlay %s: %w", u, err)
+		}
+		a.peerIDs[info.ID] = struct{}{}
+	}
+	return a, nil
+}
+
+// closed returns true if only the peers on the list are allowed.
+func (a *allowlist) closed() bool {
+	return len(a.overlays) > 0 || len(a.peerIDs) > 0
+}
+
+// mayAllow returns false if the peer is not allowed regardless of its
+// overlay address, so that it is rejected before the handshake.
+func (a *allowlist) mayAllow(peerID libp2ppeer.ID) bool {
+	if !a.closed() || len(a.overlays) > 0 {
+		return true
+	}
+	_, ok := a.peerIDs[peerID]
+	return ok
+}
+
+// allowed returns true if the peer with the overlay address, known after
+// the handshake, is allowed.
+func (a *allowlist) allowed(peerID libp2ppeer.ID, overlay swarm.Address) bool {
+	if !a.closed() {
+		return true
+	}
+	if _, ok := a.peerIDs[peerID]; ok {
+		return true
+	}
+	_, ok := a.overlays[overlay.ByteString()]
+	return ok
+}
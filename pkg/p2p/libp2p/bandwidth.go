@@ -0,0 +1,132 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/clock"
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/bandwidth"
+	"github.com/libp2p/go-libp2p-core/network"
+)
+
+// protocolLimiter limits the bandwidth of all streams of a protocol, for
+// each direction separately.
+type protocolLimiter struct {
+	read  *bandwidth.Limiter
+	write *bandwidth.Limiter
+}
+
+func newProtocolLimiters(limits map[string]int64, clock clock.Clock) (map[string]*protocolLimiter, error) {
+	limiters := make(map[string]*protocolLimiter, len(limits))
+	for name, rate := range limits {
+		if rate <= 0 {
+			return nil, fmt.Errorf("protocol %s: invalid limit %d", name, rate)
+		}
+		limiters[name] = &protocolLimiter{
+			read:  bandwidth.NewLimiter(rate, clock),
+			write: bandwidth.NewLimiter(rate, clock),
+		}
+	}
+	return limiters, nil
+}
+
+// limitStream returns the stream with the bandwidth limit of the protocol,
+// or the stream itself if the protocol is not limited.
+func (s *Service) limitStream(st network.Stream, protocolName string) network.Stream {
+	l, ok := s.bandwidthLimiters[protocolName]
+	if !ok {
+		return st
+	}
+	return &limitedStream{Stream: st, ctx: s.ctx, limiter: l}
+}
+
+// limitedStream delays the reads and the writes of the stream to keep the
+// bandwidth of its protocol under the limit. The data that is read is
+// accounted for after the read, as its length is not known before. The
+// delays end with an error at the deadlines of the stream, as the reads and
+// the writes of the stream would.
+type limitedStream struct {
+	network.Stream
+	ctx     context.Context
+	limiter *protocolLimiter
+
+	deadlineMu    sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func (s *limitedStream) Read(b []byte) (int, error) {
+	n, err := s.Stream.Read(b)
+	if n > 0 {
+		if werr := s.wait(s.limiter.read, s.deadline(true), n); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return n, err
+}
+
+func (s *limitedStream) Write(b []byte) (int, error) {
+	if err := s.wait(s.limiter.write, s.deadline(false), len(b)); err != nil {
+		return 0, err
+	}
+	return s.Stream.Write(b)
+}
+
+func (s *limitedStream) SetDeadline(t time.Time) error {
+	if err := s.Stream.SetDeadline(t); err != nil {
+		return err
+	}
+	s.deadlineMu.Lock()
+	s.readDeadline, s.writeDeadline = t, t
+	s.deadlineMu.Unlock()
+	return nil
+}
+
+func (s *limitedStream) SetReadDeadline(t time.Time) error {
+	if err := s.Stream.SetReadDeadline(t); err != nil {
+		return err
+	}
+	s.deadlineMu.Lock()
+	s.readDeadline = t
+	s.deadlineMu.Unlock()
+	return nil
+}
+
+func (s *limitedStream) SetWriteDeadline(t time.Time) error {
+	if err := s.Stream.SetWriteDeadline(t); err != nil {
+		return err
+	}
+	s.deadlineMu.Lock()
+	s.writeDeadline = t
+	s.deadlineMu.Unlock()
+	return nil
+}
+
+// deadline returns the read or the write deadline of the stream, which is
+// zero if it is not set.
+func (s *limitedStream) deadline(read bool) time.Time {
+	s.deadlineMu.Lock()
+	defer s.deadlineMu.Unlock()
+
+	if read {
+		return s.readDeadline
+	}
+	return s.writeDeadline
+}
+
+// wait waits for the bandwidth of n bytes until the deadline, if it is set.
+func (s *limitedStream) wait(l *bandwidth.Limiter, deadline time.Time, n int) error {
+	ctx := s.ctx
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	return l.Wait(ctx, n)
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/clock"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ p2p.Blocklister = (*Service)(nil)
+
+const blocklistPrefix = "blocklist_"
+
+// blocklist is the set of peers that are rejected after the handshake until
+// their entries expire. The entries are persisted in the state store, if it
+// is set, so that they are kept over the restarts of the node.
+type blocklist struct {
+	mu    sync.Mutex
+	peers map[string]time.Time // expiration times by overlay addresses
+	store storage.StateStorer
+	clock clock.Clock
+}
+
+// newBlocklist returns the blocklist with the unexpired entries from the
+// state store, removing the expired ones from it. The entries expire by the
+// time of the clock.
+func newBlocklist(store storage.StateStorer, clock clock.Clock) (*blocklist, error) {
+	b := &blocklist{
+		peers: make(map[string]time.Time),
+		store: store,
+		clock: clock,
+	}
+	if store == nil {
+		return b, nil
+	}
+
+	now := clock.Now()
+	var expired []string
+	if err := store.Iterate(blocklistPrefix, func(key, value []byte) (stop bool, err error) {
+		overlay, err := swarm.ParseHexAddress(strings.TrimPrefix(string(key), blocklistPrefix))
+		if err != nil {
+			return true, fmt.Errorf("parse blocklist key %q: %w", key, err)
+		}
+		var until time.Time
+		if err := until.UnmarshalBinary(value); err != nil {
+			return true, fmt.Errorf("blocklist entry for peer %s: %w", overlay, err)
+		}
+		if !now.Before(until) {
+			expired = append(expired, string(key))
+			return false, nil
+		}
+		b.peers[overlay.ByteString()] = until
+		return false, nil
+	}); err != nil {
+		return nil, err
+	}
+	for _, key := range expired {
+		if err := store.Delete(key); err != nil {
+			return nil, fmt.Errorf("delete blocklist entry %q: %w", key, err)
+		}
+	}
+	return b, nil
+}
+
+func (b *blocklist) add(overlay swarm.Address, duration time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	until := b.clock.Now().Add(duration)
+	if b.store != nil {
+		if err := b.store.Put(blocklistKey(overlay), until); err != nil {
+			return fmt.Errorf("store blocklist entry for peer %s: %w", overlay, err)
+		}
+	}
+	b.peers[overlay.ByteString()] = until
+	return nil
+}
+
+func (b *blocklist) remove(overlay swarm.Address) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.store != nil {
+		if err := b.store.Delete(blocklistKey(overlay)); err != nil {
+			return fmt.Errorf("delete blocklist entry for peer %s: %w", overlay, err)
+		}
+	}
+	delete(b.peers, overlay.ByteString())
+	return nil
+}
+
+// blocked returns true if the peer is on the blocklist and its entry has not
+// expired. The expired entries are removed from the state store only when
+// the blocklist is loaded.
+func (b *blocklist) blocked(overlay swarm.Address) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	until, ok := b.peers[overlay.ByteString()]
+	if !ok {
+		return false
+	}
+	if !b.clock.Now().Before(until) {
+		delete(b.peers, overlay.ByteString())
+		return false
+	}
+	return true
+}
+
+// list returns the peers with unexpired entries, sorted by their expiration
+// times.
+func (b *blocklist) list() []p2p.BlocklistedPeer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	peers := make([]p2p.BlocklistedPeer, 0, len(b.peers))
+	for k, until := range b.peers {
+		if !now.Before(until) {
+			delete(b.peers, k)
+			continue
+		}
+		peers = append(peers, p2p.BlocklistedPeer{
+			Address: swarm.NewAddress([]byte(k)),
+			Until:   until,
+		})
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Until.Before(peers[j].Until)
+	})
+	return peers
+}
+
+func blocklistKey(overlay swarm.Address) string {
+	return blocklistPrefix + overlay.String()
+}
+
+// Blocklist disconnects the peer, if it is connected, and rejects the
+// inbound and outbound connections with it until the duration passes.
+func (s *Service) Blocklist(overlay swarm.Address, duration time.Duration) error {
+	if err := s.blocklist.add(overlay, duration); err != nil {
+		return err
+	}
+	s.metrics.BlocklistedPeerCount.Inc()
+	s.logger.Debugf("blocklisted peer %s for %s", overlay, duration)
+
+	peerID, found := s.peers.peerID(overlay)
+	if !found {
+		return nil
+	}
+	return s.disconnect(peerID)
+}
+
+// Unblocklist allows the connections with the peer again.
+func (s *Service) Unblocklist(overlay swarm.Address) error {
+	return s.blocklist.remove(overlay)
+}
+
+// BlocklistedPeers returns the peers on the blocklist.
+func (s *Service) BlocklistedPeers() ([]p2p.BlocklistedPeer, error) {
+	return s.blocklist.list(), nil
+}
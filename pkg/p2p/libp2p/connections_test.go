@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/ethersphere/bee/pkg/addressbook"
+	clockmock "github.com/ethersphere/bee/pkg/clock/mock"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/handshake"
@@ -302,6 +303,99 @@ func TestConnectRepeatHandshake(t *testing.T) {
 	expectPeersEventually(t, s1)
 }
 
+func TestConnectAllowedOverlays(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s2, overlay2 := newService(t, 1, libp2p.Options{})
+	s3, _ := newService(t, 1, libp2p.Options{})
+	s1, overlay1 := newService(t, 1, libp2p.Options{
+		AllowedOverlays: []swarm.Address{overlay2},
+	})
+	addr1 := serviceUnderlayAddress(t, s1)
+
+	// an allowed peer connects
+	if _, err := s2.Connect(ctx, addr1); err != nil {
+		t.Fatal(err)
+	}
+	expectPeers(t, s2, overlay1)
+	expectPeersEventually(t, s1, overlay2)
+
+	// a peer that is not allowed is disconnected after the handshake, which
+	// may or may not be completed on its side before the stream is reset
+	_, _ = s3.Connect(ctx, addr1)
+	expectPeersEventually(t, s3)
+	expectPeersEventually(t, s1, overlay2)
+
+	// a peer that is not allowed is not connected to
+	if _, err := s1.Connect(ctx, serviceUnderlayAddress(t, s3)); !errors.Is(err, p2p.ErrPeerNotAllowed) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrPeerNotAllowed)
+	}
+	expectPeers(t, s1, overlay2)
+	expectPeersEventually(t, s3)
+}
+
+func TestConnectAllowedUnderlays(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s2, overlay2 := newService(t, 1, libp2p.Options{})
+	s3, _ := newService(t, 1, libp2p.Options{})
+	addr2 := serviceUnderlayAddress(t, s2)
+	s1, overlay1 := newService(t, 1, libp2p.Options{
+		AllowedUnderlays: []ma.Multiaddr{addr2},
+	})
+
+	if _, err := s1.Connect(ctx, addr2); err != nil {
+		t.Fatal(err)
+	}
+	expectPeers(t, s1, overlay2)
+	expectPeersEventually(t, s2, overlay1)
+
+	// the peer is rejected before dialing
+	if _, err := s1.Connect(ctx, serviceUnderlayAddress(t, s3)); !errors.Is(err, p2p.ErrPeerNotAllowed) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrPeerNotAllowed)
+	}
+
+	// and before the handshake when it connects
+	if _, err := s3.Connect(ctx, serviceUnderlayAddress(t, s1)); err == nil {
+		t.Fatal("connect attempt should result with an error")
+	}
+	expectPeers(t, s1, overlay2)
+	expectPeersEventually(t, s3)
+}
+
+func TestConnectInboundIPLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2p.Options{
+		InboundIPLimit: 1,
+	})
+	s2, overlay2 := newService(t, 1, libp2p.Options{})
+	s3, _ := newService(t, 1, libp2p.Options{})
+	addr1 := serviceUnderlayAddress(t, s1)
+
+	if _, err := s2.Connect(ctx, addr1); err != nil {
+		t.Fatal(err)
+	}
+	expectPeers(t, s2, overlay1)
+	expectPeersEventually(t, s1, overlay2)
+
+	// the second peer from the same ip address is rejected before the
+	// handshake
+	if _, err := s3.Connect(ctx, addr1); err == nil {
+		t.Fatal("connect attempt should result with an error")
+	}
+	expectPeersEventually(t, s3)
+	expectPeers(t, s1, overlay2)
+
+	// outbound connections are not limited
+	if _, err := s1.Connect(ctx, serviceUnderlayAddress(t, s3)); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestTopologyNotifier(t *testing.T) {
 	var (
 		mtx sync.Mutex
@@ -496,3 +590,141 @@ var noopNotifier = mockNotifier(
 	func(_ context.Context, _ swarm.Address) error { return nil },
 	func(_ swarm.Address) {},
 )
+
+func TestBlocklist(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2p.Options{})
+	s2, overlay2 := newService(t, 1, libp2p.Options{})
+	addr1 := serviceUnderlayAddress(t, s1)
+	addr2 := serviceUnderlayAddress(t, s2)
+
+	if _, err := s2.Connect(ctx, addr1); err != nil {
+		t.Fatal(err)
+	}
+	expectPeers(t, s2, overlay1)
+	expectPeersEventually(t, s1, overlay2)
+
+	// the connected peer is disconnected
+	if err := s1.Blocklist(overlay2, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	expectPeers(t, s1)
+	expectPeersEventually(t, s2)
+
+	peers, err := s1.BlocklistedPeers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || !peers[0].Address.Equal(overlay2) {
+		t.Fatalf("got blocklisted peers %v, want %s", peers, overlay2)
+	}
+
+	// the peer is not connected to
+	if _, err := s1.Connect(ctx, addr2); !errors.Is(err, p2p.ErrPeerBlocklisted) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrPeerBlocklisted)
+	}
+	expectPeers(t, s1)
+	expectPeersEventually(t, s2)
+
+	// and it is disconnected after the handshake when it connects
+	_, _ = s2.Connect(ctx, addr1)
+	expectPeersEventually(t, s2)
+	expectPeersEventually(t, s1)
+
+	if err := s1.Unblocklist(overlay2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s1.Connect(ctx, addr2); err != nil {
+		t.Fatal(err)
+	}
+	expectPeers(t, s1, overlay2)
+	expectPeersEventually(t, s2, overlay1)
+}
+
+func TestBlocklistExpired(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := clockmock.New(time.Unix(1600000000, 0))
+	s1, _ := newService(t, 1, libp2p.Options{Clock: clock})
+	s2, overlay2 := newService(t, 1, libp2p.Options{})
+
+	if err := s1.Blocklist(overlay2, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Add(time.Minute - time.Second)
+	peers, err := s1.BlocklistedPeers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || !peers[0].Address.Equal(overlay2) {
+		t.Fatalf("got blocklisted peers %v before the expiration, want %s", peers, overlay2)
+	}
+	clock.Add(time.Second)
+
+	peers, err = s1.BlocklistedPeers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 0 {
+		t.Fatalf("got blocklisted peers %v, want none", peers)
+	}
+
+	if _, err := s1.Connect(ctx, serviceUnderlayAddress(t, s2)); err != nil {
+		t.Fatal(err)
+	}
+	expectPeers(t, s1, overlay2)
+}
+
+func TestBlocklistPersisted(t *testing.T) {
+	store := mock.NewStateStore()
+	clock := clockmock.New(time.Unix(1600000000, 0))
+
+	s1, _ := newService(t, 1, libp2p.Options{StateStore: store, Clock: clock})
+	_, overlay2 := newService(t, 1, libp2p.Options{})
+	_, overlay3 := newService(t, 1, libp2p.Options{})
+
+	if err := s1.Blocklist(overlay2, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := s1.Blocklist(overlay3, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	clock.Add(time.Minute)
+
+	// the unexpired entries are loaded by the restarted node
+	s1, _ = newService(t, 1, libp2p.Options{StateStore: store, Clock: clock})
+	peers, err := s1.BlocklistedPeers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || !peers[0].Address.Equal(overlay2) {
+		t.Fatalf("got blocklisted peers %v, want %s", peers, overlay2)
+	}
+
+	var count int
+	if err := store.Iterate("blocklist_", func(_, _ []byte) (bool, error) {
+		count++
+		return false, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("got %d stored blocklist entries, want 1", count)
+	}
+
+	if err := s1.Unblocklist(overlay2); err != nil {
+		t.Fatal(err)
+	}
+	s1, _ = newService(t, 1, libp2p.Options{StateStore: store, Clock: clock})
+	peers, err = s1.BlocklistedPeers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 0 {
+		t.Fatalf("got blocklisted peers %v, want none", peers)
+	}
+}
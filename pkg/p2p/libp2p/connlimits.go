@@ -0,0 +1,99 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p
+
+import (
+	"net"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	libp2ppeer "github.com/libp2p/go-libp2p-core/peer"
+	manet "github.com/multiformats/go-multiaddr-net"
+)
+
+const (
+	// ip4SubnetBits and ip6SubnetBits are the prefix lengths of the subnets
+	// within which the inbound connections are limited together.
+	ip4SubnetBits = 24
+	ip6SubnetBits = 48
+)
+
+// connLimits are the limits of the number of the inbound connections from
+// the same IP address and from the same subnet, so that a single host can not
+// occupy all connection slots with many overlay addresses. Limits that are
+// not positive are not enforced.
+type connLimits struct {
+	perIP     int
+	perSubnet int
+}
+
+func (l connLimits) enabled() bool {
+	return l.perIP > 0 || l.perSubnet > 0
+}
+
+// allowed returns true if a new inbound connection from the IP address is
+// within the limits, given the remote IP addresses of the other peers with
+// inbound connections.
+func (l connLimits) allowed(ip net.IP, inbound []net.IP) bool {
+	subnet := subnetOf(ip)
+	var sameIP, sameSubnet int
+	for _, i := range inbound {
+		if i.Equal(ip) {
+			sameIP++
+		}
+		if subnet.Contains(i) {
+			sameSubnet++
+		}
+	}
+	if l.perIP > 0 && sameIP >= l.perIP {
+		return false
+	}
+	if l.perSubnet > 0 && sameSubnet >= l.perSubnet {
+		return false
+	}
+	return true
+}
+
+// subnetOf returns the subnet of the IP address within which the inbound
+// connections are limited together.
+func subnetOf(ip net.IP) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		mask := net.CIDRMask(ip4SubnetBits, 8*net.IPv4len)
+		return &net.IPNet{IP: ip4.Mask(mask), Mask: mask}
+	}
+	mask := net.CIDRMask(ip6SubnetBits, 8*net.IPv6len)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+}
+
+// inboundAllowed returns true if the inbound connection is within the limits
+// of the peers connected from its IP address and subnet. Every peer is
+// counted once, however many connections it has, and the other connections
+// of the same peer are not counted. Connections over the transports without
+// IP addresses are not limited.
+func (s *Service) inboundAllowed(conn network.Conn) bool {
+	if !s.connLimits.enabled() {
+		return true
+	}
+	ip, err := manet.ToIP(conn.RemoteMultiaddr())
+	if err != nil {
+		return true
+	}
+	peers := make(map[libp2ppeer.ID]net.IP)
+	for _, c := range s.host.Network().Conns() {
+		if c.RemotePeer() == conn.RemotePeer() || c.Stat().Direction != network.DirInbound {
+			continue
+		}
+		if _, ok := peers[c.RemotePeer()]; ok {
+			continue
+		}
+		if i, err := manet.ToIP(c.RemoteMultiaddr()); err == nil {
+			peers[c.RemotePeer()] = i
+		}
+	}
+	inbound := make([]net.IP, 0, len(peers))
+	for _, i := range peers {
+		inbound = append(inbound, i)
+	}
+	return s.connLimits.allowed(ip, inbound)
+}
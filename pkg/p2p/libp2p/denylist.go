@@ -0,0 +1,63 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p
+
+import (
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// denylist is the set of peers that are rejected after the handshake as
+// configured for the node. Unlike the blocklist, its entries do not expire
+// and are not persisted, and the whole set is replaced when the
+// configuration is reloaded.
+type denylist struct {
+	mu       sync.Mutex
+	overlays map[string]struct{}
+}
+
+func newDenylist(overlays []swarm.Address) *denylist {
+	d := new(denylist)
+	d.set(overlays)
+	return d
+}
+
+// set replaces the denied peers.
+func (d *denylist) set(overlays []swarm.Address) {
+	m := make(map[string]struct{}, len(overlays))
+	for _, o := range overlays {
+		m[o.ByteString()] = struct{}{}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.overlays = m
+}
+
+func (d *denylist) denied(overlay swarm.Address) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, ok := d.overlays[overlay.ByteString()]
+	return ok
+}
+
+// SetDeniedOverlays replaces the peers that the node does not connect with,
+// and disconnects those of them that are connected.
+func (s *Service) SetDeniedOverlays(overlays []swarm.Address) {
+	s.denylist.set(overlays)
+
+	for _, overlay := range overlays {
+		peerID, found := s.peers.peerID(overlay)
+		if !found {
+			continue
+		}
+		if err := s.disconnect(peerID); err != nil {
+			s.logger.Debugf("denylist: disconnect peer %s: %v", overlay, err)
+		}
+	}
+}
@@ -23,3 +23,19 @@ func (s *Service) NewStreamForPeerID(peerID libp2ppeer.ID, protocolName, protoco
 type StaticAddressResolver = staticAddressResolver
 
 var NewStaticAddressResolver = newStaticAddressResolver
+
+func NewFamilyAddressResolver(natAddr, nat6Addr string) (handshake.AdvertisableAddressResolver, error) {
+	ip4, err := newStaticAddressResolver(natAddr)
+	if err != nil {
+		return nil, err
+	}
+	ip6, err := newStaticIP6AddressResolver(nat6Addr)
+	if err != nil {
+		return nil, err
+	}
+	return &familyAddressResolver{ip4: ip4, ip6: ip6}, nil
+}
+
+func NewHiddenAddressResolver() handshake.AdvertisableAddressResolver {
+	return hiddenAddressResolver{}
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bandwidth provides the token bucket limiter of the bandwidth of
+// the streams of a protocol.
+package bandwidth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/clock"
+)
+
+// Limiter limits the rate of the bytes transferred to the configured number
+// of bytes per second. Up to a second worth of unused bandwidth is
+// accumulated, so that short bursts are not delayed.
+//
+// Limiter is safe for concurrent use. The transfers wait in the order in
+// which they reserve the bandwidth.
+type Limiter struct {
+	rate   float64 // bytes per second
+	burst  float64
+	clock  clock.Clock
+	mu     sync.Mutex
+	tokens float64 // negative while the reserved bytes exceed the available
+	last   time.Time
+}
+
+// NewLimiter creates a new Limiter of the rate in bytes per second, which
+// times the transfers with the clock.
+func NewLimiter(rate int64, clock clock.Clock) *Limiter {
+	return &Limiter{
+		rate:   float64(rate),
+		burst:  float64(rate),
+		clock:  clock,
+		tokens: float64(rate),
+		last:   clock.Now(),
+	}
+}
+
+// Reserve reserves the bandwidth for n bytes and returns the duration to
+// wait before they are transferred.
+func (l *Limiter) Reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+	}
+
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// Wait reserves the bandwidth for n bytes and waits until they may be
+// transferred or the context is done.
+func (l *Limiter) Wait(ctx context.Context, n int) error {
+	d := l.Reserve(n)
+	if d <= 0 {
+		return nil
+	}
+	t := l.clock.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bandwidth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	clockmock "github.com/ethersphere/bee/pkg/clock/mock"
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/bandwidth"
+)
+
+func TestReserve(t *testing.T) {
+	clock := clockmock.New(time.Unix(1600000000, 0))
+	l := bandwidth.NewLimiter(1000, clock)
+
+	for _, tc := range []struct {
+		name    string
+		elapsed time.Duration
+		n       int
+		want    time.Duration
+	}{
+		{name: "burst", n: 1000, want: 0},
+		{name: "exhausted", n: 500, want: 500 * time.Millisecond},
+		{name: "queued", n: 500, want: time.Second},
+		{name: "refilled", elapsed: time.Second, n: 500, want: 500 * time.Millisecond},
+		// unused bandwidth is accumulated for at most a second
+		{name: "idle", elapsed: time.Hour, n: 1500, want: 500 * time.Millisecond},
+	} {
+		clock.Add(tc.elapsed)
+		if got := l.Reserve(tc.n); got != tc.want {
+			t.Fatalf("%s: got wait %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWait(t *testing.T) {
+	clock := clockmock.New(time.Unix(1600000000, 0))
+	l := bandwidth.NewLimiter(10000, clock)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Wait(context.Background(), 11000)
+	}()
+	for clock.Timers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the wait ends once the bandwidth of the excess bytes is available
+	clock.Add(99 * time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("wait ended before 100ms with error %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Add(time.Millisecond)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wait did not end after 100ms")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx, 10000); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+}
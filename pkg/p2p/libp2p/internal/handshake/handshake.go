@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/bzz"
@@ -63,8 +64,9 @@ type Service struct {
 	advertisableAddresser AdvertisableAddressResolver
 	overlay               swarm.Address
 	lightNode             bool
+	disabledProtocols     []string
 	networkID             uint64
-	welcomeMessage        string
+	welcomeMessage        atomic.Value
 	receivedHandshakes    map[libp2ppeer.ID]struct{}
 	receivedHandshakesMu  sync.Mutex
 	logger                logging.Logger
@@ -74,27 +76,46 @@ type Service struct {
 
 // Info contains the information received from the handshake.
 type Info struct {
-	BzzAddress *bzz.Address
-	Light      bool
+	BzzAddress        *bzz.Address
+	Light             bool
+	DisabledProtocols []string // names of the protocols that the peer does not run
 }
 
 // New creates a new handshake Service.
-func New(signer crypto.Signer, advertisableAddresser AdvertisableAddressResolver, overlay swarm.Address, networkID uint64, lighNode bool, welcomeMessage string, logger logging.Logger) (*Service, error) {
+func New(signer crypto.Signer, advertisableAddresser AdvertisableAddressResolver, overlay swarm.Address, networkID uint64, lighNode bool, disabledProtocols []string, welcomeMessage string, logger logging.Logger) (*Service, error) {
 	if len(welcomeMessage) > MaxWelcomeMessageLength {
 		return nil, ErrWelcomeMessageLength
 	}
 
-	return &Service{
+	s := &Service{
 		signer:                signer,
 		advertisableAddresser: advertisableAddresser,
 		overlay:               overlay,
 		networkID:             networkID,
 		lightNode:             lighNode,
-		welcomeMessage:        welcomeMessage,
+		disabledProtocols:     disabledProtocols,
 		receivedHandshakes:    make(map[libp2ppeer.ID]struct{}),
 		logger:                logger,
 		Notifiee:              new(network.NoopNotifiee),
-	}, nil
+	}
+	s.welcomeMessage.Store(welcomeMessage)
+	return s, nil
+}
+
+// SetWelcomeMessage changes the welcome message that is sent in the
+// handshakes that start after it is set.
+func (s *Service) SetWelcomeMessage(welcomeMessage string) error {
+	if len(welcomeMessage) > MaxWelcomeMessageLength {
+		return ErrWelcomeMessageLength
+	}
+	s.welcomeMessage.Store(welcomeMessage)
+	return nil
+}
+
+// GetWelcomeMessage returns the welcome message that is sent in the
+// handshakes.
+func (s *Service) GetWelcomeMessage() string {
+	return s.welcomeMessage.Load().(string)
 }
 
 // Handshake initiates a handshake with a peer.
@@ -152,21 +173,26 @@ func (s *Service) Handshake(stream p2p.Stream, peerMultiaddr ma.Multiaddr, peerI
 			Overlay:   bzzAddress.Overlay.Bytes(),
 			Signature: bzzAddress.Signature,
 		},
-		NetworkID:      s.networkID,
-		Light:          s.lightNode,
-		WelcomeMessage: s.welcomeMessage,
+		NetworkID:         s.networkID,
+		Light:             s.lightNode,
+		DisabledProtocols: s.disabledProtocols,
+		WelcomeMessage:    s.GetWelcomeMessage(),
 	}); err != nil {
 		return nil, fmt.Errorf("write ack message: %w", err)
 	}
 
 	s.logger.Tracef("handshake finished for peer (outbound) %s", remoteBzzAddress.Overlay.String())
+	if len(resp.Ack.DisabledProtocols) > 0 {
+		s.logger.Debugf("handshake: peer %s disabled protocols %v", remoteBzzAddress.Overlay, resp.Ack.DisabledProtocols)
+	}
 	if len(resp.Ack.WelcomeMessage) > 0 {
 		s.logger.Infof("greeting <%s> from peer: %s", resp.Ack.WelcomeMessage, remoteBzzAddress.Overlay.String())
 	}
 
 	return &Info{
-		BzzAddress: remoteBzzAddress,
-		Light:      resp.Ack.Light,
+		BzzAddress:        remoteBzzAddress,
+		Light:             resp.Ack.Light,
+		DisabledProtocols: resp.Ack.DisabledProtocols,
 	}, nil
 }
 
@@ -226,9 +252,10 @@ func (s *Service) Handle(stream p2p.Stream, remoteMultiaddr ma.Multiaddr, remote
 				Overlay:   bzzAddress.Overlay.Bytes(),
 				Signature: bzzAddress.Signature,
 			},
-			NetworkID:      s.networkID,
-			Light:          s.lightNode,
-			WelcomeMessage: s.welcomeMessage,
+			NetworkID:         s.networkID,
+			Light:             s.lightNode,
+			DisabledProtocols: s.disabledProtocols,
+			WelcomeMessage:    s.GetWelcomeMessage(),
 		},
 	}); err != nil {
 		return nil, fmt.Errorf("write synack message: %w", err)
@@ -245,10 +272,14 @@ func (s *Service) Handle(stream p2p.Stream, remoteMultiaddr ma.Multiaddr, remote
 	}
 
 	s.logger.Tracef("handshake finished for peer (inbound) %s", remoteBzzAddress.Overlay.String())
+	if len(ack.DisabledProtocols) > 0 {
+		s.logger.Debugf("handshake: peer %s disabled protocols %v", remoteBzzAddress.Overlay, ack.DisabledProtocols)
+	}
 
 	return &Info{
-		BzzAddress: remoteBzzAddress,
-		Light:      ack.Light,
+		BzzAddress:        remoteBzzAddress,
+		Light:             ack.Light,
+		DisabledProtocols: ack.DisabledProtocols,
 	}, nil
 }
 
@@ -60,6 +60,10 @@ func (s *Stream) Close() error {
 	return nil
 }
 
+func (s *Stream) Version() string {
+	return ""
+}
+
 func (s *Stream) FullClose() error {
 	return nil
 }
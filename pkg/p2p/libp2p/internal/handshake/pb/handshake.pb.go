@@ -67,10 +67,11 @@ func (m *Syn) GetObservedUnderlay() []byte {
 }
 
 type Ack struct {
-	Address        *BzzAddress `protobuf:"bytes,1,opt,name=Address,proto3" json:"Address,omitempty"`
-	NetworkID      uint64      `protobuf:"varint,2,opt,name=NetworkID,proto3" json:"NetworkID,omitempty"`
-	Light          bool        `protobuf:"varint,3,opt,name=Light,proto3" json:"Light,omitempty"`
-	WelcomeMessage string      `protobuf:"bytes,99,opt,name=WelcomeMessage,proto3" json:"WelcomeMessage,omitempty"`
+	Address           *BzzAddress `protobuf:"bytes,1,opt,name=Address,proto3" json:"Address,omitempty"`
+	NetworkID         uint64      `protobuf:"varint,2,opt,name=NetworkID,proto3" json:"NetworkID,omitempty"`
+	Light             bool        `protobuf:"varint,3,opt,name=Light,proto3" json:"Light,omitempty"`
+	DisabledProtocols []string    `protobuf:"bytes,4,rep,name=DisabledProtocols,proto3" json:"DisabledProtocols,omitempty"`
+	WelcomeMessage    string      `protobuf:"bytes,99,opt,name=WelcomeMessage,proto3" json:"WelcomeMessage,omitempty"`
 }
 
 func (m *Ack) Reset()         { *m = Ack{} }
@@ -127,6 +128,13 @@ func (m *Ack) GetLight() bool {
 	return false
 }
 
+func (m *Ack) GetDisabledProtocols() []string {
+	if m != nil {
+		return m.DisabledProtocols
+	}
+	return nil
+}
+
 func (m *Ack) GetWelcomeMessage() string {
 	if m != nil {
 		return m.WelcomeMessage
@@ -256,26 +264,28 @@ func init() {
 func init() { proto.RegisterFile("handshake.proto", fileDescriptor_a77305914d5d202f) }
 
 var fileDescriptor_a77305914d5d202f = []byte{
-	// 302 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x91, 0x4d, 0x4b, 0xc3, 0x30,
-	0x1c, 0xc6, 0x97, 0x55, 0xf7, 0xf2, 0x77, 0x4c, 0x09, 0x0a, 0x45, 0x46, 0x08, 0x3d, 0x48, 0xf1,
-	0x30, 0x51, 0x3f, 0xc1, 0x86, 0x17, 0x61, 0x3a, 0x48, 0x11, 0xc1, 0x93, 0x7d, 0x09, 0xed, 0xe8,
-	0x4c, 0x47, 0x52, 0x27, 0xdd, 0xa7, 0x10, 0x3f, 0x95, 0xc7, 0x1d, 0x3d, 0x4a, 0xfb, 0x45, 0xa4,
-	0xd9, 0x4b, 0x75, 0x1e, 0x9f, 0x97, 0x36, 0xbf, 0x27, 0x81, 0xc3, 0xc8, 0x15, 0x81, 0x8a, 0xdc,
-	0x98, 0xf7, 0x67, 0x32, 0x49, 0x13, 0xdc, 0xde, 0x1a, 0xd6, 0x25, 0x18, 0x4e, 0x26, 0xf0, 0x39,
-	0x1c, 0x8d, 0x3d, 0xc5, 0xe5, 0x9c, 0x07, 0x0f, 0x22, 0xe0, 0x72, 0xea, 0x66, 0x26, 0xa2, 0xc8,
-	0xee, 0xb0, 0x7f, 0xbe, 0xf5, 0x81, 0xc0, 0x18, 0xf8, 0x31, 0xbe, 0x80, 0xe6, 0x20, 0x08, 0x24,
-	0x57, 0x4a, 0x57, 0x0f, 0xae, 0x4e, 0xfa, 0xd5, 0x41, 0xc3, 0xc5, 0x62, 0x1d, 0xb2, 0x4d, 0x0b,
-	0xf7, 0xa0, 0x7d, 0xcf, 0xd3, 0xb7, 0x44, 0xc6, 0xb7, 0x37, 0x66, 0x9d, 0x22, 0x7b, 0x8f, 0x55,
-	0x06, 0x3e, 0x86, 0xfd, 0xd1, 0x24, 0x8c, 0x52, 0xd3, 0xa0, 0xc8, 0x6e, 0xb1, 0x95, 0xc0, 0x67,
-	0xd0, 0x7d, 0xe4, 0x53, 0x3f, 0x79, 0xe1, 0x77, 0x5c, 0x29, 0x37, 0xe4, 0xa6, 0x4f, 0x91, 0xdd,
-	0x66, 0x3b, 0xae, 0x35, 0x82, 0x86, 0x93, 0x89, 0x12, 0x8b, 0xea, 0x45, 0x6b, 0xa4, 0xee, 0x2f,
-	0x24, 0x27, 0x13, 0x4c, 0x8f, 0xa5, 0x9a, 0x5f, 0x13, 0xfc, 0x6d, 0x0c, 0xfc, 0x98, 0x95, 0x91,
-	0xf5, 0x0c, 0x50, 0x0d, 0xc0, 0xa7, 0xd0, 0xda, 0xb9, 0x94, 0xad, 0x2e, 0x37, 0x39, 0x93, 0x50,
-	0xb8, 0xe9, 0xab, 0xe4, 0xfa, 0x8f, 0x1d, 0x56, 0x19, 0xd8, 0x84, 0xe6, 0x78, 0xbe, 0xfa, 0xd0,
-	0xd0, 0xd9, 0x46, 0x0e, 0x7b, 0x9f, 0x39, 0x41, 0xcb, 0x9c, 0xa0, 0xef, 0x9c, 0xa0, 0xf7, 0x82,
-	0xd4, 0x96, 0x05, 0xa9, 0x7d, 0x15, 0xa4, 0xf6, 0x54, 0x9f, 0x79, 0x5e, 0x43, 0xbf, 0xd3, 0xf5,
-	0x4f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x9d, 0x61, 0xea, 0x46, 0xba, 0x01, 0x00, 0x00,
+	// 323 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x91, 0xcb, 0x4a, 0xf3, 0x40,
+	0x18, 0x86, 0x3b, 0x4d, 0xff, 0xb6, 0xf9, 0xfe, 0x52, 0x75, 0x50, 0x18, 0xa4, 0x84, 0x21, 0x0b,
+	0x09, 0x22, 0x15, 0xf5, 0x0a, 0x5a, 0xba, 0x11, 0xaa, 0x95, 0x09, 0x22, 0xb8, 0x32, 0x87, 0x8f,
+	0xb6, 0x24, 0x26, 0x25, 0x13, 0x2b, 0xe9, 0x55, 0x78, 0x49, 0x2e, 0x5d, 0x76, 0xe9, 0x52, 0xda,
+	0x1b, 0x91, 0x4c, 0x0f, 0x91, 0x76, 0xf9, 0x1e, 0x92, 0x99, 0xe7, 0x1d, 0x38, 0x18, 0x39, 0x91,
+	0x2f, 0x47, 0x4e, 0x80, 0xed, 0x49, 0x12, 0xa7, 0x31, 0xd5, 0xb7, 0x86, 0x79, 0x05, 0x9a, 0x9d,
+	0x45, 0xf4, 0x1c, 0x0e, 0x07, 0xae, 0xc4, 0x64, 0x8a, 0xfe, 0x63, 0xe4, 0x63, 0x12, 0x3a, 0x19,
+	0x23, 0x9c, 0x58, 0x0d, 0xb1, 0xe7, 0x9b, 0x9f, 0x04, 0xb4, 0x8e, 0x17, 0xd0, 0x4b, 0xa8, 0x75,
+	0x7c, 0x3f, 0x41, 0x29, 0x55, 0xf5, 0xff, 0xf5, 0x49, 0xbb, 0x38, 0xa8, 0x3b, 0x9b, 0xad, 0x43,
+	0xb1, 0x69, 0xd1, 0x16, 0xe8, 0xf7, 0x98, 0xbe, 0xc7, 0x49, 0x70, 0xdb, 0x63, 0x65, 0x4e, 0xac,
+	0x8a, 0x28, 0x0c, 0x7a, 0x0c, 0xff, 0xfa, 0xe3, 0xe1, 0x28, 0x65, 0x1a, 0x27, 0x56, 0x5d, 0xac,
+	0x04, 0xbd, 0x80, 0xa3, 0xde, 0x58, 0x3a, 0x6e, 0x88, 0xfe, 0x43, 0x7e, 0x77, 0x2f, 0x0e, 0x25,
+	0xab, 0x70, 0xcd, 0xd2, 0xc5, 0x7e, 0x40, 0xcf, 0xa0, 0xf9, 0x84, 0xa1, 0x17, 0xbf, 0xe2, 0x1d,
+	0x4a, 0xe9, 0x0c, 0x91, 0x79, 0x9c, 0x58, 0xba, 0xd8, 0x71, 0xcd, 0x3e, 0x54, 0xed, 0x2c, 0xca,
+	0x21, 0xb8, 0xe2, 0x5f, 0x03, 0x34, 0xff, 0x00, 0xd8, 0x59, 0x24, 0xd4, 0x34, 0x5c, 0xd1, 0xb2,
+	0xf2, 0x5e, 0xa3, 0xe3, 0x05, 0x22, 0x8f, 0xcc, 0x17, 0x80, 0x02, 0x97, 0x9e, 0x42, 0x7d, 0x67,
+	0xc2, 0xad, 0xce, 0x17, 0xb0, 0xc7, 0xc3, 0xc8, 0x49, 0xdf, 0x12, 0x54, 0x7f, 0x6c, 0x88, 0xc2,
+	0xa0, 0x0c, 0x6a, 0x83, 0xe9, 0xea, 0x43, 0x4d, 0x65, 0x1b, 0xd9, 0x6d, 0x7d, 0x2d, 0x0c, 0x32,
+	0x5f, 0x18, 0xe4, 0x67, 0x61, 0x90, 0x8f, 0xa5, 0x51, 0x9a, 0x2f, 0x8d, 0xd2, 0xf7, 0xd2, 0x28,
+	0x3d, 0x97, 0x27, 0xae, 0x5b, 0x55, 0xaf, 0x7a, 0xf3, 0x3b, 0x00, 0xb1, 0xc4, 0x12, 0x4d, 0xe8,
+	0x01, 0x00, 0x00,
 }
 
 func (m *Syn) Marshal() (dAtA []byte, err error) {
@@ -337,6 +347,15 @@ func (m *Ack) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x9a
 	}
+	if len(m.DisabledProtocols) > 0 {
+		for iNdEx := len(m.DisabledProtocols) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.DisabledProtocols[iNdEx])
+			copy(dAtA[i:], m.DisabledProtocols[iNdEx])
+			i = encodeVarintHandshake(dAtA, i, uint64(len(m.DisabledProtocols[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
 	if m.Light {
 		i--
 		if m.Light {
@@ -498,6 +517,12 @@ func (m *Ack) Size() (n int) {
 	if m.Light {
 		n += 2
 	}
+	if len(m.DisabledProtocols) > 0 {
+		for _, s := range m.DisabledProtocols {
+			l = len(s)
+			n += 1 + l + sovHandshake(uint64(l))
+		}
+	}
 	l = len(m.WelcomeMessage)
 	if l > 0 {
 		n += 2 + l + sovHandshake(uint64(l))
@@ -740,6 +765,38 @@ func (m *Ack) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.Light = bool(v != 0)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DisabledProtocols", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DisabledProtocols = append(m.DisabledProtocols, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		case 99:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field WelcomeMessage", wireType)
@@ -0,0 +1,54 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/handshake/pb"
+	"github.com/ethersphere/bee/pkg/p2p/protobuf/protobuftest"
+)
+
+// TestMessages checks that the wire format of the messages is compatible
+// with the released version of the protocol.
+func TestMessages(t *testing.T) {
+	protobuftest.Check(t,
+		protobuftest.Message{
+			Message: &pb.Syn{ObservedUnderlay: []byte{1, 2, 3}},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "ObservedUnderlay", WireType: "bytes"},
+			},
+			Wire: "0a03010203",
+		},
+		protobuftest.Message{
+			Message: &pb.Ack{Address: &pb.BzzAddress{Underlay: []byte{1}, Signature: []byte{2}, Overlay: []byte{3}}, NetworkID: 1, Light: true, DisabledProtocols: []string{"hive"}, WelcomeMessage: "hello"},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Address", WireType: "bytes"},
+				{Number: 2, Name: "NetworkID", WireType: "varint"},
+				{Number: 3, Name: "Light", WireType: "varint"},
+				{Number: 4, Name: "DisabledProtocols", WireType: "bytes", Repeated: true},
+				{Number: 99, Name: "WelcomeMessage", WireType: "bytes"},
+			},
+			Wire: "0a090a01011201021a0103100118012204686976659a060568656c6c6f",
+		},
+		protobuftest.Message{
+			Message: &pb.SynAck{Syn: &pb.Syn{ObservedUnderlay: []byte{1}}, Ack: &pb.Ack{NetworkID: 1}},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Syn", WireType: "bytes"},
+				{Number: 2, Name: "Ack", WireType: "bytes"},
+			},
+			Wire: "0a030a010112021001",
+		},
+		protobuftest.Message{
+			Message: &pb.BzzAddress{Underlay: []byte{1}, Signature: []byte{2}, Overlay: []byte{3}},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Underlay", WireType: "bytes"},
+				{Number: 2, Name: "Signature", WireType: "bytes"},
+				{Number: 3, Name: "Overlay", WireType: "bytes"},
+			},
+			Wire: "0a01011201021a0103",
+		},
+	)
+}
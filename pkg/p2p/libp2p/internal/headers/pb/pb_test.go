@@ -0,0 +1,34 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/headers/pb"
+	"github.com/ethersphere/bee/pkg/p2p/protobuf/protobuftest"
+)
+
+// TestMessages checks that the wire format of the messages is compatible
+// with the released version of the protocol.
+func TestMessages(t *testing.T) {
+	protobuftest.Check(t,
+		protobuftest.Message{
+			Message: &pb.Headers{Headers: []*pb.Header{{Key: "key", Value: []byte("value")}}},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "headers", WireType: "bytes", Repeated: true},
+			},
+			Wire: "0a0c0a036b6579120576616c7565",
+		},
+		protobuftest.Message{
+			Message: &pb.Header{Key: "key", Value: []byte("value")},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "key", WireType: "bytes"},
+				{Number: 2, Name: "value", WireType: "bytes"},
+			},
+			Wire: "0a036b6579120576616c7565",
+		},
+	)
+}
@@ -10,14 +10,19 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"runtime/debug"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/bzz"
+	"github.com/ethersphere/bee/pkg/clock"
 	beecrypto "github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/breaker"
 	handshake "github.com/ethersphere/bee/pkg/p2p/libp2p/internal/handshake"
+	"github.com/ethersphere/bee/pkg/slowlog"
+	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/topology"
 	"github.com/ethersphere/bee/pkg/tracing"
@@ -54,13 +59,31 @@ type Service struct {
 	peers             *peerRegistry
 	topologyNotifier  topology.Notifier
 	connectionBreaker breaker.Interface
+	allowlist         *allowlist
+	blocklist         *blocklist
+	denylist          *denylist
+	connLimits        connLimits
+	bandwidthLimiters map[string]*protocolLimiter
+	proxied           bool
+	versionOverrides  map[string]p2p.VersionOverride
 	logger            logging.Logger
+	slowDialLog       *slowlog.Logger
 	tracer            *tracing.Tracer
 }
 
 type Options struct {
-	PrivateKey     *ecdsa.PrivateKey
-	NATAddr        string
+	PrivateKey *ecdsa.PrivateKey
+	NATAddr    string
+	// NAT6Addr is the address advertised to peers that are connected over
+	// IPv6. If it is not set, NATAddr is advertised to all peers.
+	NAT6Addr string
+	// ProxyAddr is the address of the SOCKS5 proxy, such as Tor, that all
+	// outbound connections are dialed through, with the host names
+	// resolved by the proxy. The node then does not listen for inbound
+	// connections and advertises only its peer id, without any transport
+	// address, UPnP is not used and only the TCP transport is supported.
+	// The node is reachable only by the peers that it connected to.
+	ProxyAddr      string
 	EnableWS       bool
 	EnableQUIC     bool
 	LightNode      bool
@@ -68,16 +91,59 @@ type Options struct {
 	Addressbook    addressbook.Putter
 	Logger         logging.Logger
 	Tracer         *tracing.Tracer
+	// DisabledProtocols are the names of the protocols that the node does
+	// not run, advertised to peers in the handshake.
+	DisabledProtocols []string
+	// SlowDialThreshold is the duration of dialing a peer above which the
+	// dial is logged as slow. It is not logged if it is zero.
+	SlowDialThreshold time.Duration
+	// AllowedOverlays and AllowedUnderlays enable the closed network mode,
+	// in which the node connects only with the peers whose overlay address
+	// or underlay peer ID is listed. Underlays must include the peer ID.
+	AllowedOverlays  []swarm.Address
+	AllowedUnderlays []ma.Multiaddr
+	// DeniedOverlays are the overlay addresses of the peers that the node
+	// does not connect with. They can be replaced with SetDeniedOverlays.
+	DeniedOverlays []swarm.Address
+	// BandwidthLimits are the limits of the bandwidth of the streams of the
+	// protocols in bytes per second, by protocol name, so that background
+	// protocols do not starve the others. Reads and writes are limited
+	// separately.
+	BandwidthLimits map[string]int64
+	// InboundIPLimit and InboundSubnetLimit are the maximal numbers of the
+	// peers with inbound connections from the same IP address and from the
+	// same /24 IPv4 or /48 IPv6 subnet. Inbound connections of further peers
+	// are closed before the handshake. They are not limited if the limit is
+	// zero.
+	InboundIPLimit     int
+	InboundSubnetLimit int
+	// VersionOverrides are the newer versions of the protocols, by protocol
+	// name, that are requested from the selected peers instead of the
+	// versions that the protocols request, to roll them out gradually. The
+	// versions that the protocols request are used with the peers that do
+	// not support the newer ones.
+	VersionOverrides map[string]p2p.VersionOverride
+	// StateStore persists the blocklist of peers over the restarts of the
+	// node. The blocklist is kept only in memory if it is not set.
+	StateStore storage.StateStorer
+	// Clock times the bandwidth limits and the expiration of the blocklist
+	// entries, the system clock if it is not set.
+	Clock clock.Clock
 }
 
 func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay swarm.Address, addr string, o Options) (*Service, error) {
+	if o.Clock == nil {
+		o.Clock = clock.System
+	}
+
 	host, port, err := net.SplitHostPort(addr)
 	if err != nil {
 		return nil, fmt.Errorf("address: %w", err)
 	}
 
+	// without the host, listen on all interfaces of both IP families
 	ip4Addr := "0.0.0.0"
-	ip6Addr := "::1"
+	ip6Addr := "::"
 
 	if host != "" {
 		ip := net.ParseIP(host)
@@ -111,19 +177,47 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		}
 	}
 
+	if o.ProxyAddr != "" && (o.EnableWS || o.EnableQUIC) {
+		return nil, errors.New("proxy: only the tcp transport is supported")
+	}
+
+	allowlist, err := newAllowlist(o.AllowedOverlays, o.AllowedUnderlays)
+	if err != nil {
+		return nil, fmt.Errorf("allowlist: %w", err)
+	}
+
+	blocklist, err := newBlocklist(o.StateStore, o.Clock)
+	if err != nil {
+		return nil, fmt.Errorf("blocklist: %w", err)
+	}
+
+	bandwidthLimiters, err := newProtocolLimiters(o.BandwidthLimits, o.Clock)
+	if err != nil {
+		return nil, fmt.Errorf("bandwidth limits: %w", err)
+	}
+
 	security := libp2p.DefaultSecurity
 	libp2pPeerstore := pstoremem.NewPeerstore()
 
 	var natManager basichost.NATManager
 
 	opts := []libp2p.Option{
-		libp2p.ListenAddrStrings(listenAddrs...),
 		security,
 		// Use dedicated peerstore instead the global DefaultPeerstore
 		libp2p.Peerstore(libp2pPeerstore),
 	}
 
-	if o.NATAddr == "" {
+	if o.ProxyAddr == "" {
+		opts = append(opts, libp2p.ListenAddrStrings(listenAddrs...))
+	} else {
+		opts = append(opts,
+			libp2p.NoListenAddrs,
+			// do not advertise any addresses with the identify protocol
+			libp2p.AddrsFactory(func([]ma.Multiaddr) []ma.Multiaddr { return nil }),
+		)
+	}
+
+	if o.NATAddr == "" && o.ProxyAddr == "" {
 		opts = append(opts,
 			libp2p.NATManager(func(n network.Network) basichost.NATManager {
 				natManager = basichost.NewNATManager(n)
@@ -142,6 +236,16 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		libp2p.Transport(tcp.NewTCPTransport),
 	}
 
+	if o.ProxyAddr != "" {
+		proxyDialer, err := newProxyDialer(o.ProxyAddr)
+		if err != nil {
+			return nil, fmt.Errorf("proxy: %w", err)
+		}
+		transports = []libp2p.Option{
+			libp2p.Transport(newProxyTransport(proxyDialer)),
+		}
+	}
+
 	if o.EnableWS {
 		transports = append(transports, libp2p.Transport(ws.New))
 	}
@@ -159,17 +263,22 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 
 	// If you want to help other peers to figure out if they are behind
 	// NATs, you can launch the server-side of AutoNAT too (AutoRelay
-	// already runs the client)
-	if _, err = autonat.NewAutoNATService(ctx, h,
-		// Support same non default security and transport options as
-		// original host.
-		append(transports, security)...,
-	); err != nil {
-		return nil, fmt.Errorf("autonat: %w", err)
+	// already runs the client). It is not launched in the proxy mode, as
+	// the node does not accept inbound connections.
+	if o.ProxyAddr == "" {
+		if _, err = autonat.NewAutoNATService(ctx, h,
+			// Support same non default security and transport options as
+			// original host.
+			append(transports, security)...,
+		); err != nil {
+			return nil, fmt.Errorf("autonat: %w", err)
+		}
 	}
 
 	var advertisableAddresser handshake.AdvertisableAddressResolver
-	if o.NATAddr == "" {
+	if o.ProxyAddr != "" {
+		advertisableAddresser = hiddenAddressResolver{}
+	} else if o.NATAddr == "" {
 		advertisableAddresser = &UpnpAddressResolver{
 			host: h,
 		}
@@ -180,7 +289,18 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		}
 	}
 
-	handshakeService, err := handshake.New(signer, advertisableAddresser, overlay, networkID, o.LightNode, o.WelcomeMessage, o.Logger)
+	if o.NAT6Addr != "" && o.ProxyAddr == "" {
+		ip6Resolver, err := newStaticIP6AddressResolver(o.NAT6Addr)
+		if err != nil {
+			return nil, fmt.Errorf("static nat ip6: %w", err)
+		}
+		advertisableAddresser = &familyAddressResolver{
+			ip4: advertisableAddresser,
+			ip6: ip6Resolver,
+		}
+	}
+
+	handshakeService, err := handshake.New(signer, advertisableAddresser, overlay, networkID, o.LightNode, o.DisabledProtocols, o.WelcomeMessage, o.Logger)
 	if err != nil {
 		return nil, fmt.Errorf("handshake service: %w", err)
 	}
@@ -197,8 +317,16 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		peers:             peerRegistry,
 		addressbook:       o.Addressbook,
 		logger:            o.Logger,
+		slowDialLog:       slowlog.New(o.Logger, "dial", o.SlowDialThreshold),
 		tracer:            o.Tracer,
 		connectionBreaker: breaker.NewBreaker(breaker.Options{}), // use default options
+		allowlist:         allowlist,
+		blocklist:         blocklist,
+		denylist:          newDenylist(o.DeniedOverlays),
+		connLimits:        connLimits{perIP: o.InboundIPLimit, perSubnet: o.InboundSubnetLimit},
+		bandwidthLimiters: bandwidthLimiters,
+		proxied:           o.ProxyAddr != "",
+		versionOverrides:  o.VersionOverrides,
 	}
 	// Construct protocols.
 	id := protocol.ID(p2p.NewSwarmStreamName(handshake.ProtocolName, handshake.ProtocolVersion, handshake.StreamName))
@@ -211,6 +339,23 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 	s.host.SetStreamHandlerMatch(id, matcher, func(stream network.Stream) {
 		peerID := stream.Conn().RemotePeer()
 		handshakeStream := NewStream(stream)
+		if !s.allowlist.mayAllow(peerID) {
+			s.logger.Debugf("handshake: peer %s not allowed", peerID)
+			_ = handshakeStream.Reset()
+			_ = s.disconnect(peerID)
+			return
+		}
+
+		if !s.inboundAllowed(stream.Conn()) {
+			s.logger.Debugf("handshake: peer %s over the inbound connection limits from %s", peerID, stream.Conn().RemoteMultiaddr())
+			s.metrics.InboundConnectionLimitedCount.Inc()
+			_ = handshakeStream.Reset()
+			// only the rejected connection is closed, as the peer may be
+			// connected over others that are within the limits
+			_ = stream.Conn().Close()
+			return
+		}
+
 		i, err := s.handshakeService.Handle(handshakeStream, stream.Conn().RemoteMultiaddr(), peerID)
 		if err != nil {
 			s.logger.Debugf("handshake: handle %s: %v", peerID, err)
@@ -220,7 +365,28 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 			return
 		}
 
-		if exists := s.peers.addIfNotExists(stream.Conn(), i.BzzAddress.Overlay); exists {
+		if !s.allowlist.allowed(peerID, i.BzzAddress.Overlay) {
+			s.logger.Debugf("handshake: peer %s with overlay %s not allowed", peerID, i.BzzAddress.Overlay)
+			_ = handshakeStream.Reset()
+			_ = s.disconnect(peerID)
+			return
+		}
+
+		if s.blocklist.blocked(i.BzzAddress.Overlay) {
+			s.logger.Debugf("handshake: peer %s with overlay %s blocklisted", peerID, i.BzzAddress.Overlay)
+			_ = handshakeStream.Reset()
+			_ = s.disconnect(peerID)
+			return
+		}
+
+		if s.denylist.denied(i.BzzAddress.Overlay) {
+			s.logger.Debugf("handshake: peer %s with overlay %s denied", peerID, i.BzzAddress.Overlay)
+			_ = handshakeStream.Reset()
+			_ = s.disconnect(peerID)
+			return
+		}
+
+		if exists := s.peers.addIfNotExists(stream.Conn(), i.BzzAddress.Overlay, i.Light, i.DisabledProtocols); exists {
 			if err = handshakeStream.FullClose(); err != nil {
 				s.logger.Debugf("handshake: could not close stream %s: %v", peerID, err)
 				s.logger.Errorf("unable to handshake with peer %v", peerID)
@@ -280,7 +446,18 @@ func (s *Service) AddProtocol(p p2p.ProtocolSpec) (err error) {
 				return
 			}
 
-			stream := newStream(streamlibp2p)
+			stream := newStream(s.limitStream(streamlibp2p, p.Name))
+
+			// a panic of the handler, as on malformed input from the
+			// peer, resets only the stream instead of crashing the node
+			defer func() {
+				if r := recover(); r != nil {
+					s.metrics.HandlerPanicCount.Inc()
+					s.logger.Errorf("handle protocol %s/%s: stream %s: peer %s: panic: %v", p.Name, p.Version, ss.Name, overlay, r)
+					s.logger.Debugf("handle protocol %s/%s: stream %s: peer %s: panic stack: %s", p.Name, p.Version, ss.Name, overlay, debug.Stack())
+					_ = stream.Reset()
+				}
+			}()
 
 			// exchange headers
 			if err := handleHeaders(ss.Headler, stream); err != nil {
@@ -306,11 +483,25 @@ func (s *Service) AddProtocol(p p2p.ProtocolSpec) (err error) {
 
 			s.metrics.HandledStreamCount.Inc()
 			if err := ss.Handler(ctx, p2p.Peer{Address: overlay}, stream); err != nil {
-				var e *p2p.DisconnectError
-				if errors.As(err, &e) {
+				var de *p2p.DisconnectError
+				if errors.As(err, &de) {
 					_ = s.Disconnect(overlay)
 				}
 
+				var bpe *p2p.BlockPeerError
+				if errors.As(err, &bpe) {
+					if err := s.Blocklist(overlay, bpe.Duration()); err != nil {
+						logger.Debugf("handle protocol %s/%s: stream %s: peer %s: blocklist: %v", p.Name, p.Version, ss.Name, overlay, err)
+					}
+				}
+
+				var te *p2p.TemporaryError
+				if errors.As(err, &te) {
+					s.metrics.HandlerTemporaryErrorCount.Inc()
+					logger.Debugf("temporary error handle protocol %s/%s: stream %s: peer %s: retry after %s: error: %v", p.Name, p.Version, ss.Name, overlay, te.RetryAfter(), err)
+					return
+				}
+
 				logger.Debugf("error handle protocol %s/%s: stream %s: peer %s: error: %v", p.Name, p.Version, ss.Name, overlay, err)
 				return
 			}
@@ -346,6 +537,14 @@ func buildUnderlayAddress(addr ma.Multiaddr, peerID libp2ppeer.ID) (ma.Multiaddr
 	return addr.Encapsulate(hostAddr), nil
 }
 
+// dialProxied dials the peer without resolving the host names of its
+// addresses, as the host connect does, so that they are resolved by the proxy.
+func (s *Service) dialProxied(ctx context.Context, info libp2ppeer.AddrInfo) error {
+	s.host.Peerstore().AddAddrs(info.ID, info.Addrs, peerstore.TempAddrTTL)
+	_, err := s.host.Network().DialPeer(ctx, info.ID)
+	return err
+}
+
 func (s *Service) ConnectNotify(ctx context.Context, addr ma.Multiaddr) (address *bzz.Address, err error) {
 	info, err := libp2ppeer.AddrInfoFromP2pAddr(addr)
 	if err != nil {
@@ -376,7 +575,19 @@ func (s *Service) Connect(ctx context.Context, addr ma.Multiaddr) (address *bzz.
 		return nil, p2p.ErrAlreadyConnected
 	}
 
-	if err := s.connectionBreaker.Execute(func() error { return s.host.Connect(ctx, *info) }); err != nil {
+	if !s.allowlist.mayAllow(info.ID) {
+		return nil, p2p.ErrPeerNotAllowed
+	}
+
+	connect := s.host.Connect
+	if s.proxied {
+		connect = s.dialProxied
+	}
+
+	dialStart := time.Now()
+	err = s.connectionBreaker.Execute(func() error { return connect(ctx, *info) })
+	s.slowDialLog.Observe(dialStart, "peer %s", addr)
+	if err != nil {
 		if errors.Is(err, breaker.ErrClosed) {
 			return nil, p2p.NewConnectionBackoffError(err, s.connectionBreaker.ClosedUntil())
 		}
@@ -397,7 +608,25 @@ func (s *Service) Connect(ctx context.Context, addr ma.Multiaddr) (address *bzz.
 		return nil, fmt.Errorf("handshake: %w", err)
 	}
 
-	if exists := s.peers.addIfNotExists(stream.Conn(), i.BzzAddress.Overlay); exists {
+	if !s.allowlist.allowed(info.ID, i.BzzAddress.Overlay) {
+		_ = handshakeStream.Reset()
+		_ = s.disconnect(info.ID)
+		return nil, p2p.ErrPeerNotAllowed
+	}
+
+	if s.blocklist.blocked(i.BzzAddress.Overlay) {
+		_ = handshakeStream.Reset()
+		_ = s.disconnect(info.ID)
+		return nil, p2p.ErrPeerBlocklisted
+	}
+
+	if s.denylist.denied(i.BzzAddress.Overlay) {
+		_ = handshakeStream.Reset()
+		_ = s.disconnect(info.ID)
+		return nil, p2p.ErrPeerDenied
+	}
+
+	if exists := s.peers.addIfNotExists(stream.Conn(), i.BzzAddress.Overlay, i.Light, i.DisabledProtocols); exists {
 		if err := handshakeStream.FullClose(); err != nil {
 			_ = s.disconnect(info.ID)
 			return nil, fmt.Errorf("peer exists, full close: %w", err)
@@ -444,6 +673,17 @@ func (s *Service) Peers() []p2p.Peer {
 	return s.peers.peers()
 }
 
+// IsLightNode returns true if the connected peer is a light node.
+func (s *Service) IsLightNode(overlay swarm.Address) bool {
+	return s.peers.isLight(overlay)
+}
+
+// SetWelcomeMessage changes the welcome message that is sent to the peers in
+// the handshakes with them after it is set.
+func (s *Service) SetWelcomeMessage(welcomeMessage string) error {
+	return s.handshakeService.SetWelcomeMessage(welcomeMessage)
+}
+
 func (s *Service) SetNotifier(n topology.Notifier) {
 	s.topologyNotifier = n
 	s.peers.setDisconnecter(n)
@@ -456,12 +696,16 @@ func (s *Service) NewStream(ctx context.Context, overlay swarm.Address, headers
 		return nil, p2p.ErrPeerNotFound
 	}
 
-	streamlibp2p, err := s.newStreamForPeerID(ctx, peerID, protocolName, protocolVersion, streamName)
+	if s.peers.protocolDisabled(overlay, protocolName) {
+		return nil, p2p.ErrProtocolDisabled
+	}
+
+	streamlibp2p, err := s.newStreamForOverlay(ctx, overlay, peerID, protocolName, protocolVersion, streamName)
 	if err != nil {
 		return nil, fmt.Errorf("new stream for peerid: %w", err)
 	}
 
-	stream := newStream(streamlibp2p)
+	stream := newStream(s.limitStream(streamlibp2p, protocolName))
 
 	// tracing: add span context header
 	if headers == nil {
@@ -480,6 +724,26 @@ func (s *Service) NewStream(ctx context.Context, overlay swarm.Address, headers
 	return stream, nil
 }
 
+// newStreamForOverlay creates the stream with the newer version of the
+// protocol if the peer is selected by its version override, or with the
+// requested version if it is not selected or does not support the newer one.
+// The version that is used is returned by the Version method of the stream,
+// so that the protocol can use the messages of that version.
+func (s *Service) newStreamForOverlay(ctx context.Context, overlay swarm.Address, peerID libp2ppeer.ID, protocolName, protocolVersion, streamName string) (network.Stream, error) {
+	o, ok := s.versionOverrides[protocolName]
+	if !ok || o.Version == protocolVersion || !o.Selects(overlay) {
+		return s.newStreamForPeerID(ctx, peerID, protocolName, protocolVersion, streamName)
+	}
+
+	st, err := s.newStreamForPeerID(ctx, peerID, protocolName, o.Version, streamName)
+	var incompatible *p2p.IncompatibleStreamError
+	if errors.As(err, &incompatible) {
+		s.logger.Tracef("protocol %s/%s not supported by peer %s, using version %s", protocolName, o.Version, overlay, protocolVersion)
+		return s.newStreamForPeerID(ctx, peerID, protocolName, protocolVersion, streamName)
+	}
+	return st, err
+}
+
 func (s *Service) newStreamForPeerID(ctx context.Context, peerID libp2ppeer.ID, protocolName, protocolVersion, streamName string) (network.Stream, error) {
 	swarmStreamName := p2p.NewSwarmStreamName(protocolName, protocolVersion, streamName)
 	st, err := s.host.NewStream(ctx, peerID, protocol.ID(swarmStreamName))
@@ -17,6 +17,11 @@ type metrics struct {
 	HandledConnectionCount prometheus.Counter
 	CreatedStreamCount     prometheus.Counter
 	HandledStreamCount     prometheus.Counter
+	HandlerPanicCount      prometheus.Counter
+
+	InboundConnectionLimitedCount prometheus.Counter
+	BlocklistedPeerCount          prometheus.Counter
+	HandlerTemporaryErrorCount    prometheus.Counter
 }
 
 func newMetrics() metrics {
@@ -47,6 +52,30 @@ func newMetrics() metrics {
 			Name:      "handled_stream_count",
 			Help:      "Number of handled incoming libp2p streams.",
 		}),
+		HandlerPanicCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "handler_panic_count",
+			Help:      "Number of protocol handler panics that were recovered.",
+		}),
+		InboundConnectionLimitedCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "inbound_connection_limited_count",
+			Help:      "Number of inbound connections closed over the per IP address or subnet limits.",
+		}),
+		BlocklistedPeerCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "blocklisted_peer_count",
+			Help:      "Number of peers added to the blocklist.",
+		}),
+		HandlerTemporaryErrorCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "handler_temporary_error_count",
+			Help:      "Number of protocol handler errors that were temporary.",
+		}),
 	}
 }
 
@@ -22,6 +22,8 @@ type peerRegistry struct {
 	overlays    map[libp2ppeer.ID]swarm.Address             // map underlay peer id to overlay address
 	connections map[libp2ppeer.ID]map[network.Conn]struct{} // list of connections for safe removal on Disconnect notification
 	streams     map[libp2ppeer.ID]map[network.Stream]context.CancelFunc
+	light       map[string]struct{}            // overlay addresses of the light node peers
+	disabled    map[string]map[string]struct{} // map overlay address to the names of the protocols that the peer does not run
 	mu          sync.RWMutex
 
 	disconnecter     topology.Disconnecter // peerRegistry notifies topology on peer disconnection
@@ -34,6 +36,8 @@ func newPeerRegistry() *peerRegistry {
 		overlays:    make(map[libp2ppeer.ID]swarm.Address),
 		connections: make(map[libp2ppeer.ID]map[network.Conn]struct{}),
 		streams:     make(map[libp2ppeer.ID]map[network.Stream]context.CancelFunc),
+		light:       make(map[string]struct{}),
+		disabled:    make(map[string]map[string]struct{}),
 
 		Notifiee: new(network.NoopNotifiee),
 	}
@@ -61,6 +65,8 @@ func (r *peerRegistry) Disconnected(_ network.Network, c network.Conn) {
 	overlay := r.overlays[peerID]
 	delete(r.overlays, peerID)
 	delete(r.underlays, overlay.ByteString())
+	delete(r.light, overlay.ByteString())
+	delete(r.disabled, overlay.ByteString())
 
 	delete(r.connections[peerID], c)
 	if len(r.connections[peerID]) == 0 {
@@ -122,7 +128,7 @@ func (r *peerRegistry) peers() []p2p.Peer {
 	return peers
 }
 
-func (r *peerRegistry) addIfNotExists(c network.Conn, overlay swarm.Address) (exists bool) {
+func (r *peerRegistry) addIfNotExists(c network.Conn, overlay swarm.Address, light bool, disabledProtocols []string) (exists bool) {
 	peerID := c.RemotePeer()
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -136,6 +142,16 @@ func (r *peerRegistry) addIfNotExists(c network.Conn, overlay swarm.Address) (ex
 	if _, exists := r.underlays[overlay.ByteString()]; !exists {
 		r.underlays[overlay.ByteString()] = peerID
 		r.overlays[peerID] = overlay
+		if light {
+			r.light[overlay.ByteString()] = struct{}{}
+		}
+		if len(disabledProtocols) > 0 {
+			disabled := make(map[string]struct{}, len(disabledProtocols))
+			for _, name := range disabledProtocols {
+				disabled[name] = struct{}{}
+			}
+			r.disabled[overlay.ByteString()] = disabled
+		}
 		return false
 	}
 
@@ -149,6 +165,22 @@ func (r *peerRegistry) peerID(overlay swarm.Address) (peerID libp2ppeer.ID, foun
 	return peerID, found
 }
 
+func (r *peerRegistry) isLight(overlay swarm.Address) bool {
+	r.mu.RLock()
+	_, light := r.light[overlay.ByteString()]
+	r.mu.RUnlock()
+	return light
+}
+
+// protocolDisabled returns true if the peer announced in the handshake that
+// it does not run the protocol.
+func (r *peerRegistry) protocolDisabled(overlay swarm.Address, protocolName string) bool {
+	r.mu.RLock()
+	_, disabled := r.disabled[overlay.ByteString()][protocolName]
+	r.mu.RUnlock()
+	return disabled
+}
+
 func (r *peerRegistry) overlay(peerID libp2ppeer.ID) (swarm.Address, bool) {
 	r.mu.RLock()
 	overlay, found := r.overlays[peerID]
@@ -161,6 +193,8 @@ func (r *peerRegistry) remove(peerID libp2ppeer.ID) {
 	overlay, found := r.overlays[peerID]
 	delete(r.overlays, peerID)
 	delete(r.underlays, overlay.ByteString())
+	delete(r.light, overlay.ByteString())
+	delete(r.disabled, overlay.ByteString())
 	delete(r.connections, peerID)
 	for _, cancel := range r.streams[peerID] {
 		cancel()
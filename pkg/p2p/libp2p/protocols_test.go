@@ -7,8 +7,10 @@ package libp2p_test
 import (
 	"context"
 	"errors"
+	"io/ioutil"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p"
@@ -186,6 +188,118 @@ func TestNewStream_semanticVersioning(t *testing.T) {
 	}
 }
 
+func TestNewStreamBandwidthLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2p.Options{})
+
+	// the limit allows a burst of a second worth of data
+	s2, _ := newService(t, 1, libp2p.Options{
+		BandwidthLimits: map[string]int64{testProtocolName: 10000},
+	})
+
+	received := make(chan int, 1)
+	if err := s1.AddProtocol(newTestProtocol(func(_ context.Context, _ p2p.Peer, s p2p.Stream) error {
+		defer s.Close()
+		data, err := ioutil.ReadAll(s)
+		if err != nil {
+			return err
+		}
+		received <- len(data)
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := serviceUnderlayAddress(t, s1)
+
+	if _, err := s2.Connect(ctx, addr); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := s2.NewStream(ctx, overlay1, nil, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := stream.Write(make([]byte, 5000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Fatalf("wrote in %v, want at least 1s", elapsed)
+	}
+
+	select {
+	case n := <-received:
+		if n != 20000 {
+			t.Fatalf("received %d bytes, want %d", n, 20000)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+// TestNewStreamBandwidthLimitDeadline tests that the writes that wait for the
+// bandwidth of the protocol end at the write deadline of the stream.
+func TestNewStreamBandwidthLimitDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2p.Options{})
+
+	s2, _ := newService(t, 1, libp2p.Options{
+		BandwidthLimits: map[string]int64{testProtocolName: 1000},
+	})
+
+	if err := s1.AddProtocol(newTestProtocol(func(_ context.Context, _ p2p.Peer, s p2p.Stream) error {
+		defer s.Close()
+		_, err := ioutil.ReadAll(s)
+		return err
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := serviceUnderlayAddress(t, s1)
+
+	if _, err := s2.Connect(ctx, addr); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := s2.NewStream(ctx, overlay1, nil, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	// the burst is written without waiting
+	if _, err := stream.Write(make([]byte, 1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	d, ok := stream.(interface{ SetWriteDeadline(time.Time) error })
+	if !ok {
+		t.Fatal("stream does not have a write deadline")
+	}
+	if err := d.SetWriteDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the write would wait for 5s without the deadline
+	start := time.Now()
+	if _, err := stream.Write(make([]byte, 5000)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("write failed in %v, want at the deadline", elapsed)
+	}
+}
+
 func TestDisconnectError(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -214,6 +328,90 @@ func TestDisconnectError(t *testing.T) {
 	expectPeersEventually(t, s1)
 }
 
+// TestBlockPeerError checks that the peer is disconnected and blocklisted
+// for the duration of the error returned by a protocol handler.
+func TestBlockPeerError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2p.Options{})
+
+	s2, overlay2 := newService(t, 1, libp2p.Options{})
+
+	if err := s1.AddProtocol(newTestProtocol(func(_ context.Context, _ p2p.Peer, _ p2p.Stream) error {
+		return p2p.NewBlockPeerError(time.Minute, errors.New("test error"))
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := serviceUnderlayAddress(t, s1)
+
+	if _, err := s2.Connect(ctx, addr); err != nil {
+		t.Fatal(err)
+	}
+
+	expectPeers(t, s1, overlay2)
+
+	_, _ = s2.NewStream(ctx, overlay1, nil, testProtocolName, testProtocolVersion, testStreamName)
+	expectPeersEventually(t, s1)
+
+	peers, err := s1.BlocklistedPeers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || !peers[0].Address.Equal(overlay2) {
+		t.Fatalf("got blocklisted peers %v, want %s", peers, overlay2)
+	}
+	if _, err := s1.Connect(ctx, serviceUnderlayAddress(t, s2)); !errors.Is(err, p2p.ErrPeerBlocklisted) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrPeerBlocklisted)
+	}
+}
+
+// TestHandlerPanic checks that the panic of a protocol handler resets only
+// its stream, keeping the peer connected and the protocol handled.
+func TestHandlerPanic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2p.Options{})
+
+	s2, overlay2 := newService(t, 1, libp2p.Options{})
+
+	var calls int32
+	handled := make(chan struct{})
+	if err := s1.AddProtocol(newTestProtocol(func(_ context.Context, _ p2p.Peer, s p2p.Stream) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			panic("malformed input")
+		}
+		defer s.Close()
+		close(handled)
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := serviceUnderlayAddress(t, s1)
+
+	if _, err := s2.Connect(ctx, addr); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		stream, err := s2.NewStream(ctx, overlay1, nil, testProtocolName, testProtocolVersion, testStreamName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = stream.Close()
+	}
+
+	select {
+	case <-handled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+	expectPeers(t, s1, overlay2)
+}
+
 const (
 	testProtocolName     = "testing"
 	testProtocolVersion  = "2.3.4"
@@ -0,0 +1,158 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package libp2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/transport"
+	tptu "github.com/libp2p/go-libp2p-transport-upgrader"
+	tcp "github.com/libp2p/go-tcp-transport"
+	ma "github.com/multiformats/go-multiaddr"
+	madns "github.com/multiformats/go-multiaddr-dns"
+	manet "github.com/multiformats/go-multiaddr-net"
+	"golang.org/x/net/proxy"
+)
+
+// errProxyListen is returned by the proxy transport on listen, as the proxy
+// can not forward inbound connections and listening directly would disclose
+// the address of the node.
+var errProxyListen = errors.New("proxy transport does not listen")
+
+// proxyTransport is the TCP transport that dials outbound connections
+// through a SOCKS5 proxy. It does not accept inbound connections.
+type proxyTransport struct {
+	*tcp.TcpTransport
+	dialer proxy.ContextDialer
+}
+
+// newProxyDialer returns the dialer of the SOCKS5 proxy at proxyAddr.
+func newProxyDialer(proxyAddr string) (proxy.ContextDialer, error) {
+	d, err := proxy.SOCKS5("tcp", proxyAddr, nil, proxy.Direct)
+	if err != nil {
+		return nil, err
+	}
+	dialer, ok := d.(proxy.ContextDialer)
+	if !ok {
+		return nil, errors.New("socks5 dialer does not support context")
+	}
+	return dialer, nil
+}
+
+// newProxyTransport returns the constructor of the transport that dials
+// through the SOCKS5 proxy dialer.
+func newProxyTransport(dialer proxy.ContextDialer) func(*tptu.Upgrader) *proxyTransport {
+	return func(upgrader *tptu.Upgrader) *proxyTransport {
+		return &proxyTransport{
+			TcpTransport: tcp.NewTCPTransport(upgrader),
+			dialer:       dialer,
+		}
+	}
+}
+
+// NewProxyResolver returns the multiaddr resolver that looks up the DNS
+// records over TCP through the SOCKS5 proxy at proxyAddr, so that the
+// lookups of the node are not disclosed to its network. The name servers
+// are the ones configured in the system, and they must be reachable through
+// the proxy.
+func NewProxyResolver(proxyAddr string) (*madns.Resolver, error) {
+	dialer, err := newProxyDialer(proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	return &madns.Resolver{
+		Backend: &net.Resolver{
+			PreferGo: true,
+			// the returned connection is not a packet connection, so the
+			// queries are sent over tcp
+			Dial: func(ctx context.Context, _, address string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "tcp", address)
+			},
+		},
+	}, nil
+}
+
+// CanDial returns true for the TCP addresses with an IP address or a host
+// name, as host names are resolved by the proxy.
+func (t *proxyTransport) CanDial(addr ma.Multiaddr) bool {
+	protocols := addr.Protocols()
+	if len(protocols) != 2 || protocols[1].Code != ma.P_TCP {
+		return false
+	}
+	switch protocols[0].Code {
+	case ma.P_IP4, ma.P_IP6, ma.P_DNS, ma.P_DNS4, ma.P_DNS6:
+		return true
+	}
+	return false
+}
+
+// Dial dials the peer at the remote address through the proxy. Host names
+// are passed to the proxy to be resolved there.
+func (t *proxyTransport) Dial(ctx context.Context, raddr ma.Multiaddr, p peer.ID) (transport.CapableConn, error) {
+	if t.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.ConnectTimeout)
+		defer cancel()
+	}
+
+	network, host, err := manet.DialArgs(raddr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := t.dialer.DialContext(ctx, network, host)
+	if err != nil {
+		return nil, fmt.Errorf("proxy dial %s: %w", raddr, err)
+	}
+	laddr, err := manet.FromNetAddr(conn.LocalAddr())
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return t.Upgrader.UpgradeOutbound(ctx, t, &proxyConn{Conn: conn, laddr: laddr, raddr: raddr}, p)
+}
+
+// Listen always returns an error, as the node does not accept inbound
+// connections in the proxy mode.
+func (t *proxyTransport) Listen(laddr ma.Multiaddr) (transport.Listener, error) {
+	return nil, errProxyListen
+}
+
+func (t *proxyTransport) String() string {
+	return "SOCKS5"
+}
+
+// proxyConn is the connection to the proxy that reports the address of the
+// dialed peer as its remote address.
+type proxyConn struct {
+	net.Conn
+	laddr ma.Multiaddr
+	raddr ma.Multiaddr
+}
+
+func (c *proxyConn) LocalMultiaddr() ma.Multiaddr {
+	return c.laddr
+}
+
+func (c *proxyConn) RemoteMultiaddr() ma.Multiaddr {
+	return c.raddr
+}
+
+// hiddenAddressResolver advertises only the peer id of the node, without any
+// transport address, so that the address of the node is not disclosed to its
+// peers and no unroutable address is gossiped to the network.
+type hiddenAddressResolver struct{}
+
+func (hiddenAddressResolver) Resolve(observedAddress ma.Multiaddr) (ma.Multiaddr, error) {
+	observableAddrInfo, err := peer.AddrInfoFromP2pAddr(observedAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return ma.NewMultiaddr(fmt.Sprintf("/p2p/%s", observableAddrInfo.ID.Pretty()))
+}
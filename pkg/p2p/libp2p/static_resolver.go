@@ -80,3 +80,32 @@ func (r *staticAddressResolver) Resolve(observedAddress ma.Multiaddr) (ma.Multia
 
 	return buildUnderlayAddress(a, observableAddrInfo.ID)
 }
+
+// newStaticIP6AddressResolver is the same as newStaticAddressResolver, but it
+// allows only IPv6 hosts.
+func newStaticIP6AddressResolver(addr string) (handshake.AdvertisableAddressResolver, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if host != "" {
+		if ip := net.ParseIP(host); ip == nil || ip.To4() != nil {
+			return nil, fmt.Errorf("invalid IPv6 %q", host)
+		}
+	}
+	return newStaticAddressResolver(addr)
+}
+
+// familyAddressResolver resolves the advertisable address with a separate
+// resolver for each IP family of the observed address.
+type familyAddressResolver struct {
+	ip4 handshake.AdvertisableAddressResolver
+	ip6 handshake.AdvertisableAddressResolver
+}
+
+func (r *familyAddressResolver) Resolve(observedAddress ma.Multiaddr) (ma.Multiaddr, error) {
+	if _, err := observedAddress.ValueForProtocol(ma.P_IP6); err == nil {
+		return r.ip6.Resolve(observedAddress)
+	}
+	return r.ip4.Resolve(observedAddress)
+}
@@ -88,3 +88,63 @@ func TestStaticAddressResolver(t *testing.T) {
 		})
 	}
 }
+
+func TestFamilyAddressResolver(t *testing.T) {
+	r, err := libp2p.NewFamilyAddressResolver("192.168.1.34:30777", "[2001:db8::8a2e:370:1111]:30778")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name              string
+		observableAddress string
+		want              string
+	}{
+		{
+			name:              "ip v4",
+			observableAddress: "/ip4/127.0.0.1/tcp/7071/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd",
+			want:              "/ip4/192.168.1.34/tcp/30777/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd",
+		},
+		{
+			name:              "ip v6",
+			observableAddress: "/ip6/2001:db8::8a2e:370:7334/tcp/7071/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd",
+			want:              "/ip6/2001:db8::8a2e:370:1111/tcp/30778/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			observableAddress, err := ma.NewMultiaddr(tc.observableAddress)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Resolve(observableAddress)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.String() != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := libp2p.NewFamilyAddressResolver("192.168.1.34:30777", "192.168.1.35:30778"); err == nil {
+		t.Error("expected error for ip v4 nat ip v6 address")
+	}
+}
+
+func TestHiddenAddressResolver(t *testing.T) {
+	observableAddress, err := ma.NewMultiaddr("/ip4/192.168.1.34/tcp/7071/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := libp2p.NewHiddenAddressResolver().Resolve(observableAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "/p2p/16Uiu2HAkyyGKpjBiCkVqCKoJa6RzzZw9Nr7hGogsMPcdad1KyMmd"
+	if got.String() != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -5,6 +5,8 @@
 package libp2p
 
 import (
+	"strings"
+
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/libp2p/go-libp2p-core/helpers"
 	"github.com/libp2p/go-libp2p-core/network"
@@ -28,6 +30,17 @@ func (s *stream) Headers() p2p.Headers {
 	return s.headers
 }
 
+// Version returns the version from the swarm stream name of the negotiated
+// protocol.
+func (s *stream) Version() string {
+	// the stream name is /swarm/<protocol>/<version>/<stream>
+	parts := strings.Split(string(s.Protocol()), "/")
+	if len(parts) != 5 {
+		return ""
+	}
+	return parts[3]
+}
+
 func (s *stream) FullClose() error {
 	return helpers.FullClose(s)
 }
@@ -7,6 +7,7 @@ package p2p
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/bzz"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -28,8 +29,38 @@ type Service interface {
 	Addresses() ([]ma.Multiaddr, error)
 }
 
+// Blocklister keeps peers disconnected for a period of time.
+type Blocklister interface {
+	// Blocklist disconnects the peer and rejects the connections with it
+	// until the duration passes.
+	Blocklist(overlay swarm.Address, duration time.Duration) error
+	// Unblocklist removes the peer from the blocklist.
+	Unblocklist(overlay swarm.Address) error
+	// BlocklistedPeers returns the peers on the blocklist.
+	BlocklistedPeers() ([]BlocklistedPeer, error)
+}
+
+// LightNodeReporter tells which of the connected peers are light nodes,
+// which do not take the storage responsibility for the chunks of their
+// neighborhood, as the peers advertise it in the handshake.
+type LightNodeReporter interface {
+	IsLightNode(overlay swarm.Address) bool
+}
+
+// BlocklistedPeer holds the time until which a Peer is on the blocklist.
+type BlocklistedPeer struct {
+	Address swarm.Address `json:"address"`
+	Until   time.Time     `json:"until"`
+}
+
 // Streamer is able to create a new Stream.
 type Streamer interface {
+	// NewStream opens a new stream of the protocol with the peer. The
+	// headers, which may be nil, are sent to the peer before any data, so
+	// that the stream options such as the tracing context can be negotiated.
+	// The handler of the peer gets them with the Headers of its Stream, and
+	// the headers that the HeadlerFunc of the peer responds with are
+	// returned by the Headers of the returned Stream.
 	NewStream(ctx context.Context, address swarm.Address, h Headers, protocol, version, stream string) (Stream, error)
 }
 
@@ -37,7 +68,14 @@ type Streamer interface {
 type Stream interface {
 	io.ReadWriter
 	io.Closer
+	// Headers returns the headers received from the other side when the
+	// stream was opened.
 	Headers() Headers
+	// Version returns the version of the protocol that is used on the
+	// stream. It is newer than the requested version if the peer is
+	// selected by a protocol version override, and the protocol must then
+	// encode and decode the messages of that version.
+	Version() string
 	FullClose() error
 	Reset() error
 }
@@ -68,7 +106,7 @@ type HandlerFunc func(context.Context, Peer, Stream) error
 type HandlerMiddleware func(HandlerFunc) HandlerFunc
 
 // HeadlerFunc is returning response headers based on the received request
-// headers.
+// headers. It is called before the HandlerFunc of the stream.
 type HeadlerFunc func(Headers) Headers
 
 // Headers represents a collection of p2p header key value pairs.
@@ -383,6 +383,10 @@ func (noopWriteCloser) Close() error {
 	return nil
 }
 
+func (noopWriteCloser) Version() string {
+	return ""
+}
+
 func (noopWriteCloser) FullClose() error {
 	return nil
 }
@@ -411,6 +415,10 @@ func (noopReadCloser) Close() error {
 	return nil
 }
 
+func (noopReadCloser) Version() string {
+	return ""
+}
+
 func (noopReadCloser) FullClose() error {
 	return nil
 }
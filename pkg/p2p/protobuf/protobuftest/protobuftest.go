@@ -0,0 +1,206 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package protobuftest provides the test helpers that check that the
+// generated protobuf messages of the protocols keep their wire format
+// compatible with the other versions of the node.
+//
+// Messages are evolved by adding fields with new numbers only. When a field
+// is removed, its number and name must be added to the reserved statement of
+// the message in the proto file and its number to the reserved numbers that
+// are passed to CheckFields, so that it is never reused with a different
+// meaning, as the nodes of the earlier versions would still decode it.
+package protobuftest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// unknownFieldNumber is the field number of the field that is added to the
+// encoded messages to check that fields from newer versions are ignored.
+const unknownFieldNumber = 1 << 20
+
+// Field describes a field of a generated protobuf message.
+type Field struct {
+	Number   int
+	Name     string
+	WireType string
+	Repeated bool
+}
+
+// Fields returns the fields of the generated protobuf message, as described
+// by the struct tags of the generated code, ordered as in the message.
+func Fields(m proto.Message) (fields []Field) {
+	t := reflect.TypeOf(m).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup("protobuf")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		if len(parts) < 3 {
+			continue
+		}
+		number, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		f := Field{
+			Number:   number,
+			WireType: parts[0],
+			Repeated: parts[2] == "rep",
+		}
+		for _, p := range parts[3:] {
+			if strings.HasPrefix(p, "name=") {
+				f.Name = strings.TrimPrefix(p, "name=")
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// CheckFields fails the test if the fields of the message are not compatible
+// with the fields of its released version. Fields may be added, but the
+// released ones must keep their numbers, names and types, unless they are
+// removed and their numbers are reserved. Reserved numbers must not be used.
+func CheckFields(t *testing.T, m proto.Message, released []Field, reserved ...int) {
+	t.Helper()
+
+	fields := make(map[int]Field)
+	for _, f := range Fields(m) {
+		if _, ok := fields[f.Number]; ok {
+			t.Errorf("%T: field number %d used more than once", m, f.Number)
+		}
+		fields[f.Number] = f
+	}
+	isReserved := make(map[int]bool)
+	for _, n := range reserved {
+		isReserved[n] = true
+		if f, ok := fields[n]; ok {
+			t.Errorf("%T: field %s uses reserved number %d", m, f.Name, n)
+		}
+	}
+	for _, r := range released {
+		f, ok := fields[r.Number]
+		if !ok {
+			if !isReserved[r.Number] {
+				t.Errorf("%T: field %s number %d removed without being reserved", m, r.Name, r.Number)
+			}
+			continue
+		}
+		if f != r {
+			t.Errorf("%T: field number %d changed from %+v to %+v", m, r.Number, r, f)
+		}
+	}
+}
+
+// CheckRoundTrip fails the test if the message is not the same after it is
+// encoded and decoded.
+func CheckRoundTrip(t *testing.T, m proto.Message) {
+	t.Helper()
+
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatalf("%T: marshal: %v", m, err)
+	}
+	got := newMessage(m)
+	if err := proto.Unmarshal(b, got); err != nil {
+		t.Fatalf("%T: unmarshal: %v", m, err)
+	}
+	if !proto.Equal(got, m) {
+		t.Errorf("%T: got %v after round trip, want %v", m, got, m)
+	}
+}
+
+// CheckEncoding fails the test if the encoding of the message differs from
+// the hex encoded wire data of the released version, or if the wire data is
+// not decoded to the message.
+func CheckEncoding(t *testing.T, m proto.Message, wire string) {
+	t.Helper()
+
+	want, err := hex.DecodeString(wire)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatalf("%T: marshal: %v", m, err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("%T: got encoding %x, want %x", m, b, want)
+	}
+
+	got := newMessage(m)
+	if err := proto.Unmarshal(want, got); err != nil {
+		t.Fatalf("%T: unmarshal: %v", m, err)
+	}
+	if !proto.Equal(got, m) {
+		t.Errorf("%T: got %v decoded, want %v", m, got, m)
+	}
+}
+
+// CheckUnknownFields fails the test if the message is not decoded when its
+// encoding contains a field that is not known to it, as a field that is
+// added by a newer version.
+func CheckUnknownFields(t *testing.T, m proto.Message) {
+	t.Helper()
+
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatalf("%T: marshal: %v", m, err)
+	}
+	unknown := []byte("unknown")
+	b = append(b, proto.EncodeVarint(uint64(unknownFieldNumber<<3|proto.WireBytes))...)
+	b = append(b, proto.EncodeVarint(uint64(len(unknown)))...)
+	b = append(b, unknown...)
+
+	got := newMessage(m)
+	if err := proto.Unmarshal(b, got); err != nil {
+		t.Fatalf("%T: unmarshal with unknown field: %v", m, err)
+	}
+	if !proto.Equal(got, m) {
+		t.Errorf("%T: got %v decoded with unknown field, want %v", m, got, m)
+	}
+}
+
+// newMessage returns a new empty message of the same type as the message.
+func newMessage(m proto.Message) proto.Message {
+	return reflect.New(reflect.TypeOf(m).Elem()).Interface().(proto.Message)
+}
+
+// Message is a test case of a generated protobuf message of a protocol.
+type Message struct {
+	// Message is the message with all of its fields set.
+	Message proto.Message
+	// Released are the fields of the released version of the message.
+	Released []Field
+	// Reserved are the numbers of the removed fields.
+	Reserved []int
+	// Wire is the hex encoded encoding of the message by the released
+	// version.
+	Wire string
+}
+
+// Check runs all checks on the messages, each in its own subtest.
+func Check(t *testing.T, messages ...Message) {
+	t.Helper()
+
+	for _, m := range messages {
+		m := m
+		t.Run(reflect.TypeOf(m.Message).Elem().Name(), func(t *testing.T) {
+			CheckFields(t, m.Message, m.Released, m.Reserved...)
+			CheckRoundTrip(t, m.Message)
+			CheckEncoding(t, m.Message, m.Wire)
+			CheckUnknownFields(t, m.Message)
+		})
+	}
+}
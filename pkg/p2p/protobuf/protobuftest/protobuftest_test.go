@@ -0,0 +1,36 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protobuftest_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p/protobuf/internal/pb"
+	"github.com/ethersphere/bee/pkg/p2p/protobuf/protobuftest"
+)
+
+func TestFields(t *testing.T) {
+	got := protobuftest.Fields(&pb.Message{})
+	want := []protobuftest.Field{
+		{Number: 1, Name: "Text", WireType: "bytes"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got fields %+v, want %+v", got, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	protobuftest.Check(t, protobuftest.Message{
+		Message: &pb.Message{Text: "text"},
+		Released: []protobuftest.Field{
+			{Number: 1, Name: "Text", WireType: "bytes"},
+			// a removed field
+			{Number: 2, Name: "Removed", WireType: "varint"},
+		},
+		Reserved: []int{2},
+		Wire:     "0a0474657874",
+	})
+}
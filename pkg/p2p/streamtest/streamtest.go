@@ -20,6 +20,7 @@ var (
 	ErrRecordsNotFound        = errors.New("records not found")
 	ErrStreamNotSupported     = errors.New("stream not supported")
 	ErrStreamFullcloseTimeout = errors.New("fullclose timeout")
+	ErrStreamReset            = errors.New("stream reset")
 	fullCloseTimeout          = fullCloseTimeoutDefault // timeout of fullclose
 	fullCloseTimeoutDefault   = 5 * time.Second         // default timeout used for helper function to reset timeout when changed
 
@@ -29,10 +30,21 @@ var (
 )
 
 type Recorder struct {
+	base        swarm.Address
 	records     map[string][]*Record
 	recordsMu   sync.Mutex
 	protocols   []p2p.ProtocolSpec
 	middlewares []p2p.HandlerMiddleware
+	readErr     *injectedError
+	writeErr    *injectedError
+	latency     time.Duration
+}
+
+// injectedError is the error returned instead of the n-th message, counting
+// from zero, that is written to a stream.
+type injectedError struct {
+	err error
+	n   int
 }
 
 func WithProtocols(protocols ...p2p.ProtocolSpec) Option {
@@ -47,6 +59,42 @@ func WithMiddlewares(middlewares ...p2p.HandlerMiddleware) Option {
 	})
 }
 
+// WithBaseAddr sets the overlay address of the node that opens the streams,
+// which is passed as the peer address to the handlers. Without it, handlers
+// receive the address of the stream target.
+func WithBaseAddr(a swarm.Address) Option {
+	return optionFunc(func(r *Recorder) {
+		r.base = a
+	})
+}
+
+// WithReadError makes the reads on the opened streams return the error
+// instead of the n-th message, counting from zero, that the stream handler
+// writes, after the previous messages are read. The stream handler is not
+// notified about it.
+func WithReadError(err error, n int) Option {
+	return optionFunc(func(r *Recorder) {
+		r.readErr = &injectedError{err: err, n: n}
+	})
+}
+
+// WithWriteError makes the n-th write, counting from zero, on the opened
+// streams return the error, without the message being received by the
+// stream handler.
+func WithWriteError(err error, n int) Option {
+	return optionFunc(func(r *Recorder) {
+		r.writeErr = &injectedError{err: err, n: n}
+	})
+}
+
+// WithLatency delays every write on both sides of the streams by the
+// duration.
+func WithLatency(d time.Duration) Option {
+	return optionFunc(func(r *Recorder) {
+		r.latency = d
+	})
+}
+
 func New(opts ...Option) *Recorder {
 	r := &Recorder{
 		records: make(map[string][]*Record),
@@ -65,12 +113,16 @@ func (r *Recorder) SetProtocols(protocols ...p2p.ProtocolSpec) {
 }
 
 func (r *Recorder) NewStream(ctx context.Context, addr swarm.Address, h p2p.Headers, protocolName, protocolVersion, streamName string) (p2p.Stream, error) {
-	recordIn := newRecord()
-	recordOut := newRecord()
+	recordIn := newRecord(r.latency)
+	recordIn.writeErr = r.writeErr
+	recordOut := newRecord(r.latency)
+	recordOut.readErr = r.readErr
 	closedIn := make(chan struct{})
 	closedOut := make(chan struct{})
 	streamOut := newStream(recordIn, recordOut, closedIn, closedOut)
 	streamIn := newStream(recordOut, recordIn, closedOut, closedIn)
+	streamOut.version = protocolVersion
+	streamIn.version = protocolVersion
 
 	var handler p2p.HandlerFunc
 	var headler p2p.HeadlerFunc
@@ -90,12 +142,20 @@ func (r *Recorder) NewStream(ctx context.Context, addr swarm.Address, h p2p.Head
 	for i := len(r.middlewares) - 1; i >= 0; i-- {
 		handler = r.middlewares[i](handler)
 	}
+	// the headers are copied as they are exchanged over the stream
+	streamIn.headers = copyHeaders(h)
 	if headler != nil {
-		streamOut.headers = headler(h)
+		streamOut.headers = copyHeaders(headler(streamIn.headers))
+	} else {
+		streamOut.headers = make(p2p.Headers)
+	}
+	record := &Record{in: recordIn, out: recordOut, headers: streamIn.headers, responseHeaders: streamOut.headers}
+	peer := addr
+	if !r.base.IsZero() {
+		peer = r.base
 	}
-	record := &Record{in: recordIn, out: recordOut}
 	go func() {
-		err := handler(ctx, p2p.Peer{Address: addr}, streamIn)
+		err := handler(ctx, p2p.Peer{Address: peer}, streamIn)
 		if err != nil && err != io.EOF {
 			record.setErr(err)
 		}
@@ -150,10 +210,12 @@ func (r *Recorder) WaitRecords(t *testing.T, addr swarm.Address, proto, version,
 }
 
 type Record struct {
-	in    *record
-	out   *record
-	err   error
-	errMu sync.Mutex
+	in              *record
+	out             *record
+	headers         p2p.Headers
+	responseHeaders p2p.Headers
+	err             error
+	errMu           sync.Mutex
 }
 
 func (r *Record) In() []byte {
@@ -164,6 +226,17 @@ func (r *Record) Out() []byte {
 	return r.out.bytes()
 }
 
+// Headers returns the headers that the stream was opened with.
+func (r *Record) Headers() p2p.Headers {
+	return r.headers
+}
+
+// ResponseHeaders returns the headers that the stream handler responded
+// with.
+func (r *Record) ResponseHeaders() p2p.Headers {
+	return r.responseHeaders
+}
+
 func (r *Record) Err() error {
 	r.errMu.Lock()
 	defer r.errMu.Unlock()
@@ -179,15 +252,16 @@ func (r *Record) setErr(err error) {
 }
 
 type stream struct {
-	in        io.WriteCloser
-	out       io.ReadCloser
+	in        *record
+	out       *record
 	headers   p2p.Headers
+	version   string
 	cin       chan struct{}
 	cout      chan struct{}
 	closeOnce sync.Once
 }
 
-func newStream(in io.WriteCloser, out io.ReadCloser, cin, cout chan struct{}) *stream {
+func newStream(in, out *record, cin, cout chan struct{}) *stream {
 	return &stream{in: in, out: out, cin: cin, cout: cout}
 }
 
@@ -221,6 +295,10 @@ func (s *stream) Close() error {
 	return e
 }
 
+func (s *stream) Version() string {
+	return s.version
+}
+
 func (s *stream) FullClose() error {
 	if err := s.Close(); err != nil {
 		return err
@@ -235,21 +313,33 @@ func (s *stream) FullClose() error {
 	return nil
 }
 
+// Reset discards the data that is not yet read on both sides of the stream,
+// which then get ErrStreamReset from their reads and writes.
 func (s *stream) Reset() error {
-	//todo: :implement appropriately after all protocols are migrated and tested
-	return s.Close()
+	s.in.reset()
+	s.out.reset()
+	s.closeOnce.Do(func() {
+		close(s.cin)
+	})
+	return nil
 }
 
 type record struct {
-	b      []byte
-	c      int
-	closed bool
-	cond   *sync.Cond
+	b        []byte
+	c        int
+	closed   bool
+	err      error // returned by reads after the data that is not discarded
+	writes   int
+	readErr  *injectedError
+	writeErr *injectedError
+	latency  time.Duration
+	cond     *sync.Cond
 }
 
-func newRecord() *record {
+func newRecord(latency time.Duration) *record {
 	return &record{
-		cond: sync.NewCond(new(sync.Mutex)),
+		latency: latency,
+		cond:    sync.NewCond(new(sync.Mutex)),
 	}
 }
 
@@ -257,7 +347,7 @@ func (r *record) Read(p []byte) (n int, err error) {
 	r.cond.L.Lock()
 	defer r.cond.L.Unlock()
 
-	for r.c == len(r.b) && !r.closed {
+	for r.c == len(r.b) && !r.closed && r.err == nil {
 		r.cond.Wait()
 	}
 	end := r.c + len(p)
@@ -266,22 +356,57 @@ func (r *record) Read(p []byte) (n int, err error) {
 	}
 	n = copy(p, r.b[r.c:end])
 	r.c += n
-	if r.closed {
+	if r.err != nil && r.c == len(r.b) {
+		err = r.err
+	} else if r.closed {
 		err = io.EOF
 	}
 	return n, err
 }
 
 func (r *record) Write(p []byte) (int, error) {
+	if r.latency > 0 {
+		time.Sleep(r.latency)
+	}
+
 	r.cond.L.Lock()
 	defer r.cond.L.Unlock()
 
-	defer r.cond.Signal()
+	defer r.cond.Broadcast()
+
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	n := r.writes
+	r.writes++
+	if r.writeErr != nil && n == r.writeErr.n {
+		return 0, r.writeErr.err
+	}
+	if r.readErr != nil && n == r.readErr.n {
+		r.err = r.readErr.err
+		return len(p), nil
+	}
 
 	r.b = append(r.b, p...)
 	return len(p), nil
 }
 
+// reset discards the data that is not yet read and makes the reads and
+// writes return ErrStreamReset.
+func (r *record) reset() {
+	r.cond.L.Lock()
+	defer r.cond.L.Unlock()
+
+	defer r.cond.Broadcast()
+
+	r.c = len(r.b)
+	r.closed = true
+	if r.err == nil {
+		r.err = ErrStreamReset
+	}
+}
+
 func (r *record) Close() error {
 	r.cond.L.Lock()
 	defer r.cond.L.Unlock()
@@ -305,3 +430,11 @@ type Option interface {
 type optionFunc func(*Recorder)
 
 func (f optionFunc) apply(r *Recorder) { f(r) }
+
+func copyHeaders(h p2p.Headers) p2p.Headers {
+	c := make(p2p.Headers, len(h))
+	for k, v := range h {
+		c[k] = append([]byte(nil), v...)
+	}
+	return c
+}
@@ -513,6 +513,276 @@ func TestRecorder_recordErr(t *testing.T) {
 	}, testErr)
 }
 
+func TestRecorder_headers(t *testing.T) {
+	headers := p2p.Headers{"price": []byte("10")}
+	handled := make(chan p2p.Headers, 1)
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(
+			p2p.ProtocolSpec{
+				Name:    testProtocolName,
+				Version: testProtocolVersion,
+				StreamSpecs: []p2p.StreamSpec{
+					{
+						Name: testStreamName,
+						Handler: func(_ context.Context, peer p2p.Peer, stream p2p.Stream) error {
+							handled <- stream.Headers()
+							return stream.Close()
+						},
+						Headler: func(h p2p.Headers) p2p.Headers {
+							return p2p.Headers{"accepted-price": h["price"]}
+						},
+					},
+				},
+			},
+		),
+	)
+
+	stream, err := recorder.NewStream(context.Background(), swarm.ZeroAddress, headers, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	// the headers are not shared with the sender
+	headers["price"][0] = '2'
+
+	if got := string(stream.Headers()["accepted-price"]); got != "10" {
+		t.Errorf("got response header %q, want %q", got, "10")
+	}
+	select {
+	case h := <-handled:
+		if got := string(h["price"]); got != "10" {
+			t.Errorf("got handler header %q, want %q", got, "10")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	records, err := recorder.Records(swarm.ZeroAddress, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(records[0].Headers()["price"]); got != "10" {
+		t.Errorf("got recorded header %q, want %q", got, "10")
+	}
+	if got := string(records[0].ResponseHeaders()["accepted-price"]); got != "10" {
+		t.Errorf("got recorded response header %q, want %q", got, "10")
+	}
+}
+
+func TestRecorder_noHeaders(t *testing.T) {
+	recorder := streamtest.New(
+		streamtest.WithProtocols(
+			newTestProtocol(func(_ context.Context, peer p2p.Peer, stream p2p.Stream) error {
+				if stream.Headers() == nil {
+					return errors.New("nil headers")
+				}
+				return stream.Close()
+			}),
+		),
+	)
+
+	stream, err := recorder.NewStream(context.Background(), swarm.ZeroAddress, nil, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stream.Headers() == nil {
+		t.Fatal("got nil response headers")
+	}
+	if err := stream.FullClose(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := recorder.Records(swarm.ZeroAddress, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRecords(t, records, [][2]string{{"", ""}}, nil)
+}
+
+func TestRecorder_reset(t *testing.T) {
+	handlerErr := make(chan error, 1)
+	recorder := streamtest.New(
+		streamtest.WithProtocols(
+			newTestProtocol(func(_ context.Context, peer p2p.Peer, stream p2p.Stream) error {
+				if _, err := stream.Write([]byte("resp\n")); err != nil {
+					return err
+				}
+				if err := stream.Reset(); err != nil {
+					return err
+				}
+				_, err := stream.Write([]byte("more\n"))
+				handlerErr <- err
+				return nil
+			}),
+		),
+	)
+
+	stream, err := recorder.NewStream(context.Background(), swarm.ZeroAddress, nil, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-handlerErr:
+		if !errors.Is(err, streamtest.ErrStreamReset) {
+			t.Fatalf("got handler write error %v, want %v", err, streamtest.ErrStreamReset)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	// the data that is not read is discarded
+	if _, err := ioutil.ReadAll(stream); !errors.Is(err, streamtest.ErrStreamReset) {
+		t.Fatalf("got read error %v, want %v", err, streamtest.ErrStreamReset)
+	}
+	if _, err := stream.Write([]byte("req\n")); !errors.Is(err, streamtest.ErrStreamReset) {
+		t.Fatalf("got write error %v, want %v", err, streamtest.ErrStreamReset)
+	}
+	if err := stream.FullClose(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecorder_readError(t *testing.T) {
+	testErr := errors.New("test error")
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(
+			newTestProtocol(func(_ context.Context, peer p2p.Peer, stream p2p.Stream) error {
+				for _, m := range []string{"first\n", "second\n", "third\n"} {
+					if _, err := stream.Write([]byte(m)); err != nil {
+						return err
+					}
+				}
+				return stream.Close()
+			}),
+		),
+		streamtest.WithReadError(testErr, 1),
+	)
+
+	stream, err := recorder.NewStream(context.Background(), swarm.ZeroAddress, nil, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	r := bufio.NewReader(stream)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "first\n" {
+		t.Fatalf("got message %q, want %q", line, "first\n")
+	}
+	if _, err := r.ReadString('\n'); !errors.Is(err, testErr) {
+		t.Fatalf("got error %v, want %v", err, testErr)
+	}
+}
+
+func TestRecorder_writeError(t *testing.T) {
+	testErr := errors.New("test error")
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(
+			newTestProtocol(func(_ context.Context, peer p2p.Peer, stream p2p.Stream) error {
+				if _, err := ioutil.ReadAll(stream); err != nil {
+					return err
+				}
+				return stream.Close()
+			}),
+		),
+		streamtest.WithWriteError(testErr, 1),
+	)
+
+	stream, err := recorder.NewStream(context.Background(), swarm.ZeroAddress, nil, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, m := range []string{"first\n", "second\n", "third\n"} {
+		_, err := stream.Write([]byte(m))
+		if i == 1 {
+			if !errors.Is(err, testErr) {
+				t.Fatalf("got error %v, want %v", err, testErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.FullClose(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := recorder.Records(swarm.ZeroAddress, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRecords(t, records, [][2]string{{"first\nthird\n", ""}}, nil)
+}
+
+func TestRecorder_latency(t *testing.T) {
+	const latency = 50 * time.Millisecond
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(
+			newTestProtocol(func(_ context.Context, peer p2p.Peer, stream p2p.Stream) error {
+				rw := bufio.NewReadWriter(bufio.NewReader(stream), bufio.NewWriter(stream))
+				defer stream.Close()
+
+				if _, err := rw.ReadString('\n'); err != nil {
+					return err
+				}
+				if _, err := rw.WriteString("resp\n"); err != nil {
+					return err
+				}
+				return rw.Flush()
+			}),
+		),
+		streamtest.WithLatency(latency),
+	)
+
+	start := time.Now()
+	stream, err := recorder.NewStream(context.Background(), swarm.ZeroAddress, nil, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	if _, err := stream.Write([]byte("req\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bufio.NewReader(stream).ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 2*latency {
+		t.Fatalf("got round trip %s, want at least %s", d, 2*latency)
+	}
+}
+
+func TestRecorder_closeEarly(t *testing.T) {
+	recorder := streamtest.New(
+		streamtest.WithProtocols(
+			newTestProtocol(func(_ context.Context, peer p2p.Peer, stream p2p.Stream) error {
+				return stream.Close()
+			}),
+		),
+	)
+
+	stream, err := recorder.NewStream(context.Background(), swarm.ZeroAddress, nil, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	if _, err := bufio.NewReader(stream).ReadString('\n'); !errors.Is(err, io.EOF) {
+		t.Fatalf("got error %v, want %v", err, io.EOF)
+	}
+}
+
 const (
 	testProtocolName    = "testing"
 	testProtocolVersion = "1.0.1"
@@ -561,3 +831,43 @@ func testRecords(t *testing.T, records []*streamtest.Record, want [][2]string, w
 		}
 	}
 }
+
+func TestRecorder_version(t *testing.T) {
+	handled := make(chan string, 1)
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(
+			p2p.ProtocolSpec{
+				Name:    testProtocolName,
+				Version: testProtocolVersion,
+				StreamSpecs: []p2p.StreamSpec{
+					{
+						Name: testStreamName,
+						Handler: func(_ context.Context, peer p2p.Peer, stream p2p.Stream) error {
+							handled <- stream.Version()
+							return stream.Close()
+						},
+					},
+				},
+			},
+		),
+	)
+
+	stream, err := recorder.NewStream(context.Background(), swarm.ZeroAddress, nil, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	if got := stream.Version(); got != testProtocolVersion {
+		t.Errorf("got version %q, want %q", got, testProtocolVersion)
+	}
+	select {
+	case got := <-handled:
+		if got != testProtocolVersion {
+			t.Errorf("got handler version %q, want %q", got, testProtocolVersion)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package p2p
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// VersionOverride selects the peers with which a newer version of a
+// protocol is requested than the version that the protocol requests, so
+// that the newer version can be rolled out gradually.
+type VersionOverride struct {
+	// Version is the newer version of the protocol.
+	Version string
+	// Peers are the overlay addresses of the selected peers.
+	Peers []swarm.Address
+	// Percentage is the percentage of all peers that are selected by
+	// their overlay addresses, in addition to Peers.
+	Percentage int
+}
+
+// Selects reports whether the newer version is requested from the peer.
+// The same peers are selected with the same percentage on all nodes, and
+// the peers that are selected with a percentage are also selected with
+// the greater ones.
+func (o VersionOverride) Selects(peer swarm.Address) bool {
+	for _, p := range o.Peers {
+		if p.Equal(peer) {
+			return true
+		}
+	}
+	b := peer.Bytes()
+	if o.Percentage <= 0 || len(b) < 2 {
+		return false
+	}
+	return int(binary.BigEndian.Uint16(b)%100) < o.Percentage
+}
+
+// ParseVersionOverrides parses the version overrides by protocol names,
+// each as protocol=version:selector, where the selector is either the hex
+// encoded overlay address of a peer or the percentage of the peers, such
+// as pushsync=1.1.0:10%. The overrides of the same protocol are merged and
+// must have the same version.
+func ParseVersionOverrides(overrides []string) (map[string]VersionOverride, error) {
+	m := make(map[string]VersionOverride)
+	for _, s := range overrides {
+		i := strings.Index(s, "=")
+		j := strings.LastIndex(s, ":")
+		if i <= 0 || j < i {
+			return nil, fmt.Errorf("version override %q: want protocol=version:selector", s)
+		}
+		name, version, selector := s[:i], s[i+1:j], s[j+1:]
+		if _, err := semver.NewVersion(version); err != nil {
+			return nil, fmt.Errorf("version override %q: %w", s, err)
+		}
+
+		o, ok := m[name]
+		if ok && o.Version != version {
+			return nil, fmt.Errorf("version override %q: protocol %s overridden with version %s", s, name, o.Version)
+		}
+		o.Version = version
+		if strings.HasSuffix(selector, "%") {
+			p, err := strconv.Atoi(strings.TrimSuffix(selector, "%"))
+			if err != nil || p < 0 || p > 100 {
+				return nil, fmt.Errorf("version override %q: invalid percentage", s)
+			}
+			if p > o.Percentage {
+				o.Percentage = p
+			}
+		} else {
+			peer, err := swarm.ParseHexAddress(selector)
+			if err != nil {
+				return nil, fmt.Errorf("version override %q: %w", s, err)
+			}
+			o.Peers = append(o.Peers, peer)
+		}
+		m[name] = o
+	}
+	return m, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package p2p_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestParseVersionOverrides(t *testing.T) {
+	peer := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+
+	got, err := p2p.ParseVersionOverrides([]string{
+		"pushsync=1.1.0:10%",
+		"pushsync=1.1.0:" + peer.String(),
+		"pushsync=1.1.0:5%",
+		"retrieval=2.0.0-rc1:50%",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]p2p.VersionOverride{
+		"pushsync": {
+			Version:    "1.1.0",
+			Peers:      []swarm.Address{peer},
+			Percentage: 10,
+		},
+		"retrieval": {
+			Version:    "2.0.0-rc1",
+			Percentage: 50,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got overrides %+v, want %+v", got, want)
+	}
+
+	for _, s := range []string{
+		"pushsync",
+		"pushsync=1.1.0",
+		"=1.1.0:10%",
+		"pushsync=1.1:10%",
+		"pushsync=1.1.0:101%",
+		"pushsync=1.1.0:-1%",
+		"pushsync=1.1.0:zz",
+	} {
+		if _, err := p2p.ParseVersionOverrides([]string{s}); err == nil {
+			t.Errorf("override %q: got no error", s)
+		}
+	}
+
+	if _, err := p2p.ParseVersionOverrides([]string{"pushsync=1.1.0:10%", "pushsync=1.2.0:10%"}); err == nil {
+		t.Error("different versions: got no error")
+	}
+}
+
+func TestVersionOverrideSelects(t *testing.T) {
+	peer := swarm.MustParseHexAddress("0009")  // 9 % 100
+	other := swarm.MustParseHexAddress("0063") // 99 % 100
+
+	for _, tc := range []struct {
+		name     string
+		override p2p.VersionOverride
+		peer     swarm.Address
+		want     bool
+	}{
+		{name: "none", peer: peer, want: false},
+		{name: "listed", override: p2p.VersionOverride{Peers: []swarm.Address{peer}}, peer: peer, want: true},
+		{name: "not listed", override: p2p.VersionOverride{Peers: []swarm.Address{other}}, peer: peer, want: false},
+		{name: "within percentage", override: p2p.VersionOverride{Percentage: 10}, peer: peer, want: true},
+		{name: "over percentage", override: p2p.VersionOverride{Percentage: 9}, peer: peer, want: false},
+		{name: "all", override: p2p.VersionOverride{Percentage: 100}, peer: other, want: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.override.Selects(tc.peer); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
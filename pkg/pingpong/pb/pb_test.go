@@ -0,0 +1,33 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p/protobuf/protobuftest"
+	"github.com/ethersphere/bee/pkg/pingpong/pb"
+)
+
+// TestMessages checks that the wire format of the messages is compatible
+// with the released version of the protocol.
+func TestMessages(t *testing.T) {
+	protobuftest.Check(t,
+		protobuftest.Message{
+			Message: &pb.Ping{Greeting: "hey"},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Greeting", WireType: "bytes"},
+			},
+			Wire: "0a03686579",
+		},
+		protobuftest.Message{
+			Message: &pb.Pong{Response: "{hey}"},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Response", WireType: "bytes"},
+			},
+			Wire: "0a057b6865797d",
+		},
+	)
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pinning provides persistence of the root references of the content
+// that is pinned, so that it can be listed and unpinned with all of its chunks.
+package pinning
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const keyPrefix = "pinned_root_"
+
+var _ Interface = (*store)(nil)
+
+var ErrNotFound = errors.New("pinning: not found")
+
+// Record is the pinned content with the root reference Address.
+type Record struct {
+	Address swarm.Address `json:"address"`
+	// Type is the type of the reference, which determines how the chunks of
+	// the content are traversed.
+	Type string `json:"type"`
+	// Counter is the number of times the content is pinned.
+	Counter uint64 `json:"counter"`
+}
+
+type Interface interface {
+	Get(address swarm.Address) (*Record, error)
+	Put(r *Record) error
+	Delete(address swarm.Address) error
+	// List returns the records of all pinned content.
+	List() ([]Record, error)
+}
+
+type store struct {
+	store storage.StateStorer
+}
+
+func New(storer storage.StateStorer) Interface {
+	return &store{
+		store: storer,
+	}
+}
+
+func (s *store) Get(address swarm.Address) (*Record, error) {
+	v := &Record{}
+	err := s.store.Get(keyPrefix+address.String(), v)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *store) Put(r *Record) error {
+	return s.store.Put(keyPrefix+r.Address.String(), r)
+}
+
+func (s *store) Delete(address swarm.Address) error {
+	return s.store.Delete(keyPrefix + address.String())
+}
+
+func (s *store) List() (records []Record, err error) {
+	err = s.store.Iterate(keyPrefix, func(_, value []byte) (stop bool, err error) {
+		var r Record
+		if err := json.Unmarshal(value, &r); err != nil {
+			return true, err
+		}
+
+		records = append(records, r)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
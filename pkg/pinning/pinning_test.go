@@ -0,0 +1,64 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pinning_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/pinning"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestRecords(t *testing.T) {
+	store := pinning.New(mock.NewStateStore())
+
+	a1 := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	a2 := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59d")
+
+	if _, err := store.Get(a1); !errors.Is(err, pinning.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, pinning.ErrNotFound)
+	}
+
+	for _, r := range []*pinning.Record{
+		{Address: a1, Type: "bytes", Counter: 1},
+		{Address: a2, Type: "manifest", Counter: 3},
+	} {
+		if err := store.Put(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := store.Get(a2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Address.Equal(a2) || got.Type != "manifest" || got.Counter != 3 {
+		t.Fatalf("got record %+v", got)
+	}
+
+	records, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want %d", len(records), 2)
+	}
+
+	if err := store.Delete(a1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(a1); !errors.Is(err, pinning.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, pinning.ErrNotFound)
+	}
+	records, err = store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || !records[0].Address.Equal(a2) {
+		t.Fatalf("got records %+v", records)
+	}
+}
@@ -2,10 +2,15 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// Package puller orchestrates pull syncing with the peers within the depth
+// and a few peers in the shallower bins. It keeps historical and live sync
+// workers for every peer and bin, and stores the intervals synced from each
+// of them in the state store, so that syncing resumes where it stopped.
 package puller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"sync"
@@ -13,6 +18,7 @@ import (
 
 	"github.com/ethersphere/bee/pkg/intervalstore"
 	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/pullsync"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -53,6 +59,12 @@ type Puller struct {
 	quit chan struct{}
 	wg   sync.WaitGroup
 
+	historySynced     chan struct{} // closed when the historical syncing within depth is done
+	historySyncedOnce sync.Once
+	histWorkers       int  // number of running historical sync workers
+	depthPeerSynced   bool // whether the syncing with a peer within depth has started
+	histMtx           sync.Mutex
+
 	bins            uint8 // how many bins do we support
 	shallowBinPeers int   // how many peers per bin do we want to sync with outside of depth
 }
@@ -81,6 +93,8 @@ func New(o Options) *Puller {
 		quit:      make(chan struct{}),
 		wg:        sync.WaitGroup{},
 
+		historySynced: make(chan struct{}),
+
 		bins:            bins,
 		shallowBinPeers: shallowBinPeers,
 	}
@@ -254,8 +268,7 @@ func (p *Puller) recalcPeer(ctx context.Context, peer swarm.Address, po, d uint8
 				binCtx, cancel := context.WithCancel(ctx)
 				syncCtx.binCancelFuncs[bin] = cancel
 				if cur > 0 {
-					p.wg.Add(1)
-					go p.histSyncWorker(binCtx, peer, bin, cur)
+					p.startHistSyncWorker(binCtx, peer, bin, cur)
 				}
 				p.wg.Add(1)
 				go p.liveSyncWorker(binCtx, peer, bin, cur)
@@ -290,8 +303,7 @@ func (p *Puller) recalcPeer(ctx context.Context, peer swarm.Address, po, d uint8
 			binCtx, cancel := context.WithCancel(ctx)
 			syncCtx.binCancelFuncs[po] = cancel
 			if cur > 0 {
-				p.wg.Add(1)
-				go p.histSyncWorker(binCtx, peer, want, cur)
+				p.startHistSyncWorker(binCtx, peer, want, cur)
 			}
 			p.wg.Add(1)
 			go p.liveSyncWorker(binCtx, peer, want, cur)
@@ -318,7 +330,9 @@ func (p *Puller) syncPeer(ctx context.Context, peer swarm.Address, po, d uint8)
 	if !ok {
 		cursors, err := p.syncer.GetCursors(ctx, peer)
 		if err != nil {
-			if logMore {
+			if errors.Is(err, p2p.ErrProtocolDisabled) {
+				p.logger.Debugf("puller: peer %s does not run pull sync", peer)
+			} else if logMore {
 				p.logger.Debugf("error getting cursors from peer %s: %v", peer.String(), err)
 			}
 			delete(p.syncPeers[po], peer.String())
@@ -339,8 +353,7 @@ func (p *Puller) syncPeer(ctx context.Context, peer swarm.Address, po, d uint8)
 		binCtx, cancel := context.WithCancel(ctx)
 		syncCtx.binCancelFuncs[po] = cancel
 		if cur > 0 {
-			p.wg.Add(1)
-			go p.histSyncWorker(binCtx, peer, bin, cur) // start historical
+			p.startHistSyncWorker(binCtx, peer, bin, cur) // start historical
 		}
 		p.wg.Add(1)
 		go p.liveSyncWorker(binCtx, peer, bin, cur) // start live
@@ -355,17 +368,55 @@ func (p *Puller) syncPeer(ctx context.Context, peer swarm.Address, po, d uint8)
 		binCtx, cancel := context.WithCancel(ctx)
 		syncCtx.binCancelFuncs[uint8(bin)] = cancel
 		if cur > 0 {
-			p.wg.Add(1)
-			go p.histSyncWorker(binCtx, peer, uint8(bin), cur) // start historical
+			p.startHistSyncWorker(binCtx, peer, uint8(bin), cur) // start historical
 		}
 		// start live
 		p.wg.Add(1)
 		go p.liveSyncWorker(binCtx, peer, uint8(bin), cur) // start live
 	}
+
+	p.histMtx.Lock()
+	p.depthPeerSynced = true
+	p.histMtx.Unlock()
+	p.checkHistorySynced()
+}
+
+// startHistSyncWorker starts the historical syncing of the bin from the peer.
+// The running historical sync workers are counted to detect when the
+// historical syncing is done.
+func (p *Puller) startHistSyncWorker(ctx context.Context, peer swarm.Address, bin uint8, cur uint64) {
+	p.histMtx.Lock()
+	p.histWorkers++
+	p.histMtx.Unlock()
+
+	p.wg.Add(1)
+	go p.histSyncWorker(ctx, peer, bin, cur)
+}
+
+// checkHistorySynced closes the history synced channel if the syncing with a
+// peer within depth has started and no historical sync workers are running.
+func (p *Puller) checkHistorySynced() {
+	p.histMtx.Lock()
+	defer p.histMtx.Unlock()
+
+	if p.depthPeerSynced && p.histWorkers == 0 {
+		p.historySyncedOnce.Do(func() { close(p.historySynced) })
+	}
+}
+
+// HistorySynced returns a channel that is closed once the historical
+// intervals of the bins within depth are synced from the peers that the
+// puller started syncing with, or they failed to be synced.
+func (p *Puller) HistorySynced() <-chan struct{} {
+	return p.historySynced
 }
 
 func (p *Puller) histSyncWorker(ctx context.Context, peer swarm.Address, bin uint8, cur uint64) {
 	defer func() {
+		p.histMtx.Lock()
+		p.histWorkers--
+		p.histMtx.Unlock()
+		p.checkHistorySynced()
 		p.wg.Done()
 		p.metrics.HistWorkerDoneCounter.Inc()
 	}()
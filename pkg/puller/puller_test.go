@@ -249,6 +249,45 @@ func TestSyncFlow_PeerWithinDepth_Live(t *testing.T) {
 	}
 }
 
+// test that the history synced channel is closed once the historical
+// intervals of the bins within depth are synced
+func TestHistorySynced(t *testing.T) {
+	var (
+		addr    = test.RandomAddress()
+		cursors = []uint64{0, 0, 100, 100, 0}
+	)
+
+	puller, st, kad, pullsync := newPuller(opts{
+		kad: []mockk.Option{
+			mockk.WithEachPeerRevCalls(
+				mockk.AddrTuple{Addr: addr, PO: 3}, // po is 3, depth is 2, so we're in depth
+			), mockk.WithDepth(2),
+		},
+		pullSync: []mockps.Option{mockps.WithCursors(cursors), mockps.WithAutoReply(), mockps.WithLiveSyncBlock()},
+		bins:     5,
+	})
+	defer puller.Close()
+	defer pullsync.Close()
+
+	select {
+	case <-puller.HistorySynced():
+		t.Fatal("history synced before syncing started")
+	default:
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	kad.Trigger()
+
+	select {
+	case <-puller.HistorySynced():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for history synced")
+	}
+
+	checkIntervals(t, st, addr, "[[1 100]]", 2)
+	checkIntervals(t, st, addr, "[[1 100]]", 3)
+}
+
 func TestPeerDisconnected(t *testing.T) {
 	cursors := []uint64{0, 0}
 	addr := test.RandomAddress()
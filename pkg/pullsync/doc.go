@@ -2,4 +2,22 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+/*
+Package pullsync provides the pull sync protocol, which lets a node fetch the
+chunks it is responsible for from its peers, complementing push sync that
+only covers freshly uploaded chunks.
+
+Chunks are synced per proximity order bin, following the localstore pull
+index. A downstream peer first asks for the cursors of the upstream peer,
+the highest BinID in each bin, on the cursors stream. It then requests an
+interval of BinIDs in a bin on the pullsync stream, the upstream peer offers
+the addresses it holds in that interval, the downstream peer replies with
+a bitvector of the chunks it wants and receives them. The topmost BinID of
+the offer is returned so that the next interval can continue from it.
+Requests that are no longer needed can be cancelled by their ruid on the
+cancel stream.
+
+The puller package drives this protocol, persisting the synced intervals of
+every peer and bin in the state store.
+*/
 package pullsync
@@ -0,0 +1,72 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p/protobuf/protobuftest"
+	"github.com/ethersphere/bee/pkg/pullsync/pb"
+)
+
+// TestMessages checks that the wire format of the messages is compatible
+// with the released version of the protocol.
+func TestMessages(t *testing.T) {
+	protobuftest.Check(t,
+		protobuftest.Message{
+			Message: &pb.Ack{Cursors: []uint64{1, 300, 70000}},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Cursors", WireType: "varint", Repeated: true},
+			},
+			Wire: "0a0601ac02f0a204",
+		},
+		protobuftest.Message{
+			Message: &pb.Ruid{Ruid: 42},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Ruid", WireType: "varint"},
+			},
+			Wire: "082a",
+		},
+		protobuftest.Message{
+			Message: &pb.Cancel{Ruid: 42},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Ruid", WireType: "varint"},
+			},
+			Wire: "082a",
+		},
+		protobuftest.Message{
+			Message: &pb.GetRange{Bin: 3, From: 1, To: 100},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Bin", WireType: "varint"},
+				{Number: 2, Name: "From", WireType: "varint"},
+				{Number: 3, Name: "To", WireType: "varint"},
+			},
+			Wire: "080310011864",
+		},
+		protobuftest.Message{
+			Message: &pb.Offer{Topmost: 100, Hashes: []byte{1, 2, 3}},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Topmost", WireType: "varint"},
+				{Number: 2, Name: "Hashes", WireType: "bytes"},
+			},
+			Wire: "08641203010203",
+		},
+		protobuftest.Message{
+			Message: &pb.Want{BitVector: []byte{5}},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "BitVector", WireType: "bytes"},
+			},
+			Wire: "0a0105",
+		},
+		protobuftest.Message{
+			Message: &pb.Delivery{Address: []byte{1, 2, 3}, Data: []byte("data")},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Address", WireType: "bytes"},
+				{Number: 2, Name: "Data", WireType: "bytes"},
+			},
+			Wire: "0a03010203120464617461",
+		},
+	)
+}
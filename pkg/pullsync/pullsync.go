@@ -26,7 +26,8 @@ import (
 )
 
 const (
-	protocolName     = "pullsync"
+	// ProtocolName is the name of the pull sync protocol.
+	ProtocolName     = "pullsync"
 	protocolVersion  = "1.0.0"
 	streamName       = "pullsync"
 	cursorStreamName = "cursors"
@@ -84,7 +85,7 @@ func New(o Options) *Syncer {
 
 func (s *Syncer) Protocol() p2p.ProtocolSpec {
 	return p2p.ProtocolSpec{
-		Name:    protocolName,
+		Name:    ProtocolName,
 		Version: protocolVersion,
 		StreamSpecs: []p2p.StreamSpec{
 			{
@@ -108,7 +109,7 @@ func (s *Syncer) Protocol() p2p.ProtocolSpec {
 // If the requested interval is too large, the downstream peer has the liberty to
 // provide less chunks than requested.
 func (s *Syncer) SyncInterval(ctx context.Context, peer swarm.Address, bin uint8, from, to uint64) (topmost uint64, ruid uint32, err error) {
-	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
+	stream, err := s.streamer.NewStream(ctx, peer, nil, ProtocolName, protocolVersion, streamName)
 	if err != nil {
 		return 0, 0, fmt.Errorf("new stream: %w", err)
 	}
@@ -343,7 +344,7 @@ func (s *Syncer) processWant(ctx context.Context, o *pb.Offer, w *pb.Want) ([]sw
 }
 
 func (s *Syncer) GetCursors(ctx context.Context, peer swarm.Address) ([]uint64, error) {
-	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, cursorStreamName)
+	stream, err := s.streamer.NewStream(ctx, peer, nil, ProtocolName, protocolVersion, cursorStreamName)
 	if err != nil {
 		return nil, fmt.Errorf("new stream: %w", err)
 	}
@@ -388,7 +389,7 @@ func (s *Syncer) cursorHandler(ctx context.Context, p p2p.Peer, stream p2p.Strea
 }
 
 func (s *Syncer) CancelRuid(peer swarm.Address, ruid uint32) error {
-	stream, err := s.streamer.NewStream(context.Background(), peer, nil, protocolName, protocolVersion, cancelStreamName)
+	stream, err := s.streamer.NewStream(context.Background(), peer, nil, ProtocolName, protocolVersion, cancelStreamName)
 	if err != nil {
 		return fmt.Errorf("new stream: %w", err)
 	}
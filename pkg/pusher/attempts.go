@@ -0,0 +1,77 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pusher
+
+import (
+	"container/list"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// failedAttempts counts the consecutive failed push attempts by chunk
+// address. It keeps the counts of at most limit chunks and evicts the count
+// of the chunk whose push failed least recently when it is full, so that the
+// counts of chunks that leave the push index without ever being pushed, as
+// when they are removed, do not accumulate.
+//
+// It is not safe for concurrent use.
+type failedAttempts struct {
+	limit   int
+	order   *list.List               // elements of the counts, most recently failed first
+	counts  map[string]*list.Element // elements of the counts by chunk address
+	metrics metrics
+}
+
+// attemptsCount is the count of failed attempts of a chunk.
+type attemptsCount struct {
+	key   string
+	count int
+}
+
+func newFailedAttempts(limit int, metrics metrics) *failedAttempts {
+	return &failedAttempts{
+		limit:   limit,
+		order:   list.New(),
+		counts:  make(map[string]*list.Element),
+		metrics: metrics,
+	}
+}
+
+// inc counts a failed push of the chunk and returns the number of
+// consecutive failed pushes of it.
+func (f *failedAttempts) inc(addr swarm.Address) int {
+	key := addr.ByteString()
+	if e, ok := f.counts[key]; ok {
+		f.metrics.FailedAttemptsHits.Inc()
+		f.order.MoveToFront(e)
+		c := e.Value.(*attemptsCount)
+		c.count++
+		return c.count
+	}
+
+	if f.order.Len() >= f.limit {
+		if e := f.order.Back(); e != nil {
+			f.remove(e)
+			f.metrics.FailedAttemptsEvictions.Inc()
+		}
+	}
+	f.counts[key] = f.order.PushFront(&attemptsCount{key: key, count: 1})
+	f.metrics.FailedAttemptsSize.Set(float64(f.order.Len()))
+	return 1
+}
+
+// reset removes the count of the chunk, when it is pushed or its retries
+// are exhausted.
+func (f *failedAttempts) reset(addr swarm.Address) {
+	if e, ok := f.counts[addr.ByteString()]; ok {
+		f.remove(e)
+	}
+}
+
+func (f *failedAttempts) remove(e *list.Element) {
+	f.order.Remove(e)
+	delete(f.counts, e.Value.(*attemptsCount).key)
+	f.metrics.FailedAttemptsSize.Set(float64(f.order.Len()))
+}
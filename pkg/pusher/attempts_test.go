@@ -0,0 +1,56 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pusher
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestFailedAttempts(t *testing.T) {
+	metrics := newMetrics()
+	f := newFailedAttempts(2, metrics)
+
+	a := swarm.MustParseHexAddress("aa")
+	b := swarm.MustParseHexAddress("bb")
+	c := swarm.MustParseHexAddress("cc")
+
+	for i, want := range []int{1, 2, 3} {
+		if got := f.inc(a); got != want {
+			t.Fatalf("got %d attempts on failure %d, want %d", got, i, want)
+		}
+	}
+	if got := f.inc(b); got != 1 {
+		t.Fatalf("got %d attempts, want 1", got)
+	}
+
+	// the count of the chunk that failed least recently is evicted
+	f.inc(a)
+	f.inc(c)
+	if got := f.inc(b); got != 1 {
+		t.Fatalf("got %d attempts of evicted chunk, want 1", got)
+	}
+	if got := f.inc(c); got != 2 {
+		t.Fatalf("got %d attempts, want 2", got)
+	}
+
+	// the count is removed on reset
+	f.reset(c)
+	if got := f.inc(c); got != 1 {
+		t.Fatalf("got %d attempts after reset, want 1", got)
+	}
+
+	if got := testutil.ToFloat64(metrics.FailedAttemptsSize); got != 2 {
+		t.Errorf("got size %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(metrics.FailedAttemptsHits); got != 4 {
+		t.Errorf("got hits %v, want 4", got)
+	}
+	if got := testutil.ToFloat64(metrics.FailedAttemptsEvictions); got != 2 {
+		t.Errorf("got evictions %v, want 2", got)
+	}
+}
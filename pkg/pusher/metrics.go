@@ -18,6 +18,9 @@ type metrics struct {
 	TotalChunksSynced          prometheus.Counter
 	ErrorSettingChunkToSynced  prometheus.Counter
 	MarkAndSweepTimer          prometheus.Histogram
+	FailedAttemptsSize         prometheus.Gauge
+	FailedAttemptsHits         prometheus.Counter
+	FailedAttemptsEvictions    prometheus.Counter
 }
 
 func newMetrics() metrics {
@@ -49,6 +52,24 @@ func newMetrics() metrics {
 			Help:      "Histogram of time spent in mark and sweep.",
 			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 60},
 		}),
+		FailedAttemptsSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "failed_attempts_size",
+			Help:      "Number of chunks whose failed push attempts are counted.",
+		}),
+		FailedAttemptsHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "failed_attempts_hits",
+			Help:      "Total failed pushes of chunks whose previous push failed too.",
+		}),
+		FailedAttemptsEvictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "failed_attempts_evictions",
+			Help:      "Total counts of failed push attempts evicted to bound their number.",
+		}),
 	}
 }
 
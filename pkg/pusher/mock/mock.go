@@ -33,6 +33,7 @@ func (m *MockPusher) RcvdReceipt(uid uint32) error {
 	if err != nil {
 		return err
 	}
+	ta.Inc(tags.StateReceipt)
 	ta.Inc(tags.StateSynced)
 
 	return nil
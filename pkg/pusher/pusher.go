@@ -10,8 +10,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/clock"
 	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/receipts"
+	"github.com/ethersphere/bee/pkg/retry"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
@@ -23,6 +27,15 @@ type Service struct {
 	pushSyncer        pushsync.PushSyncer
 	logger            logging.Logger
 	tagg              *tags.Tags
+	receipts          receipts.Putter
+	events            pushsync.EventPublisher
+	failedAttempts    *failedAttempts
+	failedAttemptsMu  sync.Mutex
+	inflight          map[string]struct{} // chunks that are being pushed
+	inflightMu        sync.Mutex
+	retry             bool // failed pushes are waiting to be retried
+	retryPolicy       retry.Policy
+	clock             clock.Clock
 	metrics           metrics
 	quit              chan struct{}
 	chunksWorkerQuitC chan struct{}
@@ -33,18 +46,40 @@ type Options struct {
 	PeerSuggester topology.ClosestPeerer
 	PushSyncer    pushsync.PushSyncer
 	Tagger        *tags.Tags
+	Receipts      receipts.Putter
+	Events        pushsync.EventPublisher
+	RetryPolicy   retry.Policy // delays the retries of failed pushes, optional
+	Clock         clock.Clock  // times the retries, the system clock if not set
 	Logger        logging.Logger
 }
 
-var retryInterval = 10 * time.Second // time interval between retries
+var (
+	retryInterval   = 10 * time.Second // time interval between retries if no retry policy is set
+	maxPushAttempts = 5                // consecutive failed pushes of a chunk after which retries are reported as exhausted
+	maxFailedChunks = 100000           // chunks whose consecutive failed pushes are counted at the same time
+)
 
 func New(o Options) *Service {
+	if o.RetryPolicy == nil {
+		o.RetryPolicy = retry.Constant(retryInterval)
+	}
+	if o.Clock == nil {
+		o.Clock = clock.System
+	}
+
+	metrics := newMetrics()
 	service := &Service{
 		storer:            o.Storer,
 		pushSyncer:        o.PushSyncer,
 		tagg:              o.Tagger,
+		receipts:          o.Receipts,
+		events:            o.Events,
+		failedAttempts:    newFailedAttempts(maxFailedChunks, metrics),
+		inflight:          make(map[string]struct{}),
+		retryPolicy:       o.RetryPolicy,
+		clock:             o.Clock,
 		logger:            o.Logger,
-		metrics:           newMetrics(),
+		metrics:           metrics,
 		quit:              make(chan struct{}),
 		chunksWorkerQuitC: make(chan struct{}),
 	}
@@ -53,15 +88,16 @@ func New(o Options) *Service {
 }
 
 // chunksWorker is a loop that keeps looking for chunks that are locally uploaded ( by monitoring pushIndex )
-// and pushes them to the closest peer and get a receipt.
+// and pushes them to the closest peer and get a receipt. The push index subscription resumes from the last
+// chunk it sent as new chunks are stored, and it is started from the beginning of the index only when
+// pushes failed and need to be retried.
 func (s *Service) chunksWorker() {
 	var chunks <-chan swarm.Chunk
 	var unsubscribe func()
 	// timer, initially set to 0 to fall through select case on timer.C for initialisation
-	timer := time.NewTimer(0)
+	timer := s.clock.NewTimer(0)
 	defer timer.Stop()
 	defer close(s.chunksWorkerQuitC)
-	chunksInBatch := -1
 	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
 		<-s.quit
@@ -69,28 +105,34 @@ func (s *Service) chunksWorker() {
 	}()
 
 	sem := make(chan struct{}, 10)
-	inflight := make(map[string]struct{})
-	var mtx sync.Mutex
+
+	// retries are delayed more with every consecutive round that
+	// has failed pushes, as the retry policy decides
+	backoff := retry.NewBackoff(s.retryPolicy)
+	retryDelay := backoff.Next()
 
 LOOP:
 	for {
 		select {
 		// handle incoming chunks
 		case ch, more := <-chunks:
-			// if no more, set to nil, reset timer to 0 to finalise batch immediately
+			// if no more, the subscription has ended, set to nil and
+			// subscribe again after the retry interval
 			if !more {
 				chunks = nil
-				var dur time.Duration
-				if chunksInBatch == 0 {
-					dur = 500 * time.Millisecond
-				}
-				timer.Reset(dur)
+				timer.Reset(retryDelay)
 				break
 			}
 
+			// chunks of lower priority uploads are postponed until all
+			// higher priority uploads are synced or their priority deadline passes
+			if s.chunkPriority(ch) < s.tagg.ActivePriority() {
+				s.retryLater()
+				continue
+			}
+
 			// postpone a retry only after we've finished processing everything in index
-			timer.Reset(retryInterval)
-			chunksInBatch++
+			timer.Reset(retryDelay)
 			s.metrics.TotalChunksToBeSentCounter.Inc()
 			select {
 			case sem <- struct{}{}:
@@ -100,15 +142,17 @@ LOOP:
 				}
 				return
 			}
-			mtx.Lock()
-			if _, ok := inflight[ch.Address().String()]; ok {
-				mtx.Unlock()
+			s.inflightMu.Lock()
+			if _, ok := s.inflight[ch.Address().String()]; ok {
+				s.inflightMu.Unlock()
 				<-sem
 				continue
 			}
 
-			inflight[ch.Address().String()] = struct{}{}
-			mtx.Unlock()
+			s.inflight[ch.Address().String()] = struct{}{}
+			s.inflightMu.Unlock()
+
+			s.publish(pushsync.Event{Type: pushsync.EventChunkQueued, Address: ch.Address()})
 
 			go func(ctx context.Context, ch swarm.Chunk) {
 				var err error
@@ -117,24 +161,45 @@ LOOP:
 						// only print this if there was no error while sending the chunk
 						s.logger.Tracef("pusher pushed chunk %s", ch.Address().String())
 					}
-					mtx.Lock()
-					delete(inflight, ch.Address().String())
-					mtx.Unlock()
+					s.inflightMu.Lock()
+					delete(s.inflight, ch.Address().String())
+					s.inflightMu.Unlock()
 					<-sem
 				}()
-				// Later when we process receipt, get the receipt and process it
-				// for now ignoring the receipt and checking only for error
-				_, err = s.pushSyncer.PushChunkToClosest(ctx, ch)
+				receipt, err := s.pushSyncer.PushChunkToClosest(ctx, ch)
 				if err != nil {
+					// the temporary failures do not count as push
+					// attempts
+					var te *p2p.TemporaryError
+					if errors.Is(err, pushsync.ErrInflight) || errors.As(err, &te) {
+						s.retryLater()
+						return
+					}
 					if !errors.Is(err, topology.ErrNotFound) {
 						s.logger.Debugf("pusher: error while sending chunk or receiving receipt: %v", err)
 					}
+					s.pushFailed(ch.Address())
 					return
 				}
+				s.pushSucceeded(ch.Address())
+				s.storeReceipt(receipt)
 				s.setChunkAsSynced(ctx, ch)
 			}(ctx, ch)
-		case <-timer.C:
-			// initially timer is set to go off as well as every time we hit the end of push index
+		case <-timer.C():
+			// the running subscription picks up the newly stored chunks
+			// by itself, it only needs to be started again to retry
+			// the chunks that failed to be pushed
+			if chunks != nil && !s.takeRetry() {
+				backoff.Reset()
+				retryDelay = backoff.Next()
+				timer.Reset(retryDelay)
+				break
+			}
+
+			// reset timer to go off after the next retry delay
+			retryDelay = backoff.Next()
+			timer.Reset(retryDelay)
+
 			startTime := time.Now()
 
 			// if subscribe was running, stop it
@@ -144,9 +209,6 @@ LOOP:
 
 			// and start iterating on Push index from the beginning
 			chunks, unsubscribe = s.storer.SubscribePush(ctx)
-
-			// reset timer to go off after retryInterval
-			timer.Reset(retryInterval)
 			s.metrics.MarkAndSweepTimer.Observe(time.Since(startTime).Seconds())
 
 		case <-s.quit:
@@ -173,6 +235,84 @@ LOOP:
 	}
 }
 
+// InflightCount returns the number of the chunks that are being pushed.
+func (s *Service) InflightCount() int {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+
+	return len(s.inflight)
+}
+
+// chunkPriority returns the push priority of the tag that the chunk belongs to.
+func (s *Service) chunkPriority(ch swarm.Chunk) tags.Priority {
+	t, err := s.tagg.Get(ch.TagID())
+	if err != nil || t == nil {
+		return tags.PriorityNormal
+	}
+	return t.Priority
+}
+
+// pushFailed counts a failed push of the chunk and reports the exhausted
+// retries once the chunk failed maxPushAttempts times in a row. The chunk
+// stays in the push index and is attempted again afterwards.
+func (s *Service) pushFailed(addr swarm.Address) {
+	s.failedAttemptsMu.Lock()
+	defer s.failedAttemptsMu.Unlock()
+
+	s.retry = true
+
+	if s.failedAttempts.inc(addr) < maxPushAttempts {
+		return
+	}
+	s.failedAttempts.reset(addr)
+	s.publish(pushsync.Event{Type: pushsync.EventRetriesExhausted, Address: addr})
+}
+
+func (s *Service) pushSucceeded(addr swarm.Address) {
+	s.failedAttemptsMu.Lock()
+	defer s.failedAttemptsMu.Unlock()
+
+	s.failedAttempts.reset(addr)
+}
+
+// retryLater makes the push index to be iterated again from the beginning
+// after the retry interval.
+func (s *Service) retryLater() {
+	s.failedAttemptsMu.Lock()
+	defer s.failedAttemptsMu.Unlock()
+
+	s.retry = true
+}
+
+// takeRetry reports whether there are failed pushes to be retried
+// since the last call.
+func (s *Service) takeRetry() bool {
+	s.failedAttemptsMu.Lock()
+	defer s.failedAttemptsMu.Unlock()
+
+	retry := s.retry
+	s.retry = false
+	return retry
+}
+
+// publish publishes the push progress event if the event publisher is configured.
+func (s *Service) publish(ev pushsync.Event) {
+	if s.events == nil {
+		return
+	}
+	s.events.Publish(ev)
+}
+
+// storeReceipt persists the receipt if the receipts store is configured.
+func (s *Service) storeReceipt(receipt *pushsync.Receipt) {
+	if s.receipts == nil || receipt == nil {
+		return
+	}
+	if err := s.receipts.Put(*receipt); err != nil {
+		s.logger.Debugf("pusher: store receipt for chunk %s: %v", receipt.Address, err)
+	}
+}
+
 func (s *Service) setChunkAsSynced(ctx context.Context, ch swarm.Chunk) {
 	if err := s.storer.Set(ctx, storage.ModeSetSyncPush, ch.Address()); err != nil {
 		s.logger.Errorf("pusher: error setting chunk as synced: %v", err)
@@ -9,15 +9,20 @@ import (
 	"errors"
 	"io/ioutil"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
-	"github.com/ethersphere/bee/pkg/localstore"
+	clockmock "github.com/ethersphere/bee/pkg/clock/mock"
 	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/pusher"
 	"github.com/ethersphere/bee/pkg/pushsync"
 	pushsyncmock "github.com/ethersphere/bee/pkg/pushsync/mock"
+	"github.com/ethersphere/bee/pkg/receipts"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/inmem"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
 	"github.com/ethersphere/bee/pkg/topology/mock"
@@ -33,14 +38,15 @@ type Store struct {
 	modeSetMu *sync.Mutex
 }
 
-// Override the Set function to capture the ModeSetSyncPush
+// Override the Set function to capture the ModeSetSyncPush, the chunks are
+// also set in the underlying store so that synced chunks leave the push index
 func (s Store) Set(ctx context.Context, mode storage.ModeSet, addrs ...swarm.Address) error {
 	s.modeSetMu.Lock()
-	defer s.modeSetMu.Unlock()
 	for _, addr := range addrs {
 		s.modeSet[addr.String()] = mode
 	}
-	return nil
+	s.modeSetMu.Unlock()
+	return s.Storer.Set(ctx, mode, addrs...)
 }
 
 // TestSendChunkToPushSync sends a chunk to pushsync to be sent ot its closest peer and get a receipt.
@@ -94,6 +100,51 @@ func TestSendChunkToPushSyncWithTag(t *testing.T) {
 	p.Close()
 }
 
+// TestSendChunkStoresReceipt checks that the receipt received for a pushed
+// chunk is stored in the receipts store.
+func TestSendChunkStoresReceipt(t *testing.T) {
+	chunk := createChunk()
+
+	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+	storerPeer := swarm.MustParseHexAddress("f000000000000000000000000000000000000000000000000000000000000000")
+
+	pushSyncService := pushsyncmock.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		return &pushsync.Receipt{Address: chunk.Address(), Storer: storerPeer}, nil
+	})
+
+	logger := logging.New(ioutil.Discard, 0)
+	storer := inmem.New(triggerPeer.Bytes(), inmem.Options{})
+	defer storer.Close()
+	receiptStore := receipts.New(statestore.NewStateStore())
+
+	p := pusher.New(pusher.Options{Storer: storer, PushSyncer: pushSyncService, Tagger: tags.NewTags(), Receipts: receiptStore, Logger: logger})
+	defer p.Close()
+
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		receipt *pushsync.Receipt
+		err     error
+	)
+	for i := 0; i < noOfRetries; i++ {
+		// Give some time for chunk to be pushed and receipt to be received
+		time.Sleep(10 * time.Millisecond)
+
+		receipt, err = receiptStore.Get(chunk.Address())
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !receipt.Storer.Equal(storerPeer) {
+		t.Fatalf("got receipt storer %s, want %s", receipt.Storer, storerPeer)
+	}
+}
+
 // TestSendChunkToPushSyncWithoutTag is similar to TestSendChunkToPushSync, excep that the tags are not
 // present to simulate bzz api withotu splitter condition
 func TestSendChunkToPushSyncWithoutTag(t *testing.T) {
@@ -213,6 +264,340 @@ func TestSendChunkAndTimeoutinReceivingReceipt(t *testing.T) {
 	}
 }
 
+// TestPushPriority checks that chunks of a normal priority tag are not pushed
+// while a high priority tag still has chunks which are not synced.
+func TestPushPriority(t *testing.T) {
+	defer func(d time.Duration) { *pusher.RetryInterval = d }(*pusher.RetryInterval)
+	*pusher.RetryInterval = 50 * time.Millisecond
+
+	// create a trigger  and a closestpeer
+	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("f000000000000000000000000000000000000000000000000000000000000000")
+
+	highChunk := swarm.NewChunk(swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), []byte("1234"))
+	normalChunk := swarm.NewChunk(swarm.MustParseHexAddress("7100000000000000000000000000000000000000000000000000000000000000"), []byte("5678"))
+
+	var (
+		mtx          sync.Mutex
+		highSynced   bool
+		normalPushed bool
+	)
+	pushSyncService := pushsyncmock.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if chunk.Address().Equal(highChunk.Address()) && !highSynced {
+			return nil, errors.New("no receipt")
+		}
+		if chunk.Address().Equal(normalChunk.Address()) {
+			normalPushed = true
+		}
+		return &pushsync.Receipt{Address: chunk.Address()}, nil
+	})
+	// the clock of the tags expires the cached active priority
+	clock := clockmock.New(time.Now())
+	mtags := tags.NewTagsWithClock(clock)
+	p, storer := createPusherWithTags(t, triggerPeer, mtags, pushSyncService, mock.WithClosestPeer(closestPeer))
+	defer storer.Close()
+	defer p.Close()
+
+	high, err := mtags.CreateWithPriority("high", 1, false, tags.PriorityHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	high.Inc(tags.StateStored)
+	normal, err := mtags.Create("normal", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normal.Inc(tags.StateStored)
+
+	for _, ch := range []swarm.Chunk{normalChunk.WithTagID(normal.Uid), highChunk.WithTagID(high.Uid)} {
+		if _, err := storer.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// give the pusher time for a few iterations over the push index
+	time.Sleep(200 * time.Millisecond)
+
+	mtx.Lock()
+	if normalPushed {
+		mtx.Unlock()
+		t.Fatal("normal priority chunk pushed before the high priority tag is synced")
+	}
+	highSynced = true
+	mtx.Unlock()
+
+	for i := 0; i < noOfRetries; i++ {
+		time.Sleep(50 * time.Millisecond)
+		clock.Add(time.Second)
+
+		err = checkIfModeSet(normalChunk.Address(), storage.ModeSetSyncPush, storer)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPushEvents checks that the queued and exhausted retries events are
+// published for a chunk that can not be pushed.
+func TestPushEvents(t *testing.T) {
+	defer func(d time.Duration, n int) {
+		*pusher.RetryInterval = d
+		*pusher.MaxPushAttempts = n
+	}(*pusher.RetryInterval, *pusher.MaxPushAttempts)
+	*pusher.RetryInterval = 10 * time.Millisecond
+	*pusher.MaxPushAttempts = 2
+
+	chunk := createChunk()
+	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+
+	pushSyncService := pushsyncmock.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		return nil, errors.New("no receipt")
+	})
+
+	logger := logging.New(ioutil.Discard, 0)
+	storer := inmem.New(triggerPeer.Bytes(), inmem.Options{})
+	defer storer.Close()
+
+	events := pushsync.NewEvents()
+	c, unsubscribe := events.Subscribe()
+	defer unsubscribe()
+
+	p := pusher.New(pusher.Options{Storer: storer, PushSyncer: pushSyncService, Tagger: tags.NewTags(), Events: events, Logger: logger})
+	defer p.Close()
+
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	var queued int
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-c:
+			if !e.Address.Equal(chunk.Address()) {
+				t.Fatalf("got event for chunk %s, want %s", e.Address, chunk.Address())
+			}
+			switch e.Type {
+			case pushsync.EventChunkQueued:
+				queued++
+			case pushsync.EventRetriesExhausted:
+				if queued < *pusher.MaxPushAttempts {
+					t.Fatalf("got exhausted retries after %d queued events, want at least %d", queued, *pusher.MaxPushAttempts)
+				}
+				return
+			default:
+				t.Fatalf("unexpected event type %q", e.Type)
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for exhausted retries event")
+		}
+	}
+}
+
+// TestPushTemporaryError checks that the temporary push failures are retried
+// without exhausting the push attempts of the chunk.
+func TestPushTemporaryError(t *testing.T) {
+	defer func(d time.Duration, n int) {
+		*pusher.RetryInterval = d
+		*pusher.MaxPushAttempts = n
+	}(*pusher.RetryInterval, *pusher.MaxPushAttempts)
+	*pusher.RetryInterval = 10 * time.Millisecond
+	*pusher.MaxPushAttempts = 2
+
+	chunk := createChunk()
+	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+
+	var calls int32
+	pushSyncService := pushsyncmock.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		if atomic.AddInt32(&calls, 1) <= 5 {
+			return nil, p2p.NewTemporaryError(time.Millisecond, errors.New("no peer"))
+		}
+		return &pushsync.Receipt{Address: chunk.Address()}, nil
+	})
+
+	logger := logging.New(ioutil.Discard, 0)
+	storer := inmem.New(triggerPeer.Bytes(), inmem.Options{})
+	defer storer.Close()
+
+	events := pushsync.NewEvents()
+	c, unsubscribe := events.Subscribe()
+	defer unsubscribe()
+
+	p := pusher.New(pusher.Options{Storer: storer, PushSyncer: pushSyncService, Tagger: tags.NewTags(), Events: events, Logger: logger})
+	defer p.Close()
+
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for atomic.LoadInt32(&calls) <= 5 {
+		select {
+		case e := <-c:
+			if e.Type == pushsync.EventRetriesExhausted {
+				t.Fatal("got exhausted retries for temporary failures")
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the retries")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// TestInflightCount checks that the chunks are counted as in flight while
+// they are being pushed.
+func TestInflightCount(t *testing.T) {
+	chunk := createChunk()
+	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+
+	var pushingOnce sync.Once
+	pushing := make(chan struct{})
+	release := make(chan struct{})
+	pushSyncService := pushsyncmock.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		pushingOnce.Do(func() { close(pushing) })
+		<-release
+		return &pushsync.Receipt{Address: chunk.Address()}, nil
+	})
+
+	_, p, storer := createPusher(t, triggerPeer, pushSyncService)
+	defer p.Close()
+
+	if got := p.InflightCount(); got != 0 {
+		t.Fatalf("got %d chunks in flight, want %d", got, 0)
+	}
+
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-pushing:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the push")
+	}
+	if got := p.InflightCount(); got != 1 {
+		t.Fatalf("got %d chunks in flight, want %d", got, 1)
+	}
+
+	close(release)
+	for i := 0; i < noOfRetries; i++ {
+		if p.InflightCount() == 0 {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("got %d chunks in flight after the push, want %d", p.InflightCount(), 0)
+}
+
+// subscribeCountingStore counts the push index subscriptions.
+type subscribeCountingStore struct {
+	storage.Storer
+	count int32
+}
+
+func (s *subscribeCountingStore) SubscribePush(ctx context.Context) (<-chan swarm.Chunk, func()) {
+	atomic.AddInt32(&s.count, 1)
+	return s.Storer.SubscribePush(ctx)
+}
+
+// TestPushSubscriptionResumed checks that the push index subscription is
+// started again from the beginning only if there are failed pushes to retry.
+func TestPushSubscriptionResumed(t *testing.T) {
+	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+
+	var failOnce sync.Once
+	pushed := make(chan swarm.Address, 10)
+	pushSyncService := pushsyncmock.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		var err error
+		if chunk.Data()[0] == 'f' {
+			failOnce.Do(func() { err = errors.New("no receipt") })
+		}
+		if err == nil {
+			pushed <- chunk.Address()
+		}
+		return &pushsync.Receipt{Address: chunk.Address()}, err
+	})
+
+	logger := logging.New(ioutil.Discard, 0)
+	db := inmem.New(triggerPeer.Bytes(), inmem.Options{})
+	defer db.Close()
+	storer := &subscribeCountingStore{Storer: db}
+
+	clock := clockmock.New(time.Now())
+	p := pusher.New(pusher.Options{Storer: storer, PushSyncer: pushSyncService, Tagger: tags.NewTags(), Clock: clock, Logger: logger})
+	defer p.Close()
+
+	// advance moves the clock to the next retry and waits for the retry
+	// timer to be set again
+	advance := func() {
+		t.Helper()
+
+		clock.Add(*pusher.RetryInterval)
+		for deadline := time.Now().Add(5 * time.Second); clock.Timers() == 0; {
+			if time.Now().After(deadline) {
+				t.Fatal("retry timer not set")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitPushed := func(ch swarm.Chunk) {
+		t.Helper()
+
+		select {
+		case addr := <-pushed:
+			if !addr.Equal(ch.Address()) {
+				t.Fatalf("got pushed chunk %s, want %s", addr, ch.Address())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("chunk %s not pushed", ch.Address())
+		}
+	}
+
+	chunk := swarm.NewChunk(swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), []byte("chunk"))
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunk); err != nil {
+		t.Fatal(err)
+	}
+	waitPushed(chunk)
+
+	for i := 0; i < 5; i++ {
+		advance()
+	}
+	if got := atomic.LoadInt32(&storer.count); got != 1 {
+		t.Fatalf("got %d subscriptions without failed pushes, want 1", got)
+	}
+
+	failing := swarm.NewChunk(swarm.MustParseHexAddress("7100000000000000000000000000000000000000000000000000000000000000"), []byte("failing chunk"))
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, failing); err != nil {
+		t.Fatal(err)
+	}
+	// the failed push is retried once the clock reaches the retry
+	for i := 0; ; i++ {
+		if i == 50 {
+			t.Fatalf("chunk %s not pushed", failing.Address())
+		}
+		advance()
+		select {
+		case addr := <-pushed:
+			if !addr.Equal(failing.Address()) {
+				t.Fatalf("got pushed chunk %s, want %s", addr, failing.Address())
+			}
+		case <-time.After(100 * time.Millisecond):
+			continue
+		}
+		break
+	}
+
+	if got := atomic.LoadInt32(&storer.count); got < 2 {
+		t.Fatalf("got %d subscriptions after a failed push, want at least 2", got)
+	}
+}
+
 func createChunk() swarm.Chunk {
 	// chunk data to upload
 	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
@@ -221,14 +606,18 @@ func createChunk() swarm.Chunk {
 }
 
 func createPusher(t *testing.T, addr swarm.Address, pushSyncService pushsync.PushSyncer, mockOpts ...mock.Option) (*tags.Tags, *pusher.Service, *Store) {
+	t.Helper()
+	mtags := tags.NewTags()
+	p, storer := createPusherWithTags(t, addr, mtags, pushSyncService, mockOpts...)
+	return mtags, p, storer
+}
+
+// createPusherWithTags creates a pusher of the chunks of the tags.
+func createPusherWithTags(t *testing.T, addr swarm.Address, mtags *tags.Tags, pushSyncService pushsync.PushSyncer, mockOpts ...mock.Option) (*pusher.Service, *Store) {
 	t.Helper()
 	logger := logging.New(ioutil.Discard, 0)
-	storer, err := localstore.New("", addr.Bytes(), nil, logger)
-	if err != nil {
-		t.Fatal(err)
-	}
+	storer := inmem.New(addr.Bytes(), inmem.Options{})
 
-	mtags := tags.NewTags()
 	pusherStorer := &Store{
 		Storer:    storer,
 		modeSet:   make(map[string]storage.ModeSet),
@@ -237,7 +626,7 @@ func createPusher(t *testing.T, addr swarm.Address, pushSyncService pushsync.Pus
 	peerSuggester := mock.NewTopologyDriver(mockOpts...)
 
 	pusherService := pusher.New(pusher.Options{Storer: pusherStorer, PushSyncer: pushSyncService, Tagger: mtags, PeerSuggester: peerSuggester, Logger: logger})
-	return mtags, pusherService, pusherStorer
+	return pusherService, pusherStorer
 }
 
 func checkIfModeSet(addr swarm.Address, mode storage.ModeSet, storer *Store) error {
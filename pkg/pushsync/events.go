@@ -0,0 +1,111 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pushsync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// EventType is the kind of the push progress event.
+type EventType string
+
+const (
+	// EventChunkQueued is published when a chunk is taken from the push
+	// index to be pushed.
+	EventChunkQueued EventType = "queued"
+	// EventChunkSent is published when a chunk is sent to the closest peer.
+	EventChunkSent EventType = "sent"
+	// EventReceiptReceived is published when a valid receipt is received
+	// for a chunk.
+	EventReceiptReceived EventType = "receipt"
+	// EventRetriesExhausted is published when a chunk push failed the
+	// maximal number of consecutive attempts.
+	EventRetriesExhausted EventType = "retries-exhausted"
+)
+
+// Event describes a step in the push progress of a chunk.
+type Event struct {
+	Type    EventType     `json:"type"`
+	Address swarm.Address `json:"address"`
+	Peer    swarm.Address `json:"peer"`
+	Time    time.Time     `json:"time"`
+}
+
+// EventPublisher publishes push progress events.
+type EventPublisher interface {
+	Publish(e Event)
+}
+
+// EventSubscriber provides subscriptions to push progress events.
+type EventSubscriber interface {
+	Subscribe() (c <-chan Event, unsubscribe func())
+}
+
+var (
+	_ EventPublisher  = (*Events)(nil)
+	_ EventSubscriber = (*Events)(nil)
+)
+
+// eventsBufferSize is the number of events buffered for every subscriber.
+// Events are dropped for subscribers that do not keep up.
+const eventsBufferSize = 128
+
+// Events is the push progress event bus.
+type Events struct {
+	subscribers map[chan Event]struct{}
+	mu          sync.Mutex
+}
+
+// NewEvents returns a new push progress event bus.
+func NewEvents() *Events {
+	return &Events{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish sends the event to all subscribers without blocking. If the time
+// of the event is not set, the current time is used.
+func (e *Events) Publish(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for c := range e.subscribers {
+		select {
+		case c <- ev:
+		default:
+		}
+	}
+}
+
+// Subscribe returns the channel on which the events are received. Returned
+// function is safe to be called multiple times.
+func (e *Events) Subscribe() (c <-chan Event, unsubscribe func()) {
+	channel := make(chan Event, eventsBufferSize)
+	var closeOnce sync.Once
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.subscribers[channel] = struct{}{}
+
+	unsubscribe = func() {
+		closeOnce.Do(func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+
+			delete(e.subscribers, channel)
+			close(channel)
+		})
+	}
+
+	return channel, unsubscribe
+}
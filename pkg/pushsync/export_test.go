@@ -4,8 +4,22 @@
 
 package pushsync
 
+import (
+	"time"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
 var (
 	ProtocolName    = protocolName
 	ProtocolVersion = protocolVersion
 	StreamName      = streamName
 )
+
+func SetTimeNow(f func() time.Time) {
+	timeNow = f
+}
+
+func (s *PeerScores) Record(peer swarm.Address, success bool) {
+	s.record(peer, success)
+}
@@ -25,8 +25,17 @@ type metrics struct {
 	ReceiveReceiptErrorCounter prometheus.Counter
 	RetriesExhaustedCounter    prometheus.Counter
 	InvalidReceiptReceived     prometheus.Counter
+	InvalidChunkReceived       prometheus.Counter
+	OutOfDepthReceiptReceived  prometheus.Counter
+	DuplicatePushSuppressed    prometheus.Counter
+	ChunksReplicated           prometheus.Counter
+	ReplicationErrorCounter    prometheus.Counter
+	ReplicationRejectedCounter prometheus.Counter
 	SendChunkTimer             prometheus.Histogram
 	ReceiptRTT                 prometheus.Histogram
+	PeerReceiptsReceived       *prometheus.CounterVec
+	PeerSendErrors             *prometheus.CounterVec
+	PeerReceiptRTT             *prometheus.HistogramVec
 }
 
 func newMetrics() metrics {
@@ -99,6 +108,42 @@ func newMetrics() metrics {
 			Name:      "invalid_receipt_receipt",
 			Help:      "Invalid receipt received from peer.",
 		}),
+		InvalidChunkReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "invalid_chunk_received",
+			Help:      "Chunks received from peers that are not valid.",
+		}),
+		OutOfDepthReceiptReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "out_of_depth_receipt_received",
+			Help:      "Receipt received from peer outside of the storage depth.",
+		}),
+		DuplicatePushSuppressed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "duplicate_push_suppressed",
+			Help:      "Total no of times a chunk push was skipped as the same chunk was already in flight.",
+		}),
+		ChunksReplicated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "chunks_replicated",
+			Help:      "Total no of chunks replicated to neighbours.",
+		}),
+		ReplicationErrorCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "replication_error",
+			Help:      "Total no of errors while replicating chunks to neighbours.",
+		}),
+		ReplicationRejectedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "replication_rejected",
+			Help:      "Total no of replicated chunks rejected as outside of storage depth.",
+		}),
 		SendChunkTimer: prometheus.NewHistogram(prometheus.HistogramOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -113,6 +158,25 @@ func newMetrics() metrics {
 			Help:      "Histogram of RTT for receiving receipt for a pushed chunk.",
 			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 60},
 		}),
+		PeerReceiptsReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "peer_received_receipts",
+			Help:      "Total no of valid receipts received by peer.",
+		}, []string{"peer"}),
+		PeerSendErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "peer_send_error",
+			Help:      "Total no of failed chunk pushes by peer.",
+		}, []string{"peer"}),
+		PeerReceiptRTT: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "peer_receipt_rtt_histogram",
+			Help:      "Histogram of RTT for receiving receipt for a pushed chunk by peer.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 60},
+		}, []string{"peer"}),
 	}
 }
 
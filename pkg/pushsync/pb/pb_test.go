@@ -0,0 +1,36 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p/protobuf/protobuftest"
+	"github.com/ethersphere/bee/pkg/pushsync/pb"
+)
+
+// TestMessages checks that the wire format of the messages is compatible
+// with the released version of the protocol.
+func TestMessages(t *testing.T) {
+	protobuftest.Check(t,
+		protobuftest.Message{
+			Message: &pb.Delivery{Address: []byte{1, 2, 3}, Data: []byte("data")},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Address", WireType: "bytes"},
+				{Number: 2, Name: "Data", WireType: "bytes"},
+			},
+			Wire: "0a03010203120464617461",
+		},
+		protobuftest.Message{
+			Message: &pb.Receipt{Address: []byte{1, 2, 3}, Storer: []byte{4, 5, 6}, Signature: []byte{7, 8, 9}},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Address", WireType: "bytes"},
+				{Number: 2, Name: "Storer", WireType: "bytes"},
+				{Number: 3, Name: "Signature", WireType: "bytes"},
+			},
+			Wire: "0a0301020312030405061a03070809",
+		},
+	)
+}
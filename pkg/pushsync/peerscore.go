@@ -0,0 +1,103 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pushsync
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology"
+)
+
+var _ topology.Disconnecter = (*PeerScores)(nil)
+
+const (
+	// peerScoreWeight is the weight of the latest push outcome in the peer
+	// score.
+	peerScoreWeight = 0.2
+	// peerScoreHalfLife is the time in which the distance of a peer score
+	// from 1 halves, so that the peers that failed are tried again.
+	peerScoreHalfLife = 10 * time.Minute
+	// minPeerScore is the score below which a peer is pushed to only if no
+	// other peer is closer to the chunk than this node.
+	minPeerScore = 0.5
+)
+
+// timeNow is used to deterministically mock time.Now() in tests.
+var timeNow = time.Now
+
+// PeerScores keeps the reliability scores of peers as push targets. A score
+// is the moving average of the push outcomes, which is 1 for the peers that
+// were not pushed to yet, decreases with every failed push and recovers
+// towards 1 over time. The scores are kept only for the connected peers.
+type PeerScores struct {
+	scores map[string]peerScore
+	mu     sync.Mutex
+}
+
+type peerScore struct {
+	score   float64
+	updated time.Time
+}
+
+// current returns the score recovered since it was updated.
+func (s peerScore) current(now time.Time) float64 {
+	halfLives := float64(now.Sub(s.updated)) / float64(peerScoreHalfLife)
+	return 1 - (1-s.score)*math.Pow(0.5, halfLives)
+}
+
+func NewPeerScores() *PeerScores {
+	return &PeerScores{
+		scores: make(map[string]peerScore),
+	}
+}
+
+// PeerScore returns the push reliability score of the peer, from 0 for
+// peers that always fail to 1 for peers that never fail.
+func (s *PeerScores) PeerScore(peer swarm.Address) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	score, ok := s.scores[peer.ByteString()]
+	if !ok {
+		return 1
+	}
+	return score.current(timeNow())
+}
+
+// Disconnected removes the score of the disconnected peer.
+func (s *PeerScores) Disconnected(peer swarm.Address) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.scores, peer.ByteString())
+}
+
+// unreliable is the topology filter of the peers with a score lower than
+// the minimum.
+func (s *PeerScores) unreliable(peer swarm.Address) bool {
+	return s.PeerScore(peer) < minPeerScore
+}
+
+func (s *PeerScores) record(peer swarm.Address, success bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := timeNow()
+	score := 1.0
+	if ps, ok := s.scores[peer.ByteString()]; ok {
+		score = ps.current(now)
+	}
+	var outcome float64
+	if success {
+		outcome = 1
+	}
+	s.scores[peer.ByteString()] = peerScore{
+		score:   (1-peerScoreWeight)*score + peerScoreWeight*outcome,
+		updated: now,
+	}
+}
@@ -8,63 +8,220 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/pkg/pushsync/pb"
+	"github.com/ethersphere/bee/pkg/slowlog"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
 	"github.com/ethersphere/bee/pkg/topology"
+	"github.com/ethersphere/bee/pkg/tracing"
+	"github.com/opentracing/opentracing-go"
+	"golang.org/x/crypto/sha3"
 )
 
 const (
-	protocolName    = "pushsync"
-	protocolVersion = "1.0.0"
-	streamName      = "pushsync"
+	protocolName          = "pushsync"
+	protocolVersion       = "2.0.0"
+	streamName            = "pushsync"
+	replicationStreamName = "replication"
+	// receiptSignPrefix is hashed with the chunk address into the data
+	// that the storer signs in the receipt, so that the signature is not
+	// valid for the other uses of the node key.
+	receiptSignPrefix = "swarm pushsync receipt"
+	// maxOutOfDepthRetries is the number of the times that a chunk is
+	// pushed again to the next closest peer after a receipt of a storer
+	// outside of the storage depth.
+	maxOutOfDepthRetries = 2
+)
+
+var (
+	// ErrOutOfDepthReceipt is returned when a receipt is received from a peer
+	// that is not within the storage depth of the pushed chunk, or when the
+	// storer of the receipt can not be verified from its signature.
+	ErrOutOfDepthReceipt = errors.New("receipt from peer outside of storage depth")
+	// ErrInflight is returned when a chunk is pushed while a previous push
+	// of the same chunk has not yet been completed.
+	ErrInflight = errors.New("chunk push already in flight")
+	// ErrNotResponsible is returned when a chunk is replicated to a node
+	// that is not within the storage depth of the chunk.
+	ErrNotResponsible = errors.New("replicated chunk outside of storage depth")
+	// ErrNotNeighbour is returned when a chunk is replicated to a node by a
+	// peer that is not within the neighborhood depth of the node.
+	ErrNotNeighbour = errors.New("replicating peer outside of neighborhood")
+	// ErrLightNode is returned when a chunk is pushed to a light node that
+	// would have to store it, as light nodes do not store pushed chunks.
+	ErrLightNode = errors.New("light node does not store pushed chunks")
 )
 
 type PushSyncer interface {
 	PushChunkToClosest(ctx context.Context, ch swarm.Chunk) (*Receipt, error)
 }
 
+var _ EventSubscriber = (*PushSync)(nil)
+
 type Receipt struct {
-	Address swarm.Address
+	Address swarm.Address `json:"address"`
+	// Storer is the overlay address of the node that stored the chunk. It
+	// is verified from the signature of the receipt only if the storer
+	// depth check is enabled.
+	Storer swarm.Address `json:"storer"`
 }
 
 type PushSync struct {
+	base          swarm.Address
+	signer        crypto.Signer
+	networkID     uint64
 	streamer      p2p.Streamer
 	storer        storage.Putter
+	validator     swarm.ChunkValidator
 	peerSuggester topology.ClosestPeerer
+	depther       topology.NeighborhoodDepther
+	storageDepth  topology.NeighborhoodDepther
+	neighbours    topology.EachPeerer
+	replication   int
+	lightNode     bool
 	tagg          *tags.Tags
+	accounting    accounting.Interface
+	pricer        accounting.Pricer
+	events        *Events
+	peerScores    *PeerScores
 	logger        logging.Logger
+	tracer        *tracing.Tracer
+	slowReceipt   *slowlog.Logger
 	metrics       metrics
+	inflight      map[string]struct{} // chunk addresses that are currently being pushed
+	inflightMu    sync.Mutex
+	quit          chan struct{}
+	closeOnce     sync.Once
+	wg            sync.WaitGroup // replications in progress
 }
 
 type Options struct {
+	Base swarm.Address
+	// Signer signs the receipts of the chunks stored by this node with the
+	// key of the Base overlay address in the network with NetworkID, so
+	// that the peers can verify the storer. Receipts are not signed if it
+	// is not set.
+	Signer        crypto.Signer
+	NetworkID     uint64
 	Streamer      p2p.Streamer
 	Storer        storage.Putter
 	ClosestPeerer topology.ClosestPeerer
-	Tagger        *tags.Tags
-	Logger        logging.Logger
+	// ChunkValidator rejects the received chunks that are not valid, which
+	// are then neither stored nor forwarded. Chunks are not validated if it
+	// is not set.
+	ChunkValidator swarm.ChunkValidator
+	// ReceiptDepther enables the storer depth check on receipts if set.
+	// Receipts are then accepted only if they are signed by storers that
+	// are within the neighborhood depth of the chunk, otherwise the chunk
+	// is pushed to the next closest peer and the delivery is treated as
+	// incomplete if no peer returns such a receipt.
+	ReceiptDepther topology.NeighborhoodDepther
+	// StorageDepther enables storing the forwarded chunks that are within
+	// the current neighborhood depth of this node, as the node shares the
+	// storage responsibility for them with the closer peers. Only the
+	// chunks for which this node is the closest peer are stored if it is
+	// not set.
+	StorageDepther topology.NeighborhoodDepther
+	// ReplicationPeers and ReplicationFactor enable the replication of
+	// the chunks that are stored by this node as the closest node. Each
+	// such chunk is replicated to ReplicationFactor connected peers within
+	// the neighborhood depth of this node that are the closest to the
+	// chunk, which do not forward it any further. Chunks are replicated to
+	// and accepted from the neighbours only if StorageDepther is set.
+	ReplicationPeers  topology.EachPeerer
+	ReplicationFactor int
+	// LightNode makes the node forward the pushed chunks without storing
+	// them, and refuse the chunks that it would have to store.
+	LightNode bool
+	Tagger    *tags.Tags
+	// Accounting is credited for the chunks delivered to peers that
+	// returned a receipt and debited for the receipts returned to peers.
+	// It defaults to an accounting that does not keep any balances.
+	Accounting accounting.Interface
+	// Pricer prices the chunk deliveries. It defaults to a pricer with
+	// zero prices.
+	Pricer accounting.Pricer
+	// Events receives the push progress events. A new event bus is
+	// created if it is not set.
+	Events *Events
+	// PeerScores records the outcomes of the pushes to peers. New scores
+	// are created if it is not set.
+	PeerScores *PeerScores
+	// SlowReceiptThreshold is the duration of waiting for a receipt above
+	// which the wait is logged as slow. It is not logged if it is zero.
+	SlowReceiptThreshold time.Duration
+	Logger               logging.Logger
+	Tracer               *tracing.Tracer
 }
 
 var timeToWaitForReceipt = 3 * time.Second // time to wait to get a receipt for a chunk
 
+var (
+	// invalidChunkBlockDuration is the duration for which the peers that
+	// deliver invalid chunks are blocklisted.
+	invalidChunkBlockDuration = 10 * time.Minute
+	// retryAfter is the duration after which the pushes that failed
+	// temporarily, as no peer could take the chunk, may be retried.
+	retryAfter = 5 * time.Second
+)
+
 func New(o Options) *PushSync {
+	if o.Accounting == nil {
+		o.Accounting = accounting.NewNoop()
+	}
+	if o.Pricer == nil {
+		o.Pricer = accounting.NewFixedPricer(o.Base, 0)
+	}
+	if o.Events == nil {
+		o.Events = NewEvents()
+	}
+	if o.PeerScores == nil {
+		o.PeerScores = NewPeerScores()
+	}
+
 	ps := &PushSync{
+		base:          o.Base,
+		signer:        o.Signer,
+		networkID:     o.NetworkID,
 		streamer:      o.Streamer,
 		storer:        o.Storer,
+		validator:     o.ChunkValidator,
 		peerSuggester: o.ClosestPeerer,
+		depther:       o.ReceiptDepther,
+		storageDepth:  o.StorageDepther,
+		neighbours:    o.ReplicationPeers,
+		replication:   o.ReplicationFactor,
+		lightNode:     o.LightNode,
 		tagg:          o.Tagger,
+		accounting:    o.Accounting,
+		pricer:        o.Pricer,
+		events:        o.Events,
+		peerScores:    o.PeerScores,
 		logger:        o.Logger,
+		tracer:        o.Tracer,
+		slowReceipt:   slowlog.New(o.Logger, "pushsync receipt", o.SlowReceiptThreshold),
 		metrics:       newMetrics(),
+		inflight:      make(map[string]struct{}),
+		quit:          make(chan struct{}),
 	}
 	return ps
 }
 
+// Subscribe returns the channel on which the push progress events are
+// received. Returned function is safe to be called multiple times.
+func (ps *PushSync) Subscribe() (c <-chan Event, unsubscribe func()) {
+	return ps.events.Subscribe()
+}
+
 func (s *PushSync) Protocol() p2p.ProtocolSpec {
 	return p2p.ProtocolSpec{
 		Name:    protocolName,
@@ -74,6 +231,10 @@ func (s *PushSync) Protocol() p2p.ProtocolSpec {
 				Name:    streamName,
 				Handler: s.handler,
 			},
+			{
+				Name:    replicationStreamName,
+				Handler: s.replicationHandler,
+			},
 		},
 	}
 }
@@ -90,17 +251,27 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 		}
 	}()
 
+	span, _, ctx := ps.tracer.StartSpanFromContext(ctx, "pushsync-handler", nil)
+	defer span.Finish()
+
 	// Get the delivery
 	chunk, err := ps.getChunkDelivery(r)
 	if err != nil {
+		if errors.Is(err, storage.ErrInvalidChunk) {
+			return p2p.NewBlockPeerError(invalidChunkBlockDuration, fmt.Errorf("chunk delivery from peer %s: %w", p.Address.String(), err))
+		}
 		return fmt.Errorf("chunk delivery from peer %s: %w", p.Address.String(), err)
 	}
+	span.SetTag("address", chunk.Address().String())
 
 	// Select the closest peer to forward the chunk
-	peer, err := ps.peerSuggester.ClosestPeer(chunk.Address())
+	peer, err := ps.closestPeer(chunk.Address())
 	if err != nil {
 		// If i am the closest peer then store the chunk and send receipt
 		if errors.Is(err, topology.ErrWantSelf) {
+			if ps.lightNode {
+				return fmt.Errorf("chunk %s from peer %s: %w", chunk.Address(), p.Address.String(), ErrLightNode)
+			}
 
 			// Store the chunk in the local store
 			_, err := ps.storer.Put(ctx, storage.ModePutSync, chunk)
@@ -110,12 +281,21 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 			ps.metrics.TotalChunksStoredInDB.Inc()
 
 			// Send a receipt immediately once the storage of the chunk is successfully
-			receipt := &pb.Receipt{Address: chunk.Address().Bytes()}
+			receipt, err := ps.newReceipt(chunk.Address())
+			if err != nil {
+				return fmt.Errorf("receipt: %w", err)
+			}
 			err = ps.sendReceipt(w, receipt)
 			if err != nil {
 				return fmt.Errorf("send receipt to peer %s: %w", p.Address.String(), err)
 			}
-			return nil
+
+			ps.startReplication(chunk, p.Address)
+
+			return ps.accounting.Debit(p.Address, ps.pricer.Price(chunk.Address()))
+		}
+		if errors.Is(err, topology.ErrNotFound) {
+			return p2p.NewTemporaryError(retryAfter, fmt.Errorf("closest peer: %w", err))
 		}
 		return err
 	}
@@ -123,6 +303,9 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 	// This is a special situation in that the other peer thinks thats we are the closest node
 	// and we think that the sending peer
 	if p.Address.Equal(peer) {
+		if ps.lightNode {
+			return fmt.Errorf("chunk %s from peer %s: %w", chunk.Address(), p.Address.String(), ErrLightNode)
+		}
 
 		// Store the chunk in the local store
 		_, err := ps.storer.Put(ctx, storage.ModePutSync, chunk)
@@ -132,11 +315,31 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 		ps.metrics.TotalChunksStoredInDB.Inc()
 
 		// Send a receipt immediately once the storage of the chunk is successfully
-		receipt := &pb.Receipt{Address: chunk.Address().Bytes()}
-		return ps.sendReceipt(w, receipt)
+		receipt, err := ps.newReceipt(chunk.Address())
+		if err != nil {
+			return fmt.Errorf("receipt: %w", err)
+		}
+		if err := ps.sendReceipt(w, receipt); err != nil {
+			return fmt.Errorf("send receipt to peer %s: %w", p.Address.String(), err)
+		}
+		return ps.accounting.Debit(p.Address, ps.pricer.Price(chunk.Address()))
+	}
+
+	// Store the chunk within the neighborhood of this node before forwarding
+	if ps.storageDepth != nil && swarm.Proximity(ps.base.Bytes(), chunk.Address().Bytes()) >= ps.storageDepth.NeighborhoodDepth() {
+		if _, err := ps.storer.Put(ctx, storage.ModePutSync, chunk); err != nil {
+			return fmt.Errorf("chunk store: %w", err)
+		}
+		ps.metrics.TotalChunksStoredInDB.Inc()
 	}
 
 	// Forward chunk to closest peer
+	price := ps.pricer.PeerPrice(peer, chunk.Address())
+	if err := ps.accounting.Reserve(ctx, peer, price); err != nil {
+		return reserveError(peer, err)
+	}
+	defer ps.accounting.Release(peer, price)
+
 	streamer, err := ps.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
 	if err != nil {
 		return fmt.Errorf("new stream peer %s: %w", peer.String(), err)
@@ -155,7 +358,7 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 	}
 	receiptRTTTimer := time.Now()
 
-	receipt, err := ps.receiveReceipt(rc)
+	receipt, err := ps.receiveReceipt(rc, peer, chunk.Address())
 	if err != nil {
 		return fmt.Errorf("receive receipt from peer %s: %w", peer.String(), err)
 	}
@@ -167,6 +370,11 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 		return fmt.Errorf("invalid receipt from peer %s", peer.String())
 	}
 
+	err = ps.accounting.Credit(peer, price)
+	if err != nil {
+		return err
+	}
+
 	// pass back the received receipt in the previously received stream
 	err = ps.sendReceipt(w, &receipt)
 	if err != nil {
@@ -174,9 +382,213 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 	}
 	ps.metrics.ReceiptsSentCounter.Inc()
 
+	return ps.accounting.Debit(p.Address, ps.pricer.Price(chunk.Address()))
+}
+
+// replicationHandler stores the chunk replicated by a neighbour and sends
+// back a receipt. Replicated chunks are never forwarded. Only the chunks
+// within the storage depth of this node that are replicated by the peers
+// within its neighborhood are accepted, so that the other peers can not
+// fill the reserve of this node.
+func (ps *PushSync) replicationHandler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	w, r := protobuf.NewWriterAndReader(stream)
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			_ = stream.FullClose()
+		}
+	}()
+
+	// only the chunks that this node is responsible for are stored
+	if ps.storageDepth == nil {
+		return fmt.Errorf("replicated chunk from peer %s: %w", p.Address.String(), ErrNotResponsible)
+	}
+	depth := ps.storageDepth.NeighborhoodDepth()
+	if po := swarm.Proximity(ps.base.Bytes(), p.Address.Bytes()); po < depth {
+		ps.metrics.ReplicationRejectedCounter.Inc()
+		return fmt.Errorf("replicated chunk from peer %s proximity %d, depth %d: %w", p.Address.String(), po, depth, ErrNotNeighbour)
+	}
+
+	chunk, err := ps.getChunkDelivery(r)
+	if err != nil {
+		if errors.Is(err, storage.ErrInvalidChunk) {
+			return p2p.NewBlockPeerError(invalidChunkBlockDuration, fmt.Errorf("replicated chunk delivery from peer %s: %w", p.Address.String(), err))
+		}
+		return fmt.Errorf("replicated chunk delivery from peer %s: %w", p.Address.String(), err)
+	}
+
+	if po := swarm.Proximity(ps.base.Bytes(), chunk.Address().Bytes()); po < depth {
+		ps.metrics.ReplicationRejectedCounter.Inc()
+		return fmt.Errorf("replicated chunk %s from peer %s proximity %d, depth %d: %w", chunk.Address(), p.Address.String(), po, depth, ErrNotResponsible)
+	}
+
+	if _, err := ps.storer.Put(ctx, storage.ModePutSync, chunk); err != nil {
+		return fmt.Errorf("chunk store: %w", err)
+	}
+	ps.metrics.TotalChunksStoredInDB.Inc()
+
+	receipt, err := ps.newReceipt(chunk.Address())
+	if err != nil {
+		return fmt.Errorf("receipt: %w", err)
+	}
+	if err := ps.sendReceipt(w, receipt); err != nil {
+		return fmt.Errorf("send receipt to peer %s: %w", p.Address.String(), err)
+	}
+	return ps.accounting.Debit(p.Address, ps.pricer.Price(chunk.Address()))
+}
+
+// startReplication replicates the chunk stored as the closest node in the
+// background, unless the replication is disabled or the service is closed.
+func (ps *PushSync) startReplication(ch swarm.Chunk, origin swarm.Address) {
+	if ps.replication <= 0 || ps.neighbours == nil || ps.storageDepth == nil {
+		return
+	}
+	select {
+	case <-ps.quit:
+		return
+	default:
+	}
+	ps.wg.Add(1)
+	go func() {
+		defer ps.wg.Done()
+		ps.replicate(ch, origin)
+	}()
+}
+
+// replicate sends the chunk to the neighbours closest to the chunk, except
+// the peer that the chunk was received from.
+func (ps *PushSync) replicate(ch swarm.Chunk, origin swarm.Address) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeToWaitForReceipt)
+	defer cancel()
+	go func() {
+		select {
+		case <-ps.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	peers, err := ps.replicationPeers(ch.Address(), origin)
+	if err != nil {
+		ps.metrics.ReplicationErrorCounter.Inc()
+		ps.logger.Debugf("pushsync: replication peers for chunk %s: %v", ch.Address(), err)
+		return
+	}
+
+	for _, peer := range peers {
+		if err := ps.replicateTo(ctx, peer, ch); err != nil {
+			ps.metrics.ReplicationErrorCounter.Inc()
+			ps.logger.Debugf("pushsync: replicate chunk %s to peer %s: %v", ch.Address(), peer, err)
+			continue
+		}
+		ps.metrics.ChunksReplicated.Inc()
+	}
+}
+
+// replicationPeers returns at most the replication factor number of
+// connected peers within the neighborhood depth of this node that are the
+// closest to the chunk address.
+func (ps *PushSync) replicationPeers(addr, origin swarm.Address) (peers []swarm.Address, err error) {
+	depth := ps.storageDepth.NeighborhoodDepth()
+	outOfDepth := func(peer swarm.Address) bool {
+		return swarm.Proximity(ps.base.Bytes(), peer.Bytes()) < depth
+	}
+	return topology.ClosestPeers(ps.neighbours, addr, ps.replication, topology.SkipPeers(origin), outOfDepth)
+}
+
+func (ps *PushSync) replicateTo(ctx context.Context, peer swarm.Address, ch swarm.Chunk) error {
+	price := ps.pricer.PeerPrice(peer, ch.Address())
+	if err := ps.accounting.Reserve(ctx, peer, price); err != nil {
+		return reserveError(peer, err)
+	}
+	defer ps.accounting.Release(peer, price)
+
+	streamer, err := ps.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, replicationStreamName)
+	if err != nil {
+		return fmt.Errorf("new stream: %w", err)
+	}
+	defer func() { go streamer.FullClose() }()
+
+	w, r := protobuf.NewWriterAndReader(streamer)
+	if err := ps.sendChunkDelivery(w, ch); err != nil {
+		_ = streamer.Reset()
+		return fmt.Errorf("send chunk: %w", err)
+	}
+
+	receipt, err := ps.receiveReceipt(r, peer, ch.Address())
+	if err != nil {
+		_ = streamer.Reset()
+		return fmt.Errorf("receive receipt: %w", err)
+	}
+	if !ch.Address().Equal(swarm.NewAddress(receipt.Address)) {
+		ps.metrics.InvalidReceiptReceived.Inc()
+		_ = streamer.Reset()
+		return errors.New("invalid receipt")
+	}
+	return ps.accounting.Credit(peer, price)
+}
+
+// Close stops the replications in progress and waits for them to return. It
+// is safe to be called multiple times.
+func (ps *PushSync) Close() error {
+	ps.closeOnce.Do(func() { close(ps.quit) })
+	cc := make(chan struct{})
+	go func() {
+		defer close(cc)
+		ps.wg.Wait()
+	}()
+	select {
+	case <-cc:
+	case <-time.After(10 * time.Second):
+		ps.logger.Warning("pushsync shutting down with running replications")
+	}
 	return nil
 }
 
+// closestPeer returns the closest peer to the chunk address that is not
+// unreliable as a push target, if the topology tells the reliable peers
+// apart, or the closest peer otherwise. The skipped peers are never
+// returned, topology.ErrNotFound is returned instead if the topology does
+// not leave them out.
+func (ps *PushSync) closestPeer(addr swarm.Address, skip ...swarm.Address) (swarm.Address, error) {
+	if rp, ok := ps.peerSuggester.(topology.ReliableClosestPeerer); ok {
+		return rp.ClosestReliablePeer(addr, ps.peerScores.unreliable, skip...)
+	}
+	peer, err := ps.peerSuggester.ClosestPeer(addr)
+	if err != nil {
+		return swarm.Address{}, err
+	}
+	if topology.SkipPeers(skip...)(peer) {
+		return swarm.Address{}, topology.ErrNotFound
+	}
+	return peer, nil
+}
+
+// pushFailed records the failed push to the peer.
+func (ps *PushSync) pushFailed(peer swarm.Address) {
+	ps.metrics.PeerSendErrors.WithLabelValues(peer.String()).Inc()
+	ps.peerScores.record(peer, false)
+}
+
+// pushSucceeded records the push to the peer that returned a valid receipt.
+func (ps *PushSync) pushSucceeded(peer swarm.Address, receiptRTT time.Duration) {
+	ps.metrics.PeerReceiptsReceived.WithLabelValues(peer.String()).Inc()
+	ps.metrics.PeerReceiptRTT.WithLabelValues(peer.String()).Observe(receiptRTT.Seconds())
+	ps.peerScores.record(peer, true)
+}
+
+// reserveError returns the error of the reservation of the price for the
+// peer, which is temporary if the debt to the peer is over the payment
+// threshold until it is settled.
+func reserveError(peer swarm.Address, err error) error {
+	err = fmt.Errorf("reserve balance for peer %s: %w", peer.String(), err)
+	if errors.Is(err, accounting.ErrOverdraft) {
+		return p2p.NewTemporaryError(retryAfter, err)
+	}
+	return err
+}
+
 func (ps *PushSync) getChunkDelivery(r protobuf.Reader) (chunk swarm.Chunk, err error) {
 	var ch pb.Delivery
 	if err = r.ReadMsg(&ch); err != nil {
@@ -188,6 +600,10 @@ func (ps *PushSync) getChunkDelivery(r protobuf.Reader) (chunk swarm.Chunk, err
 	// create chunk
 	addr := swarm.NewAddress(ch.Address)
 	chunk = swarm.NewChunk(addr, ch.Data)
+	if ps.validator != nil && !ps.validator.Validate(chunk) {
+		ps.metrics.InvalidChunkReceived.Inc()
+		return nil, storage.ErrInvalidChunk
+	}
 	return chunk, nil
 }
 
@@ -205,6 +621,50 @@ func (ps *PushSync) sendChunkDelivery(w protobuf.Writer, chunk swarm.Chunk) (err
 	return nil
 }
 
+// newReceipt returns the receipt of the chunk stored by this node, signed
+// if the node has a signer.
+func (ps *PushSync) newReceipt(addr swarm.Address) (*pb.Receipt, error) {
+	receipt := &pb.Receipt{Address: addr.Bytes(), Storer: ps.base.Bytes()}
+	if ps.signer == nil {
+		return receipt, nil
+	}
+	signature, err := ps.signer.Sign(receiptSignData(addr.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	receipt.Signature = signature
+	return receipt, nil
+}
+
+// receiptStorer returns the storer of the receipt if it is the overlay
+// address of the key that signed the receipt.
+func (ps *PushSync) receiptStorer(receipt *pb.Receipt) (swarm.Address, error) {
+	if len(receipt.Signature) == 0 {
+		return swarm.Address{}, errors.New("receipt not signed")
+	}
+	pk, err := crypto.Recover(receipt.Signature, receiptSignData(receipt.Address))
+	if err != nil {
+		return swarm.Address{}, fmt.Errorf("recover receipt signer: %w", err)
+	}
+	overlay, err := crypto.NewOverlayAddress(*pk, ps.networkID)
+	if err != nil {
+		return swarm.Address{}, err
+	}
+	if !overlay.Equal(swarm.NewAddress(receipt.Storer)) {
+		return swarm.Address{}, fmt.Errorf("receipt signed by %s", overlay)
+	}
+	return overlay, nil
+}
+
+// receiptSignData returns the data that the storer signs in the receipt of
+// the chunk with the address.
+func receiptSignData(addr []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	_, _ = h.Write([]byte(receiptSignPrefix))
+	_, _ = h.Write(addr)
+	return h.Sum(nil)
+}
+
 func (ps *PushSync) sendReceipt(w protobuf.Writer, receipt *pb.Receipt) (err error) {
 	if err := w.WriteMsg(receipt); err != nil {
 		ps.metrics.SendReceiptErrorCounter.Inc()
@@ -214,7 +674,8 @@ func (ps *PushSync) sendReceipt(w protobuf.Writer, receipt *pb.Receipt) (err err
 	return nil
 }
 
-func (ps *PushSync) receiveReceipt(r protobuf.Reader) (receipt pb.Receipt, err error) {
+func (ps *PushSync) receiveReceipt(r protobuf.Reader, peer, addr swarm.Address) (receipt pb.Receipt, err error) {
+	defer ps.slowReceipt.Observe(time.Now(), "receipt of chunk %s from peer %s", addr, peer)
 	if err := r.ReadMsg(&receipt); err != nil {
 		ps.metrics.ReceiveReceiptErrorCounter.Inc()
 		return receipt, err
@@ -225,53 +686,155 @@ func (ps *PushSync) receiveReceipt(r protobuf.Reader) (receipt pb.Receipt, err e
 
 // PushChunkToClosest sends chunk to the closest peer by opening a stream. It then waits for
 // a receipt from that peer and returns error or nil based on the receiving and
-// the validity of the receipt.
+// the validity of the receipt. If the same chunk is already being pushed,
+// ErrInflight is returned without sending the chunk again.
 func (ps *PushSync) PushChunkToClosest(ctx context.Context, ch swarm.Chunk) (*Receipt, error) {
-	peer, err := ps.peerSuggester.ClosestPeer(ch.Address())
+	key := ch.Address().ByteString()
+	ps.inflightMu.Lock()
+	if _, ok := ps.inflight[key]; ok {
+		ps.inflightMu.Unlock()
+		ps.metrics.DuplicatePushSuppressed.Inc()
+		return nil, ErrInflight
+	}
+	ps.inflight[key] = struct{}{}
+	ps.inflightMu.Unlock()
+	defer func() {
+		ps.inflightMu.Lock()
+		delete(ps.inflight, key)
+		ps.inflightMu.Unlock()
+	}()
+
+	span, _, ctx := ps.tracer.StartSpanFromContext(ctx, "pushsync-push", nil, opentracing.Tag{Key: "address", Value: ch.Address().String()})
+	defer span.Finish()
+
+	//  if you manage to get a tag, just increment the respective counter
+	t, err := ps.tagg.Get(ch.TagID())
 	if err != nil {
-		if errors.Is(err, topology.ErrWantSelf) {
-			// if you are the closest node return a receipt immediately
-			return &Receipt{
-				Address: ch.Address(),
-			}, nil
+		t = nil
+	}
+
+	// the peers that returned receipts of storers outside of the storage
+	// depth are skipped when the chunk is pushed again
+	var (
+		skip     []swarm.Address
+		depthErr error
+	)
+	for {
+		peer, err := ps.closestPeer(ch.Address(), skip...)
+		if err != nil {
+			if depthErr != nil {
+				// no other peer to push the chunk to
+				return nil, depthErr
+			}
+			if errors.Is(err, topology.ErrWantSelf) {
+				// if you are the closest node return a receipt immediately
+				return &Receipt{
+					Address: ch.Address(),
+					Storer:  ps.base,
+				}, nil
+			}
+			if errors.Is(err, topology.ErrNotFound) {
+				return nil, p2p.NewTemporaryError(retryAfter, fmt.Errorf("closest peer: %w", err))
+			}
+			return nil, fmt.Errorf("closest peer: %w", err)
 		}
-		return nil, fmt.Errorf("closest peer: %w", err)
+
+		receipt, err := ps.pushToPeer(ctx, peer, ch, t, len(skip) == 0)
+		if errors.Is(err, ErrOutOfDepthReceipt) && len(skip) < maxOutOfDepthRetries {
+			ps.logger.Debugf("pushsync: push chunk %s to the next closest peer: %v", ch.Address(), err)
+			skip = append(skip, peer)
+			depthErr = err
+			continue
+		}
+		return receipt, err
+	}
+}
+
+// pushToPeer sends the chunk to the peer and waits for its receipt. The tag
+// of the chunk, if it is not nil, counts the chunk as sent if first is true,
+// and as synced when the receipt is accepted.
+func (ps *PushSync) pushToPeer(ctx context.Context, peer swarm.Address, ch swarm.Chunk, t *tags.Tag, first bool) (*Receipt, error) {
+	// the price is reserved so that the concurrent pushes to the peer can
+	// not bring the debt to it over the payment threshold
+	price := ps.pricer.PeerPrice(peer, ch.Address())
+	if err := ps.accounting.Reserve(ctx, peer, price); err != nil {
+		return nil, reserveError(peer, err)
 	}
+	defer ps.accounting.Release(peer, price)
 
 	streamer, err := ps.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
 	if err != nil {
+		ps.pushFailed(peer)
 		return nil, fmt.Errorf("new stream for peer %s: %w", peer.String(), err)
 	}
 	defer func() { go streamer.FullClose() }()
 
 	w, r := protobuf.NewWriterAndReader(streamer)
 	if err := ps.sendChunkDelivery(w, ch); err != nil {
+		ps.pushFailed(peer)
 		_ = streamer.Reset()
 		return nil, fmt.Errorf("chunk deliver to peer %s: %w", peer.String(), err)
 	}
-	//  if you manage to get a tag, just increment the respective counter
-	t, err := ps.tagg.Get(ch.TagID())
-	if err == nil && t != nil {
+	ps.events.Publish(Event{Type: EventChunkSent, Address: ch.Address(), Peer: peer})
+
+	if t != nil && first {
 		t.Inc(tags.StateSent)
 	}
 
 	receiptRTTTimer := time.Now()
-	receipt, err := ps.receiveReceipt(r)
+	receipt, err := ps.receiveReceipt(r, peer, ch.Address())
 	if err != nil {
+		ps.pushFailed(peer)
 		_ = streamer.Reset()
 		return nil, fmt.Errorf("receive receipt from peer %s: %w", peer.String(), err)
 	}
-	ps.metrics.ReceiptRTT.Observe(time.Since(receiptRTTTimer).Seconds())
+	receiptRTT := time.Since(receiptRTTTimer)
+	ps.metrics.ReceiptRTT.Observe(receiptRTT.Seconds())
 
 	// Check if the receipt is valid
 	if !ch.Address().Equal(swarm.NewAddress(receipt.Address)) {
 		ps.metrics.InvalidReceiptReceived.Inc()
+		ps.pushFailed(peer)
 		_ = streamer.Reset()
 		return nil, fmt.Errorf("invalid receipt. peer %s", peer.String())
 	}
 
+	// Check if the receipt is signed off by a storer within the storage
+	// depth, which is not the peer if it forwarded the chunk
+	storer := swarm.NewAddress(receipt.Storer)
+	if ps.depther != nil {
+		var depthErr error
+		if signer, err := ps.receiptStorer(&receipt); err != nil {
+			depthErr = fmt.Errorf("receipt from peer %s with storer %s: %v: %w", peer.String(), storer.String(), err, ErrOutOfDepthReceipt)
+		} else if po, depth := swarm.Proximity(signer.Bytes(), ch.Address().Bytes()), ps.depther.NeighborhoodDepth(); po < depth {
+			depthErr = fmt.Errorf("receipt from peer %s with storer %s proximity %d, depth %d: %w", peer.String(), storer.String(), po, depth, ErrOutOfDepthReceipt)
+		}
+		if depthErr != nil {
+			ps.metrics.OutOfDepthReceiptReceived.Inc()
+			ps.pushFailed(peer)
+			// the peer is paid for the delivery that it was debited for,
+			// so that the balances of both sides stay the same
+			if err := ps.accounting.Credit(peer, price); err != nil {
+				return nil, err
+			}
+			return nil, depthErr
+		}
+	}
+	ps.pushSucceeded(peer, receiptRTT)
+
+	err = ps.accounting.Credit(peer, price)
+	if err != nil {
+		return nil, err
+	}
+
+	if t != nil {
+		t.Inc(tags.StateReceipt)
+	}
+	ps.events.Publish(Event{Type: EventReceiptReceived, Address: ch.Address(), Peer: peer})
+
 	rec := &Receipt{
 		Address: swarm.NewAddress(receipt.Address),
+		Storer:  storer,
 	}
 
 	return rec, nil
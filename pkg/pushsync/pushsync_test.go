@@ -7,15 +7,25 @@ package pushsync_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io/ioutil"
+	"math"
 	"testing"
+	"time"
 
-	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/accounting"
+	accountingmock "github.com/ethersphere/bee/pkg/accounting/mock"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/localstore/localstoretest"
 	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/pkg/p2p/streamtest"
 	"github.com/ethersphere/bee/pkg/pushsync"
 	"github.com/ethersphere/bee/pkg/pushsync/pb"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage/inmem"
+	"github.com/ethersphere/bee/pkg/storage/mock/validator"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
 	"github.com/ethersphere/bee/pkg/topology"
@@ -56,12 +66,22 @@ func TestSendChunkAndReceiveReceipt(t *testing.T) {
 		t.Fatal("invalid receipt")
 	}
 
+	if !closestPeer.Equal(receipt.Storer) {
+		t.Fatalf("got receipt storer %s, want %s", receipt.Storer, closestPeer)
+	}
+
 	// this intercepts the outgoing delivery message
 	waitOnRecordAndTest(t, closestPeer, recorder, chunkAddress, chunkData)
 
 	// this intercepts the incoming receipt message
 	waitOnRecordAndTest(t, closestPeer, recorder, chunkAddress, nil)
 
+	// the chunk is stored by the closest peer to be pull synced in its
+	// neighbourhood, and it is not push synced again
+	localstoretest.AssertStored(t, storerPeer, chunkAddress, true)
+	localstoretest.AssertPullIndex(t, storerPeer, chunkAddress, true)
+	localstoretest.AssertPushIndex(t, storerPeer, chunkAddress, false)
+	localstoretest.AssertStored(t, storerPivot, chunkAddress, false)
 }
 
 // PushChunkToClosest tests the sending of chunk to closest peer from the origination source perspective.
@@ -125,15 +145,64 @@ func TestPushChunkToClosest(t *testing.T) {
 	if ta2.Get(tags.StateSent) != 1 {
 		t.Fatalf("tags error")
 	}
+	if ta2.Get(tags.StateReceipt) != 1 {
+		t.Fatalf("got %d receipts in tag, want 1", ta2.Get(tags.StateReceipt))
+	}
 
 }
 
+// TestPushChunkToClosestTemporaryError checks that a push which fails as no
+// peer can take the chunk succeeds once the closest peer is connected.
+func TestPushChunkToClosestTemporaryError(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")   // base is 0000
+	closestPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000") // binary 0110 -> po 1
+
+	psPeer, storerPeer, _ := createPushSyncNode(t, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer storerPeer.Close()
+
+	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
+
+	pivotTopology := mock.NewTopologyDriver(mock.WithClosestPeerSequence(
+		mock.ClosestPeerAnswer{Err: topology.ErrNotFound},
+		mock.ClosestPeerAnswer{Peer: closestPeer},
+	))
+	psPivot := pushsync.New(pushsync.Options{
+		Base:          pivotNode,
+		Streamer:      recorder,
+		Storer:        inmem.New(pivotNode.Bytes(), inmem.Options{}),
+		ClosestPeerer: pivotTopology,
+		Tagger:        tags.NewTags(),
+		Logger:        logging.New(ioutil.Discard, 0),
+	})
+
+	_, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+	var terr *p2p.TemporaryError
+	if !errors.As(err, &terr) {
+		t.Fatalf("got error %v, want temporary error", err)
+	}
+
+	receipt, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closestPeer.Equal(receipt.Storer) {
+		t.Fatalf("got receipt storer %s, want %s", receipt.Storer, closestPeer)
+	}
+
+	calls := pivotTopology.ClosestPeerCalls()
+	if len(calls) != 2 || !calls[0].Equal(chunkAddress) || !calls[1].Equal(chunkAddress) {
+		t.Fatalf("got closest peer calls %v, want two for %s", calls, chunkAddress)
+	}
+}
+
 // TestHandler expect a chunk from a node on a stream. It then stores the chunk in the local store and
 // sends back a receipt. This is tested by intercepting the incoming stream for proper messages.
 // It also sends the chunk to the closest peerand receives a receipt.
 //
 // Chunk moves from   TriggerPeer -> PivotPeer -> ClosestPeer
-//
 func TestHandler(t *testing.T) {
 	// chunk data to upload
 	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
@@ -170,6 +239,11 @@ func TestHandler(t *testing.T) {
 		t.Fatal("invalid receipt")
 	}
 
+	// the receipt is forwarded by the pivot peer from the storer
+	if !closestPeer.Equal(receipt.Storer) {
+		t.Fatalf("got receipt storer %s, want %s", receipt.Storer, closestPeer)
+	}
+
 	// In pivot peer,  intercept the incoming delivery chunk from the trigger peer and check for correctness
 	waitOnRecordAndTest(t, pivotPeer, pivotRecorder, chunkAddress, chunkData)
 
@@ -184,28 +258,940 @@ func TestHandler(t *testing.T) {
 	waitOnRecordAndTest(t, pivotPeer, pivotRecorder, chunkAddress, nil)
 }
 
-func createPushSyncNode(t *testing.T, addr swarm.Address, recorder *streamtest.Recorder, mockOpts ...mock.Option) (*pushsync.PushSync, *localstore.DB, *tags.Tags) {
-	logger := logging.New(ioutil.Discard, 0)
+// TestReceiptDepthCheck checks that a receipt from a peer which is not within
+// the storage depth of the chunk is not accepted.
+func TestReceiptDepthCheck(t *testing.T) {
+	// create a pivot node and a mocked closest node
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000") // base is 0000
+	closestSigner, closestPeer := newTestSigner(t)
+
+	// chunk data to upload
+	chunkAddress := addressWithProximity(closestPeer, 3)
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	for _, tc := range []struct {
+		name    string
+		depth   uint8
+		wantErr error
+	}{
+		{
+			name:  "within depth",
+			depth: 3,
+		},
+		{
+			name:    "outside of depth",
+			depth:   4,
+			wantErr: pushsync.ErrOutOfDepthReceipt,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			psPeer, storerPeer, _ := createPushSyncNodeWithOptions(t, closestPeer, nil, pushsync.Options{Signer: closestSigner}, mock.WithClosestPeerErr(topology.ErrWantSelf))
+			defer storerPeer.Close()
+
+			recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
+
+			psPivot, storerPivot, _ := createPushSyncNodeWithOptions(t, pivotNode, recorder, pushsync.Options{
+				ReceiptDepther: mock.NewTopologyDriver(mock.WithNeighborhoodDepth(tc.depth)),
+			}, mock.WithClosestPeer(closestPeer))
+			defer storerPivot.Close()
+
+			_, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestReceiptDepthCheckForwarded checks that the depth check is done against
+// the storer of the forwarded chunk, and not against the first hop peer.
+func TestReceiptDepthCheckForwarded(t *testing.T) {
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+
+	storerSigner, storer := newTestSigner(t)
+	chunkAddress := addressWithProximity(storer, 4)
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	for _, tc := range []struct {
+		name      string
+		forwarder swarm.Address
+		depth     uint8
+		wantErr   error
+	}{
+		{
+			name:      "storer within depth",
+			forwarder: addressWithProximity(chunkAddress, 1),
+			depth:     4,
+		},
+		{
+			name:      "storer outside of depth",
+			forwarder: addressWithProximity(chunkAddress, 5),
+			depth:     5,
+			wantErr:   pushsync.ErrOutOfDepthReceipt,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			psStorer, storerDB, _ := createPushSyncNodeWithOptions(t, storer, nil, pushsync.Options{Signer: storerSigner}, mock.WithClosestPeerErr(topology.ErrWantSelf))
+			defer storerDB.Close()
+
+			storerRecorder := streamtest.New(streamtest.WithProtocols(psStorer.Protocol()))
+
+			psForwarder, forwarderDB, _ := createPushSyncNode(t, tc.forwarder, storerRecorder, mock.WithClosestPeer(storer))
+			defer forwarderDB.Close()
+
+			forwarderRecorder := streamtest.New(streamtest.WithProtocols(psForwarder.Protocol()))
+
+			psPivot, pivotDB, _ := createPushSyncNodeWithOptions(t, pivotNode, forwarderRecorder, pushsync.Options{
+				ReceiptDepther: mock.NewTopologyDriver(mock.WithNeighborhoodDepth(tc.depth)),
+			}, mock.WithClosestPeer(tc.forwarder))
+			defer pivotDB.Close()
+
+			_, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestReceiptSignature checks that the storer of a receipt is accepted only
+// if the receipt is signed with the key of the storer.
+func TestReceiptSignature(t *testing.T) {
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	storerSigner, storer := newTestSigner(t)
+	otherSigner, _ := newTestSigner(t)
+	chunk := swarm.NewChunk(addressWithProximity(storer, 8), []byte("1234"))
+
+	for _, tc := range []struct {
+		name    string
+		signer  crypto.Signer
+		wantErr error
+	}{
+		{
+			name:   "signed by the storer",
+			signer: storerSigner,
+		},
+		{
+			name:    "signed by another node",
+			signer:  otherSigner,
+			wantErr: pushsync.ErrOutOfDepthReceipt,
+		},
+		{
+			name:    "not signed",
+			wantErr: pushsync.ErrOutOfDepthReceipt,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			psStorer, storerDB, _ := createPushSyncNodeWithOptions(t, storer, nil, pushsync.Options{Signer: tc.signer}, mock.WithClosestPeerErr(topology.ErrWantSelf))
+			defer storerDB.Close()
+
+			recorder := streamtest.New(streamtest.WithProtocols(psStorer.Protocol()))
+
+			psPivot, pivotDB, _ := createPushSyncNodeWithOptions(t, pivotNode, recorder, pushsync.Options{
+				ReceiptDepther: mock.NewTopologyDriver(mock.WithNeighborhoodDepth(8)),
+			}, mock.WithClosestPeer(storer))
+			defer pivotDB.Close()
+
+			receipt, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !receipt.Storer.Equal(storer) {
+				t.Fatalf("got receipt storer %s, want %s", receipt.Storer, storer)
+			}
+		})
+	}
+}
+
+// TestOutOfDepthReceiptRetry checks that the chunk is pushed to the next
+// closest peer after a receipt of a storer outside of the storage depth, and
+// that the rejected peer is still paid for the delivery that it charged.
+func TestOutOfDepthReceiptRetry(t *testing.T) {
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	nearSigner, nearPeer := newTestSigner(t)
+	chunkAddress := addressWithProximity(nearPeer, 8)
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+	farSigner, farPeer := newTestSigner(t)
+	for swarm.Proximity(farPeer.Bytes(), chunkAddress.Bytes()) >= 8 {
+		farSigner, farPeer = newTestSigner(t)
+	}
+
+	farAccounting := accountingmock.NewAccounting()
+	psFar, farDB, _ := createPushSyncNodeWithOptions(t, farPeer, nil, pushsync.Options{Signer: farSigner, Accounting: farAccounting}, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer farDB.Close()
+	psNear, nearDB, _ := createPushSyncNodeWithOptions(t, nearPeer, nil, pushsync.Options{Signer: nearSigner}, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer nearDB.Close()
+
+	streamer := peerStreamer{
+		farPeer.ByteString():  streamtest.New(streamtest.WithProtocols(psFar.Protocol()), streamtest.WithBaseAddr(pivotNode)),
+		nearPeer.ByteString(): streamtest.New(streamtest.WithProtocols(psNear.Protocol()), streamtest.WithBaseAddr(pivotNode)),
+	}
+	pivotTopology := mock.NewTopologyDriver(mock.WithClosestPeerSequence(
+		mock.ClosestPeerAnswer{Peer: farPeer},
+		mock.ClosestPeerAnswer{Peer: nearPeer},
+	))
+	pivotAccounting := accountingmock.NewAccounting()
+	psPivot := pushsync.New(pushsync.Options{
+		Base:           pivotNode,
+		Streamer:       streamer,
+		Storer:         inmem.New(pivotNode.Bytes(), inmem.Options{}),
+		ClosestPeerer:  pivotTopology,
+		ReceiptDepther: mock.NewTopologyDriver(mock.WithNeighborhoodDepth(8)),
+		Tagger:         tags.NewTags(),
+		Accounting:     pivotAccounting,
+		Pricer:         accounting.NewFixedPricer(pivotNode, fixedPrice),
+		Logger:         logging.New(ioutil.Discard, 0),
+	})
+
+	receipt, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !receipt.Storer.Equal(nearPeer) {
+		t.Fatalf("got receipt storer %s, want %s", receipt.Storer, nearPeer)
+	}
+
+	farPrice := int64(accounting.NewFixedPricer(farPeer, fixedPrice).Price(chunkAddress))
+	if got, err := pivotAccounting.Balance(farPeer); err != nil || got != -farPrice {
+		t.Fatalf("got balance %d with rejected peer, error %v, want %d", got, err, -farPrice)
+	}
+	var got int64
+	for i := 0; i < 50; i++ {
+		if got, err = farAccounting.Balance(pivotNode); err != nil {
+			t.Fatal(err)
+		}
+		if got == farPrice {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got != farPrice {
+		t.Fatalf("got balance %d of rejected peer, want %d", got, farPrice)
+	}
+}
+
+// TestStorageDepth checks that the forwarded chunks are stored only if they
+// are within the neighborhood depth of the forwarding node.
+func TestStorageDepth(t *testing.T) {
+	// chunk data to upload
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunkData := []byte("1234")
+	chunk := swarm.NewChunk(chunkAddress, chunkData)
+
+	pivotPeer := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")   // base is 0000, po 1 to the chunk
+	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000") // binary 0110
+	closestPeer := swarm.MustParseHexAddress("7800000000000000000000000000000000000000000000000000000000000000") // binary 0111 1000
+
+	for _, tc := range []struct {
+		name       string
+		depth      uint8
+		wantStored bool
+	}{
+		{
+			name:       "within depth",
+			depth:      1,
+			wantStored: true,
+		},
+		{
+			name:  "outside of depth",
+			depth: 2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			psClosestPeer, closestStorerPeerDB, _ := createPushSyncNode(t, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+			defer closestStorerPeerDB.Close()
+
+			closestRecorder := streamtest.New(streamtest.WithProtocols(psClosestPeer.Protocol()))
+
+			psPivot, storerPivotDB, _ := createPushSyncNodeWithOptions(t, pivotPeer, closestRecorder, pushsync.Options{
+				StorageDepther: mock.NewTopologyDriver(mock.WithNeighborhoodDepth(tc.depth)),
+			}, mock.WithClosestPeer(closestPeer))
+			defer storerPivotDB.Close()
+
+			pivotRecorder := streamtest.New(streamtest.WithProtocols(psPivot.Protocol()))
+
+			psTriggerPeer, triggerStorerDB, _ := createPushSyncNode(t, triggerPeer, pivotRecorder, mock.WithClosestPeer(pivotPeer))
+			defer triggerStorerDB.Close()
+
+			receipt, err := psTriggerPeer.PushChunkToClosest(context.Background(), chunk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !closestPeer.Equal(receipt.Storer) {
+				t.Fatalf("got receipt storer %s, want %s", receipt.Storer, closestPeer)
+			}
+
+			has, err := storerPivotDB.Has(context.Background(), chunkAddress)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if has != tc.wantStored {
+				t.Fatalf("got chunk stored %v, want %v", has, tc.wantStored)
+			}
+		})
+	}
+}
+
+// TestInvalidChunk checks that a chunk that is not valid is neither stored
+// nor acknowledged with a receipt by the peer.
+func TestInvalidChunk(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")   // base is 0000
+	closestPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000") // binary 0110 -> po 1
+
+	// the validator accepts only other data for the chunk address
+	chunkValidator := validator.NewMockValidator(chunkAddress, []byte("5678"))
+	psPeer, storerPeer, _ := createPushSyncNodeWithOptions(t, closestPeer, nil, pushsync.Options{ChunkValidator: chunkValidator}, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer storerPeer.Close()
 
-	storer, err := localstore.New("", addr.Bytes(), nil, logger)
+	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
+
+	psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, recorder, mock.WithClosestPeer(closestPeer))
+	defer storerPivot.Close()
+
+	if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); err == nil {
+		t.Fatal("got no error pushing invalid chunk")
+	}
+
+	// the peer that delivers the invalid chunk is blocklisted
+	records, err := recorder.Records(closestPeer, pushsync.ProtocolName, pushsync.ProtocolVersion, pushsync.StreamName)
 	if err != nil {
 		t.Fatal(err)
 	}
+	var bpe *p2p.BlockPeerError
+	for deadline := time.Now().Add(5 * time.Second); !errors.As(records[0].Err(), &bpe); {
+		if time.Now().After(deadline) {
+			t.Fatalf("got handler error %v, want block peer error", records[0].Err())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	has, err := storerPeer.Has(context.Background(), chunkAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has {
+		t.Fatal("invalid chunk stored by peer")
+	}
+}
+
+// TestPushChunkToClosestReceiptError checks that the push fails if the
+// receipt can not be read, even if the peer stored the chunk.
+func TestPushChunkToClosestReceiptError(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")   // base is 0000
+	closestPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000") // binary 0110 -> po 1
+
+	psPeer, storerPeer, _ := createPushSyncNode(t, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer storerPeer.Close()
+
+	// the receipt is the first message of the peer
+	testErr := errors.New("test error")
+	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()), streamtest.WithReadError(testErr, 0))
+
+	psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, recorder, mock.WithClosestPeer(closestPeer))
+	defer storerPivot.Close()
+
+	if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); !errors.Is(err, testErr) {
+		t.Fatalf("got error %v, want %v", err, testErr)
+	}
+	localstoretest.AssertStored(t, storerPeer, chunkAddress, true)
+}
+
+// TestPushChunkToClosestInflight checks that a chunk which is already being
+// pushed is not sent again until the previous push is completed.
+func TestPushChunkToClosestInflight(t *testing.T) {
+	// chunk data to upload
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunkData := []byte("1234")
+	chunk := swarm.NewChunk(chunkAddress, chunkData)
+
+	// create a pivot node and a mocked closest node
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")   // base is 0000
+	closestPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000") // binary 0110 -> po 1
+
+	psPeer, storerPeer, _ := createPushSyncNode(t, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer storerPeer.Close()
+
+	// hold the handler of the closest peer until the duplicate push is made
+	release := make(chan struct{})
+	recorder := streamtest.New(
+		streamtest.WithProtocols(psPeer.Protocol()),
+		streamtest.WithMiddlewares(func(f p2p.HandlerFunc) p2p.HandlerFunc {
+			return func(ctx context.Context, p p2p.Peer, s p2p.Stream) error {
+				<-release
+				return f(ctx, p, s)
+			}
+		}),
+	)
+
+	psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, recorder, mock.WithClosestPeer(closestPeer))
+	defer storerPivot.Close()
+
+	errC := make(chan error, 1)
+	go func() {
+		_, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+		errC <- err
+	}()
+
+	// wait for the first push to open the stream
+	recorder.WaitRecords(t, closestPeer, pushsync.ProtocolName, pushsync.ProtocolVersion, pushsync.StreamName, 1, 5)
+
+	if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); !errors.Is(err, pushsync.ErrInflight) {
+		t.Fatalf("got error %v, want %v", err, pushsync.ErrInflight)
+	}
+
+	close(release)
+	if err := <-errC; err != nil {
+		t.Fatal(err)
+	}
+
+	// the chunk can be pushed again once the previous push is done
+	if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestEvents checks that the sent and receipt events are published when
+// a chunk is pushed.
+func TestEvents(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+
+	psPeer, storerPeer, _ := createPushSyncNode(t, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer storerPeer.Close()
+
+	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
+
+	psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, recorder, mock.WithClosestPeer(closestPeer))
+	defer storerPivot.Close()
+
+	c, unsubscribe := psPivot.Subscribe()
+	defer unsubscribe()
+
+	if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []pushsync.EventType{pushsync.EventChunkSent, pushsync.EventReceiptReceived} {
+		select {
+		case e := <-c:
+			if e.Type != want {
+				t.Fatalf("got event type %q, want %q", e.Type, want)
+			}
+			if !e.Address.Equal(chunkAddress) {
+				t.Fatalf("got event chunk %s, want %s", e.Address, chunkAddress)
+			}
+			if !e.Peer.Equal(closestPeer) {
+				t.Fatalf("got event peer %s, want %s", e.Peer, closestPeer)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %q event", want)
+		}
+	}
+}
+
+// TestAccounting checks that the peers are credited and debited for the
+// delivered chunks and the returned receipts along the forwarding path.
+func TestAccounting(t *testing.T) {
+	// chunk data to upload
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunkData := []byte("1234")
+	chunk := swarm.NewChunk(chunkAddress, chunkData)
+
+	pivotPeer := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("f000000000000000000000000000000000000000000000000000000000000000")
+
+	closestAccounting := accountingmock.NewAccounting()
+	psClosestPeer, closestStorerPeerDB, _ := createPushSyncNodeWithOptions(t, closestPeer, nil, pushsync.Options{Accounting: closestAccounting}, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer closestStorerPeerDB.Close()
+
+	closestRecorder := streamtest.New(streamtest.WithProtocols(psClosestPeer.Protocol()), streamtest.WithBaseAddr(pivotPeer))
+
+	pivotAccounting := accountingmock.NewAccounting()
+	psPivot, storerPivotDB, _ := createPushSyncNodeWithOptions(t, pivotPeer, closestRecorder, pushsync.Options{Accounting: pivotAccounting}, mock.WithClosestPeer(closestPeer))
+	defer storerPivotDB.Close()
+
+	pivotRecorder := streamtest.New(streamtest.WithProtocols(psPivot.Protocol()), streamtest.WithBaseAddr(triggerPeer))
+
+	triggerAccounting := accountingmock.NewAccounting()
+	psTriggerPeer, triggerStorerDB, _ := createPushSyncNodeWithOptions(t, triggerPeer, pivotRecorder, pushsync.Options{Accounting: triggerAccounting}, mock.WithClosestPeer(pivotPeer))
+	defer triggerStorerDB.Close()
+
+	if _, err := psTriggerPeer.PushChunkToClosest(context.Background(), chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	pivotPrice := int64(accounting.NewFixedPricer(pivotPeer, fixedPrice).Price(chunkAddress))
+	closestPrice := int64(accounting.NewFixedPricer(closestPeer, fixedPrice).Price(chunkAddress))
+
+	for _, tc := range []struct {
+		name       string
+		accounting *accountingmock.Accounting
+		peer       swarm.Address
+		want       int64
+	}{
+		{name: "trigger owes pivot", accounting: triggerAccounting, peer: pivotPeer, want: -pivotPrice},
+		{name: "pivot charges trigger", accounting: pivotAccounting, peer: triggerPeer, want: pivotPrice},
+		{name: "pivot owes closest", accounting: pivotAccounting, peer: closestPeer, want: -closestPrice},
+		{name: "closest charges pivot", accounting: closestAccounting, peer: pivotPeer, want: closestPrice},
+	} {
+		// debits happen in the handlers after the receipts are sent
+		var (
+			got int64
+			err error
+		)
+		for i := 0; i < 50; i++ {
+			got, err = tc.accounting.Balance(tc.peer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == tc.want {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got balance %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestAccountingOverdraft checks that the chunk is not pushed to the closest
+// peer if the price would bring the debt to it over the payment threshold.
+func TestAccountingOverdraft(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+
+	psPeer, storerPeer, _ := createPushSyncNode(t, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer storerPeer.Close()
+
+	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
+
+	price := accounting.NewFixedPricer(pivotNode, fixedPrice).PeerPrice(closestPeer, chunkAddress)
+	acc := accounting.New(accounting.Options{
+		Store:            statestore.NewStateStore(),
+		PaymentThreshold: price - 1,
+		Logger:           logging.New(ioutil.Discard, 0),
+	})
+	psPivot, storerPivot, _ := createPushSyncNodeWithOptions(t, pivotNode, recorder, pushsync.Options{Accounting: acc}, mock.WithClosestPeer(closestPeer))
+	defer storerPivot.Close()
+
+	_, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+	if !errors.Is(err, accounting.ErrOverdraft) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrOverdraft)
+	}
+	var te *p2p.TemporaryError
+	if !errors.As(err, &te) {
+		t.Fatalf("got error %v, want temporary error", err)
+	}
+	if records, _ := recorder.Records(closestPeer, pushsync.ProtocolName, pushsync.ProtocolVersion, pushsync.StreamName); len(records) != 0 {
+		t.Fatalf("got %d records, want none", len(records))
+	}
+}
+
+// TestReplication checks that the closest node replicates the stored chunk
+// to its closest neighbour over the replication stream, which does not
+// forward the chunk any further.
+func TestReplication(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunkData := []byte("1234")
+	chunk := swarm.NewChunk(chunkAddress, chunkData)
+
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("7100000000000000000000000000000000000000000000000000000000000000")
+	nearNeighbour := swarm.MustParseHexAddress("7300000000000000000000000000000000000000000000000000000000000000")
+	farNeighbour := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+
+	// the neighbour would forward pushed chunks to the pivot node
+	psNeighbour, storerNeighbour, _ := createPushSyncNodeWithOptions(t, nearNeighbour, nil, pushsync.Options{
+		StorageDepther: mock.NewTopologyDriver(mock.WithNeighborhoodDepth(2)),
+	}, mock.WithClosestPeer(pivotNode))
+	defer storerNeighbour.Close()
+
+	neighbourRecorder := streamtest.New(streamtest.WithProtocols(psNeighbour.Protocol()), streamtest.WithBaseAddr(closestPeer))
+
+	psClosest, storerClosest, _ := createPushSyncNodeWithOptions(t, closestPeer, neighbourRecorder, pushsync.Options{
+		ReplicationFactor: 2,
+		StorageDepther:    mock.NewTopologyDriver(mock.WithNeighborhoodDepth(2)),
+	}, mock.WithClosestPeerErr(topology.ErrWantSelf), mock.WithPeers(farNeighbour, pivotNode, nearNeighbour))
+	defer storerClosest.Close()
+	defer psClosest.Close()
+
+	recorder := streamtest.New(streamtest.WithProtocols(psClosest.Protocol()), streamtest.WithBaseAddr(pivotNode))
+
+	psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, recorder, mock.WithClosestPeer(closestPeer))
+	defer storerPivot.Close()
+
+	if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	records := neighbourRecorder.WaitRecords(t, nearNeighbour, "pushsync", pushsync.ProtocolVersion, "replication", 1, 5)
+	// the stream is recorded before the delivery is written to it
+	for i := 0; i < 50 && len(records[0].In()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	messages, err := protobuf.ReadMessages(
+		bytes.NewReader(records[0].In()),
+		func() protobuf.Message { return new(pb.Delivery) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("got %v delivery messages, want 1", len(messages))
+	}
+	if delivery := messages[0].(*pb.Delivery); !bytes.Equal(delivery.Address, chunkAddress.Bytes()) || !bytes.Equal(delivery.Data, chunkData) {
+		t.Fatalf("got delivery %+v, want chunk %s", delivery, chunkAddress)
+	}
+
+	// wait for the receipt from the neighbour, so that the chunk is stored
+	for i := 0; i < 50 && len(records[0].Out()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	has, err := storerNeighbour.Has(context.Background(), chunkAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !has {
+		t.Fatal("replicated chunk not stored by the neighbour")
+	}
+
+	// only the neighbour within the depth receives the chunk and it does
+	// not forward it
+	if _, err := neighbourRecorder.Records(farNeighbour, "pushsync", pushsync.ProtocolVersion, "replication"); err != streamtest.ErrRecordsNotFound {
+		t.Fatalf("got error %v, want %v", err, streamtest.ErrRecordsNotFound)
+	}
+	if _, err := neighbourRecorder.Records(pivotNode, "pushsync", pushsync.ProtocolVersion, "pushsync"); err != streamtest.ErrRecordsNotFound {
+		t.Fatalf("got error %v, want %v", err, streamtest.ErrRecordsNotFound)
+	}
+}
+
+// TestReplicationOutOfDepth checks that a neighbour does not store the
+// replicated chunks that are outside of its storage depth.
+func TestReplicationOutOfDepth(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("7200000000000000000000000000000000000000000000000000000000000000")
+	neighbour := swarm.MustParseHexAddress("7300000000000000000000000000000000000000000000000000000000000000")
+
+	// the chunk is at proximity 6 and the closest peer at proximity 7
+	// from the neighbour
+	psNeighbour, storerNeighbour, _ := createPushSyncNodeWithOptions(t, neighbour, nil, pushsync.Options{
+		StorageDepther: mock.NewTopologyDriver(mock.WithNeighborhoodDepth(7)),
+	})
+	defer storerNeighbour.Close()
+
+	neighbourRecorder := streamtest.New(streamtest.WithProtocols(psNeighbour.Protocol()), streamtest.WithBaseAddr(closestPeer))
+
+	psClosest, storerClosest, _ := createPushSyncNodeWithOptions(t, closestPeer, neighbourRecorder, pushsync.Options{
+		ReplicationFactor: 1,
+		StorageDepther:    mock.NewTopologyDriver(mock.WithNeighborhoodDepth(2)),
+	}, mock.WithClosestPeerErr(topology.ErrWantSelf), mock.WithPeers(neighbour))
+	defer storerClosest.Close()
+	defer psClosest.Close()
+
+	recorder := streamtest.New(streamtest.WithProtocols(psClosest.Protocol()), streamtest.WithBaseAddr(pivotNode))
+
+	psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, recorder, mock.WithClosestPeer(closestPeer))
+	defer storerPivot.Close()
+
+	if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	records := neighbourRecorder.WaitRecords(t, neighbour, "pushsync", pushsync.ProtocolVersion, "replication", 1, 5)
+	for i := 0; i < 50 && records[0].Err() == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := records[0].Err(); !errors.Is(err, pushsync.ErrNotResponsible) {
+		t.Fatalf("got error %v, want %v", err, pushsync.ErrNotResponsible)
+	}
+	if out := records[0].Out(); len(out) != 0 {
+		t.Fatalf("got receipt %x, want none", out)
+	}
+	has, err := storerNeighbour.Has(context.Background(), chunkAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has {
+		t.Fatal("replicated chunk outside of depth stored by the neighbour")
+	}
+}
+
+// TestReplicationFromNonNeighbour checks that a node does not store the
+// chunks replicated by the peers outside of its neighborhood.
+func TestReplicationFromNonNeighbour(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	sender := swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000")
+	neighbour := swarm.MustParseHexAddress("7300000000000000000000000000000000000000000000000000000000000000")
+
+	// the chunk is at proximity 6 and the sender at proximity 1 from the
+	// neighbour
+	psNeighbour, storerNeighbour, _ := createPushSyncNodeWithOptions(t, neighbour, nil, pushsync.Options{
+		StorageDepther: mock.NewTopologyDriver(mock.WithNeighborhoodDepth(2)),
+	})
+	defer storerNeighbour.Close()
+
+	neighbourRecorder := streamtest.New(streamtest.WithProtocols(psNeighbour.Protocol()), streamtest.WithBaseAddr(sender))
+
+	psSender, storerSender, _ := createPushSyncNodeWithOptions(t, sender, neighbourRecorder, pushsync.Options{
+		ReplicationFactor: 1,
+		StorageDepther:    mock.NewTopologyDriver(mock.WithNeighborhoodDepth(0)),
+	}, mock.WithClosestPeerErr(topology.ErrWantSelf), mock.WithPeers(neighbour))
+	defer storerSender.Close()
+
+	recorder := streamtest.New(streamtest.WithProtocols(psSender.Protocol()), streamtest.WithBaseAddr(pivotNode))
+
+	psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, recorder, mock.WithClosestPeer(sender))
+	defer storerPivot.Close()
+
+	if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	records := neighbourRecorder.WaitRecords(t, neighbour, "pushsync", pushsync.ProtocolVersion, "replication", 1, 5)
+	for i := 0; i < 50 && records[0].Err() == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := records[0].Err(); !errors.Is(err, pushsync.ErrNotNeighbour) {
+		t.Fatalf("got error %v, want %v", err, pushsync.ErrNotNeighbour)
+	}
+	has, err := storerNeighbour.Has(context.Background(), chunkAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has {
+		t.Fatal("chunk replicated by a peer outside of the neighborhood stored")
+	}
+
+	// the service may be closed more than once
+	for i := 0; i < 2; i++ {
+		if err := psSender.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestLightNodeHandler checks that a light node forwards the pushed chunks
+// without storing them and refuses the chunks that it would have to store.
+func TestLightNodeHandler(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	lightNode := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("7100000000000000000000000000000000000000000000000000000000000000")
+
+	t.Run("forward", func(t *testing.T) {
+		psClosest, storerClosest, _ := createPushSyncNode(t, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+		defer storerClosest.Close()
+		closestRecorder := streamtest.New(streamtest.WithProtocols(psClosest.Protocol()), streamtest.WithBaseAddr(lightNode))
+
+		psLight, storerLight, _ := createPushSyncNodeWithOptions(t, lightNode, closestRecorder, pushsync.Options{LightNode: true}, mock.WithClosestPeer(closestPeer))
+		defer storerLight.Close()
+		lightRecorder := streamtest.New(streamtest.WithProtocols(psLight.Protocol()), streamtest.WithBaseAddr(pivotNode))
+
+		psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, lightRecorder, mock.WithClosestPeer(lightNode))
+		defer storerPivot.Close()
+
+		receipt, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !receipt.Storer.Equal(closestPeer) {
+			t.Fatalf("got storer %s, want %s", receipt.Storer, closestPeer)
+		}
+		if has, err := storerLight.Has(context.Background(), chunkAddress); err != nil || has {
+			t.Fatalf("got chunk stored by the light node %v, error %v", has, err)
+		}
+	})
+
+	for _, tc := range []struct {
+		name string
+		opt  mock.Option
+	}{
+		{name: "closest", opt: mock.WithClosestPeerErr(topology.ErrWantSelf)},
+		{name: "sender closest", opt: mock.WithClosestPeer(pivotNode)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			psLight, storerLight, _ := createPushSyncNodeWithOptions(t, lightNode, nil, pushsync.Options{LightNode: true}, tc.opt)
+			defer storerLight.Close()
+			lightRecorder := streamtest.New(streamtest.WithProtocols(psLight.Protocol()), streamtest.WithBaseAddr(pivotNode))
+
+			psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, lightRecorder, mock.WithClosestPeer(lightNode))
+			defer storerPivot.Close()
+
+			if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); err == nil {
+				t.Fatal("chunk pushed to the light node")
+			}
+			records := lightRecorder.WaitRecords(t, lightNode, "pushsync", pushsync.ProtocolVersion, "pushsync", 1, 5)
+			if err := records[0].Err(); !errors.Is(err, pushsync.ErrLightNode) {
+				t.Fatalf("got error %v, want %v", err, pushsync.ErrLightNode)
+			}
+			if has, err := storerLight.Has(context.Background(), chunkAddress); err != nil || has {
+				t.Fatalf("got chunk stored by the light node %v, error %v", has, err)
+			}
+		})
+	}
+}
+
+// TestPeerScores checks that the push outcomes are recorded in the peer
+// scores.
+func TestPeerScores(t *testing.T) {
+	chunk := swarm.NewChunk(swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), []byte("1234"))
+
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+
+	psPeer, storerPeer, _ := createPushSyncNode(t, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer storerPeer.Close()
+
+	for _, tc := range []struct {
+		name      string
+		recorder  *streamtest.Recorder
+		wantError bool
+		wantScore func(score float64) bool
+	}{
+		{
+			name:      "receipt",
+			recorder:  streamtest.New(streamtest.WithProtocols(psPeer.Protocol())),
+			wantScore: func(score float64) bool { return score == 1 },
+		},
+		{
+			name:      "failure",
+			recorder:  streamtest.New(),
+			wantError: true,
+			wantScore: func(score float64) bool { return score < 1 },
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			scores := pushsync.NewPeerScores()
+			psPivot, storerPivot, _ := createPushSyncNodeWithOptions(t, pivotNode, tc.recorder, pushsync.Options{PeerScores: scores}, mock.WithClosestPeer(closestPeer))
+			defer storerPivot.Close()
+
+			_, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+			if gotError := err != nil; gotError != tc.wantError {
+				t.Fatalf("got error %v, want error %v", err, tc.wantError)
+			}
+
+			if score := scores.PeerScore(closestPeer); !tc.wantScore(score) {
+				t.Fatalf("unexpected score %v", score)
+			}
+		})
+	}
+}
+
+// TestPeerScoresRecovery checks that the peer scores recover over time and
+// that they are removed when the peers disconnect.
+func TestPeerScoresRecovery(t *testing.T) {
+	now := time.Unix(1000, 0)
+	pushsync.SetTimeNow(func() time.Time { return now })
+	defer pushsync.SetTimeNow(time.Now)
+
+	peer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+	scores := pushsync.NewPeerScores()
+	for i := 0; i < 4; i++ {
+		scores.Record(peer, false)
+	}
+	failed := scores.PeerScore(peer)
+	if failed >= 0.5 {
+		t.Fatalf("got score %v after failures, want less than 0.5", failed)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if got, want := scores.PeerScore(peer), 1-(1-failed)/2; math.Abs(got-want) > 1e-9 {
+		t.Fatalf("got score %v after a half life, want %v", got, want)
+	}
+
+	scores.Record(peer, false)
+	scores.Disconnected(peer)
+	if got := scores.PeerScore(peer); got != 1 {
+		t.Fatalf("got score %v after disconnect, want 1", got)
+	}
+}
+
+const fixedPrice = 10
+
+func createPushSyncNode(t *testing.T, addr swarm.Address, recorder *streamtest.Recorder, mockOpts ...mock.Option) (*pushsync.PushSync, *inmem.Store, *tags.Tags) {
+	return createPushSyncNodeWithOptions(t, addr, recorder, pushsync.Options{}, mockOpts...)
+}
+
+// createPushSyncNodeWithOptions creates a push sync node with the services
+// required by every test, and the other options taken from o.
+func createPushSyncNodeWithOptions(t *testing.T, addr swarm.Address, recorder *streamtest.Recorder, o pushsync.Options, mockOpts ...mock.Option) (*pushsync.PushSync, *inmem.Store, *tags.Tags) {
+	logger := logging.New(ioutil.Discard, 0)
+
+	storer := inmem.New(addr.Bytes(), inmem.Options{})
 
 	mockTopology := mock.NewTopologyDriver(mockOpts...)
 	mtag := tags.NewTags()
 
-	ps := pushsync.New(pushsync.Options{
-		Streamer:      recorder,
-		Storer:        storer,
-		Tagger:        mtag,
-		ClosestPeerer: mockTopology,
-		Logger:        logger,
-	})
+	o.Base = addr
+	o.Streamer = recorder
+	o.Storer = storer
+	o.Tagger = mtag
+	o.ClosestPeerer = mockTopology
+	o.Pricer = accounting.NewFixedPricer(addr, fixedPrice)
+	o.Logger = logger
+	if o.ReplicationFactor > 0 {
+		o.ReplicationPeers = mockTopology
+	}
+	ps := pushsync.New(o)
 
 	return ps, storer, mtag
 }
 
+// newTestSigner returns a new signer and the overlay address of its key in
+// the network of the tests.
+func newTestSigner(t *testing.T) (crypto.Signer, swarm.Address) {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := crypto.NewOverlayAddress(key.PublicKey, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.NewDefaultSigner(key), overlay
+}
+
+// addressWithProximity returns the address that has the proximity order po
+// to the addr.
+func addressWithProximity(addr swarm.Address, po int) swarm.Address {
+	b := make([]byte, len(addr.Bytes()))
+	copy(b, addr.Bytes())
+	b[po/8] ^= 0x80 >> uint(po%8)
+	return swarm.NewAddress(b)
+}
+
+// peerStreamer is the p2p.Streamer that opens the streams to each peer with
+// the streamer of the peer.
+type peerStreamer map[string]p2p.Streamer
+
+func (s peerStreamer) NewStream(ctx context.Context, addr swarm.Address, h p2p.Headers, protocol, version, stream string) (p2p.Stream, error) {
+	return s[addr.ByteString()].NewStream(ctx, addr, h, protocol, version, stream)
+}
+
 func waitOnRecordAndTest(t *testing.T, peer swarm.Address, recorder *streamtest.Recorder, add swarm.Address, data []byte) {
 	t.Helper()
 	records := recorder.WaitRecords(t, peer, pushsync.ProtocolName, pushsync.ProtocolVersion, pushsync.StreamName, 1, 5)
@@ -0,0 +1,135 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package receipts provides persistence of push sync receipts
+// received for locally uploaded chunks.
+package receipts
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	keyPrefix = "receipt_"
+
+	// TTL is the time after which a receipt expires and is removed, as the
+	// storer may no longer keep the chunk.
+	TTL = 7 * 24 * time.Hour
+	// pruneInterval is the minimal time between the removals of the
+	// expired receipts.
+	pruneInterval = time.Hour
+)
+
+// timeNow is used to deterministically mock time.Now() in tests.
+var timeNow = time.Now
+
+var _ Interface = (*store)(nil)
+
+var ErrNotFound = errors.New("receipts: not found")
+
+type Interface interface {
+	Getter
+	Putter
+}
+
+type Getter interface {
+	// Get returns the receipt of the chunk, or ErrNotFound if it is
+	// expired.
+	Get(addr swarm.Address) (receipt *pushsync.Receipt, err error)
+}
+
+type Putter interface {
+	// Put stores the receipt. The expired receipts are removed at most
+	// once in the prune interval.
+	Put(receipt pushsync.Receipt) (err error)
+}
+
+// record is the stored receipt with the time when it was received.
+type record struct {
+	pushsync.Receipt
+	Received time.Time `json:"received"`
+}
+
+func (r *record) expired(now time.Time) bool {
+	return now.Sub(r.Received) >= TTL
+}
+
+type store struct {
+	store    storage.StateStorer
+	pruned   time.Time // time of the last removal of the expired receipts
+	prunedMu sync.Mutex
+}
+
+func New(storer storage.StateStorer) Interface {
+	return &store{
+		store: storer,
+	}
+}
+
+func (s *store) Get(addr swarm.Address) (*pushsync.Receipt, error) {
+	v := &record{}
+	err := s.store.Get(keyPrefix+addr.String(), v)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+	if v.expired(timeNow()) {
+		return nil, ErrNotFound
+	}
+	return &v.Receipt, nil
+}
+
+func (s *store) Put(receipt pushsync.Receipt) (err error) {
+	if err := s.prune(); err != nil {
+		return err
+	}
+	return s.store.Put(keyPrefix+receipt.Address.String(), &record{
+		Receipt:  receipt,
+		Received: timeNow().UTC(),
+	})
+}
+
+// prune removes the expired receipts if they were not removed within the
+// prune interval.
+func (s *store) prune() error {
+	s.prunedMu.Lock()
+	defer s.prunedMu.Unlock()
+
+	now := timeNow()
+	if now.Sub(s.pruned) < pruneInterval {
+		return nil
+	}
+
+	var expired []string
+	err := s.store.Iterate(keyPrefix, func(key, value []byte) (stop bool, err error) {
+		var r record
+		if err := json.Unmarshal(value, &r); err != nil {
+			return true, err
+		}
+		if r.expired(now) {
+			expired = append(expired, string(key))
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range expired {
+		if err := s.store.Delete(key); err != nil {
+			return err
+		}
+	}
+	s.pruned = now
+	return nil
+}
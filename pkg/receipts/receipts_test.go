@@ -0,0 +1,85 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package receipts_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/receipts"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestReceipts(t *testing.T) {
+	store := receipts.New(mock.NewStateStore())
+
+	addr := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	storer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+
+	if _, err := store.Get(addr); !errors.Is(err, receipts.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, receipts.ErrNotFound)
+	}
+
+	if err := store.Put(pushsync.Receipt{Address: addr, Storer: storer}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := store.Get(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Address.Equal(addr) {
+		t.Fatalf("got address %s, want %s", r.Address, addr)
+	}
+	if !r.Storer.Equal(storer) {
+		t.Fatalf("got storer %s, want %s", r.Storer, storer)
+	}
+}
+
+func TestReceiptsExpiry(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	receipts.SetTimeNow(func() time.Time { return now })
+	defer receipts.SetTimeNow(time.Now)
+
+	stateStore := mock.NewStateStore()
+	store := receipts.New(stateStore)
+
+	old := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	recent := swarm.MustParseHexAddress("7100000000000000000000000000000000000000000000000000000000000000")
+
+	if err := store.Put(pushsync.Receipt{Address: old}); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(receipts.TTL / 2)
+	if err := store.Put(pushsync.Receipt{Address: recent}); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(receipts.TTL / 2)
+	if _, err := store.Get(old); !errors.Is(err, receipts.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, receipts.ErrNotFound)
+	}
+	if _, err := store.Get(recent); err != nil {
+		t.Fatal(err)
+	}
+
+	// the expired receipts are removed with the next put
+	if err := store.Put(pushsync.Receipt{Address: swarm.MustParseHexAddress("7200000000000000000000000000000000000000000000000000000000000000")}); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := stateStore.Iterate("receipt_", func(_, _ []byte) (bool, error) {
+		count++
+		return false, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("got %d stored receipts, want 2", count)
+	}
+}
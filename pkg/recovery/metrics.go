@@ -0,0 +1,62 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package recovery
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	// all metrics fields must be exported
+	// to be able to return them by Metrics()
+	// using reflection
+	RequestsSentCounter     prometheus.Counter
+	SendRequestErrorCounter prometheus.Counter
+	RequestsReceivedCounter prometheus.Counter
+	ChunksRepushedCounter   prometheus.Counter
+	HopLimitReachedCounter  prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "recovery"
+
+	return metrics{
+		RequestsSentCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "requests_sent",
+			Help:      "Number of recovery requests sent to peers.",
+		}),
+		SendRequestErrorCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "send_request_error",
+			Help:      "Number of recovery requests that could not be sent.",
+		}),
+		RequestsReceivedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "requests_received",
+			Help:      "Number of recovery requests received from peers.",
+		}),
+		ChunksRepushedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "chunks_repushed",
+			Help:      "Number of pinned chunks pushed again to recover them.",
+		}),
+		HopLimitReachedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "hop_limit_reached",
+			Help:      "Number of recovery requests that were not forwarded as they reached the hop limit.",
+		}),
+	}
+}
+
+func (s *Service) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
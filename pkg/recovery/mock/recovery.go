@@ -0,0 +1,23 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"context"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+type Recovery struct {
+	recoverChunk func(ctx context.Context, addr swarm.Address, targets [][]byte) error
+}
+
+func New(recoverChunk func(ctx context.Context, addr swarm.Address, targets [][]byte) error) *Recovery {
+	return &Recovery{recoverChunk: recoverChunk}
+}
+
+func (r *Recovery) RecoverChunk(ctx context.Context, addr swarm.Address, targets [][]byte) error {
+	return r.recoverChunk(ctx, addr, targets)
+}
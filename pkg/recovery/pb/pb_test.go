@@ -0,0 +1,29 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p/protobuf/protobuftest"
+	"github.com/ethersphere/bee/pkg/recovery/pb"
+)
+
+// TestMessages checks that the wire format of the messages is compatible
+// with the released version of the protocol.
+func TestMessages(t *testing.T) {
+	protobuftest.Check(t,
+		protobuftest.Message{
+			Message: &pb.Request{Addr: []byte{1, 2, 3}, Target: []byte{4}, Hops: 2, Neighbourhood: true},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Addr", WireType: "bytes"},
+				{Number: 2, Name: "Target", WireType: "bytes"},
+				{Number: 3, Name: "Hops", WireType: "varint"},
+				{Number: 4, Name: "Neighbourhood", WireType: "varint"},
+			},
+			Wire: "0a0301020312010418022001",
+		},
+	)
+}
@@ -0,0 +1,329 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package recovery provides the repair of chunks that can not be retrieved
+from the network.
+
+A node that fails to retrieve a chunk sends recovery requests towards the
+targets, the address prefixes of the neighbourhoods of the nodes that pinned
+the content, usually of its uploader. A request is forwarded to the closest
+peer to the target until it reaches the node that has no closer peer, which
+delivers it to all of its peers with addresses that have the target prefix.
+Every node that has the chunk pinned pushes it to the network again, so
+that it can be retrieved after a while.
+*/
+package recovery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/clock"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/protobuf"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/recovery/pb"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology"
+)
+
+const (
+	protocolName    = "recovery"
+	protocolVersion = "1.0.0"
+	streamName      = "recovery"
+)
+
+// MaxTargets is the maximal number of targets that the recovery of a chunk
+// is requested from.
+const MaxTargets = 4
+
+var (
+	// ErrInvalidTarget is returned if the recovery target is empty or longer
+	// than an address.
+	ErrInvalidTarget = errors.New("invalid recovery target")
+	// ErrHopLimitReached is returned if the request was forwarded too many
+	// times to be forwarded again.
+	ErrHopLimitReached = errors.New("hop limit reached")
+	// ErrTooManyTargets is returned if the recovery is requested from more
+	// than MaxTargets targets.
+	ErrTooManyTargets = errors.New("too many recovery targets")
+	// ErrTooManyRequests is returned if the request is not handled as too
+	// many other requests were handled recently.
+	ErrTooManyRequests = errors.New("too many recovery requests")
+
+	maxHops        uint32 = 16               // maximal number of times a request is forwarded
+	requestTimeout        = 10 * time.Second // time to send a request to a peer
+	handledWindow         = time.Minute      // time in which a request for the same chunk and target is handled once
+	maxHandled            = 1024             // maximal number of requests handled within the window
+)
+
+type Interface interface {
+	RecoverChunk(ctx context.Context, addr swarm.Address, targets [][]byte) error
+}
+
+// PeerSuggester suggests the peers that recovery requests are sent to.
+type PeerSuggester interface {
+	topology.ClosestPeerer
+	topology.EachPeerer
+}
+
+type Service struct {
+	streamer      p2p.Streamer
+	peerSuggester PeerSuggester
+	storer        storage.Storer
+	pushSyncer    pushsync.PushSyncer
+	metrics       metrics
+	logger        logging.Logger
+	clock         clock.Clock
+
+	handled   map[string]time.Time // requests handled within the window by chunk and target
+	handledMu sync.Mutex
+}
+
+type Options struct {
+	Streamer      p2p.Streamer
+	PeerSuggester PeerSuggester
+	Storer        storage.Storer
+	PushSyncer    pushsync.PushSyncer
+	Logger        logging.Logger
+	// Clock times the handled requests, the system clock if it is not set.
+	Clock clock.Clock
+}
+
+func New(o Options) *Service {
+	if o.Clock == nil {
+		o.Clock = clock.System
+	}
+	return &Service{
+		streamer:      o.Streamer,
+		peerSuggester: o.PeerSuggester,
+		storer:        o.Storer,
+		pushSyncer:    o.PushSyncer,
+		metrics:       newMetrics(),
+		logger:        o.Logger,
+		clock:         o.Clock,
+		handled:       make(map[string]time.Time),
+	}
+}
+
+func (s *Service) Protocol() p2p.ProtocolSpec {
+	return p2p.ProtocolSpec{
+		Name:    protocolName,
+		Version: protocolVersion,
+		StreamSpecs: []p2p.StreamSpec{
+			{
+				Name:    streamName,
+				Handler: s.handler,
+			},
+		},
+	}
+}
+
+// RecoverChunk sends the request to recover the chunk towards every target.
+func (s *Service) RecoverChunk(ctx context.Context, addr swarm.Address, targets [][]byte) error {
+	if len(targets) > MaxTargets {
+		return ErrTooManyTargets
+	}
+	for _, target := range targets {
+		if err := validateTarget(target); err != nil {
+			return err
+		}
+	}
+	var errs []error
+	for _, target := range targets {
+		if err := s.route(ctx, &pb.Request{Addr: addr.Bytes(), Target: target}); err != nil {
+			s.logger.Debugf("recovery: chunk %s target %x: %v", addr, target, err)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == len(targets) && len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// route forwards the request to the closest peer to the target, or delivers
+// it to the peers in the target neighbourhood if this node is the closest.
+func (s *Service) route(ctx context.Context, req *pb.Request) error {
+	if req.Hops >= maxHops {
+		s.metrics.HopLimitReachedCounter.Inc()
+		return ErrHopLimitReached
+	}
+
+	peer, err := s.peerSuggester.ClosestPeer(targetAddress(req.Target))
+	if err == nil {
+		return s.send(ctx, peer, &pb.Request{
+			Addr:   req.Addr,
+			Target: req.Target,
+			Hops:   req.Hops + 1,
+		})
+	}
+	if !errors.Is(err, topology.ErrWantSelf) {
+		return fmt.Errorf("closest peer: %w", err)
+	}
+
+	var sent int
+	err = s.peerSuggester.EachPeer(func(peer swarm.Address, _ uint8) (stop, jumpToNext bool, err error) {
+		if !bytes.HasPrefix(peer.Bytes(), req.Target) {
+			return false, false, nil
+		}
+		if err := s.send(ctx, peer, &pb.Request{
+			Addr:          req.Addr,
+			Target:        req.Target,
+			Hops:          req.Hops + 1,
+			Neighbourhood: true,
+		}); err != nil {
+			s.logger.Debugf("recovery: send to neighbour %s: %v", peer, err)
+			return false, false, nil
+		}
+		sent++
+		return false, false, nil
+	})
+	if err != nil {
+		return err
+	}
+	s.logger.Tracef("recovery: chunk %x delivered to %d peers in target neighbourhood %x", req.Addr, sent, req.Target)
+	return nil
+}
+
+func (s *Service) send(ctx context.Context, peer swarm.Address, req *pb.Request) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
+	if err != nil {
+		return fmt.Errorf("new stream: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	w := protobuf.NewWriter(stream)
+	if err := w.WriteMsgWithContext(ctx, req); err != nil {
+		s.metrics.SendRequestErrorCounter.Inc()
+		return fmt.Errorf("write request: %w peer %s", err, peer)
+	}
+	s.metrics.RequestsSentCounter.Inc()
+	return nil
+}
+
+// handler handles a recovery request. The chunk is pushed again if it is
+// pinned by this node, otherwise the request is routed towards the target.
+func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	r := protobuf.NewReader(stream)
+	var req pb.Request
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		return fmt.Errorf("read request: %w peer %s", err, p.Address)
+	}
+	s.metrics.RequestsReceivedCounter.Inc()
+	if err := validateTarget(req.Target); err != nil {
+		return fmt.Errorf("%w peer %s", err, p.Address)
+	}
+
+	addr := swarm.NewAddress(req.Addr)
+	handle, err := s.handle(req.Addr, req.Target)
+	if err != nil {
+		return fmt.Errorf("%w peer %s", err, p.Address)
+	}
+	if !handle {
+		return nil
+	}
+
+	repushed, err := s.repush(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("repush chunk %s: %w", addr, err)
+	}
+	if repushed || req.Neighbourhood {
+		return nil
+	}
+
+	if err := s.route(ctx, &req); err != nil {
+		return fmt.Errorf("route chunk %s: %w", addr, err)
+	}
+	return nil
+}
+
+// handle returns true if the request for the recovery of the chunk towards
+// the target was not handled within the window, and marks it as handled.
+// Requests are refused if too many others were handled within the window,
+// so that the peers can not make the node repush and forward without limit.
+func (s *Service) handle(addr, target []byte) (bool, error) {
+	s.handledMu.Lock()
+	defer s.handledMu.Unlock()
+
+	now := s.clock.Now()
+	key := string(addr) + string(target)
+	if t, ok := s.handled[key]; ok && now.Sub(t) < handledWindow {
+		return false, nil
+	}
+	if len(s.handled) >= maxHandled {
+		for k, t := range s.handled {
+			if now.Sub(t) >= handledWindow {
+				delete(s.handled, k)
+			}
+		}
+		if len(s.handled) >= maxHandled {
+			return false, ErrTooManyRequests
+		}
+	}
+	s.handled[key] = now
+	return true, nil
+}
+
+// repush pushes the chunk to the network if it is pinned by this node.
+func (s *Service) repush(ctx context.Context, addr swarm.Address) (bool, error) {
+	if _, err := s.storer.PinInfo(addr); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	ch, err := s.storer.Get(ctx, storage.ModeGetLookup, addr)
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.pushSyncer.PushChunkToClosest(ctx, ch); err != nil {
+		if !errors.Is(err, topology.ErrWantSelf) {
+			return false, err
+		}
+	}
+	s.metrics.ChunksRepushedCounter.Inc()
+	s.logger.Tracef("recovery: pushed pinned chunk %s", addr)
+	return true, nil
+}
+
+func validateTarget(target []byte) error {
+	if len(target) == 0 || len(target) > swarm.HashSize {
+		return ErrInvalidTarget
+	}
+	return nil
+}
+
+// targetAddress returns the address with the target prefix that the request
+// is routed towards.
+func targetAddress(target []byte) swarm.Address {
+	addr := make([]byte, swarm.HashSize)
+	copy(addr, target)
+	return swarm.NewAddress(addr)
+}
@@ -0,0 +1,239 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package recovery_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	clockmock "github.com/ethersphere/bee/pkg/clock/mock"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/protobuf"
+	"github.com/ethersphere/bee/pkg/p2p/streamtest"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	pushsyncmock "github.com/ethersphere/bee/pkg/pushsync/mock"
+	"github.com/ethersphere/bee/pkg/recovery"
+	"github.com/ethersphere/bee/pkg/recovery/pb"
+	"github.com/ethersphere/bee/pkg/storage"
+	storemock "github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology"
+	topologymock "github.com/ethersphere/bee/pkg/topology/mock"
+)
+
+const (
+	protocolName    = "recovery"
+	protocolVersion = "1.0.0"
+	streamName      = "recovery"
+)
+
+var (
+	testTimeout = 5 * time.Second
+	chunkAddr   = swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunkData   = []byte("recovered data")
+)
+
+// TestRecoverChunk tests that the recovery request is sent to the closest
+// peer to the target, which pushes the chunk again if it has it pinned.
+func TestRecoverChunk(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+
+	pushed := make(chan swarm.Chunk, 1)
+	server := recovery.New(recovery.Options{
+		Storer: pinnedStorer(t),
+		PushSyncer: pushsyncmock.New(func(_ context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+			pushed <- ch
+			return &pushsync.Receipt{Address: ch.Address()}, nil
+		}),
+		Logger: logger,
+	})
+	recorder := streamtest.New(
+		streamtest.WithProtocols(server.Protocol()),
+		streamtest.WithMiddlewares(backgroundContext),
+	)
+
+	closest := swarm.MustParseHexAddress("a100000000000000000000000000000000000000000000000000000000000000")
+	client := recovery.New(recovery.Options{
+		Streamer:      recorder,
+		PeerSuggester: topologymock.NewTopologyDriver(topologymock.WithClosestPeer(closest)),
+		Logger:        logger,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if err := client.RecoverChunk(ctx, chunkAddr, [][]byte{{0xa1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ch := <-pushed:
+		if !ch.Address().Equal(chunkAddr) {
+			t.Fatalf("got pushed chunk %s, want %s", ch.Address(), chunkAddr)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("pinned chunk not pushed")
+	}
+
+	records := recorder.WaitRecords(t, closest, protocolName, protocolVersion, streamName, 1, 5)
+	req := readRequest(t, records[0])
+	if req.Hops != 1 {
+		t.Fatalf("got hops %d, want 1", req.Hops)
+	}
+	if req.Neighbourhood {
+		t.Fatal("forwarded request marked as neighbourhood request")
+	}
+}
+
+// TestRecoverChunkHandledWindow tests that the requests for the same chunk
+// and target are handled once within the window.
+func TestRecoverChunkHandledWindow(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	clock := clockmock.New(time.Unix(1600000000, 0))
+
+	pushed := make(chan swarm.Chunk, 3)
+	server := recovery.New(recovery.Options{
+		Storer: pinnedStorer(t),
+		PushSyncer: pushsyncmock.New(func(_ context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+			pushed <- ch
+			return &pushsync.Receipt{Address: ch.Address()}, nil
+		}),
+		Logger: logger,
+		Clock:  clock,
+	})
+	recorder := streamtest.New(
+		streamtest.WithProtocols(server.Protocol()),
+		streamtest.WithMiddlewares(backgroundContext),
+	)
+
+	closest := swarm.MustParseHexAddress("a100000000000000000000000000000000000000000000000000000000000000")
+	client := recovery.New(recovery.Options{
+		Streamer:      recorder,
+		PeerSuggester: topologymock.NewTopologyDriver(topologymock.WithClosestPeer(closest)),
+		Logger:        logger,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	for i, want := range []int{1, 1, 2} {
+		if i == 2 {
+			clock.Add(time.Minute)
+		}
+		if err := client.RecoverChunk(ctx, chunkAddr, [][]byte{{0xa1}}); err != nil {
+			t.Fatal(err)
+		}
+		recorder.WaitRecords(t, closest, protocolName, protocolVersion, streamName, i+1, 5)
+		// the handler returns before the recorded stream is closed
+		time.Sleep(50 * time.Millisecond)
+		if got := len(pushed); got != want {
+			t.Fatalf("request %d: got %d pushes, want %d", i, got, want)
+		}
+	}
+}
+
+// TestRecoverChunkNeighbourhood tests that the node closest to the target
+// delivers the request only to the peers in the target neighbourhood.
+func TestRecoverChunkNeighbourhood(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+
+	server := recovery.New(recovery.Options{
+		Storer: storemock.NewStorer(),
+		Logger: logger,
+	})
+	recorder := streamtest.New(
+		streamtest.WithProtocols(server.Protocol()),
+		streamtest.WithMiddlewares(backgroundContext),
+	)
+
+	neighbour := swarm.MustParseHexAddress("a1b0000000000000000000000000000000000000000000000000000000000000")
+	stranger := swarm.MustParseHexAddress("b100000000000000000000000000000000000000000000000000000000000000")
+	client := recovery.New(recovery.Options{
+		Streamer: recorder,
+		PeerSuggester: topologymock.NewTopologyDriver(
+			topologymock.WithClosestPeerErr(topology.ErrWantSelf),
+			topologymock.WithPeers(neighbour, stranger),
+		),
+		Logger: logger,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if err := client.RecoverChunk(ctx, chunkAddr, [][]byte{{0xa1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	records := recorder.WaitRecords(t, neighbour, protocolName, protocolVersion, streamName, 1, 5)
+	req := readRequest(t, records[0])
+	if !req.Neighbourhood {
+		t.Fatal("request to the target neighbourhood not marked")
+	}
+	if _, err := recorder.Records(stranger, protocolName, protocolVersion, streamName); !errors.Is(err, streamtest.ErrRecordsNotFound) {
+		t.Fatalf("got error %v, want %v", err, streamtest.ErrRecordsNotFound)
+	}
+}
+
+func TestRecoverChunkInvalidTarget(t *testing.T) {
+	client := recovery.New(recovery.Options{
+		Logger: logging.New(ioutil.Discard, 0),
+	})
+
+	for _, target := range [][]byte{{}, make([]byte, swarm.HashSize+1)} {
+		err := client.RecoverChunk(context.Background(), chunkAddr, [][]byte{target})
+		if !errors.Is(err, recovery.ErrInvalidTarget) {
+			t.Fatalf("got error %v, want %v", err, recovery.ErrInvalidTarget)
+		}
+	}
+
+	targets := make([][]byte, recovery.MaxTargets+1)
+	for i := range targets {
+		targets[i] = []byte{byte(i)}
+	}
+	err := client.RecoverChunk(context.Background(), chunkAddr, targets)
+	if !errors.Is(err, recovery.ErrTooManyTargets) {
+		t.Fatalf("got error %v, want %v", err, recovery.ErrTooManyTargets)
+	}
+}
+
+// pinnedStorer returns a storer with the test chunk pinned.
+func pinnedStorer(t *testing.T) storage.Storer {
+	t.Helper()
+
+	s := storemock.NewStorer()
+	if _, err := s.Put(context.Background(), storage.ModePutUpload, swarm.NewChunk(chunkAddr, chunkData)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(context.Background(), storage.ModeSetPin, chunkAddr); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// backgroundContext runs the handler with a context that is independent of
+// the stream opener, as the streams of a node do.
+func backgroundContext(h p2p.HandlerFunc) p2p.HandlerFunc {
+	return func(_ context.Context, p p2p.Peer, s p2p.Stream) error {
+		return h(context.Background(), p, s)
+	}
+}
+
+func readRequest(t *testing.T, record *streamtest.Record) *pb.Request {
+	t.Helper()
+
+	messages, err := protobuf.ReadMessages(
+		bytes.NewReader(record.In()),
+		func() protobuf.Message { return new(pb.Request) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("got %d messages, want 1", len(messages))
+	}
+	return messages[0].(*pb.Request)
+}
@@ -0,0 +1,281 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package reputation keeps persistent per-peer statistics of connection
+// attempt outcomes, so that historically reliable peers can be preferred
+// after a node restart. The statistics decay with time, so that a peer is
+// not penalised forever by its early failures.
+package reputation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/clock"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const keyPrefix = "reputation_entry_"
+
+var (
+	// defaultHalfLife is the time after which the weight of the recorded
+	// outcomes is halved.
+	defaultHalfLife = 24 * time.Hour
+	// defaultPersistInterval is the interval at which the changed records
+	// are written to the state store.
+	defaultPersistInterval = time.Minute
+)
+
+var _ Interface = (*store)(nil)
+
+var ErrNotFound = errors.New("reputation: not found")
+
+// Record holds the connection statistics of a single peer. The counts are
+// weighted by the age of the outcomes at the time of the last update.
+type Record struct {
+	Successes float64   `json:"successes"`
+	Failures  float64   `json:"failures"`
+	Updated   time.Time `json:"updated"`
+}
+
+// Score returns the estimated probability of a successful connection to the
+// peer at the time, with the weight of the outcomes halved every half life.
+// Peers without any history have a neutral score of 0.5.
+func (r Record) Score(now time.Time, halfLife time.Duration) float64 {
+	d := r.decayed(now, halfLife)
+	return (d.Successes + 1) / (d.Successes + d.Failures + 2)
+}
+
+// decayed returns the record with the counts weighted by their age at the
+// time. Records without the update time are not decayed.
+func (r Record) decayed(now time.Time, halfLife time.Duration) Record {
+	if r.Updated.IsZero() || halfLife <= 0 || !now.After(r.Updated) {
+		return r
+	}
+	f := math.Exp2(-float64(now.Sub(r.Updated)) / float64(halfLife))
+	return Record{
+		Successes: r.Successes * f,
+		Failures:  r.Failures * f,
+		Updated:   now,
+	}
+}
+
+type Interface interface {
+	Getter
+	Recorder
+	Sort(overlays []swarm.Address) error
+	Delete(overlay swarm.Address) error
+	io.Closer
+}
+
+type Getter interface {
+	Get(overlay swarm.Address) (r *Record, err error)
+}
+
+type Recorder interface {
+	Success(overlay swarm.Address) error
+	Failure(overlay swarm.Address) error
+}
+
+type Options struct {
+	// HalfLife is the time after which the weight of the recorded outcomes
+	// is halved. It defaults to a day.
+	HalfLife time.Duration
+	// PersistInterval is the interval at which the changed records are
+	// written to the state store. It defaults to a minute.
+	PersistInterval time.Duration
+	// Clock times the outcomes and the persistence, the system clock if it
+	// is not set.
+	Clock  clock.Clock
+	Logger logging.Logger
+}
+
+// store keeps the records in memory and writes the changed ones to the state
+// store periodically and when it is closed.
+type store struct {
+	store           storage.StateStorer
+	clock           clock.Clock
+	halfLife        time.Duration
+	persistInterval time.Duration
+	logger          logging.Logger
+	records         map[string]*Record  // records by overlay address
+	changed         map[string]struct{} // overlay addresses of the records that are not persisted
+	mu              sync.Mutex          // protects records and changed
+	quit            chan struct{}
+	done            chan struct{}
+	closeOnce       sync.Once
+}
+
+// New loads the records from the state store and starts persisting their
+// changes.
+func New(storer storage.StateStorer, o Options) (Interface, error) {
+	if o.HalfLife == 0 {
+		o.HalfLife = defaultHalfLife
+	}
+	if o.PersistInterval == 0 {
+		o.PersistInterval = defaultPersistInterval
+	}
+	if o.Clock == nil {
+		o.Clock = clock.System
+	}
+
+	s := &store{
+		store:           storer,
+		clock:           o.Clock,
+		halfLife:        o.HalfLife,
+		persistInterval: o.PersistInterval,
+		logger:          o.Logger,
+		records:         make(map[string]*Record),
+		changed:         make(map[string]struct{}),
+		quit:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+
+	err := storer.Iterate(keyPrefix, func(key, value []byte) (stop bool, err error) {
+		if !strings.HasPrefix(string(key), keyPrefix) {
+			return true, nil
+		}
+		var r Record
+		if err := json.Unmarshal(value, &r); err != nil {
+			return true, err
+		}
+		s.records[strings.TrimPrefix(string(key), keyPrefix)] = &r
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the timer is created before the loop is started, so that it is timed
+	// from the creation of the store
+	go s.persistLoop(s.clock.NewTimer(s.persistInterval))
+	return s, nil
+}
+
+// Get returns the record of the peer, with the counts as of its last update.
+func (s *store) Get(overlay swarm.Address) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[overlay.String()]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	v := *r
+	return &v, nil
+}
+
+// Success records a successful connection to the peer.
+func (s *store) Success(overlay swarm.Address) error {
+	s.update(overlay, func(r *Record) {
+		r.Successes++
+	})
+	return nil
+}
+
+// Failure records a failed connection attempt to the peer.
+func (s *store) Failure(overlay swarm.Address) error {
+	s.update(overlay, func(r *Record) {
+		r.Failures++
+	})
+	return nil
+}
+
+func (s *store) update(overlay swarm.Address, f func(r *Record)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := overlay.String()
+	now := s.clock.Now()
+	r := Record{Updated: now}
+	if v, ok := s.records[key]; ok {
+		r = v.decayed(now, s.halfLife)
+		r.Updated = now
+	}
+	f(&r)
+	s.records[key] = &r
+	s.changed[key] = struct{}{}
+}
+
+// Delete removes the recorded statistics of the peer, so that a peer that is
+// forgotten does not leave its record behind.
+func (s *store) Delete(overlay swarm.Address) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := overlay.String()
+	delete(s.records, key)
+	delete(s.changed, key)
+	return s.store.Delete(keyPrefix + key)
+}
+
+// Sort sorts overlays in place by their score, the most reliable peers first.
+// Peers without any recorded history are considered neutral.
+func (s *store) Sort(overlays []swarm.Address) error {
+	s.mu.Lock()
+	now := s.clock.Now()
+	scores := make(map[string]float64, len(overlays))
+	for _, o := range overlays {
+		var r Record
+		if v, ok := s.records[o.String()]; ok {
+			r = *v
+		}
+		scores[o.String()] = r.Score(now, s.halfLife)
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(overlays, func(i, j int) bool {
+		return scores[overlays[i].String()] > scores[overlays[j].String()]
+	})
+	return nil
+}
+
+func (s *store) persistLoop(timer clock.Timer) {
+	defer close(s.done)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C():
+			if err := s.persist(); err != nil {
+				s.logger.Debugf("reputation: persist records: %v", err)
+				s.logger.Error("reputation: failed to persist peer records")
+			}
+			timer.Reset(s.persistInterval)
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// persist writes the changed records to the state store. The records that
+// are not written are written again by the next call.
+func (s *store) persist() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.changed {
+		if err := s.store.Put(keyPrefix+key, s.records[key]); err != nil {
+			return err
+		}
+		delete(s.changed, key)
+	}
+	return nil
+}
+
+// Close stops the periodic persistence and writes the changed records.
+func (s *store) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+	})
+	<-s.done
+	return s.persist()
+}
@@ -0,0 +1,168 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reputation_test
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	clockmock "github.com/ethersphere/bee/pkg/clock/mock"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/reputation"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestReputation(t *testing.T) {
+	store := mock.NewStateStore()
+	rep := newReputation(t, store, reputation.Options{})
+
+	reliable := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	unreliable := swarm.MustParseHexAddress("8b2c1b1f7f2fa7ed1ea1d8e5f7fcfa0f13ecbd6ba7fc5c9ac1a4f3f3cc0de8d4")
+	unknown := swarm.MustParseHexAddress("1f8e2b7c3d9a4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8e9f")
+
+	if _, err := rep.Get(unknown); err != reputation.ErrNotFound {
+		t.Fatalf("got error %v, want %v", err, reputation.ErrNotFound)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := rep.Success(reliable); err != nil {
+			t.Fatal(err)
+		}
+		if err := rep.Failure(unreliable); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rep.Failure(reliable); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := rep.Get(reliable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Successes < 2.99 || r.Failures < 0.99 {
+		t.Fatalf("got %+v, want 3 successes and 1 failure", r)
+	}
+
+	// records must survive a new instance over the same state store
+	if err := rep.Close(); err != nil {
+		t.Fatal(err)
+	}
+	overlays := []swarm.Address{unreliable, unknown, reliable}
+	if err := newReputation(t, store, reputation.Options{}).Sort(overlays); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []swarm.Address{reliable, unknown, unreliable}
+	for i := range want {
+		if !overlays[i].Equal(want[i]) {
+			t.Fatalf("overlay %d: got %s, want %s", i, overlays[i], want[i])
+		}
+	}
+	if err := rep.Delete(reliable); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rep.Get(reliable); err != reputation.ErrNotFound {
+		t.Fatalf("got error %v, want %v", err, reputation.ErrNotFound)
+	}
+	if _, err := newReputation(t, store, reputation.Options{}).Get(reliable); err != reputation.ErrNotFound {
+		t.Fatalf("got error %v after reload, want %v", err, reputation.ErrNotFound)
+	}
+}
+
+// TestPersistInterval checks that the changed records are written to the
+// state store at the persist interval.
+func TestPersistInterval(t *testing.T) {
+	store := mock.NewStateStore()
+	clock := clockmock.New(time.Unix(1600000000, 0))
+	rep := newReputation(t, store, reputation.Options{Clock: clock, PersistInterval: time.Minute})
+
+	peer := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	if err := rep.Success(peer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newReputation(t, store, reputation.Options{}).Get(peer); err != reputation.ErrNotFound {
+		t.Fatalf("got error %v before the persist interval, want %v", err, reputation.ErrNotFound)
+	}
+
+	clock.Add(time.Minute)
+	var err error
+	for i := 0; i < 50; i++ {
+		if _, err = newReputation(t, store, reputation.Options{}).Get(peer); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("got error %v after the persist interval", err)
+	}
+}
+
+// TestDecay checks that the recorded outcomes lose their weight with time,
+// so that a peer that failed once recovers its score.
+func TestDecay(t *testing.T) {
+	clock := clockmock.New(time.Unix(1600000000, 0))
+	rep := newReputation(t, mock.NewStateStore(), reputation.Options{Clock: clock, HalfLife: time.Hour})
+
+	failed := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	recent := swarm.MustParseHexAddress("8b2c1b1f7f2fa7ed1ea1d8e5f7fcfa0f13ecbd6ba7fc5c9ac1a4f3f3cc0de8d4")
+
+	for i := 0; i < 4; i++ {
+		if err := rep.Failure(failed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clock.Add(10 * time.Hour)
+	if err := rep.Failure(recent); err != nil {
+		t.Fatal(err)
+	}
+
+	// the old failures weigh less than the recent one
+	overlays := []swarm.Address{recent, failed}
+	if err := rep.Sort(overlays); err != nil {
+		t.Fatal(err)
+	}
+	if !overlays[0].Equal(failed) {
+		t.Fatalf("got %s first, want %s", overlays[0], failed)
+	}
+
+	r, err := rep.Get(failed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Score(clock.Now(), time.Hour), 0.5; got < want-0.01 || got >= want {
+		t.Fatalf("got score %v after ten half lives, want close to %v", got, want)
+	}
+
+	// the counts are decayed when the record is updated
+	if err := rep.Success(failed); err != nil {
+		t.Fatal(err)
+	}
+	if r, err = rep.Get(failed); err != nil {
+		t.Fatal(err)
+	}
+	if r.Failures > 0.01 || r.Successes != 1 {
+		t.Fatalf("got %+v, want the failures decayed", r)
+	}
+}
+
+func newReputation(t *testing.T, store storage.StateStorer, o reputation.Options) reputation.Interface {
+	t.Helper()
+
+	o.Logger = logging.New(ioutil.Discard, 0)
+	rep, err := reputation.New(store, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := rep.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return rep
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package resolver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/clock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	// defaultCacheTTL is the time for which a resolved name is cached.
+	defaultCacheTTL = 10 * time.Minute
+	// defaultNegativeCacheTTL is the time for which a name that is not
+	// found is cached.
+	defaultNegativeCacheTTL = time.Minute
+	// maxCacheEntries is the maximal number of the cached names, so that
+	// the lookups of arbitrary names do not fill the memory.
+	maxCacheEntries = 10000
+)
+
+var _ Interface = (*Cache)(nil)
+
+// CacheOptions are the options of the Cache.
+type CacheOptions struct {
+	// TTL is the time for which a resolved name is cached. It defaults to
+	// ten minutes.
+	TTL time.Duration
+	// NegativeTTL is the time for which a name that is not found is
+	// cached. It defaults to a minute, and the names that are not found
+	// are not cached if it is negative.
+	NegativeTTL time.Duration
+	// Clock times the expiration of the cached names, the system clock if
+	// it is not set.
+	Clock clock.Clock
+}
+
+// Cache caches the names resolved by a resolver, and the names that are not
+// found by it, so that every request for a name does not make a lookup.
+// Concurrent lookups of the same name are made once. The errors other than
+// ErrNotFound are not cached.
+type Cache struct {
+	resolver    Interface
+	ttl         time.Duration
+	negativeTTL time.Duration
+	clock       clock.Clock
+	metrics     metrics
+
+	entries map[string]cacheEntry
+	mu      sync.Mutex // protects entries
+	lookups singleflight.Group
+}
+
+type cacheEntry struct {
+	address swarm.Address // zero address if the name is not found
+	expires time.Time
+}
+
+// NewCache returns the cache of the names resolved by the resolver.
+func NewCache(r Interface, o CacheOptions) *Cache {
+	if o.TTL == 0 {
+		o.TTL = defaultCacheTTL
+	}
+	if o.NegativeTTL == 0 {
+		o.NegativeTTL = defaultNegativeCacheTTL
+	}
+	if o.Clock == nil {
+		o.Clock = clock.System
+	}
+	return &Cache{
+		resolver:    r,
+		ttl:         o.TTL,
+		negativeTTL: o.NegativeTTL,
+		clock:       o.Clock,
+		metrics:     newMetrics(),
+		entries:     make(map[string]cacheEntry),
+	}
+}
+
+// Resolve returns the cached address of the name, or resolves it if it is
+// not cached or its entry expired.
+func (c *Cache) Resolve(ctx context.Context, name string) (swarm.Address, error) {
+	c.mu.Lock()
+	e, ok := c.entries[name]
+	if ok && !c.clock.Now().Before(e.expires) {
+		delete(c.entries, name)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if ok {
+		if e.address.IsZero() {
+			c.metrics.NegativeHitsCounter.Inc()
+			return swarm.ZeroAddress, ErrNotFound
+		}
+		c.metrics.HitsCounter.Inc()
+		return e.address, nil
+	}
+
+	c.metrics.MissesCounter.Inc()
+	v, err, _ := c.lookups.Do(name, func() (interface{}, error) {
+		addr, err := c.resolver.Resolve(ctx, name)
+		switch {
+		case err == nil:
+			c.put(name, addr, c.ttl)
+		case errors.Is(err, ErrNotFound) && c.negativeTTL > 0:
+			c.put(name, swarm.ZeroAddress, c.negativeTTL)
+		}
+		return addr, err
+	})
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return v.(swarm.Address), nil
+}
+
+// put caches the address of the name for the ttl. The expired entries are
+// removed when the cache is full, and the name is not cached if there are
+// none.
+func (c *Cache) put(name string, addr swarm.Address, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	if _, ok := c.entries[name]; !ok && len(c.entries) >= maxCacheEntries {
+		for n, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, n)
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			return
+		}
+	}
+	c.entries[name] = cacheEntry{address: addr, expires: now.Add(ttl)}
+}
+
+// Flush removes all cached names and returns their number.
+func (c *Cache) Flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.entries)
+	c.entries = make(map[string]cacheEntry)
+	c.metrics.FlushesCounter.Inc()
+	return n
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package resolver_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	clockmock "github.com/ethersphere/bee/pkg/clock/mock"
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/resolver/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestCache(t *testing.T) {
+	addr := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	r := mock.New(map[string]swarm.Address{"swarm.eth": addr})
+	clock := clockmock.New(time.Unix(0, 0))
+	c := resolver.NewCache(r, resolver.CacheOptions{
+		TTL:         time.Minute,
+		NegativeTTL: time.Second,
+		Clock:       clock,
+	})
+	ctx := context.Background()
+
+	resolve := func(name string, wantAddr swarm.Address, wantErr error, wantLookups int) {
+		t.Helper()
+
+		got, err := c.Resolve(ctx, name)
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("got error %v, want %v", err, wantErr)
+		}
+		if !got.Equal(wantAddr) {
+			t.Fatalf("got address %s, want %s", got, wantAddr)
+		}
+		if n := r.Lookups(name); n != wantLookups {
+			t.Fatalf("got %v lookups of %q, want %v", n, name, wantLookups)
+		}
+	}
+
+	resolve("swarm.eth", addr, nil, 1)
+	resolve("swarm.eth", addr, nil, 1)
+	resolve("unknown.eth", swarm.ZeroAddress, resolver.ErrNotFound, 1)
+	resolve("unknown.eth", swarm.ZeroAddress, resolver.ErrNotFound, 1)
+
+	// the negative entry expires before the resolved one
+	clock.Add(time.Second)
+	resolve("unknown.eth", swarm.ZeroAddress, resolver.ErrNotFound, 2)
+	resolve("swarm.eth", addr, nil, 1)
+
+	clock.Add(time.Minute)
+	resolve("swarm.eth", addr, nil, 2)
+
+	// the errors other than not found are not cached
+	lookupErr := errors.New("lookup error")
+	r.SetError(lookupErr)
+	resolve("other.eth", swarm.ZeroAddress, lookupErr, 1)
+	r.SetError(nil)
+	resolve("other.eth", swarm.ZeroAddress, resolver.ErrNotFound, 2)
+
+	// the expired negative entry of unknown.eth is flushed too
+	if n := c.Flush(); n != 3 {
+		t.Fatalf("got %v flushed entries, want 3", n)
+	}
+	resolve("swarm.eth", addr, nil, 3)
+	if n := c.Flush(); n != 1 {
+		t.Fatalf("got %v flushed entries, want 1", n)
+	}
+}
+
+func TestCacheNoNegative(t *testing.T) {
+	r := mock.New(nil)
+	c := resolver.NewCache(r, resolver.CacheOptions{NegativeTTL: -1})
+
+	for i := 1; i <= 2; i++ {
+		if _, err := c.Resolve(context.Background(), "unknown.eth"); !errors.Is(err, resolver.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, resolver.ErrNotFound)
+		}
+		if n := r.Lookups("unknown.eth"); n != i {
+			t.Fatalf("got %v lookups, want %v", n, i)
+		}
+	}
+}
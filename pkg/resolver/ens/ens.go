@@ -0,0 +1,245 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ens resolves the ENS names to the swarm addresses set as their
+// content hashes, using the JSON-RPC API of an Ethereum endpoint.
+package ens
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"golang.org/x/crypto/sha3"
+)
+
+// DefaultRegistryAddress is the address of the ENS registry on the Ethereum
+// mainnet and the public test networks.
+const DefaultRegistryAddress = "00000000000c2e074ec69a0dfb2997ba6c7d2e1e"
+
+var (
+	// swarmContentHashPrefix is the prefix of the swarm content hashes: the
+	// swarm-ns multicodec, CIDv1, the swarm-manifest multicodec and the
+	// keccak-256 multihash of 32 bytes.
+	swarmContentHashPrefix = []byte{0xe4, 0x01, 0x01, 0xfa, 0x01, 0x1b, 0x20}
+
+	resolverSelector    = selector("resolver(bytes32)")
+	contentHashSelector = selector("contenthash(bytes32)")
+)
+
+var (
+	// ErrInvalidContentHash is returned if the content hash of a name is not
+	// a swarm address.
+	ErrInvalidContentHash = errors.New("ens: invalid content hash")
+	// ErrInvalidResponse is returned if the endpoint responds with a result
+	// that can not be decoded.
+	ErrInvalidResponse = errors.New("ens: invalid response")
+)
+
+var _ resolver.Interface = (*Resolver)(nil)
+
+// Options are the options of the Resolver.
+type Options struct {
+	// RegistryAddress is the hex address of the ENS registry, the
+	// DefaultRegistryAddress if it is not set.
+	RegistryAddress string
+	// HTTPClient makes the requests to the endpoint, the default client if
+	// it is not set.
+	HTTPClient *http.Client
+}
+
+// Resolver resolves the ENS names with the eth_call requests to an Ethereum
+// endpoint.
+type Resolver struct {
+	endpoint string
+	registry []byte
+	client   *http.Client
+}
+
+// New returns a new Resolver that makes the requests to the endpoint.
+func New(endpoint string, o Options) (*Resolver, error) {
+	if o.RegistryAddress == "" {
+		o.RegistryAddress = DefaultRegistryAddress
+	}
+	registry, err := hex.DecodeString(strings.TrimPrefix(o.RegistryAddress, "0x"))
+	if err != nil || len(registry) != 20 {
+		return nil, fmt.Errorf("ens: invalid registry address %q", o.RegistryAddress)
+	}
+	if o.HTTPClient == nil {
+		o.HTTPClient = http.DefaultClient
+	}
+	return &Resolver{
+		endpoint: endpoint,
+		registry: registry,
+		client:   o.HTTPClient,
+	}, nil
+}
+
+// Resolve returns the swarm address set as the content hash of the name. It
+// returns resolver.ErrNotFound if the name has no resolver or no content hash.
+func (r *Resolver) Resolve(ctx context.Context, name string) (swarm.Address, error) {
+	node := namehash(name)
+
+	result, err := r.call(ctx, r.registry, resolverSelector, node)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("get resolver: %w", err)
+	}
+	if len(result) != 32 {
+		return swarm.ZeroAddress, ErrInvalidResponse
+	}
+	resolverAddress := result[12:]
+	if bytes.Equal(resolverAddress, make([]byte, 20)) {
+		return swarm.ZeroAddress, resolver.ErrNotFound
+	}
+
+	result, err = r.call(ctx, resolverAddress, contentHashSelector, node)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("get content hash: %w", err)
+	}
+	contentHash, err := decodeBytes(result)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if len(contentHash) == 0 {
+		return swarm.ZeroAddress, resolver.ErrNotFound
+	}
+	if len(contentHash) != len(swarmContentHashPrefix)+swarm.HashSize || !bytes.HasPrefix(contentHash, swarmContentHashPrefix) {
+		return swarm.ZeroAddress, ErrInvalidContentHash
+	}
+	return swarm.NewAddress(contentHash[len(swarmContentHashPrefix):]), nil
+}
+
+type callRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type callParams struct {
+	To   string `json:"to"`
+	Data string `json:"data"`
+}
+
+type callResponse struct {
+	Result string `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call makes the eth_call of the contract method with a single bytes32
+// argument and returns the result.
+func (r *Resolver) call(ctx context.Context, to, selector, arg []byte) ([]byte, error) {
+	body, err := json.Marshal(callRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "eth_call",
+		Params: []interface{}{
+			callParams{
+				To:   "0x" + hex.EncodeToString(to),
+				Data: "0x" + hex.EncodeToString(append(append([]byte{}, selector...), arg...)),
+			},
+			"latest",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("endpoint response status %s", resp.Status)
+	}
+
+	var res callResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("endpoint error %d: %s", res.Error.Code, res.Error.Message)
+	}
+	result, err := hex.DecodeString(strings.TrimPrefix(res.Result, "0x"))
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	return result, nil
+}
+
+// decodeBytes decodes the ABI encoded dynamic bytes returned by a contract
+// method. An empty result decodes to no bytes.
+func decodeBytes(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	if len(b) < 64 {
+		return nil, ErrInvalidResponse
+	}
+	offset, ok := decodeUint(b[:32])
+	if !ok || offset > uint64(len(b))-32 {
+		return nil, ErrInvalidResponse
+	}
+	length, ok := decodeUint(b[offset : offset+32])
+	if !ok || length > uint64(len(b))-offset-32 {
+		return nil, ErrInvalidResponse
+	}
+	return b[offset+32 : offset+32+length], nil
+}
+
+// decodeUint decodes an ABI encoded uint256 word that fits into uint64.
+func decodeUint(word []byte) (uint64, bool) {
+	if !bytes.Equal(word[:24], make([]byte, 24)) {
+		return 0, false
+	}
+	var v uint64
+	for _, b := range word[24:] {
+		v = v<<8 | uint64(b)
+	}
+	return v, true
+}
+
+// namehash returns the ENS node of the name.
+func namehash(name string) []byte {
+	node := make([]byte, 32)
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = keccak256(node, keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// selector returns the selector of the contract method with the signature.
+func selector(signature string) []byte {
+	return keccak256([]byte(signature))[:4]
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		_, _ = h.Write(d)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,192 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ens_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/resolver/ens"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestNamehash(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{name: "", want: "0000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "eth", want: "93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"},
+		{name: "foo.eth", want: "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+	} {
+		if got := hex.EncodeToString(ens.Namehash(tc.name)); got != tc.want {
+			t.Errorf("namehash of %q: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSelectors(t *testing.T) {
+	if got := hex.EncodeToString(ens.ResolverSelector); got != "0178b8bf" {
+		t.Errorf("got resolver selector %s, want 0178b8bf", got)
+	}
+	if got := hex.EncodeToString(ens.ContentHashSelector); got != "bc1c58d1" {
+		t.Errorf("got contenthash selector %s, want bc1c58d1", got)
+	}
+}
+
+const (
+	registryAddress = "00000000000c2e074ec69a0dfb2997ba6c7d2e1e"
+	resolverAddress = "4976fb03c32e5b8cfe2b6ccb31c09ba78ebaba41"
+)
+
+func TestResolve(t *testing.T) {
+	addr := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+
+	for _, tc := range []struct {
+		name        string
+		resolver    string // hex address returned by the registry
+		contentHash string // hex content hash returned by the resolver
+		wantAddr    swarm.Address
+		wantErr     error
+	}{
+		{
+			name:        "swarm.eth",
+			resolver:    resolverAddress,
+			contentHash: "e40101fa011b20" + addr.String(),
+			wantAddr:    addr,
+		},
+		{
+			name:     "no resolver",
+			resolver: strings.Repeat("0", 40),
+			wantErr:  resolver.ErrNotFound,
+		},
+		{
+			name:     "no content hash",
+			resolver: resolverAddress,
+			wantErr:  resolver.ErrNotFound,
+		},
+		{
+			name:        "ipfs content hash",
+			resolver:    resolverAddress,
+			contentHash: "e301017012201687de19f1516b9e560ab8655faa678e3a023ebff43494ac06a36581aafc957e",
+			wantErr:     ens.ErrInvalidContentHash,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := newEndpoint(t, tc.name, tc.resolver, tc.contentHash)
+			defer endpoint.Close()
+
+			r, err := ens.New(endpoint.URL, ens.Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Resolve(context.Background(), tc.name)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if !got.Equal(tc.wantAddr) {
+				t.Fatalf("got address %s, want %s", got, tc.wantAddr)
+			}
+		})
+	}
+}
+
+func TestResolveEndpointError(t *testing.T) {
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"header not found"}}`))
+	}))
+	defer endpoint.Close()
+
+	r, err := ens.New(endpoint.URL, ens.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Resolve(context.Background(), "swarm.eth")
+	if err == nil || errors.Is(err, resolver.ErrNotFound) {
+		t.Fatalf("got error %v, want endpoint error", err)
+	}
+}
+
+func TestNewInvalidRegistry(t *testing.T) {
+	if _, err := ens.New("http://localhost", ens.Options{RegistryAddress: "0x1234"}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// newEndpoint returns a JSON-RPC server that answers the eth_call requests
+// of the registry with the resolver address and the eth_call requests of the
+// resolver with the ABI encoded content hash, for the node of the name.
+func newEndpoint(t *testing.T, name, resolverAddr, contentHash string) *httptest.Server {
+	t.Helper()
+
+	node := hex.EncodeToString(ens.Namehash(name))
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		if req.Method != "eth_call" || len(req.Params) != 2 {
+			t.Errorf("unexpected request %s %v", req.Method, req.Params)
+			return
+		}
+		var call struct {
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+		if err := json.Unmarshal(req.Params[0], &call); err != nil {
+			t.Errorf("decode call: %v", err)
+			return
+		}
+
+		var result string
+		switch call.To {
+		case "0x" + registryAddress:
+			if call.Data != "0x"+hex.EncodeToString(ens.ResolverSelector)+node {
+				t.Errorf("unexpected registry call data %s", call.Data)
+			}
+			result = strings.Repeat("0", 24) + resolverAddr
+		case "0x" + resolverAddress:
+			if call.Data != "0x"+hex.EncodeToString(ens.ContentHashSelector)+node {
+				t.Errorf("unexpected resolver call data %s", call.Data)
+			}
+			result = encodeBytes(contentHash)
+		default:
+			t.Errorf("unexpected call to %s", call.To)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result":  "0x" + result,
+		})
+	}))
+}
+
+// encodeBytes returns the hex of the ABI encoded dynamic bytes of the hex
+// data.
+func encodeBytes(data string) string {
+	word := func(v int) string {
+		b := make([]byte, 32)
+		b[31] = byte(v)
+		return hex.EncodeToString(b)
+	}
+	padded := data
+	if n := len(data) % 64; n != 0 {
+		padded += strings.Repeat("0", 64-n)
+	}
+	return word(32) + word(len(data)/2) + padded
+}
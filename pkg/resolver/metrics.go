@@ -0,0 +1,55 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package resolver
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	// all metrics fields must be exported
+	// to be able to return them by Metrics()
+	// using reflection
+	HitsCounter         prometheus.Counter
+	NegativeHitsCounter prometheus.Counter
+	MissesCounter       prometheus.Counter
+	FlushesCounter      prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "resolver_cache"
+
+	return metrics{
+		HitsCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "hits",
+			Help:      "Number of names resolved from the cache.",
+		}),
+		NegativeHitsCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "negative_hits",
+			Help:      "Number of names found in the cache as not registered.",
+		}),
+		MissesCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "misses",
+			Help:      "Number of names that were not cached and were looked up.",
+		}),
+		FlushesCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "flushes",
+			Help:      "Number of times the cache was flushed.",
+		}),
+	}
+}
+
+func (c *Cache) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(c.metrics)
+}
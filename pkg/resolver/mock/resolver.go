@@ -0,0 +1,79 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/resolver"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ resolver.Interface = (*Resolver)(nil)
+
+// Resolver resolves the names from a map and counts the lookups of every
+// name.
+type Resolver struct {
+	names   map[string]swarm.Address
+	lookups map[string]int
+	err     error
+	mu      sync.Mutex
+}
+
+// New returns a new Resolver of the names. The names that are not in the map
+// are not found.
+func New(names map[string]swarm.Address) *Resolver {
+	r := &Resolver{
+		names:   make(map[string]swarm.Address),
+		lookups: make(map[string]int),
+	}
+	for name, addr := range names {
+		r.names[name] = addr
+	}
+	return r
+}
+
+// Set sets the address of the name.
+func (r *Resolver) Set(name string, addr swarm.Address) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.names[name] = addr
+}
+
+// Resolve returns the address of the name or resolver.ErrNotFound, or the
+// error set by SetError.
+func (r *Resolver) Resolve(_ context.Context, name string) (swarm.Address, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lookups[name]++
+	if r.err != nil {
+		return swarm.ZeroAddress, r.err
+	}
+	addr, ok := r.names[name]
+	if !ok {
+		return swarm.ZeroAddress, resolver.ErrNotFound
+	}
+	return addr, nil
+}
+
+// SetError sets the error returned by every lookup, or clears it if it is
+// nil.
+func (r *Resolver) SetError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.err = err
+}
+
+// Lookups returns the number of the lookups of the name.
+func (r *Resolver) Lookups(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lookups[name]
+}
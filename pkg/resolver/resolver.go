@@ -0,0 +1,23 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package resolver resolves the names of the content, such as the ENS names,
+// to their swarm addresses, and caches the resolved names.
+package resolver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ErrNotFound is returned by the resolvers if the name is not registered or
+// it has no swarm address.
+var ErrNotFound = errors.New("resolver: name not found")
+
+// Interface resolves the names to the swarm addresses.
+type Interface interface {
+	Resolve(ctx context.Context, name string) (swarm.Address, error)
+}
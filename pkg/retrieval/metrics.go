@@ -0,0 +1,98 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package retrieval
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	// all metrics fields must be exported
+	// to be able to return them by Metrics()
+	// using reflection
+	RequestCounter           prometheus.Counter
+	PeerRequestCounter       prometheus.Counter
+	TotalRetrieved           prometheus.Counter
+	RetrieveChunkErrorCount  prometheus.Counter
+	PeerRetrieveErrorCounter prometheus.Counter
+	HopLimitReachedCounter   prometheus.Counter
+	ChunksDeliveredCounter   prometheus.Counter
+	DeliveryErrorCounter     prometheus.Counter
+	InvalidChunkRetrieved    prometheus.Counter
+	RetrieveChunkTimer       prometheus.Histogram
+}
+
+func newMetrics() metrics {
+	subsystem := "retrieval"
+
+	return metrics{
+		RequestCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "request_count",
+			Help:      "Number of requests to retrieve chunks.",
+		}),
+		PeerRequestCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "peer_request_count",
+			Help:      "Number of requests to single peers to retrieve chunks.",
+		}),
+		TotalRetrieved: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_retrieved",
+			Help:      "Number of chunks successfully retrieved.",
+		}),
+		RetrieveChunkErrorCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "retrieve_chunk_error_count",
+			Help:      "Number of requests to retrieve chunks that failed.",
+		}),
+		PeerRetrieveErrorCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "peer_retrieve_error_count",
+			Help:      "Number of requests to single peers that failed.",
+		}),
+		HopLimitReachedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "hop_limit_reached_count",
+			Help:      "Number of requests that were not forwarded as they reached the hop limit.",
+		}),
+		ChunksDeliveredCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "chunks_delivered_count",
+			Help:      "Number of chunks delivered to peers.",
+		}),
+		DeliveryErrorCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "delivery_error_count",
+			Help:      "Number of requests from peers that could not be served.",
+		}),
+		InvalidChunkRetrieved: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "invalid_chunk_retrieved",
+			Help:      "Number of chunks delivered by peers that are not valid.",
+		}),
+		RetrieveChunkTimer: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "retrieve_chunk_time_histogram",
+			Help:      "Histogram of the time taken to retrieve a chunk.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 60},
+		}),
+	}
+}
+
+func (s *Service) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
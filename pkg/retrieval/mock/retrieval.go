@@ -0,0 +1,23 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"context"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+type Retrieval struct {
+	retrieveChunk func(ctx context.Context, addr swarm.Address) (data []byte, err error)
+}
+
+func New(retrieveChunk func(ctx context.Context, addr swarm.Address) (data []byte, err error)) *Retrieval {
+	return &Retrieval{retrieveChunk: retrieveChunk}
+}
+
+func (r *Retrieval) RetrieveChunk(ctx context.Context, addr swarm.Address) (data []byte, err error) {
+	return r.retrieveChunk(ctx, addr)
+}
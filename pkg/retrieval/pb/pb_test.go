@@ -0,0 +1,34 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p/protobuf/protobuftest"
+	"github.com/ethersphere/bee/pkg/retrieval/pb"
+)
+
+// TestMessages checks that the wire format of the messages is compatible
+// with the released version of the protocol.
+func TestMessages(t *testing.T) {
+	protobuftest.Check(t,
+		protobuftest.Message{
+			Message: &pb.Request{Addr: []byte{1, 2, 3}, Hops: 2},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Addr", WireType: "bytes"},
+				{Number: 2, Name: "Hops", WireType: "varint"},
+			},
+			Wire: "0a030102031002",
+		},
+		protobuftest.Message{
+			Message: &pb.Delivery{Data: []byte("data")},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Data", WireType: "bytes"},
+			},
+			Wire: "0a0464617461",
+		},
+	)
+}
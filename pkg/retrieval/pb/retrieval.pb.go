@@ -24,6 +24,7 @@ const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
 type Request struct {
 	Addr []byte `protobuf:"bytes,1,opt,name=Addr,proto3" json:"Addr,omitempty"`
+	Hops uint32 `protobuf:"varint,2,opt,name=Hops,proto3" json:"Hops,omitempty"`
 }
 
 func (m *Request) Reset()         { *m = Request{} }
@@ -66,6 +67,13 @@ func (m *Request) GetAddr() []byte {
 	return nil
 }
 
+func (m *Request) GetHops() uint32 {
+	if m != nil {
+		return m.Hops
+	}
+	return 0
+}
+
 type Delivery struct {
 	Data []byte `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
 }
@@ -118,16 +126,17 @@ func init() {
 func init() { proto.RegisterFile("retrieval.proto", fileDescriptor_fcade0a564e5dcd4) }
 
 var fileDescriptor_fcade0a564e5dcd4 = []byte{
-	// 134 bytes of a gzipped FileDescriptorProto
+	// 145 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x2f, 0x4a, 0x2d, 0x29,
 	0xca, 0x4c, 0x2d, 0x4b, 0xcc, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x28, 0x4a, 0x2d,
-	0x01, 0xf3, 0x95, 0x64, 0xb9, 0xd8, 0x83, 0x52, 0x0b, 0x4b, 0x53, 0x8b, 0x4b, 0x84, 0x84, 0xb8,
-	0x58, 0x1c, 0x53, 0x52, 0x8a, 0x24, 0x18, 0x15, 0x18, 0x35, 0x78, 0x82, 0xc0, 0x6c, 0x25, 0x39,
-	0x2e, 0x0e, 0x97, 0xd4, 0x9c, 0xcc, 0xb2, 0xd4, 0xa2, 0x4a, 0x90, 0xbc, 0x4b, 0x62, 0x49, 0x22,
-	0x4c, 0x1e, 0xc4, 0x76, 0x92, 0x39, 0xf1, 0x48, 0x8e, 0xf1, 0xc2, 0x23, 0x39, 0xc6, 0x07, 0x8f,
-	0xe4, 0x18, 0x27, 0x3c, 0x96, 0x63, 0xb8, 0xf0, 0x58, 0x8e, 0xe1, 0xc6, 0x63, 0x39, 0x86, 0x28,
-	0xa6, 0x82, 0xa4, 0x24, 0x36, 0xb0, 0x6d, 0xc6, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0xe5, 0x88,
-	0xb0, 0x44, 0x80, 0x00, 0x00, 0x00,
+	0x01, 0xf3, 0x95, 0x0c, 0xb9, 0xd8, 0x83, 0x52, 0x0b, 0x4b, 0x53, 0x8b, 0x4b, 0x84, 0x84, 0xb8,
+	0x58, 0x1c, 0x53, 0x52, 0x8a, 0x24, 0x18, 0x15, 0x18, 0x35, 0x78, 0x82, 0xc0, 0x6c, 0x90, 0x98,
+	0x47, 0x7e, 0x41, 0xb1, 0x04, 0x93, 0x02, 0xa3, 0x06, 0x6f, 0x10, 0x98, 0xad, 0x24, 0xc7, 0xc5,
+	0xe1, 0x92, 0x9a, 0x93, 0x59, 0x96, 0x5a, 0x54, 0x09, 0x92, 0x77, 0x49, 0x2c, 0x49, 0x84, 0xe9,
+	0x01, 0xb1, 0x9d, 0x64, 0x4e, 0x3c, 0x92, 0x63, 0xbc, 0xf0, 0x48, 0x8e, 0xf1, 0xc1, 0x23, 0x39,
+	0xc6, 0x09, 0x8f, 0xe5, 0x18, 0x2e, 0x3c, 0x96, 0x63, 0xb8, 0xf1, 0x58, 0x8e, 0x21, 0x8a, 0xa9,
+	0x20, 0x29, 0x89, 0x0d, 0xec, 0x02, 0x63, 0xc0, 0x00, 0x47, 0x86, 0x77, 0xc4, 0x94, 0x00, 0x00,
+	0x00,
 }
 
 func (m *Request) Marshal() (dAtA []byte, err error) {
@@ -150,6 +159,11 @@ func (m *Request) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Hops != 0 {
+		i = encodeVarintRetrieval(dAtA, i, uint64(m.Hops))
+		i--
+		dAtA[i] = 0x10
+	}
 	if len(m.Addr) > 0 {
 		i -= len(m.Addr)
 		copy(dAtA[i:], m.Addr)
@@ -211,6 +225,9 @@ func (m *Request) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovRetrieval(uint64(l))
 	}
+	if m.Hops != 0 {
+		n += 1 + sovRetrieval(uint64(m.Hops))
+	}
 	return n
 }
 
@@ -296,6 +313,25 @@ func (m *Request) Unmarshal(dAtA []byte) error {
 				m.Addr = []byte{}
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hops", wireType)
+			}
+			m.Hops = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRetrieval
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Hops |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRetrieval(dAtA[iNdEx:])
@@ -6,6 +6,7 @@ package retrieval
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -16,11 +17,17 @@ import (
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/topology"
+	"github.com/ethersphere/bee/pkg/tracing"
+	"github.com/opentracing/opentracing-go"
 	"golang.org/x/sync/singleflight"
 )
 
 type requestSourceContextKey struct{}
 
+// requestHopsContextKey is the context key of the number of times the request
+// was forwarded before it reached this node.
+type requestHopsContextKey struct{}
+
 const (
 	protocolName    = "retrieval"
 	protocolVersion = "1.0.0"
@@ -29,6 +36,12 @@ const (
 
 var _ Interface = (*Service)(nil)
 
+// ErrHopLimitReached is returned if the request was forwarded too many times
+// to be forwarded again.
+var ErrHopLimitReached = errors.New("hop limit reached")
+
+var maxHops uint32 = 16 // maximal number of times a request is forwarded
+
 type Interface interface {
 	RetrieveChunk(ctx context.Context, addr swarm.Address) (data []byte, err error)
 }
@@ -37,15 +50,23 @@ type Service struct {
 	streamer      p2p.Streamer
 	peerSuggester topology.EachPeerer
 	storer        storage.Storer
+	validator     swarm.ChunkValidator
 	singleflight  singleflight.Group
+	metrics       metrics
 	logger        logging.Logger
+	tracer        *tracing.Tracer
 }
 
 type Options struct {
 	Streamer    p2p.Streamer
 	ChunkPeerer topology.EachPeerer
 	Storer      storage.Storer
-	Logger      logging.Logger
+	// ChunkValidator rejects the delivered chunks that are not valid, and
+	// the chunk is then retrieved from the next closest peer. Chunks are
+	// not validated if it is not set.
+	ChunkValidator swarm.ChunkValidator
+	Logger         logging.Logger
+	Tracer         *tracing.Tracer
 }
 
 func New(o Options) *Service {
@@ -53,7 +74,10 @@ func New(o Options) *Service {
 		streamer:      o.Streamer,
 		peerSuggester: o.ChunkPeerer,
 		storer:        o.Storer,
+		validator:     o.ChunkValidator,
+		metrics:       newMetrics(),
 		logger:        o.Logger,
+		tracer:        o.Tracer,
 	}
 }
 
@@ -75,19 +99,35 @@ const (
 	retrieveChunkTimeout = 10 * time.Second
 )
 
+// RetrieveChunk retrieves the chunk from the closest peers to its address,
+// trying the next closest peer if a peer fails to deliver it. Requests that
+// are received from peers are forwarded at most maxHops times.
 func (s *Service) RetrieveChunk(ctx context.Context, addr swarm.Address) (data []byte, err error) {
+	s.metrics.RequestCounter.Inc()
+
+	hops, _ := ctx.Value(requestHopsContextKey{}).(uint32)
+	if hops > maxHops {
+		s.metrics.HopLimitReachedCounter.Inc()
+		return nil, ErrHopLimitReached
+	}
+
+	span, _, ctx := s.tracer.StartSpanFromContext(ctx, "retrieval-retrieve", nil, opentracing.Tag{Key: "address", Value: addr.String()})
+	defer span.Finish()
+
 	ctx, cancel := context.WithTimeout(ctx, maxPeers*retrieveChunkTimeout)
 	defer cancel()
 
+	start := time.Now()
 	v, err, _ := s.singleflight.Do(addr.String(), func() (v interface{}, err error) {
 		var skipPeers []swarm.Address
 		for i := 0; i < maxPeers; i++ {
 			var peer swarm.Address
-			data, peer, err = s.retrieveChunk(ctx, addr, skipPeers)
+			data, peer, err = s.retrieveChunk(ctx, addr, hops, skipPeers)
 			if err != nil {
 				if peer.IsZero() {
 					return nil, err
 				}
+				s.metrics.PeerRetrieveErrorCounter.Inc()
 				s.logger.Debugf("retrieval: failed to get chunk %s from peer %s: %v", addr, peer, err)
 				skipPeers = append(skipPeers, peer)
 				continue
@@ -98,12 +138,15 @@ func (s *Service) RetrieveChunk(ctx context.Context, addr swarm.Address) (data [
 		return nil, err
 	})
 	if err != nil {
+		s.metrics.RetrieveChunkErrorCount.Inc()
 		return nil, err
 	}
+	s.metrics.TotalRetrieved.Inc()
+	s.metrics.RetrieveChunkTimer.Observe(time.Since(start).Seconds())
 	return v.([]byte), nil
 }
 
-func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, skipPeers []swarm.Address) (data []byte, peer swarm.Address, err error) {
+func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, hops uint32, skipPeers []swarm.Address) (data []byte, peer swarm.Address, err error) {
 	v := ctx.Value(requestSourceContextKey{})
 	if src, ok := v.(string); ok {
 		skipAddr, err := swarm.ParseHexAddress(src)
@@ -119,6 +162,7 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, skipPee
 		return nil, peer, fmt.Errorf("get closest: %w", err)
 	}
 	s.logger.Tracef("retrieval: requesting chunk %s from peer %s", addr, peer)
+	s.metrics.PeerRequestCounter.Inc()
 	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
 	if err != nil {
 		return nil, peer, fmt.Errorf("new stream: %w", err)
@@ -135,6 +179,7 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, skipPee
 
 	if err := w.WriteMsgWithContext(ctx, &pb.Request{
 		Addr: addr.Bytes(),
+		Hops: hops,
 	}); err != nil {
 		return nil, peer, fmt.Errorf("write request: %w peer %s", err, peer.String())
 	}
@@ -143,64 +188,50 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, skipPee
 	if err := r.ReadMsgWithContext(ctx, &d); err != nil {
 		return nil, peer, fmt.Errorf("read delivery: %w peer %s", err, peer.String())
 	}
+	if s.validator != nil && !s.validator.Validate(swarm.NewChunk(addr, d.Data)) {
+		s.metrics.InvalidChunkRetrieved.Inc()
+		return nil, peer, fmt.Errorf("delivery: %w peer %s", storage.ErrInvalidChunk, peer.String())
+	}
 
 	return d.Data, peer, nil
 }
 
 func (s *Service) closestPeer(addr swarm.Address, skipPeers []swarm.Address) (swarm.Address, error) {
-	closest := swarm.Address{}
-	err := s.peerSuggester.EachPeerRev(func(peer swarm.Address, po uint8) (bool, bool, error) {
-		for _, a := range skipPeers {
-			if a.Equal(peer) {
-				return false, false, nil
-			}
-		}
-		if closest.IsZero() {
-			closest = peer
-			return false, false, nil
-		}
-		dcmp, err := swarm.DistanceCmp(addr.Bytes(), closest.Bytes(), peer.Bytes())
-		if err != nil {
-			return false, false, fmt.Errorf("distance compare error. addr %s closest %s peer %s: %w", addr.String(), closest.String(), peer.String(), err)
-		}
-		switch dcmp {
-		case 0:
-			// do nothing
-		case -1:
-			// current peer is closer
-			closest = peer
-		case 1:
-			// closest is already closer to chunk
-			// do nothing
-		}
-		return false, false, nil
-	})
+	peers, err := topology.ClosestPeers(s.peerSuggester, addr, 1, topology.SkipPeers(skipPeers...))
 	if err != nil {
-		return swarm.Address{}, err
+		return swarm.Address{}, fmt.Errorf("closest peers to %s: %w", addr, err)
 	}
 
 	// check if found
-	if closest.IsZero() {
+	if len(peers) == 0 {
 		return swarm.Address{}, topology.ErrNotFound
 	}
 
-	return closest, nil
+	return peers[0], nil
 }
 
 func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
 	w, r := protobuf.NewWriterAndReader(stream)
 	defer func() {
 		if err != nil {
+			s.metrics.DeliveryErrorCounter.Inc()
 			_ = stream.Reset()
 		} else {
+			s.metrics.ChunksDeliveredCounter.Inc()
 			_ = stream.FullClose()
 		}
 	}()
+
+	span, _, ctx := s.tracer.StartSpanFromContext(ctx, "retrieval-handler", nil)
+	defer span.Finish()
+
 	var req pb.Request
 	if err := r.ReadMsg(&req); err != nil {
 		return fmt.Errorf("read request: %w peer %s", err, p.Address.String())
 	}
+	span.SetTag("address", swarm.NewAddress(req.Addr).String())
 	ctx = context.WithValue(ctx, requestSourceContextKey{}, p.Address.String())
+	ctx = context.WithValue(ctx, requestHopsContextKey{}, req.Hops+1)
 	chunk, err := s.storer.Get(ctx, storage.ModeGetRequest, swarm.NewAddress(req.Addr))
 	if err != nil {
 		return fmt.Errorf("get from store: %w peer %s", err, p.Address.String())
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/netstore"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/pkg/p2p/streamtest"
 	"github.com/ethersphere/bee/pkg/retrieval"
@@ -122,6 +123,178 @@ func TestDelivery(t *testing.T) {
 
 }
 
+// TestInvalidDelivery tests that the chunk is retrieved from the next
+// closest peer if the delivered chunk is not valid.
+func TestInvalidDelivery(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+
+	reqAddr := swarm.MustParseHexAddress("00112233")
+	reqData := []byte("data data data")
+
+	serverStorer := storemock.NewStorer()
+	if _, err := serverStorer.Put(context.Background(), storage.ModePutUpload, swarm.NewChunk(reqAddr, reqData)); err != nil {
+		t.Fatal(err)
+	}
+	server := retrieval.New(retrieval.Options{
+		Storer: serverStorer,
+		Logger: logger,
+	})
+	recorder := streamtest.New(
+		streamtest.WithProtocols(server.Protocol()),
+	)
+
+	closestPeer := swarm.MustParseHexAddress("00112234")
+	nextPeer := swarm.MustParseHexAddress("9ee7add7")
+
+	// the chunk delivered by the first peer is not valid
+	var validations int
+	client := retrieval.New(retrieval.Options{
+		Streamer: recorder,
+		ChunkPeerer: mockPeerSuggester{eachPeerRevFunc: func(f topology.EachPeerFunc) error {
+			for _, peer := range []swarm.Address{closestPeer, nextPeer} {
+				if stop, _, err := f(peer, 0); stop || err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		ChunkValidator: validatorFunc(func(swarm.Chunk) bool {
+			validations++
+			return validations > 1
+		}),
+		Logger: logger,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	v, err := client.RetrieveChunk(ctx, reqAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(v, reqData) {
+		t.Fatalf("request and response data not equal. got %s want %s", v, reqData)
+	}
+
+	for _, peer := range []swarm.Address{closestPeer, nextPeer} {
+		records, err := recorder.Records(peer, "retrieval", "1.0.0", "retrieval")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l := len(records); l != 1 {
+			t.Fatalf("got %v records for peer %s, want %v", l, peer, 1)
+		}
+	}
+}
+
+// TestForwarding tests that requests for chunks that are not stored locally
+// are forwarded to the closest peer, unless the hop limit is reached.
+func TestForwarding(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+
+	reqAddr := swarm.MustParseHexAddress("00112233")
+	reqData := []byte("data data data")
+
+	// the storer node holds the chunk
+	storerNodeStorer := storemock.NewStorer()
+	if _, err := storerNodeStorer.Put(context.Background(), storage.ModePutUpload, swarm.NewChunk(reqAddr, reqData)); err != nil {
+		t.Fatal(err)
+	}
+	storerNode := retrieval.New(retrieval.Options{
+		Storer: storerNodeStorer,
+		Logger: logger,
+	})
+	storerNodeRecorder := streamtest.New(
+		streamtest.WithProtocols(storerNode.Protocol()),
+	)
+
+	// the forwarder node does not hold the chunk and requests it from the
+	// storer node through its netstore
+	storerNodeAddr := swarm.MustParseHexAddress("9ee7add7")
+	forwarder := retrieval.New(retrieval.Options{
+		Streamer: storerNodeRecorder,
+		ChunkPeerer: mockPeerSuggester{eachPeerRevFunc: func(f topology.EachPeerFunc) error {
+			_, _, _ = f(storerNodeAddr, 0)
+			return nil
+		}},
+		Logger: logger,
+	})
+	forwarder.SetStorer(netstore.New(storemock.NewStorer(), nil, forwarder, logging.New(ioutil.Discard, 0), mockValidator{}))
+	forwarderRecorder := streamtest.New(
+		streamtest.WithProtocols(forwarder.Protocol()),
+	)
+
+	forwarderAddr := swarm.MustParseHexAddress("8ee7add7")
+	newClient := func() *retrieval.Service {
+		return retrieval.New(retrieval.Options{
+			Streamer: forwarderRecorder,
+			ChunkPeerer: mockPeerSuggester{eachPeerRevFunc: func(f topology.EachPeerFunc) error {
+				_, _, _ = f(forwarderAddr, 0)
+				return nil
+			}},
+			Storer: storemock.NewStorer(),
+			Logger: logger,
+		})
+	}
+
+	t.Run("forwarded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+		defer cancel()
+
+		v, err := newClient().RetrieveChunk(ctx, reqAddr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(v, reqData) {
+			t.Fatalf("request and response data not equal. got %s want %s", v, reqData)
+		}
+
+		records, err := storerNodeRecorder.Records(storerNodeAddr, "retrieval", "1.0.0", "retrieval")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l := len(records); l != 1 {
+			t.Fatalf("got %v records, want %v", l, 1)
+		}
+		messages, err := protobuf.ReadMessages(
+			bytes.NewReader(records[0].In()),
+			func() protobuf.Message { return new(pb.Request) },
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l := len(messages); l != 1 {
+			t.Fatalf("got %v requests, want %v", l, 1)
+		}
+		if hops := messages[0].(*pb.Request).Hops; hops != 1 {
+			t.Fatalf("got forwarded request hops %v, want %v", hops, 1)
+		}
+	})
+
+	t.Run("hop limit reached", func(t *testing.T) {
+		defer func(h uint32) { *retrieval.MaxHops = h }(*retrieval.MaxHops)
+		*retrieval.MaxHops = 0
+
+		// the forwarder stored the chunk in the previous request
+		forwarder.SetStorer(netstore.New(storemock.NewStorer(), nil, forwarder, logging.New(ioutil.Discard, 0), mockValidator{}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+		defer cancel()
+
+		if _, err := newClient().RetrieveChunk(ctx, reqAddr); err == nil {
+			t.Fatal("expected error")
+		}
+
+		// the storer node did not receive another request
+		records, err := storerNodeRecorder.Records(storerNodeAddr, "retrieval", "1.0.0", "retrieval")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l := len(records); l != 1 {
+			t.Fatalf("got %v records, want %v", l, 1)
+		}
+	})
+}
+
 type mockPeerSuggester struct {
 	eachPeerRevFunc func(f topology.EachPeerFunc) error
 }
@@ -132,3 +305,11 @@ func (s mockPeerSuggester) EachPeer(topology.EachPeerFunc) error {
 func (s mockPeerSuggester) EachPeerRev(f topology.EachPeerFunc) error {
 	return s.eachPeerRevFunc(f)
 }
+
+type mockValidator struct{}
+
+func (mockValidator) Validate(swarm.Chunk) bool { return true }
+
+type validatorFunc func(swarm.Chunk) bool
+
+func (f validatorFunc) Validate(ch swarm.Chunk) bool { return f(ch) }
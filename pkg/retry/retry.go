@@ -0,0 +1,141 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package retry provides policies that decide how long to wait before an
+// operation that failed is attempted again.
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Names of the policies that can be constructed with New.
+const (
+	PolicyConstant           = "constant"
+	PolicyExponential        = "exponential"
+	PolicyDecorrelatedJitter = "jitter"
+)
+
+// ErrUnknownPolicy is returned by New if the policy name is not supported.
+var ErrUnknownPolicy = errors.New("unknown retry policy")
+
+// Policy decides the delay before retrying an operation.
+type Policy interface {
+	// Delay returns the time to wait before the given attempt, counted from
+	// one, where previous is the delay returned for the preceding attempt,
+	// or zero for the first one.
+	Delay(attempt int, previous time.Duration) time.Duration
+}
+
+// New constructs the policy with the given name. The delay is the base delay
+// of the policy and maxDelay caps the delays of the policies that grow.
+func New(name string, delay, maxDelay time.Duration) (Policy, error) {
+	if delay <= 0 {
+		return nil, fmt.Errorf("invalid retry delay %s", delay)
+	}
+	if maxDelay < delay {
+		maxDelay = delay
+	}
+	switch name {
+	case PolicyConstant:
+		return Constant(delay), nil
+	case PolicyExponential:
+		return Exponential(delay, maxDelay), nil
+	case PolicyDecorrelatedJitter:
+		return DecorrelatedJitter(delay, maxDelay), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownPolicy, name)
+}
+
+type constant time.Duration
+
+// Constant returns a policy that waits the same delay before every attempt.
+func Constant(delay time.Duration) Policy {
+	return constant(delay)
+}
+
+func (c constant) Delay(int, time.Duration) time.Duration {
+	return time.Duration(c)
+}
+
+type exponential struct {
+	base, max time.Duration
+}
+
+// Exponential returns a policy that doubles the delay with every attempt,
+// starting from the base delay, up to the maximal delay.
+func Exponential(base, max time.Duration) Policy {
+	return exponential{base: base, max: max}
+}
+
+func (e exponential) Delay(attempt int, _ time.Duration) time.Duration {
+	d := e.base
+	for i := 1; i < attempt && d < e.max; i++ {
+		d *= 2
+	}
+	if d > e.max {
+		return e.max
+	}
+	return d
+}
+
+type decorrelatedJitter struct {
+	base, max time.Duration
+	mu        sync.Mutex
+	rand      *rand.Rand
+}
+
+// DecorrelatedJitter returns a policy that waits a random delay between the
+// base delay and three times the previous delay, up to the maximal delay, so
+// that the retries of many failed operations are spread over time.
+func DecorrelatedJitter(base, max time.Duration) Policy {
+	return &decorrelatedJitter{
+		base: base,
+		max:  max,
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (j *decorrelatedJitter) Delay(_ int, previous time.Duration) time.Duration {
+	if previous < j.base {
+		previous = j.base
+	}
+	j.mu.Lock()
+	d := j.base + time.Duration(j.rand.Int63n(int64(3*previous-j.base)+1))
+	j.mu.Unlock()
+	if d > j.max {
+		return j.max
+	}
+	return d
+}
+
+// Backoff tracks the consecutive attempts of a single retried operation.
+// It is not safe for concurrent use.
+type Backoff struct {
+	policy  Policy
+	attempt int
+	delay   time.Duration
+}
+
+// NewBackoff returns a Backoff that delays the attempts by the policy.
+func NewBackoff(p Policy) *Backoff {
+	return &Backoff{policy: p}
+}
+
+// Next counts an attempt and returns the delay to wait before it.
+func (b *Backoff) Next() time.Duration {
+	b.attempt++
+	b.delay = b.policy.Delay(b.attempt, b.delay)
+	return b.delay
+}
+
+// Reset starts counting the attempts from the beginning.
+func (b *Backoff) Reset() {
+	b.attempt = 0
+	b.delay = 0
+}
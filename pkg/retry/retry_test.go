@@ -0,0 +1,95 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package retry_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/retry"
+)
+
+func TestConstant(t *testing.T) {
+	p := retry.Constant(time.Second)
+	for attempt := 1; attempt < 10; attempt++ {
+		if got := p.Delay(attempt, time.Second); got != time.Second {
+			t.Fatalf("attempt %d: got delay %s, want %s", attempt, got, time.Second)
+		}
+	}
+}
+
+func TestExponential(t *testing.T) {
+	p := retry.Exponential(time.Second, 10*time.Second)
+	for _, tc := range []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 8 * time.Second},
+		{attempt: 5, want: 10 * time.Second},
+		{attempt: 100, want: 10 * time.Second},
+	} {
+		if got := p.Delay(tc.attempt, 0); got != tc.want {
+			t.Errorf("attempt %d: got delay %s, want %s", tc.attempt, got, tc.want)
+		}
+	}
+}
+
+func TestDecorrelatedJitter(t *testing.T) {
+	base, max := 10*time.Millisecond, time.Second
+	b := retry.NewBackoff(retry.DecorrelatedJitter(base, max))
+
+	var previous time.Duration
+	for i := 0; i < 100; i++ {
+		d := b.Next()
+		if d < base || d > max {
+			t.Fatalf("got delay %s out of range [%s, %s]", d, base, max)
+		}
+		if previous > 0 && d > 3*previous {
+			t.Fatalf("got delay %s, more than three times the previous delay %s", d, previous)
+		}
+		previous = d
+	}
+
+	b.Reset()
+	if d := b.Next(); d > 3*base {
+		t.Fatalf("got delay %s after reset, want at most %s", d, 3*base)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := retry.NewBackoff(retry.Exponential(time.Second, time.Minute))
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		if got := b.Next(); got != want {
+			t.Fatalf("got delay %s, want %s", got, want)
+		}
+	}
+	b.Reset()
+	if got := b.Next(); got != time.Second {
+		t.Fatalf("got delay %s after reset, want %s", got, time.Second)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{retry.PolicyConstant, retry.PolicyExponential, retry.PolicyDecorrelatedJitter} {
+		p, err := retry.New(name, time.Second, time.Minute)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if d := p.Delay(1, 0); d < time.Second || d > 3*time.Second {
+			t.Fatalf("%s: got first delay %s", name, d)
+		}
+	}
+
+	if _, err := retry.New("linear", time.Second, time.Minute); !errors.Is(err, retry.ErrUnknownPolicy) {
+		t.Fatalf("got error %v, want %v", err, retry.ErrUnknownPolicy)
+	}
+	if _, err := retry.New(retry.PolicyConstant, 0, time.Minute); err == nil {
+		t.Fatal("expected error for zero delay")
+	}
+}
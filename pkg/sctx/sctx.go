@@ -10,6 +10,7 @@ type (
 	HTTPRequestIDKey struct{}
 	requestHostKey   struct{}
 	tagKey           struct{}
+	targetsKey       struct{}
 )
 
 // SetHost sets the http request host in the context
@@ -39,3 +40,17 @@ func GetTag(ctx context.Context) uint32 {
 	}
 	return 0
 }
+
+// SetTargets sets the recovery targets in the context
+func SetTargets(ctx context.Context, targets [][]byte) context.Context {
+	return context.WithValue(ctx, targetsKey{}, targets)
+}
+
+// GetTargets gets the recovery targets from the context
+func GetTargets(ctx context.Context) [][]byte {
+	v, ok := ctx.Value(targetsKey{}).([][]byte)
+	if ok {
+		return v
+	}
+	return nil
+}
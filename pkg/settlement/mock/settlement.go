@@ -0,0 +1,84 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ settlement.Interface = (*Settlement)(nil)
+
+// Settlement keeps the settled amounts in memory. All payments are
+// accepted in full.
+type Settlement struct {
+	sent     map[string]uint64
+	received map[string]uint64
+	mu       sync.Mutex
+}
+
+func NewSettlement() *Settlement {
+	return &Settlement{
+		sent:     make(map[string]uint64),
+		received: make(map[string]uint64),
+	}
+}
+
+func (s *Settlement) Pay(_ context.Context, peer swarm.Address, amount uint64) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sent[peer.String()] += amount
+	return amount, nil
+}
+
+// Receive records the amount as received from the peer.
+func (s *Settlement) Receive(peer swarm.Address, amount uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.received[peer.String()] += amount
+}
+
+func (s *Settlement) TotalSent(peer swarm.Address) (uint64, error) {
+	return s.total(s.sent, peer)
+}
+
+func (s *Settlement) TotalReceived(peer swarm.Address) (uint64, error) {
+	return s.total(s.received, peer)
+}
+
+func (s *Settlement) SettlementsSent() (map[string]uint64, error) {
+	return s.totals(s.sent), nil
+}
+
+func (s *Settlement) SettlementsReceived() (map[string]uint64, error) {
+	return s.totals(s.received), nil
+}
+
+func (s *Settlement) total(totals map[string]uint64, peer swarm.Address) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total, ok := totals[peer.String()]
+	if !ok {
+		return 0, settlement.ErrPeerNoSettlements
+	}
+	return total, nil
+}
+
+func (s *Settlement) totals(totals map[string]uint64) map[string]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := make(map[string]uint64, len(totals))
+	for k, v := range totals {
+		m[k] = v
+	}
+	return m
+}
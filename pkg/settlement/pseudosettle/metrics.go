@@ -0,0 +1,55 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pseudosettle
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	// all metrics fields must be exported
+	// to be able to return them by Metrics()
+	// using reflection
+	SentSettlementsCount     prometheus.Counter
+	ReceivedSettlementsCount prometheus.Counter
+	TotalSentAmount          prometheus.Counter
+	TotalReceivedAmount      prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "pseudosettle"
+
+	return metrics{
+		SentSettlementsCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "sent_settlements_count",
+			Help:      "Number of settlements sent to peers.",
+		}),
+		ReceivedSettlementsCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "received_settlements_count",
+			Help:      "Number of settlements received from peers.",
+		}),
+		TotalSentAmount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_sent_amount",
+			Help:      "Amount settled with peers and accepted by them.",
+		}),
+		TotalReceivedAmount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_received_amount",
+			Help:      "Amount settled by peers and accepted from them.",
+		}),
+	}
+}
+
+func (s *Service) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
@@ -0,0 +1,34 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p/protobuf/protobuftest"
+	"github.com/ethersphere/bee/pkg/settlement/pseudosettle/pb"
+)
+
+// TestMessages checks that the wire format of the messages is compatible
+// with the released version of the protocol.
+func TestMessages(t *testing.T) {
+	protobuftest.Check(t,
+		protobuftest.Message{
+			Message: &pb.Payment{Amount: 100},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Amount", WireType: "varint"},
+			},
+			Wire: "0864",
+		},
+		protobuftest.Message{
+			Message: &pb.PaymentAck{Amount: 100, Timestamp: 1000},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Amount", WireType: "varint"},
+				{Number: 2, Name: "Timestamp", WireType: "varint"},
+			},
+			Wire: "086410e807",
+		},
+	)
+}
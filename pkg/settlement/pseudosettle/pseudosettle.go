@@ -0,0 +1,312 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pseudosettle implements a settlement protocol that clears the
+// debts to peers without an actual payment, as a placeholder for the real
+// payments. A peer accepts the settlements of another peer only up to the
+// amount that accrues at the refresh rate since the previous settlement, or
+// since the first settlement attempt of the peer, for at most the refresh
+// window.
+package pseudosettle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/clock"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/protobuf"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/pseudosettle/pb"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	protocolName    = "pseudosettle"
+	protocolVersion = "1.0.0"
+	streamName      = "pseudosettle"
+)
+
+const (
+	totalSentPrefix     = "pseudosettle_total_sent_"
+	totalReceivedPrefix = "pseudosettle_total_received_"
+	lastReceivedPrefix  = "pseudosettle_last_received_"
+)
+
+// refreshWindow is the longest time that the refresh allowance of a peer
+// accrues for, so that a peer that did not settle for a long time can not
+// clear a large debt at once.
+const refreshWindow = time.Minute
+
+var (
+	// ErrNoPaymentObserver is returned by the protocol handler if the
+	// payment observer is not set.
+	ErrNoPaymentObserver = errors.New("no payment observer")
+	// ErrOverAccepted is returned by Pay if the peer acknowledges a greater
+	// amount than the paid one.
+	ErrOverAccepted = errors.New("accepted amount greater than paid")
+)
+
+var _ settlement.Interface = (*Service)(nil)
+
+// Service is the pseudosettle protocol.
+type Service struct {
+	streamer    p2p.Streamer
+	store       storage.StateStorer
+	refreshRate uint64
+	logger      logging.Logger
+	clock       clock.Clock
+	metrics     metrics
+
+	observer settlement.PaymentObserver
+	mu       sync.Mutex // guards the stored totals and last received times
+}
+
+// Options are the options for the Service.
+type Options struct {
+	Streamer p2p.Streamer
+	Store    storage.StateStorer
+	// RefreshRate is the amount per second that can be settled by a peer.
+	// The settlements are accepted in full if it is zero.
+	RefreshRate uint64
+	Logger      logging.Logger
+	// Clock times the refresh allowance of the peers, the system clock if
+	// it is not set.
+	Clock clock.Clock
+}
+
+// New returns a new pseudosettle Service.
+func New(o Options) *Service {
+	if o.Clock == nil {
+		o.Clock = clock.System
+	}
+	return &Service{
+		streamer:    o.Streamer,
+		store:       o.Store,
+		refreshRate: o.RefreshRate,
+		logger:      o.Logger,
+		clock:       o.Clock,
+		metrics:     newMetrics(),
+	}
+}
+
+// SetPaymentObserver sets the observer of the settlements received from
+// peers. It must be called before the protocol is served.
+func (s *Service) SetPaymentObserver(observer settlement.PaymentObserver) {
+	s.observer = observer
+}
+
+func (s *Service) Protocol() p2p.ProtocolSpec {
+	return p2p.ProtocolSpec{
+		Name:    protocolName,
+		Version: protocolVersion,
+		StreamSpecs: []p2p.StreamSpec{
+			{
+				Name:    streamName,
+				Handler: s.handler,
+			},
+		},
+	}
+}
+
+// handler accepts the settlement of the peer up to its refresh allowance
+// and acknowledges the accepted amount.
+func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	w, r := protobuf.NewWriterAndReader(stream)
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	var req pb.Payment
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		return fmt.Errorf("read request from peer %s: %w", p.Address, err)
+	}
+	s.metrics.ReceivedSettlementsCount.Inc()
+
+	accepted, now, err := s.accept(p.Address, req.Amount)
+	if err != nil {
+		return fmt.Errorf("accept settlement of %d from peer %s: %w", req.Amount, p.Address, err)
+	}
+	s.logger.Tracef("pseudosettle: accepted %d of %d from peer %s", accepted, req.Amount, p.Address)
+
+	if err := w.WriteMsgWithContext(ctx, &pb.PaymentAck{
+		Amount:    accepted,
+		Timestamp: now.Unix(),
+	}); err != nil {
+		return fmt.Errorf("write ack to peer %s: %w", p.Address, err)
+	}
+	return nil
+}
+
+// accept limits the amount to the refresh allowance of the peer, and
+// records the accepted amount as received. The allowance of a peer that has
+// not settled before starts to accrue from its first settlement attempt.
+func (s *Service) accept(peer swarm.Address, amount uint64) (accepted uint64, now time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now = s.clock.Now()
+	accepted = amount
+	if s.refreshRate > 0 {
+		var last time.Time
+		if err := s.store.Get(lastReceivedKey(peer), &last); err != nil {
+			if !errors.Is(err, storage.ErrNotFound) {
+				return 0, now, err
+			}
+			if err := s.store.Put(lastReceivedKey(peer), now); err != nil {
+				return 0, now, err
+			}
+			last = now
+		}
+		elapsed := now.Sub(last)
+		if elapsed > refreshWindow {
+			elapsed = refreshWindow
+		}
+		allowance := uint64(elapsed.Seconds() * float64(s.refreshRate))
+		if accepted > allowance {
+			accepted = allowance
+		}
+	}
+	if accepted == 0 {
+		return 0, now, nil
+	}
+
+	if s.observer == nil {
+		return 0, now, ErrNoPaymentObserver
+	}
+	if err := s.observer.NotifyPayment(peer, accepted); err != nil {
+		return 0, now, err
+	}
+	if err := s.addTotal(totalReceivedPrefix, peer, accepted); err != nil {
+		return 0, now, err
+	}
+	if err := s.store.Put(lastReceivedKey(peer), now); err != nil {
+		return 0, now, err
+	}
+	s.metrics.TotalReceivedAmount.Add(float64(accepted))
+
+	return accepted, now, nil
+}
+
+// Pay sends the settlement of the amount to the peer and returns the
+// amount that the peer accepted.
+func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount uint64) (accepted uint64, err error) {
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
+	if err != nil {
+		return 0, fmt.Errorf("new stream: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	w, r := protobuf.NewWriterAndReader(stream)
+	if err := w.WriteMsgWithContext(ctx, &pb.Payment{
+		Amount: amount,
+	}); err != nil {
+		return 0, fmt.Errorf("write request: %w", err)
+	}
+	s.metrics.SentSettlementsCount.Inc()
+
+	var ack pb.PaymentAck
+	if err := r.ReadMsgWithContext(ctx, &ack); err != nil {
+		return 0, fmt.Errorf("read ack: %w", err)
+	}
+	if ack.Amount > amount {
+		return 0, fmt.Errorf("peer %s accepted %d of %d: %w", peer, ack.Amount, amount, ErrOverAccepted)
+	}
+	s.logger.Tracef("pseudosettle: peer %s accepted %d of %d", peer, ack.Amount, amount)
+
+	if ack.Amount > 0 {
+		s.mu.Lock()
+		err = s.addTotal(totalSentPrefix, peer, ack.Amount)
+		s.mu.Unlock()
+		if err != nil {
+			return 0, err
+		}
+		s.metrics.TotalSentAmount.Add(float64(ack.Amount))
+	}
+
+	return ack.Amount, nil
+}
+
+// TotalSent returns the total amount settled with the peer.
+func (s *Service) TotalSent(peer swarm.Address) (uint64, error) {
+	return s.total(totalSentPrefix, peer)
+}
+
+// TotalReceived returns the total amount accepted from the peer.
+func (s *Service) TotalReceived(peer swarm.Address) (uint64, error) {
+	return s.total(totalReceivedPrefix, peer)
+}
+
+// SettlementsSent returns the total amounts settled with all peers.
+func (s *Service) SettlementsSent() (map[string]uint64, error) {
+	return s.totals(totalSentPrefix)
+}
+
+// SettlementsReceived returns the total amounts accepted from all peers.
+func (s *Service) SettlementsReceived() (map[string]uint64, error) {
+	return s.totals(totalReceivedPrefix)
+}
+
+// addTotal adds the amount to the stored total with the peer. It must be
+// called with the lock held.
+func (s *Service) addTotal(prefix string, peer swarm.Address, amount uint64) error {
+	total, err := s.total(prefix, peer)
+	if err != nil && !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		return err
+	}
+	return s.store.Put(prefix+peer.String(), total+amount)
+}
+
+func lastReceivedKey(peer swarm.Address) string {
+	return lastReceivedPrefix + peer.String()
+}
+
+func (s *Service) total(prefix string, peer swarm.Address) (total uint64, err error) {
+	if err := s.store.Get(prefix+peer.String(), &total); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return 0, settlement.ErrPeerNoSettlements
+		}
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *Service) totals(prefix string) (map[string]uint64, error) {
+	totals := make(map[string]uint64)
+	err := s.store.Iterate(prefix, func(key, value []byte) (stop bool, err error) {
+		peer, err := swarm.ParseHexAddress(strings.TrimPrefix(string(key), prefix))
+		if err != nil {
+			return true, fmt.Errorf("parse total key %q: %w", key, err)
+		}
+
+		var total uint64
+		if err := json.Unmarshal(value, &total); err != nil {
+			return true, err
+		}
+
+		totals[peer.String()] = total
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
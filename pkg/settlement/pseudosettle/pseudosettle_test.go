@@ -0,0 +1,173 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pseudosettle_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	clockmock "github.com/ethersphere/bee/pkg/clock/mock"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/streamtest"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/pseudosettle"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var (
+	payer    = swarm.MustParseHexAddress("00112233")
+	receiver = swarm.MustParseHexAddress("00112244")
+)
+
+type paymentObserverFunc func(peer swarm.Address, amount uint64) error
+
+func (f paymentObserverFunc) NotifyPayment(peer swarm.Address, amount uint64) error {
+	return f(peer, amount)
+}
+
+func TestPay(t *testing.T) {
+	var notified uint64
+	receiverService := newService(0, nil)
+	receiverService.SetPaymentObserver(paymentObserverFunc(func(peer swarm.Address, amount uint64) error {
+		if !peer.Equal(payer) {
+			t.Fatalf("got payment from peer %s, want %s", peer, payer)
+		}
+		notified += amount
+		return nil
+	}))
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(receiverService.Protocol()),
+		streamtest.WithBaseAddr(payer),
+	)
+	payerService := newService(0, recorder)
+
+	for _, amount := range []uint64{100, 50} {
+		accepted, err := payerService.Pay(context.Background(), receiver, amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if accepted != amount {
+			t.Fatalf("got accepted %d, want %d", accepted, amount)
+		}
+	}
+	if notified != 150 {
+		t.Fatalf("got notified %d, want %d", notified, 150)
+	}
+
+	assertTotal(t, payerService.TotalSent, receiver, 150)
+	assertTotal(t, receiverService.TotalReceived, payer, 150)
+
+	if _, err := payerService.TotalReceived(receiver); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+
+	sent, err := payerService.SettlementsSent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[receiver.String()] != 150 {
+		t.Fatalf("got settlements sent %v", sent)
+	}
+	received, err := receiverService.SettlementsReceived()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[payer.String()] != 150 {
+		t.Fatalf("got settlements received %v", received)
+	}
+}
+
+func TestPayRefreshRate(t *testing.T) {
+	clock := clockmock.New(time.Unix(1000, 0))
+	receiverService := pseudosettle.New(pseudosettle.Options{
+		Store:       statestore.NewStateStore(),
+		RefreshRate: 10,
+		Logger:      logging.New(ioutil.Discard, 0),
+		Clock:       clock,
+	})
+	receiverService.SetPaymentObserver(paymentObserverFunc(func(swarm.Address, uint64) error {
+		return nil
+	}))
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(receiverService.Protocol()),
+		streamtest.WithBaseAddr(payer),
+	)
+	payerService := newService(0, recorder)
+
+	for _, tc := range []struct {
+		elapsed time.Duration
+		amount  uint64
+		want    uint64
+	}{
+		// the allowance of a new peer starts to accrue now
+		{elapsed: 0, amount: 100, want: 0},
+		{elapsed: 5 * time.Second, amount: 100, want: 50},
+		{elapsed: 0, amount: 100, want: 0},
+		{elapsed: 20 * time.Second, amount: 100, want: 100},
+		// the allowance accrues for at most the refresh window
+		{elapsed: time.Hour, amount: 1000, want: 600},
+	} {
+		clock.Add(tc.elapsed)
+
+		accepted, err := payerService.Pay(context.Background(), receiver, tc.amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if accepted != tc.want {
+			t.Fatalf("after %v: got accepted %d, want %d", tc.elapsed, accepted, tc.want)
+		}
+	}
+
+	assertTotal(t, payerService.TotalSent, receiver, 750)
+	assertTotal(t, receiverService.TotalReceived, payer, 750)
+}
+
+func TestPayRejected(t *testing.T) {
+	receiverService := newService(0, nil)
+	receiverService.SetPaymentObserver(paymentObserverFunc(func(swarm.Address, uint64) error {
+		return errors.New("overpayment")
+	}))
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(receiverService.Protocol()),
+		streamtest.WithBaseAddr(payer),
+	)
+	payerService := newService(0, recorder)
+
+	if _, err := payerService.Pay(context.Background(), receiver, 100); err == nil {
+		t.Fatal("got no error")
+	}
+	if _, err := payerService.TotalSent(receiver); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+}
+
+func newService(refreshRate uint64, streamer p2p.Streamer) *pseudosettle.Service {
+	return pseudosettle.New(pseudosettle.Options{
+		Streamer:    streamer,
+		Store:       statestore.NewStateStore(),
+		RefreshRate: refreshRate,
+		Logger:      logging.New(ioutil.Discard, 0),
+	})
+}
+
+func assertTotal(t *testing.T, f func(swarm.Address) (uint64, error), peer swarm.Address, want uint64) {
+	t.Helper()
+
+	got, err := f(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got total %d with peer %s, want %d", got, peer, want)
+	}
+}
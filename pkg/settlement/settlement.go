@@ -0,0 +1,41 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package settlement provides the interfaces for clearing the balances with
+// peers that are kept by the accounting.
+package settlement
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ErrPeerNoSettlements is returned if there are no settlements with the
+// peer.
+var ErrPeerNoSettlements = errors.New("no settlements for peer")
+
+// Interface is the settlement of the debts to peers.
+type Interface interface {
+	// Pay pays the amount to the peer and returns the part of it that is
+	// accepted by the peer.
+	Pay(ctx context.Context, peer swarm.Address, amount uint64) (accepted uint64, err error)
+	// TotalSent returns the total amount paid to the peer.
+	TotalSent(peer swarm.Address) (uint64, error)
+	// TotalReceived returns the total amount received from the peer.
+	TotalReceived(peer swarm.Address) (uint64, error)
+	// SettlementsSent returns the total amounts paid to all peers, keyed by
+	// the hex encoded peer address.
+	SettlementsSent() (map[string]uint64, error)
+	// SettlementsReceived returns the total amounts received from all
+	// peers, keyed by the hex encoded peer address.
+	SettlementsReceived() (map[string]uint64, error)
+}
+
+// PaymentObserver is notified about the payments received from peers.
+type PaymentObserver interface {
+	// NotifyPayment is called when the amount is received from the peer.
+	NotifyPayment(peer swarm.Address, amount uint64) error
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"golang.org/x/crypto/sha3"
+)
+
+// AddressLength is the length of the chain addresses of the chequebooks and
+// the beneficiaries.
+const AddressLength = 20
+
+// ErrInvalidAddress is returned if an address in a cheque does not have the
+// length of the chain addresses.
+var ErrInvalidAddress = errors.New("invalid address")
+
+// chequeDomainSeparator is hashed together with the cheque, so that the
+// signature of a cheque can not be obtained by signing a digest for any other
+// purpose with the key of the chequebook owner. It is the hash of a fixed
+// string, not an EIP-712 domain separator.
+var chequeDomainSeparator = keccak256([]byte("Swarm Chequebook Cheque v1"))
+
+// Cheque is the promise of the chequebook owner to pay the cumulative payout
+// to the beneficiary. Every new cheque to the same beneficiary replaces the
+// previous one, so only the last cheque needs to be cashed.
+type Cheque struct {
+	Chequebook       []byte `json:"chequebook"`
+	Beneficiary      []byte `json:"beneficiary"`
+	CumulativePayout uint64 `json:"cumulativePayout"`
+}
+
+// SignedCheque is the cheque with the signature of the chequebook owner.
+type SignedCheque struct {
+	Cheque
+	Signature []byte `json:"signature"`
+}
+
+// Equal reports whether the cheques are the same.
+func (c *Cheque) Equal(o *Cheque) bool {
+	return bytes.Equal(c.Chequebook, o.Chequebook) &&
+		bytes.Equal(c.Beneficiary, o.Beneficiary) &&
+		c.CumulativePayout == o.CumulativePayout
+}
+
+// Hash returns the hash of the cheque that is signed by the chequebook
+// owner: the keccak256 hash of the "\x19\x01" prefix, the domain separator
+// and the hash of the cheque fields. It is not compatible with the hashes
+// that the chequebook contracts verify.
+func (c *Cheque) Hash() ([]byte, error) {
+	if len(c.Chequebook) != AddressLength || len(c.Beneficiary) != AddressLength {
+		return nil, ErrInvalidAddress
+	}
+
+	// the payout is encoded as a 256 bit big endian integer, as in the
+	// contract
+	payout := make([]byte, 32)
+	binary.BigEndian.PutUint64(payout[24:], c.CumulativePayout)
+
+	structHash := keccak256(c.Chequebook, c.Beneficiary, payout)
+
+	return keccak256([]byte{0x19, 0x01}, chequeDomainSeparator, structHash), nil
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		_, _ = h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// Sign signs the cheque with the key of the chequebook owner. The signature
+// is in the compact format of the signer, with the recovery byte first.
+func Sign(signer crypto.Signer, cheque *Cheque) (*SignedCheque, error) {
+	hash, err := cheque.Hash()
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(hash)
+	if err != nil {
+		return nil, fmt.Errorf("sign cheque: %w", err)
+	}
+	return &SignedCheque{
+		Cheque:    *cheque,
+		Signature: signature,
+	}, nil
+}
+
+// RecoverIssuer returns the chain address of the key that signed the
+// cheque.
+func RecoverIssuer(cheque *SignedCheque) ([]byte, error) {
+	hash, err := cheque.Hash()
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := crypto.Recover(cheque.Signature, hash)
+	if err != nil {
+		return nil, fmt.Errorf("recover cheque signer: %w", err)
+	}
+	return crypto.NewEthereumAddress(*publicKey)
+}
@@ -0,0 +1,197 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package chequebook issues the cheques of the chequebook contract of the
+// node, and provides the bindings of the chequebook contracts of the peers.
+//
+// The package is experimental. The cheque hashes and signatures are not
+// those that the deployed chequebook contracts verify, no contract binding is
+// implemented, and the node is not started with a chequebook, so its
+// endpoints of the debug API are not implemented.
+package chequebook
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+const (
+	lastIssuedChequePrefix = "chequebook_last_issued_cheque_"
+	totalIssuedKey         = "chequebook_total_issued"
+)
+
+var (
+	// ErrInsufficientFunds is returned by Issue if the chequebook does not
+	// hold enough funds for the cheque, with all issued cheques that are
+	// not cashed yet.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrNoCheque is returned by LastCheque if no cheque is issued to the
+	// beneficiary.
+	ErrNoCheque = errors.New("no cheque")
+)
+
+// Service is the chequebook of the node.
+type Service interface {
+	// Address returns the chain address of the chequebook contract.
+	Address() []byte
+	// Balance returns the amount held by the chequebook.
+	Balance(ctx context.Context) (uint64, error)
+	// AvailableBalance returns the amount held by the chequebook that is
+	// not promised by the issued cheques.
+	AvailableBalance(ctx context.Context) (uint64, error)
+	// Issue issues the cheque that promises the amount to the beneficiary,
+	// in addition to the previous cheques.
+	Issue(ctx context.Context, beneficiary []byte, amount uint64) (*SignedCheque, error)
+	// LastCheque returns the last cheque issued to the beneficiary.
+	LastCheque(beneficiary []byte) (*SignedCheque, error)
+}
+
+var _ Service = (*chequebook)(nil)
+
+type chequebook struct {
+	address  []byte
+	contract Contract
+	store    storage.StateStorer
+	signer   crypto.Signer
+	mu       sync.Mutex
+}
+
+// Options are the options for the chequebook Service.
+type Options struct {
+	// Address is the chain address of the chequebook contract.
+	Address  []byte
+	Contract Contract
+	Store    storage.StateStorer
+	// Signer signs the cheques. Its key must be the one of the issuer of
+	// the chequebook contract.
+	Signer crypto.Signer
+}
+
+// New returns the chequebook Service for the deployed chequebook contract.
+func New(o Options) (Service, error) {
+	if len(o.Address) != AddressLength {
+		return nil, ErrInvalidAddress
+	}
+	return &chequebook{
+		address:  o.Address,
+		contract: o.Contract,
+		store:    o.Store,
+		signer:   o.Signer,
+	}, nil
+}
+
+func (c *chequebook) Address() []byte {
+	return c.address
+}
+
+func (c *chequebook) Balance(ctx context.Context) (uint64, error) {
+	return c.contract.Balance(ctx)
+}
+
+func (c *chequebook) AvailableBalance(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.availableBalance(ctx)
+}
+
+// availableBalance returns the balance less the amount that is issued, but
+// not paid out yet. It must be called with the lock held.
+func (c *chequebook) availableBalance(ctx context.Context) (uint64, error) {
+	balance, err := c.contract.Balance(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get balance: %w", err)
+	}
+	paidOut, err := c.contract.TotalPaidOut(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get total paid out: %w", err)
+	}
+	var issued uint64
+	if err := c.store.Get(totalIssuedKey, &issued); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return 0, fmt.Errorf("get total issued: %w", err)
+	}
+
+	// the balance is already reduced by the paid out amount
+	if issued < paidOut {
+		return balance, nil
+	}
+	if outstanding := issued - paidOut; outstanding < balance {
+		return balance - outstanding, nil
+	}
+	return 0, nil
+}
+
+func (c *chequebook) Issue(ctx context.Context, beneficiary []byte, amount uint64) (*SignedCheque, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	available, err := c.availableBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if amount > available {
+		return nil, ErrInsufficientFunds
+	}
+
+	var cumulativePayout uint64
+	last, err := c.lastCheque(beneficiary)
+	switch {
+	case err == nil:
+		cumulativePayout = last.CumulativePayout
+	case errors.Is(err, ErrNoCheque):
+	default:
+		return nil, err
+	}
+
+	cheque, err := Sign(c.signer, &Cheque{
+		Chequebook:       c.address,
+		Beneficiary:      beneficiary,
+		CumulativePayout: cumulativePayout + amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var issued uint64
+	if err := c.store.Get(totalIssuedKey, &issued); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("get total issued: %w", err)
+	}
+	if err := c.store.Put(lastIssuedChequeKey(beneficiary), cheque); err != nil {
+		return nil, fmt.Errorf("store cheque: %w", err)
+	}
+	if err := c.store.Put(totalIssuedKey, issued+amount); err != nil {
+		return nil, fmt.Errorf("store total issued: %w", err)
+	}
+
+	return cheque, nil
+}
+
+func (c *chequebook) LastCheque(beneficiary []byte) (*SignedCheque, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lastCheque(beneficiary)
+}
+
+// lastCheque must be called with the lock held.
+func (c *chequebook) lastCheque(beneficiary []byte) (*SignedCheque, error) {
+	var cheque SignedCheque
+	if err := c.store.Get(lastIssuedChequeKey(beneficiary), &cheque); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNoCheque
+		}
+		return nil, fmt.Errorf("get last cheque: %w", err)
+	}
+	return &cheque, nil
+}
+
+func lastIssuedChequeKey(beneficiary []byte) string {
+	return lastIssuedChequePrefix + hex.EncodeToString(beneficiary)
+}
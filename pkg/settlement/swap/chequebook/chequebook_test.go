@@ -0,0 +1,179 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+var (
+	chequebookAddress = bytes.Repeat([]byte{1}, chequebook.AddressLength)
+	beneficiary1      = bytes.Repeat([]byte{2}, chequebook.AddressLength)
+	beneficiary2      = bytes.Repeat([]byte{3}, chequebook.AddressLength)
+)
+
+func TestSignCheque(t *testing.T) {
+	signer, issuer := newSigner(t)
+
+	cheque := &chequebook.Cheque{
+		Chequebook:       chequebookAddress,
+		Beneficiary:      beneficiary1,
+		CumulativePayout: 100,
+	}
+	signed, err := chequebook.Sign(signer, cheque)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signed.Equal(cheque) {
+		t.Fatalf("got cheque %+v, want %+v", signed.Cheque, cheque)
+	}
+
+	got, err := chequebook.RecoverIssuer(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, issuer) {
+		t.Fatalf("got issuer %x, want %x", got, issuer)
+	}
+
+	// a signature of the cheque hash as a signed message is not a valid
+	// cheque signature
+	hash, err := cheque.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := crypto.HashSignedMessage(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := signer.Sign(digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = chequebook.RecoverIssuer(&chequebook.SignedCheque{
+		Cheque:    *cheque,
+		Signature: signature,
+	})
+	if err == nil && bytes.Equal(got, issuer) {
+		t.Fatal("recovered the issuer from the signed message")
+	}
+
+	// the signature does not match a cheque with another payout
+	signed.CumulativePayout = 200
+	got, err = chequebook.RecoverIssuer(signed)
+	if err == nil && bytes.Equal(got, issuer) {
+		t.Fatal("recovered the issuer of the changed cheque")
+	}
+
+	if _, err := chequebook.Sign(signer, &chequebook.Cheque{
+		Chequebook:  chequebookAddress[1:],
+		Beneficiary: beneficiary1,
+	}); !errors.Is(err, chequebook.ErrInvalidAddress) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrInvalidAddress)
+	}
+}
+
+func TestIssue(t *testing.T) {
+	ctx := context.Background()
+	signer, issuer := newSigner(t)
+	contract := mock.NewBackend().Deploy(chequebookAddress, issuer, 100)
+
+	book, err := chequebook.New(chequebook.Options{
+		Address:  chequebookAddress,
+		Contract: contract,
+		Store:    statestore.NewStateStore(),
+		Signer:   signer,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := book.LastCheque(beneficiary1); !errors.Is(err, chequebook.ErrNoCheque) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrNoCheque)
+	}
+
+	// the cheques to the same beneficiary are cumulative
+	for _, tc := range []struct {
+		beneficiary []byte
+		amount      uint64
+		want        uint64
+	}{
+		{beneficiary: beneficiary1, amount: 30, want: 30},
+		{beneficiary: beneficiary1, amount: 20, want: 50},
+		{beneficiary: beneficiary2, amount: 40, want: 40},
+	} {
+		cheque, err := book.Issue(ctx, tc.beneficiary, tc.amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cheque.CumulativePayout != tc.want {
+			t.Fatalf("got cumulative payout %d, want %d", cheque.CumulativePayout, tc.want)
+		}
+		last, err := book.LastCheque(tc.beneficiary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !last.Equal(&cheque.Cheque) {
+			t.Fatalf("got last cheque %+v, want %+v", last.Cheque, cheque.Cheque)
+		}
+	}
+	assertAvailableBalance(t, book, 10)
+
+	// the issued cheques can not exceed the balance
+	if _, err := book.Issue(ctx, beneficiary2, 20); !errors.Is(err, chequebook.ErrInsufficientFunds) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrInsufficientFunds)
+	}
+
+	// the cashed cheques are no longer outstanding
+	last, err := book.LastCheque(beneficiary1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := contract.CashCheque(ctx, last); err != nil {
+		t.Fatal(err)
+	}
+	balance, err := book.Balance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 50 {
+		t.Fatalf("got balance %d, want %d", balance, 50)
+	}
+	assertAvailableBalance(t, book, 10)
+}
+
+func newSigner(t *testing.T) (signer crypto.Signer, address []byte) {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err = crypto.NewEthereumAddress(key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.NewDefaultSigner(key), address
+}
+
+func assertAvailableBalance(t *testing.T, book chequebook.Service, want uint64) {
+	t.Helper()
+
+	got, err := book.AvailableBalance(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got available balance %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import "context"
+
+// Contract is the binding of a deployed chequebook contract.
+type Contract interface {
+	// Issuer returns the chain address of the owner of the chequebook, who
+	// signs its cheques.
+	Issuer(ctx context.Context) ([]byte, error)
+	// Balance returns the amount held by the chequebook.
+	Balance(ctx context.Context) (uint64, error)
+	// PaidOut returns the amount that is already paid out to the
+	// beneficiary by cashing its cheques.
+	PaidOut(ctx context.Context, beneficiary []byte) (uint64, error)
+	// TotalPaidOut returns the amount that is already paid out to all
+	// beneficiaries.
+	TotalPaidOut(ctx context.Context) (uint64, error)
+	// CashCheque pays out the cumulative payout of the cheque to its
+	// beneficiary, less the amount that is already paid out to it.
+	CashCheque(ctx context.Context, cheque *SignedCheque) error
+}
+
+// Backend binds the chequebook contracts that are deployed on the chain.
+type Backend interface {
+	// Bind returns the binding of the chequebook contract at the chain
+	// address.
+	Bind(address []byte) (Contract, error)
+}
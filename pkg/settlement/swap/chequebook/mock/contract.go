@@ -0,0 +1,136 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+var (
+	_ chequebook.Backend  = (*Backend)(nil)
+	_ chequebook.Contract = (*Contract)(nil)
+)
+
+var (
+	// ErrNoContract is returned by Bind if no contract is deployed at the
+	// address.
+	ErrNoContract = errors.New("no contract")
+	// ErrBadSignature is returned by CashCheque if the cheque is not signed
+	// by the issuer of the chequebook.
+	ErrBadSignature = errors.New("bad signature")
+	// ErrBounced is returned by CashCheque if the chequebook does not hold
+	// enough funds for the payout.
+	ErrBounced = errors.New("cheque bounced")
+)
+
+// Backend keeps the chequebook contracts in memory.
+type Backend struct {
+	contracts map[string]*Contract
+	mu        sync.Mutex
+}
+
+func NewBackend() *Backend {
+	return &Backend{
+		contracts: make(map[string]*Contract),
+	}
+}
+
+// Deploy deploys the chequebook contract of the issuer with the balance at
+// the address.
+func (b *Backend) Deploy(address, issuer []byte, balance uint64) *Contract {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := &Contract{
+		address: address,
+		issuer:  issuer,
+		balance: balance,
+		paidOut: make(map[string]uint64),
+	}
+	b.contracts[hex.EncodeToString(address)] = c
+	return c
+}
+
+func (b *Backend) Bind(address []byte) (chequebook.Contract, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.contracts[hex.EncodeToString(address)]
+	if !ok {
+		return nil, ErrNoContract
+	}
+	return c, nil
+}
+
+// Contract is the chequebook contract that verifies and cashes the cheques
+// like the deployed one.
+type Contract struct {
+	address      []byte
+	issuer       []byte
+	balance      uint64
+	paidOut      map[string]uint64
+	totalPaidOut uint64
+	mu           sync.Mutex
+}
+
+func (c *Contract) Issuer(_ context.Context) ([]byte, error) {
+	return c.issuer, nil
+}
+
+func (c *Contract) Balance(_ context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.balance, nil
+}
+
+func (c *Contract) PaidOut(_ context.Context, beneficiary []byte) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.paidOut[hex.EncodeToString(beneficiary)], nil
+}
+
+func (c *Contract) TotalPaidOut(_ context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.totalPaidOut, nil
+}
+
+func (c *Contract) CashCheque(_ context.Context, cheque *chequebook.SignedCheque) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !bytes.Equal(cheque.Chequebook, c.address) {
+		return chequebook.ErrInvalidAddress
+	}
+	issuer, err := chequebook.RecoverIssuer(cheque)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(issuer, c.issuer) {
+		return ErrBadSignature
+	}
+
+	key := hex.EncodeToString(cheque.Beneficiary)
+	if cheque.CumulativePayout <= c.paidOut[key] {
+		return nil
+	}
+	payout := cheque.CumulativePayout - c.paidOut[key]
+	if payout > c.balance {
+		return ErrBounced
+	}
+	c.balance -= payout
+	c.paidOut[key] += payout
+	c.totalPaidOut += payout
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	// all metrics fields must be exported
+	// to be able to return them by Metrics()
+	// using reflection
+	SentChequesCount     prometheus.Counter
+	ReceivedChequesCount prometheus.Counter
+	RejectedChequesCount prometheus.Counter
+	TotalSentAmount      prometheus.Counter
+	TotalReceivedAmount  prometheus.Counter
+	CashedAmount         prometheus.Counter
+}
+
+func newMetrics() metrics {
+	subsystem := "swap"
+
+	return metrics{
+		SentChequesCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "sent_cheques_count",
+			Help:      "Number of cheques sent to peers.",
+		}),
+		ReceivedChequesCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "received_cheques_count",
+			Help:      "Number of cheques received from peers.",
+		}),
+		RejectedChequesCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "rejected_cheques_count",
+			Help:      "Number of cheques received from peers that are rejected.",
+		}),
+		TotalSentAmount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_sent_amount",
+			Help:      "Amount of the cheques sent to peers and accepted by them.",
+		}),
+		TotalReceivedAmount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_received_amount",
+			Help:      "Amount of the cheques received from peers and accepted from them.",
+		}),
+		CashedAmount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "cashed_amount",
+			Help:      "Amount paid out by cashing the cheques received from peers.",
+		}),
+	}
+}
+
+func (s *Service) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(s.metrics)
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p/protobuf/protobuftest"
+	"github.com/ethersphere/bee/pkg/settlement/swap/pb"
+)
+
+// TestMessages checks that the wire format of the messages is compatible
+// with the released version of the protocol.
+func TestMessages(t *testing.T) {
+	protobuftest.Check(t,
+		protobuftest.Message{
+			Message: &pb.Handshake{Beneficiary: []byte{0xaa, 0xbb}},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Beneficiary", WireType: "bytes"},
+			},
+			Wire: "0a02aabb",
+		},
+		protobuftest.Message{
+			Message: &pb.EmitCheque{Cheque: []byte("{}")},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Cheque", WireType: "bytes"},
+			},
+			Wire: "0a027b7d",
+		},
+		protobuftest.Message{
+			Message: &pb.ChequeAck{Amount: 100},
+			Released: []protobuftest.Field{
+				{Number: 1, Name: "Amount", WireType: "varint"},
+			},
+			Wire: "0864",
+		},
+	)
+}
@@ -0,0 +1,503 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package swap implements a settlement protocol that pays the debts to peers
+// with the cheques of the chequebook contract of the node. The peers verify
+// the received cheques against their chequebook contracts on the chain and
+// can cash them out later.
+//
+// The package is experimental, as the chequebook package that it is built
+// on, and the node does not settle with it.
+package swap
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/protobuf"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/pb"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	protocolName    = "swap"
+	protocolVersion = "1.0.0"
+	streamName      = "swap"
+)
+
+const (
+	lastSentChequePrefix     = "swap_last_sent_cheque_"
+	lastReceivedChequePrefix = "swap_last_received_cheque_"
+	pendingChequePrefix      = "swap_pending_cheque_"
+	chequebookPayoutPrefix   = "swap_chequebook_payout_"
+)
+
+var (
+	// ErrNoChequebook is returned by Pay if the node does not have a
+	// chequebook.
+	ErrNoChequebook = errors.New("no chequebook")
+	// ErrNoPaymentObserver is returned by the protocol handler if the
+	// payment observer is not set.
+	ErrNoPaymentObserver = errors.New("no payment observer")
+	// ErrWrongBeneficiary is returned by the protocol handler if the
+	// received cheque is not issued to the node.
+	ErrWrongBeneficiary = errors.New("wrong beneficiary")
+	// ErrWrongChequebook is returned by the protocol handler if the
+	// received cheque is not from the chequebook of the previous cheques of
+	// the peer.
+	ErrWrongChequebook = errors.New("wrong chequebook")
+	// ErrInvalidSignature is returned by the protocol handler if the
+	// received cheque is not signed by the issuer of its chequebook.
+	ErrInvalidSignature = errors.New("invalid cheque signature")
+	// ErrChequeValueTooLow is returned by the protocol handler if the
+	// received cheque does not increase the cumulative payout.
+	ErrChequeValueTooLow = errors.New("cheque value too low")
+	// ErrBouncingCheque is returned by the protocol handler if the
+	// chequebook of the received cheque does not hold enough funds for it.
+	ErrBouncingCheque = errors.New("bouncing cheque")
+	// ErrOverAccepted is returned by Pay if the peer acknowledges a greater
+	// amount than the paid one.
+	ErrOverAccepted = errors.New("accepted amount greater than paid")
+)
+
+// Interface is the swap settlement, with the cashing of the received
+// cheques.
+type Interface interface {
+	settlement.Interface
+	// CashCheque cashes the last cheque received from the peer and returns
+	// the amount that is paid out by it.
+	CashCheque(ctx context.Context, peer swarm.Address) (uint64, error)
+}
+
+var _ Interface = (*Service)(nil)
+
+// Service is the swap protocol.
+type Service struct {
+	streamer    p2p.Streamer
+	store       storage.StateStorer
+	chequebook  chequebook.Service
+	backend     chequebook.Backend
+	beneficiary []byte
+	logger      logging.Logger
+	metrics     metrics
+
+	observer settlement.PaymentObserver
+	mu       sync.Mutex
+}
+
+// Options are the options for the Service.
+type Options struct {
+	Streamer p2p.Streamer
+	Store    storage.StateStorer
+	// Chequebook issues the cheques to peers. The node can only receive
+	// cheques if it is not set.
+	Chequebook chequebook.Service
+	// Backend binds the chequebook contracts of the received cheques.
+	Backend chequebook.Backend
+	// Beneficiary is the chain address that the cheques to the node are
+	// issued to.
+	Beneficiary []byte
+	Logger      logging.Logger
+}
+
+// New returns a new swap Service.
+func New(o Options) (*Service, error) {
+	if len(o.Beneficiary) != chequebook.AddressLength {
+		return nil, fmt.Errorf("beneficiary: %w", chequebook.ErrInvalidAddress)
+	}
+	return &Service{
+		streamer:    o.Streamer,
+		store:       o.Store,
+		chequebook:  o.Chequebook,
+		backend:     o.Backend,
+		beneficiary: o.Beneficiary,
+		logger:      o.Logger,
+		metrics:     newMetrics(),
+	}, nil
+}
+
+// SetPaymentObserver sets the observer of the cheques received from peers.
+// It must be called before the protocol is served.
+func (s *Service) SetPaymentObserver(observer settlement.PaymentObserver) {
+	s.observer = observer
+}
+
+func (s *Service) Protocol() p2p.ProtocolSpec {
+	return p2p.ProtocolSpec{
+		Name:    protocolName,
+		Version: protocolVersion,
+		StreamSpecs: []p2p.StreamSpec{
+			{
+				Name:    streamName,
+				Handler: s.handler,
+			},
+		},
+	}
+}
+
+// handler sends the beneficiary of the node to the peer, and verifies and
+// acknowledges the cheque that the peer issues to it.
+func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	w, r := protobuf.NewWriterAndReader(stream)
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	if err := w.WriteMsgWithContext(ctx, &pb.Handshake{
+		Beneficiary: s.beneficiary,
+	}); err != nil {
+		return fmt.Errorf("write handshake to peer %s: %w", p.Address, err)
+	}
+
+	var req pb.EmitCheque
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		return fmt.Errorf("read cheque from peer %s: %w", p.Address, err)
+	}
+	s.metrics.ReceivedChequesCount.Inc()
+
+	var cheque chequebook.SignedCheque
+	if err := json.Unmarshal(req.Cheque, &cheque); err != nil {
+		s.metrics.RejectedChequesCount.Inc()
+		return fmt.Errorf("decode cheque from peer %s: %w", p.Address, err)
+	}
+
+	amount, err := s.receiveCheque(ctx, p.Address, &cheque)
+	if err != nil {
+		s.metrics.RejectedChequesCount.Inc()
+		return fmt.Errorf("receive cheque from peer %s: %w", p.Address, err)
+	}
+	s.logger.Tracef("swap: received cheque of %d from peer %s", amount, p.Address)
+
+	if err := w.WriteMsgWithContext(ctx, &pb.ChequeAck{
+		Amount: amount,
+	}); err != nil {
+		return fmt.Errorf("write ack to peer %s: %w", p.Address, err)
+	}
+	return nil
+}
+
+// receiveCheque verifies the cheque from the peer against its chequebook
+// contract, and records the amount that it adds to the last cheque received
+// from the same chequebook as received. The cumulative payout is tracked per
+// chequebook and beneficiary, and not per peer, so that a cheque can not be
+// credited again by sending it from another overlay address.
+func (s *Service) receiveCheque(ctx context.Context, peer swarm.Address, cheque *chequebook.SignedCheque) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !bytes.Equal(cheque.Beneficiary, s.beneficiary) {
+		return 0, ErrWrongBeneficiary
+	}
+
+	last, err := s.lastCheque(lastReceivedChequePrefix, peer)
+	switch {
+	case err == nil:
+		if !bytes.Equal(cheque.Chequebook, last.Chequebook) {
+			return 0, ErrWrongChequebook
+		}
+	case errors.Is(err, settlement.ErrPeerNoSettlements):
+	default:
+		return 0, err
+	}
+
+	var lastPayout uint64
+	payoutKey := chequebookPayoutKey(cheque.Chequebook, cheque.Beneficiary)
+	if err := s.store.Get(payoutKey, &lastPayout); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return 0, fmt.Errorf("get chequebook payout: %w", err)
+	}
+	if cheque.CumulativePayout <= lastPayout {
+		return 0, ErrChequeValueTooLow
+	}
+	amount := cheque.CumulativePayout - lastPayout
+
+	contract, err := s.backend.Bind(cheque.Chequebook)
+	if err != nil {
+		return 0, fmt.Errorf("bind chequebook: %w", err)
+	}
+	issuer, err := contract.Issuer(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get chequebook issuer: %w", err)
+	}
+	signer, err := chequebook.RecoverIssuer(cheque)
+	if err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(signer, issuer) {
+		return 0, ErrInvalidSignature
+	}
+
+	balance, err := contract.Balance(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get chequebook balance: %w", err)
+	}
+	paidOut, err := contract.PaidOut(ctx, s.beneficiary)
+	if err != nil {
+		return 0, fmt.Errorf("get chequebook paid out: %w", err)
+	}
+	if cheque.CumulativePayout > paidOut && cheque.CumulativePayout-paidOut > balance {
+		return 0, ErrBouncingCheque
+	}
+
+	if s.observer == nil {
+		return 0, ErrNoPaymentObserver
+	}
+	if err := s.observer.NotifyPayment(peer, amount); err != nil {
+		return 0, err
+	}
+	if err := s.store.Put(payoutKey, cheque.CumulativePayout); err != nil {
+		return 0, fmt.Errorf("store chequebook payout: %w", err)
+	}
+	if err := s.store.Put(lastReceivedChequePrefix+peer.String(), cheque); err != nil {
+		return 0, fmt.Errorf("store cheque: %w", err)
+	}
+	s.metrics.TotalReceivedAmount.Add(float64(amount))
+
+	return amount, nil
+}
+
+// Pay issues the cheque of the amount to the beneficiary of the peer and
+// returns the amount that the peer accepted.
+//
+// The cheque is stored as pending before it is sent, and it is removed only
+// when the peer acknowledges it. If the acknowledgement is lost, the amount
+// of the pending cheque is included in the next payment to the peer instead
+// of issued again, so that the same debt is not paid twice, no matter if the
+// peer accepted the lost cheque or not.
+func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount uint64) (accepted uint64, err error) {
+	if s.chequebook == nil {
+		return 0, ErrNoChequebook
+	}
+
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
+	if err != nil {
+		return 0, fmt.Errorf("new stream: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	w, r := protobuf.NewWriterAndReader(stream)
+	var hs pb.Handshake
+	if err := r.ReadMsgWithContext(ctx, &hs); err != nil {
+		return 0, fmt.Errorf("read handshake: %w", err)
+	}
+	if len(hs.Beneficiary) != chequebook.AddressLength {
+		return 0, fmt.Errorf("peer %s beneficiary: %w", peer, chequebook.ErrInvalidAddress)
+	}
+
+	s.mu.Lock()
+	pending, err := s.pendingCheque(peer)
+	s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if pending != nil && !bytes.Equal(pending.Cheque.Beneficiary, hs.Beneficiary) {
+		s.logger.Debugf("swap: peer %s changed beneficiary, dropping pending cheque of %d", peer, pending.Amount)
+		pending = nil
+	}
+
+	var cheque *chequebook.SignedCheque
+	if pending != nil && amount <= pending.Amount {
+		// the pending cheque already covers the amount
+		cheque, amount = pending.Cheque, pending.Amount
+	} else {
+		var unacknowledged uint64
+		if pending != nil {
+			unacknowledged = pending.Amount
+		}
+		cheque, err = s.chequebook.Issue(ctx, hs.Beneficiary, amount-unacknowledged)
+		if err != nil {
+			return 0, fmt.Errorf("issue cheque: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	err = s.store.Put(pendingChequePrefix+peer.String(), &pendingPayment{
+		Cheque: cheque,
+		Amount: amount,
+	})
+	s.mu.Unlock()
+	if err != nil {
+		return 0, fmt.Errorf("store pending cheque: %w", err)
+	}
+
+	encoded, err := json.Marshal(cheque)
+	if err != nil {
+		return 0, fmt.Errorf("encode cheque: %w", err)
+	}
+
+	if err := w.WriteMsgWithContext(ctx, &pb.EmitCheque{
+		Cheque: encoded,
+	}); err != nil {
+		return 0, fmt.Errorf("write cheque: %w", err)
+	}
+	s.metrics.SentChequesCount.Inc()
+
+	var ack pb.ChequeAck
+	if err := r.ReadMsgWithContext(ctx, &ack); err != nil {
+		return 0, fmt.Errorf("read ack: %w", err)
+	}
+	// the peer acknowledges less than the amount if it already accepted the
+	// pending cheque whose acknowledgement was lost
+	if ack.Amount > amount {
+		return 0, fmt.Errorf("peer %s accepted %d of %d: %w", peer, ack.Amount, amount, ErrOverAccepted)
+	}
+	s.logger.Tracef("swap: peer %s accepted cheque of %d", peer, ack.Amount)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.store.Put(lastSentChequePrefix+peer.String(), cheque); err != nil {
+		return 0, fmt.Errorf("store cheque: %w", err)
+	}
+	if err := s.store.Delete(pendingChequePrefix + peer.String()); err != nil {
+		return 0, fmt.Errorf("delete pending cheque: %w", err)
+	}
+	s.metrics.TotalSentAmount.Add(float64(amount))
+
+	return amount, nil
+}
+
+// pendingPayment is the cheque sent to the peer whose acknowledgement is not
+// received. Amount is the part of its cumulative payout that is not
+// accounted as paid.
+type pendingPayment struct {
+	Cheque *chequebook.SignedCheque `json:"cheque"`
+	Amount uint64                   `json:"amount"`
+}
+
+// pendingCheque returns the pending cheque of the peer, or nil if there is
+// none. It must be called with the lock held.
+func (s *Service) pendingCheque(peer swarm.Address) (*pendingPayment, error) {
+	var pending pendingPayment
+	if err := s.store.Get(pendingChequePrefix+peer.String(), &pending); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get pending cheque: %w", err)
+	}
+	return &pending, nil
+}
+
+// CashCheque cashes the last cheque received from the peer with its
+// chequebook contract.
+func (s *Service) CashCheque(ctx context.Context, peer swarm.Address) (uint64, error) {
+	s.mu.Lock()
+	cheque, err := s.lastCheque(lastReceivedChequePrefix, peer)
+	s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	contract, err := s.backend.Bind(cheque.Chequebook)
+	if err != nil {
+		return 0, fmt.Errorf("bind chequebook: %w", err)
+	}
+	paidOut, err := contract.PaidOut(ctx, cheque.Beneficiary)
+	if err != nil {
+		return 0, fmt.Errorf("get chequebook paid out: %w", err)
+	}
+	if cheque.CumulativePayout <= paidOut {
+		return 0, nil
+	}
+	if err := contract.CashCheque(ctx, cheque); err != nil {
+		return 0, fmt.Errorf("cash cheque: %w", err)
+	}
+	amount := cheque.CumulativePayout - paidOut
+	s.metrics.CashedAmount.Add(float64(amount))
+	s.logger.Tracef("swap: cashed %d from the cheque of peer %s", amount, peer)
+
+	return amount, nil
+}
+
+// TotalSent returns the cumulative payout of the last cheque issued to the
+// peer.
+func (s *Service) TotalSent(peer swarm.Address) (uint64, error) {
+	return s.total(lastSentChequePrefix, peer)
+}
+
+// TotalReceived returns the cumulative payout of the last cheque received
+// from the peer.
+func (s *Service) TotalReceived(peer swarm.Address) (uint64, error) {
+	return s.total(lastReceivedChequePrefix, peer)
+}
+
+// SettlementsSent returns the cumulative payouts of the last cheques issued
+// to all peers.
+func (s *Service) SettlementsSent() (map[string]uint64, error) {
+	return s.totals(lastSentChequePrefix)
+}
+
+// SettlementsReceived returns the cumulative payouts of the last cheques
+// received from all peers.
+func (s *Service) SettlementsReceived() (map[string]uint64, error) {
+	return s.totals(lastReceivedChequePrefix)
+}
+
+func (s *Service) total(prefix string, peer swarm.Address) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cheque, err := s.lastCheque(prefix, peer)
+	if err != nil {
+		return 0, err
+	}
+	return cheque.CumulativePayout, nil
+}
+
+// lastCheque must be called with the lock held.
+func (s *Service) lastCheque(prefix string, peer swarm.Address) (*chequebook.SignedCheque, error) {
+	var cheque chequebook.SignedCheque
+	if err := s.store.Get(prefix+peer.String(), &cheque); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, settlement.ErrPeerNoSettlements
+		}
+		return nil, err
+	}
+	return &cheque, nil
+}
+
+func chequebookPayoutKey(chequebook, beneficiary []byte) string {
+	return chequebookPayoutPrefix + hex.EncodeToString(chequebook) + "_" + hex.EncodeToString(beneficiary)
+}
+
+func (s *Service) totals(prefix string) (map[string]uint64, error) {
+	totals := make(map[string]uint64)
+	err := s.store.Iterate(prefix, func(key, value []byte) (stop bool, err error) {
+		peer, err := swarm.ParseHexAddress(strings.TrimPrefix(string(key), prefix))
+		if err != nil {
+			return true, fmt.Errorf("parse cheque key %q: %w", key, err)
+		}
+
+		var cheque chequebook.SignedCheque
+		if err := json.Unmarshal(value, &cheque); err != nil {
+			return true, err
+		}
+
+		totals[peer.String()] = cheque.CumulativePayout
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
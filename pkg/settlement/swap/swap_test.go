@@ -0,0 +1,336 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/streamtest"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var (
+	payer    = swarm.MustParseHexAddress("00112233")
+	receiver = swarm.MustParseHexAddress("00112244")
+
+	chequebookAddress   = bytes.Repeat([]byte{1}, chequebook.AddressLength)
+	receiverBeneficiary = bytes.Repeat([]byte{2}, chequebook.AddressLength)
+	payerBeneficiary    = bytes.Repeat([]byte{3}, chequebook.AddressLength)
+)
+
+type paymentObserverFunc func(peer swarm.Address, amount uint64) error
+
+func (f paymentObserverFunc) NotifyPayment(peer swarm.Address, amount uint64) error {
+	return f(peer, amount)
+}
+
+func TestPay(t *testing.T) {
+	ctx := context.Background()
+	backend := mock.NewBackend()
+	book, _ := newChequebook(t, backend, 1000)
+
+	var notified uint64
+	receiverService := newService(t, nil, nil, backend, receiverBeneficiary)
+	receiverService.SetPaymentObserver(paymentObserverFunc(func(peer swarm.Address, amount uint64) error {
+		if !peer.Equal(payer) {
+			t.Fatalf("got payment from peer %s, want %s", peer, payer)
+		}
+		notified += amount
+		return nil
+	}))
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(receiverService.Protocol()),
+		streamtest.WithBaseAddr(payer),
+	)
+	payerService := newService(t, recorder, book, backend, payerBeneficiary)
+
+	for _, amount := range []uint64{100, 50} {
+		accepted, err := payerService.Pay(ctx, receiver, amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if accepted != amount {
+			t.Fatalf("got accepted %d, want %d", accepted, amount)
+		}
+	}
+	if notified != 150 {
+		t.Fatalf("got notified %d, want %d", notified, 150)
+	}
+
+	assertTotal(t, payerService.TotalSent, receiver, 150)
+	assertTotal(t, receiverService.TotalReceived, payer, 150)
+
+	if _, err := payerService.TotalReceived(receiver); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+
+	sent, err := payerService.SettlementsSent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[receiver.String()] != 150 {
+		t.Fatalf("got settlements sent %v", sent)
+	}
+	received, err := receiverService.SettlementsReceived()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[payer.String()] != 150 {
+		t.Fatalf("got settlements received %v", received)
+	}
+
+	// only the last cheque needs to be cashed
+	for _, want := range []uint64{150, 0} {
+		cashed, err := receiverService.CashCheque(ctx, payer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cashed != want {
+			t.Fatalf("got cashed %d, want %d", cashed, want)
+		}
+	}
+	balance, err := book.Balance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 850 {
+		t.Fatalf("got chequebook balance %d, want %d", balance, 850)
+	}
+
+	if _, err := receiverService.CashCheque(ctx, receiver); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+}
+
+func TestPayRejected(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		balance  uint64
+		observer paymentObserverFunc
+	}{
+		{
+			name:    "bouncing cheque",
+			balance: 50,
+			observer: func(swarm.Address, uint64) error {
+				return nil
+			},
+		},
+		{
+			name:    "not accepted payment",
+			balance: 1000,
+			observer: func(swarm.Address, uint64) error {
+				return errors.New("not accepted")
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// the receiver sees the chequebook of the payer with its own
+			// balance, as if it has changed since the cheque is issued
+			book, issuer := newChequebook(t, mock.NewBackend(), 1000)
+			receiverBackend := mock.NewBackend()
+			receiverBackend.Deploy(chequebookAddress, issuer, tc.balance)
+
+			receiverService := newService(t, nil, nil, receiverBackend, receiverBeneficiary)
+			receiverService.SetPaymentObserver(tc.observer)
+
+			recorder := streamtest.New(
+				streamtest.WithProtocols(receiverService.Protocol()),
+				streamtest.WithBaseAddr(payer),
+			)
+			payerService := newService(t, recorder, book, receiverBackend, payerBeneficiary)
+
+			if _, err := payerService.Pay(context.Background(), receiver, 100); err == nil {
+				t.Fatal("got no error")
+			}
+			if _, err := receiverService.TotalReceived(payer); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+				t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+			}
+		})
+	}
+}
+
+func TestPayLostAck(t *testing.T) {
+	ctx := context.Background()
+	backend := mock.NewBackend()
+	book, _ := newChequebook(t, backend, 1000)
+
+	var notified uint64
+	receiverService := newService(t, nil, nil, backend, receiverBeneficiary)
+	receiverService.SetPaymentObserver(paymentObserverFunc(func(_ swarm.Address, amount uint64) error {
+		notified += amount
+		return nil
+	}))
+
+	store := statestore.NewStateStore()
+	newPayerService := func(opts ...streamtest.Option) *swap.Service {
+		s, err := swap.New(swap.Options{
+			Streamer: streamtest.New(append(opts,
+				streamtest.WithProtocols(receiverService.Protocol()),
+				streamtest.WithBaseAddr(payer),
+			)...),
+			Store:       store,
+			Chequebook:  book,
+			Backend:     backend,
+			Beneficiary: payerBeneficiary,
+			Logger:      logging.New(ioutil.Discard, 0),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	// the receiver accepts the cheque, but the payer does not read the ack
+	errLost := errors.New("lost")
+	if _, err := newPayerService(streamtest.WithReadError(errLost, 1)).Pay(ctx, receiver, 100); !errors.Is(err, errLost) {
+		t.Fatalf("got error %v, want %v", err, errLost)
+	}
+	if notified != 100 {
+		t.Fatalf("got notified %d, want %d", notified, 100)
+	}
+
+	// the debt of the lost payment is still not settled for the payer, and
+	// it is paid only once
+	accepted, err := newPayerService().Pay(ctx, receiver, 130)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accepted != 130 {
+		t.Fatalf("got accepted %d, want %d", accepted, 130)
+	}
+	if notified != 130 {
+		t.Fatalf("got notified %d, want %d", notified, 130)
+	}
+	cheque, err := book.LastCheque(receiverBeneficiary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cheque.CumulativePayout != 130 {
+		t.Fatalf("got cumulative payout %d, want %d", cheque.CumulativePayout, 130)
+	}
+}
+
+func TestPayReplayedFromOtherPeer(t *testing.T) {
+	ctx := context.Background()
+	backend := mock.NewBackend()
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, err := crypto.NewEthereumAddress(key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := backend.Deploy(chequebookAddress, issuer, 1000)
+
+	var notified uint64
+	receiverService := newService(t, nil, nil, backend, receiverBeneficiary)
+	receiverService.SetPaymentObserver(paymentObserverFunc(func(_ swarm.Address, amount uint64) error {
+		notified += amount
+		return nil
+	}))
+
+	// the chequebooks with separate stores issue the same cheque, as if it
+	// is replayed from the other overlay
+	for i, base := range []swarm.Address{payer, swarm.MustParseHexAddress("00112255")} {
+		book, err := chequebook.New(chequebook.Options{
+			Address:  chequebookAddress,
+			Contract: contract,
+			Store:    statestore.NewStateStore(),
+			Signer:   crypto.NewDefaultSigner(key),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorder := streamtest.New(
+			streamtest.WithProtocols(receiverService.Protocol()),
+			streamtest.WithBaseAddr(base),
+		)
+		_, err = newService(t, recorder, book, backend, payerBeneficiary).Pay(ctx, receiver, 100)
+		if i == 0 && err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 && err == nil {
+			t.Fatal("replayed cheque accepted")
+		}
+	}
+	if notified != 100 {
+		t.Fatalf("got notified %d, want %d", notified, 100)
+	}
+}
+
+func TestPayNoChequebook(t *testing.T) {
+	s := newService(t, streamtest.New(), nil, mock.NewBackend(), payerBeneficiary)
+
+	if _, err := s.Pay(context.Background(), receiver, 100); !errors.Is(err, swap.ErrNoChequebook) {
+		t.Fatalf("got error %v, want %v", err, swap.ErrNoChequebook)
+	}
+}
+
+func newChequebook(t *testing.T, backend *mock.Backend, balance uint64) (book chequebook.Service, issuer []byte) {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, err = crypto.NewEthereumAddress(key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book, err = chequebook.New(chequebook.Options{
+		Address:  chequebookAddress,
+		Contract: backend.Deploy(chequebookAddress, issuer, balance),
+		Store:    statestore.NewStateStore(),
+		Signer:   crypto.NewDefaultSigner(key),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return book, issuer
+}
+
+func newService(t *testing.T, streamer p2p.Streamer, book chequebook.Service, backend chequebook.Backend, beneficiary []byte) *swap.Service {
+	t.Helper()
+
+	s, err := swap.New(swap.Options{
+		Streamer:    streamer,
+		Store:       statestore.NewStateStore(),
+		Chequebook:  book,
+		Backend:     backend,
+		Beneficiary: beneficiary,
+		Logger:      logging.New(ioutil.Discard, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func assertTotal(t *testing.T, total func(swarm.Address) (uint64, error), peer swarm.Address, want uint64) {
+	t.Helper()
+
+	got, err := total(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got total %d for peer %s, want %d", got, peer, want)
+	}
+}
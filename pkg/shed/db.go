@@ -32,7 +32,8 @@ import (
 )
 
 var (
-	openFileLimit = 128 // The limit for LevelDB OpenFilesCacheCapacity.
+	openFileLimit      = 128                           // The limit for LevelDB OpenFilesCacheCapacity.
+	blockCacheCapacity = opt.DefaultBlockCacheCapacity // The capacity of the LevelDB block cache in bytes.
 )
 
 // DB provides abstractions over LevelDB in order to
@@ -51,10 +52,13 @@ type DB struct {
 func NewDB(path string) (db *DB, err error) {
 	var ldb *leveldb.DB
 	if path == "" {
-		ldb, err = leveldb.Open(storage.NewMemStorage(), nil)
+		ldb, err = leveldb.Open(storage.NewMemStorage(), &opt.Options{
+			BlockCacheCapacity: blockCacheCapacity,
+		})
 	} else {
 		ldb, err = leveldb.OpenFile(path, &opt.Options{
 			OpenFilesCacheCapacity: openFileLimit,
+			BlockCacheCapacity:     blockCacheCapacity,
 		})
 	}
 
@@ -150,6 +154,16 @@ func (db *DB) WriteBatch(batch *leveldb.Batch) (err error) {
 	return nil
 }
 
+// BlockCacheStats returns the size of the cached blocks of the LevelDB
+// tables and the capacity of the block cache, both in bytes.
+func (db *DB) BlockCacheStats() (size, capacity int, err error) {
+	var stats leveldb.DBStats
+	if err := db.ldb.Stats(&stats); err != nil {
+		return 0, 0, err
+	}
+	return stats.BlockCacheSize, blockCacheCapacity, nil
+}
+
 // Close closes LevelDB database.
 func (db *DB) Close() (err error) {
 	close(db.quit)
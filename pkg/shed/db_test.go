@@ -92,6 +92,27 @@ func TestDB_persistence(t *testing.T) {
 // newTestDB is a helper function that constructs a
 // temporary database and returns a cleanup function that must
 // be called to remove the data.
+// TestDB_BlockCacheStats validates that the block cache statistics report
+// the configured capacity.
+func TestDB_BlockCacheStats(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+
+	size, capacity, err := db.BlockCacheStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capacity != blockCacheCapacity {
+		t.Errorf("got capacity %v, want %v", capacity, blockCacheCapacity)
+	}
+	if size < 0 || size > capacity {
+		t.Errorf("got size %v, want between 0 and %v", size, capacity)
+	}
+}
+
 func newTestDB(t *testing.T) *DB {
 	t.Helper()
 	db, err := NewDB("")
@@ -0,0 +1,48 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package slowlog logs the operations that take longer than a threshold, which
+// makes the tail latency of the operations visible without tracing.
+package slowlog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+)
+
+// Logger logs the operations of a subsystem that exceed its threshold. A nil
+// Logger and a Logger with zero threshold do not log any operation.
+type Logger struct {
+	logger    logging.Logger
+	name      string
+	threshold time.Duration
+}
+
+// New creates a new Logger of the operations of the named subsystem, such as
+// "localstore put", that take longer than the threshold.
+func New(logger logging.Logger, name string, threshold time.Duration) *Logger {
+	return &Logger{
+		logger:    logger,
+		name:      name,
+		threshold: threshold,
+	}
+}
+
+// Observe logs the operation described by the format and the arguments if
+// more time than the threshold passed since the start of the operation. It is
+// meant to be deferred at the start of the operation:
+//
+//	defer l.Observe(time.Now(), "chunk %s", addr)
+func (l *Logger) Observe(start time.Time, format string, args ...interface{}) {
+	if l == nil || l.threshold <= 0 {
+		return
+	}
+	d := time.Since(start)
+	if d <= l.threshold {
+		return
+	}
+	l.logger.Warningf("slow %s: %s took %s, threshold %s", l.name, fmt.Sprintf(format, args...), d, l.threshold)
+}
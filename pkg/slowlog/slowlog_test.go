@@ -0,0 +1,64 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slowlog_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/slowlog"
+	"github.com/sirupsen/logrus"
+)
+
+func TestObserve(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		threshold time.Duration
+		took      time.Duration
+		logged    bool
+	}{
+		{
+			name:      "slow",
+			threshold: time.Second,
+			took:      2 * time.Second,
+			logged:    true,
+		},
+		{
+			name:      "fast",
+			threshold: time.Second,
+			took:      time.Millisecond,
+		},
+		{
+			name: "disabled",
+			took: time.Hour,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := slowlog.New(logging.New(&buf, logrus.WarnLevel), "test put", tc.threshold)
+
+			l.Observe(time.Now().Add(-tc.took), "chunk %s", "abcd")
+
+			got := buf.String()
+			if !tc.logged {
+				if got != "" {
+					t.Fatalf("got log %q, want none", got)
+				}
+				return
+			}
+			if !strings.Contains(got, "slow test put: chunk abcd took") {
+				t.Fatalf("got log %q", got)
+			}
+		})
+	}
+}
+
+func TestObserveNil(t *testing.T) {
+	var l *slowlog.Logger
+	l.Observe(time.Now().Add(-time.Hour), "chunk %s", "abcd")
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
@@ -32,6 +33,16 @@ func NewStateStore(path string) (storage.StateStorer, error) {
 	}, nil
 }
 
+// BlockCacheStats returns the size of the cached blocks of the LevelDB
+// tables and the capacity of the block cache, both in bytes.
+func (s *store) BlockCacheStats() (size, capacity int, err error) {
+	var stats leveldb.DBStats
+	if err := s.db.Stats(&stats); err != nil {
+		return 0, 0, err
+	}
+	return stats.BlockCacheSize, opt.DefaultBlockCacheCapacity, nil
+}
+
 // Get retrieves a value of the requested key. If no results are found,
 // storage.ErrNotFound will be returned.
 func (s *store) Get(key string, i interface{}) error {
@@ -0,0 +1,583 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package inmem provides the storage.Storer that keeps the chunks in memory.
+// It maintains the push and pull sync indexes and the pin counters of the
+// chunks as the local store does, without its garbage collection, so that it
+// can be used by the protocol tests and by the nodes that do not need a
+// persistent store. Failures of the storer operations can be injected.
+package inmem
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+// maxPinnedChunks is the number of the pinned chunks returned by
+// PinnedChunks, as by the local store.
+const maxPinnedChunks = 20
+
+var _ storage.Storer = (*Store)(nil)
+
+// ErrInvalidMode is returned when an unknown put or set mode is provided.
+var ErrInvalidMode = errors.New("invalid mode")
+
+// Op is the storer operation to which a failure is injected.
+type Op int
+
+const (
+	OpGet Op = iota
+	OpPut
+	OpHas
+	OpSet
+)
+
+// FailureFunc returns the error with which the operation on the chunk with
+// the address fails, or nil if it should succeed.
+type FailureFunc func(op Op, addr swarm.Address) error
+
+type Options struct {
+	// Tags are incremented when the chunks of the tags are synced. They are
+	// not incremented if it is not set.
+	Tags *tags.Tags
+}
+
+type item struct {
+	address swarm.Address
+	data    []byte
+	binID   uint64 // zero if the chunk is not in the pull index
+	pushSeq uint64 // zero if the chunk is not in the push index
+	pushTag uint32
+	pullTag uint32
+}
+
+// Store is the in-memory storage.Storer.
+type Store struct {
+	baseKey      []byte
+	tags         *tags.Tags
+	chunks       map[string]*item
+	pull         [swarm.MaxPO + 1][]*item
+	binIDs       [swarm.MaxPO + 1]uint64
+	push         []*item
+	pushSeq      uint64
+	pins         map[string]uint64
+	failure      FailureFunc
+	pullTriggers [swarm.MaxPO + 1][]chan struct{}
+	pushTriggers []chan struct{}
+	mu           sync.Mutex
+	quit         chan struct{}
+	quitOnce     sync.Once
+}
+
+// New returns the empty store in which the chunks are assigned to the pull
+// sync bins by their proximity to the base key.
+func New(baseKey []byte, o Options) *Store {
+	return &Store{
+		baseKey: baseKey,
+		tags:    o.Tags,
+		chunks:  make(map[string]*item),
+		pins:    make(map[string]uint64),
+		quit:    make(chan struct{}),
+	}
+}
+
+// SetFailure sets the function that decides which operations fail. The
+// failures are disabled if it is nil.
+func (s *Store) SetFailure(f FailureFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failure = f
+}
+
+func (s *Store) fail(op Op, addr swarm.Address) error {
+	if s.failure == nil {
+		return nil
+	}
+	return s.failure(op, addr)
+}
+
+func (s *Store) Get(_ context.Context, _ storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.get(addr)
+}
+
+func (s *Store) get(addr swarm.Address) (swarm.Chunk, error) {
+	if err := s.fail(OpGet, addr); err != nil {
+		return nil, err
+	}
+	i, ok := s.chunks[addr.ByteString()]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return swarm.NewChunk(i.address, i.data), nil
+}
+
+func (s *Store) GetMulti(_ context.Context, _ storage.ModeGet, addrs ...swarm.Address) ([]swarm.Chunk, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chunks := make([]swarm.Chunk, 0, len(addrs))
+	for _, addr := range addrs {
+		ch, err := s.get(addr)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, ch)
+	}
+	return chunks, nil
+}
+
+// Put stores the chunks. Uploaded chunks are added to the push and the pull
+// indexes and synced chunks only to the pull index. No chunk is stored if
+// the put fails.
+func (s *Store) Put(_ context.Context, mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err error) {
+	switch mode {
+	case storage.ModePutRequest, storage.ModePutSync, storage.ModePutUpload:
+	default:
+		return nil, ErrInvalidMode
+	}
+
+	s.mu.Lock()
+
+	for _, ch := range chs {
+		if err := s.fail(OpPut, ch.Address()); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+	}
+
+	exist = make([]bool, len(chs))
+	pullBins := make(map[uint8]struct{})
+	var pushed bool
+	for j, ch := range chs {
+		key := ch.Address().ByteString()
+		if _, ok := s.chunks[key]; ok {
+			exist[j] = true
+			continue
+		}
+		i := &item{
+			address: ch.Address(),
+			data:    append([]byte(nil), ch.Data()...),
+		}
+		if mode == storage.ModePutSync || mode == storage.ModePutUpload {
+			po := swarm.Proximity(s.baseKey, i.address.Bytes())
+			s.binIDs[po]++
+			i.binID = s.binIDs[po]
+			s.pull[po] = append(s.pull[po], i)
+			pullBins[po] = struct{}{}
+		}
+		if mode == storage.ModePutUpload {
+			s.pushSeq++
+			i.pushSeq = s.pushSeq
+			i.pushTag = ch.TagID()
+			i.pullTag = ch.TagID()
+			s.push = append(s.push, i)
+			pushed = true
+		}
+		s.chunks[key] = i
+	}
+
+	for po := range pullBins {
+		trigger(s.pullTriggers[po])
+	}
+	if pushed {
+		trigger(s.pushTriggers)
+	}
+
+	s.mu.Unlock()
+	return exist, nil
+}
+
+func (s *Store) Has(_ context.Context, addr swarm.Address) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.has(addr)
+}
+
+func (s *Store) has(addr swarm.Address) (bool, error) {
+	if err := s.fail(OpHas, addr); err != nil {
+		return false, err
+	}
+	_, ok := s.chunks[addr.ByteString()]
+	return ok, nil
+}
+
+func (s *Store) HasMulti(_ context.Context, addrs ...swarm.Address) ([]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	yes := make([]bool, len(addrs))
+	for j, addr := range addrs {
+		has, err := s.has(addr)
+		if err != nil {
+			return nil, err
+		}
+		yes[j] = has
+	}
+	return yes, nil
+}
+
+// Set updates the indexes of the chunks as the local store does, and
+// increments their tags when they are synced.
+func (s *Store) Set(_ context.Context, mode storage.ModeSet, addrs ...swarm.Address) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, addr := range addrs {
+		if err := s.fail(OpSet, addr); err != nil {
+			return err
+		}
+	}
+
+	if mode == storage.ModeSetRemoveUnpinned {
+		for _, addr := range addrs {
+			if _, ok := s.pins[addr.ByteString()]; ok {
+				return storage.ErrPinned
+			}
+		}
+		mode = storage.ModeSetRemove
+	}
+
+	for _, addr := range addrs {
+		key := addr.ByteString()
+		i := s.chunks[key]
+		switch mode {
+		case storage.ModeSetAccess:
+		case storage.ModeSetSyncPush:
+			if i == nil || i.pushSeq == 0 {
+				break
+			}
+			if t := s.tag(i.pushTag); t != nil {
+				if t.Anonymous {
+					return errors.New("got an anonymous chunk in push sync index")
+				}
+				t.Inc(tags.StateSynced)
+			}
+			s.removePush(i)
+		case storage.ModeSetDiscard:
+			if i == nil || i.pushSeq == 0 {
+				break
+			}
+			s.removePush(i)
+		case storage.ModeSetSyncPull:
+			if i == nil || i.binID == 0 {
+				break
+			}
+			// anonymous tags are incremented only once, as the chunks
+			// are synced to multiple peers
+			if t := s.tag(i.pullTag); t != nil && t.Anonymous {
+				t.Inc(tags.StateSent)
+				i.pullTag = 0
+			}
+		case storage.ModeSetRemove:
+			if i == nil {
+				break
+			}
+			s.removePush(i)
+			if i.binID != 0 {
+				po := swarm.Proximity(s.baseKey, addr.Bytes())
+				s.pull[po] = removeItem(s.pull[po], i)
+			}
+			delete(s.chunks, key)
+		case storage.ModeSetPin:
+			s.pins[key]++
+		case storage.ModeSetUnpin:
+			counter, ok := s.pins[key]
+			if !ok {
+				return storage.ErrNotFound
+			}
+			if counter > 1 {
+				s.pins[key] = counter - 1
+			} else {
+				delete(s.pins, key)
+			}
+		default:
+			return ErrInvalidMode
+		}
+	}
+	return nil
+}
+
+// tag returns the tag with the uid, or nil if it is not found.
+func (s *Store) tag(uid uint32) *tags.Tag {
+	if s.tags == nil || uid == 0 {
+		return nil
+	}
+	t, err := s.tags.Get(uid)
+	if err != nil {
+		return nil
+	}
+	return t
+}
+
+func (s *Store) removePush(i *item) {
+	if i.pushSeq == 0 {
+		return
+	}
+	s.push = removeItem(s.push, i)
+	i.pushSeq = 0
+}
+
+func removeItem(items []*item, i *item) []*item {
+	for j, v := range items {
+		if v == i {
+			return append(items[:j], items[j+1:]...)
+		}
+	}
+	return items
+}
+
+func (s *Store) LastPullSubscriptionBinID(bin uint8) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if int(bin) >= len(s.pull) || len(s.pull[bin]) == 0 {
+		return 0, nil
+	}
+	items := s.pull[bin]
+	return items[len(items)-1].binID, nil
+}
+
+// SubscribePull sends the descriptors of the chunks in the bin of the pull
+// index with bin ids in the [since, until] interval, as the local store
+// does. The subscription is live if until is zero.
+func (s *Store) SubscribePull(ctx context.Context, bin uint8, since, until uint64) (<-chan storage.Descriptor, <-chan struct{}, func()) {
+	descriptors := make(chan storage.Descriptor)
+	trigger := make(chan struct{}, 1)
+	trigger <- struct{}{}
+
+	s.mu.Lock()
+	s.pullTriggers[bin] = append(s.pullTriggers[bin], trigger)
+	s.mu.Unlock()
+
+	stopC := make(chan struct{})
+	var stopOnce sync.Once
+
+	go func() {
+		defer close(descriptors)
+
+		next := since
+		for {
+			select {
+			case <-trigger:
+			case <-stopC:
+				return
+			case <-s.quit:
+				return
+			case <-ctx.Done():
+				return
+			}
+
+			s.mu.Lock()
+			var pending []storage.Descriptor
+			for _, i := range s.pull[bin] {
+				if i.binID >= next && (until == 0 || i.binID <= until) {
+					pending = append(pending, storage.Descriptor{Address: i.address, BinID: i.binID})
+				}
+			}
+			s.mu.Unlock()
+
+			for _, d := range pending {
+				select {
+				case descriptors <- d:
+					next = d.BinID + 1
+				case <-stopC:
+					return
+				case <-s.quit:
+					return
+				case <-ctx.Done():
+					return
+				}
+				if until > 0 && d.BinID == until {
+					return
+				}
+			}
+		}
+	}()
+
+	stop := func() {
+		stopOnce.Do(func() {
+			close(stopC)
+		})
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.pullTriggers[bin] = removeTrigger(s.pullTriggers[bin], trigger)
+	}
+	return descriptors, s.quit, stop
+}
+
+// SubscribePush sends the chunks of the push index in the order in which
+// they are uploaded, with their tag uids, until it is stopped.
+func (s *Store) SubscribePush(ctx context.Context) (<-chan swarm.Chunk, func()) {
+	chunks := make(chan swarm.Chunk)
+	trigger := make(chan struct{}, 1)
+	trigger <- struct{}{}
+
+	s.mu.Lock()
+	s.pushTriggers = append(s.pushTriggers, trigger)
+	s.mu.Unlock()
+
+	stopC := make(chan struct{})
+	var stopOnce sync.Once
+
+	go func() {
+		defer close(chunks)
+
+		var last uint64
+		for {
+			select {
+			case <-trigger:
+			case <-stopC:
+				return
+			case <-s.quit:
+				return
+			case <-ctx.Done():
+				return
+			}
+
+			s.mu.Lock()
+			var pending []pushItem
+			for _, i := range s.push {
+				if i.pushSeq > last {
+					pending = append(pending, pushItem{
+						chunk: swarm.NewChunk(i.address, i.data).WithTagID(i.pushTag),
+						seq:   i.pushSeq,
+					})
+				}
+			}
+			s.mu.Unlock()
+
+			for _, p := range pending {
+				select {
+				case chunks <- p.chunk:
+					last = p.seq
+				case <-stopC:
+					return
+				case <-s.quit:
+					return
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	stop := func() {
+		stopOnce.Do(func() {
+			close(stopC)
+		})
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.pushTriggers = removeTrigger(s.pushTriggers, trigger)
+	}
+	return chunks, stop
+}
+
+// pushItem is the chunk from the push index with its position in it.
+type pushItem struct {
+	chunk swarm.Chunk
+	seq   uint64
+}
+
+func trigger(triggers []chan struct{}) {
+	for _, t := range triggers {
+		select {
+		case t <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func removeTrigger(triggers []chan struct{}, trigger chan struct{}) []chan struct{} {
+	for j, t := range triggers {
+		if t == trigger {
+			return append(triggers[:j], triggers[j+1:]...)
+		}
+	}
+	return triggers
+}
+
+// PinnedChunks returns the pinned chunks in the order of their addresses,
+// starting from the cursor.
+func (s *Store) PinnedChunks(_ context.Context, cursor swarm.Address) ([]*storage.Pinner, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.pins))
+	for key := range s.pins {
+		if bytes.Compare([]byte(key), cursor.Bytes()) >= 0 {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("get first pin: %w", storage.ErrNotFound)
+	}
+	sort.Strings(keys)
+	if len(keys) > maxPinnedChunks {
+		keys = keys[:maxPinnedChunks]
+	}
+
+	pinned := make([]*storage.Pinner, 0, len(keys))
+	for _, key := range keys {
+		pinned = append(pinned, &storage.Pinner{
+			Address:    swarm.NewAddress([]byte(key)),
+			PinCounter: s.pins[key],
+		})
+	}
+	return pinned, nil
+}
+
+func (s *Store) PinInfo(addr swarm.Address) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counter, ok := s.pins[addr.ByteString()]
+	if !ok {
+		return 0, storage.ErrNotFound
+	}
+	return counter, nil
+}
+
+// ChunkState returns the presence of the chunk in the indexes of the store,
+// as the localstore.DB. The chunks are never in the gc index, as they are
+// not garbage collected.
+func (s *Store) ChunkState(addr swarm.Address) (cs localstore.ChunkState, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.chunks[addr.ByteString()]
+	if !ok {
+		return cs, nil
+	}
+	cs.Stored = true
+	cs.InPushIndex = i.pushSeq > 0
+	cs.InPullIndex = i.binID > 0
+	cs.PinCounter = s.pins[addr.ByteString()]
+	return cs, nil
+}
+
+// Close terminates the subscriptions. The stored chunks are kept.
+func (s *Store) Close() error {
+	s.quitOnce.Do(func() {
+		close(s.quit)
+	})
+	return nil
+}
@@ -0,0 +1,273 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inmem_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/inmem"
+	testingc "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+var baseKey = make([]byte, swarm.HashSize)
+
+func TestPutGet(t *testing.T) {
+	s := inmem.New(baseKey, inmem.Options{})
+	defer s.Close()
+	ctx := context.Background()
+
+	chunks := testingc.GenerateTestRandomChunks(3)
+	exist, err := s.Put(ctx, storage.ModePutUpload, chunks[0], chunks[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exist[0] || exist[1] {
+		t.Errorf("got exist %v, want none", exist)
+	}
+	exist, err = s.Put(ctx, storage.ModePutRequest, chunks[1], chunks[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exist[0] || exist[1] {
+		t.Errorf("got exist %v, want [true false]", exist)
+	}
+
+	for _, ch := range chunks {
+		got, err := s.Get(ctx, storage.ModeGetRequest, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(ch) {
+			t.Errorf("got chunk %s, want %s", got.Address(), ch.Address())
+		}
+	}
+	got, err := s.GetMulti(ctx, storage.ModeGetRequest, chunks[0].Address(), chunks[2].Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !got[0].Equal(chunks[0]) || !got[1].Equal(chunks[2]) {
+		t.Errorf("got chunks %v, want %v", got, chunks[:2])
+	}
+
+	missing := testingc.GenerateTestRandomChunk().Address()
+	if _, err := s.Get(ctx, storage.ModeGetRequest, missing); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+	yes, err := s.HasMulti(ctx, chunks[0].Address(), missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !yes[0] || yes[1] {
+		t.Errorf("got has %v, want [true false]", yes)
+	}
+
+	if err := s.Set(ctx, storage.ModeSetRemove, chunks[0].Address()); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := s.Has(ctx, chunks[0].Address()); err != nil || has {
+		t.Errorf("got has %v, error %v after remove", has, err)
+	}
+}
+
+func TestSubscribePull(t *testing.T) {
+	s := inmem.New(baseKey, inmem.Options{})
+	defer s.Close()
+	ctx := context.Background()
+
+	// all chunks are in the bin 0 of the zero base key
+	var chunks []swarm.Chunk
+	for len(chunks) < 3 {
+		ch := testingc.GenerateTestRandomChunk()
+		if swarm.Proximity(baseKey, ch.Address().Bytes()) == 0 {
+			chunks = append(chunks, ch)
+		}
+	}
+	if _, err := s.Put(ctx, storage.ModePutSync, chunks[0], chunks[1]); err != nil {
+		t.Fatal(err)
+	}
+	// chunks that are requested are not in the pull index
+	if _, err := s.Put(ctx, storage.ModePutRequest, testingc.GenerateTestRandomChunk()); err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := s.LastPullSubscriptionBinID(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != 2 {
+		t.Errorf("got last bin id %d, want %d", last, 2)
+	}
+
+	c, _, stop := s.SubscribePull(ctx, 0, 2, 0)
+	defer stop()
+	expectDescriptor(t, c, chunks[1].Address(), 2)
+
+	if _, err := s.Put(ctx, storage.ModePutUpload, chunks[2]); err != nil {
+		t.Fatal(err)
+	}
+	expectDescriptor(t, c, chunks[2].Address(), 3)
+
+	// the subscription with until is closed when it is reached
+	c, _, stop = s.SubscribePull(ctx, 0, 0, 2)
+	defer stop()
+	expectDescriptor(t, c, chunks[0].Address(), 1)
+	expectDescriptor(t, c, chunks[1].Address(), 2)
+	select {
+	case _, ok := <-c:
+		if ok {
+			t.Fatal("got descriptor after until")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscription not closed")
+	}
+}
+
+func TestSubscribePush(t *testing.T) {
+	tg := tags.NewTags()
+	s := inmem.New(baseKey, inmem.Options{Tags: tg})
+	defer s.Close()
+	ctx := context.Background()
+
+	tag, err := tg.Create("test", 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := testingc.GenerateTestRandomChunks(2)
+	if _, err := s.Put(ctx, storage.ModePutUpload, chunks[0].WithTagID(tag.Uid)); err != nil {
+		t.Fatal(err)
+	}
+	// synced chunks are not pushed
+	if _, err := s.Put(ctx, storage.ModePutSync, testingc.GenerateTestRandomChunk()); err != nil {
+		t.Fatal(err)
+	}
+
+	c, stop := s.SubscribePush(ctx)
+	defer stop()
+	expectChunk(t, c, chunks[0], tag.Uid)
+
+	if _, err := s.Put(ctx, storage.ModePutUpload, chunks[1].WithTagID(tag.Uid)); err != nil {
+		t.Fatal(err)
+	}
+	expectChunk(t, c, chunks[1], tag.Uid)
+
+	if err := s.Set(ctx, storage.ModeSetSyncPush, chunks[0].Address(), chunks[1].Address()); err != nil {
+		t.Fatal(err)
+	}
+	if got := tag.Get(tags.StateSynced); got != 2 {
+		t.Errorf("got %d synced chunks in tag, want %d", got, 2)
+	}
+
+	// synced chunks are no longer in the push index
+	c, stop = s.SubscribePush(ctx)
+	defer stop()
+	select {
+	case ch := <-c:
+		t.Fatalf("got synced chunk %s", ch.Address())
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPin(t *testing.T) {
+	s := inmem.New(baseKey, inmem.Options{})
+	defer s.Close()
+	ctx := context.Background()
+
+	ch := testingc.GenerateTestRandomChunk()
+	if _, err := s.PinnedChunks(ctx, swarm.ZeroAddress); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := s.Set(ctx, storage.ModeSetPin, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	counter, err := s.PinInfo(ch.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counter != 2 {
+		t.Errorf("got pin counter %d, want %d", counter, 2)
+	}
+	pinned, err := s.PinnedChunks(ctx, swarm.ZeroAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pinned) != 1 || !pinned[0].Address.Equal(ch.Address()) || pinned[0].PinCounter != 2 {
+		t.Errorf("got pinned chunks %v", pinned)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := s.Set(ctx, storage.ModeSetUnpin, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.PinInfo(ch.Address()); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+	if err := s.Set(ctx, storage.ModeSetUnpin, ch.Address()); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func TestFailure(t *testing.T) {
+	s := inmem.New(baseKey, inmem.Options{})
+	defer s.Close()
+	ctx := context.Background()
+
+	chunks := testingc.GenerateTestRandomChunks(2)
+	testErr := errors.New("test error")
+	s.SetFailure(func(op inmem.Op, addr swarm.Address) error {
+		if op == inmem.OpPut && addr.Equal(chunks[1].Address()) {
+			return testErr
+		}
+		return nil
+	})
+
+	// no chunk is stored if any of them fails
+	if _, err := s.Put(ctx, storage.ModePutUpload, chunks...); !errors.Is(err, testErr) {
+		t.Fatalf("got error %v, want %v", err, testErr)
+	}
+	if has, err := s.Has(ctx, chunks[0].Address()); err != nil || has {
+		t.Fatalf("got has %v, error %v after failed put", has, err)
+	}
+
+	s.SetFailure(nil)
+	if _, err := s.Put(ctx, storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectDescriptor(t *testing.T, c <-chan storage.Descriptor, addr swarm.Address, binID uint64) {
+	t.Helper()
+
+	select {
+	case d := <-c:
+		if !d.Address.Equal(addr) || d.BinID != binID {
+			t.Fatalf("got descriptor %s, want %s bin id %d", d.String(), addr, binID)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("descriptor %s not received", addr)
+	}
+}
+
+func expectChunk(t *testing.T, c <-chan swarm.Chunk, ch swarm.Chunk, tagID uint32) {
+	t.Helper()
+
+	select {
+	case got := <-c:
+		if !got.Equal(ch) || got.TagID() != tagID {
+			t.Fatalf("got chunk %s with tag %d, want %s with tag %d", got.Address(), got.TagID(), ch.Address(), tagID)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("chunk %s not received", ch.Address())
+	}
+}
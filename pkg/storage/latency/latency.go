@@ -0,0 +1,145 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package latency provides a storage.Storer wrapper that delays the chunk
+// operations and fails some of them, so that the protocols that store and
+// retrieve chunks can be tested against a slow or failing disk.
+package latency
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ErrInjected is returned by the operations that are failed by the Store.
+var ErrInjected = errors.New("injected storage error")
+
+var _ storage.Storer = (*Store)(nil)
+
+// Store delays the Get, GetMulti, Put, Has, HasMulti and Set operations of
+// the wrapped storage.Storer, and fails them at the configured rate. All
+// other operations are passed through unchanged.
+type Store struct {
+	storage.Storer
+
+	latency   time.Duration
+	jitter    time.Duration
+	errorRate float64
+
+	rand   *rand.Rand
+	randMu sync.Mutex
+}
+
+// Options are the options for the Store.
+type Options struct {
+	// Latency is the delay of every operation.
+	Latency time.Duration
+	// Jitter is the maximal random delay added to the Latency.
+	Jitter time.Duration
+	// ErrorRate is the fraction of the operations, from 0 to 1, that fail
+	// with ErrInjected after the delay.
+	ErrorRate float64
+	// Seed is the seed of the random delays and failures. The current time
+	// is used if it is zero.
+	Seed int64
+}
+
+// New returns a new Store that wraps the storer.
+func New(storer storage.Storer, o Options) *Store {
+	seed := o.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Store{
+		Storer:    storer,
+		latency:   o.Latency,
+		jitter:    o.Jitter,
+		errorRate: o.ErrorRate,
+		rand:      rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (s *Store) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (ch swarm.Chunk, err error) {
+	if err := s.inject(ctx); err != nil {
+		return nil, err
+	}
+	return s.Storer.Get(ctx, mode, addr)
+}
+
+func (s *Store) GetMulti(ctx context.Context, mode storage.ModeGet, addrs ...swarm.Address) (chs []swarm.Chunk, err error) {
+	if err := s.inject(ctx); err != nil {
+		return nil, err
+	}
+	return s.Storer.GetMulti(ctx, mode, addrs...)
+}
+
+func (s *Store) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err error) {
+	if err := s.inject(ctx); err != nil {
+		return nil, err
+	}
+	return s.Storer.Put(ctx, mode, chs...)
+}
+
+func (s *Store) Has(ctx context.Context, addr swarm.Address) (yes bool, err error) {
+	if err := s.inject(ctx); err != nil {
+		return false, err
+	}
+	return s.Storer.Has(ctx, addr)
+}
+
+func (s *Store) HasMulti(ctx context.Context, addrs ...swarm.Address) (yes []bool, err error) {
+	if err := s.inject(ctx); err != nil {
+		return nil, err
+	}
+	return s.Storer.HasMulti(ctx, addrs...)
+}
+
+func (s *Store) Set(ctx context.Context, mode storage.ModeSet, addrs ...swarm.Address) (err error) {
+	if err := s.inject(ctx); err != nil {
+		return err
+	}
+	return s.Storer.Set(ctx, mode, addrs...)
+}
+
+// inject waits for the delay of an operation and returns ErrInjected if the
+// operation should fail. It returns the context error if the context is
+// done before the delay passes.
+func (s *Store) inject(ctx context.Context) error {
+	delay, fail := s.next()
+
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if fail {
+		return ErrInjected
+	}
+	return nil
+}
+
+// next returns the delay of an operation and whether it should fail.
+func (s *Store) next() (delay time.Duration, fail bool) {
+	s.randMu.Lock()
+	defer s.randMu.Unlock()
+
+	delay = s.latency
+	if s.jitter > 0 {
+		delay += time.Duration(s.rand.Int63n(int64(s.jitter)))
+	}
+	fail = s.errorRate > 0 && s.rand.Float64() < s.errorRate
+	return delay, fail
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package latency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/latency"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	testingc "github.com/ethersphere/bee/pkg/storage/testing"
+)
+
+func TestLatency(t *testing.T) {
+	s := latency.New(mock.NewStorer(), latency.Options{
+		Latency: 20 * time.Millisecond,
+		Jitter:  10 * time.Millisecond,
+	})
+	ctx := context.Background()
+	ch := testingc.GenerateTestRandomChunk()
+
+	start := time.Now()
+	if _, err := s.Put(ctx, storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, storage.ModeGetRequest, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Fatalf("got duration %v of two operations, want at least %v", d, 40*time.Millisecond)
+	}
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := s.Has(ctx, ch.Address()); !errors.Is(err, context.Canceled) {
+			t.Fatalf("got error %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+func TestErrorRate(t *testing.T) {
+	storer := mock.NewStorer()
+	ctx := context.Background()
+	ch := testingc.GenerateTestRandomChunk()
+
+	s := latency.New(storer, latency.Options{
+		ErrorRate: 1,
+	})
+	if _, err := s.Put(ctx, storage.ModePutUpload, ch); !errors.Is(err, latency.ErrInjected) {
+		t.Fatalf("got error %v, want %v", err, latency.ErrInjected)
+	}
+	// the failed operation does not reach the wrapped storer
+	if has, err := storer.Has(ctx, ch.Address()); err != nil || has {
+		t.Fatalf("got has %v, error %v, want chunk not stored", has, err)
+	}
+
+	s = latency.New(storer, latency.Options{
+		ErrorRate: 0.5,
+		Seed:      1,
+	})
+	var failed int
+	for i := 0; i < 1000; i++ {
+		if _, err := s.Has(ctx, ch.Address()); err != nil {
+			if !errors.Is(err, latency.ErrInjected) {
+				t.Fatal(err)
+			}
+			failed++
+		}
+	}
+	if failed < 400 || failed > 600 {
+		t.Fatalf("got %d failed operations of 1000 with error rate 0.5", failed)
+	}
+}
@@ -95,12 +95,12 @@ func (m *MockStorer) Put(ctx context.Context, mode storage.ModePut, chs ...swarm
 				return nil, storage.ErrInvalidChunk
 			}
 		}
-		m.store[ch.Address().String()] = ch.Data()
 		yes, err := m.has(ctx, ch.Address())
 		if err != nil {
 			exist = append(exist, false)
 			continue
 		}
+		m.store[ch.Address().String()] = ch.Data()
 		if yes {
 			exist = append(exist, true)
 		} else {
@@ -135,9 +135,28 @@ func (m *MockStorer) Set(ctx context.Context, mode storage.ModeSet, addrs ...swa
 	m.pinSetMu.Lock()
 	defer m.modeSetMu.Unlock()
 	defer m.pinSetMu.Unlock()
+
+	if mode == storage.ModeSetRemoveUnpinned {
+		for _, addr := range addrs {
+			for _, ad := range m.pinnedAddress {
+				if addr.Equal(ad) {
+					return storage.ErrPinned
+				}
+			}
+		}
+		mode = storage.ModeSetRemove
+	}
+
 	for _, addr := range addrs {
 		m.modeSet[addr.String()] = mode
 
+		// if mode is set remove, delete the chunk from the store
+		if mode == storage.ModeSetRemove {
+			m.mtx.Lock()
+			delete(m.store, addr.String())
+			m.mtx.Unlock()
+		}
+
 		// if mode is set pin, increment the pin counter
 		if mode == storage.ModeSetPin {
 			var found bool
@@ -16,6 +16,7 @@ import (
 var (
 	ErrNotFound     = errors.New("storage: not found")
 	ErrInvalidChunk = errors.New("storage: invalid chunk")
+	ErrPinned       = errors.New("storage: chunk is pinned")
 )
 
 // ModeGet enumerates different Getter modes.
@@ -91,6 +92,10 @@ func (m ModeSet) String() string {
 		return "ModeSetPin"
 	case ModeSetUnpin:
 		return "ModeSetUnpin"
+	case ModeSetRemoveUnpinned:
+		return "RemoveUnpinned"
+	case ModeSetDiscard:
+		return "Discard"
 	default:
 		return "Unknown"
 	}
@@ -110,6 +115,12 @@ const (
 	ModeSetPin
 	// ModeSetUnpin: when a chunk is unpinned using a command locally
 	ModeSetUnpin
+	// ModeSetRemoveUnpinned: when chunks are removed unless any of them is
+	// pinned, in which case ErrPinned is returned and none is removed
+	ModeSetRemoveUnpinned
+	// ModeSetDiscard: when the chunks of an aborted upload are not to be
+	// push synced, but left to the garbage collection
+	ModeSetDiscard
 )
 
 // Descriptor holds information required for Pull syncing. This struct
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -39,25 +40,38 @@ var (
 type State = uint32
 
 const (
-	TotalChunks State = iota // The total no of chunks for the tag
-	StateSplit               // chunk has been processed by filehasher/swarm safe call
-	StateStored              // chunk stored locally
-	StateSeen                // chunk previously seen
-	StateSent                // chunk sent to neighbourhood
-	StateSynced              // proof is received; chunk removed from sync db; chunk is available everywhere
+	TotalChunks  State = iota // The total no of chunks for the tag
+	StateSplit                // chunk has been processed by filehasher/swarm safe call
+	StateStored               // chunk stored locally
+	StateSeen                 // chunk previously seen
+	StateSent                 // chunk sent to neighbourhood
+	StateSynced               // proof is received; chunk removed from sync db; chunk is available everywhere
+	StateReceipt              // receipt is received from the peer that stored the chunk in the network
+)
+
+// Priority is the push sync priority of the chunks belonging to a tag.
+// Chunks of tags with higher priority are pushed before the chunks of
+// tags with lower priority.
+type Priority uint8
+
+const (
+	PriorityNormal Priority = iota // default priority
+	PriorityHigh                   // chunks are pushed before the ones with normal priority
 )
 
 // Tag represents info on the status of new chunks
 type Tag struct {
-	Total  int64 // total chunks belonging to a tag
-	Split  int64 // number of chunks already processed by splitter for hashing
-	Seen   int64 // number of chunks already seen
-	Stored int64 // number of chunks already stored locally
-	Sent   int64 // number of chunks sent for push syncing
-	Synced int64 // number of chunks synced with proof
+	Total    int64 // total chunks belonging to a tag
+	Split    int64 // number of chunks already processed by splitter for hashing
+	Seen     int64 // number of chunks already seen
+	Stored   int64 // number of chunks already stored locally
+	Sent     int64 // number of chunks sent for push syncing
+	Synced   int64 // number of chunks synced with proof
+	Receipts int64 // number of chunks with receipts received from the network
 
 	Uid       uint32        // a unique identifier for this tag
 	Anonymous bool          // indicates if the tag is anonymous (i.e. if only pull sync should be used)
+	Priority  Priority      // push sync priority of the chunks of this tag
 	Name      string        // a name tag for this tag
 	Address   swarm.Address // the associated swarm hash for this tag
 	StartedAt time.Time     // tag started to calculate ETA
@@ -112,6 +126,8 @@ func (t *Tag) IncN(state State, n int) {
 		v = &t.Sent
 	case StateSynced:
 		v = &t.Synced
+	case StateReceipt:
+		v = &t.Receipts
 	}
 	atomic.AddInt64(v, int64(n))
 }
@@ -137,6 +153,8 @@ func (t *Tag) Get(state State) int64 {
 		v = &t.Sent
 	case StateSynced:
 		v = &t.Synced
+	case StateReceipt:
+		v = &t.Receipts
 	}
 	return atomic.LoadInt64(v)
 }
@@ -190,7 +208,7 @@ func (t *Tag) Status(state State) (int64, int64, error) {
 	switch state {
 	case StateSplit, StateStored, StateSeen:
 		return count, total, nil
-	case StateSent, StateSynced:
+	case StateSent, StateSynced, StateReceipt:
 		stored := atomic.LoadInt64(&t.Stored)
 		if stored < total {
 			return count, total - seen, errNA
@@ -214,6 +232,12 @@ func (t *Tag) ETA(state State) (time.Time, error) {
 	return t.StartedAt.Add(dur), nil
 }
 
+// encodingVersion is the version of the binary encoding of the tags. It is
+// written as a negative value in place of the address length of the legacy
+// encoding, which is never negative, so that the tags encoded before the
+// Receipts counter was added are still decoded.
+const encodingVersion = 1
+
 // MarshalBinary marshals the tag into a byte slice
 func (tag *Tag) MarshalBinary() (data []byte, err error) {
 	buffer := make([]byte, 4)
@@ -230,15 +254,17 @@ func (tag *Tag) MarshalBinary() (data []byte, err error) {
 	n := binary.PutVarint(intBuffer, tag.StartedAt.Unix())
 	buffer = append(buffer, intBuffer[:n]...)
 
-	n = binary.PutVarint(intBuffer, int64(len(tag.Address.Bytes())))
-	buffer = append(buffer, intBuffer[:n]...)
+	encodeInt64Append(&buffer, -encodingVersion)
+	encodeInt64Append(&buffer, int64(len(tag.Address.Bytes())))
 	buffer = append(buffer, tag.Address.Bytes()...)
+	encodeInt64Append(&buffer, tag.Receipts)
 	buffer = append(buffer, []byte(tag.Name)...)
 
 	return buffer, nil
 }
 
-// UnmarshalBinary unmarshals a byte slice into a tag
+// UnmarshalBinary unmarshals a byte slice into a tag. The tags in the legacy
+// encoding, without the version, are decoded with no receipts.
 func (tag *Tag) UnmarshalBinary(buffer []byte) error {
 	if len(buffer) < 13 {
 		return errors.New("buffer too short")
@@ -257,12 +283,28 @@ func (tag *Tag) UnmarshalBinary(buffer []byte) error {
 	tag.StartedAt = time.Unix(t, 0)
 	buffer = buffer[n:]
 
-	t, n = binary.Varint(buffer)
-	buffer = buffer[n:]
+	version := int64(0)
+	t = decodeInt64Splice(&buffer)
+	if t < 0 {
+		version = -t
+		t = decodeInt64Splice(&buffer)
+	}
+	if version > encodingVersion {
+		return fmt.Errorf("unsupported tag encoding version %d", version)
+	}
+	if t < 0 || t > int64(len(buffer)) {
+		return errors.New("invalid address length")
+	}
+	tag.Address = swarm.ZeroAddress
 	if t > 0 {
 		tag.Address = swarm.NewAddress(buffer[:t])
 	}
-	tag.Name = string(buffer[t:])
+	buffer = buffer[t:]
+	tag.Receipts = 0
+	if version >= 1 {
+		tag.Receipts = decodeInt64Splice(&buffer)
+	}
+	tag.Name = string(buffer)
 
 	return nil
 }
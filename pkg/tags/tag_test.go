@@ -18,6 +18,7 @@ package tags
 
 import (
 	"context"
+	"encoding/binary"
 	"sync"
 	"testing"
 	"time"
@@ -26,7 +27,7 @@ import (
 )
 
 var (
-	allStates = []State{StateSplit, StateStored, StateSeen, StateSent, StateSynced}
+	allStates = []State{StateSplit, StateStored, StateSeen, StateSent, StateSynced, StateReceipt}
 )
 
 // TestTagSingleIncrements tests if Inc increments the tag state value
@@ -44,6 +45,7 @@ func TestTagSingleIncrements(t *testing.T) {
 		{state: StateSeen, inc: 1, expcount: 1, exptotal: 10},
 		{state: StateSent, inc: 9, expcount: 9, exptotal: 9},
 		{state: StateSynced, inc: 9, expcount: 9, exptotal: 9},
+		{state: StateReceipt, inc: 8, expcount: 8, exptotal: 9},
 	}
 
 	for _, tc := range tc {
@@ -116,7 +118,7 @@ func TestTagConcurrentIncrements(t *testing.T) {
 	tg := &Tag{}
 	n := 1000
 	wg := sync.WaitGroup{}
-	wg.Add(5 * n)
+	wg.Add(len(allStates) * n)
 	for _, f := range allStates {
 		go func(f State) {
 			for j := 0; j < n; j++ {
@@ -141,7 +143,7 @@ func TestTagsMultipleConcurrentIncrementsSyncMap(t *testing.T) {
 	ts := NewTags()
 	n := 100
 	wg := sync.WaitGroup{}
-	wg.Add(10 * 5 * n)
+	wg.Add(10 * len(allStates) * n)
 	for i := 0; i < 10; i++ {
 		s := string([]byte{uint8(i)})
 		tag, err := ts.Create(s, int64(n), false)
@@ -275,3 +277,48 @@ func TestMarshallingNoAddr(t *testing.T) {
 		t.Fatalf("expected tag addresses to be equal length")
 	}
 }
+
+// TestUnmarshallingLegacy tests that the tags encoded before the encoding
+// was versioned are still unmarshalled, without the receipts.
+func TestUnmarshallingLegacy(t *testing.T) {
+	addr := []byte{0, 1, 2, 3, 4, 5, 6}
+
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, 111)
+	for _, v := range []int64{10, 1, 2, 3, 4, 5, 1600000000, int64(len(addr))} {
+		encodeInt64Append(&b, v)
+	}
+	b = append(b, addr...)
+	b = append(b, []byte("test/tag")...)
+
+	tg := &Tag{}
+	if err := tg.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if tg.Uid != 111 || tg.Name != "test/tag" {
+		t.Fatalf("got uid %d name %q, want 111 %q", tg.Uid, tg.Name, "test/tag")
+	}
+	if tg.Total != 10 || tg.Split != 1 || tg.Seen != 2 || tg.Stored != 3 || tg.Sent != 4 || tg.Synced != 5 || tg.Receipts != 0 {
+		t.Fatalf("got counters %d %d %d %d %d %d %d", tg.Total, tg.Split, tg.Seen, tg.Stored, tg.Sent, tg.Synced, tg.Receipts)
+	}
+	if !tg.StartedAt.Equal(time.Unix(1600000000, 0)) {
+		t.Fatalf("got started at %v", tg.StartedAt)
+	}
+	if !tg.Address.Equal(swarm.NewAddress(addr)) {
+		t.Fatalf("got address %v, want %x", tg.Address, addr)
+	}
+
+	// the unmarshalled tag is marshalled in the current encoding
+	tg.Receipts = 5
+	b, err := tg.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &Tag{}
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if got.Receipts != 5 || got.Name != tg.Name || !got.Address.Equal(tg.Address) {
+		t.Fatalf("got receipts %d name %q address %v", got.Receipts, got.Name, got.Address)
+	}
+}
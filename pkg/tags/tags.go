@@ -26,6 +26,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/clock"
 	"github.com/ethersphere/bee/pkg/sctx"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
@@ -35,30 +36,117 @@ var (
 	ErrNotFound = errors.New("tag not found")
 )
 
+// priorityDeadline is the time since the start of a tag for which its
+// priority holds back the chunks of the tags with a lower priority, so that
+// they are not starved by the higher priority uploads.
+var priorityDeadline = 10 * time.Minute
+
+// priorityRecheckInterval is the time for which the active priority is
+// cached while there are tags with a priority above normal, after which
+// their sync progress and deadlines are checked again.
+var priorityRecheckInterval = time.Second
+
 // Tags hold tag information indexed by a unique random uint32
 type Tags struct {
-	tags *sync.Map
+	tags          *sync.Map
+	clock         clock.Clock
+	prioritized   map[uint32]*Tag // tags with a priority above normal that may be active
+	active        Priority        // cached active priority
+	activeValid   bool            // whether the cached active priority is valid
+	activeUntil   time.Time       // expiration of the cached active priority, if not zero
+	prioritizedMu sync.Mutex
 }
 
 // NewTags creates a tags object
 func NewTags() *Tags {
+	return NewTagsWithClock(clock.System)
+}
+
+// NewTagsWithClock creates a tags object that times the tags and their
+// priority deadlines with the clock.
+func NewTagsWithClock(c clock.Clock) *Tags {
 	return &Tags{
-		tags: &sync.Map{},
+		tags:        &sync.Map{},
+		clock:       c,
+		prioritized: make(map[uint32]*Tag),
 	}
 }
 
 // Create creates a new tag, stores it by the name and returns it
 // it returns an error if the tag with this name already exists
 func (ts *Tags) Create(s string, total int64, anon bool) (*Tag, error) {
+	return ts.CreateWithPriority(s, total, anon, PriorityNormal)
+}
+
+// CreateWithPriority creates a new tag with the given push sync priority,
+// stores it by the name and returns it
+// it returns an error if the tag with this name already exists
+func (ts *Tags) CreateWithPriority(s string, total int64, anon bool, priority Priority) (*Tag, error) {
 	t := NewTag(context.Background(), TagUidFunc(), s, total, anon, nil)
+	t.StartedAt = ts.clock.Now()
+	t.Priority = priority
 
 	if _, loaded := ts.tags.LoadOrStore(t.Uid, t); loaded {
 		return nil, errExists
 	}
+	ts.addPrioritized(t)
 
 	return t, nil
 }
 
+// ActivePriority returns the highest priority of the tags that have chunks
+// which are not yet synced, and which were started within the priority
+// deadline. Only the tags with a priority above normal are checked, and the
+// ones that are synced or past the deadline are not checked again. The
+// result is cached until such tags are added or deleted, and while there
+// are any of them, at most for the priority recheck interval, as it is
+// called for every chunk that is pushed.
+func (ts *Tags) ActivePriority() (priority Priority) {
+	ts.prioritizedMu.Lock()
+	defer ts.prioritizedMu.Unlock()
+
+	now := ts.clock.Now()
+	if ts.activeValid && (ts.activeUntil.IsZero() || now.Before(ts.activeUntil)) {
+		return ts.active
+	}
+
+	for uid, t := range ts.prioritized {
+		if now.Sub(t.StartedAt) > priorityDeadline {
+			delete(ts.prioritized, uid)
+			continue
+		}
+		if t.TotalCounter() == 0 {
+			// no chunks are added to the tag yet
+			continue
+		}
+		if t.Done(StateSynced) {
+			delete(ts.prioritized, uid)
+			continue
+		}
+		if t.Priority > priority {
+			priority = t.Priority
+		}
+	}
+
+	ts.active = priority
+	ts.activeValid = true
+	ts.activeUntil = time.Time{}
+	if len(ts.prioritized) > 0 {
+		ts.activeUntil = now.Add(priorityRecheckInterval)
+	}
+	return priority
+}
+
+func (ts *Tags) addPrioritized(t *Tag) {
+	if t.Priority <= PriorityNormal {
+		return
+	}
+	ts.prioritizedMu.Lock()
+	ts.prioritized[t.Uid] = t
+	ts.activeValid = false
+	ts.prioritizedMu.Unlock()
+}
+
 // All returns all existing tags in Tags' sync.Map
 // Note that tags are returned in no particular order
 func (ts *Tags) All() (t []*Tag) {
@@ -116,6 +204,14 @@ func (ts *Tags) Range(fn func(k, v interface{}) bool) {
 
 func (ts *Tags) Delete(k interface{}) {
 	ts.tags.Delete(k)
+	if uid, ok := k.(uint32); ok {
+		ts.prioritizedMu.Lock()
+		if _, ok := ts.prioritized[uid]; ok {
+			delete(ts.prioritized, uid)
+			ts.activeValid = false
+		}
+		ts.prioritizedMu.Unlock()
+	}
 }
 
 func (ts *Tags) MarshalJSON() (out []byte, err error) {
@@ -150,6 +246,7 @@ func (ts *Tags) UnmarshalJSON(value []byte) error {
 		v.Sent = v.Synced
 
 		ts.tags.Store(key, v)
+		ts.addPrioritized(v)
 	}
 
 	return err
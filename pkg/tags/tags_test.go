@@ -18,6 +18,9 @@ package tags
 
 import (
 	"testing"
+	"time"
+
+	clockmock "github.com/ethersphere/bee/pkg/clock/mock"
 )
 
 func TestAll(t *testing.T) {
@@ -52,3 +55,65 @@ func TestAll(t *testing.T) {
 		t.Fatalf("expected length to be 3 got %d", len(all))
 	}
 }
+
+// TestActivePriority checks that the priority of a tag is active while it has
+// chunks which are not synced, and at most for the priority deadline.
+func TestActivePriority(t *testing.T) {
+	clock := clockmock.New(time.Unix(1600000000, 0))
+	ts := NewTagsWithClock(clock)
+	if _, err := ts.Create("normal", 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := ts.ActivePriority(); got != PriorityNormal {
+		t.Fatalf("got priority %d, want %d", got, PriorityNormal)
+	}
+
+	high, err := ts.CreateWithPriority("high", 1, false, PriorityHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	high.Inc(StateStored)
+	if got := ts.ActivePriority(); got != PriorityHigh {
+		t.Fatalf("got priority %d, want %d", got, PriorityHigh)
+	}
+	high.Inc(StateSynced)
+	// the active priority is cached until the recheck interval passes
+	if got := ts.ActivePriority(); got != PriorityHigh {
+		t.Fatalf("got priority %d before the recheck, want %d", got, PriorityHigh)
+	}
+	clock.Add(priorityRecheckInterval)
+	if got := ts.ActivePriority(); got != PriorityNormal {
+		t.Fatalf("got priority %d after sync, want %d", got, PriorityNormal)
+	}
+
+	stale, err := ts.CreateWithPriority("stale", 1, false, PriorityHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.Inc(StateStored)
+	if got := ts.ActivePriority(); got != PriorityHigh {
+		t.Fatalf("got priority %d before the deadline, want %d", got, PriorityHigh)
+	}
+	clock.Add(priorityDeadline + time.Second)
+	if got := ts.ActivePriority(); got != PriorityNormal {
+		t.Fatalf("got priority %d after the deadline, want %d", got, PriorityNormal)
+	}
+}
+
+// TestActivePriorityDelete checks that the cached active priority is
+// recomputed when a prioritized tag is deleted.
+func TestActivePriorityDelete(t *testing.T) {
+	ts := NewTagsWithClock(clockmock.New(time.Unix(1600000000, 0)))
+	high, err := ts.CreateWithPriority("high", 1, false, PriorityHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	high.Inc(StateStored)
+	if got := ts.ActivePriority(); got != PriorityHigh {
+		t.Fatalf("got priority %d, want %d", got, PriorityHigh)
+	}
+	ts.Delete(high.Uid)
+	if got := ts.ActivePriority(); got != PriorityNormal {
+		t.Fatalf("got priority %d after delete, want %d", got, PriorityNormal)
+	}
+}
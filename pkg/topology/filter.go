@@ -0,0 +1,95 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package topology
+
+import (
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// PeerFilter returns true if the peer is skipped by the iteration. The
+// methods that tell the peers apart by their overlay addresses, such as
+// IsLightNode of the p2p LightNodeReporter, can be used as filters.
+type PeerFilter func(peer swarm.Address) (skip bool)
+
+// SkipPeers returns the filter that skips the peers with the addresses.
+func SkipPeers(addrs ...swarm.Address) PeerFilter {
+	return func(peer swarm.Address) bool {
+		for _, a := range addrs {
+			if a.Equal(peer) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Filtered returns the EachPeerer that iterates over the peers of p, in the
+// same order, skipping the peers for which any of the filters returns true.
+func Filtered(p EachPeerer, filters ...PeerFilter) EachPeerer {
+	if len(filters) == 0 {
+		return p
+	}
+	return &filtered{
+		EachPeerer: p,
+		filters:    filters,
+	}
+}
+
+type filtered struct {
+	EachPeerer
+	filters []PeerFilter
+}
+
+func (f *filtered) EachPeer(fn EachPeerFunc) error {
+	return f.EachPeerer.EachPeer(f.filter(fn))
+}
+
+func (f *filtered) EachPeerRev(fn EachPeerFunc) error {
+	return f.EachPeerer.EachPeerRev(f.filter(fn))
+}
+
+func (f *filtered) filter(fn EachPeerFunc) EachPeerFunc {
+	return func(peer swarm.Address, po uint8) (bool, bool, error) {
+		for _, skip := range f.filters {
+			if skip(peer) {
+				return false, false, nil
+			}
+		}
+		return fn(peer, po)
+	}
+}
+
+// ClosestPeers returns at most n peers of p that are the closest to the
+// address, ordered from the closest, skipping the peers for which any of the
+// filters returns true. All peers are returned if n is not positive.
+func ClosestPeers(p EachPeerer, addr swarm.Address, n int, filters ...PeerFilter) (peers []swarm.Address, err error) {
+	err = Filtered(p, filters...).EachPeerRev(func(peer swarm.Address, _ uint8) (bool, bool, error) {
+		// insert the peer after the peers that are not farther from the address
+		i := len(peers)
+		for ; i > 0; i-- {
+			dcmp, err := swarm.DistanceCmp(addr.Bytes(), peers[i-1].Bytes(), peer.Bytes())
+			if err != nil {
+				return false, false, err
+			}
+			if dcmp != -1 {
+				break
+			}
+		}
+		if n > 0 && i >= n {
+			return false, false, nil
+		}
+		peers = append(peers, swarm.Address{})
+		copy(peers[i+1:], peers[i:])
+		peers[i] = peer
+		if n > 0 && len(peers) > n {
+			peers = peers[:n]
+		}
+		return false, false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package topology_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology"
+	"github.com/ethersphere/bee/pkg/topology/mock"
+)
+
+func TestFiltered(t *testing.T) {
+	peers := []swarm.Address{
+		swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000"),
+		swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000"),
+		swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000"),
+	}
+	light := func(peer swarm.Address) bool {
+		return peer.Equal(peers[2])
+	}
+
+	p := topology.Filtered(mock.NewTopologyDriver(mock.WithPeers(peers...)), topology.SkipPeers(peers[0]), light)
+
+	var got []swarm.Address
+	if err := p.EachPeer(func(peer swarm.Address, _ uint8) (bool, bool, error) {
+		got = append(got, peer)
+		return false, false, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || !got[0].Equal(peers[1]) {
+		t.Fatalf("got peers %v, want %v", got, peers[1:2])
+	}
+}
+
+func TestClosestPeers(t *testing.T) {
+	peers := []swarm.Address{
+		swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000"), // binary 1000
+		swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000"), // binary 0100
+		swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000"), // binary 0110
+		swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), // binary 0111
+	}
+	p := mock.NewTopologyDriver(mock.WithPeers(peers...))
+	addr := swarm.MustParseHexAddress("7800000000000000000000000000000000000000000000000000000000000000") // binary 0111 1000
+
+	for _, tc := range []struct {
+		name    string
+		n       int
+		filters []topology.PeerFilter
+		want    []swarm.Address
+	}{
+		{
+			name: "all",
+			want: []swarm.Address{peers[3], peers[2], peers[1], peers[0]},
+		},
+		{
+			name: "closest two",
+			n:    2,
+			want: []swarm.Address{peers[3], peers[2]},
+		},
+		{
+			name:    "closest two with skipped",
+			n:       2,
+			filters: []topology.PeerFilter{topology.SkipPeers(peers[3])},
+			want:    []swarm.Address{peers[2], peers[1]},
+		},
+		{
+			name:    "all skipped",
+			n:       1,
+			filters: []topology.PeerFilter{topology.SkipPeers(peers...)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := topology.ClosestPeers(p, addr, tc.n, tc.filters...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got peers %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tc.want[i]) {
+					t.Fatalf("got peers %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
@@ -173,6 +173,12 @@ func (_ *driver) SubscribePeersChange() (c <-chan struct{}, unsubscribe func())
 	return c, unsubscribe
 }
 
+// SubscribeDepth returns the channel that never signals, as the depth is
+// always 0.
+func (_ *driver) SubscribeDepth() (c <-chan struct{}, unsubscribe func()) {
+	return c, func() {}
+}
+
 func (d *driver) MarshalJSON() ([]byte, error) {
 	var peers []string
 	for p := range d.receivedPeers {
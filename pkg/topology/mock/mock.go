@@ -12,48 +12,117 @@ import (
 	"github.com/ethersphere/bee/pkg/topology"
 )
 
-type mock struct {
-	peers           []swarm.Address
-	closestPeer     swarm.Address
-	closestPeerErr  error
-	addPeerErr      error
-	marshalJSONFunc func() ([]byte, error)
-	mtx             sync.Mutex
+// Mock is the topology driver for tests, which answers the closest peer
+// queries as scripted by the options and records them.
+type Mock struct {
+	peers            []swarm.Address
+	closestPeer      swarm.Address
+	closestPeerErr   error
+	closestPeerFor   map[string]ClosestPeerAnswer
+	closestPeerFunc  func(addr swarm.Address) (swarm.Address, error)
+	closestPeerSeq   []ClosestPeerAnswer
+	closestPeerCalls []swarm.Address
+	addPeerErr       error
+	depth            uint8
+	marshalJSONFunc  func() ([]byte, error)
+	peerSig          []chan struct{}
+	mtx              sync.Mutex
+}
+
+// ClosestPeerAnswer is the answer of the ClosestPeer of the Mock, the peer
+// or the error.
+type ClosestPeerAnswer struct {
+	Peer swarm.Address
+	Err  error
+}
+
+func WithPeers(peers ...swarm.Address) Option {
+	return optionFunc(func(d *Mock) {
+		d.peers = peers
+	})
 }
 
 func WithAddPeerErr(err error) Option {
-	return optionFunc(func(d *mock) {
+	return optionFunc(func(d *Mock) {
 		d.addPeerErr = err
 	})
 }
 
 func WithClosestPeer(addr swarm.Address) Option {
-	return optionFunc(func(d *mock) {
+	return optionFunc(func(d *Mock) {
 		d.closestPeer = addr
 	})
 }
 
 func WithClosestPeerErr(err error) Option {
-	return optionFunc(func(d *mock) {
+	return optionFunc(func(d *Mock) {
 		d.closestPeerErr = err
 	})
 }
 
+// WithClosestPeerFor sets the closest peer to the address, which is
+// returned instead of the one set by the WithClosestPeer. It can be used
+// multiple times for different addresses.
+func WithClosestPeerFor(addr, peer swarm.Address) Option {
+	return optionFunc(func(d *Mock) {
+		if d.closestPeerFor == nil {
+			d.closestPeerFor = make(map[string]ClosestPeerAnswer)
+		}
+		d.closestPeerFor[addr.ByteString()] = ClosestPeerAnswer{Peer: peer}
+	})
+}
+
+// WithClosestPeerErrFor sets the error of the closest peer queries for the
+// address, as the WithClosestPeerFor.
+func WithClosestPeerErrFor(addr swarm.Address, err error) Option {
+	return optionFunc(func(d *Mock) {
+		if d.closestPeerFor == nil {
+			d.closestPeerFor = make(map[string]ClosestPeerAnswer)
+		}
+		d.closestPeerFor[addr.ByteString()] = ClosestPeerAnswer{Err: err}
+	})
+}
+
+// WithClosestPeerFunc sets the function that answers the closest peer
+// queries for the addresses without the answers set by the
+// WithClosestPeerFor or the WithClosestPeerErrFor.
+func WithClosestPeerFunc(f func(addr swarm.Address) (swarm.Address, error)) Option {
+	return optionFunc(func(d *Mock) {
+		d.closestPeerFunc = f
+	})
+}
+
+// WithClosestPeerSequence sets the answers that are returned to the closest
+// peer queries in order, regardless of the address, such as an error on the
+// first query and a peer on the next one. The queries are answered as
+// configured by the other options once all answers are returned.
+func WithClosestPeerSequence(answers ...ClosestPeerAnswer) Option {
+	return optionFunc(func(d *Mock) {
+		d.closestPeerSeq = answers
+	})
+}
+
+func WithNeighborhoodDepth(depth uint8) Option {
+	return optionFunc(func(d *Mock) {
+		d.depth = depth
+	})
+}
+
 func WithMarshalJSONFunc(f func() ([]byte, error)) Option {
-	return optionFunc(func(d *mock) {
+	return optionFunc(func(d *Mock) {
 		d.marshalJSONFunc = f
 	})
 }
 
-func NewTopologyDriver(opts ...Option) topology.Driver {
-	d := new(mock)
+func NewTopologyDriver(opts ...Option) *Mock {
+	d := new(Mock)
 	for _, o := range opts {
 		o.apply(d)
 	}
 	return d
 }
 
-func (d *mock) AddPeer(_ context.Context, addr swarm.Address) error {
+func (d *Mock) AddPeer(_ context.Context, addr swarm.Address) error {
 	if d.addPeerErr != nil {
 		return d.addPeerErr
 	}
@@ -63,52 +132,164 @@ func (d *mock) AddPeer(_ context.Context, addr swarm.Address) error {
 	d.mtx.Unlock()
 	return nil
 }
-func (d *mock) Connected(ctx context.Context, addr swarm.Address) error {
-	return d.AddPeer(ctx, addr)
+
+// Connected adds the peer and signals the peers change subscribers.
+func (d *Mock) Connected(ctx context.Context, addr swarm.Address) error {
+	if err := d.AddPeer(ctx, addr); err != nil {
+		return err
+	}
+	d.notifyPeerSig()
+	return nil
 }
 
-func (d *mock) Disconnected(swarm.Address) {
-	panic("todo")
+// Disconnected removes the peer and signals the peers change subscribers.
+func (d *Mock) Disconnected(addr swarm.Address) {
+	d.mtx.Lock()
+	for i, p := range d.peers {
+		if p.Equal(addr) {
+			d.peers = append(d.peers[:i:i], d.peers[i+1:]...)
+			break
+		}
+	}
+	d.mtx.Unlock()
+	d.notifyPeerSig()
 }
 
-func (d *mock) Peers() []swarm.Address {
+func (d *Mock) Peers() []swarm.Address {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
 	return d.peers
 }
 
-func (d *mock) ClosestPeer(addr swarm.Address) (peerAddr swarm.Address, err error) {
-	return d.closestPeer, d.closestPeerErr
+// ClosestPeer answers the query as configured by the options. The function
+// set by the WithClosestPeerFunc is called without the lock, so that it may
+// call the other methods of the mock.
+func (d *Mock) ClosestPeer(addr swarm.Address) (peerAddr swarm.Address, err error) {
+	d.mtx.Lock()
+
+	d.closestPeerCalls = append(d.closestPeerCalls, addr)
+
+	if len(d.closestPeerSeq) > 0 {
+		a := d.closestPeerSeq[0]
+		d.closestPeerSeq = d.closestPeerSeq[1:]
+		d.mtx.Unlock()
+		return a.Peer, a.Err
+	}
+	if a, ok := d.closestPeerFor[addr.ByteString()]; ok {
+		d.mtx.Unlock()
+		return a.Peer, a.Err
+	}
+	f := d.closestPeerFunc
+	peerAddr, err = d.closestPeer, d.closestPeerErr
+	d.mtx.Unlock()
+
+	if f != nil {
+		return f(addr)
+	}
+	return peerAddr, err
+}
+
+// ClosestPeerCalls returns the addresses of the closest peer queries in the
+// order in which they were made.
+func (d *Mock) ClosestPeerCalls() []swarm.Address {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	calls := make([]swarm.Address, len(d.closestPeerCalls))
+	copy(calls, d.closestPeerCalls)
+	return calls
+}
+
+// SubscribePeersChange returns the channel that signals when the peers
+// are connected or disconnected. Returned function is safe to be called
+// multiple times.
+func (d *Mock) SubscribePeersChange() (c <-chan struct{}, unsubscribe func()) {
+	channel := make(chan struct{}, 1)
+	var closeOnce sync.Once
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	d.peerSig = append(d.peerSig, channel)
+
+	unsubscribe = func() {
+		d.mtx.Lock()
+		defer d.mtx.Unlock()
+
+		for i, c := range d.peerSig {
+			if c == channel {
+				d.peerSig = append(d.peerSig[:i], d.peerSig[i+1:]...)
+				break
+			}
+		}
+
+		closeOnce.Do(func() { close(channel) })
+	}
+
+	return channel, unsubscribe
+}
+
+func (d *Mock) notifyPeerSig() {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, c := range d.peerSig {
+		select {
+		case c <- struct{}{}:
+		default:
+		}
+	}
 }
 
-func (d *mock) SubscribePeersChange() (c <-chan struct{}, unsubscribe func()) {
-	return c, unsubscribe
+// SubscribeDepth returns the channel that never signals, as the depth set by
+// the WithNeighborhoodDepth does not change, and the unsubscribe function that
+// does nothing.
+func (d *Mock) SubscribeDepth() (c <-chan struct{}, unsubscribe func()) {
+	return make(chan struct{}), func() {}
 }
 
-func (_ *mock) NeighborhoodDepth() uint8 {
-	return 0
+func (d *Mock) NeighborhoodDepth() uint8 {
+	return d.depth
 }
 
-// EachPeer iterates from closest bin to farthest
-func (_ *mock) EachPeer(_ topology.EachPeerFunc) error {
-	panic("not implemented") // TODO: Implement
+// EachPeer iterates over all peers in the order they were added. The mock
+// has no base address, so the proximity order is always 0.
+func (d *Mock) EachPeer(f topology.EachPeerFunc) error {
+	d.mtx.Lock()
+	peers := make([]swarm.Address, len(d.peers))
+	copy(peers, d.peers)
+	d.mtx.Unlock()
+
+	for _, p := range peers {
+		stop, _, err := f(p, 0)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
 }
 
-// EachPeerRev iterates from farthest bin to closest
-func (_ *mock) EachPeerRev(_ topology.EachPeerFunc) error {
-	panic("not implemented") // TODO: Implement
+// EachPeerRev iterates over all peers in the same order as EachPeer.
+func (d *Mock) EachPeerRev(f topology.EachPeerFunc) error {
+	return d.EachPeer(f)
 }
 
-func (d *mock) MarshalJSON() ([]byte, error) {
+func (d *Mock) MarshalJSON() ([]byte, error) {
 	return d.marshalJSONFunc()
 }
 
-func (d *mock) Close() error {
+func (d *Mock) Close() error {
 	return nil
 }
 
 type Option interface {
-	apply(*mock)
+	apply(*Mock)
 }
 
-type optionFunc func(*mock)
+type optionFunc func(*Mock)
 
-func (f optionFunc) apply(r *mock) { f(r) }
+func (f optionFunc) apply(r *Mock) { f(r) }
@@ -22,7 +22,8 @@ type Driver interface {
 	ClosestPeerer
 	EachPeerer
 	Notifier
-	NeighborhoodDepth() uint8
+	NeighborhoodDepther
+	DepthSubscriber
 	SubscribePeersChange() (c <-chan struct{}, unsubscribe func())
 	io.Closer
 }
@@ -55,6 +56,29 @@ type ClosestPeerer interface {
 	ClosestPeer(addr swarm.Address) (peerAddr swarm.Address, err error)
 }
 
+// ReliableClosestPeerer is the ClosestPeerer that prefers the reliable
+// peers.
+type ReliableClosestPeerer interface {
+	// ClosestReliablePeer returns the closest peer to the address for which
+	// the unreliable filter returns false, or the closest peer if no such
+	// peer is closer to the address than this node. The skipped peers are
+	// never returned.
+	ClosestReliablePeer(addr swarm.Address, unreliable PeerFilter, skip ...swarm.Address) (peerAddr swarm.Address, err error)
+}
+
+type NeighborhoodDepther interface {
+	// NeighborhoodDepth returns the current estimate of the storage depth.
+	NeighborhoodDepth() uint8
+}
+
+type DepthSubscriber interface {
+	// SubscribeDepth returns the channel that signals when the neighborhood
+	// depth changes, for the current depth to be read with the
+	// NeighborhoodDepth. Returned function is safe to be called multiple
+	// times.
+	SubscribeDepth() (c <-chan struct{}, unsubscribe func())
+}
+
 type EachPeerer interface {
 	// EachPeer iterates from closest bin to farthest
 	EachPeer(EachPeerFunc) error
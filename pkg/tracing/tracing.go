@@ -10,6 +10,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net/http"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/logging"
@@ -158,6 +159,56 @@ func (t *Tracer) WithContextFromHeaders(ctx context.Context, headers p2p.Headers
 	return WithContext(ctx, c), nil
 }
 
+// AddContextHTTPHeader adds a tracing span context to provided HTTP Header
+// from the go context. If the tracing span context is not present in go
+// context, ErrContextNotFound is returned.
+func (t *Tracer) AddContextHTTPHeader(ctx context.Context, headers http.Header) error {
+	if t == nil {
+		t = noopTracer
+	}
+
+	c := FromContext(ctx)
+	if c == nil {
+		return ErrContextNotFound
+	}
+
+	return t.tracer.Inject(c, opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(headers))
+}
+
+// FromHTTPHeaders returns tracing span context from HTTP Header. If the
+// tracing span context is not present in the headers, ErrContextNotFound is
+// returned.
+func (t *Tracer) FromHTTPHeaders(headers http.Header) (opentracing.SpanContext, error) {
+	if t == nil {
+		t = noopTracer
+	}
+
+	c, err := t.tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(headers))
+	if err != nil {
+		if errors.Is(err, opentracing.ErrSpanContextNotFound) {
+			return nil, ErrContextNotFound
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// WithContextFromHTTPHeaders returns a new context with injected tracing span
+// context if they are found in HTTP Header. If the tracing span context is not
+// present in the headers, ErrContextNotFound is returned.
+func (t *Tracer) WithContextFromHTTPHeaders(ctx context.Context, headers http.Header) (context.Context, error) {
+	if t == nil {
+		t = noopTracer
+	}
+
+	c, err := t.FromHTTPHeaders(headers)
+	if err != nil {
+		return ctx, err
+	}
+	return WithContext(ctx, c), nil
+}
+
 // WithContext adds tracing span context to go context.
 func WithContext(ctx context.Context, c opentracing.SpanContext) context.Context {
 	return context.WithValue(ctx, contextKey, c)
@@ -6,8 +6,10 @@ package tracing_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"testing"
 
@@ -80,6 +82,43 @@ func TestSpanWithContextFromHeaders(t *testing.T) {
 	}
 }
 
+func TestSpanWithContextFromHTTPHeaders(t *testing.T) {
+	tracer, closer := newTracer(t)
+	defer closer.Close()
+
+	span, _, ctx := tracer.StartSpanFromContext(context.Background(), "some-operation", nil)
+	defer span.Finish()
+
+	headers := make(http.Header)
+	if err := tracer.AddContextHTTPHeader(ctx, headers); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, err := tracer.WithContextFromHTTPHeaders(context.Background(), headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotSpanContext := tracing.FromContext(ctx)
+	if fmt.Sprint(gotSpanContext) == "" {
+		t.Fatal("got empty span context")
+	}
+
+	wantSpanContext := span.Context()
+	if fmt.Sprint(gotSpanContext) != fmt.Sprint(wantSpanContext) {
+		t.Errorf("got span context %+v, want %+v", gotSpanContext, wantSpanContext)
+	}
+}
+
+func TestFromHTTPHeaders_notFound(t *testing.T) {
+	tracer, closer := newTracer(t)
+	defer closer.Close()
+
+	if _, err := tracer.FromHTTPHeaders(make(http.Header)); !errors.Is(err, tracing.ErrContextNotFound) {
+		t.Fatalf("got error %v, want %v", err, tracing.ErrContextNotFound)
+	}
+}
+
 func TestFromContext(t *testing.T) {
 	tracer, closer := newTracer(t)
 	defer closer.Close()
@@ -0,0 +1,172 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package traversal provides iteration over the addresses of all chunks
+// that form the content addressed chunk trees of uploaded data.
+//
+// Only unencrypted references are supported.
+package traversal
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/collection/entry"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/manifest/jsonmanifest"
+	"github.com/ethersphere/bee/pkg/manifest/triemanifest"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var (
+	// ErrInvalidReference is returned if the reference is not a valid
+	// unencrypted chunk address.
+	ErrInvalidReference = errors.New("traversal: invalid reference")
+	// ErrInvalidChunk is returned if the chunk data is too short to
+	// contain the span.
+	ErrInvalidChunk = errors.New("traversal: invalid chunk")
+)
+
+// AddressIterFunc is called for every chunk address in the traversed tree.
+// Returning an error stops the traversal.
+type AddressIterFunc func(address swarm.Address) error
+
+// Service traverses chunk trees.
+type Service interface {
+	// TraverseBytesAddresses iterates through the addresses of all chunks
+	// of the data uploaded as raw bytes, starting with the root chunk.
+	TraverseBytesAddresses(ctx context.Context, reference swarm.Address, f AddressIterFunc) error
+	// TraverseFileAddresses iterates through the addresses of all chunks
+	// of the file entry, its metadata and the file data.
+	TraverseFileAddresses(ctx context.Context, reference swarm.Address, f AddressIterFunc) error
+	// TraverseManifestAddresses iterates through the addresses of all chunks
+	// of the manifest and of all files in it. The addresses of the chunks
+	// that are shared by several files are iterated once for every file.
+	TraverseManifestAddresses(ctx context.Context, reference swarm.Address, f AddressIterFunc) error
+}
+
+type service struct {
+	getter storage.Getter
+}
+
+// NewService creates a new traversal Service that retrieves chunks
+// using the provided getter. Chunks are retrieved with ModeGetLookup, so
+// that the traversal does not change their access order, and the traversal
+// stops once the context is done.
+func NewService(getter storage.Getter) Service {
+	return &service{
+		getter: lookupGetter{getter},
+	}
+}
+
+// lookupGetter gets the chunks with ModeGetLookup, whichever mode the
+// joiners use, while the context is not done.
+type lookupGetter struct {
+	storage.Getter
+}
+
+func (g lookupGetter) Get(ctx context.Context, _ storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return g.Getter.Get(ctx, storage.ModeGetLookup, addr)
+}
+
+func (s *service) TraverseBytesAddresses(ctx context.Context, reference swarm.Address, f AddressIterFunc) error {
+	if len(reference.Bytes()) != swarm.HashSize {
+		return ErrInvalidReference
+	}
+
+	ch, err := s.getter.Get(ctx, storage.ModeGetLookup, reference)
+	if err != nil {
+		return fmt.Errorf("get chunk %s: %w", reference, err)
+	}
+	if err := f(reference); err != nil {
+		return err
+	}
+
+	data := ch.Data()
+	if len(data) < 8 {
+		return fmt.Errorf("chunk %s: %w", reference, ErrInvalidChunk)
+	}
+
+	// chunks with span not larger than the chunk size are data chunks
+	if binary.LittleEndian.Uint64(data[:8]) <= swarm.ChunkSize {
+		return nil
+	}
+
+	// intermediate chunks hold the references to the chunks of the lower level
+	for refs := data[8:]; len(refs) >= swarm.HashSize; refs = refs[swarm.HashSize:] {
+		if err := s.TraverseBytesAddresses(ctx, swarm.NewAddress(refs[:swarm.HashSize]), f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) TraverseFileAddresses(ctx context.Context, reference swarm.Address, f AddressIterFunc) error {
+	if err := s.TraverseBytesAddresses(ctx, reference, f); err != nil {
+		return fmt.Errorf("entry: %w", err)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := file.JoinReadAll(joiner.NewSimpleJoiner(s.getter), reference, buf, false); err != nil {
+		return fmt.Errorf("read entry %s: %w", reference, err)
+	}
+	e := &entry.Entry{}
+	if err := e.UnmarshalBinary(buf.Bytes()); err != nil {
+		return fmt.Errorf("unmarshal entry %s: %w", reference, err)
+	}
+
+	if err := s.TraverseBytesAddresses(ctx, e.Metadata(), f); err != nil {
+		return fmt.Errorf("metadata: %w", err)
+	}
+	if err := s.TraverseBytesAddresses(ctx, e.Reference(), f); err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	return nil
+}
+
+func (s *service) TraverseManifestAddresses(ctx context.Context, reference swarm.Address, f AddressIterFunc) error {
+	if err := s.TraverseBytesAddresses(ctx, reference, f); err != nil {
+		return fmt.Errorf("manifest: %w", err)
+	}
+
+	m, err := s.loadManifest(reference)
+	if err != nil {
+		return fmt.Errorf("load manifest %s: %w", reference, err)
+	}
+	return m.Walk(func(path string, e manifest.Entry) error {
+		if err := s.TraverseFileAddresses(ctx, e.Reference(), f); err != nil {
+			return fmt.Errorf("file %q: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// loadManifest loads the manifest with the reference, which is either a trie
+// manifest or a JSON manifest of the earlier collection uploads. The JSON
+// manifests are objects, while the serialized trie manifests start with
+// their version byte, which is never an opening brace.
+func (s *service) loadManifest(reference swarm.Address) (manifest.Interface, error) {
+	buf := bytes.NewBuffer(nil)
+	if _, err := file.JoinReadAll(joiner.NewSimpleJoiner(s.getter), reference, buf, false); err != nil {
+		return nil, fmt.Errorf("manifest read: %w", err)
+	}
+
+	var m manifest.Interface = triemanifest.NewManifest()
+	if bytes.HasPrefix(buf.Bytes(), []byte("{")) {
+		m = jsonmanifest.NewManifest()
+	}
+	if err := m.UnmarshalBinary(buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("%w: %v", manifest.ErrInvalidManifest, err)
+	}
+	return m, nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package traversal_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/collection/entry"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	filetest "github.com/ethersphere/bee/pkg/file/testing"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/manifest/jsonmanifest"
+	"github.com/ethersphere/bee/pkg/manifest/triemanifest"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/traversal"
+)
+
+// recordingStorer records the addresses of all chunks that are put.
+type recordingStorer struct {
+	*mock.MockStorer
+	mu    sync.Mutex
+	added map[string]struct{}
+}
+
+func newRecordingStorer() *recordingStorer {
+	return &recordingStorer{
+		MockStorer: mock.NewStorer(),
+		added:      make(map[string]struct{}),
+	}
+}
+
+func (s *recordingStorer) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) ([]bool, error) {
+	s.mu.Lock()
+	for _, ch := range chs {
+		s.added[ch.Address().String()] = struct{}{}
+	}
+	s.mu.Unlock()
+	return s.MockStorer.Put(ctx, mode, chs...)
+}
+
+func TestTraverseBytesAddresses(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		size       int
+		wantChunks int
+	}{
+		{
+			name:       "single chunk",
+			size:       swarm.ChunkSize,
+			wantChunks: 1,
+		},
+		{
+			name:       "two levels",
+			size:       swarm.ChunkSize*2 + 10,
+			wantChunks: 4,
+		},
+		{
+			name: "three levels",
+			size: swarm.ChunkSize*swarm.Branches + 1,
+			// the dangling data chunk is referenced directly from the root chunk
+			wantChunks: swarm.Branches + 1 + 1 + 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newRecordingStorer()
+			reference := split(t, store, tc.size)
+
+			traversed := traverse(t, func(f traversal.AddressIterFunc) error {
+				return traversal.NewService(store).TraverseBytesAddresses(context.Background(), reference, f)
+			})
+
+			if len(traversed) != tc.wantChunks {
+				t.Fatalf("got %d chunks, want %d", len(traversed), tc.wantChunks)
+			}
+			checkTraversed(t, store, traversed)
+		})
+	}
+}
+
+func TestTraverseFileAddresses(t *testing.T) {
+	store := newRecordingStorer()
+
+	dataReference := split(t, store, swarm.ChunkSize*2)
+	metadataReference := split(t, store, 100)
+	e, err := entry.New(dataReference, metadataReference).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference, err := splitter.NewSimpleSplitter(store).Split(context.Background(), file.NewSimpleReadCloser(e), int64(len(e)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	traversed := traverse(t, func(f traversal.AddressIterFunc) error {
+		return traversal.NewService(store).TraverseFileAddresses(context.Background(), reference, f)
+	})
+
+	// entry, metadata and three chunks of data
+	if len(traversed) != 5 {
+		t.Fatalf("got %d chunks, want %d", len(traversed), 5)
+	}
+	checkTraversed(t, store, traversed)
+}
+
+func TestTraverseManifestAddresses(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		manifest func() manifest.Interface
+		entry    func(reference swarm.Address, name string) manifest.Entry
+	}{
+		{
+			name:     "trie",
+			manifest: func() manifest.Interface { return triemanifest.NewManifest() },
+			entry: func(reference swarm.Address, name string) manifest.Entry {
+				return triemanifest.NewEntry(reference, name, "", nil)
+			},
+		},
+		{
+			name:     "json",
+			manifest: func() manifest.Interface { return jsonmanifest.NewManifest() },
+			entry: func(reference swarm.Address, name string) manifest.Entry {
+				return jsonmanifest.NewEntry(reference, name, "", nil)
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newRecordingStorer()
+
+			m := tc.manifest()
+			for _, f := range []struct {
+				path string
+				size int
+			}{
+				{path: "index.html", size: 100},
+				{path: "img/logo.png", size: swarm.ChunkSize * 2},
+			} {
+				e, err := entry.New(split(t, store, f.size), split(t, store, 10)).MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				reference, err := splitter.NewSimpleSplitter(store).Split(context.Background(), file.NewSimpleReadCloser(e), int64(len(e)), false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				m.Add(f.path, tc.entry(reference, f.path))
+			}
+			reference, err := manifest.Store(context.Background(), splitter.NewSimpleSplitter(store), m, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			traversed := traverse(t, func(f traversal.AddressIterFunc) error {
+				return traversal.NewService(store).TraverseManifestAddresses(context.Background(), reference, f)
+			})
+
+			checkTraversed(t, store, traversed)
+		})
+	}
+}
+
+func TestTraverseStop(t *testing.T) {
+	store := newRecordingStorer()
+	reference := split(t, store, swarm.ChunkSize*3)
+
+	errStop := errors.New("stop")
+	var count int
+	err := traversal.NewService(store).TraverseBytesAddresses(context.Background(), reference, func(swarm.Address) error {
+		count++
+		if count == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("got error %v, want %v", err, errStop)
+	}
+	if count != 2 {
+		t.Fatalf("got %d iterations, want %d", count, 2)
+	}
+}
+
+func split(t *testing.T, store storage.Putter, size int) swarm.Address {
+	t.Helper()
+
+	data := filetest.GenerateTestData(t, size)
+	reference, err := splitter.NewSimpleSplitter(store).Split(context.Background(), file.NewSimpleReadCloser(data), int64(size), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reference
+}
+
+func traverse(t *testing.T, f func(traversal.AddressIterFunc) error) map[string]struct{} {
+	t.Helper()
+
+	traversed := make(map[string]struct{})
+	if err := f(func(addr swarm.Address) error {
+		traversed[addr.String()] = struct{}{}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return traversed
+}
+
+func checkTraversed(t *testing.T, store *recordingStorer, traversed map[string]struct{}) {
+	t.Helper()
+
+	if len(traversed) != len(store.added) {
+		t.Fatalf("traversed %d chunks, stored %d", len(traversed), len(store.added))
+	}
+	for a := range store.added {
+		if _, ok := traversed[a]; !ok {
+			t.Fatalf("chunk %s not traversed", a)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package uploadkeys provides persistence of the references of encrypted
+// uploads, so that the decryption keys of the content uploaded through the
+// node are not lost. The references are kept encrypted with the password of
+// the user, and only their addresses are stored in plain text.
+package uploadkeys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keyPrefix = "upload_key_"
+
+	saltLength  = 32
+	scryptN     = 1 << 15
+	scryptR     = 8
+	scryptP     = 1
+	scryptDKLen = 32
+
+	// maxDerivations is the number of the scrypt key derivations that are
+	// run concurrently, as every one of them takes a lot of memory and cpu.
+	maxDerivations = 2
+)
+
+var _ Interface = (*store)(nil)
+
+var (
+	ErrNotFound        = errors.New("upload keys: not found")
+	ErrInvalidPassword = errors.New("upload keys: invalid password")
+	// ErrNotEncrypted is returned when the reference does not include the
+	// decryption key.
+	ErrNotEncrypted = errors.New("upload keys: reference not encrypted")
+)
+
+// Key is the stored reference of the encrypted content with the root chunk
+// Address.
+type Key struct {
+	Address swarm.Address `json:"address"`
+	// Name is the optional name of the uploaded content, as the file name.
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	// Salt is the salt of the scrypt derivation of the encryption key from
+	// the password, and Nonce the nonce of the AES-GCM encryption of the
+	// reference.
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	CipherText []byte `json:"cipherText"`
+}
+
+type Interface interface {
+	// Put stores the encrypted reference, with the decryption key, under the
+	// address of its root chunk, encrypted with the password.
+	Put(reference swarm.Address, name, password string) (*Key, error)
+	// Get returns the stored reference without decrypting it.
+	Get(address swarm.Address) (*Key, error)
+	// Export returns the reference with the decryption key of the content
+	// with the root chunk address.
+	Export(address swarm.Address, password string) (swarm.Address, error)
+	// Delete removes the stored reference if the password is the one that
+	// it is encrypted with.
+	Delete(address swarm.Address, password string) error
+	// List returns all stored references without decrypting them.
+	List() ([]Key, error)
+}
+
+type store struct {
+	store       storage.StateStorer
+	derivations chan struct{} // limits the number of concurrent key derivations
+}
+
+func New(storer storage.StateStorer) Interface {
+	return &store{
+		store:       storer,
+		derivations: make(chan struct{}, maxDerivations),
+	}
+}
+
+func (s *store) Put(reference swarm.Address, name, password string) (*Key, error) {
+	if len(reference.Bytes()) <= swarm.HashSize {
+		return nil, ErrNotEncrypted
+	}
+
+	k := &Key{
+		Address: swarm.NewAddress(reference.Bytes()[:swarm.HashSize]),
+		Name:    name,
+		Created: time.Now().UTC(),
+		Salt:    make([]byte, saltLength),
+	}
+	if _, err := io.ReadFull(rand.Reader, k.Salt); err != nil {
+		return nil, fmt.Errorf("read random data: %w", err)
+	}
+	aead, err := s.newAEAD(password, k.Salt)
+	if err != nil {
+		return nil, err
+	}
+	k.Nonce = make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, k.Nonce); err != nil {
+		return nil, fmt.Errorf("read random data: %w", err)
+	}
+	// the address is authenticated with the reference, so that the stored
+	// cipher text cannot be moved under a different address
+	k.CipherText = aead.Seal(nil, k.Nonce, reference.Bytes(), k.Address.Bytes())
+
+	if err := s.store.Put(keyPrefix+k.Address.String(), k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (s *store) Get(address swarm.Address) (*Key, error) {
+	v := &Key{}
+	err := s.store.Get(keyPrefix+address.String(), v)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *store) Export(address swarm.Address, password string) (swarm.Address, error) {
+	k, err := s.Get(address)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	aead, err := s.newAEAD(password, k.Salt)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	reference, err := aead.Open(nil, k.Nonce, k.CipherText, k.Address.Bytes())
+	if err != nil {
+		return swarm.ZeroAddress, ErrInvalidPassword
+	}
+	return swarm.NewAddress(reference), nil
+}
+
+func (s *store) Delete(address swarm.Address, password string) error {
+	if _, err := s.Export(address, password); err != nil {
+		return err
+	}
+	return s.store.Delete(keyPrefix + address.String())
+}
+
+func (s *store) List() (keys []Key, err error) {
+	err = s.store.Iterate(keyPrefix, func(_, value []byte) (stop bool, err error) {
+		var k Key
+		if err := json.Unmarshal(value, &k); err != nil {
+			return true, err
+		}
+
+		keys = append(keys, k)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// newAEAD returns the AES-GCM cipher with the key derived from the password.
+func (s *store) newAEAD(password string, salt []byte) (cipher.AEAD, error) {
+	s.derivations <- struct{}{}
+	key, err := scrypt.Key([]byte(password), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	<-s.derivations
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
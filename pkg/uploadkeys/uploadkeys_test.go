@@ -0,0 +1,69 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package uploadkeys_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/uploadkeys"
+)
+
+func TestKeys(t *testing.T) {
+	store := uploadkeys.New(mock.NewStateStore())
+
+	reference := swarm.MustParseHexAddress("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaabbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+	address := swarm.NewAddress(reference.Bytes()[:swarm.HashSize])
+
+	if _, err := store.Put(address, "", "secret"); !errors.Is(err, uploadkeys.ErrNotEncrypted) {
+		t.Fatalf("got error %v, want %v", err, uploadkeys.ErrNotEncrypted)
+	}
+	if _, err := store.Get(address); !errors.Is(err, uploadkeys.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, uploadkeys.ErrNotFound)
+	}
+
+	k, err := store.Put(reference, "photo.jpg", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !k.Address.Equal(address) {
+		t.Fatalf("got address %s, want %s", k.Address, address)
+	}
+	if bytes.Contains(k.CipherText, reference.Bytes()[swarm.HashSize:]) {
+		t.Fatal("decryption key stored in plain text")
+	}
+
+	keys, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || !keys[0].Address.Equal(address) || keys[0].Name != "photo.jpg" {
+		t.Fatalf("got keys %+v", keys)
+	}
+
+	if _, err := store.Export(address, "wrong"); !errors.Is(err, uploadkeys.ErrInvalidPassword) {
+		t.Fatalf("got error %v, want %v", err, uploadkeys.ErrInvalidPassword)
+	}
+	got, err := store.Export(address, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(reference) {
+		t.Fatalf("got reference %s, want %s", got, reference)
+	}
+
+	if err := store.Delete(address, "wrong"); !errors.Is(err, uploadkeys.ErrInvalidPassword) {
+		t.Fatalf("got error %v, want %v", err, uploadkeys.ErrInvalidPassword)
+	}
+	if err := store.Delete(address, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Export(address, "secret"); !errors.Is(err, uploadkeys.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, uploadkeys.ErrNotFound)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package uploadsession provides persistence of the sessions of resumable
+// file uploads, so that an upload that is interrupted can be continued from
+// the data that has already been stored.
+package uploadsession
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+const (
+	keyPrefix = "upload_session_"
+	idLength  = 16
+
+	// TTL is the time after the last update of a session after which it
+	// expires and is removed, as its upload is deemed abandoned.
+	TTL = 24 * time.Hour
+)
+
+// timeNow is used to deterministically mock time.Now() in tests.
+var timeNow = time.Now
+
+var _ Interface = (*store)(nil)
+
+var ErrNotFound = errors.New("upload session: not found")
+
+// Session is the state of a resumable upload of a file.
+type Session struct {
+	ID          string `json:"id"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	// Length is the length of the file data and Offset the length of its
+	// part that is stored.
+	Length    int64 `json:"length"`
+	Offset    int64 `json:"offset"`
+	ToEncrypt bool  `json:"toEncrypt"`
+	// TagUid is the uid of the tag that counts the chunks of the upload.
+	TagUid uint32 `json:"tagUid"`
+	// SplitterState is the state of the splitter after the stored part of
+	// the data, from which the splitting is resumed.
+	SplitterState []byte `json:"splitterState"`
+	// Updated is the time of the last store of the session.
+	Updated time.Time `json:"updated"`
+}
+
+func (s *Session) expired(now time.Time) bool {
+	return now.Sub(s.Updated) >= TTL
+}
+
+type Interface interface {
+	// Create assigns a new random ID to the session and stores it. The
+	// expired sessions are removed.
+	Create(s *Session) error
+	// Get returns the session, or ErrNotFound if it is expired.
+	Get(id string) (*Session, error)
+	// Put stores the session, which is then not expired for the TTL.
+	Put(s *Session) error
+	Delete(id string) error
+}
+
+type store struct {
+	store storage.StateStorer
+}
+
+func New(storer storage.StateStorer) Interface {
+	return &store{
+		store: storer,
+	}
+}
+
+func (s *store) Create(session *Session) error {
+	id := make([]byte, idLength)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	session.ID = hex.EncodeToString(id)
+	if err := s.prune(); err != nil {
+		return err
+	}
+	return s.Put(session)
+}
+
+func (s *store) Get(id string) (*Session, error) {
+	v := &Session{}
+	err := s.store.Get(keyPrefix+id, v)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+	if v.expired(timeNow()) {
+		if err := s.Delete(id); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *store) Put(session *Session) error {
+	session.Updated = timeNow().UTC()
+	return s.store.Put(keyPrefix+session.ID, session)
+}
+
+// prune removes the expired sessions.
+func (s *store) prune() error {
+	now := timeNow()
+	var expired []string
+	err := s.store.Iterate(keyPrefix, func(key, value []byte) (stop bool, err error) {
+		var v Session
+		if err := json.Unmarshal(value, &v); err != nil {
+			return true, err
+		}
+		if v.expired(now) {
+			expired = append(expired, string(key))
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range expired {
+		if err := s.store.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *store) Delete(id string) error {
+	return s.store.Delete(keyPrefix + id)
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package uploadsession_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/uploadsession"
+)
+
+func TestSessions(t *testing.T) {
+	store := uploadsession.New(mock.NewStateStore())
+
+	if _, err := store.Get("missing"); !errors.Is(err, uploadsession.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, uploadsession.ErrNotFound)
+	}
+
+	s := &uploadsession.Session{
+		FileName: "video.mp4",
+		Length:   10000,
+		TagUid:   7,
+	}
+	if err := store.Create(s); err != nil {
+		t.Fatal(err)
+	}
+	if s.ID == "" {
+		t.Fatal("no session id")
+	}
+
+	s.Offset = 4096
+	s.SplitterState = []byte("state")
+	if err := store.Put(s); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Get(s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.FileName != s.FileName || got.Length != s.Length || got.Offset != s.Offset || got.TagUid != s.TagUid || !bytes.Equal(got.SplitterState, s.SplitterState) {
+		t.Fatalf("got session %+v, want %+v", got, s)
+	}
+
+	if err := store.Delete(s.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(s.ID); !errors.Is(err, uploadsession.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, uploadsession.ErrNotFound)
+	}
+}
+
+func TestSessionsExpiry(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	uploadsession.SetTimeNow(func() time.Time { return now })
+	defer uploadsession.SetTimeNow(time.Now)
+
+	stateStore := mock.NewStateStore()
+	store := uploadsession.New(stateStore)
+
+	stale := &uploadsession.Session{Length: 10000}
+	if err := store.Create(stale); err != nil {
+		t.Fatal(err)
+	}
+	fresh := &uploadsession.Session{Length: 10000}
+	if err := store.Create(fresh); err != nil {
+		t.Fatal(err)
+	}
+
+	// the update of the session extends its expiry
+	now = now.Add(uploadsession.TTL / 2)
+	if err := store.Put(fresh); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(uploadsession.TTL / 2)
+	if _, err := store.Get(fresh.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(stale.ID); !errors.Is(err, uploadsession.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, uploadsession.ErrNotFound)
+	}
+
+	// the expired sessions are removed when a session is created
+	now = now.Add(uploadsession.TTL)
+	if err := store.Create(&uploadsession.Session{Length: 10000}); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := stateStore.Iterate("upload_session_", func(_, _ []byte) (bool, error) {
+		count++
+		return false, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("got %d stored sessions, want 1", count)
+	}
+}
@@ -16,6 +16,10 @@ import (
 
 var _ swarm.ChunkValidator = (*ContentAddressValidator)(nil)
 
+// spanSize is the size of the span at the start of the chunk data, which
+// is the length of the data that is referenced by the chunk.
+const spanSize = 8
+
 func hashFunc() hash.Hash {
 	return sha3.NewLegacyKeccak256()
 }
@@ -33,13 +37,17 @@ func NewContentAddressValidator() swarm.ChunkValidator {
 
 // Validate performs the validation check
 func (v *ContentAddressValidator) Validate(ch swarm.Chunk) (valid bool) {
+	// the data must hold the span and at most a chunk of payload
+	data := ch.Data()
+	if len(data) < spanSize || len(data) > spanSize+swarm.ChunkSize {
+		return false
+	}
+
 	p := bmtlegacy.NewTreePool(hashFunc, swarm.Branches, bmtlegacy.PoolSize)
 	hasher := bmtlegacy.New(p)
 
-	// prepare data
-	data := ch.Data()
 	address := ch.Address()
-	span := binary.LittleEndian.Uint64(data[:8])
+	span := binary.LittleEndian.Uint64(data[:spanSize])
 
 	// execute hash, compare and return result
 	hasher.Reset()
@@ -47,7 +55,7 @@ func (v *ContentAddressValidator) Validate(ch swarm.Chunk) (valid bool) {
 	if err != nil {
 		return false
 	}
-	_, err = hasher.Write(data[8:])
+	_, err = hasher.Write(data[spanSize:])
 	if err != nil {
 		return false
 	}
@@ -39,4 +39,16 @@ func TestContentAddressValidator(t *testing.T) {
 	if validator.Validate(ch) {
 		t.Fatalf("data '%s' should not have validated to hash '%s'", ch.Data(), ch.Address())
 	}
+
+	// test with data shorter than the span
+	ch = swarm.NewChunk(address, fooBytes[:4])
+	if validator.Validate(ch) {
+		t.Fatalf("data '%s' without span should not have validated", ch.Data())
+	}
+
+	// test with data longer than a chunk
+	ch = swarm.NewChunk(address, make([]byte, 8+swarm.ChunkSize+1))
+	if validator.Validate(ch) {
+		t.Fatal("data longer than a chunk should not have validated")
+	}
 }